@@ -2,48 +2,204 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
+	"linkedin-automation/internal/api"
 	"linkedin-automation/internal/auth"
 	"linkedin-automation/internal/browser"
 	"linkedin-automation/internal/config"
 	"linkedin-automation/internal/connect"
+	"linkedin-automation/internal/dashboard"
+	"linkedin-automation/internal/engage"
 	"linkedin-automation/internal/logger"
 	"linkedin-automation/internal/message"
+	"linkedin-automation/internal/metrics"
+	"linkedin-automation/internal/normalize"
+	"linkedin-automation/internal/notify"
+	"linkedin-automation/internal/report"
 	"linkedin-automation/internal/scheduler"
+	"linkedin-automation/internal/scoring"
 	"linkedin-automation/internal/search"
+	"linkedin-automation/internal/stealth"
 	"linkedin-automation/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/spf13/pflag"
+)
+
+// Build information, injected via -ldflags "-X main.buildTime=... -X main.gitHash=..."
+var (
+	buildTime = "unknown"
+	gitHash   = "unknown"
 )
 
+// cliOptions holds command line flags that override file configuration
+type cliOptions struct {
+	configPath     string
+	headless       bool
+	dryRun         bool
+	api            bool
+	dashboard      bool
+	campaign       string
+	logLevel       string
+	maxConnections int
+	maxMessages    int
+	exportHubSpot  string
+	exportCSV      string
+	importNav      string
+	resetSearch    bool
+	resume         bool
+	report         bool
+	deduplicate    bool
+	simulateTiming bool
+	noCache        bool
+	safeMode       bool
+	listTechniques bool
+	stealthTest    bool
+	version        bool
+}
+
 func main() {
+	fs := pflag.CommandLine
+	opts, err := parseFlags(fs, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if opts.version {
+		fmt.Printf("linkedin-automation (build time: %s, git hash: %s)\n", buildTime, gitHash)
+		return
+	}
+
+	if opts.listTechniques {
+		printTechniques()
+		return
+	}
+
 	// Initialize logger
 	log := logger.Init()
 	log.Info("Starting LinkedIn Automation Bot")
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.Load(opts.configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if err := applyFlagOverrides(fs, opts, cfg); err != nil {
+		log.Fatalf("Invalid command line flags: %v", err)
+	}
+
+	// Registered last so it runs after any other hooks
+	if cfg.Logging.EnableRedaction {
+		if err := logger.EnableRedaction(cfg.Logging.RedactPatterns, cfg.LinkedIn.Password); err != nil {
+			log.Fatalf("Invalid log redaction config: %v", err)
+		}
+	}
+
+	if cfg.DryRun {
+		log.Info("Dry run enabled, no connection requests or messages will be sent")
+	}
+
+	if opts.simulateTiming {
+		printTimingDistribution(cfg)
+		return
+	}
+
+	normalize.SetSynonyms(cfg.Scoring.TitleSynonyms)
+
 	// Initialize storage
-	store, err := storage.New(cfg.Storage.DatabasePath)
+	store, err := storage.New(cfg.Storage.DatabasePath, cfg.Storage.ProfileCacheSize)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 	defer store.Close()
 
+	if fs.Changed("safe-mode") {
+		if err := store.SetSafeMode(opts.safeMode, "command line"); err != nil {
+			log.Fatalf("Failed to set safe mode: %v", err)
+		}
+	}
+	if store.SafeModeActive() {
+		connectionsPerDay, messagesPerDay := cfg.DailyLimits(true)
+		log.Warnf("Safe mode active: %d connections and %d messages per day", connectionsPerDay, messagesPerDay)
+	}
+
+	if opts.resetSearch {
+		for _, target := range cfg.Search.Targets {
+			if err := store.ResetSearchState(search.TargetHash(target)); err != nil {
+				log.Fatalf("Failed to reset search state: %v", err)
+			}
+		}
+		log.Info("Search progress reset, all targets will start from page 1")
+	}
+
+	if opts.report {
+		fmt.Print(report.GenerateStatusReport(store, cfg, scheduler.New(cfg)))
+		return
+	}
+
+	if opts.deduplicate {
+		result, err := store.DeduplicateProfiles()
+		if err != nil {
+			log.Fatalf("Profile deduplication failed: %v", err)
+		}
+		for _, pair := range result.Flagged {
+			log.Warnf("Possible duplicate left for review: %s and %s", pair.ProfileURL, pair.OtherURL)
+		}
+		log.Infof("Merged %d duplicate profiles, flagged %d possible duplicates", result.Merged, len(result.Flagged))
+		return
+	}
+
+	if opts.exportHubSpot != "" {
+		if err := exportProfiles(opts.exportHubSpot, store.ExportHubSpotCSV); err != nil {
+			log.Fatalf("HubSpot export failed: %v", err)
+		}
+		log.Infof("Exported profiles to %s", opts.exportHubSpot)
+		return
+	}
+
+	if opts.exportCSV != "" {
+		if err := exportProfiles(opts.exportCSV, store.ExportProfilesCSV); err != nil {
+			log.Fatalf("CSV export failed: %v", err)
+		}
+		log.Infof("Exported profiles to %s", opts.exportCSV)
+		return
+	}
+
 	// Initialize browser
-	browserCtx, err := browser.New(cfg)
+	browserCtx, err := browser.New(cfg, store)
 	if err != nil {
 		log.Fatalf("Failed to initialize browser: %v", err)
 	}
 	defer browserCtx.Close()
 
+	if opts.stealthTest {
+		failed, err := runStealthTest(browserCtx, cfg)
+		if err != nil {
+			log.Fatalf("Stealth self-test failed: %v", err)
+		}
+		if failed {
+			browserCtx.Close()
+			store.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -51,11 +207,16 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	go func() {
-		<-sigChan
-		log.Info("Received shutdown signal, cleaning up...")
-		cancel()
-	}()
+	go awaitShutdown(sigChan, browserCtx.Actions(), cfg.ShutdownTimeout, cancel)
+
+	go runDailyCleanup(ctx, store, cfg)
+
+	metricsCollector := metrics.New(store)
+	go metricsCollector.Start(ctx)
+
+	if err := browserCtx.MonitorPageMemory(ctx); err != nil {
+		log.Warnf("Failed to start page memory monitoring: %v", err)
+	}
 
 	// Authenticate
 	log.Info("Authenticating with LinkedIn...")
@@ -69,17 +230,90 @@ func main() {
 	searchService := search.New(browserCtx, store, cfg)
 	connectService := connect.New(browserCtx, store, cfg)
 	messageService := message.New(browserCtx, store, cfg)
+	engageService := engage.New(browserCtx, store, cfg)
 	schedulerService := scheduler.New(cfg)
 
+	// Queue Sales Navigator leads so this run connects with them
+	if opts.importNav != "" {
+		if err := importNavigatorLeads(ctx, connectService, opts.importNav); err != nil {
+			log.Fatalf("Failed to import Sales Navigator leads: %v", err)
+		}
+	}
+
+	// Start REST API and dashboard if enabled
+	var apiServer *api.Server
+	if cfg.API.Enabled || cfg.API.Dashboard {
+		apiServer = api.New(store, cfg)
+		apiServer.SetSearchCacheStats(searchService.CacheStats)
+		apiServer.AddBreakerStats(connectService.BreakerStats)
+		apiServer.AddBreakerStats(messageService.BreakerStats)
+		apiServer.SetMetrics(metricsCollector.Snapshot)
+	}
+	if cfg.API.Enabled {
+		go func() {
+			if err := apiServer.Start(ctx); err != nil {
+				log.Errorf("REST API error: %v", err)
+			}
+		}()
+	}
+	if cfg.API.Dashboard {
+		// The page only gets /api/ when the REST API itself is enabled
+		var apiHandler http.Handler
+		if cfg.API.Enabled {
+			apiHandler = apiServer.Handler()
+		}
+		dashboardServer, err := dashboard.New(cfg.API.DashboardAddr, apiHandler)
+		if err != nil {
+			log.Fatalf("Failed to initialize dashboard: %v", err)
+		}
+		go func() {
+			if err := dashboardServer.Start(ctx); err != nil {
+				log.Errorf("Dashboard error: %v", err)
+			}
+		}()
+	}
+
+	// Visit queued profiles in the background, between workflow runs and
+	// only when a run would be allowed to start
+	if cfg.Enrichment.WorkerCount > 0 {
+		searchService.SetEnrichmentGate(func() bool {
+			if apiServer != nil && apiServer.Paused() {
+				return false
+			}
+			return schedulerService.ShouldRun()
+		})
+		go searchService.StartEnrichmentWorkers(logger.WithPhase(ctx, "enrich"))
+	}
+
 	// Main automation loop
 	log.Info("Starting automation workflow...")
+
+	var lastWeeklyReport time.Time
+	errorScreenshots := 0
+	forceResume := opts.resume
 	
 	for {
 		select {
 		case <-ctx.Done():
 			log.Info("Shutting down gracefully...")
+			if err := store.FlushActivityLog(); err != nil {
+				log.Errorf("Failed to flush activity log: %v", err)
+			}
 			return
 		default:
+			if schedulerService.IsWeeklyReportDue(lastWeeklyReport) {
+				if err := generateWeeklyReport(store, cfg); err != nil {
+					log.Errorf("Weekly report failed: %v", err)
+				}
+				lastWeeklyReport = time.Now()
+			}
+
+			if apiServer != nil && apiServer.Paused() {
+				log.Debug("Paused from the dashboard, waiting...")
+				time.Sleep(1 * time.Minute)
+				continue
+			}
+
 			// Check if we should run based on schedule
 			if !schedulerService.ShouldRun() {
 				log.Info("Outside active hours, sleeping...")
@@ -94,12 +328,33 @@ func main() {
 				continue
 			}
 
-			// Execute workflow
-			if err := runWorkflow(ctx, searchService, connectService, messageService, store, cfg); err != nil {
+			// Keep background enrichment off the page until the run is done
+			browserCtx.LockPage()
+
+			// Refresh the session before it expires mid-run
+			if err := authService.VerifySession(ctx); err != nil {
+				log.Warnf("Session check failed, logging in again: %v", err)
+				if err := authService.Login(ctx); err != nil {
+					browserCtx.UnlockPage()
+					log.Errorf("Re-authentication failed: %v", err)
+					time.Sleep(5 * time.Minute)
+					continue
+				}
+			}
+
+			// Execute workflow with a fresh run ID for log correlation
+			runCtx := logger.WithRunID(ctx, uuid.NewString())
+			err := runWorkflowSafely(runCtx, searchService, connectService, messageService, engageService, store, cfg, forceResume)
+			forceResume = false
+			browserCtx.IsolateSession()
+			if err != nil {
 				log.Errorf("Workflow error: %v", err)
+				captureErrorScreenshot(browserCtx, cfg, &errorScreenshots)
+				browserCtx.UnlockPage()
 				time.Sleep(5 * time.Minute)
 				continue
 			}
+			browserCtx.UnlockPage()
 
 			// Wait before next iteration
 			log.Info("Workflow completed, taking a break...")
@@ -113,46 +368,596 @@ func runWorkflow(
 	searchSvc *search.Service,
 	connectSvc *connect.Service,
 	messageSvc *message.Service,
+	engageSvc *engage.Service,
 	store *storage.Storage,
 	cfg *config.Config,
+	forceResume bool,
 ) error {
-	log := logger.Get()
+	log := logger.FromContext(ctx)
+	runID := logger.RunIDFromContext(ctx)
+
+	// Invitations LinkedIn has already expired would otherwise stay pending
+	// and count against withdrawals
+	if _, err := connectSvc.MarkExpiredConnections(ctx); err != nil {
+		log.Warnf("Marking expired connection requests failed: %v", err)
+	}
 
-	// Phase 1: Search for profiles
-	log.Info("Phase 1: Searching for target profiles...")
-	profiles, err := searchSvc.SearchProfiles(ctx)
+	// Pick up an interrupted run instead of searching again
+	checkpoint, err := loadCheckpoint(store, forceResume)
 	if err != nil {
-		return fmt.Errorf("search failed: %w", err)
+		log.Warnf("Failed to load workflow checkpoint: %v", err)
+	}
+
+	var profiles []*storage.Profile
+	if checkpoint != nil {
+		runID = checkpoint.RunID
+		ctx = logger.WithRunID(ctx, runID)
+		log = logger.FromContext(ctx)
+
+		profiles, err = store.GetProfilesByIDs(checkpoint.ProfileIDs)
+		if err != nil {
+			return fmt.Errorf("failed to load checkpoint profiles: %w", err)
+		}
+		log.Infof("Resuming run after %s phase with %d profiles", checkpoint.Phase, len(profiles))
+	} else {
+		// Phase 1: Search for profiles
+		searchCtx := logger.WithPhase(ctx, "search")
+		log = logger.FromContext(searchCtx)
+		log.Info("Phase 1: Searching for target profiles...")
+		profiles, err = searchSvc.SearchProfiles(searchCtx)
+		if err != nil {
+			return fmt.Errorf("search failed: %w", err)
+		}
+		log.Infof("Found %d profiles", len(profiles))
+
+		if cfg.Connection.CrawlPeopleAlsoViewed {
+			queued, err := searchSvc.ProcessDiscoveryQueue(searchCtx)
+			if err != nil {
+				log.Warnf("Discovery queue failed: %v", err)
+			}
+			profiles = append(profiles, queued...)
+		}
+
+		// Leads imported from Sales Navigator wait in the queued state
+		leads, err := store.GetQueuedProfiles(storage.DiscoverySourceNavigatorImport)
+		if err != nil {
+			log.Warnf("Failed to load imported leads: %v", err)
+		}
+		profiles = append(profiles, leads...)
+
+		saveCheckpoint(ctx, store, runID, storage.PhaseSearch, profiles)
+	}
+
+	if checkpoint == nil || checkpoint.Phase == storage.PhaseSearch {
+		if err := runConnectPhase(ctx, connectSvc, engageSvc, cfg, profiles); err != nil {
+			return err
+		}
+		saveCheckpoint(ctx, store, runID, storage.PhaseConnect, profiles)
+	}
+
+	// Phase 3: Send messages to accepted connections
+	messageCtx := logger.WithPhase(ctx, "message")
+	log = logger.FromContext(messageCtx)
+	log.Info("Phase 3: Messaging accepted connections...")
+	if _, err := connectSvc.CheckAcceptances(messageCtx); err != nil {
+		log.Warnf("Acceptance check failed: %v", err)
+	}
+
+	// Withdraw requests left pending too long, now that acceptances are recorded
+	if cfg.Connection.WithdrawAfterDays > 0 {
+		withdrawCtx := logger.WithPhase(ctx, "connect")
+		if _, err := connectSvc.WithdrawStaleConnections(withdrawCtx, cfg.Connection.WithdrawAfterDays, cfg.Connection.MaxWithdrawalsPerRun); err != nil {
+			log.Warnf("Withdrawing stale requests failed: %v", err)
+		}
+	}
+	messaged, err := messageSvc.SendMessages(messageCtx)
+	if err != nil {
+		return fmt.Errorf("messaging failed: %w", err)
+	}
+	log.Infof("Sent %d messages", messaged)
+
+	// Record read receipts for earlier messages
+	if cfg.Messaging.CheckDelivery {
+		if _, err := messageSvc.CheckPendingDeliveries(messageCtx); err != nil {
+			log.Warnf("Delivery check failed: %v", err)
+		}
+	}
+
+	// Estimate whether day-old messages were read
+	if cfg.Messaging.TrackEngagement {
+		if _, err := messageSvc.TrackEngagement(messageCtx); err != nil {
+			log.Warnf("Engagement tracking failed: %v", err)
+		}
+	}
+
+	// Run the auto-responder rules against new replies
+	if len(cfg.Messaging.AutoResponderRules) > 0 {
+		if _, err := messageSvc.CheckReplies(messageCtx); err != nil {
+			log.Warnf("Reply check failed: %v", err)
+		}
+	}
+
+	if err := store.CompleteCheckpoint(runID); err != nil {
+		log.Warnf("Failed to complete workflow checkpoint: %v", err)
+	}
+
+	return nil
+}
+
+// runConnectPhase warms up and follows targets, then sends connection requests
+func runConnectPhase(
+	ctx context.Context,
+	connectSvc *connect.Service,
+	engageSvc *engage.Service,
+	cfg *config.Config,
+	profiles []*storage.Profile,
+) error {
+	// Warm up new profiles by commenting on a recent post before connecting
+	if cfg.Engagement.Enabled {
+		engageCtx := logger.WithPhase(ctx, "engage")
+		if _, err := engageSvc.CommentOnProfiles(engageCtx, profiles); err != nil {
+			return fmt.Errorf("engagement failed: %w", err)
+		}
+	}
+
+	// Endorse a few skills so our name is familiar when the request arrives
+	if cfg.Engagement.SkillEndorsementEnabled {
+		engageCtx := logger.WithPhase(ctx, "engage")
+		if _, err := engageSvc.EndorseProfiles(engageCtx, profiles); err != nil {
+			return fmt.Errorf("skill endorsement failed: %w", err)
+		}
+	}
+
+	// Follow target companies ahead of connecting with their employees
+	if cfg.Engagement.CompanyFollowEnabled {
+		engageCtx := logger.WithPhase(ctx, "engage")
+		if _, err := engageSvc.FollowCompanies(engageCtx); err != nil {
+			return fmt.Errorf("company follow failed: %w", err)
+		}
 	}
-	log.Infof("Found %d profiles", len(profiles))
 
 	// Phase 2: Send connection requests
+	connectCtx := logger.WithPhase(ctx, "connect")
+	log := logger.FromContext(connectCtx)
+
+	if resumeAt, paused := connectSvc.WeeklyLimitPausedUntil(); paused {
+		log.Infof("Weekly invitation limit reached, skipping connection requests until %s", resumeAt.Format("Mon 2006-01-02 15:04"))
+		return nil
+	}
+
+	if connectSvc.IsLowAcceptanceHour(connectCtx) {
+		log.Info("Low acceptance hour, holding connection requests until a better hour")
+		return nil
+	}
+
 	log.Info("Phase 2: Sending connection requests...")
-	sent, err := connectSvc.SendConnectionRequests(ctx, profiles)
+	sent, err := connectSvc.SendConnectionRequests(connectCtx, profiles)
+	if errors.Is(err, connect.ErrWeeklyLimitReached) {
+		log.Infof("Sent %d connection requests before the weekly limit", sent)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("connection requests failed: %w", err)
 	}
 	log.Infof("Sent %d connection requests", sent)
 
-	// Phase 3: Send messages to accepted connections
-	log.Info("Phase 3: Messaging accepted connections...")
-	messaged, err := messageSvc.SendMessages(ctx)
+	// Retry previously failed connection requests whose backoff has elapsed
+	retried, err := connectSvc.ProcessRetryQueue(connectCtx)
+	if errors.Is(err, connect.ErrWeeklyLimitReached) {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("messaging failed: %w", err)
+		return fmt.Errorf("retry queue failed: %w", err)
+	}
+	if retried > 0 {
+		log.Infof("Sent %d connection requests from retry queue", retried)
+	}
+
+	return nil
+}
+
+// loadCheckpoint returns an unfinished run from the last 24 hours, or the
+// most recent one of any age when forced
+func loadCheckpoint(store *storage.Storage, force bool) (*storage.WorkflowCheckpoint, error) {
+	maxAge := 24 * time.Hour
+	if force {
+		maxAge = 0
+	}
+	return store.GetIncompleteCheckpoint(maxAge)
+}
+
+// saveCheckpoint records a completed phase; failures only cost the ability to resume
+func saveCheckpoint(ctx context.Context, store *storage.Storage, runID, phase string, profiles []*storage.Profile) {
+	ids := make([]int64, 0, len(profiles))
+	for _, profile := range profiles {
+		if profile.ID > 0 {
+			ids = append(ids, profile.ID)
+		}
+	}
+
+	if err := store.SaveCheckpoint(runID, phase, ids); err != nil {
+		logger.FromContext(ctx).Warnf("Failed to save %s checkpoint: %v", phase, err)
+	}
+}
+
+// awaitShutdown waits for a shutdown signal, lets in-flight browser actions
+// finish for up to timeout, then cancels the workflow context
+func awaitShutdown(sigChan <-chan os.Signal, actions *browser.ActionState, timeout time.Duration, cancel context.CancelFunc) {
+	log := logger.Get()
+
+	<-sigChan
+	log.Infof("Received shutdown signal, waiting up to %s for in-flight actions...", timeout)
+	if !actions.Drain(timeout) {
+		log.Warn("Timed out waiting for in-flight actions")
+	}
+	log.Info("Cleaning up...")
+	cancel()
+}
+
+// runDailyCleanup performs daily database maintenance until ctx is cancelled
+func runDailyCleanup(ctx context.Context, store *storage.Storage, cfg *config.Config) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		decayScores(store, cfg)
+		extractProfileKeywords(store)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// decayScores lowers the scores of uncontacted profiles as they age
+func decayScores(store *storage.Storage, cfg *config.Config) {
+	log := logger.Get()
+
+	if cfg.Scoring.Decay.HalfLifeDays <= 0 {
+		return
+	}
+
+	now := time.Now()
+	updated, err := store.DecayScores(func(base float64, discoveredAt time.Time) float64 {
+		return scoring.ApplyDecay(base, discoveredAt, cfg.Scoring.Decay, now)
+	})
+	if err != nil {
+		log.Errorf("Score decay failed: %v", err)
+		return
+	}
+
+	log.Infof("Decayed scores of %d stale profiles", updated)
+}
+
+// profileKeywordCount is how many keywords are stored per profile
+const profileKeywordCount = 10
+
+// extractProfileKeywords rebuilds the TF-IDF corpus from every stored profile
+// and stores each profile's top keywords for {{DerivedTopics}}
+func extractProfileKeywords(store *storage.Storage) {
+	log := logger.Get()
+
+	texts, err := store.GetProfileTexts()
+	if err != nil {
+		log.Errorf("Keyword extraction failed: %v", err)
+		return
+	}
+
+	corpus := make([]string, len(texts))
+	for i, text := range texts {
+		corpus[i] = text.Text
+	}
+	normalize.SetKeywordCorpus(corpus)
+
+	keywords := make(map[int64][]storage.ProfileKeyword, len(texts))
+	for _, text := range texts {
+		// The name is part of the document but its words are unique to the
+		// profile and would always rank first, so they're never kept as topics
+		nameWords := strings.Fields(strings.ToLower(text.Name))
+		scored := normalize.ScoreKeywords(text.Text, profileKeywordCount+len(nameWords))
+
+		profileKeywords := make([]storage.ProfileKeyword, 0, profileKeywordCount)
+		for _, keyword := range scored {
+			if len(profileKeywords) == profileKeywordCount {
+				break
+			}
+			if slices.Contains(nameWords, keyword.Word) {
+				continue
+			}
+			profileKeywords = append(profileKeywords, storage.ProfileKeyword{
+				Keyword: keyword.Word,
+				Score:   keyword.Score,
+			})
+		}
+		keywords[text.ProfileID] = profileKeywords
+	}
+
+	if err := store.ReplaceProfileKeywords(keywords); err != nil {
+		log.Errorf("Failed to save profile keywords: %v", err)
+		return
+	}
+
+	log.Infof("Extracted keywords for %d profiles", len(texts))
+}
+
+// runWorkflowSafely runs the workflow, converting a panic into an error so
+// the main loop can record it and continue
+func runWorkflowSafely(
+	ctx context.Context,
+	searchSvc *search.Service,
+	connectSvc *connect.Service,
+	messageSvc *message.Service,
+	engageSvc *engage.Service,
+	store *storage.Storage,
+	cfg *config.Config,
+	forceResume bool,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ctx).Errorf("Workflow panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("workflow panic: %v", r)
+		}
+	}()
+
+	return runWorkflow(ctx, searchSvc, connectSvc, messageSvc, engageSvc, store, cfg, forceResume)
+}
+
+// errorScreenshotDir is where captureErrorScreenshot saves screenshots
+const errorScreenshotDir = "./logs"
+
+// screenshotter saves a screenshot of the current page; *browser.Context
+// implements it
+type screenshotter interface {
+	Screenshot(path string) error
+}
+
+// captureErrorScreenshot saves a screenshot of the current page after a
+// workflow failure, stopping once MaxErrorScreenshots have been taken
+func captureErrorScreenshot(browserCtx screenshotter, cfg *config.Config, count *int) {
+	log := logger.Get()
+
+	if !cfg.Logging.ScreenshotOnError {
+		return
+	}
+
+	if *count >= cfg.Logging.MaxErrorScreenshots {
+		log.Debugf("Error screenshot limit (%d) reached, skipping", cfg.Logging.MaxErrorScreenshots)
+		return
+	}
+
+	// No colons in the name so the file can be saved on Windows
+	path := filepath.Join(errorScreenshotDir, fmt.Sprintf("error_%s.png", time.Now().Format("20060102_150405")))
+	if err := browserCtx.Screenshot(path); err != nil {
+		log.Warnf("Failed to capture error screenshot: %v", err)
+		return
+	}
+
+	*count++
+	log.Infof("Saved error screenshot to %s", path)
+}
+
+// printTimingDistribution prints when connection requests would be sent
+// over the next 24 hours with the configured Poisson rate, plus an hourly histogram
+func printTimingDistribution(cfg *config.Config) {
+	lambda := cfg.Connection.PoissonRateLimit.LambdaPerHour
+	if lambda <= 0 {
+		fmt.Println("connection.poisson_rate_limit.lambda_per_hour is not set")
+		return
+	}
+
+	start := time.Now().Truncate(time.Hour)
+	times := connect.SimulateTimingDistribution(cfg, start, 24*time.Hour)
+
+	perHour := make([]int, 24)
+	for _, t := range times {
+		fmt.Println(t.Format("2006-01-02 15:04:05"))
+		perHour[int(t.Sub(start)/time.Hour)]++
+	}
+
+	fmt.Printf("\n%d requests in 24h (lambda %.2f/h, caps %d/h and %d/day)\n",
+		len(times), lambda, cfg.RateLimits.Connections.PerHour, cfg.RateLimits.Connections.PerDay)
+	for hour, count := range perHour {
+		fmt.Printf("%s %2d %s\n", start.Add(time.Duration(hour)*time.Hour).Format("15:04"), count, strings.Repeat("#", count))
+	}
+}
+
+// exportProfiles writes all stored profiles to a file using the given exporter
+func exportProfiles(path string, export func(io.Writer, storage.ProfileQueryOptions) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	return export(file, storage.ProfileQueryOptions{})
+}
+
+// importNavigatorLeads queues the leads of a Sales Navigator lead list export
+func importNavigatorLeads(ctx context.Context, connectSvc *connect.Service, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open lead list: %w", err)
+	}
+	defer file.Close()
+
+	_, err = connectSvc.ImportNavigatorLeads(ctx, file)
+	return err
+}
+
+// generateWeeklyReport summarizes the past seven days into ./logs and emails
+// the report when configured
+func generateWeeklyReport(store *storage.Storage, cfg *config.Config) error {
+	log := logger.Get()
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -6)
+
+	report, err := store.GenerateWeeklyReport(from, to)
+	if err != nil {
+		return err
+	}
+
+	text := storage.FormatWeeklyReportText(report)
+	html := storage.FormatWeeklyReportHTML(report)
+
+	base := fmt.Sprintf("./logs/weekly_report_%s", to.Format("20060102"))
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	if err := os.WriteFile(base+".txt", []byte(text), 0644); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	if err := os.WriteFile(base+".html", []byte(html), 0644); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+
+	log.Infof("Weekly report saved to %s.txt", base)
+
+	if cfg.Notify.EmailWeeklyReport {
+		mailer := notify.NewSMTPNotifier(cfg.Notify.SMTP)
+		subject := fmt.Sprintf("LinkedIn automation weekly report (%s)", to.Format("2006-01-02"))
+		if err := mailer.SendHTML(subject, html); err != nil {
+			return err
+		}
+		log.Info("Weekly report emailed")
 	}
-	log.Infof("Sent %d messages", messaged)
 
 	return nil
 }
 
+// runStealthTest runs the stealth self-test, prints the report and saves it
+// under ./logs. It reports whether any critical check failed.
+func runStealthTest(browserCtx *browser.Context, cfg *config.Config) (bool, error) {
+	report, err := browserCtx.GetStealth().RunSelfTest(browserCtx.GetPage(), cfg.Stealth.StealthTestURL)
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := report.RanAt.Format("20060102_150405")
+	report.Screenshot = fmt.Sprintf("./logs/stealth_test_%s.png", timestamp)
+	if err := browserCtx.Screenshot(report.Screenshot); err != nil {
+		report.Screenshot = ""
+	}
+
+	fmt.Print(report.String())
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	reportPath := fmt.Sprintf("./logs/stealth_test_%s.json", timestamp)
+	if err := os.MkdirAll(filepath.Dir(reportPath), 0755); err != nil {
+		return false, fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		return false, fmt.Errorf("failed to save report: %w", err)
+	}
+
+	fmt.Printf("Report saved to %s\n", reportPath)
+	return report.HasCriticalFailure(), nil
+}
+
+// printTechniques lists the names accepted in stealth.techniques
+func printTechniques() {
+	for _, technique := range stealth.Techniques() {
+		fmt.Printf("%-14s %s\n", technique.Name, technique.Description)
+	}
+}
+
+// registerFlags defines the command line flags on the given flag set
+func registerFlags(fs *pflag.FlagSet) *cliOptions {
+	opts := &cliOptions{}
+
+	fs.StringVar(&opts.configPath, "config", "config.yaml", "path to the YAML configuration file")
+	fs.BoolVar(&opts.headless, "headless", false, "run the browser in headless mode")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "run the workflow without sending connection requests or messages")
+	fs.BoolVar(&opts.api, "api", false, "start the REST API server")
+	fs.BoolVar(&opts.dashboard, "dashboard", false, "serve the web dashboard on http://127.0.0.1:3000")
+	fs.StringVar(&opts.campaign, "campaign", "", "only run search targets belonging to this campaign")
+	fs.StringVar(&opts.logLevel, "log-level", "", "log level (debug, info, warn, error)")
+	fs.IntVar(&opts.maxConnections, "max-connections", 0, "maximum connection requests per day")
+	fs.IntVar(&opts.maxMessages, "max-messages", 0, "maximum messages per day")
+	fs.StringVar(&opts.exportHubSpot, "export-hubspot", "", "export profiles to a HubSpot CSV file and exit")
+	fs.StringVar(&opts.exportCSV, "export-csv", "", "export profiles with connection notes to a CSV file and exit")
+	fs.StringVar(&opts.importNav, "import-navigator", "", "queue the leads of a Sales Navigator lead list CSV for connecting")
+	fs.BoolVar(&opts.resetSearch, "reset-search", false, "discard saved search progress and start every target from page 1")
+	fs.BoolVar(&opts.resume, "resume", false, "resume the most recent unfinished run regardless of its age")
+	fs.BoolVar(&opts.report, "report", false, "print a status report of outreach progress and exit")
+	fs.BoolVar(&opts.deduplicate, "deduplicate", false, "merge duplicate profiles stored under linked URLs, flag likely duplicates and exit")
+	fs.BoolVar(&opts.simulateTiming, "simulate-timing-distribution", false, "print 24 hours of simulated connection request times and exit")
+	fs.BoolVar(&opts.noCache, "no-cache", false, "bypass the search result cache")
+	fs.BoolVar(&opts.safeMode, "safe-mode", false, "turn safe mode on (or off with --safe-mode=false) and keep it until changed")
+	fs.BoolVar(&opts.listTechniques, "list-techniques", false, "print the available stealth techniques and exit")
+	fs.BoolVar(&opts.stealthTest, "stealth-test", false, "check the browser fingerprint against a bot detection page and exit")
+	fs.BoolVar(&opts.version, "version", false, "print version information and exit")
+
+	return opts
+}
+
+// parseFlags registers the command line flags on fs and parses args, which
+// exclude the program name
+func parseFlags(fs *pflag.FlagSet, args []string) (*cliOptions, error) {
+	opts := registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// applyFlagOverrides applies explicitly set flags on top of the loaded configuration
+func applyFlagOverrides(fs *pflag.FlagSet, opts *cliOptions, cfg *config.Config) error {
+	if fs.Changed("headless") {
+		cfg.Browser.Headless = opts.headless
+	}
+
+	if fs.Changed("dry-run") {
+		cfg.DryRun = opts.dryRun
+	}
+
+	if fs.Changed("api") {
+		cfg.API.Enabled = opts.api
+	}
+
+	if fs.Changed("dashboard") {
+		cfg.API.Dashboard = opts.dashboard
+	}
+
+	if fs.Changed("campaign") {
+		cfg.Campaign = opts.campaign
+	}
+
+	if fs.Changed("log-level") {
+		cfg.Logging.Level = opts.logLevel
+		logger.SetLevel(opts.logLevel)
+	}
+
+	if fs.Changed("max-connections") {
+		cfg.RateLimits.Connections.PerDay = opts.maxConnections
+	}
+
+	if fs.Changed("max-messages") {
+		cfg.RateLimits.Messages.PerDay = opts.maxMessages
+	}
+
+	if fs.Changed("no-cache") {
+		cfg.Search.DisableCache = opts.noCache
+	}
+
+	return cfg.Validate()
+}
+
 func canProceed(store *storage.Storage, cfg *config.Config) bool {
 	stats := store.GetTodayStats()
+	connectionsPerDay, messagesPerDay := cfg.DailyLimits(store.SafeModeActive())
 	
-	if stats.ConnectionsSent >= cfg.RateLimits.Connections.PerDay {
+	if stats.ConnectionsSent >= connectionsPerDay {
 		return false
 	}
 	
-	if stats.MessagesSent >= cfg.RateLimits.Messages.PerDay {
+	if stats.MessagesSent >= messagesPerDay {
 		return false
 	}
 	
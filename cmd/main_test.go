@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+
+	"github.com/spf13/pflag"
+)
+
+// fakeScreenshotter writes a placeholder image instead of capturing a page
+type fakeScreenshotter struct {
+	paths []string
+}
+
+func (f *fakeScreenshotter) Screenshot(path string) error {
+	f.paths = append(f.paths, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte("png"), 0644)
+}
+
+// chdirTemp runs the test from an empty temporary directory so relative
+// paths like ./logs don't touch the source tree
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	t.Setenv("LOG_FILE", filepath.Join(dir, "automation.log"))
+
+	return dir
+}
+
+func TestWorkflowErrorSavesScreenshot(t *testing.T) {
+	dir := chdirTemp(t)
+
+	cfg := &config.Config{}
+	cfg.Logging.ScreenshotOnError = true
+	cfg.Logging.MaxErrorScreenshots = 1
+
+	// A workflow without services panics straight away, which
+	// runWorkflowSafely reports as an error
+	err := runWorkflowSafely(context.Background(), nil, nil, nil, nil, nil, cfg, false)
+	if err == nil {
+		t.Fatal("runWorkflowSafely returned nil, want an error")
+	}
+
+	shooter := &fakeScreenshotter{}
+	count := 0
+	captureErrorScreenshot(shooter, cfg, &count)
+	captureErrorScreenshot(shooter, cfg, &count)
+
+	if count != 1 || len(shooter.paths) != 1 {
+		t.Fatalf("took %d screenshots (count %d), want 1 under MaxErrorScreenshots", len(shooter.paths), count)
+	}
+	path := shooter.paths[0]
+	if strings.ContainsRune(filepath.Base(path), ':') {
+		t.Errorf("screenshot name %q contains a colon", path)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "logs", "error_*.png"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("screenshots in logs = %v (%v), want 1", files, err)
+	}
+}
+
+func TestErrorScreenshotDisabled(t *testing.T) {
+	chdirTemp(t)
+
+	shooter := &fakeScreenshotter{}
+	count := 0
+	captureErrorScreenshot(shooter, &config.Config{}, &count)
+
+	if len(shooter.paths) != 0 {
+		t.Errorf("took %d screenshots with ScreenshotOnError off, want 0", len(shooter.paths))
+	}
+}
+
+// loadFlagConfig parses args on a fresh flag set and applies them on top of
+// the repository's sample config
+func loadFlagConfig(t *testing.T, args ...string) (*cliOptions, *config.Config, error) {
+	t.Helper()
+
+	t.Setenv("LOG_FILE", filepath.Join(t.TempDir(), "automation.log"))
+	t.Setenv("LINKEDIN_EMAIL", "jane@example.com")
+	t.Setenv("LINKEDIN_PASSWORD", "secret")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := config.Load(filepath.Join("..", opts.configPath))
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	return opts, cfg, applyFlagOverrides(fs, opts, cfg)
+}
+
+func TestFlagsOverrideConfig(t *testing.T) {
+	_, defaults, err := loadFlagConfig(t)
+	if err != nil {
+		t.Fatalf("no flags: %v", err)
+	}
+
+	opts, cfg, err := loadFlagConfig(t,
+		"--config=config.yaml",
+		"--headless=true",
+		"--dry-run",
+		"--api",
+		"--campaign=fintech",
+		"--log-level=debug",
+		"--max-connections=7",
+		"--max-messages=3",
+	)
+	if err != nil {
+		t.Fatalf("with flags: %v", err)
+	}
+
+	if !cfg.Browser.Headless || !cfg.DryRun || !cfg.API.Enabled {
+		t.Errorf("headless/dry-run/api = %v/%v/%v, want all true", cfg.Browser.Headless, cfg.DryRun, cfg.API.Enabled)
+	}
+	if cfg.Campaign != "fintech" || cfg.Logging.Level != "debug" {
+		t.Errorf("campaign/log level = %q/%q, want fintech/debug", cfg.Campaign, cfg.Logging.Level)
+	}
+	if cfg.RateLimits.Connections.PerDay != 7 || cfg.RateLimits.Messages.PerDay != 3 {
+		t.Errorf("per-day limits = %d/%d, want 7/3", cfg.RateLimits.Connections.PerDay, cfg.RateLimits.Messages.PerDay)
+	}
+
+	// Flags left unset keep the file's values
+	if cfg.Search.DisableCache != defaults.Search.DisableCache || cfg.API.Dashboard != defaults.API.Dashboard {
+		t.Error("unset flags changed config values")
+	}
+	if opts.version || opts.report {
+		t.Error("unset bool flags parsed as true")
+	}
+}
+
+func TestFalseFlagOverridesConfig(t *testing.T) {
+	_, cfg, err := loadFlagConfig(t, "--headless=false", "--dry-run=false")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if cfg.Browser.Headless || cfg.DryRun {
+		t.Errorf("headless/dry-run = %v/%v, want explicit false to win", cfg.Browser.Headless, cfg.DryRun)
+	}
+}
+
+func TestInvalidFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown flag", []string{"--bogus"}},
+		{"non-numeric limit", []string{"--max-connections=lots"}},
+		{"limit failing validation", []string{"--max-connections=-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := loadFlagConfig(t, tt.args...); err == nil {
+				t.Errorf("args %v accepted, want an error", tt.args)
+			}
+		})
+	}
+}
+
+func TestVersionFlag(t *testing.T) {
+	opts, err := parseFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), []string{"--version"})
+	if err != nil || !opts.version {
+		t.Errorf("--version parsed as %v, %v, want true", opts, err)
+	}
+}
+
+func TestSaveAndLoadCheckpoint(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	// Profiles that were never saved have no ID to resume from
+	profiles := []*storage.Profile{{ID: 4}, {ID: 0}, {ID: 7}}
+	saveCheckpoint(context.Background(), store, "run-1", storage.PhaseSearch, profiles)
+
+	cp, err := loadCheckpoint(store, false)
+	if err != nil {
+		t.Fatalf("loadCheckpoint: %v", err)
+	}
+	if cp == nil || cp.RunID != "run-1" || cp.Phase != storage.PhaseSearch || len(cp.ProfileIDs) != 2 || cp.ProfileIDs[0] != 4 || cp.ProfileIDs[1] != 7 {
+		t.Fatalf("checkpoint = %+v, want run-1 after search with profiles [4 7]", cp)
+	}
+
+	if err := store.CompleteCheckpoint("run-1"); err != nil {
+		t.Fatalf("CompleteCheckpoint: %v", err)
+	}
+	for _, force := range []bool{false, true} {
+		if cp, err := loadCheckpoint(store, force); err != nil || cp != nil {
+			t.Errorf("loadCheckpoint(force %v) after completion = %+v, %v, want nil", force, cp, err)
+		}
+	}
+}
+
+func TestShutdownWaitsForInFlightAction(t *testing.T) {
+	actions := browser.NewActionState()
+	if err := actions.Begin(); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	tornDown := make(chan struct{})
+	go awaitShutdown(sigChan, actions, 5*time.Second, func() {
+		record("teardown")
+		close(tornDown)
+	})
+
+	sigChan <- syscall.SIGTERM
+	for !actions.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := actions.Begin(); !errors.Is(err, browser.ErrDraining) {
+		t.Errorf("Begin while draining = %v, want ErrDraining", err)
+	}
+
+	// Finish typing the message after the signal arrived
+	time.Sleep(50 * time.Millisecond)
+	record("action done")
+	actions.End()
+
+	select {
+	case <-tornDown:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown never tore down")
+	}
+	if want := []string{"action done", "teardown"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestShutdownTimeoutCancelsStuckAction(t *testing.T) {
+	actions := browser.NewActionState()
+	if err := actions.Begin(); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer actions.End()
+
+	sigChan := make(chan os.Signal, 1)
+	sigChan <- syscall.SIGTERM
+
+	start := time.Now()
+	cancelled := false
+	awaitShutdown(sigChan, actions, 50*time.Millisecond, func() { cancelled = true })
+
+	if !cancelled {
+		t.Error("shutdown did not cancel after the timeout")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("shutdown took %s, want about the 50ms timeout", elapsed)
+	}
+}
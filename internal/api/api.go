@@ -0,0 +1,808 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"linkedin-automation/internal/circuit"
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/metrics"
+	"linkedin-automation/internal/normalize"
+	"linkedin-automation/internal/search"
+	"linkedin-automation/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultActivityLimit is how many entries GET /activity returns by default
+const defaultActivityLimit = 50
+
+type Server struct {
+	store *storage.Storage
+	cfg   *config.Config
+	log   *logrus.Logger
+	mux   *http.ServeMux
+
+	// paused is set from the dashboard to hold the automation loop
+	paused atomic.Bool
+
+	// searchCacheStats reports search result cache usage in /health
+	searchCacheStats func() search.CacheStats
+
+	// breakerStats report the circuit breakers in /health
+	breakerStats []func() circuit.BreakerSnapshot
+
+	// metrics serves /metrics from memory
+	metrics func() metrics.Snapshot
+}
+
+func New(store *storage.Storage, cfg *config.Config) *Server {
+	s := &Server{
+		store: store,
+		cfg:   cfg,
+		log:   logger.Get(),
+		mux:   http.NewServeMux(),
+	}
+
+	s.routes()
+	return s
+}
+
+// routes registers all REST endpoints
+func (s *Server) routes() {
+	s.mux.HandleFunc("/profiles", s.handleProfiles)
+	s.mux.HandleFunc("/profiles/", s.handleProfileResource)
+	s.mux.HandleFunc("/blacklist/company", s.handleCompanyBlacklist)
+	s.mux.HandleFunc("/blacklist/company/", s.handleCompanyBlacklistEntry)
+	s.mux.HandleFunc("/companies/aliases", s.handleCompanyAliases)
+	s.mux.HandleFunc("/companies/aliases/", s.handleCompanyAlias)
+	s.mux.HandleFunc("/stats/daily", s.handleDailyStats)
+	s.mux.HandleFunc("/stats/weekly", s.handleWeeklyStats)
+	s.mux.HandleFunc("/stats/expired-connections", s.handleExpiredConnections)
+	s.mux.HandleFunc("/stats/inmail-balance", s.handleInMailBalance)
+	s.mux.HandleFunc("/health", s.handleHealth)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+	s.mux.HandleFunc("/activity", s.handleActivity)
+	s.mux.HandleFunc("/control", s.handleControl)
+	s.mux.HandleFunc("/control/pause", s.handlePause)
+	s.mux.HandleFunc("/control/resume", s.handleResume)
+	s.mux.HandleFunc("/mode/safe", s.handleSafeMode)
+}
+
+// SetSearchCacheStats makes /health report the search result cache
+func (s *Server) SetSearchCacheStats(stats func() search.CacheStats) {
+	s.searchCacheStats = stats
+}
+
+// AddBreakerStats makes /health report a circuit breaker
+func (s *Server) AddBreakerStats(stats func() circuit.BreakerSnapshot) {
+	s.breakerStats = append(s.breakerStats, stats)
+}
+
+// SetMetrics makes /metrics serve the collector's in-memory snapshot
+func (s *Server) SetMetrics(snapshot func() metrics.Snapshot) {
+	s.metrics = snapshot
+}
+
+// Paused reports whether automation was paused through the API
+func (s *Server) Paused() bool {
+	return s.paused.Load()
+}
+
+// Handler returns the HTTP handler serving the REST API
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start serves the REST API until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	addr := s.cfg.API.Addr
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	s.log.Infof("REST API listening on %s", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("api server failed: %w", err)
+	}
+
+	return nil
+}
+
+// handleProfiles serves GET /profiles; ?q= runs a full-text search instead
+// of the filters
+func (s *Server) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	opts := storage.ProfileQueryOptions{
+		OpenToWorkOnly:   query.Get("open_to_work") == "true",
+		HeadlineContains: query.Get("headline"),
+		CompanyContains:  query.Get("company"),
+		Seniority:        normalize.Seniority(query.Get("seniority")),
+		CompanySize:      storage.CompanySize(query.Get("company_size")),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		opts.Limit = n
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+		opts.Offset = n
+	}
+
+	var profiles []storage.Profile
+	var err error
+	if q := query.Get("q"); q != "" {
+		// Full-text search over profile fields and notes, best match first
+		profiles, err = s.store.SearchProfilesFTS(q, opts.Limit)
+	} else {
+		profiles, err = s.store.ListProfiles(opts)
+	}
+	if err != nil {
+		s.log.Errorf("Failed to list profiles: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to list profiles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+// handleProfileResource routes /profiles/{id}/... sub-resources
+func (s *Server) handleProfileResource(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/profiles/"), "/"), "/")
+	if len(parts) != 2 && !(len(parts) == 3 && parts[1] == "notes") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	profileID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid profile id")
+		return
+	}
+
+	if len(parts) == 3 {
+		s.handleProfileNote(w, r, profileID, parts[2])
+		return
+	}
+
+	switch parts[1] {
+	case "related":
+		s.handleRelatedProfiles(w, r, profileID)
+	case "variables":
+		s.handleProfileVariables(w, r, profileID)
+	case "notes":
+		s.handleProfileNotes(w, r, profileID)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleRelatedProfiles serves GET /profiles/{id}/related
+func (s *Server) handleRelatedProfiles(w http.ResponseWriter, r *http.Request, profileID int64) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	profiles, err := s.store.GetRelatedProfiles(profileID, r.URL.Query().Get("type"))
+	if err != nil {
+		s.log.Errorf("Failed to get related profiles: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get related profiles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+// handleProfileVariables serves GET and POST /profiles/{id}/variables. POST
+// takes a JSON object of key/value pairs and sets each one.
+func (s *Server) handleProfileVariables(w http.ResponseWriter, r *http.Request, profileID int64) {
+	profiles, err := s.store.GetProfilesByIDs([]int64{profileID})
+	if err != nil {
+		s.log.Errorf("Failed to load profile %d: %v", profileID, err)
+		writeError(w, http.StatusInternalServerError, "failed to load profile")
+		return
+	}
+	if len(profiles) == 0 {
+		writeError(w, http.StatusNotFound, "profile not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body) == 0 {
+			writeError(w, http.StatusBadRequest, "body must be a JSON object of variables")
+			return
+		}
+
+		for key := range body {
+			if !storage.ValidVariableKey(key) {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid variable key %q", key))
+				return
+			}
+		}
+
+		for key, value := range body {
+			if err := s.store.SetVariable(profileID, key, value); err != nil {
+				s.log.Errorf("Failed to set variable %s: %v", key, err)
+				writeError(w, http.StatusInternalServerError, "failed to set variables")
+				return
+			}
+		}
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	vars, err := s.store.GetVariables(profileID)
+	if err != nil {
+		s.log.Errorf("Failed to get variables: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get variables")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, vars)
+}
+
+// noteBody is the JSON body of POST /profiles/{id}/notes and PUT /profiles/{id}/notes/{noteID}
+type noteBody struct {
+	Content string `json:"content"`
+}
+
+// decodeNote reads a note body, requiring non-empty content
+func decodeNote(r *http.Request) (string, bool) {
+	var body noteBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Content) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(body.Content), true
+}
+
+// handleProfileNotes serves GET and POST /profiles/{id}/notes
+func (s *Server) handleProfileNotes(w http.ResponseWriter, r *http.Request, profileID int64) {
+	profiles, err := s.store.GetProfilesByIDs([]int64{profileID})
+	if err != nil {
+		s.log.Errorf("Failed to load profile %d: %v", profileID, err)
+		writeError(w, http.StatusInternalServerError, "failed to load profile")
+		return
+	}
+	if len(profiles) == 0 {
+		writeError(w, http.StatusNotFound, "profile not found")
+		return
+	}
+
+	status := http.StatusOK
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		content, ok := decodeNote(r)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "content is required")
+			return
+		}
+
+		if err := s.store.AddNote(profileID, content); err != nil {
+			s.log.Errorf("Failed to add note: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to add note")
+			return
+		}
+		status = http.StatusCreated
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	notes, err := s.store.GetNotes(profileID)
+	if err != nil {
+		s.log.Errorf("Failed to get notes: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get notes")
+		return
+	}
+
+	writeJSON(w, status, notes)
+}
+
+// handleProfileNote serves PUT and DELETE /profiles/{id}/notes/{noteID}
+func (s *Server) handleProfileNote(w http.ResponseWriter, r *http.Request, profileID int64, rawNoteID string) {
+	noteID, err := strconv.ParseInt(rawNoteID, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		content, ok := decodeNote(r)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "content is required")
+			return
+		}
+		err = s.store.UpdateNote(profileID, noteID, content)
+	case http.MethodDelete:
+		err = s.store.DeleteNote(profileID, noteID)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if errors.Is(err, storage.ErrNoteNotFound) {
+		writeError(w, http.StatusNotFound, "note not found")
+		return
+	}
+	if err != nil {
+		s.log.Errorf("Failed to change note %d: %v", noteID, err)
+		writeError(w, http.StatusInternalServerError, "failed to change note")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCompanyBlacklist serves GET and POST /blacklist/company
+func (s *Server) handleCompanyBlacklist(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		names, err := s.store.GetBlacklistedCompanies()
+		if err != nil {
+			s.log.Errorf("Failed to list company blacklist: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to list company blacklist")
+			return
+		}
+		writeJSON(w, http.StatusOK, names)
+
+	case http.MethodPost:
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Name) == "" {
+			writeError(w, http.StatusBadRequest, "name is required")
+			return
+		}
+
+		if err := s.store.AddBlacklistedCompany(body.Name); err != nil {
+			s.log.Errorf("Failed to blacklist company: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to blacklist company")
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]string{"name": strings.TrimSpace(body.Name)})
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleCompanyBlacklistEntry serves DELETE /blacklist/company/{name}
+func (s *Server) handleCompanyBlacklistEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	name, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/blacklist/company/"))
+	if err != nil || strings.TrimSpace(name) == "" {
+		writeError(w, http.StatusBadRequest, "invalid company name")
+		return
+	}
+
+	removed, err := s.store.RemoveBlacklistedCompany(name)
+	if err != nil {
+		s.log.Errorf("Failed to remove company from blacklist: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to remove company")
+		return
+	}
+	if !removed {
+		writeError(w, http.StatusNotFound, "company not blacklisted")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCompanyAliases serves GET and POST /companies/aliases
+func (s *Server) handleCompanyAliases(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		aliases, err := s.store.GetCompanyAliases()
+		if err != nil {
+			s.log.Errorf("Failed to list company aliases: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to list company aliases")
+			return
+		}
+		writeJSON(w, http.StatusOK, aliases)
+
+	case http.MethodPost:
+		var body struct {
+			Raw       string `json:"raw"`
+			Canonical string `json:"canonical"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Raw) == "" {
+			writeError(w, http.StatusBadRequest, "raw is required")
+			return
+		}
+		if normalize.NormalizeCompanyName(body.Canonical) == "" {
+			writeError(w, http.StatusBadRequest, "canonical is required")
+			return
+		}
+
+		alias, err := s.store.SetCompanyAlias(body.Raw, body.Canonical)
+		if err != nil {
+			s.log.Errorf("Failed to set company alias: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to set company alias")
+			return
+		}
+		writeJSON(w, http.StatusCreated, alias)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleCompanyAlias serves DELETE /companies/aliases/{raw_name}
+func (s *Server) handleCompanyAlias(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	raw, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/companies/aliases/"))
+	if err != nil || strings.TrimSpace(raw) == "" {
+		writeError(w, http.StatusBadRequest, "invalid company name")
+		return
+	}
+
+	removed, err := s.store.RemoveCompanyAlias(raw)
+	if err != nil {
+		s.log.Errorf("Failed to remove company alias: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to remove company alias")
+		return
+	}
+	if !removed {
+		writeError(w, http.StatusNotFound, "company alias not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDailyStats serves GET /stats/daily?from=YYYY-MM-DD&to=YYYY-MM-DD,
+// defaulting to the last 14 days
+func (s *Server) handleDailyStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -13)
+
+	query := r.URL.Query()
+	for param, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := query.Get(param); value != "" {
+			t, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid "+param+" date")
+				return
+			}
+			*dst = t
+		}
+	}
+
+	if from.After(to) {
+		writeError(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+
+	points, err := s.store.GetDailyStats(from, to)
+	if err != nil {
+		s.log.Errorf("Failed to get daily stats: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get daily stats")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, points)
+}
+
+// handleWeeklyStats serves GET /stats/weekly?weeks=N, defaulting to 4 weeks
+func (s *Server) handleWeeklyStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	weeks := 4
+	if value := r.URL.Query().Get("weeks"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid weeks")
+			return
+		}
+		weeks = n
+	}
+
+	points, err := s.store.GetWeeklyStats(weeks)
+	if err != nil {
+		s.log.Errorf("Failed to get weekly stats: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get weekly stats")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, points)
+}
+
+// handleExpiredConnections serves
+// GET /stats/expired-connections?from=YYYY-MM-DD&to=YYYY-MM-DD, listing
+// requests marked expired in that range, defaulting to the last 30 days
+func (s *Server) handleExpiredConnections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	now := time.Now().UTC()
+	from := now.AddDate(0, 0, -30)
+	to := now
+
+	query := r.URL.Query()
+	if value := query.Get("from"); value != "" {
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid from date")
+			return
+		}
+		from = t
+	}
+	if value := query.Get("to"); value != "" {
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid to date")
+			return
+		}
+		// Include the whole day
+		to = t.Add(24*time.Hour - time.Second)
+	}
+
+	if from.After(to) {
+		writeError(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+
+	connections, err := s.store.GetExpiredConnections(from, to)
+	if err != nil {
+		s.log.Errorf("Failed to get expired connections: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get expired connections")
+		return
+	}
+	if connections == nil {
+		connections = []storage.ConnectionRequest{}
+	}
+
+	writeJSON(w, http.StatusOK, connections)
+}
+
+// handleInMailBalance serves GET /stats/inmail-balance, the InMail credits
+// remaining when last checked
+func (s *Server) handleInMailBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	balance, err := s.store.GetLatestInMailBalance()
+	if err != nil {
+		s.log.Errorf("Failed to get InMail balance: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get InMail balance")
+		return
+	}
+	if balance == nil {
+		writeError(w, http.StatusNotFound, "InMail balance not checked yet")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, balance)
+}
+
+// healthResponse is the body of GET /health
+type healthResponse struct {
+	Status               string             `json:"status"`
+	ProfileCache         storage.CacheStats `json:"profile_cache"`
+	SearchCache          *search.CacheStats `json:"search_cache,omitempty"`
+	EnrichmentQueueDepth int                `json:"enrichment_queue_depth"`
+
+	CircuitBreakers []circuit.BreakerSnapshot `json:"circuit_breakers,omitempty"`
+}
+
+// handleHealth serves GET /health
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	resp := healthResponse{
+		Status:               "ok",
+		ProfileCache:         s.store.GetCacheStats(),
+		EnrichmentQueueDepth: s.store.GetEnrichmentQueueDepth(),
+	}
+	if s.searchCacheStats != nil {
+		stats := s.searchCacheStats()
+		resp.SearchCache = &stats
+	}
+	for _, stats := range s.breakerStats {
+		resp.CircuitBreakers = append(resp.CircuitBreakers, stats())
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleMetrics serves GET /metrics from the in-memory snapshot; it never
+// queries the database
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if s.metrics == nil {
+		writeError(w, http.StatusServiceUnavailable, "metrics not available")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.metrics())
+}
+
+// handleActivity serves GET /activity?limit=N
+func (s *Server) handleActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	limit := defaultActivityLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	entries, err := s.store.GetRecentActivity(limit)
+	if err != nil {
+		s.log.Errorf("Failed to get recent activity: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get recent activity")
+		return
+	}
+	if entries == nil {
+		entries = []storage.ActivityEntry{}
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// controlResponse is the body of the /control endpoints
+type controlResponse struct {
+	Paused bool `json:"paused"`
+}
+
+// handleControl serves GET /control
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, controlResponse{Paused: s.Paused()})
+}
+
+// handlePause serves POST /control/pause
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, true)
+}
+
+// handleResume serves POST /control/resume
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, false)
+}
+
+func (s *Server) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if s.paused.Swap(paused) != paused {
+		if paused {
+			s.log.Info("Automation paused through the API")
+		} else {
+			s.log.Info("Automation resumed through the API")
+		}
+	}
+
+	writeJSON(w, http.StatusOK, controlResponse{Paused: paused})
+}
+
+// handleSafeMode serves GET /mode/safe, POST /mode/safe to turn safe mode on
+// and DELETE /mode/safe to turn it off
+func (s *Server) handleSafeMode(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodDelete:
+		active := r.Method == http.MethodPost
+		if err := s.store.SetSafeMode(active, "api"); err != nil {
+			s.log.Errorf("Failed to set safe mode: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to set safe mode")
+			return
+		}
+		if active {
+			s.log.Warn("Safe mode turned on through the API")
+		} else {
+			s.log.Info("Safe mode turned off through the API")
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	state, err := s.store.GetBotState()
+	if err != nil {
+		s.log.Errorf("Failed to get bot state: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get bot state")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"linkedin-automation/internal/circuit"
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newTestServer returns a server over a fresh database that logs nowhere
+func newTestServer(t *testing.T) (*Server, *storage.Storage) {
+	t.Helper()
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	s := &Server{store: store, cfg: &config.Config{}, log: log, mux: http.NewServeMux()}
+	s.routes()
+	return s, store
+}
+
+// saveProfile saves a profile with the given username and returns its ID
+func saveProfile(t *testing.T, store *storage.Storage, username string) int64 {
+	t.Helper()
+
+	id, err := store.SaveProfile(&storage.Profile{ProfileURL: "https://www.linkedin.com/in/" + username, Name: username})
+	if err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	return id
+}
+
+func TestProfileNoteRequiresMatchingProfile(t *testing.T) {
+	s, store := newTestServer(t)
+
+	owner := saveProfile(t, store, "owner")
+	other := saveProfile(t, store, "other")
+	if err := store.AddNote(owner, "met at the conference"); err != nil {
+		t.Fatalf("AddNote: %v", err)
+	}
+	notes, err := store.GetNotes(owner)
+	if err != nil || len(notes) != 1 {
+		t.Fatalf("GetNotes = %v, %v", notes, err)
+	}
+	noteID := notes[0].ID
+
+	tests := []struct {
+		name    string
+		method  string
+		profile int64
+		want    int
+	}{
+		{"update through other profile", http.MethodPut, other, http.StatusNotFound},
+		{"delete through other profile", http.MethodDelete, other, http.StatusNotFound},
+		{"update through owner", http.MethodPut, owner, http.StatusNoContent},
+		{"delete through owner", http.MethodDelete, owner, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := fmt.Sprintf("/profiles/%d/notes/%d", tt.profile, noteID)
+			req := httptest.NewRequest(tt.method, path, strings.NewReader(`{"content":"updated"}`))
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthReportsCircuitBreakers(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	breaker := circuit.New("connect", circuit.Settings{FailureThreshold: 1})
+	breaker.Execute(func() error { return errors.New("send failed") })
+	s.AddBreakerStats(breaker.Stats)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var resp healthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.CircuitBreakers) != 1 {
+		t.Fatalf("circuit_breakers = %+v, want one breaker", resp.CircuitBreakers)
+	}
+	if got := resp.CircuitBreakers[0]; got.Name != "connect" || got.State != "open" || got.ConsecutiveFailures != 1 {
+		t.Errorf("breaker = %+v, want connect open after 1 failure", got)
+	}
+}
+
+func TestListProfilesOpenToWork(t *testing.T) {
+	s, store := newTestServer(t)
+
+	saveProfile(t, store, "employed")
+	if _, err := store.SaveProfile(&storage.Profile{ProfileURL: "https://www.linkedin.com/in/looking", Name: "looking", OpenToWork: true}); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"employed", "looking"}},
+		{"?open_to_work=true", []string{"looking"}},
+		{"?open_to_work=false", []string{"employed", "looking"}},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/profiles"+tt.query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /profiles%s status = %d", tt.query, rec.Code)
+		}
+
+		var profiles []storage.Profile
+		if err := json.NewDecoder(rec.Body).Decode(&profiles); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		var names []string
+		for _, profile := range profiles {
+			names = append(names, profile.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("GET /profiles%s = %v, want %v", tt.query, names, tt.want)
+		}
+	}
+}
+
+func TestInMailBalance(t *testing.T) {
+	s, store := newTestServer(t)
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats/inmail-balance", nil))
+		return rec
+	}
+
+	if rec := get(); rec.Code != http.StatusNotFound {
+		t.Errorf("status before a balance check = %d, want 404", rec.Code)
+	}
+
+	if err := store.SaveInMailBalance(17); err != nil {
+		t.Fatalf("SaveInMailBalance: %v", err)
+	}
+	rec := get()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var balance storage.InMailBalance
+	if err := json.NewDecoder(rec.Body).Decode(&balance); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if balance.Balance != 17 || balance.CheckedAt.IsZero() {
+		t.Errorf("balance = %+v, want 17 with a check time", balance)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/stats/inmail-balance", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
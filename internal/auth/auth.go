@@ -1,54 +1,93 @@
 package auth
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"linkedin-automation/internal/browser"
 	"linkedin-automation/internal/config"
 	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/notify"
+	"linkedin-automation/internal/stealth"
 	"linkedin-automation/internal/storage"
 
-	"github.com/sirupsen/logrus"
+	"github.com/go-rod/rod"
+)
+
+// Login, verification and logout selectors, most specific first. Fallbacks
+// cover the alternate markup LinkedIn serves on some login pages.
+var (
+	emailInputSelectors    = []string{"#username", "input[name='session_key']"}
+	passwordInputSelectors = []string{"#password", "input[name='session_password']"}
+	submitButtonSelectors  = []string{"button[type='submit']", "button[data-litms-control-urn='login-submit']"}
+	pinInputSelectors      = []string{"input[name='pin']", "#input__email_verification_pin", "input[autocomplete='one-time-code']"}
+	meButtonSelectors      = []string{"button.global-nav__primary-link--me", "button.global-nav__primary-link-me-menu-trigger"}
+	signOutSelectors       = []string{"a[href*='logout']", "a[data-control-name='nav.settings_signout']"}
 )
 
 type Service struct {
-	browser *browser.Context
-	store   *storage.Storage
-	cfg     *config.Config
-	log     *logrus.Logger
+	browser  *browser.Context
+	store    *storage.Storage
+	cfg      *config.Config
+	stealth  *stealth.Stealth
+	notifier *notify.SlackNotifier
 }
 
 func New(browser *browser.Context, store *storage.Storage, cfg *config.Config) *Service {
-	return &Service{
+	s := &Service{
 		browser: browser,
 		store:   store,
 		cfg:     cfg,
-		log:     logger.Get(),
+		stealth: browser.NewStealth("auth"),
+	}
+
+	if webhook := cfg.Notify.CAPTCHAWebhookURL; notify.IsSlackWebhook(webhook) {
+		s.notifier = notify.NewSlackNotifier(webhook, cfg.Notify.Slack)
 	}
+
+	// Navigate has no caller context to pass on
+	browser.SetVerificationHandler(func(page *rod.Page) (bool, error) {
+		return s.HandleAlmostThereVerification(context.Background(), page)
+	})
+
+	return s
 }
 
 // Login authenticates with LinkedIn
 func (s *Service) Login(ctx context.Context) error {
-	s.log.Info("Starting LinkedIn authentication...")
+	log := logger.FromContext(ctx)
+
+	log.Info("Starting LinkedIn authentication...")
+
+	// Don't carry storage from an earlier session into the login
+	s.browser.IsolateSession()
+
+	// Build up some browsing history before touching LinkedIn
+	if err := s.stealth.SimulateBrowsingHistory(ctx, s.browser.GetPage()); err != nil {
+		log.Warnf("Browsing preamble failed: %v", err)
+	}
 
 	// Try to load existing cookies first
 	cookiePath := s.cfg.Storage.CookiePath
 	if err := s.browser.LoadCookies(cookiePath); err == nil {
-		s.log.Info("Loaded existing cookies, checking session...")
+		log.Info("Loaded existing cookies, checking session...")
 
 		// Navigate to LinkedIn to check if session is valid
 		if err := s.browser.Navigate("https://www.linkedin.com/feed/"); err == nil {
 			// Check if we're logged in
 			if s.isLoggedIn() {
-				s.log.Info("Session is valid, skipping login")
+				log.Info("Session is valid, skipping login")
+				s.recordSessionStart(ctx, false)
 				return nil
 			}
 		}
 	}
 
-	s.log.Info("No valid session found, performing fresh login...")
+	log.Info("No valid session found, performing fresh login...")
 
 	// Navigate to LinkedIn login page
 	if err := s.browser.Navigate("https://www.linkedin.com/login"); err != nil {
@@ -56,52 +95,57 @@ func (s *Service) Login(ctx context.Context) error {
 	}
 
 	page := s.browser.GetPage()
-	stealth := s.browser.GetStealth()
+	stealth := s.stealth
 
 	// Wait for login form
-	emailInput, err := stealth.WaitForElement(page, "#username", 10*time.Second)
+	emailInput, err := stealth.WaitForElement(page, emailInputSelectors, 10*time.Second)
 	if err != nil {
 		return fmt.Errorf("email input not found: %w", err)
 	}
 
 	// Type email with human-like behavior
-	s.log.Info("Entering email...")
-	if err := stealth.HumanType(emailInput, s.cfg.LinkedIn.Email); err != nil {
+	log.Info("Entering email...")
+	if err := stealth.HumanType(ctx, emailInput, s.cfg.LinkedIn.Email); err != nil {
 		return fmt.Errorf("failed to enter email: %w", err)
 	}
 
 	stealth.RandomDelay("action")
 
 	// Find password input
-	passwordInput, err := stealth.WaitForElement(page, "#password", 5*time.Second)
+	passwordInput, err := stealth.WaitForElement(page, passwordInputSelectors, 5*time.Second)
 	if err != nil {
 		return fmt.Errorf("password input not found: %w", err)
 	}
 
 	// Type password with human-like behavior
-	s.log.Info("Entering password...")
-	if err := stealth.HumanType(passwordInput, s.cfg.LinkedIn.Password); err != nil {
+	log.Info("Entering password...")
+	if err := stealth.HumanType(ctx, passwordInput, s.cfg.LinkedIn.Password); err != nil {
 		return fmt.Errorf("failed to enter password: %w", err)
 	}
 
 	stealth.RandomDelay("think")
 
 	// Find and click login button
-	loginButton, err := stealth.WaitForElement(page, "button[type='submit']", 5*time.Second)
+	loginButton, err := stealth.WaitForElement(page, submitButtonSelectors, 5*time.Second)
 	if err != nil {
 		return fmt.Errorf("login button not found: %w", err)
 	}
 
-	s.log.Info("Clicking login button...")
-	if err := stealth.HumanClick(loginButton); err != nil {
+	log.Info("Clicking login button...")
+	if err := stealth.HumanClick(ctx, loginButton); err != nil {
 		return fmt.Errorf("failed to click login: %w", err)
 	}
 
 	// Wait for navigation
-	time.Sleep(5 * time.Second)
+	if err := page.WaitLoad(); err != nil {
+		return fmt.Errorf("login page load failed: %w", err)
+	}
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
 
 	// Check for common login issues
-	if err := s.checkLoginIssues(); err != nil {
+	if err := s.checkLoginIssues(ctx); err != nil {
 		return err
 	}
 
@@ -112,19 +156,56 @@ func (s *Service) Login(ctx context.Context) error {
 		return fmt.Errorf("login verification failed")
 	}
 
-	s.log.Info("Login successful!")
+	log.Info("Login successful!")
 
 	// Save cookies for future use
 	if err := s.browser.SaveCookies(cookiePath); err != nil {
-		s.log.Warnf("Failed to save cookies: %v", err)
+		log.Warnf("Failed to save cookies: %v", err)
 	}
 
+	s.recordSessionStart(ctx, true)
+
 	// Log activity
 	s.store.LogActivity("login", "https://www.linkedin.com", "success", "")
 
 	return nil
 }
 
+// recordSessionStart stores when the session was created. Reused sessions
+// of unknown age are treated as starting now.
+func (s *Service) recordSessionStart(ctx context.Context, fresh bool) {
+	log := logger.FromContext(ctx)
+
+	if !fresh {
+		createdAt, err := s.store.GetSessionCreatedAt()
+		if err != nil || !createdAt.IsZero() {
+			return
+		}
+	}
+
+	if err := s.store.SaveSessionCreatedAt(time.Now()); err != nil {
+		log.Warnf("Failed to record session start: %v", err)
+	}
+}
+
+// sessionNeedsRefresh reports whether the session is older than the
+// configured maximum age
+func (s *Service) sessionNeedsRefresh(now time.Time) (bool, error) {
+	if !s.cfg.Auth.ProactiveRefresh || s.cfg.Auth.SessionMaxAgeHours <= 0 {
+		return false, nil
+	}
+
+	createdAt, err := s.store.GetSessionCreatedAt()
+	if err != nil {
+		return false, fmt.Errorf("failed to get session age: %w", err)
+	}
+	if createdAt.IsZero() {
+		return false, nil
+	}
+
+	return now.Sub(createdAt) >= time.Duration(s.cfg.Auth.SessionMaxAgeHours)*time.Hour, nil
+}
+
 // isLoggedIn checks if the user is currently logged in
 func (s *Service) isLoggedIn() bool {
 	page := s.browser.GetPage()
@@ -140,42 +221,84 @@ func (s *Service) isLoggedIn() bool {
 	}
 
 	// Check if we're on the feed page
-	currentURL := page.MustInfo().URL
-	if currentURL == "https://www.linkedin.com/feed/" {
-		return true
+	info, err := page.Info()
+	if err != nil {
+		return false
 	}
 
-	return false
+	return info.URL == "https://www.linkedin.com/feed/"
 }
 
 // checkLoginIssues checks for common login issues
-func (s *Service) checkLoginIssues() error {
+func (s *Service) checkLoginIssues(ctx context.Context) error {
 	page := s.browser.GetPage()
 
+	if _, err := s.HandleAlmostThereVerification(ctx, page); err != nil {
+		return err
+	}
+
 	// Check for CAPTCHA
 	if s.browser.IsElementPresent("#captcha-internal") {
 		s.browser.Screenshot("./logs/captcha_detected.png")
 		s.store.LogActivity("login", "https://www.linkedin.com", "captcha", "CAPTCHA detected")
-		return fmt.Errorf("CAPTCHA detected - manual intervention required")
+		s.enterSafeMode(ctx, "CAPTCHA at login")
+
+		if s.notifier == nil {
+			return fmt.Errorf("CAPTCHA detected - manual intervention required")
+		}
+		if _, err := s.requestIntervention(ctx, "CAPTCHA detected on LinkedIn login. Solve it in the browser window, then reply `done`."); err != nil {
+			return fmt.Errorf("CAPTCHA detected - manual intervention failed: %w", err)
+		}
+		if s.browser.IsElementPresent("#captcha-internal") {
+			return fmt.Errorf("CAPTCHA still present after manual intervention")
+		}
+	}
+
+	// Check for SMS verification, which can precede an app-based 2FA step
+	if s.cfg.LinkedIn.SMSVerificationEnabled && s.isSMSChallenge() {
+		s.browser.Screenshot("./logs/sms_verification.png")
+		s.store.LogActivity("login", "https://www.linkedin.com", "sms", "SMS verification required")
+
+		code, err := s.requestSMSCode(ctx)
+		if err != nil {
+			return fmt.Errorf("SMS verification required - failed to get code: %w", err)
+		}
+		if err := s.submitVerificationCode(ctx, code); err != nil {
+			return err
+		}
 	}
 
 	// Check for 2FA/verification
 	if s.browser.IsElementPresent("input[name='pin']") {
 		s.browser.Screenshot("./logs/2fa_detected.png")
 		s.store.LogActivity("login", "https://www.linkedin.com", "2fa", "2FA verification required")
-		return fmt.Errorf("2FA verification required - manual intervention needed")
+
+		if s.notifier == nil {
+			return fmt.Errorf("2FA verification required - manual intervention needed")
+		}
+		code, err := s.requestIntervention(ctx, "LinkedIn is asking for a 2FA verification code. Reply with the code.")
+		if err != nil {
+			return fmt.Errorf("2FA verification required - manual intervention failed: %w", err)
+		}
+		if err := s.submitVerificationCode(ctx, code); err != nil {
+			return err
+		}
 	}
 
 	// Check for security challenge
 	if s.browser.IsElementPresent(".challenge-dialog") {
 		s.browser.Screenshot("./logs/security_challenge.png")
 		s.store.LogActivity("login", "https://www.linkedin.com", "challenge", "Security challenge detected")
+		s.enterSafeMode(ctx, "security challenge at login")
 		return fmt.Errorf("security challenge detected - manual intervention required")
 	}
 
 	// Check for incorrect credentials
-	currentURL := page.MustInfo().URL
-	if currentURL == "https://www.linkedin.com/login" || currentURL == "https://www.linkedin.com/uas/login-submit" {
+	info, err := page.Info()
+	if err != nil {
+		return fmt.Errorf("failed to get page info: %w", err)
+	}
+	if currentURL := info.URL; currentURL == "https://www.linkedin.com/login" || currentURL == "https://www.linkedin.com/uas/login-submit" {
 		// Still on login page, check for error messages
 		if s.browser.IsElementPresent(".form__label--error") {
 			s.browser.Screenshot("./logs/login_error.png")
@@ -187,12 +310,144 @@ func (s *Service) checkLoginIssues() error {
 	return nil
 }
 
+// enterSafeMode turns on safe mode after LinkedIn challenged the account,
+// so the following runs stay under the SafeMode limits
+func (s *Service) enterSafeMode(ctx context.Context, reason string) {
+	log := logger.FromContext(ctx)
+
+	if s.store.SafeModeActive() {
+		return
+	}
+
+	if err := s.store.SetSafeMode(true, reason); err != nil {
+		log.Errorf("Failed to enable safe mode: %v", err)
+		return
+	}
+
+	log.Warnf("Safe mode enabled after %s; turn it off with --safe-mode=false or DELETE /mode/safe", reason)
+}
+
+// isSMSChallenge reports whether the verification page asks for a code sent
+// by text message rather than one from an authenticator app
+func (s *Service) isSMSChallenge() bool {
+	page := s.browser.GetPage()
+
+	if !s.browser.IsElementPresent("input[name='pin']") {
+		return false
+	}
+
+	has, body, err := page.Has("body")
+	if err != nil || !has {
+		return false
+	}
+	text, err := body.Text()
+	if err != nil {
+		return false
+	}
+
+	text = strings.ToLower(text)
+	return strings.Contains(text, "phone") || strings.Contains(text, "sms")
+}
+
+// requestSMSCode fetches the SMS code from the configured webhook, or prompts
+// for it on stdout when no webhook is set
+func (s *Service) requestSMSCode(ctx context.Context) (string, error) {
+	log := logger.FromContext(ctx)
+
+	timeout := s.cfg.LinkedIn.SMSTimeout
+	if timeout <= 0 {
+		timeout = 3 * time.Minute
+	}
+
+	if s.cfg.LinkedIn.SMSWebhookURL != "" {
+		log.Infof("Waiting up to %s for SMS code from webhook", timeout)
+		return notify.RequestSMSCode(ctx, s.cfg.LinkedIn.SMSWebhookURL, s.cfg.LinkedIn.Email, timeout)
+	}
+
+	fmt.Printf("LinkedIn sent a verification code by SMS for %s. Enter it: ", s.cfg.LinkedIn.Email)
+
+	lines := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		lines <- strings.TrimSpace(line)
+	}()
+
+	select {
+	case code := <-lines:
+		if code == "" {
+			return "", notify.ErrNoSMSCode
+		}
+		return code, nil
+	case <-time.After(timeout):
+		return "", notify.ErrAcknowledgmentTimeout
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// requestIntervention asks the operator for help over Slack and waits for a reply
+func (s *Service) requestIntervention(ctx context.Context, prompt string) (string, error) {
+	log := logger.FromContext(ctx)
+
+	log.Warnf("Waiting for manual intervention: %s", prompt)
+	reply, err := s.notifier.WaitForAcknowledgment(ctx, prompt, s.cfg.Notify.InterventionTimeoutMinutes)
+	if err != nil {
+		s.store.LogActivity("login", "https://www.linkedin.com", "intervention_failed", err.Error())
+		return "", err
+	}
+
+	log.Info("Manual intervention acknowledged")
+	s.store.LogActivity("login", "https://www.linkedin.com", "intervention", "")
+
+	return reply, nil
+}
+
+// submitVerificationCode types a 2FA code into the verification form and submits it
+func (s *Service) submitVerificationCode(ctx context.Context, code string) error {
+	page := s.browser.GetPage()
+	stealth := s.stealth
+
+	pinInput, err := stealth.WaitForElement(page, pinInputSelectors, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("verification input not found: %w", err)
+	}
+
+	if err := stealth.HumanType(ctx, pinInput, strings.TrimSpace(code)); err != nil {
+		return fmt.Errorf("failed to enter verification code: %w", err)
+	}
+
+	stealth.RandomDelay("think")
+
+	submitButton, err := stealth.WaitForElement(page, submitButtonSelectors, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("verification submit button not found: %w", err)
+	}
+
+	if err := stealth.HumanClick(ctx, submitButton); err != nil {
+		return fmt.Errorf("failed to submit verification code: %w", err)
+	}
+
+	// Wait for navigation
+	if err := page.WaitLoad(); err != nil {
+		return fmt.Errorf("verification page load failed: %w", err)
+	}
+	_ = s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait)
+
+	if s.browser.IsElementPresent("input[name='pin']") {
+		return fmt.Errorf("verification code was rejected")
+	}
+
+	return nil
+}
+
 // Logout logs out from LinkedIn
-func (s *Service) Logout() error {
-	s.log.Info("Logging out from LinkedIn...")
+func (s *Service) Logout(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+
+	log.Info("Logging out from LinkedIn...")
 
 	page := s.browser.GetPage()
-	stealth := s.browser.GetStealth()
+	stealth := s.stealth
 
 	// Navigate to LinkedIn home
 	if err := s.browser.Navigate("https://www.linkedin.com/feed/"); err != nil {
@@ -200,35 +455,58 @@ func (s *Service) Logout() error {
 	}
 
 	// Click on "Me" dropdown
-	meButton, err := stealth.WaitForElement(page, "button.global-nav__primary-link--me", 10*time.Second)
+	meButton, err := stealth.WaitForElement(page, meButtonSelectors, 10*time.Second)
 	if err != nil {
 		return fmt.Errorf("me button not found: %w", err)
 	}
 
-	if err := stealth.HumanClick(meButton); err != nil {
+	if err := stealth.HumanClick(ctx, meButton); err != nil {
 		return fmt.Errorf("failed to click me button: %w", err)
 	}
 
 	stealth.RandomDelay("action")
 
 	// Click sign out
-	signOutButton, err := stealth.WaitForElement(page, "a[href*='logout']", 5*time.Second)
+	signOutButton, err := stealth.WaitForElement(page, signOutSelectors, 5*time.Second)
 	if err != nil {
 		return fmt.Errorf("sign out button not found: %w", err)
 	}
 
-	if err := stealth.HumanClick(signOutButton); err != nil {
+	if err := stealth.HumanClick(ctx, signOutButton); err != nil {
 		return fmt.Errorf("failed to click sign out: %w", err)
 	}
 
-	s.log.Info("Logged out successfully")
+	log.Info("Logged out successfully")
 	s.store.LogActivity("logout", "https://www.linkedin.com", "success", "")
 
 	return nil
 }
 
-// VerifySession verifies that the current session is still valid
-func (s *Service) VerifySession() error {
+// VerifySession verifies that the current session is still valid, logging
+// in again first when it is old enough that LinkedIn may soon expire it
+func (s *Service) VerifySession(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+
+	refresh, err := s.sessionNeedsRefresh(time.Now())
+	if err != nil {
+		log.Warnf("Failed to check session age: %v", err)
+	}
+
+	if refresh {
+		log.Info("Session is older than the configured maximum age, starting proactive session refresh")
+		if err := s.Logout(ctx); err != nil {
+			log.Warnf("Logout before session refresh failed: %v", err)
+		}
+		if err := s.Login(ctx); err != nil {
+			return fmt.Errorf("proactive session refresh failed: %w", err)
+		}
+		// Login keeps the old start time when it reuses saved cookies, which
+		// would make every later check refresh again
+		s.recordSessionStart(ctx, true)
+		s.store.LogActivity("session_refresh", "https://www.linkedin.com", "success", "")
+		return nil
+	}
+
 	if !s.isLoggedIn() {
 		return fmt.Errorf("session is no longer valid")
 	}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+// newSessionTestService returns a service with only the storage and config
+// the session age checks use
+func newSessionTestService(t *testing.T, maxAgeHours int) *Service {
+	t.Helper()
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 10)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{}
+	cfg.Auth.ProactiveRefresh = true
+	cfg.Auth.SessionMaxAgeHours = maxAgeHours
+
+	return &Service{store: store, cfg: cfg}
+}
+
+func TestSessionNeedsRefresh(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		want      bool
+	}{
+		{"no session recorded", time.Time{}, false},
+		{"fresh session", now.Add(-time.Hour), false},
+		{"just under max age", now.Add(-71 * time.Hour), false},
+		{"past max age", now.Add(-73 * time.Hour), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSessionTestService(t, 72)
+			if !tt.createdAt.IsZero() {
+				if err := s.store.SaveSessionCreatedAt(tt.createdAt); err != nil {
+					t.Fatalf("SaveSessionCreatedAt: %v", err)
+				}
+			}
+
+			got, err := s.sessionNeedsRefresh(now)
+			if err != nil {
+				t.Fatalf("sessionNeedsRefresh: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("sessionNeedsRefresh = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionNeedsRefreshDisabled(t *testing.T) {
+	s := newSessionTestService(t, 72)
+	s.cfg.Auth.ProactiveRefresh = false
+	if err := s.store.SaveSessionCreatedAt(time.Now().Add(-100 * time.Hour)); err != nil {
+		t.Fatalf("SaveSessionCreatedAt: %v", err)
+	}
+
+	if got, _ := s.sessionNeedsRefresh(time.Now()); got {
+		t.Error("refresh requested with proactive refresh disabled")
+	}
+}
+
+func TestRecordSessionStart(t *testing.T) {
+	ctx := context.Background()
+	s := newSessionTestService(t, 72)
+	old := time.Now().Add(-100 * time.Hour)
+	if err := s.store.SaveSessionCreatedAt(old); err != nil {
+		t.Fatalf("SaveSessionCreatedAt: %v", err)
+	}
+
+	// A reused session keeps its start time
+	s.recordSessionStart(ctx, false)
+	if refresh, _ := s.sessionNeedsRefresh(time.Now()); !refresh {
+		t.Fatal("reusing the session should keep its age")
+	}
+
+	// A refreshed session starts over
+	s.recordSessionStart(ctx, true)
+	if refresh, _ := s.sessionNeedsRefresh(time.Now()); refresh {
+		t.Error("session still needs a refresh after it was refreshed")
+	}
+}
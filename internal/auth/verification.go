@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/logger"
+
+	"github.com/go-rod/rod"
+)
+
+// ErrVerificationRequired is returned when LinkedIn's "Almost there" human
+// verification page couldn't be passed automatically
+var ErrVerificationRequired = errors.New("human verification required - manual intervention needed")
+
+// almostThereSelector detects the "Almost there" interstitial
+const almostThereSelector = "#almost-there"
+
+// joinFormChallengeSelector detects the challenge variant served inside the
+// join form. The same container is used by regular sign-up forms, so it only
+// counts on checkpoint pages.
+const joinFormChallengeSelector = ".join-form-container"
+
+// checkpointPath is the URL path prefix of LinkedIn's verification pages
+const checkpointPath = "/checkpoint/"
+
+// almostThereButtonText matches the buttons that pass the check without a puzzle
+const almostThereButtonText = `(?i)^\s*(i agree|verify)`
+
+const (
+	// almostThereButtonTimeout is how long to look for an agree/verify button
+	almostThereButtonTimeout = 3 * time.Second
+
+	// almostThereResolveWait is how long LinkedIn gets to move on after the click
+	almostThereResolveWait = 5 * time.Second
+)
+
+// HandleAlmostThereVerification detects the "Almost there" verification page
+// and clicks its "I agree" or "Verify" button. It returns true when the page
+// was shown and passed, false when it wasn't shown, and
+// ErrVerificationRequired when it's still there, e.g. for a puzzle.
+func (s *Service) HandleAlmostThereVerification(ctx context.Context, page *rod.Page) (bool, error) {
+	shown, err := onAlmostTherePage(page)
+	if err != nil || !shown {
+		return false, err
+	}
+
+	log := logger.Get()
+	info, err := page.Info()
+	if err != nil {
+		return false, fmt.Errorf("failed to get page info: %w", err)
+	}
+	pageURL := info.URL
+	log.Warn("LinkedIn \"Almost there\" verification page detected")
+
+	if button, err := page.Timeout(almostThereButtonTimeout).ElementR("button", almostThereButtonText); err == nil {
+		if err := s.stealth.HumanClick(ctx, button); err != nil {
+			log.Debugf("Failed to click verification button: %v", err)
+		} else {
+			time.Sleep(almostThereResolveWait)
+			if shown, err := onAlmostTherePage(page); err == nil && !shown {
+				log.Info("Passed \"Almost there\" verification")
+				s.store.LogActivity("verification", pageURL, "success", "Almost there page passed")
+				return true, nil
+			}
+		}
+	}
+
+	s.browser.Screenshot("./logs/almost_there_verification.png")
+	s.store.LogActivity("verification", pageURL, "failed", "Almost there page not passed")
+	return false, ErrVerificationRequired
+}
+
+// onAlmostTherePage reports whether the page shows the verification interstitial
+func onAlmostTherePage(page *rod.Page) (bool, error) {
+	if has, _, err := page.Has(almostThereSelector); err == nil && has {
+		return true, nil
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		return false, fmt.Errorf("failed to get page info: %w", err)
+	}
+	if !strings.Contains(info.URL, checkpointPath) {
+		return false, nil
+	}
+
+	has, _, err := page.Has(joinFormChallengeSelector)
+	return err == nil && has, nil
+}
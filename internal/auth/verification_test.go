@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// newFixturePage opens a blank page in a headless browser, skipping the test
+// when no browser is installed. CHROME_PATH overrides the browser like in
+// browser.New.
+func newFixturePage(t *testing.T) *rod.Page {
+	t.Helper()
+
+	bin := os.Getenv("CHROME_PATH")
+	if bin == "" {
+		path, found := launcher.LookPath()
+		if !found {
+			t.Skip("no browser installed")
+		}
+		bin = path
+	}
+
+	u, err := launcher.New().Bin(bin).Headless(true).Launch()
+	if err != nil {
+		t.Fatalf("launch browser: %v", err)
+	}
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("connect to browser: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("open page: %v", err)
+	}
+	return page
+}
+
+func TestOnAlmostTherePage(t *testing.T) {
+	fixtures := map[string]string{
+		"interstitial": `<div id="almost-there"><button>I agree</button></div>`,
+		"join-form":    `<div class="join-form-container"><button>Verify</button></div>`,
+		"feed":         `<nav class="global-nav"></nav>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<html><body>%s</body></html>", fixtures[r.URL.Query().Get("fixture")])
+	}))
+	defer server.Close()
+
+	page := newFixturePage(t)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/feed/?fixture=interstitial", true},
+		{"/checkpoint/challenge?fixture=join-form", true},
+		{"/signup?fixture=join-form", false},
+		{"/feed/?fixture=feed", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if err := page.Navigate(server.URL + tt.path); err != nil {
+				t.Fatalf("navigate: %v", err)
+			}
+			if err := page.WaitLoad(); err != nil {
+				t.Fatalf("wait load: %v", err)
+			}
+
+			got, err := onAlmostTherePage(page)
+			if err != nil {
+				t.Fatalf("onAlmostTherePage: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("onAlmostTherePage = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package browser
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDraining is returned by Begin once shutdown has started
+var ErrDraining = errors.New("shutting down, not starting new actions")
+
+// ActionState tracks in-flight browser actions so shutdown can let them
+// finish instead of closing the browser mid-action
+type ActionState struct {
+	ActionWG *sync.WaitGroup
+
+	mu       sync.Mutex
+	draining bool
+}
+
+// NewActionState creates an ActionState that accepts new actions
+func NewActionState() *ActionState {
+	return &ActionState{ActionWG: &sync.WaitGroup{}}
+}
+
+// Begin registers a new action, or returns ErrDraining during shutdown.
+// Every successful Begin must be paired with End.
+func (a *ActionState) Begin() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.draining {
+		return ErrDraining
+	}
+	a.ActionWG.Add(1)
+	return nil
+}
+
+// End marks an action started with Begin as finished
+func (a *ActionState) End() {
+	a.ActionWG.Done()
+}
+
+// Draining reports whether shutdown has started
+func (a *ActionState) Draining() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.draining
+}
+
+// Drain stops new actions from starting and waits up to timeout for the
+// in-flight ones. It returns false if the timeout elapsed first.
+func (a *ActionState) Drain(timeout time.Duration) bool {
+	a.mu.Lock()
+	a.draining = true
+	a.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		a.ActionWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -1,14 +1,21 @@
 package browser
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"linkedin-automation/internal/config"
 	"linkedin-automation/internal/logger"
 	"linkedin-automation/internal/stealth"
+	"linkedin-automation/internal/storage"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
@@ -20,12 +27,42 @@ type Context struct {
 	browser *rod.Browser
 	page    *rod.Page
 	stealth *stealth.Stealth
+	store   *storage.Storage
 	cfg     *config.Config
 	log     *logrus.Logger
+	actions *ActionState
+
+	viewport  stealth.Viewport
+	userAgent string
+
+	// sessionDir is the account's user data directory with
+	// browser.isolated_profiles, "" for a temporary profile
+	sessionDir string
+
+	// verify gets past interstitial verification pages after navigating,
+	// see SetVerificationHandler
+	verify func(page *rod.Page) (bool, error)
+
+	// pageMu gives one caller at a time use of the page, see LockPage
+	pageMu sync.Mutex
+
+	memMu    sync.RWMutex
+	memStats MemorySnapshot
 }
 
+// MemorySnapshot holds the most recent page memory sample
+type MemorySnapshot struct {
+	JSHeapUsedSize  int64
+	JSHeapTotalSize int64
+	SampledAt       time.Time
+	Reloads         int
+}
+
+// memorySampleInterval is how often page memory is sampled
+const memorySampleInterval = 5 * time.Minute
+
 // New creates a new browser context with stealth techniques applied
-func New(cfg *config.Config) (*Context, error) {
+func New(cfg *config.Config, store *storage.Storage) (*Context, error) {
 	log := logger.Get()
 	log.Info("Initializing browser...")
 
@@ -61,6 +98,20 @@ func New(cfg *config.Config) (*Context, error) {
 		l = l.Bin(chromePath)
 	}
 
+	// Give the account its own profile; without an email the launcher's
+	// fresh temporary profile is used
+	sessionDir := ""
+	if cfg.Browser.IsolatedProfiles {
+		sessionDir = SessionDir(cfg.Storage.SessionsDir(), cfg.LinkedIn.Email)
+	}
+	if sessionDir != "" {
+		if err := prepareSessionDir(sessionDir); err != nil {
+			return nil, err
+		}
+		l = l.UserDataDir(sessionDir)
+		log.Infof("Using browser session %s", sessionDir)
+	}
+
 	// Launch browser
 	url, err := l.Launch()
 	if err != nil {
@@ -79,14 +130,22 @@ func New(cfg *config.Config) (*Context, error) {
 	}
 
 	// Set viewport
+	viewport := chooseViewport(cfg.Browser)
 	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
-		Width:             cfg.Browser.Viewport.Width,
-		Height:            cfg.Browser.Viewport.Height,
-		DeviceScaleFactor: 1,
+		Width:             viewport.Width,
+		Height:            viewport.Height,
+		DeviceScaleFactor: viewport.DeviceScaleFactor,
 		Mobile:            false,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to set viewport: %w", err)
 	}
+	log.Infof("Viewport set to %dx%d @%.0fx", viewport.Width, viewport.Height, viewport.DeviceScaleFactor)
+
+	if cfg.Browser.ViewportRandomization {
+		if err := page.SetWindow(randomWindowBounds(viewport)); err != nil {
+			log.Warnf("Failed to position browser window: %v", err)
+		}
+	}
 
 	// Set random user agent
 	userAgent := cfg.Browser.UserAgents[rand.Intn(len(cfg.Browser.UserAgents))]
@@ -99,17 +158,48 @@ func New(cfg *config.Config) (*Context, error) {
 	log.Infof("User agent set to: %s", userAgent)
 
 	// Initialize stealth
-	stealthEngine := stealth.New(cfg)
+	stealthEngine := stealth.New(cfg, "browser")
+	stealthEngine.SetViewport(viewport)
+	stealthEngine.SetUserAgent(userAgent)
+	stealthEngine.SetSafeMode(store.SafeModeActive)
 	if err := stealthEngine.ApplyBrowserStealth(page); err != nil {
 		return nil, fmt.Errorf("failed to apply stealth: %w", err)
 	}
+	stealthEngine.AttachPage(page)
+
+	// Keep navigator.platform and friends in line with the user agent's OS
+	if err := stealthEngine.SpoofPlatform(userAgent); err != nil {
+		return nil, fmt.Errorf("failed to spoof platform: %w", err)
+	}
 
 	ctx := &Context{
 		browser: browser,
 		page:    page,
 		stealth: stealthEngine,
+		store:   store,
 		cfg:     cfg,
 		log:     log,
+		actions: NewActionState(),
+
+		viewport:   viewport,
+		userAgent:  userAgent,
+		sessionDir: sessionDir,
+	}
+
+	if cfg.Browser.IsolatedProfiles {
+		if err := ctx.CleanupOldSessions(cfg.Browser.SessionMaxAgeDays); err != nil {
+			log.Warnf("Failed to clean up old browser sessions: %v", err)
+		}
+	}
+
+	if name := cfg.Browser.NetworkProfile; name != "" {
+		profile, ok := NetworkProfileByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown network profile %q", name)
+		}
+		if err := ctx.ThrottleNetwork(profile); err != nil {
+			return nil, err
+		}
 	}
 
 	log.Info("Browser initialized successfully")
@@ -121,13 +211,54 @@ func (c *Context) GetPage() *rod.Page {
 	return c.page
 }
 
+// NewStealth creates a stealth engine for the given module, attached to the
+// browser page so it can open idle browsing tabs
+func (c *Context) NewStealth(module string) *stealth.Stealth {
+	engine := stealth.New(c.cfg, module)
+	engine.AttachPage(c.page)
+	engine.SetViewport(c.viewport)
+	engine.SetSafeMode(c.store.SafeModeActive)
+	return engine
+}
+
+// LockPage gives the caller exclusive use of the browser page until
+// UnlockPage. The workflow holds it for a whole run, so background work
+// such as enrichment only navigates between runs.
+func (c *Context) LockPage() {
+	c.pageMu.Lock()
+}
+
+// UnlockPage releases the page locked with LockPage
+func (c *Context) UnlockPage() {
+	c.pageMu.Unlock()
+}
+
+// SetVerificationHandler makes Navigate run handler after each page load to
+// get past verification interstitials. The auth service registers itself so
+// the browser doesn't depend on it.
+func (c *Context) SetVerificationHandler(handler func(page *rod.Page) (bool, error)) {
+	c.verify = handler
+}
+
+// Actions returns the in-flight action tracker shared by all services
+func (c *Context) Actions() *ActionState {
+	return c.actions
+}
+
 // GetStealth returns the stealth engine
 func (c *Context) GetStealth() *stealth.Stealth {
 	return c.stealth
 }
 
-// Navigate navigates to a URL with human-like behavior
+// Navigate navigates to a URL with human-like behavior. Profile pages are
+// recorded as outreach visits.
 func (c *Context) Navigate(url string) error {
+	return c.NavigateFor(url, storage.VisitPurposeOutreach)
+}
+
+// NavigateFor navigates like Navigate, recording profile page visits with
+// the given purpose, e.g. storage.VisitPurposeEnrichment
+func (c *Context) NavigateFor(url, purpose string) error {
 	c.log.Infof("Navigating to: %s", url)
 
 	// Think before navigating
@@ -142,35 +273,195 @@ func (c *Context) Navigate(url string) error {
 		return fmt.Errorf("page load failed: %w", err)
 	}
 
+	// Get past LinkedIn's "Almost there" check before reading the page
+	if c.verify != nil {
+		if _, err := c.verify(c.page); err != nil {
+			return fmt.Errorf("verification after navigating to %s: %w", url, err)
+		}
+	}
+
 	// Simulate reading the page
 	c.stealth.SimulateReading(c.page)
 
+	if isProfileURL(url) && c.store != nil {
+		if err := c.store.RecordProfileVisit(url, purpose); err != nil {
+			c.log.Warnf("Failed to record profile visit: %v", err)
+		}
+	}
+
 	return nil
 }
 
-// SaveCookies saves browser cookies to a file
+// VisitedRecently reports whether the profile was opened within the
+// configured revisit window, since repeat visits on the same day look
+// automated
+func (c *Context) VisitedRecently(ctx context.Context, profileURL string) bool {
+	window := c.cfg.Stealth.ProfileRevisitWindowHours
+	if window <= 0 || c.store == nil {
+		return false
+	}
+
+	visited, err := c.store.HasVisitedRecently(profileURL, window)
+	if err != nil {
+		logger.FromContext(ctx).Warnf("Failed to check profile visits: %v", err)
+		return false
+	}
+
+	if visited {
+		logger.FromContext(ctx).Debugf("Profile %s visited within %dh, skipping", profileURL, window)
+	}
+	return visited
+}
+
+// isProfileURL reports whether a URL points at a LinkedIn member profile
+func isProfileURL(rawURL string) bool {
+	return strings.Contains(rawURL, "linkedin.com/in/")
+}
+
+// RequestHeader returns the browser's User-Agent and LinkedIn session
+// cookies as HTTP headers, so requests made outside the browser are treated
+// like the logged in session instead of being sent to the authwall
+func (c *Context) RequestHeader() (http.Header, error) {
+	header := http.Header{}
+	header.Set("User-Agent", c.userAgent)
+
+	cookies, err := c.page.Cookies([]string{"https://www.linkedin.com/"})
+	if err != nil {
+		return header, fmt.Errorf("failed to get cookies: %w", err)
+	}
+
+	pairs := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		if IsLinkedInDomain(cookie.Domain) {
+			pairs = append(pairs, cookie.Name+"="+cookie.Value)
+		}
+	}
+	if len(pairs) > 0 {
+		header.Set("Cookie", strings.Join(pairs, "; "))
+	}
+
+	return header, nil
+}
+
+// SaveCookies saves the page's LinkedIn cookies to a JSON file so the
+// session can be restored with LoadCookies
 func (c *Context) SaveCookies(path string) error {
 	cookies, err := c.page.Cookies([]string{})
 	if err != nil {
 		return fmt.Errorf("failed to get cookies: %w", err)
 	}
 
+	params := linkedInCookieParams(cookies)
+	if err := writeCookieFile(path, params); err != nil {
+		return err
+	}
+
+	c.log.Infof("Saved %d cookies to %s", len(params), path)
+	return nil
+}
+
+// LoadCookies restores cookies saved with SaveCookies. Cookies for domains
+// other than linkedin.com and expired cookies are skipped, and an error is
+// returned when no LinkedIn cookie is left to load.
+func (c *Context) LoadCookies(path string) error {
+	params, err := readCookieFile(path)
+	if err != nil {
+		return err
+	}
+
+	valid, foreign := loadableCookies(params, time.Now())
+	for _, param := range foreign {
+		c.log.Warnf("Skipping saved cookie %s for non-LinkedIn domain %q", param.Name, param.Domain)
+	}
+
+	if len(valid) == 0 {
+		return fmt.Errorf("no valid LinkedIn cookies in %s", path)
+	}
+
+	if err := c.page.SetCookies(valid); err != nil {
+		return fmt.Errorf("failed to set cookies: %w", err)
+	}
+
+	c.log.Infof("Loaded %d cookies from %s", len(valid), path)
+	return nil
+}
+
+// linkedInCookieParams keeps the LinkedIn cookies, the only session worth
+// saving, as parameters for SetCookies
+func linkedInCookieParams(cookies []*proto.NetworkCookie) []*proto.NetworkCookieParam {
+	var linkedIn []*proto.NetworkCookie
+	for _, cookie := range cookies {
+		if IsLinkedInDomain(cookie.Domain) {
+			linkedIn = append(linkedIn, cookie)
+		}
+	}
+
+	params := proto.CookiesToParams(linkedIn)
+	for _, param := range params {
+		// Session cookies are reported with expires -1, which would set
+		// them as already expired
+		if param.Expires <= 0 {
+			param.Expires = 0
+		}
+	}
+
+	return params
+}
+
+// writeCookieFile saves cookies as JSON, readable only by the owner since
+// the file holds a logged in session
+func writeCookieFile(path string, params []*proto.NetworkCookieParam) error {
+	data, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cookies: %w", err)
+	}
+
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Save cookies (in production, use proper JSON marshaling)
-	c.log.Infof("Saved %d cookies to %s", len(cookies), path)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cookies: %w", err)
+	}
+
 	return nil
 }
 
-// LoadCookies loads browser cookies from a file
-func (c *Context) LoadCookies(path string) error {
-	// In production, implement proper cookie loading
-	c.log.Infof("Loading cookies from %s", path)
-	return nil
+// readCookieFile reads cookies saved by writeCookieFile
+func readCookieFile(path string) ([]*proto.NetworkCookieParam, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookies: %w", err)
+	}
+
+	var params []*proto.NetworkCookieParam
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("failed to decode cookies from %s: %w", path, err)
+	}
+
+	return params, nil
+}
+
+// loadableCookies splits saved cookies into the LinkedIn cookies still
+// valid at now and those for other domains. Expired cookies are dropped.
+func loadableCookies(params []*proto.NetworkCookieParam, now time.Time) (valid, foreign []*proto.NetworkCookieParam) {
+	for _, param := range params {
+		if param == nil {
+			continue
+		}
+		if !IsLinkedInDomain(param.Domain) {
+			foreign = append(foreign, param)
+			continue
+		}
+		if param.Expires > 0 && param.Expires.Time().Before(now) {
+			continue
+		}
+		valid = append(valid, param)
+	}
+
+	return valid, foreign
 }
 
 // Screenshot takes a screenshot of the current page
@@ -215,7 +506,185 @@ func (c *Context) IsElementPresent(selector string) bool {
 	return err == nil
 }
 
+// Default timings for WaitForNetworkIdle
+const (
+	NetworkIdleTimeout = 500 * time.Millisecond
+	NetworkIdleMaxWait = 15 * time.Second
+)
+
+// WaitForNetworkIdle blocks until no network requests have been pending for
+// idleTimeout, or returns an error once maxWait has elapsed. Long-lived
+// connections (WebSocket, EventSource) are ignored since they never finish.
+func (c *Context) WaitForNetworkIdle(page *rod.Page, idleTimeout, maxWait time.Duration) error {
+	if err := (proto.NetworkEnable{}).Call(page); err != nil {
+		return fmt.Errorf("failed to enable network events: %w", err)
+	}
+
+	var mu sync.Mutex
+	pending := make(map[proto.NetworkRequestID]struct{})
+	activity := make(chan struct{}, 1)
+
+	signal := func() {
+		select {
+		case activity <- struct{}{}:
+		default:
+		}
+	}
+	done := func(id proto.NetworkRequestID) {
+		mu.Lock()
+		delete(pending, id)
+		mu.Unlock()
+		signal()
+	}
+
+	eventPage, cancel := page.WithCancel()
+	defer cancel()
+
+	go eventPage.EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			if e.Type == proto.NetworkResourceTypeWebSocket || e.Type == proto.NetworkResourceTypeEventSource {
+				return
+			}
+			mu.Lock()
+			pending[e.RequestID] = struct{}{}
+			mu.Unlock()
+			signal()
+		},
+		func(e *proto.NetworkLoadingFinished) { done(e.RequestID) },
+		func(e *proto.NetworkLoadingFailed) { done(e.RequestID) },
+	)()
+
+	deadline := time.NewTimer(maxWait)
+	defer deadline.Stop()
+
+	idle := time.NewTimer(idleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case <-deadline.C:
+			mu.Lock()
+			n := len(pending)
+			mu.Unlock()
+			return fmt.Errorf("network not idle after %s (%d requests pending)", maxWait, n)
+
+		case <-activity:
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(idleTimeout)
+
+		case <-idle.C:
+			mu.Lock()
+			n := len(pending)
+			mu.Unlock()
+			if n == 0 {
+				return nil
+			}
+			idle.Reset(idleTimeout)
+		}
+	}
+}
+
 // WaitForNavigation waits for navigation to complete
 func (c *Context) WaitForNavigation() error {
 	return c.page.WaitLoad()
 }
+
+// MonitorPageMemory samples the page's JS heap in the background and reloads
+// the page when it grows beyond the configured limit
+func (c *Context) MonitorPageMemory(ctx context.Context) error {
+	if c.cfg.Browser.MaxHeapMB <= 0 {
+		return nil
+	}
+
+	if err := (proto.PerformanceEnable{}).Call(c.page); err != nil {
+		return fmt.Errorf("failed to enable performance metrics: %w", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.checkPageMemory(); err != nil {
+					c.log.Warnf("Page memory check failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	c.log.Infof("Page memory monitoring enabled (limit %d MB)", c.cfg.Browser.MaxHeapMB)
+	return nil
+}
+
+// checkPageMemory samples heap metrics and reloads the page if over the limit
+func (c *Context) checkPageMemory() error {
+	result, err := (proto.PerformanceGetMetrics{}).Call(c.page)
+	if err != nil {
+		return fmt.Errorf("failed to get performance metrics: %w", err)
+	}
+
+	snapshot := c.MemoryStats()
+	snapshot.SampledAt = time.Now()
+	for _, metric := range result.Metrics {
+		switch metric.Name {
+		case "JSHeapUsedSize":
+			snapshot.JSHeapUsedSize = int64(metric.Value)
+		case "JSHeapTotalSize":
+			snapshot.JSHeapTotalSize = int64(metric.Value)
+		}
+	}
+
+	limit := int64(c.cfg.Browser.MaxHeapMB) * 1024 * 1024
+	if snapshot.JSHeapUsedSize > limit {
+		c.log.Warnf("JS heap usage %d MB exceeds limit of %d MB, reloading page",
+			snapshot.JSHeapUsedSize/1024/1024, c.cfg.Browser.MaxHeapMB)
+
+		if err := c.reloadPage(); err != nil {
+			return err
+		}
+
+		snapshot.Reloads++
+		c.store.LogActivityAsync("page_reload", "", "memory_limit",
+			fmt.Sprintf("JS heap used %d bytes", snapshot.JSHeapUsedSize))
+	}
+
+	c.memMu.Lock()
+	c.memStats = snapshot
+	c.memMu.Unlock()
+
+	return nil
+}
+
+// reloadPage reloads the page and re-applies stealth. It waits for the page
+// lock so a running workflow isn't reloaded mid-action.
+func (c *Context) reloadPage() error {
+	c.LockPage()
+	defer c.UnlockPage()
+
+	if err := c.page.Reload(); err != nil {
+		return fmt.Errorf("failed to reload page: %w", err)
+	}
+	if err := c.page.WaitLoad(); err != nil {
+		return fmt.Errorf("page load after reload failed: %w", err)
+	}
+	if err := c.stealth.ApplyBrowserStealth(c.page); err != nil {
+		return fmt.Errorf("failed to re-apply stealth: %w", err)
+	}
+	return nil
+}
+
+// MemoryStats returns the latest page memory sample
+func (c *Context) MemoryStats() MemorySnapshot {
+	c.memMu.RLock()
+	defer c.memMu.RUnlock()
+	return c.memStats
+}
@@ -0,0 +1,317 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestVisitedRecently(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{}
+	c := &Context{cfg: cfg, store: store}
+	ctx := context.Background()
+	visited := "https://www.linkedin.com/in/visited"
+	if err := store.RecordProfileVisit(visited, storage.VisitPurposeOutreach); err != nil {
+		t.Fatalf("RecordProfileVisit: %v", err)
+	}
+
+	if c.VisitedRecently(ctx, visited) {
+		t.Error("VisitedRecently = true without a revisit window")
+	}
+
+	cfg.Stealth.ProfileRevisitWindowHours = 24
+	if !c.VisitedRecently(ctx, visited) {
+		t.Error("VisitedRecently = false for a profile opened just now")
+	}
+	if c.VisitedRecently(ctx, "https://www.linkedin.com/in/other") {
+		t.Error("VisitedRecently = true for a profile never opened")
+	}
+}
+
+// cookieNames returns the names of cookies in order
+func cookieNames(params []*proto.NetworkCookieParam) []string {
+	var names []string
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
+func TestCookieFileRoundTrip(t *testing.T) {
+	now := time.Now()
+	later := proto.TimeSinceEpoch(now.Add(24 * time.Hour).Unix())
+	earlier := proto.TimeSinceEpoch(now.Add(-time.Hour).Unix())
+
+	// As reported by the browser, session cookies have expires -1
+	cookies := []*proto.NetworkCookie{
+		{Name: "li_at", Value: "AQEDAR", Domain: ".www.linkedin.com", Path: "/", Expires: later, HTTPOnly: true, Secure: true},
+		{Name: "JSESSIONID", Value: "ajax:123", Domain: ".www.linkedin.com", Path: "/", Expires: -1, Secure: true},
+		{Name: "lang", Value: "en", Domain: "linkedin.com", Path: "/", Expires: earlier},
+		{Name: "tracker", Value: "x", Domain: ".doubleclick.net", Path: "/", Expires: later},
+	}
+
+	params := linkedInCookieParams(cookies)
+	if got := cookieNames(params); !reflect.DeepEqual(got, []string{"li_at", "JSESSIONID", "lang"}) {
+		t.Fatalf("saved cookies = %v, want only the LinkedIn ones", got)
+	}
+	if params[1].Expires != 0 {
+		t.Errorf("session cookie expires = %v, want 0 so it isn't loaded as expired", params[1].Expires)
+	}
+
+	path := filepath.Join(t.TempDir(), "session", "cookies.json")
+	if err := writeCookieFile(path, params); err != nil {
+		t.Fatalf("writeCookieFile: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatalf("stat: %v", err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("cookie file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, err := readCookieFile(path)
+	if err != nil {
+		t.Fatalf("readCookieFile: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, params) {
+		t.Errorf("read back %+v, want %+v", loaded, params)
+	}
+
+	// A file edited by hand may hold cookies from another site
+	loaded = append(loaded, &proto.NetworkCookieParam{Name: "other", Value: "y", Domain: "evil-linkedin.com", Expires: later}, nil)
+
+	valid, foreign := loadableCookies(loaded, now)
+	if got := cookieNames(valid); !reflect.DeepEqual(got, []string{"li_at", "JSESSIONID"}) {
+		t.Errorf("loadable cookies = %v, want li_at and the session cookie without the expired one", got)
+	}
+	if got := cookieNames(foreign); !reflect.DeepEqual(got, []string{"other"}) {
+		t.Errorf("foreign cookies = %v, want [other]", got)
+	}
+	if valid[0].Value != "AQEDAR" || !valid[0].HTTPOnly || !valid[0].Secure {
+		t.Errorf("li_at = %+v, want its value and flags kept", valid[0])
+	}
+}
+
+func TestReadCookieFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := readCookieFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing file read without an error")
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := readCookieFile(corrupt); err == nil {
+		t.Error("corrupt file read without an error")
+	}
+}
+
+// mockCDP is a CDP client that acknowledges and records every call and lets
+// the test fire events at the page
+type mockCDP struct {
+	events        chan *cdp.Event
+	networkEnable chan struct{}
+
+	mu    sync.Mutex
+	calls []mockCDPCall
+}
+
+// mockCDPCall is a CDP method called on mockCDP with its JSON params
+type mockCDPCall struct {
+	method string
+	params []byte
+}
+
+func (m *mockCDP) Event() <-chan *cdp.Event { return m.events }
+
+func (m *mockCDP) Call(ctx context.Context, sessionID, method string, params interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.calls = append(m.calls, mockCDPCall{method: method, params: encoded})
+	m.mu.Unlock()
+
+	switch method {
+	case "Target.attachToTarget":
+		return []byte(`{"sessionId":"mock-session"}`), nil
+	case "Network.enable":
+		select {
+		case m.networkEnable <- struct{}{}:
+		default:
+		}
+	}
+	return []byte("{}"), nil
+}
+
+// called returns the calls made to method so far
+func (m *mockCDP) called(method string) []mockCDPCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []mockCDPCall
+	for _, call := range m.calls {
+		if call.method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// fire sends an event to the mock page
+func (m *mockCDP) fire(t *testing.T, event proto.Event) {
+	t.Helper()
+
+	params, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("marshal %s: %v", event.ProtoEvent(), err)
+	}
+	m.events <- &cdp.Event{SessionID: "mock-session", Method: event.ProtoEvent(), Params: params}
+}
+
+// newMockCDPPage returns a page backed by mockCDP instead of a browser
+func newMockCDPPage(t *testing.T) (*rod.Page, *mockCDP) {
+	t.Helper()
+
+	mock := &mockCDP{events: make(chan *cdp.Event), networkEnable: make(chan struct{}, 1)}
+	b := rod.New().Client(mock).NoDefaultDevice()
+	if err := b.Connect(); err != nil {
+		t.Fatalf("connect to mock CDP: %v", err)
+	}
+	t.Cleanup(func() { close(mock.events) })
+
+	page, err := b.PageFromTarget("mock-target")
+	if err != nil {
+		t.Fatalf("attach to mock page: %v", err)
+	}
+	return page, mock
+}
+
+// waitForNetworkIdle runs WaitForNetworkIdle in the background and returns
+// once it has subscribed to network events
+func waitForNetworkIdle(t *testing.T, page *rod.Page, mock *mockCDP, idleTimeout, maxWait time.Duration) <-chan error {
+	t.Helper()
+
+	result := make(chan error, 1)
+	go func() { result <- (&Context{}).WaitForNetworkIdle(page, idleTimeout, maxWait) }()
+
+	select {
+	case <-mock.networkEnable:
+	case <-time.After(time.Second):
+		t.Fatal("WaitForNetworkIdle never enabled network events")
+	}
+	// Leave time to subscribe after enabling the domain
+	time.Sleep(50 * time.Millisecond)
+
+	return result
+}
+
+func TestWaitForNetworkIdleReturnsAfterLastRequest(t *testing.T) {
+	const idleTimeout = 200 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		finish proto.Event
+	}{
+		{"loading finished", &proto.NetworkLoadingFinished{RequestID: "2"}},
+		{"loading failed", &proto.NetworkLoadingFailed{RequestID: "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, mock := newMockCDPPage(t)
+			result := waitForNetworkIdle(t, page, mock, idleTimeout, 5*time.Second)
+
+			mock.fire(t, &proto.NetworkRequestWillBeSent{RequestID: "1", Type: proto.NetworkResourceTypeDocument})
+			mock.fire(t, &proto.NetworkRequestWillBeSent{RequestID: "2", Type: proto.NetworkResourceTypeXHR})
+			mock.fire(t, &proto.NetworkLoadingFinished{RequestID: "1"})
+
+			// Request 2 stays pending for longer than the idle timeout
+			select {
+			case err := <-result:
+				t.Fatalf("returned %v with a request pending", err)
+			case <-time.After(3 * idleTimeout):
+			}
+
+			mock.fire(t, tt.finish)
+			finished := time.Now()
+
+			select {
+			case err := <-result:
+				if err != nil {
+					t.Fatalf("WaitForNetworkIdle: %v", err)
+				}
+				if elapsed := time.Since(finished); elapsed < idleTimeout-20*time.Millisecond || elapsed > idleTimeout+300*time.Millisecond {
+					t.Errorf("returned %s after the last request, want about %s", elapsed, idleTimeout)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("WaitForNetworkIdle did not return once the network was idle")
+			}
+		})
+	}
+}
+
+func TestWaitForNetworkIdleIgnoresLongLivedConnections(t *testing.T) {
+	page, mock := newMockCDPPage(t)
+	start := time.Now()
+	result := waitForNetworkIdle(t, page, mock, 200*time.Millisecond, 5*time.Second)
+
+	mock.fire(t, &proto.NetworkRequestWillBeSent{RequestID: "ws", Type: proto.NetworkResourceTypeWebSocket})
+	mock.fire(t, &proto.NetworkRequestWillBeSent{RequestID: "sse", Type: proto.NetworkResourceTypeEventSource})
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("WaitForNetworkIdle: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("returned after %s, want soon after the idle timeout", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitForNetworkIdle waited on a WebSocket")
+	}
+}
+
+func TestWaitForNetworkIdleMaxWait(t *testing.T) {
+	const maxWait = 500 * time.Millisecond
+
+	page, mock := newMockCDPPage(t)
+	start := time.Now()
+	result := waitForNetworkIdle(t, page, mock, 100*time.Millisecond, maxWait)
+
+	mock.fire(t, &proto.NetworkRequestWillBeSent{RequestID: "1", Type: proto.NetworkResourceTypeFetch})
+
+	select {
+	case err := <-result:
+		if err == nil || !strings.Contains(err.Error(), "1 requests pending") {
+			t.Errorf("err = %v, want a timeout with 1 request pending", err)
+		}
+		if elapsed := time.Since(start); elapsed < maxWait || elapsed > maxWait+300*time.Millisecond {
+			t.Errorf("returned after %s, want about %s", elapsed, maxWait)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitForNetworkIdle did not give up after maxWait")
+	}
+}
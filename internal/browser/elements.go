@@ -0,0 +1,38 @@
+package browser
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// findFirstPollInterval is how often FindFirst checks the selectors again
+// while waiting for one of them to match
+const findFirstPollInterval = 250 * time.Millisecond
+
+// ElementFinder is a page or element whose descendants can be looked up;
+// both *rod.Page and *rod.Element implement it
+type ElementFinder interface {
+	Has(selector string) (bool, *rod.Element, error)
+}
+
+// FindFirst returns the first element in parent matching any of the
+// selectors, checking them all again until one matches or timeout has
+// elapsed. A zero timeout checks once. The selector name is included in the
+// error to help track down DOM changes.
+func FindFirst(parent ElementFinder, name string, selectors []string, timeout time.Duration) (*rod.Element, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, selector := range selectors {
+			if has, element, err := parent.Has(selector); err == nil && has {
+				return element, nil
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("selector %s matched no element (tried %q)", name, selectors)
+		}
+		time.Sleep(findFirstPollInterval)
+	}
+}
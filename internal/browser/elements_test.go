@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// fakeFinder matches selector once it has been asked about it appearAfter
+// times, like an element rendered after a delay
+type fakeFinder struct {
+	selector    string
+	appearAfter int
+	calls       map[string]int
+}
+
+func (f *fakeFinder) Has(selector string) (bool, *rod.Element, error) {
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[selector]++
+	if selector == f.selector && f.calls[selector] > f.appearAfter {
+		return true, &rod.Element{}, nil
+	}
+	return false, nil, nil
+}
+
+func TestFindFirstTriesEverySelector(t *testing.T) {
+	finder := &fakeFinder{selector: "button.fallback"}
+
+	element, err := FindFirst(finder, "Button", []string{"button.primary", "button.fallback"}, 0)
+	if err != nil || element == nil {
+		t.Fatalf("FindFirst = %v, %v", element, err)
+	}
+	if finder.calls["button.primary"] != 1 {
+		t.Errorf("primary selector checked %d times, want 1", finder.calls["button.primary"])
+	}
+}
+
+func TestFindFirstWaitsForElement(t *testing.T) {
+	finder := &fakeFinder{selector: "button.modal", appearAfter: 2}
+
+	if _, err := FindFirst(finder, "ModalButton", []string{"button.modal"}, 0); err == nil {
+		t.Fatal("FindFirst without a timeout found an element that wasn't there yet")
+	}
+
+	element, err := FindFirst(finder, "ModalButton", []string{"button.modal"}, 5*time.Second)
+	if err != nil || element == nil {
+		t.Fatalf("FindFirst = %v, %v", element, err)
+	}
+}
+
+func TestFindFirstNamesSelectorInError(t *testing.T) {
+	_, err := FindFirst(&fakeFinder{}, "ConnectButton", []string{"button.connect"}, 0)
+	if err == nil || !strings.Contains(err.Error(), "ConnectButton") {
+		t.Errorf("error = %v, want it to name ConnectButton", err)
+	}
+}
@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// IsolateSession clears page storage and non-LinkedIn cookies when
+// browser.isolate_storage is on, logging rather than returning failures
+func (c *Context) IsolateSession() {
+	if !c.cfg.Browser.IsolateStorage {
+		return
+	}
+
+	if err := c.ClearBrowserStorage(); err != nil {
+		c.log.Warnf("Failed to clear browser storage: %v", err)
+	}
+	if err := c.ClearNonLinkedInCookies(); err != nil {
+		c.log.Warnf("Failed to clear non-LinkedIn cookies: %v", err)
+	}
+}
+
+// ClearBrowserStorage clears localStorage and sessionStorage of the current
+// page's origin, where LinkedIn may keep fingerprinting data between sessions
+func (c *Context) ClearBrowserStorage() error {
+	result, err := c.page.Eval(`() => {
+		// Pages such as about:blank have no storage
+		if (location.origin === "null") return "";
+		localStorage.clear();
+		sessionStorage.clear();
+		return location.origin;
+	}`)
+	if err != nil {
+		c.logActivity("storage_clear", "", "failed", err.Error())
+		return fmt.Errorf("failed to clear storage: %w", err)
+	}
+
+	origin := result.Value.String()
+	if origin == "" {
+		return nil
+	}
+
+	c.log.Debugf("Cleared localStorage and sessionStorage for %s", origin)
+	c.logActivity("storage_clear", origin, "success", "")
+	return nil
+}
+
+// ClearNonLinkedInCookies deletes every cookie whose domain isn't
+// linkedin.com or one of its subdomains
+func (c *Context) ClearNonLinkedInCookies() error {
+	cookies, err := c.browser.GetCookies()
+	if err != nil {
+		return fmt.Errorf("failed to get cookies: %w", err)
+	}
+
+	removed := 0
+	for _, cookie := range cookies {
+		if IsLinkedInDomain(cookie.Domain) {
+			continue
+		}
+
+		err := proto.NetworkDeleteCookies{
+			Name:   cookie.Name,
+			Domain: cookie.Domain,
+			Path:   cookie.Path,
+		}.Call(c.page)
+		if err != nil {
+			c.logActivity("cookie_clear", cookie.Domain, "failed", err.Error())
+			return fmt.Errorf("failed to delete cookie %s for %s: %w", cookie.Name, cookie.Domain, err)
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		c.log.Debugf("Cleared %d non-LinkedIn cookies", removed)
+		c.logActivity("cookie_clear", "", "success", fmt.Sprintf("removed %d cookies", removed))
+	}
+	return nil
+}
+
+// IsLinkedInDomain reports whether a cookie domain is linkedin.com or one of
+// its subdomains
+func IsLinkedInDomain(domain string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	return domain == "linkedin.com" || strings.HasSuffix(domain, ".linkedin.com")
+}
+
+// logActivity records a browser housekeeping event when a store is attached
+func (c *Context) logActivity(actionType, targetURL, outcome, message string) {
+	if c.store != nil {
+		c.store.LogActivityAsync(actionType, targetURL, outcome, message)
+	}
+}
@@ -0,0 +1,190 @@
+package browser
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+func TestIsLinkedInDomain(t *testing.T) {
+	tests := map[string]bool{
+		"linkedin.com":          true,
+		".linkedin.com":         true,
+		"www.linkedin.com":      true,
+		".WWW.LinkedIn.com":     true,
+		"example.com":           false,
+		"notlinkedin.com":       false,
+		"linkedin.com.evil.com": false,
+		"":                      false,
+	}
+	for domain, want := range tests {
+		if got := IsLinkedInDomain(domain); got != want {
+			t.Errorf("IsLinkedInDomain(%q) = %v, want %v", domain, got, want)
+		}
+	}
+}
+
+// newIsolationTestContext opens a headless browser wrapped in a Context
+// that logs to a temporary store, skipping the test when no browser is
+// installed
+func newIsolationTestContext(t *testing.T) (*Context, *storage.Storage) {
+	t.Helper()
+
+	path := os.Getenv("CHROME_PATH")
+	if path == "" {
+		var found bool
+		if path, found = launcher.LookPath(); !found {
+			t.Skip("no browser installed")
+		}
+	}
+
+	u, err := launcher.New().Bin(path).Headless(true).Launch()
+	if err != nil {
+		t.Skipf("failed to launch browser: %v", err)
+	}
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		t.Skipf("failed to connect to browser: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("open page: %v", err)
+	}
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	return &Context{browser: b, page: page, store: store, cfg: &config.Config{}, log: log}, store
+}
+
+func TestClearBrowserStorage(t *testing.T) {
+	c, store := newIsolationTestContext(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html><body>ok</body></html>")
+	}))
+	defer server.Close()
+
+	if err := c.page.Navigate(server.URL); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	if err := c.page.WaitLoad(); err != nil {
+		t.Fatalf("wait load: %v", err)
+	}
+
+	// stored counts the keys in localStorage and sessionStorage
+	stored := func() int {
+		t.Helper()
+		result, err := c.page.Eval(`() => localStorage.length + sessionStorage.length`)
+		if err != nil {
+			t.Fatalf("eval: %v", err)
+		}
+		return result.Value.Int()
+	}
+
+	if _, err := c.page.Eval(`() => {
+		localStorage.setItem("li_fingerprint", "abc123");
+		localStorage.setItem("li_theme", "dark");
+		sessionStorage.setItem("li_session_trace", "xyz");
+	}`); err != nil {
+		t.Fatalf("set storage: %v", err)
+	}
+	if n := stored(); n != 3 {
+		t.Fatalf("stored %d keys before clearing, want 3", n)
+	}
+
+	if err := c.ClearBrowserStorage(); err != nil {
+		t.Fatalf("ClearBrowserStorage: %v", err)
+	}
+	if n := stored(); n != 0 {
+		t.Errorf("%d keys left after ClearBrowserStorage, want 0", n)
+	}
+
+	if err := store.FlushActivityLog(); err != nil {
+		t.Fatalf("FlushActivityLog: %v", err)
+	}
+	entries, err := store.GetRecentActivity(10)
+	if err != nil {
+		t.Fatalf("GetRecentActivity: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ActionType != "storage_clear" || entries[0].TargetURL != server.URL || entries[0].Outcome != "success" {
+		t.Errorf("activity log = %+v, want one successful storage_clear for %s", entries, server.URL)
+	}
+}
+
+func TestClearBrowserStorageOnBlankPage(t *testing.T) {
+	c, store := newIsolationTestContext(t)
+
+	if err := c.ClearBrowserStorage(); err != nil {
+		t.Fatalf("ClearBrowserStorage on about:blank: %v", err)
+	}
+
+	if err := store.FlushActivityLog(); err != nil {
+		t.Fatalf("FlushActivityLog: %v", err)
+	}
+	if entries, _ := store.GetRecentActivity(10); len(entries) != 0 {
+		t.Errorf("activity log = %+v, want nothing logged for a page without storage", entries)
+	}
+}
+
+func TestClearNonLinkedInCookies(t *testing.T) {
+	c, store := newIsolationTestContext(t)
+
+	err := c.browser.SetCookies([]*proto.NetworkCookieParam{
+		{Name: "li_at", Value: "session", Domain: ".linkedin.com", Path: "/"},
+		{Name: "JSESSIONID", Value: "ajax", Domain: "www.linkedin.com", Path: "/"},
+		{Name: "_ga", Value: "tracker", Domain: ".example.com", Path: "/"},
+		{Name: "uid", Value: "ad", Domain: "ads.example.net", Path: "/"},
+	})
+	if err != nil {
+		t.Fatalf("SetCookies: %v", err)
+	}
+
+	if err := c.ClearNonLinkedInCookies(); err != nil {
+		t.Fatalf("ClearNonLinkedInCookies: %v", err)
+	}
+
+	cookies, err := c.browser.GetCookies()
+	if err != nil {
+		t.Fatalf("GetCookies: %v", err)
+	}
+	var names []string
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "JSESSIONID" || names[1] != "li_at" {
+		t.Errorf("cookies left = %v, want only the LinkedIn ones", names)
+	}
+
+	if err := store.FlushActivityLog(); err != nil {
+		t.Fatalf("FlushActivityLog: %v", err)
+	}
+	entries, err := store.GetRecentActivity(10)
+	if err != nil {
+		t.Fatalf("GetRecentActivity: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ActionType != "cookie_clear" || entries[0].ErrorMessage != "removed 2 cookies" {
+		t.Errorf("activity log = %+v, want one cookie_clear removing 2 cookies", entries)
+	}
+}
@@ -0,0 +1,157 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/stealth"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeCDP answers CDP calls without a browser. Performance.getMetrics
+// reports heapUsed and every other call succeeds with a generic result.
+type fakeCDP struct {
+	heapUsed float64
+	events   chan *cdp.Event
+}
+
+func (f *fakeCDP) Event() <-chan *cdp.Event {
+	return f.events
+}
+
+func (f *fakeCDP) Call(ctx context.Context, sessionID, method string, params interface{}) ([]byte, error) {
+	switch method {
+	case "Performance.getMetrics":
+		return json.Marshal(proto.PerformanceGetMetricsResult{Metrics: []*proto.PerformanceMetric{
+			{Name: "JSHeapUsedSize", Value: f.heapUsed},
+			{Name: "JSHeapTotalSize", Value: f.heapUsed * 2},
+		}})
+	case "Target.attachToTarget":
+		return []byte(`{"sessionId":"session"}`), nil
+	case "Runtime.callFunctionOn":
+		// Reload waits for the frame to navigate again
+		if call, ok := params.(proto.RuntimeCallFunctionOn); ok && strings.Contains(call.FunctionDeclaration, "location.reload") {
+			go func() {
+				f.events <- &cdp.Event{
+					SessionID: sessionID,
+					Method:    "Page.frameNavigated",
+					Params:    json.RawMessage(`{"frame":{"id":"target","loaderId":"","url":"about:blank","securityOrigin":"","mimeType":"text/html","domainAndRegistry":"","secureContextType":"Secure","crossOriginIsolatedContextType":"NotIsolated","gatedAPIFeatures":[]}}`),
+				}
+			}()
+		}
+		return []byte(`{"result":{"type":"object","objectId":"1"}}`), nil
+	case "Runtime.evaluate":
+		return []byte(`{"result":{"type":"object","objectId":"1"}}`), nil
+	}
+	return []byte(`{}`), nil
+}
+
+// newMemoryTestContext returns a context whose page is served by fake
+func newMemoryTestContext(t *testing.T, fake *fakeCDP, maxHeapMB int) (*Context, *storage.Storage) {
+	t.Helper()
+
+	b := rod.New().Client(fake)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	page, err := b.PageFromTarget("target")
+	if err != nil {
+		t.Fatalf("page: %v", err)
+	}
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 10)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{}
+	cfg.Browser.MaxHeapMB = maxHeapMB
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	c := &Context{browser: b, page: page, store: store, cfg: cfg, log: log, stealth: stealth.New(cfg, "browser")}
+	return c, store
+}
+
+// reloadsLogged counts the page_reload entries in the activity log
+func reloadsLogged(t *testing.T, store *storage.Storage) int {
+	t.Helper()
+
+	if err := store.FlushActivityLog(); err != nil {
+		t.Fatalf("FlushActivityLog: %v", err)
+	}
+	entries, err := store.GetRecentActivity(100)
+	if err != nil {
+		t.Fatalf("GetRecentActivity: %v", err)
+	}
+
+	n := 0
+	for _, entry := range entries {
+		if entry.ActionType == "page_reload" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCheckPageMemoryBelowLimit(t *testing.T) {
+	fake := &fakeCDP{heapUsed: 100 * 1024 * 1024, events: make(chan *cdp.Event)}
+	c, store := newMemoryTestContext(t, fake, 512)
+
+	if err := c.checkPageMemory(); err != nil {
+		t.Fatalf("checkPageMemory: %v", err)
+	}
+
+	stats := c.MemoryStats()
+	if stats.JSHeapUsedSize != 100*1024*1024 || stats.JSHeapTotalSize != 200*1024*1024 {
+		t.Errorf("MemoryStats = %+v, want the sampled heap sizes", stats)
+	}
+	if stats.Reloads != 0 || reloadsLogged(t, store) != 0 {
+		t.Errorf("page reloaded below the limit")
+	}
+}
+
+func TestCheckPageMemoryReloadsOverLimit(t *testing.T) {
+	fake := &fakeCDP{heapUsed: 600 * 1024 * 1024, events: make(chan *cdp.Event)}
+	c, store := newMemoryTestContext(t, fake, 512)
+
+	// The reload waits for a workflow holding the page
+	c.LockPage()
+	done := make(chan error, 1)
+	go func() { done <- c.checkPageMemory() }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("checkPageMemory returned %v while the page was locked", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	c.UnlockPage()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("checkPageMemory: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("checkPageMemory didn't finish after the page was unlocked")
+	}
+
+	if stats := c.MemoryStats(); stats.Reloads != 1 {
+		t.Errorf("Reloads = %d, want 1", stats.Reloads)
+	}
+	if n := reloadsLogged(t, store); n != 1 {
+		t.Errorf("logged %d page reloads, want 1", n)
+	}
+}
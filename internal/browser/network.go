@@ -0,0 +1,81 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Names of the preset network throttle profiles
+const (
+	NetworkProfile4G    = "4G"
+	NetworkProfile3G    = "3G"
+	NetworkProfileWiFi  = "WiFi"
+	NetworkProfileCable = "Cable"
+)
+
+// NetworkThrottleProfile describes emulated network conditions. Throughput is
+// in bytes per second and latency in milliseconds.
+type NetworkThrottleProfile struct {
+	Name               string
+	DownloadThroughput float64
+	UploadThroughput   float64
+	Latency            float64
+	Offline            bool
+}
+
+// kbps converts kilobits per second to bytes per second
+func kbps(n float64) float64 {
+	return n * 1024 / 8
+}
+
+// networkProfiles are the presets selectable via browser.network_profile
+var networkProfiles = map[string]NetworkThrottleProfile{
+	strings.ToLower(NetworkProfile4G):    {Name: NetworkProfile4G, DownloadThroughput: kbps(9000), UploadThroughput: kbps(9000), Latency: 85},
+	strings.ToLower(NetworkProfile3G):    {Name: NetworkProfile3G, DownloadThroughput: kbps(1600), UploadThroughput: kbps(750), Latency: 150},
+	strings.ToLower(NetworkProfileWiFi):  {Name: NetworkProfileWiFi, DownloadThroughput: kbps(30000), UploadThroughput: kbps(15000), Latency: 2},
+	strings.ToLower(NetworkProfileCable): {Name: NetworkProfileCable, DownloadThroughput: kbps(5000), UploadThroughput: kbps(1000), Latency: 28},
+}
+
+// NetworkProfileByName looks up a preset profile, case-insensitively
+func NetworkProfileByName(name string) (NetworkThrottleProfile, bool) {
+	profile, ok := networkProfiles[strings.ToLower(name)]
+	return profile, ok
+}
+
+// ThrottleNetwork emulates the given network conditions on the page
+func (c *Context) ThrottleNetwork(profile NetworkThrottleProfile) error {
+	if err := (proto.NetworkEnable{}).Call(c.page); err != nil {
+		return fmt.Errorf("failed to enable network domain: %w", err)
+	}
+
+	err := proto.NetworkEmulateNetworkConditions{
+		Offline:            profile.Offline,
+		Latency:            profile.Latency,
+		DownloadThroughput: profile.DownloadThroughput,
+		UploadThroughput:   profile.UploadThroughput,
+	}.Call(c.page)
+	if err != nil {
+		return fmt.Errorf("failed to emulate network conditions: %w", err)
+	}
+
+	c.log.Infof("Network throttled to %s profile", profile.Name)
+	return nil
+}
+
+// DisableThrottling restores unthrottled network conditions
+func (c *Context) DisableThrottling() error {
+	// A throughput of -1 disables throttling
+	err := proto.NetworkEmulateNetworkConditions{
+		Offline:            false,
+		Latency:            0,
+		DownloadThroughput: -1,
+		UploadThroughput:   -1,
+	}.Call(c.page)
+	if err != nil {
+		return fmt.Errorf("failed to disable network throttling: %w", err)
+	}
+
+	return nil
+}
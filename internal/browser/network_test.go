@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// newMockCDPContext returns a browser context whose page is backed by mockCDP
+func newMockCDPContext(t *testing.T) (*Context, *mockCDP) {
+	t.Helper()
+
+	page, mock := newMockCDPPage(t)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	return &Context{page: page, log: log}, mock
+}
+
+// lastNetworkConditions decodes the latest Network.emulateNetworkConditions
+// call made to mock
+func lastNetworkConditions(t *testing.T, mock *mockCDP) proto.NetworkEmulateNetworkConditions {
+	t.Helper()
+
+	calls := mock.called("Network.emulateNetworkConditions")
+	if len(calls) == 0 {
+		t.Fatal("Network.emulateNetworkConditions was not sent")
+	}
+
+	var conditions proto.NetworkEmulateNetworkConditions
+	if err := json.Unmarshal(calls[len(calls)-1].params, &conditions); err != nil {
+		t.Fatalf("decode network conditions: %v", err)
+	}
+	return conditions
+}
+
+func TestThrottleNetwork(t *testing.T) {
+	c, mock := newMockCDPContext(t)
+
+	profile, ok := NetworkProfileByName("3g")
+	if !ok {
+		t.Fatal("3G preset not found by a lower-case name")
+	}
+	if err := c.ThrottleNetwork(profile); err != nil {
+		t.Fatalf("ThrottleNetwork: %v", err)
+	}
+
+	if len(mock.called("Network.enable")) == 0 {
+		t.Error("network domain was not enabled before throttling")
+	}
+	want := proto.NetworkEmulateNetworkConditions{
+		Offline:            false,
+		Latency:            150,
+		DownloadThroughput: 1600 * 1024 / 8,
+		UploadThroughput:   750 * 1024 / 8,
+	}
+	if got := lastNetworkConditions(t, mock); got != want {
+		t.Errorf("network conditions = %+v, want %+v", got, want)
+	}
+
+	if err := c.DisableThrottling(); err != nil {
+		t.Fatalf("DisableThrottling: %v", err)
+	}
+	want = proto.NetworkEmulateNetworkConditions{Offline: false, DownloadThroughput: -1, UploadThroughput: -1}
+	if got := lastNetworkConditions(t, mock); got != want {
+		t.Errorf("network conditions after disabling = %+v, want %+v", got, want)
+	}
+
+	// offline must be sent explicitly so a previous offline emulation ends
+	calls := mock.called("Network.emulateNetworkConditions")
+	var raw map[string]interface{}
+	if err := json.Unmarshal(calls[len(calls)-1].params, &raw); err != nil {
+		t.Fatalf("decode network conditions: %v", err)
+	}
+	if offline, ok := raw["offline"]; !ok || offline != false {
+		t.Errorf("offline = %v (sent %v), want false", offline, ok)
+	}
+}
+
+func TestNetworkProfileByName(t *testing.T) {
+	for _, name := range []string{NetworkProfile4G, NetworkProfile3G, NetworkProfileWiFi, NetworkProfileCable, "wifi", "CABLE"} {
+		profile, ok := NetworkProfileByName(name)
+		if !ok || profile.DownloadThroughput <= 0 || profile.UploadThroughput <= 0 {
+			t.Errorf("NetworkProfileByName(%q) = %+v, %v, want a throttled preset", name, profile, ok)
+		}
+	}
+
+	if _, ok := NetworkProfileByName("5G"); ok {
+		t.Error("NetworkProfileByName found an unknown profile")
+	}
+}
@@ -0,0 +1,79 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SessionDir returns the user data directory of an account inside
+// sessionsDir, "" when there is no account
+func SessionDir(sessionsDir, account string) string {
+	account = strings.ToLower(strings.TrimSpace(account))
+	if account == "" {
+		return ""
+	}
+
+	// Emails are safe apart from separators and the odd special character
+	safe := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || strings.ContainsRune("@._-+", r) {
+			return r
+		}
+		return '_'
+	}, account)
+
+	return filepath.Join(sessionsDir, safe)
+}
+
+// prepareSessionDir creates the account's session directory and marks it as
+// used now, so CleanupOldSessions keeps it
+func prepareSessionDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	now := time.Now()
+	return os.Chtimes(dir, now, now)
+}
+
+// CleanupOldSessions deletes session directories not used for maxAgeDays,
+// keeping the current one. A maxAgeDays of 0 keeps every session.
+func (c *Context) CleanupOldSessions(maxAgeDays int) error {
+	if maxAgeDays <= 0 {
+		return nil
+	}
+
+	root := c.cfg.Storage.SessionsDir()
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -maxAgeDays)
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		if !entry.IsDir() || dir == c.sessionDir {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat session %s: %w", entry.Name(), err)
+		}
+		if info.ModTime().After(cutoff) {
+			continue
+		}
+
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to remove session %s: %w", entry.Name(), err)
+		}
+		c.log.Infof("Removed browser session %s, unused since %s", entry.Name(), info.ModTime().Format("2006-01-02"))
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package browser
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSessionDir(t *testing.T) {
+	tests := []struct {
+		account string
+		want    string
+	}{
+		{"", ""},
+		{" Jane.Doe+work@Example.com ", filepath.Join("sessions", "jane.doe+work@example.com")},
+		{"../../etc/passwd", filepath.Join("sessions", ".._.._etc_passwd")},
+	}
+
+	for _, tt := range tests {
+		if got := SessionDir("sessions", tt.account); got != tt.want {
+			t.Errorf("SessionDir(%q) = %q, want %q", tt.account, got, tt.want)
+		}
+	}
+}
+
+func TestCleanupOldSessions(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Storage.DataDir = root
+
+	current := SessionDir(cfg.Storage.SessionsDir(), "current@example.com")
+	stale := SessionDir(cfg.Storage.SessionsDir(), "stale@example.com")
+	recent := SessionDir(cfg.Storage.SessionsDir(), "recent@example.com")
+	for _, dir := range []string{current, stale, recent} {
+		if err := prepareSessionDir(dir); err != nil {
+			t.Fatalf("prepareSessionDir: %v", err)
+		}
+	}
+	old := time.Now().AddDate(0, 0, -40)
+	for _, dir := range []string{current, stale} {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	c := &Context{cfg: cfg, log: log, sessionDir: current}
+	if err := c.CleanupOldSessions(30); err != nil {
+		t.Fatalf("CleanupOldSessions: %v", err)
+	}
+
+	for dir, want := range map[string]bool{current: true, stale: false, recent: true} {
+		_, err := os.Stat(dir)
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", filepath.Base(dir), exists, want)
+		}
+	}
+}
+
+func TestIsolatedProfilesHaveSeparateCookies(t *testing.T) {
+	if os.Getenv("CHROME_PATH") == "" {
+		if _, found := launcher.LookPath(); !found {
+			t.Skip("no browser installed")
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "li_at", Value: r.URL.Query().Get("account"), Expires: time.Now().Add(time.Hour)})
+		}
+		io.WriteString(w, "<html><body>ok</body></html>")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	store, err := storage.New(filepath.Join(dir, "test.db"), 10)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	newContext := func(account string) *Context {
+		t.Helper()
+
+		cfg := &config.Config{}
+		cfg.Browser.Headless = true
+		cfg.Browser.IsolatedProfiles = true
+		cfg.Browser.UserAgents = []string{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"}
+		cfg.Storage.DatabasePath = filepath.Join(dir, "test.db")
+		cfg.LinkedIn.Email = account
+
+		c, err := New(cfg, store)
+		if err != nil {
+			t.Fatalf("New(%s): %v", account, err)
+		}
+		return c
+	}
+
+	// cookies returns the values of the li_at cookies the context holds
+	cookies := func(c *Context) []string {
+		t.Helper()
+
+		all, err := c.browser.GetCookies()
+		if err != nil {
+			t.Fatalf("GetCookies: %v", err)
+		}
+		var values []string
+		for _, cookie := range all {
+			if cookie.Name == "li_at" {
+				values = append(values, cookie.Value)
+			}
+		}
+		return values
+	}
+
+	first := newContext("first@example.com")
+	if err := first.page.Navigate(server.URL + "/login?account=first"); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	if err := first.page.WaitLoad(); err != nil {
+		t.Fatalf("wait load: %v", err)
+	}
+	if got := cookies(first); len(got) != 1 || got[0] != "first" {
+		t.Fatalf("first account cookies = %v, want [first]", got)
+	}
+	first.Close()
+
+	second := newContext("second@example.com")
+	defer second.Close()
+	if got := cookies(second); len(got) != 0 {
+		t.Errorf("second account sees cookies %v from the first", got)
+	}
+
+	// The first account's profile keeps its own session
+	again := newContext("first@example.com")
+	defer again.Close()
+	if got := cookies(again); len(got) != 1 || got[0] != "first" {
+		t.Errorf("first account cookies after relaunch = %v, want [first]", got)
+	}
+}
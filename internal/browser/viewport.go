@@ -0,0 +1,82 @@
+package browser
+
+import (
+	"math/rand"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/stealth"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Bounds for randomized viewports, covering common desktop resolutions
+const (
+	minViewportWidth  = 1024
+	maxViewportWidth  = 1920
+	minViewportHeight = 768
+	maxViewportHeight = 1080
+)
+
+// retinaProbability is the share of sessions emulating a 2x display
+const retinaProbability = 0.3
+
+// windowChromeHeight approximates the tab strip and toolbar above the page
+const windowChromeHeight = 85
+
+// Bounds for the randomized window position, in screen pixels
+const (
+	maxWindowLeft = 200
+	maxWindowTop  = 100
+)
+
+// chooseViewport returns the configured viewport, or a randomized one within
+// ±ViewportVariancePixels/2 of it when ViewportRandomization is enabled
+func chooseViewport(cfg config.BrowserConfig) stealth.Viewport {
+	viewport := stealth.Viewport{
+		Width:             cfg.Viewport.Width,
+		Height:            cfg.Viewport.Height,
+		DeviceScaleFactor: 1,
+	}
+
+	if !cfg.ViewportRandomization {
+		return viewport
+	}
+
+	if variance := cfg.ViewportVariancePixels; variance > 0 {
+		viewport.Width = clamp(viewport.Width+rand.Intn(variance+1)-variance/2, minViewportWidth, maxViewportWidth)
+		viewport.Height = clamp(viewport.Height+rand.Intn(variance+1)-variance/2, minViewportHeight, maxViewportHeight)
+	}
+
+	if rand.Float64() < retinaProbability {
+		viewport.DeviceScaleFactor = 2
+	}
+
+	return viewport
+}
+
+// randomWindowBounds places a window fitting viewport at a random position
+// near the top left of the screen
+func randomWindowBounds(viewport stealth.Viewport) *proto.BrowserBounds {
+	return &proto.BrowserBounds{
+		Left:   intPtr(rand.Intn(maxWindowLeft)),
+		Top:    intPtr(rand.Intn(maxWindowTop)),
+		Width:  intPtr(viewport.Width),
+		Height: intPtr(viewport.Height + windowChromeHeight),
+	}
+}
+
+// clamp limits n to [min, max]
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// intPtr returns a pointer to n, for optional CDP fields
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,91 @@
+package browser
+
+import (
+	"math"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/stealth"
+)
+
+func TestChooseViewportStaysWithinBounds(t *testing.T) {
+	const samples = 5000
+
+	tests := []struct {
+		name          string
+		width, height int
+		variance      int
+	}{
+		{"default size", 1280, 800, 200},
+		{"near the minimum", 1024, 768, 300},
+		{"near the maximum", 1920, 1080, 300},
+		{"variance past both bounds", 1600, 900, 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.BrowserConfig{ViewportRandomization: true, ViewportVariancePixels: tt.variance}
+			cfg.Viewport.Width = tt.width
+			cfg.Viewport.Height = tt.height
+
+			retina := 0
+			for i := 0; i < samples; i++ {
+				v := chooseViewport(cfg)
+
+				if v.Width < minViewportWidth || v.Width > maxViewportWidth || v.Height < minViewportHeight || v.Height > maxViewportHeight {
+					t.Fatalf("viewport %dx%d outside %d-%d x %d-%d", v.Width, v.Height,
+						minViewportWidth, maxViewportWidth, minViewportHeight, maxViewportHeight)
+				}
+				if abs(v.Width-tt.width) > tt.variance/2 || abs(v.Height-tt.height) > tt.variance/2 {
+					t.Fatalf("viewport %dx%d more than %d pixels from %dx%d", v.Width, v.Height, tt.variance/2, tt.width, tt.height)
+				}
+
+				switch v.DeviceScaleFactor {
+				case 1:
+				case 2:
+					retina++
+				default:
+					t.Fatalf("DeviceScaleFactor = %v, want 1 or 2", v.DeviceScaleFactor)
+				}
+			}
+
+			if share := float64(retina) / samples; math.Abs(share-retinaProbability) > 0.05 {
+				t.Errorf("retina share = %.2f, want about %.2f", share, retinaProbability)
+			}
+		})
+	}
+}
+
+func TestChooseViewportDisabled(t *testing.T) {
+	cfg := config.BrowserConfig{ViewportVariancePixels: 400}
+	cfg.Viewport.Width = 1280
+	cfg.Viewport.Height = 800
+
+	for i := 0; i < 100; i++ {
+		if v := chooseViewport(cfg); v != (stealth.Viewport{Width: 1280, Height: 800, DeviceScaleFactor: 1}) {
+			t.Fatalf("viewport without randomization = %+v, want the configured 1280x800 @1x", v)
+		}
+	}
+}
+
+func TestRandomWindowBounds(t *testing.T) {
+	viewport := stealth.Viewport{Width: 1366, Height: 768, DeviceScaleFactor: 1}
+
+	for i := 0; i < 1000; i++ {
+		bounds := randomWindowBounds(viewport)
+
+		if *bounds.Left < 0 || *bounds.Left >= maxWindowLeft || *bounds.Top < 0 || *bounds.Top >= maxWindowTop {
+			t.Fatalf("window position (%d, %d) outside [0, %d) x [0, %d)", *bounds.Left, *bounds.Top, maxWindowLeft, maxWindowTop)
+		}
+		if *bounds.Width != 1366 || *bounds.Height != 768+windowChromeHeight {
+			t.Fatalf("window size %dx%d, want the viewport plus browser chrome", *bounds.Width, *bounds.Height)
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
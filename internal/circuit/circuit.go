@@ -0,0 +1,231 @@
+package circuit
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen is returned by Execute while the breaker is rejecting calls
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State is the current position of a breaker
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Settings configures a Breaker. Zero values fall back to the defaults below.
+type Settings struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// RecoveryTimeout is how long the circuit stays open before a trial call
+	RecoveryTimeout time.Duration
+	// HalfOpenMaxRequests is how many trial calls must succeed to close again
+	HalfOpenMaxRequests int
+	// IsSuccessful decides whether an error counts as a failure; nil treats
+	// every non-nil error as one
+	IsSuccessful func(err error) bool
+}
+
+const (
+	defaultFailureThreshold    = 5
+	defaultRecoveryTimeout     = 15 * time.Minute
+	defaultHalfOpenMaxRequests = 1
+)
+
+// SettingsFromConfig converts the YAML circuit breaker config into Settings
+func SettingsFromConfig(cfg config.CircuitBreakerConfig) Settings {
+	return Settings{
+		FailureThreshold:    cfg.FailureThreshold,
+		RecoveryTimeout:     time.Duration(cfg.RecoveryTimeoutMinutes) * time.Minute,
+		HalfOpenMaxRequests: cfg.HalfOpenMaxRequests,
+	}
+}
+
+// BreakerSnapshot is a point-in-time view of a breaker for health reporting
+type BreakerSnapshot struct {
+	Name                string    `json:"name"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	OpenedAt            time.Time `json:"opened_at,omitempty"`
+}
+
+// Breaker stops calling a failing dependency until it has had time to recover
+type Breaker struct {
+	name     string
+	settings Settings
+	log      *logrus.Logger
+	now      func() time.Time
+
+	mu                sync.Mutex
+	state             State
+	failures          int
+	openedAt          time.Time
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+}
+
+// New creates a closed breaker identified by name in logs and snapshots
+func New(name string, settings Settings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = defaultFailureThreshold
+	}
+	if settings.RecoveryTimeout <= 0 {
+		settings.RecoveryTimeout = defaultRecoveryTimeout
+	}
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = defaultHalfOpenMaxRequests
+	}
+
+	return &Breaker{
+		name:     name,
+		settings: settings,
+		log:      logger.Get(),
+		now:      time.Now,
+	}
+}
+
+// Execute runs fn unless the circuit is open, recording its outcome
+func (b *Breaker) Execute(fn func() error) error {
+	if err := b.beforeCall(); err != nil {
+		return err
+	}
+
+	err := fn()
+	b.afterCall(b.isSuccessful(err))
+	return err
+}
+
+// State returns the current state, moving open circuits to half-open once the
+// recovery timeout has elapsed
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkRecovery()
+	return b.state
+}
+
+// Stats returns a snapshot of the breaker for health reporting
+func (b *Breaker) Stats() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkRecovery()
+	return BreakerSnapshot{
+		Name:                b.name,
+		State:               b.state.String(),
+		ConsecutiveFailures: b.failures,
+		OpenedAt:            b.openedAt,
+	}
+}
+
+func (b *Breaker) isSuccessful(err error) bool {
+	if b.settings.IsSuccessful != nil {
+		return b.settings.IsSuccessful(err)
+	}
+	return err == nil
+}
+
+// beforeCall rejects the call when open or when half-open trials are exhausted
+func (b *Breaker) beforeCall() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkRecovery()
+
+	switch b.state {
+	case StateOpen:
+		return ErrCircuitOpen
+	case StateHalfOpen:
+		if b.halfOpenInFlight >= b.settings.HalfOpenMaxRequests {
+			return ErrCircuitOpen
+		}
+		b.halfOpenInFlight++
+	}
+
+	return nil
+}
+
+// afterCall updates counters and transitions based on the call outcome
+func (b *Breaker) afterCall(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.transition(StateOpen)
+		}
+
+	case StateHalfOpen:
+		b.halfOpenInFlight--
+		if !success {
+			b.failures++
+			b.transition(StateOpen)
+			return
+		}
+
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.settings.HalfOpenMaxRequests {
+			b.transition(StateClosed)
+		}
+	}
+}
+
+// checkRecovery moves an open circuit to half-open after the recovery timeout.
+// Callers must hold b.mu.
+func (b *Breaker) checkRecovery() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.settings.RecoveryTimeout {
+		b.transition(StateHalfOpen)
+	}
+}
+
+// transition switches state and resets the counters for it. Callers must hold b.mu.
+func (b *Breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.halfOpenInFlight = 0
+	b.halfOpenSuccesses = 0
+
+	switch to {
+	case StateOpen:
+		b.openedAt = b.now()
+		b.log.Warnf("Circuit breaker %s: %s -> %s after %d consecutive failures, pausing for %s",
+			b.name, from, to, b.failures, b.settings.RecoveryTimeout)
+	case StateHalfOpen:
+		b.log.Infof("Circuit breaker %s: %s -> %s, allowing trial requests", b.name, from, to)
+	case StateClosed:
+		b.failures = 0
+		b.openedAt = time.Time{}
+		b.log.Infof("Circuit breaker %s: %s -> %s", b.name, from, to)
+	}
+}
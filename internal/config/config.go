@@ -1,56 +1,248 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
-	"gopkg.in/yaml.v3"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Browser    BrowserConfig    `yaml:"browser"`
 	Stealth    StealthConfig    `yaml:"stealth"`
 	RateLimits RateLimitsConfig `yaml:"rate_limits"`
+	SafeMode   SafeModeConfig   `yaml:"safe_mode"`
 	Search     SearchConfig     `yaml:"search"`
+	Enrichment EnrichmentConfig `yaml:"enrichment"`
 	Connection ConnectionConfig `yaml:"connection"`
 	Messaging  MessagingConfig  `yaml:"messaging"`
+	Engagement EngagementConfig `yaml:"engagement"`
 	Scheduling SchedulingConfig `yaml:"scheduling"`
 	Storage    StorageConfig    `yaml:"storage"`
 	Logging    LoggingConfig    `yaml:"logging"`
+	API        APIConfig        `yaml:"api"`
+	Notify     NotifyConfig     `yaml:"notifications"`
+	Selectors  SelectorsConfig  `yaml:"selectors"`
+	Scoring    ScoringWeights   `yaml:"scoring"`
 
-	// From environment
-	LinkedIn LinkedInCredentials
+	// PerModuleOverrides tunes stealth behavior per package ("connect",
+	// "message", "search", ...). Zero values inherit from Stealth.
+	PerModuleOverrides map[string]StealthConfig `yaml:"per_module_overrides"`
+
+	// ShutdownTimeout is how long shutdown waits for in-flight actions
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	Auth AuthConfig `yaml:"auth"`
+
+	// Credentials from environment, login verification settings from YAML
+	LinkedIn LinkedInCredentials `yaml:"linkedin"`
+
+	// From command line flags
+	DryRun   bool   `yaml:"-"`
+	Campaign string `yaml:"-"`
 }
 
 type BrowserConfig struct {
 	Headless   bool           `yaml:"headless"`
 	Viewport   ViewportConfig `yaml:"viewport"`
 	UserAgents []string       `yaml:"user_agents"`
+	MaxHeapMB  int            `yaml:"max_heap_mb"`
+
+	// NetworkProfile throttles the connection to a preset: 4G, 3G, WiFi or Cable
+	NetworkProfile string `yaml:"network_profile"`
+
+	// ViewportRandomization varies the viewport by up to
+	// ±ViewportVariancePixels/2 per session and sometimes emulates a 2x display
+	ViewportRandomization  bool `yaml:"viewport_randomization"`
+	ViewportVariancePixels int  `yaml:"viewport_variance_pixels"`
+
+	// IsolateStorage clears localStorage, sessionStorage and non-LinkedIn
+	// cookies before each login and after each workflow iteration
+	IsolateStorage bool `yaml:"isolate_storage"`
+
+	// IsolatedProfiles gives each LinkedIn account its own Chrome user data
+	// directory under DataDir/sessions, so accounts never share cookies,
+	// storage or cache
+	IsolatedProfiles bool `yaml:"isolated_profiles"`
+
+	// SessionMaxAgeDays is how long unused session directories are kept
+	SessionMaxAgeDays int `yaml:"session_max_age_days" validate:"min=0"`
 }
 
 type ViewportConfig struct {
-	Width  int `yaml:"width"`
-	Height int `yaml:"height"`
+	Width  int `yaml:"width" validate:"min=800,max=2560"`
+	Height int `yaml:"height" validate:"min=600,max=1440"`
 }
 
 type StealthConfig struct {
-	EnableMouseMovement   bool            `yaml:"enable_mouse_movement"`
-	EnableRandomScrolling bool            `yaml:"enable_random_scrolling"`
-	EnableHumanTyping     bool            `yaml:"enable_human_typing"`
-	EnableMouseHovering   bool            `yaml:"enable_mouse_hovering"`
-	EnableIdleBreaks      bool            `yaml:"enable_idle_breaks"`
-	ActionDelay           DelayConfig     `yaml:"action_delay"`
-	ScrollDelay           DelayConfig     `yaml:"scroll_delay"`
-	TypingDelay           DelayConfig     `yaml:"typing_delay"`
-	ThinkTime             DelayConfig     `yaml:"think_time"`
-	IdleBreak             IdleBreakConfig `yaml:"idle_break"`
+	// Techniques lists the enabled stealth techniques by name, see
+	// StealthTechniques
+	Techniques                []string        `yaml:"techniques"`
+	ContentTypeAwareTyping    bool            `yaml:"content_type_aware_typing"`
+	EnableMouseHovering       bool            `yaml:"enable_mouse_hovering"`
+	EnableIdleBrowsing        bool            `yaml:"enable_idle_browsing"`
+	UsePoisson                bool            `yaml:"use_poisson"`
+	EnableBrowsingPreamble    bool            `yaml:"enable_browsing_preamble"`
+	PreambleURLs              []string        `yaml:"preamble_urls"`
+	FocusLossIntervalActions  int             `yaml:"focus_loss_interval_actions"`
+	StealthTestURL            string          `yaml:"stealth_test_url"`
+	ProfileRevisitWindowHours int             `yaml:"profile_revisit_window_hours"`
+	ActionDelay               DelayConfig     `yaml:"action_delay"`
+	ScrollDelay               DelayConfig     `yaml:"scroll_delay"`
+	TypingDelay               DelayConfig     `yaml:"typing_delay"`
+	ThinkTime                 DelayConfig     `yaml:"think_time"`
+	IdleBreak                 IdleBreakConfig `yaml:"idle_break"`
+	AccidentalMissRate        float64         `yaml:"accidental_miss_rate"`
+
+	// StabilizationWaitMs is how long the network must be idle before
+	// WaitForElement retries a selector that wasn't found
+	StabilizationWaitMs int `yaml:"stabilization_wait_ms" validate:"min=0,max=10000"`
+
+	// ProfilePreviewDelay is the pause before opening a profile picked for
+	// connection, extended by the time to read its title and headline at
+	// ReadingWPM. Unset uses ThinkTime.
+	ProfilePreviewDelay DelayConfig `yaml:"profile_preview_delay"`
+	ReadingWPM          int         `yaml:"reading_wpm" validate:"min=0"`
+
+	// UseKeyboardShortcuts sends messages with Ctrl+Enter (Meta+Enter on
+	// Mac) instead of the Send button and sometimes moves between form
+	// fields with Tab
+	UseKeyboardShortcuts bool `yaml:"use_keyboard_shortcuts"`
+
+	// ProfileVisitBeforeConnectProbability is the chance a profile is opened
+	// before connecting; otherwise Connect is clicked on its search result
+	ProfileVisitBeforeConnectProbability float64 `yaml:"profile_visit_before_connect_probability" validate:"min=0,max=1"`
+
+	// ActivitySmoothing spreads each hour's connection requests and
+	// messages over the hour instead of sending them as fast as the hourly
+	// limits allow
+	ActivitySmoothing bool `yaml:"activity_smoothing"`
+}
+
+// Stealth technique names accepted in StealthConfig.Techniques
+const (
+	TechniqueCanvas       = "canvas"
+	TechniqueWebGL        = "webgl"
+	TechniqueHardware     = "hardware"
+	TechniqueBattery      = "battery"
+	TechniqueNetworkInfo  = "network_info"
+	TechniqueFonts        = "fonts"
+	TechniqueWebRTC       = "webrtc"
+	TechniqueAudio        = "audio"
+	TechniqueGeolocation  = "geolocation"
+	TechniqueTimezone     = "timezone"
+	TechniqueHumanMouse   = "human_mouse"
+	TechniqueHumanTyping  = "human_typing"
+	TechniqueRandomScroll = "random_scroll"
+	TechniqueIdleBreaks   = "idle_breaks"
+	TechniqueFocusBlur    = "focus_blur"
+)
+
+// StealthTechniques is every technique name, in the order they are applied
+var StealthTechniques = []string{
+	TechniqueCanvas,
+	TechniqueWebGL,
+	TechniqueHardware,
+	TechniqueBattery,
+	TechniqueNetworkInfo,
+	TechniqueFonts,
+	TechniqueWebRTC,
+	TechniqueAudio,
+	TechniqueGeolocation,
+	TechniqueTimezone,
+	TechniqueHumanMouse,
+	TechniqueHumanTyping,
+	TechniqueRandomScroll,
+	TechniqueIdleBreaks,
+	TechniqueFocusBlur,
+}
+
+// DefaultStealthTechniques are enabled when stealth.techniques isn't set:
+// the behavioral techniques the bot always had. The fingerprint spoofs are
+// opt-in.
+var DefaultStealthTechniques = []string{
+	TechniqueHumanMouse,
+	TechniqueHumanTyping,
+	TechniqueRandomScroll,
+	TechniqueIdleBreaks,
+	TechniqueFocusBlur,
+}
+
+// legacyTechniqueFlags maps the boolean stealth.enable_* keys replaced by
+// stealth.techniques to the technique they toggled
+var legacyTechniqueFlags = map[string]string{
+	"enable_mouse_movement":   TechniqueHumanMouse,
+	"enable_human_typing":     TechniqueHumanTyping,
+	"enable_random_scrolling": TechniqueRandomScroll,
+	"enable_idle_breaks":      TechniqueIdleBreaks,
+	"enable_focus_simulation": TechniqueFocusBlur,
+}
+
+// HasTechnique reports whether the named stealth technique is enabled
+func (s StealthConfig) HasTechnique(name string) bool {
+	for _, technique := range s.Techniques {
+		if technique == name {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateTechniques fills stealth.techniques for configs that predate it:
+// DefaultStealthTechniques are enabled except those switched off by a legacy
+// enable_* flag. Module overrides using legacy flags get a list derived the same way
+// from the base techniques.
+func migrateTechniques(v *viper.Viper) {
+	if !v.IsSet("stealth.techniques") {
+		v.Set("stealth.techniques", techniquesFromLegacyFlags(v, "stealth", DefaultStealthTechniques))
+	}
+
+	base := v.GetStringSlice("stealth.techniques")
+	for module := range v.GetStringMap("per_module_overrides") {
+		prefix := "per_module_overrides." + module
+		if v.IsSet(prefix + ".techniques") {
+			continue
+		}
+		for flag := range legacyTechniqueFlags {
+			if v.IsSet(prefix + "." + flag) {
+				v.Set(prefix+".techniques", techniquesFromLegacyFlags(v, prefix, base))
+				break
+			}
+		}
+	}
+}
+
+// techniquesFromLegacyFlags applies the enable_* flags under prefix to base
+func techniquesFromLegacyFlags(v *viper.Viper, prefix string, base []string) []string {
+	enabled := make(map[string]bool, len(base))
+	for _, technique := range base {
+		enabled[technique] = true
+	}
+	for flag, technique := range legacyTechniqueFlags {
+		if v.IsSet(prefix + "." + flag) {
+			enabled[technique] = v.GetBool(prefix + "." + flag)
+		}
+	}
+
+	techniques := make([]string, 0, len(StealthTechniques))
+	for _, technique := range StealthTechniques {
+		if enabled[technique] {
+			techniques = append(techniques, technique)
+		}
+	}
+	return techniques
 }
 
 type DelayConfig struct {
-	Min int `yaml:"min"`
-	Max int `yaml:"max"`
+	Min             int     `yaml:"min"`
+	Max             int     `yaml:"max"`
+	PoissonLambdaMs float64 `yaml:"poisson_lambda_ms"`
 }
 
 type IdleBreakConfig struct {
@@ -60,9 +252,17 @@ type IdleBreakConfig struct {
 }
 
 type RateLimitsConfig struct {
-	Connections RateLimit `yaml:"connections"`
-	Messages    RateLimit `yaml:"messages"`
-	Searches    RateLimit `yaml:"searches"`
+	Connections    RateLimit            `yaml:"connections"`
+	Messages       RateLimit            `yaml:"messages"`
+	Searches       RateLimit            `yaml:"searches"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+}
+
+// CircuitBreakerConfig pauses a service after repeated LinkedIn errors
+type CircuitBreakerConfig struct {
+	FailureThreshold       int `yaml:"failure_threshold"`
+	RecoveryTimeoutMinutes int `yaml:"recovery_timeout_minutes"`
+	HalfOpenMaxRequests    int `yaml:"half_open_max_requests"`
 }
 
 type RateLimit struct {
@@ -70,22 +270,190 @@ type RateLimit struct {
 	PerDay  int `yaml:"per_day"`
 }
 
+// SafeModeConfig holds the conservative limits used while safe mode is
+// active, for new accounts or after LinkedIn shows a warning. Safe mode is
+// turned on with --safe-mode, POST /mode/safe or automatically on a login
+// challenge.
+type SafeModeConfig struct {
+	MaxConnectionsPerDay int `yaml:"max_connections_per_day" validate:"min=0"`
+	MaxMessagesPerDay    int `yaml:"max_messages_per_day" validate:"min=0"`
+	MinActionDelayMs     int `yaml:"min_action_delay_ms" validate:"min=0"`
+
+	// ForceFullStealth enables every stealth technique regardless of
+	// stealth.techniques
+	ForceFullStealth bool `yaml:"force_full_stealth"`
+}
+
+// DailyLimits returns the connections and messages allowed per day, capped
+// at the SafeMode limits when safe mode is active
+func (c *Config) DailyLimits(safeMode bool) (connections, messages int) {
+	connections = c.RateLimits.Connections.PerDay
+	messages = c.RateLimits.Messages.PerDay
+
+	if safeMode {
+		connections = min(connections, c.SafeMode.MaxConnectionsPerDay)
+		messages = min(messages, c.SafeMode.MaxMessagesPerDay)
+	}
+	return connections, messages
+}
+
+// EnrichmentConfig controls the background queue of profiles waiting for
+// their profile page to be visited
+type EnrichmentConfig struct {
+	// WorkerCount is how many goroutines drain the queue, 0 to not queue
+	// profiles at all. Workers share the browser page, so profile visits
+	// still happen one at a time and never during a workflow run.
+	WorkerCount int `yaml:"worker_count" validate:"min=0,max=10"`
+
+	// PriorityScoreThreshold queues profiles scoring above it ahead of the rest
+	PriorityScoreThreshold float64 `yaml:"priority_score_threshold" validate:"min=0,max=1"`
+
+	// MaxVisitsPerHour caps the profile pages enrichment opens per hour
+	MaxVisitsPerHour int `yaml:"max_visits_per_hour" validate:"min=0"`
+}
+
 type SearchConfig struct {
 	Targets             []SearchTarget `yaml:"targets"`
 	MaxResultsPerSearch int            `yaml:"max_results_per_search"`
 	PaginationLimit     int            `yaml:"pagination_limit"`
+	TrackSearchQuota    bool           `yaml:"track_search_quota"`
+	MonthlySearchLimit  int            `yaml:"monthly_search_limit"`
+	ExcludedCompanies   []string       `yaml:"excluded_companies"`
+	ExcludedDomains     []string       `yaml:"excluded_domains"`
+
+	// ICP describes the ideal customer profile used to score results
+	ICP ICPConfig `yaml:"icp"`
+	// MinICPScore drops results scoring below this threshold (0 keeps all)
+	MinICPScore float64 `yaml:"min_icp_score"`
+
+	// RelevantKeywords are looked for in a profile's About section during
+	// enrichment; MinKeywordDensity is the share of About words that must
+	// match them (0 disables the check)
+	RelevantKeywords  []string `yaml:"relevant_keywords"`
+	MinKeywordDensity float64  `yaml:"min_keyword_density"`
+
+	// InferCompanySize visits the profile's company page during enrichment
+	// to bucket its employee count (cached for 7 days per company)
+	InferCompanySize bool `yaml:"infer_company_size"`
+
+	// SearchMode is SearchModeDepthFirst (default: every page of one target
+	// before the next) or SearchModeBreadthFirst (page 1 of all targets,
+	// then page 2, ...)
+	SearchMode string `yaml:"search_mode"`
+
+	// ResolveRedirects follows redirects of profile URLs found on search
+	// cards (e.g. after a vanity URL change) and stores the canonical URL
+	ResolveRedirects bool `yaml:"resolve_redirects"`
+
+	// CacheExpiryHours reuses a target's results when it is searched again
+	// within this many hours (0 disables). DisableCache is set by --no-cache.
+	CacheExpiryHours float64 `yaml:"cache_expiry_hours"`
+	DisableCache     bool    `yaml:"-"`
+}
+
+// Search modes for SearchConfig.SearchMode
+const (
+	SearchModeDepthFirst   = "depth-first"
+	SearchModeBreadthFirst = "breadth-first"
+)
+
+// ScoringWeights tunes how profile scores are computed
+type ScoringWeights struct {
+	Decay ScoreDecay `yaml:"decay"`
+
+	// TitleSynonyms maps job title variants to a canonical form on top of
+	// the built-in synonyms, e.g. "vice president" -> "vp"
+	TitleSynonyms map[string]string `yaml:"title_synonyms"`
+}
+
+// ScoreDecay de-prioritizes stale leads: a profile's score halves every
+// HalfLifeDays after discovery (0 disables decay)
+type ScoreDecay struct {
+	HalfLifeDays float64 `yaml:"half_life_days"`
+}
+
+// ICPConfig describes the ideal customer profile. Empty lists match any value.
+type ICPConfig struct {
+	Industries   []string `yaml:"industries"`
+	CompanySizes []string `yaml:"company_sizes"` // SMB, Mid-Market, Enterprise
+	Seniorities  []string `yaml:"seniorities"`   // IC, Manager, Director, VP, C-Suite
+	Locations    []string `yaml:"locations"`
 }
 
 type SearchTarget struct {
 	JobTitle string `yaml:"job_title"`
 	Location string `yaml:"location"`
 	Keywords string `yaml:"keywords"`
+	Campaign string `yaml:"campaign"`
+
+	// MinRecentActivityDays skips profiles whose activity badge shows they
+	// were last active longer ago than this (0 disables the filter)
+	MinRecentActivityDays int `yaml:"min_recent_activity_days"`
+
+	// Schools restricts results to alumni of these schools (by name)
+	Schools []string `yaml:"schools"`
+
+	// ConnectionDegree restricts results to 2nd (2) or 3rd+ (3) degree
+	// connections; 0 searches both
+	ConnectionDegree int `yaml:"connection_degree"`
 }
 
 type ConnectionConfig struct {
-	SendNote      bool     `yaml:"send_note"`
-	NoteTemplates []string `yaml:"note_templates"`
-	NoteMaxLength int      `yaml:"note_max_length"`
+	SendNote           bool     `yaml:"send_note"`
+	NoteTemplates      []string `yaml:"note_templates"`
+	NoteMaxLength      int      `yaml:"note_max_length"`
+	TruncateOnOverflow bool     `yaml:"truncate_on_overflow"`
+
+	// FollowIfConnectUnavailable follows profiles that only offer "Follow"
+	FollowIfConnectUnavailable bool `yaml:"follow_if_connect_unavailable"`
+
+	// EmailRequiredAction handles profiles that ask for their email address
+	// before accepting an invitation: EmailRequiredSkip (default),
+	// EmailRequiredUseProfileEmail or EmailRequiredUseConfiguredEmail
+	EmailRequiredAction string `yaml:"email_required_action"`
+
+	Batch BatchConfig `yaml:"batch"`
+
+	// PoissonRateLimit spaces requests with exponentially distributed gaps
+	// instead of the action delay. The rate limits still apply as hard caps.
+	PoissonRateLimit PoissonRateLimitConfig `yaml:"poisson_rate_limit"`
+
+	// NotificationPolling detects accepted requests from the notifications
+	// page, falling back to the connections list when it fails
+	NotificationPolling bool `yaml:"notification_polling"`
+
+	// TemplatePreferenceRules favor note templates for profiles whose
+	// headline contains certain words
+	TemplatePreferenceRules []TemplatePreferenceRule `yaml:"template_preference_rules"`
+
+	// CrawlPeopleAlsoViewed queues up to 3 "People Also Viewed" suggestions
+	// from each profile we connect with, visited on the next run
+	CrawlPeopleAlsoViewed bool `yaml:"crawl_people_also_viewed"`
+
+	// WithdrawAfterDays withdraws requests still pending after this many
+	// days, at most MaxWithdrawalsPerRun per run (0 disables)
+	WithdrawAfterDays    int `yaml:"withdraw_after_days" validate:"min=0"`
+	MaxWithdrawalsPerRun int `yaml:"max_withdrawals_per_run" validate:"min=0"`
+}
+
+// TemplatePreferenceRule multiplies the selection weight of the note
+// template at PreferTemplateIndex (0-based) by BoostWeight when the headline
+// contains IfHeadlineContains (case-insensitive)
+type TemplatePreferenceRule struct {
+	IfHeadlineContains  string  `yaml:"if_headline_contains"`
+	PreferTemplateIndex int     `yaml:"prefer_template_index"`
+	BoostWeight         float64 `yaml:"boost_weight"`
+}
+
+// PoissonRateLimitConfig sets the average number of requests per hour (0 disables)
+type PoissonRateLimitConfig struct {
+	LambdaPerHour float64 `yaml:"lambda_per_hour"`
+}
+
+// BatchConfig groups connection requests, with a longer pause between groups
+type BatchConfig struct {
+	BatchSize  int         `yaml:"batch_size"`
+	BatchPause DelayConfig `yaml:"batch_pause"`
 }
 
 type MessagingConfig struct {
@@ -93,12 +461,91 @@ type MessagingConfig struct {
 	DelayAfterConnectionHours int      `yaml:"delay_after_connection_hours"`
 	Templates                 []string `yaml:"templates"`
 	FollowUpEnabled           bool     `yaml:"follow_up_enabled"`
+	TruncateOnOverflow        bool     `yaml:"truncate_on_overflow"`
+
+	// DelayJitterHours is the standard deviation of the delay after
+	// acceptance, so messages don't go out exactly DelayAfterConnectionHours later
+	DelayJitterHours float64 `yaml:"delay_jitter_hours"`
+
+	// InMailFollowed messages followed profiles via InMail instead of waiting
+	// for a connection to be accepted
+	InMailFollowed bool `yaml:"inmail_followed"`
+
+	// MinInMailBalance stops InMail sending once the remaining Premium
+	// InMail credits drop below it
+	MinInMailBalance int `yaml:"min_inmail_balance" validate:"min=0"`
+
+	// CheckDelivery looks for "Seen" receipts on messages sent at least
+	// DeliveryCheckDelayMinutes ago
+	CheckDelivery             bool `yaml:"check_delivery"`
+	DeliveryCheckDelayMinutes int  `yaml:"delivery_check_delay_minutes"`
+
+	// TrackEngagement revisits recipients' profiles a day after messaging to
+	// estimate whether the message was read
+	TrackEngagement bool `yaml:"track_engagement"`
+
+	// AutoResponderRules are evaluated in order against replies; every rule
+	// with a matching keyword fires once per profile
+	AutoResponderRules []AutoResponderRule `yaml:"auto_responder_rules"`
+
+	// RecentPostsLimit is how many of a recipient's recent posts are scraped
+	// to fill {{RecentTopics}}; 0 disables scraping
+	RecentPostsLimit int `yaml:"recent_posts_limit"`
+}
+
+// Connection.EmailRequiredAction values
+const (
+	EmailRequiredSkip               = "skip"
+	EmailRequiredUseProfileEmail    = "use_profile_email"
+	EmailRequiredUseConfiguredEmail = "use_configured_email"
+)
+
+// Auto-responder actions
+const (
+	AutoResponderReply  = "reply"
+	AutoResponderNotify = "notify"
+	AutoResponderTag    = "tag"
+)
+
+// AutoResponderRule reacts to a reply containing any of KeywordsMatch
+// (case-insensitive) by sending ReplyTemplate, notifying the operator or
+// tagging the profile with TagName
+type AutoResponderRule struct {
+	KeywordsMatch []string `yaml:"keywords_match"`
+	Action        string   `yaml:"action"`
+	ReplyTemplate string   `yaml:"reply_template"`
+	TagName       string   `yaml:"tag_name"`
+}
+
+type EngagementConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	CommentTemplates  []string `yaml:"comment_templates"`
+	MinPostAgeDays    int      `yaml:"min_post_age_days"`
+	MaxPostAgeDays    int      `yaml:"max_post_age_days"`
+	MaxCommentsPerRun int      `yaml:"max_comments_per_run"`
+
+	// CompanyFollowEnabled follows CompanyURLs plus the company pages of
+	// discovered profiles
+	CompanyFollowEnabled bool     `yaml:"company_follow_enabled"`
+	CompanyURLs          []string `yaml:"company_urls"`
+
+	// SkillEndorsementEnabled endorses up to MaxSkillEndorsements skills on
+	// each new profile before connecting
+	SkillEndorsementEnabled bool `yaml:"skill_endorsement_enabled"`
+	MaxSkillEndorsements    int  `yaml:"max_skill_endorsements"`
 }
 
 type SchedulingConfig struct {
-	ActiveHours ActiveHoursConfig `yaml:"active_hours"`
-	ActiveDays  []string          `yaml:"active_days"`
-	Timezone    string            `yaml:"timezone"`
+	ActiveHours  ActiveHoursConfig `yaml:"active_hours"`
+	ActiveDays   []string          `yaml:"active_days" validate:"dive,weekday"`
+	Timezone     string            `yaml:"timezone" validate:"omitempty,timezone"`
+	WeeklyReport bool              `yaml:"weekly_report"`
+
+	// AdaptiveConnectHours skips connection requests during hours whose
+	// historical acceptance rate is under half the average, once the hour has
+	// AdaptiveMinSamples requests
+	AdaptiveConnectHours bool `yaml:"adaptive_connect_hours"`
+	AdaptiveMinSamples   int  `yaml:"adaptive_min_samples"`
 }
 
 type ActiveHoursConfig struct {
@@ -107,71 +554,500 @@ type ActiveHoursConfig struct {
 }
 
 type StorageConfig struct {
-	DatabasePath string `yaml:"database_path"`
-	CookiePath   string `yaml:"cookie_path"`
+	DatabasePath     string `yaml:"database_path"`
+	CookiePath       string `yaml:"cookie_path"`
+	ProfileCacheSize int    `yaml:"profile_cache_size"`
+
+	// DataDir holds downloaded files such as profile photos; defaults to
+	// the database directory
+	DataDir string `yaml:"data_dir"`
+
+	// PhotoStorageEnabled downloads profile photos to DataDir/photos during
+	// enrichment
+	PhotoStorageEnabled bool `yaml:"photo_storage_enabled"`
+}
+
+// PhotoDir returns the directory profile photos are saved in
+func (c StorageConfig) PhotoDir() string {
+	return filepath.Join(c.dataDir(), "photos")
+}
+
+// SessionsDir returns the directory holding a browser profile per account
+func (c StorageConfig) SessionsDir() string {
+	return filepath.Join(c.dataDir(), "sessions")
+}
+
+// dataDir returns DataDir, defaulting to the database directory
+func (c StorageConfig) dataDir() string {
+	if c.DataDir != "" {
+		return c.DataDir
+	}
+	return filepath.Dir(c.DatabasePath)
 }
 
 type LoggingConfig struct {
 	Level   string `yaml:"level"`
 	File    string `yaml:"file"`
 	Console bool   `yaml:"console"`
+
+	// ScreenshotOnError saves a screenshot when a workflow run fails, up to
+	// MaxErrorScreenshots per process
+	ScreenshotOnError   bool `yaml:"screenshot_on_error"`
+	MaxErrorScreenshots int  `yaml:"max_error_screenshots"`
+
+	// EnableRedaction masks emails, profile slugs, passwords, session cookies
+	// and RedactPatterns (regexes) in log output
+	EnableRedaction bool     `yaml:"enable_redaction"`
+	RedactPatterns  []string `yaml:"redact_patterns"`
 }
 
-type LinkedInCredentials struct {
-	Email    string
-	Password string
+// SelectorsConfig holds the CSS selectors used to locate LinkedIn DOM elements.
+// Empty fields fall back to DefaultSelectors.
+type SelectorsConfig struct {
+	// Search results
+	SearchResultCard string   `yaml:"search_result_card"`
+	ProfileLink      string   `yaml:"profile_link"`
+	ProfileName      string   `yaml:"profile_name"`
+	ProfileTitle     string   `yaml:"profile_title"`
+	ProfileSubtitle  string   `yaml:"profile_subtitle"`
+	NextPageButton   string   `yaml:"next_page_button"`
+	ResultsCount     string   `yaml:"results_count"`
+	ActivityBadge    string   `yaml:"activity_badge"`
+	ProfileHeadline  []string `yaml:"profile_headline"`
+	ProfileSummary   []string `yaml:"profile_summary"`
+	ConnectionDegree string   `yaml:"connection_degree"`
+
+	// Profile page
+	ProfilePageName     string   `yaml:"profile_page_name"`
+	ProfilePageTitle    string   `yaml:"profile_page_title"`
+	OpenToWorkBadge     string   `yaml:"open_to_work_badge"`
+	ProfileCompanyLink  string   `yaml:"profile_company_link"`
+	ProfilePhoto        []string `yaml:"profile_photo"`
+	ProfileAboutSection []string `yaml:"profile_about_section"`
+	ProfileAboutSeeMore []string `yaml:"profile_about_see_more"`
+	ProfileAboutText    []string `yaml:"profile_about_text"`
+
+	// Connection requests
+	ConnectButton         []string `yaml:"connect_button"`
+	AddNoteButton         []string `yaml:"add_note_button"`
+	NoteTextarea          []string `yaml:"note_textarea"`
+	SendNowButton         string   `yaml:"send_now_button"`
+	SendWithoutNoteButton []string `yaml:"send_without_note_button"`
+	WithdrawButton        string   `yaml:"withdraw_button"`
+	WithdrawConfirmButton string   `yaml:"withdraw_confirm_button"`
+	PendingButton         []string `yaml:"pending_button"`
+	PendingWithdrawButton []string `yaml:"pending_withdraw_button"`
+	FollowButton          []string `yaml:"follow_button"`
+	PremiumNotePrompt     string   `yaml:"premium_note_prompt"`
+	PremiumSkipButton     []string `yaml:"premium_skip_button"`
+	NoteCharCounter       string   `yaml:"note_char_counter"`
+	WeeklyLimitModal      string   `yaml:"weekly_limit_modal"`
+	WeeklyLimitBanner     string   `yaml:"weekly_limit_banner"`
+	PeopleAlsoViewedLink  string   `yaml:"people_also_viewed_link"`
+
+	// Connect button inside a search result card
+	SearchResultConnectButton []string `yaml:"search_result_connect_button"`
+
+	// "Connect via email" modal and the profile's contact info overlay
+	EmailRequiredInput   string   `yaml:"email_required_input"`
+	EmailRequiredDismiss []string `yaml:"email_required_dismiss"`
+	ContactInfoLink      string   `yaml:"contact_info_link"`
+	ContactInfoEmail     string   `yaml:"contact_info_email"`
+	ContactInfoDismiss   string   `yaml:"contact_info_dismiss"`
+
+	// Acceptance checks
+	NotificationCard        string `yaml:"notification_card"`
+	NotificationProfileLink string `yaml:"notification_profile_link"`
+	ConnectionCardLink      string `yaml:"connection_card_link"`
+
+	// Post content on the recent activity feed (posts match ActivityPost)
+	PostText         string `yaml:"post_text"`
+	PostLikeCount    string `yaml:"post_like_count"`
+	PostCommentCount string `yaml:"post_comment_count"`
+
+	// Messaging
+	MessageBox         []string `yaml:"message_box"`
+	SendButton         []string `yaml:"send_button"`
+	MessageThreadEvent string   `yaml:"message_thread_event"`
+	ReadReceiptStatus  string   `yaml:"read_receipt_status"`
+	IncomingMessage    string   `yaml:"incoming_message"`
+
+	// Remaining InMail credits on the Premium settings page
+	InMailCredits string `yaml:"inmail_credits"`
+
+	// Message engagement checks on profile pages
+	ProfileRepliedIndicator string `yaml:"profile_replied_indicator"`
+	ProfileMessageButton    string `yaml:"profile_message_button"`
+
+	// Post engagement
+	ActivityPost        string   `yaml:"activity_post"`
+	PostTimestamp       string   `yaml:"post_timestamp"`
+	PostCommentButton   []string `yaml:"post_comment_button"`
+	CommentBox          string   `yaml:"comment_box"`
+	CommentSubmitButton string   `yaml:"comment_submit_button"`
+	CommentAvatar       string   `yaml:"comment_avatar"`
+	OwnProfilePhoto     string   `yaml:"own_profile_photo"`
+
+	// Company pages
+	CompanyName         string   `yaml:"company_name"`
+	CompanyFollowButton []string `yaml:"company_follow_button"`
+	CompanySizeInfo     string   `yaml:"company_size_info"`
+
+	// Skill endorsements
+	SkillItem          string   `yaml:"skill_item"`
+	SkillName          string   `yaml:"skill_name"`
+	SkillEndorseButton []string `yaml:"skill_endorse_button"`
 }
 
-// Load reads configuration from config.yaml and environment variables
-func Load() (*Config, error) {
-	// Load .env file if exists
-	_ = godotenv.Load()
+// DefaultSelectors returns the built-in selectors for the current LinkedIn DOM
+func DefaultSelectors() SelectorsConfig {
+	return SelectorsConfig{
+		SearchResultCard: ".reusable-search__result-container",
+		ProfileLink:      "a.app-aware-link",
+		ProfileName:      ".entity-result__title-text a span[aria-hidden='true']",
+		ProfileTitle:     ".entity-result__primary-subtitle",
+		ProfileSubtitle:  ".entity-result__secondary-subtitle",
+		NextPageButton:   "button[aria-label='Next']",
+		ResultsCount:     ".search-results-container h2",
+		ActivityBadge:    ".entity-result__simple-insight-text, .entity-result__insights",
+		ProfileHeadline: []string{
+			".entity-result__summary",
+			".entity-result__primary-subtitle",
+		},
+		ProfileSummary: []string{
+			".entity-result__summary--2-lines",
+			".entity-result__content-summary",
+			"p.entity-result__summary",
+		},
+		ConnectionDegree: ".entity-result__badge-text, .entity-result__badge",
+
+		ProfilePageName:  "h1.text-heading-xlarge",
+		ProfilePageTitle: ".text-body-medium.break-words",
+		OpenToWorkBadge: ".open-to-work-badge-container, " +
+			".pv-top-card-profile-picture [aria-label*='Open to work' i], " +
+			".pv-top-card__photo[aria-label*='Open to work' i], " +
+			".pv-open-to-work-banner",
+		ProfileCompanyLink: "a[data-field='experience_company_logo'], a[href*='linkedin.com/company/']",
+		ProfilePhoto: []string{
+			".pv-top-card-profile-picture img",
+			"img.pv-top-card-profile-picture__image",
+		},
+		ProfileAboutSection: []string{
+			"section:has(> #about)",
+			".pv-about-section",
+		},
+		ProfileAboutSeeMore: []string{
+			".inline-show-more-text__button",
+			"button.lt-line-clamp__more",
+			"button[aria-label*='see more' i]",
+		},
+		ProfileAboutText: []string{
+			".inline-show-more-text span[aria-hidden='true']",
+			".pv-about__summary-text",
+			".inline-show-more-text",
+		},
+
+		ConnectButton: []string{
+			"button[aria-label*='Connect']",
+			"button.pvs-profile-actions__action:has-text('Connect')",
+			"button:has-text('Connect')",
+			".pvs-profile-actions button:has-text('Connect')",
+		},
+		AddNoteButton: []string{
+			"button[aria-label*='Add a note']",
+			"button:has-text('Add a note')",
+		},
+		NoteTextarea:  []string{"textarea[name='message']", "textarea#custom-message"},
+		SendNowButton: "button[aria-label*='Send now']",
+		SendWithoutNoteButton: []string{
+			"button[aria-label*='Send without a note']",
+			"button:has-text('Send')",
+		},
+		WithdrawButton:        "button[aria-label*='Withdraw']",
+		WithdrawConfirmButton: "button[data-control-name='withdraw_single']",
+		// "Pending" on a profile we invited, and the withdraw confirmation it opens
+		PendingButton: []string{
+			"main button[aria-label^='Pending']",
+			"button[aria-label*='withdraw invitation' i]",
+		},
+		PendingWithdrawButton: []string{
+			"div[role='alertdialog'] button.artdeco-button--primary",
+			".artdeco-modal button[aria-label^='Withdraw']",
+		},
+		FollowButton: []string{
+			"button[aria-label*='Follow']",
+		},
+		PremiumNotePrompt: ".modal-upsell, [data-test-modal-id='upsell-modal'], .artdeco-modal[aria-labelledby*='upsell']",
+		PremiumSkipButton: []string{
+			"button[aria-label*='Skip for free']",
+			".modal-upsell button.artdeco-button--secondary",
+			"button:has-text('Skip for free')",
+		},
+		NoteCharCounter: ".artdeco-modal .t-14.t-black--light",
+
+		// Containers searched for "You've reached the weekly invitation limit"
+		WeeklyLimitModal:  "[data-test-modal-id='limit-reached-modal'], .limit-reached-modal, #limit-reached-modal",
+		WeeklyLimitBanner: ".artdeco-modal, [role='alert'], [role='dialog'], .artdeco-toast-item",
+
+		PeopleAlsoViewedLink: ".pv-browsemap-section__member-link",
+
+		SearchResultConnectButton: []string{
+			"button[aria-label^='Invite'][aria-label$='to connect']",
+			".entity-result__actions button:has-text('Connect')",
+		},
+
+		EmailRequiredInput: ".artdeco-modal input#email, .artdeco-modal input[name='email'], .artdeco-modal input[type='email']",
+		EmailRequiredDismiss: []string{
+			".artdeco-modal button[aria-label='Dismiss']",
+			"button.artdeco-modal__dismiss",
+		},
+		ContactInfoLink:    "a#top-card-text-details-contact-info, a[href*='/overlay/contact-info/']",
+		ContactInfoEmail:   "section.ci-email a[href^='mailto:'], a[href^='mailto:']",
+		ContactInfoDismiss: ".artdeco-modal button[aria-label='Dismiss']",
+
+		NotificationCard:        "article.nt-card",
+		NotificationProfileLink: "a[href*='/in/']",
+		ConnectionCardLink:      "a.mn-connection-card__link",
+
+		PostText:         ".update-components-text, .feed-shared-update-v2__description",
+		PostLikeCount:    ".social-details-social-counts__reactions-count",
+		PostCommentCount: ".social-details-social-counts__comments",
+
+		MessageBox: []string{
+			".msg-form__contenteditable",
+			"div[role='textbox']",
+		},
+		SendButton: []string{
+			"button.msg-form__send-button",
+			"button[type='submit']",
+			"button:has-text('Send')",
+			".msg-form__send-button",
+		},
+		MessageThreadEvent: ".msg-s-message-list__event",
+		ReadReceiptStatus:  ".msg-s-message-group__meta .msg-s-message-group__read-receipt-status",
+		IncomingMessage:    ".msg-s-event-listitem--other .msg-s-event-listitem__body",
+
+		InMailCredits: ".inmail-credits__count",
 
-	// Read YAML config
-	data, err := os.ReadFile("config.yaml")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config.yaml: %w", err)
+		// An unread badge on the Message button means they wrote back
+		ProfileRepliedIndicator: ".pv-top-card .message-anywhere-button .notification-badge, " +
+			".pv-top-card [aria-label*='replied' i]",
+		ProfileMessageButton: ".pv-top-card .message-anywhere-button, .pvs-profile-actions button[aria-label^='Message']",
+
+		ActivityPost:  ".feed-shared-update-v2[data-urn]",
+		PostTimestamp: ".update-components-actor__sub-description",
+		PostCommentButton: []string{
+			"button[aria-label*='Comment']",
+			"button.comment-button",
+		},
+		CommentBox:          ".comments-comment-box__form .ql-editor[contenteditable='true']",
+		CommentSubmitButton: "button.comments-comment-box__submit-button",
+		CommentAvatar:       ".comments-comment-item img, .comments-comment-entity img",
+		OwnProfilePhoto:     "img.global-nav__me-photo",
+
+		CompanyName:     "h1.org-top-card-summary__title",
+		CompanySizeInfo: ".org-top-card-summary-info-list__info-item",
+		CompanyFollowButton: []string{
+			".org-top-card-primary-actions__inner button.follow",
+			".org-top-card-primary-actions button[aria-label*='Follow']",
+			"button.org-company-follow-button",
+		},
+
+		SkillItem: ".pvs-list__paged-list-item, .pvs-list > li",
+		SkillName: ".mr1.t-bold span[aria-hidden='true'], .t-bold span[aria-hidden='true']",
+		SkillEndorseButton: []string{
+			"button[aria-label^='Endorse']",
+			"button[aria-label*='endorse' i]",
+			".pvs-list__item--with-top-padding button.artdeco-button--secondary",
+		},
 	}
+}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config.yaml: %w", err)
+// GetStealthConfig returns the stealth settings for a module: the base
+// StealthConfig with any non-zero fields from the module's override applied
+func (c *Config) GetStealthConfig(module string) StealthConfig {
+	merged := c.Stealth
+
+	override, ok := c.PerModuleOverrides[module]
+	if !ok {
+		return merged
 	}
 
-	// Override with environment variables
-	cfg.LinkedIn.Email = getEnv("LINKEDIN_EMAIL", "")
-	cfg.LinkedIn.Password = getEnv("LINKEDIN_PASSWORD", "")
+	mergeNonZero(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(override))
+	return merged
+}
+
+// mergeNonZero copies non-zero fields from src onto dst, recursing into
+// nested structs so partially specified delays still inherit the rest
+func mergeNonZero(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Field(i)
+		if field.IsZero() {
+			continue
+		}
 
-	if cfg.LinkedIn.Email == "" || cfg.LinkedIn.Password == "" {
-		return nil, fmt.Errorf("LINKEDIN_EMAIL and LINKEDIN_PASSWORD must be set")
+		if field.Kind() == reflect.Struct {
+			mergeNonZero(dst.Field(i), field)
+			continue
+		}
+
+		dst.Field(i).Set(field)
 	}
+}
 
-	// Override other settings from env if present
-	if headless := os.Getenv("HEADLESS"); headless != "" {
-		cfg.Browser.Headless = headless == "true"
+// applyDefaults fills empty selector fields from DefaultSelectors
+func (c *SelectorsConfig) applyDefaults() {
+	defaults := reflect.ValueOf(DefaultSelectors())
+	current := reflect.ValueOf(c).Elem()
+
+	for i := 0; i < current.NumField(); i++ {
+		if current.Field(i).IsZero() {
+			current.Field(i).Set(defaults.Field(i))
+		}
 	}
+}
+
+type APIConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"`
+
+	// Dashboard serves the web dashboard on DashboardAddr, with the REST API
+	// mounted under /api/
+	Dashboard     bool   `yaml:"dashboard"`
+	DashboardAddr string `yaml:"dashboard_addr"`
+}
+
+type NotifyConfig struct {
+	CAPTCHAWebhookURL          string      `yaml:"captcha_webhook_url"`
+	InterventionTimeoutMinutes int         `yaml:"intervention_timeout_minutes"`
+	EmailWeeklyReport          bool        `yaml:"email_weekly_report"`
+	Slack                      SlackConfig `yaml:"slack"`
+	SMTP                       SMTPConfig  `yaml:"smtp"`
+}
+
+type SlackConfig struct {
+	BotToken string `yaml:"-"`
+	Channel  string `yaml:"channel"`
+	APIURL   string `yaml:"api_url"`
+}
+
+type SMTPConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"-"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// AuthConfig controls how long a LinkedIn session is trusted
+type AuthConfig struct {
+	// ProactiveRefresh logs in again once the session is SessionMaxAgeHours
+	// old, before LinkedIn expires it mid-run
+	ProactiveRefresh   bool `yaml:"proactive_refresh"`
+	SessionMaxAgeHours int  `yaml:"session_max_age_hours"`
+}
 
-	if dbPath := os.Getenv("DB_PATH"); dbPath != "" {
-		cfg.Storage.DatabasePath = dbPath
+type LinkedInCredentials struct {
+	Email    string `yaml:"-"`
+	Password string `yaml:"-"`
+
+	// SMSVerificationEnabled answers SMS login challenges with a code fetched
+	// from SMSWebhookURL, or typed on stdin when no webhook is configured
+	SMSVerificationEnabled bool          `yaml:"sms_verification_enabled"`
+	SMSWebhookURL          string        `yaml:"sms_webhook_url"`
+	SMSTimeout             time.Duration `yaml:"sms_timeout"`
+}
+
+// envPrefix is prepended to every environment variable override, e.g.
+// LINKEDIN_RATE_LIMITS_CONNECTIONS_PER_DAY overrides rate_limits.connections.per_day
+const envPrefix = "LINKEDIN"
+
+// legacyEnvVars are environment variables that predate the LINKEDIN_ prefix
+// and are still honored
+var legacyEnvVars = map[string]string{
+	"browser.headless":                "HEADLESS",
+	"storage.database_path":           "DB_PATH",
+	"logging.level":                   "LOG_LEVEL",
+	"rate_limits.connections.per_day": "MAX_CONNECTIONS_PER_DAY",
+	"rate_limits.messages.per_day":    "MAX_MESSAGES_PER_DAY",
+}
+
+// Load reads configuration from the given YAML file. Every field can be
+// overridden by a LINKEDIN_ prefixed environment variable.
+func Load(path string) (*Config, error) {
+	// Load .env file if exists
+	_ = godotenv.Load()
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+
+	v.SetDefault("logging.screenshot_on_error", true)
+	v.SetDefault("logging.max_error_screenshots", 20)
+	v.SetDefault("shutdown_timeout", "30s")
+	v.SetDefault("linkedin.sms_timeout", "3m")
+	v.SetDefault("storage.profile_cache_size", 500)
+	v.SetDefault("api.dashboard_addr", "127.0.0.1:3000")
+	v.SetDefault("auth.session_max_age_hours", 72)
+	v.SetDefault("search.search_mode", SearchModeDepthFirst)
+	v.SetDefault("stealth.stabilization_wait_ms", 500)
+	v.SetDefault("stealth.reading_wpm", 200)
+	v.SetDefault("browser.session_max_age_days", 30)
+	v.SetDefault("stealth.profile_visit_before_connect_probability", 0.7)
+	v.SetDefault("connection.max_withdrawals_per_run", 10)
+	v.SetDefault("enrichment.priority_score_threshold", 0.7)
+	v.SetDefault("enrichment.max_visits_per_hour", 10)
+	v.SetDefault("safe_mode.max_connections_per_day", 5)
+	v.SetDefault("safe_mode.max_messages_per_day", 3)
+	v.SetDefault("safe_mode.min_action_delay_ms", 5000)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
 	}
 
-	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
-		cfg.Logging.Level = logLevel
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// AutomaticEnv only applies to keys viper already knows about, so bind
+	// every config field explicitly to cover keys missing from the YAML
+	if err := bindEnvs(v, reflect.TypeOf(Config{}), ""); err != nil {
+		return nil, fmt.Errorf("failed to bind environment variables: %w", err)
 	}
 
-	// Override rate limits from env
-	if val := os.Getenv("MAX_CONNECTIONS_PER_DAY"); val != "" {
-		if n, err := strconv.Atoi(val); err == nil {
-			cfg.RateLimits.Connections.PerDay = n
+	// Secrets and credentials only come from the environment
+	for key, env := range map[string]string{
+		"email":           "LINKEDIN_EMAIL",
+		"password":        "LINKEDIN_PASSWORD",
+		"slack_bot_token": "SLACK_BOT_TOKEN",
+		"smtp_password":   "SMTP_PASSWORD",
+	} {
+		if err := v.BindEnv(key, env); err != nil {
+			return nil, fmt.Errorf("failed to bind %s: %w", env, err)
 		}
 	}
 
-	if val := os.Getenv("MAX_MESSAGES_PER_DAY"); val != "" {
-		if n, err := strconv.Atoi(val); err == nil {
-			cfg.RateLimits.Messages.PerDay = n
-		}
+	migrateTechniques(v)
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.TagName = "yaml"
+	}); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	cfg.LinkedIn.Email = v.GetString("email")
+	cfg.LinkedIn.Password = v.GetString("password")
+	cfg.Notify.Slack.BotToken = v.GetString("slack_bot_token")
+	cfg.Notify.SMTP.Password = v.GetString("smtp_password")
+
+	if cfg.LinkedIn.Email == "" || cfg.LinkedIn.Password == "" {
+		return nil, fmt.Errorf("LINKEDIN_EMAIL and LINKEDIN_PASSWORD must be set")
 	}
 
+	cfg.Selectors.applyDefaults()
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -180,30 +1056,107 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
-// Validate checks if the configuration is valid
-func (c *Config) Validate() error {
-	if c.Browser.Viewport.Width <= 0 || c.Browser.Viewport.Height <= 0 {
-		return fmt.Errorf("invalid viewport dimensions")
+// bindEnvs walks the config struct and binds each YAML key to its LINKEDIN_
+// prefixed environment variable, plus any legacy variable name
+func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		// Maps can't be expressed as a single environment variable
+		if field.Type.Kind() == reflect.Map {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := bindEnvs(v, field.Type, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		envs := []string{envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))}
+		if legacy, ok := legacyEnvVars[key]; ok {
+			envs = append(envs, legacy)
+		}
+
+		if err := v.BindEnv(append([]string{key}, envs...)...); err != nil {
+			return err
+		}
 	}
 
+	return nil
+}
+
+// Validate checks if the configuration is valid, returning every problem
+// found joined into one error
+func (c *Config) Validate() error {
+	errs := validateTags(c)
+
 	if len(c.Browser.UserAgents) == 0 {
-		return fmt.Errorf("at least one user agent must be specified")
+		errs = append(errs, fmt.Errorf("at least one user agent must be specified"))
 	}
 
-	if c.RateLimits.Connections.PerDay <= 0 {
-		return fmt.Errorf("connections per day must be positive")
+	if c.Storage.DatabasePath == "" {
+		errs = append(errs, fmt.Errorf("database path must be specified"))
 	}
 
-	if c.Storage.DatabasePath == "" {
-		return fmt.Errorf("database path must be specified")
+	for i, rule := range c.Messaging.AutoResponderRules {
+		switch rule.Action {
+		case AutoResponderReply, AutoResponderNotify, AutoResponderTag:
+		default:
+			errs = append(errs, fmt.Errorf("auto responder rule %d: invalid action %q", i+1, rule.Action))
+		}
 	}
 
-	return nil
+	if err := validateTechniques("stealth", c.Stealth.Techniques); err != nil {
+		errs = append(errs, err)
+	}
+	for module, override := range c.PerModuleOverrides {
+		if err := validateTechniques("per_module_overrides."+module, override.Techniques); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch c.Connection.EmailRequiredAction {
+	case "", EmailRequiredSkip, EmailRequiredUseProfileEmail, EmailRequiredUseConfiguredEmail:
+	default:
+		errs = append(errs, fmt.Errorf("invalid email required action %q (want %s, %s or %s)", c.Connection.EmailRequiredAction,
+			EmailRequiredSkip, EmailRequiredUseProfileEmail, EmailRequiredUseConfiguredEmail))
+	}
+
+	switch c.Search.SearchMode {
+	case "", SearchModeDepthFirst, SearchModeBreadthFirst:
+	default:
+		errs = append(errs, fmt.Errorf("invalid search mode %q (want %s or %s)", c.Search.SearchMode, SearchModeDepthFirst, SearchModeBreadthFirst))
+	}
+
+	return errors.Join(errs...)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// validateTechniques rejects technique names that aren't in StealthTechniques
+func validateTechniques(key string, techniques []string) error {
+	for _, technique := range techniques {
+		known := false
+		for _, name := range StealthTechniques {
+			if technique == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%s.techniques: unknown technique %q (see --list-techniques)", key, technique)
+		}
 	}
-	return defaultValue
+
+	return nil
 }
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// repoConfig is the sample configuration shipped with the repository
+const repoConfig = "../../config.yaml"
+
+func TestLoadAppliesEnvironmentOverrides(t *testing.T) {
+	t.Setenv("LINKEDIN_EMAIL", "jane@example.com")
+	t.Setenv("LINKEDIN_PASSWORD", "secret")
+	t.Setenv("LINKEDIN_RATE_LIMITS_CONNECTIONS_PER_DAY", "7")
+	t.Setenv("LINKEDIN_LOGGING_LEVEL", "debug")
+	// Legacy variable without the LINKEDIN_ prefix
+	t.Setenv("HEADLESS", "true")
+	// Dropped from the file below, so only bound through the struct
+	t.Setenv("LINKEDIN_API_ADDR", ":9090")
+
+	data, err := os.ReadFile(repoConfig)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	const addrLine = "  addr: \":8080\"\n"
+	if !strings.Contains(string(data), addrLine) {
+		t.Fatalf("%s no longer sets api.addr", repoConfig)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), addrLine, "", 1)), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.LinkedIn.Email != "jane@example.com" || cfg.LinkedIn.Password != "secret" {
+		t.Errorf("credentials = %q/%q, want them from the environment", cfg.LinkedIn.Email, cfg.LinkedIn.Password)
+	}
+	if cfg.RateLimits.Connections.PerDay != 7 {
+		t.Errorf("connections per day = %d, want 7", cfg.RateLimits.Connections.PerDay)
+	}
+	if cfg.Logging.Level != "debug" {
+		t.Errorf("logging level = %q, want debug", cfg.Logging.Level)
+	}
+	if !cfg.Browser.Headless {
+		t.Error("HEADLESS=true didn't enable headless mode")
+	}
+	if cfg.API.Addr != ":9090" {
+		t.Errorf("API addr = %q, want :9090", cfg.API.Addr)
+	}
+
+	// Values without an override still come from the file
+	if cfg.RateLimits.Messages.PerDay != 30 {
+		t.Errorf("messages per day = %d, want 30 from config.yaml", cfg.RateLimits.Messages.PerDay)
+	}
+}
+
+func TestLoadRequiresCredentials(t *testing.T) {
+	t.Setenv("LINKEDIN_EMAIL", "")
+	t.Setenv("LINKEDIN_PASSWORD", "")
+
+	_, err := Load(repoConfig)
+	if err == nil || !strings.Contains(err.Error(), "LINKEDIN_EMAIL") {
+		t.Errorf("Load without credentials = %v, want a missing credentials error", err)
+	}
+}
+
+// loadRepoConfig loads the sample configuration with test credentials
+func loadRepoConfig(t *testing.T) *Config {
+	t.Helper()
+
+	t.Setenv("LINKEDIN_EMAIL", "jane@example.com")
+	t.Setenv("LINKEDIN_PASSWORD", "secret")
+
+	cfg, err := Load(repoConfig)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+func TestValidateConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string // empty when the config is valid
+	}{
+		{"sample config", func(c *Config) {}, ""},
+		{"action delay min at bound", func(c *Config) { c.Stealth.ActionDelay = DelayConfig{Min: 100, Max: 200} }, ""},
+		{"action delay min too low", func(c *Config) { c.Stealth.ActionDelay = DelayConfig{Min: 99, Max: 200} }, "stealth.action_delay.min must be at least 100"},
+		{"action delay min too high", func(c *Config) { c.Stealth.ActionDelay = DelayConfig{Min: 10001, Max: 20000} }, "stealth.action_delay.min must be at most 10000"},
+		{"action delay max not above min", func(c *Config) { c.Stealth.ActionDelay = DelayConfig{Min: 2000, Max: 2000} }, "stealth.action_delay.max must be greater than"},
+		{"per day at bounds", func(c *Config) { c.RateLimits.Connections.PerDay = 100 }, ""},
+		{"per day zero", func(c *Config) { c.RateLimits.Connections.PerDay = 0 }, "connections.per_day must be at least 1"},
+		{"per day over 100", func(c *Config) { c.RateLimits.Connections.PerDay = 101 }, "connections.per_day must be at most 100"},
+		{"weekday", func(c *Config) { c.Scheduling.ActiveDays = []string{"Monday", "friday"} }, ""},
+		{"bad weekday", func(c *Config) { c.Scheduling.ActiveDays = []string{"monday", "funday"} }, `"funday" is not a weekday name`},
+		{"timezone", func(c *Config) { c.Scheduling.Timezone = "Europe/Berlin" }, ""},
+		{"bad timezone", func(c *Config) { c.Scheduling.Timezone = "Mars/Olympus" }, `"Mars/Olympus" is not a valid IANA time zone`},
+		{"viewport too narrow", func(c *Config) { c.Browser.Viewport.Width = 640 }, "browser.viewport.width must be at least 800"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadRepoConfig(t)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	cfg := loadRepoConfig(t)
+	cfg.Stealth.ActionDelay = DelayConfig{Min: 50, Max: 10}
+	cfg.RateLimits.Connections.PerDay = 500
+	cfg.Scheduling.Timezone = "Nowhere/Else"
+	cfg.Storage.DatabasePath = ""
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate = nil, want errors")
+	}
+
+	want := []string{
+		"stealth.action_delay.min must be at least 100",
+		"stealth.action_delay.max must be greater than",
+		"connections.per_day must be at most 100",
+		"is not a valid IANA time zone",
+		"database path must be specified",
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("Validate error is missing %q:\n%v", w, err)
+		}
+	}
+	if lines := strings.Count(err.Error(), "\n") + 1; lines != len(want) {
+		t.Errorf("Validate reported %d errors, want %d:\n%v", lines, len(want), err)
+	}
+}
+
+// emptySelectors returns the names of selector fields that are empty or,
+// for lists, contain an empty selector
+func emptySelectors(selectors SelectorsConfig) []string {
+	var empty []string
+
+	v := reflect.ValueOf(selectors)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		switch field := v.Field(i).Interface().(type) {
+		case string:
+			if strings.TrimSpace(field) == "" {
+				empty = append(empty, name)
+			}
+		case []string:
+			if len(field) == 0 {
+				empty = append(empty, name)
+			}
+			for _, selector := range field {
+				if strings.TrimSpace(selector) == "" {
+					empty = append(empty, name)
+					break
+				}
+			}
+		default:
+			empty = append(empty, name+" (unsupported type)")
+		}
+	}
+
+	return empty
+}
+
+func TestDefaultSelectorsAreComplete(t *testing.T) {
+	if empty := emptySelectors(DefaultSelectors()); len(empty) > 0 {
+		t.Errorf("selectors without a default: %v", empty)
+	}
+}
+
+func TestApplyDefaultsKeepsExplicitSelectors(t *testing.T) {
+	var empty SelectorsConfig
+	empty.applyDefaults()
+	if !reflect.DeepEqual(empty, DefaultSelectors()) {
+		t.Error("an empty selectors section did not get every default")
+	}
+
+	selectors := SelectorsConfig{
+		SearchResultCard: "li.search-result",
+		ConnectButton:    []string{"button.connect"},
+	}
+	selectors.applyDefaults()
+
+	if selectors.SearchResultCard != "li.search-result" {
+		t.Errorf("SearchResultCard = %q, want the configured selector", selectors.SearchResultCard)
+	}
+	if !reflect.DeepEqual(selectors.ConnectButton, []string{"button.connect"}) {
+		t.Errorf("ConnectButton = %v, want the configured list, not merged with defaults", selectors.ConnectButton)
+	}
+	if selectors.SendNowButton != DefaultSelectors().SendNowButton {
+		t.Errorf("SendNowButton = %q, want the default", selectors.SendNowButton)
+	}
+
+	// The sample config sets only a few selectors
+	if empty := emptySelectors(loadRepoConfig(t).Selectors); len(empty) > 0 {
+		t.Errorf("selectors left empty after Load: %v", empty)
+	}
+}
+
+func TestGetStealthConfig(t *testing.T) {
+	cfg := &Config{}
+	cfg.Stealth = StealthConfig{
+		Techniques:  []string{TechniqueFocusBlur},
+		UsePoisson:  true,
+		ActionDelay: DelayConfig{Min: 1000, Max: 3000},
+		TypingDelay: DelayConfig{Min: 50, Max: 150, PoissonLambdaMs: 90},
+		ThinkTime:   DelayConfig{Min: 2000, Max: 5000},
+	}
+	cfg.PerModuleOverrides = map[string]StealthConfig{
+		"message": {
+			TypingDelay:         DelayConfig{Min: 80, Max: 250},
+			EnableMouseHovering: true,
+		},
+		"search": {
+			Techniques: []string{TechniqueIdleBreaks},
+		},
+	}
+
+	message := cfg.GetStealthConfig("message")
+	if want := (DelayConfig{Min: 80, Max: 250, PoissonLambdaMs: 90}); message.TypingDelay != want {
+		t.Errorf("message typing delay = %+v, want the override with the base lambda %+v", message.TypingDelay, want)
+	}
+	if !message.EnableMouseHovering {
+		t.Error("message EnableMouseHovering = false, want the override")
+	}
+	if message.ActionDelay != cfg.Stealth.ActionDelay || message.ThinkTime != cfg.Stealth.ThinkTime || !message.UsePoisson {
+		t.Errorf("message = %+v, want unset fields inherited from the base", message)
+	}
+	if !reflect.DeepEqual(message.Techniques, []string{TechniqueFocusBlur}) {
+		t.Errorf("message techniques = %v, want the base list", message.Techniques)
+	}
+
+	if search := cfg.GetStealthConfig("search"); !reflect.DeepEqual(search.Techniques, []string{TechniqueIdleBreaks}) {
+		t.Errorf("search techniques = %v, want the override", search.Techniques)
+	}
+
+	if unknown := cfg.GetStealthConfig("billing"); !reflect.DeepEqual(unknown, cfg.Stealth) {
+		t.Errorf("unknown module = %+v, want the base config", unknown)
+	}
+
+	// Merging never changes the base config
+	if cfg.Stealth.TypingDelay.Min != 50 || cfg.Stealth.EnableMouseHovering {
+		t.Errorf("base config changed to %+v", cfg.Stealth)
+	}
+}
+
+func TestDailyLimitsInSafeMode(t *testing.T) {
+	cfg := &Config{}
+	cfg.RateLimits.Connections.PerDay = 20
+	cfg.RateLimits.Messages.PerDay = 2
+	cfg.SafeMode.MaxConnectionsPerDay = 5
+	cfg.SafeMode.MaxMessagesPerDay = 3
+
+	if connections, messages := cfg.DailyLimits(false); connections != 20 || messages != 2 {
+		t.Errorf("DailyLimits(false) = %d, %d, want the normal 20, 2", connections, messages)
+	}
+	// Safe mode only ever lowers a limit
+	if connections, messages := cfg.DailyLimits(true); connections != 5 || messages != 2 {
+		t.Errorf("DailyLimits(true) = %d, %d, want 5, 2", connections, messages)
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestMigrateTechniquesKeepsSpoofsOptIn(t *testing.T) {
+	v := viper.New()
+	v.Set("stealth.enable_idle_breaks", false)
+
+	migrateTechniques(v)
+
+	got := v.GetStringSlice("stealth.techniques")
+	want := []string{TechniqueHumanMouse, TechniqueHumanTyping, TechniqueRandomScroll, TechniqueFocusBlur}
+	if len(got) != len(want) {
+		t.Fatalf("migrated techniques = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("migrated techniques = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMigrateTechniquesLeavesExplicitList(t *testing.T) {
+	v := viper.New()
+	v.Set("stealth.techniques", []string{TechniqueCanvas, TechniqueWebGL})
+
+	migrateTechniques(v)
+
+	if got := v.GetStringSlice("stealth.techniques"); len(got) != 2 || got[0] != TechniqueCanvas {
+		t.Errorf("explicit techniques changed to %v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validateOnce sync.Once
+	validate     *validator.Validate
+)
+
+// getValidator builds the struct tag validator on first use. Field names in
+// errors are the YAML keys, e.g. browser.viewport.width.
+func getValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+
+		_ = validate.RegisterValidation("weekday", func(fl validator.FieldLevel) bool {
+			_, ok := parseWeekday(fl.Field().String())
+			return ok
+		})
+
+		// Shared types such as DelayConfig and RateLimit are only bounded
+		// where they're used for the settings below
+		validate.RegisterStructValidation(validateStealthDelays, StealthConfig{})
+		validate.RegisterStructValidation(validateRateLimits, RateLimitsConfig{})
+	})
+
+	return validate
+}
+
+// validateTags checks the validate struct tags and the struct level rules,
+// returning one error per failed constraint
+func validateTags(c *Config) []error {
+	err := getValidator().Struct(c)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []error{err}
+	}
+
+	errs := make([]error, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		errs = append(errs, describeFieldError(fieldErr))
+	}
+	return errs
+}
+
+// validateStealthDelays bounds stealth.action_delay
+func validateStealthDelays(sl validator.StructLevel) {
+	stealth := sl.Current().Interface().(StealthConfig)
+	delay := stealth.ActionDelay
+
+	if delay.Min < 100 {
+		sl.ReportError(delay.Min, "action_delay.min", "Min", "min", "100")
+	}
+	if delay.Min > 10000 {
+		sl.ReportError(delay.Min, "action_delay.min", "Min", "max", "10000")
+	}
+	if delay.Max <= delay.Min {
+		sl.ReportError(delay.Max, "action_delay.max", "Max", "gtfield", "stealth.action_delay.min")
+	}
+}
+
+// validateRateLimits bounds rate_limits.connections.per_day
+func validateRateLimits(sl validator.StructLevel) {
+	limits := sl.Current().Interface().(RateLimitsConfig)
+	perDay := limits.Connections.PerDay
+
+	if perDay < 1 {
+		sl.ReportError(perDay, "connections.per_day", "PerDay", "min", "1")
+	}
+	if perDay > 100 {
+		sl.ReportError(perDay, "connections.per_day", "PerDay", "max", "100")
+	}
+}
+
+// describeFieldError turns a validator error into a message naming the YAML key
+func describeFieldError(fieldErr validator.FieldError) error {
+	key := strings.TrimPrefix(fieldErr.Namespace(), "Config.")
+	value := fieldErr.Value()
+
+	switch fieldErr.Tag() {
+	case "min":
+		return fmt.Errorf("%s must be at least %s (got %v)", key, fieldErr.Param(), value)
+	case "max":
+		return fmt.Errorf("%s must be at most %s (got %v)", key, fieldErr.Param(), value)
+	case "gtfield":
+		return fmt.Errorf("%s must be greater than %s (got %v)", key, fieldErr.Param(), value)
+	case "weekday":
+		return fmt.Errorf("%s: %q is not a weekday name", key, value)
+	case "timezone":
+		return fmt.Errorf("%s: %q is not a valid IANA time zone", key, value)
+	default:
+		return fmt.Errorf("%s failed %s validation (got %v)", key, fieldErr.Tag(), value)
+	}
+}
+
+// parseWeekday matches a weekday name such as "monday", ignoring case
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), strings.TrimSpace(name)) {
+			return day, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,195 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/message"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+)
+
+const (
+	notificationsURL   = "https://www.linkedin.com/notifications/"
+	connectionsListURL = "https://www.linkedin.com/mynetwork/invite-connect/connections/"
+)
+
+// acceptedInvitationPattern matches notifications such as "Jane Doe accepted
+// your invitation to connect"
+var acceptedInvitationPattern = regexp.MustCompile(`(?i)accepted your invitation`)
+
+// IsAcceptanceNotification reports whether notification text announces an
+// accepted connection request
+func IsAcceptanceNotification(text string) bool {
+	return acceptedInvitationPattern.MatchString(text)
+}
+
+// CheckAcceptances marks pending connection requests as accepted, using the
+// notifications page when NotificationPolling is enabled and the connections
+// list otherwise or when that fails
+func (s *Service) CheckAcceptances(ctx context.Context) ([]string, error) {
+	log := logger.FromContext(ctx)
+
+	if s.cfg.Connection.NotificationPolling {
+		accepted, err := s.PollNotificationsForAcceptances(ctx)
+		if err == nil {
+			return accepted, nil
+		}
+		log.Warnf("Notification polling failed, checking connections list: %v", err)
+	}
+
+	return s.PollConnectionsForAcceptances(ctx)
+}
+
+// PollNotificationsForAcceptances reads the notifications page and marks the
+// pending requests it reports as accepted. It returns their profile URLs.
+func (s *Service) PollNotificationsForAcceptances(ctx context.Context) ([]string, error) {
+	log := logger.FromContext(ctx)
+
+	page, err := s.openPage(ctx, notificationsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to navigate to notifications: %w", err)
+	}
+
+	urls, err := s.acceptanceNotificationURLs(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Found %d acceptance notifications", len(urls))
+	return s.markAccepted(ctx, urls)
+}
+
+// acceptanceNotificationURLs returns the profile URLs linked from the
+// acceptance notifications on an open notifications page
+func (s *Service) acceptanceNotificationURLs(ctx context.Context, page *rod.Page) ([]string, error) {
+	log := logger.FromContext(ctx)
+
+	cards, err := page.Elements(s.cfg.Selectors.NotificationCard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notifications (selector NotificationCard): %w", err)
+	}
+
+	var urls []string
+	for _, card := range cards {
+		text, _ := card.Text()
+		if !IsAcceptanceNotification(text) {
+			continue
+		}
+
+		link, err := card.Element(s.cfg.Selectors.NotificationProfileLink)
+		if err != nil {
+			log.Debugf("Selector NotificationProfileLink not found: %v", err)
+			continue
+		}
+		if href, err := link.Attribute("href"); err == nil && href != nil {
+			urls = append(urls, *href)
+		}
+	}
+
+	return urls, nil
+}
+
+// PollConnectionsForAcceptances reads the most recent entries of the
+// connections list and marks pending requests found there as accepted
+func (s *Service) PollConnectionsForAcceptances(ctx context.Context) ([]string, error) {
+	page, err := s.openPage(ctx, connectionsListURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to navigate to connections: %w", err)
+	}
+
+	links, err := page.Elements(s.cfg.Selectors.ConnectionCardLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find connections (selector ConnectionCardLink): %w", err)
+	}
+
+	var urls []string
+	for _, link := range links {
+		if href, err := link.Attribute("href"); err == nil && href != nil {
+			urls = append(urls, *href)
+		}
+	}
+
+	return s.markAccepted(ctx, urls)
+}
+
+// openPage navigates to url and waits for it to settle
+func (s *Service) openPage(ctx context.Context, url string) (*rod.Page, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.browser.Navigate(url); err != nil {
+		return nil, err
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	s.stealth.RandomScroll(page)
+	s.stealth.RandomDelay("scroll")
+
+	return page, nil
+}
+
+// markAccepted updates the pending requests among profileURLs to accepted
+// and returns the stored URLs that were updated
+func (s *Service) markAccepted(ctx context.Context, profileURLs []string) ([]string, error) {
+	log := logger.FromContext(ctx)
+
+	pending, err := s.store.GetPendingConnectionURLs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending connections: %w", err)
+	}
+
+	pendingByKey := make(map[string]string, len(pending))
+	for _, url := range pending {
+		pendingByKey[profileURLKey(url)] = url
+	}
+
+	var accepted []string
+	for _, url := range profileURLs {
+		stored, ok := pendingByKey[profileURLKey(url)]
+		if !ok {
+			continue
+		}
+		delete(pendingByKey, profileURLKey(url))
+
+		err := s.store.UpdateConnectionStatus(stored, "accepted")
+		if errors.Is(err, storage.ErrStateConflict) {
+			log.Warnf("Connection state not updated for %s: %v", stored, err)
+		} else if err != nil {
+			log.Warnf("Failed to mark %s as accepted: %v", stored, err)
+			continue
+		}
+
+		// Draw the message delay once, when the acceptance is seen
+		messageAfter := time.Now().Add(message.MessageDelay(float64(s.cfg.Messaging.DelayAfterConnectionHours), s.cfg.Messaging.DelayJitterHours))
+		if err := s.store.SetMessageAfterForProfile(stored, messageAfter); err != nil {
+			log.Warnf("Failed to save message delay for %s: %v", stored, err)
+		}
+
+		s.store.LogActivityAsync("connection_accepted", stored, "success", "")
+		accepted = append(accepted, stored)
+	}
+
+	log.Infof("Detected %d accepted connection requests", len(accepted))
+	return accepted, nil
+}
+
+// profileURLKey reduces a profile URL to its /in/<slug> path so relative and
+// absolute links, query strings and trailing slashes compare equal
+func profileURLKey(url string) string {
+	url = strings.Split(url, "?")[0]
+	if idx := strings.Index(url, "/in/"); idx >= 0 {
+		url = url[idx:]
+	}
+	return strings.ToLower(strings.TrimSuffix(url, "/"))
+}
@@ -0,0 +1,124 @@
+package connect
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+// notificationsFixture has two acceptance notifications among others
+const notificationsFixture = `<html><body><main>
+<article class="nt-card">
+  <a href="https://www.linkedin.com/in/jane?miniProfileUrn=urn%3Ali%3Afs_miniProfile%3A1">Jane Doe</a>
+  <span>Jane Doe accepted your invitation. Send a message now.</span>
+</article>
+<article class="nt-card">
+  <a href="https://www.linkedin.com/in/jim/">Jim Beam</a>
+  <span>Jim Beam viewed your profile</span>
+</article>
+<article class="nt-card">
+  <a href="/in/john/">John Roe</a>
+  <span>John Roe Accepted Your Invitation to connect</span>
+</article>
+</main></body></html>`
+
+// requestConnection saves a pending connection request to a new profile
+func requestConnection(t *testing.T, store *storage.Storage, username string) *storage.Profile {
+	t.Helper()
+
+	profile := queueProfile(t, store, username)
+	if err := store.SaveConnectionRequest(&storage.ConnectionRequest{ProfileID: profile.ID, ProfileURL: profile.ProfileURL, Status: "pending"}); err != nil {
+		t.Fatalf("SaveConnectionRequest: %v", err)
+	}
+	if err := store.TransitionState(profile.ProfileURL, storage.StateQueued, storage.StateRequested); err != nil {
+		t.Fatalf("TransitionState: %v", err)
+	}
+	return profile
+}
+
+func TestPollNotificationsForAcceptances(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(notificationsFixture); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	s, store := newStoreTestService(t)
+	s.cfg.Selectors = config.DefaultSelectors()
+	for _, username := range []string{"jane", "jim", "john", "joan"} {
+		requestConnection(t, store, username)
+	}
+
+	urls, err := s.acceptanceNotificationURLs(context.Background(), page)
+	if err != nil {
+		t.Fatalf("acceptanceNotificationURLs: %v", err)
+	}
+	if len(urls) != 2 {
+		t.Fatalf("found %d acceptance notifications (%v), want 2", len(urls), urls)
+	}
+
+	accepted, err := s.markAccepted(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("markAccepted: %v", err)
+	}
+	sort.Strings(accepted)
+	if want := []string{"https://www.linkedin.com/in/jane", "https://www.linkedin.com/in/john"}; !reflect.DeepEqual(accepted, want) {
+		t.Errorf("accepted = %v, want %v", accepted, want)
+	}
+
+	pending, err := store.GetPendingConnectionURLs()
+	if err != nil {
+		t.Fatalf("GetPendingConnectionURLs: %v", err)
+	}
+	sort.Strings(pending)
+	if want := []string{"https://www.linkedin.com/in/jim", "https://www.linkedin.com/in/joan"}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("still pending = %v, want %v", pending, want)
+	}
+}
+
+func TestMarkAcceptedIgnoresUnknownProfiles(t *testing.T) {
+	s, store := newStoreTestService(t)
+	requestConnection(t, store, "jane")
+
+	accepted, err := s.markAccepted(context.Background(), []string{
+		"https://www.linkedin.com/in/stranger",
+		"https://www.linkedin.com/in/jane/",
+		"https://www.linkedin.com/in/jane?trk=notification",
+	})
+	if err != nil {
+		t.Fatalf("markAccepted: %v", err)
+	}
+	if want := []string{"https://www.linkedin.com/in/jane"}; !reflect.DeepEqual(accepted, want) {
+		t.Errorf("accepted = %v, want %v once", accepted, want)
+	}
+
+	profile, err := store.GetProfileByURL("https://www.linkedin.com/in/jane")
+	if err != nil {
+		t.Fatalf("GetProfileByURL: %v", err)
+	}
+	if profile.ConnectionState != storage.StateAccepted {
+		t.Errorf("connection state = %s, want %s", profile.ConnectionState, storage.StateAccepted)
+	}
+}
+
+func TestIsAcceptanceNotification(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Jane Doe accepted your invitation to connect", true},
+		{"JANE DOE ACCEPTED YOUR INVITATION", true},
+		{"Jane Doe viewed your profile", false},
+		{"Jane Doe invited you to connect", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsAcceptanceNotification(tt.text); got != tt.want {
+			t.Errorf("IsAcceptanceNotification(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
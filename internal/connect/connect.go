@@ -2,95 +2,471 @@ package connect
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/circuit"
 	"linkedin-automation/internal/config"
 	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/notify"
 	"linkedin-automation/internal/stealth"
 	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/template"
 
 	"github.com/go-rod/rod"
-	"github.com/sirupsen/logrus"
 )
 
+// retryActionConnect is the retry queue action type for connection requests
+const retryActionConnect = "connection_request"
+
+// defaultBatchSize is used when Connection.Batch.BatchSize is unset
+const defaultBatchSize = 5
+
+// elementWaitTimeout is how long to wait for profile buttons and invitation
+// modal buttons to render
+const elementWaitTimeout = 10 * time.Second
+
 type Service struct {
-	browser *browser.Context
-	store   *storage.Storage
-	cfg     *config.Config
-	log     *logrus.Logger
+	browser   *browser.Context
+	store     *storage.Storage
+	cfg       *config.Config
+	stealth   *stealth.Stealth
+	templates *template.Engine
+	breaker   *circuit.Breaker
+
+	// notifier alerts the operator when the weekly limit is hit; nil
+	// without a Slack webhook
+	notifier *notify.SlackNotifier
+
+	// withdrawalAlerted is set once the high withdrawal rate alert was sent
+	// and cleared when the rate drops back under the threshold
+	withdrawalAlerted bool
+
+	// schedule spreads requests over each hour; nil without
+	// stealth.activity_smoothing
+	schedule *stealth.ActivitySchedule
 }
 
 func New(browser *browser.Context, store *storage.Storage, cfg *config.Config) *Service {
-	return &Service{
-		browser: browser,
-		store:   store,
-		cfg:     cfg,
-		log:     logger.Get(),
+	s := &Service{
+		browser:   browser,
+		store:     store,
+		cfg:       cfg,
+		stealth:   browser.NewStealth("connect"),
+		templates: template.New(cfg),
+		breaker:   newBreaker(cfg),
+	}
+
+	if webhook := cfg.Notify.CAPTCHAWebhookURL; notify.IsSlackWebhook(webhook) {
+		s.notifier = notify.NewSlackNotifier(webhook, cfg.Notify.Slack)
+	}
+
+	if s.stealth.ActivitySmoothing() && !cfg.DryRun {
+		s.schedule = stealth.NewActivitySchedule(map[string]int{
+			stealth.ActionConnection: cfg.RateLimits.Connections.PerHour,
+		})
+	}
+
+	return s
+}
+
+// newBreaker creates the connection request breaker. Profiles skipped for
+// requiring an email don't count as LinkedIn failures.
+func newBreaker(cfg *config.Config) *circuit.Breaker {
+	settings := circuit.SettingsFromConfig(cfg.RateLimits.CircuitBreaker)
+	settings.IsSuccessful = func(err error) bool {
+		return err == nil || errors.Is(err, ErrEmailRequired)
 	}
+	return circuit.New("connect", settings)
+}
+
+// BreakerStats reports the state of the connection request circuit breaker
+func (s *Service) BreakerStats() circuit.BreakerSnapshot {
+	return s.breaker.Stats()
 }
 
-// SendConnectionRequests sends connection requests to profiles
+// SendConnectionRequests sends connection requests to profiles in batches of
+// Connection.Batch.BatchSize, pausing between batches
 func (s *Service) SendConnectionRequests(ctx context.Context, profiles []*storage.Profile) (int, error) {
-	s.log.Info("Starting to send connection requests...")
+	log := logger.FromContext(ctx)
+
+	log.Info("Starting to send connection requests...")
+
+	batches := splitBatches(sortByDegree(profiles), s.cfg.Connection.Batch.BatchSize)
+	sent, err := runBatches(ctx, batches,
+		func(batch []*storage.Profile, sentBefore int) (int, bool, error) {
+			return s.sendBatch(ctx, batch, sentBefore, len(profiles))
+		},
+		func() { s.pauseBetweenBatches(ctx) },
+	)
+	if err != nil {
+		return sent, err
+	}
+
+	log.Infof("Sent %d connection requests", sent)
+	return sent, nil
+}
+
+// runBatches calls send for each batch in turn and pause between batches,
+// returning the total sent. It stops early when send asks to or the context
+// is cancelled.
+func runBatches(ctx context.Context, batches [][]*storage.Profile, send func(batch []*storage.Profile, sentBefore int) (int, bool, error), pause func()) (int, error) {
+	log := logger.FromContext(ctx)
 
 	sent := 0
 
-	for _, profile := range profiles {
+	for i, batch := range batches {
 		select {
 		case <-ctx.Done():
-			s.log.Info("Context cancelled, stopping connection requests")
+			log.Info("Context cancelled, stopping connection requests")
 			return sent, ctx.Err()
 		default:
 		}
 
-		// Check rate limits
-		if !s.canSendConnection() {
-			s.log.Warn("Rate limit reached for connections")
+		log.Infof("Starting connection batch %d/%d (%d profiles)", i+1, len(batches), len(batch))
+
+		batchSent, stop, err := send(batch, sent)
+		sent += batchSent
+		if err != nil {
+			return sent, err
+		}
+
+		log.Infof("Finished connection batch %d/%d: %d sent", i+1, len(batches), batchSent)
+
+		if stop {
 			break
 		}
 
+		// Only pause when something was actually sent and more batches remain
+		if batchSent > 0 && i < len(batches)-1 {
+			pause()
+		}
+	}
+
+	return sent, nil
+}
+
+// sendBatch sends connection requests to one batch of profiles and returns
+// how many were sent. The bool is true when the run should stop early (rate
+// limit, open circuit or cancellation).
+func (s *Service) sendBatch(ctx context.Context, batch []*storage.Profile, sentBefore, total int) (int, bool, error) {
+	log := logger.FromContext(ctx)
+
+	sent := 0
+
+	for _, profile := range batch {
+		select {
+		case <-ctx.Done():
+			log.Info("Context cancelled, stopping connection requests")
+			return sent, true, ctx.Err()
+		default:
+		}
+
 		// Check if already sent
 		alreadySent, err := s.store.IsConnectionSent(profile.ProfileURL)
 		if err != nil {
-			s.log.Errorf("Failed to check connection status: %v", err)
+			log.Errorf("Failed to check connection status: %v", err)
 			continue
 		}
 
 		if alreadySent {
-			s.log.Debugf("Connection already sent to %s, skipping", profile.ProfileURL)
+			log.Debugf("Connection already sent to %s, skipping", profile.ProfileURL)
+			continue
+		}
+
+		if s.browser.VisitedRecently(ctx, profile.ProfileURL) {
+			continue
+		}
+
+		if s.isInactive(profile) {
+			log.Debugf("Profile %s was last active %s, skipping", profile.ProfileURL, profile.LastActiveEstimate.Format("2006-01-02"))
+			s.store.LogActivityAsync("connection_request", profile.ProfileURL, "skipped", "inactive profile")
+			continue
+		}
+
+		// Check rate limits last so skipped profiles don't use up a slot
+		if !s.canSendConnection(ctx) {
+			log.Warn("Rate limit reached for connections")
+			return sent, true, nil
+		}
+
+		if s.cfg.DryRun {
+			log.Infof("[dry-run] Would send connection request to %s", profile.ProfileURL)
 			continue
 		}
 
+		if err := s.browser.Actions().Begin(); err != nil {
+			log.Info("Shutting down, stopping connection requests")
+			return sent, true, nil
+		}
+
+		s.transitionState(ctx, profile.ProfileURL, storage.StateDiscovered, storage.StateQueued)
+
 		// Send connection request
-		if err := s.sendConnectionRequest(profile); err != nil {
-			s.log.Errorf("Failed to send connection to %s: %v", profile.ProfileURL, err)
-			s.store.LogActivity("connection_request", profile.ProfileURL, "failed", err.Error())
+		err = s.breaker.Execute(func() error {
+			return s.sendConnectionRequest(ctx, profile)
+		})
+		s.browser.Actions().End()
+		if err != nil {
+			s.releaseProfile(ctx, profile.ProfileURL)
+		}
+		if errors.Is(err, circuit.ErrCircuitOpen) {
+			log.Warn("Circuit breaker open, pausing connection requests")
+			return sent, true, nil
+		}
+		if errors.Is(err, ErrWeeklyLimitReached) {
+			s.handleWeeklyLimit(ctx)
+			return sent, true, err
+		}
+		if errors.Is(err, ErrEmailRequired) {
+			continue
+		}
+		if err != nil {
+			log.Errorf("Failed to send connection to %s: %v", profile.ProfileURL, err)
+			s.store.LogActivityAsync("connection_request", profile.ProfileURL, "failed", err.Error())
+			s.scheduleRetry(ctx, profile, err)
 			continue
 		}
 
 		sent++
-		s.log.Infof("Connection request sent to %s (%d/%d)", profile.Name, sent, len(profiles))
+		if s.schedule != nil {
+			s.schedule.UseSlot(stealth.ActionConnection)
+		}
+		log.Infof("Connection request sent to %s (%d/%d)", profile.Name, sentBefore+sent, total)
 
 		// Random delay between requests
-		s.browser.GetStealth().RandomDelay("action")
+		s.waitBeforeNextRequest(ctx)
+	}
+
+	return sent, false, nil
+}
+
+// pauseBetweenBatches waits for Connection.Batch.BatchPause, falling back to
+// the think time when no pause is configured
+func (s *Service) pauseBetweenBatches(ctx context.Context) {
+	log := logger.FromContext(ctx)
+
+	pause := s.cfg.Connection.Batch.BatchPause
+	if pause.Max <= 0 {
+		log.Info("Pausing between connection batches")
+		s.stealth.RandomDelay("think")
+		return
+	}
+
+	log.Infof("Pausing %d-%dms between connection batches", pause.Min, pause.Max)
+	s.stealth.DelayWith("batch", pause)
+}
+
+// sortByDegree returns a copy of profiles ordered by connection degree so
+// 2nd degree connections are tried before 3rd+, with unknown degrees last
+func sortByDegree(profiles []*storage.Profile) []*storage.Profile {
+	sorted := make([]*storage.Profile, len(profiles))
+	copy(sorted, profiles)
+
+	rank := func(p *storage.Profile) int {
+		if p.ConnectionDegree == 0 {
+			return math.MaxInt
+		}
+		return p.ConnectionDegree
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+
+	return sorted
+}
+
+// splitBatches groups profiles into batches of at most size profiles
+func splitBatches(profiles []*storage.Profile, size int) [][]*storage.Profile {
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	var batches [][]*storage.Profile
+	for start := 0; start < len(profiles); start += size {
+		end := start + size
+		if end > len(profiles) {
+			end = len(profiles)
+		}
+		batches = append(batches, profiles[start:end])
+	}
+
+	return batches
+}
+
+// transitionState records a connection lifecycle change. Failures are only
+// logged: a mismatch usually means the profile was left in another state by an
+// earlier run, which shouldn't block sending.
+func (s *Service) transitionState(ctx context.Context, profileURL string, from, to storage.ConnectionState) {
+	if err := s.store.TransitionState(profileURL, from, to); err != nil {
+		logger.FromContext(ctx).Warnf("Connection state not updated for %s: %v", profileURL, err)
+	}
+}
+
+// releaseProfile moves a profile queued for a request that wasn't sent back
+// to discovered, so a failed send doesn't leave it queued forever. Profiles
+// whose request was recorded anyway, e.g. when the weekly limit showed after
+// sending, keep their state.
+func (s *Service) releaseProfile(ctx context.Context, profileURL string) {
+	if sent, err := s.store.IsConnectionSent(profileURL); err != nil || sent {
+		return
+	}
+	s.transitionState(ctx, profileURL, storage.StateQueued, storage.StateDiscovered)
+}
+
+// isInactive reports whether a profile's activity estimate is older than the
+// MinRecentActivityDays of the search target it was found through. Profiles
+// without an estimate are never treated as inactive.
+func (s *Service) isInactive(profile *storage.Profile) bool {
+	if profile.LastActiveEstimate == nil {
+		return false
+	}
 
-		// Longer delay every few requests
-		if sent%5 == 0 {
-			s.browser.GetStealth().RandomDelay("think")
+	for _, target := range s.cfg.Search.Targets {
+		if target.MinRecentActivityDays <= 0 {
+			continue
+		}
+		if target.Keywords != profile.Keywords || target.Location != profile.Location {
+			continue
 		}
+
+		cutoff := time.Now().AddDate(0, 0, -target.MinRecentActivityDays)
+		return profile.LastActiveEstimate.Before(cutoff)
+	}
+
+	return false
+}
+
+// ProcessRetryQueue retries connection requests that previously failed and
+// whose backoff has elapsed
+func (s *Service) ProcessRetryQueue(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	retries, err := s.store.GetDueRetries(retryActionConnect)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load retry queue: %w", err)
+	}
+
+	if len(retries) == 0 {
+		return 0, nil
 	}
 
-	s.log.Infof("Sent %d connection requests", sent)
+	log.Infof("Retrying %d failed connection requests", len(retries))
+
+	sent := 0
+	for _, retry := range retries {
+		select {
+		case <-ctx.Done():
+			return sent, ctx.Err()
+		default:
+		}
+
+		profile, err := s.store.GetProfileByURL(retry.ProfileURL)
+		if err != nil || profile == nil {
+			log.Errorf("Failed to load profile for retry %s: %v", retry.ProfileURL, err)
+			continue
+		}
+
+		if alreadySent, err := s.store.IsConnectionSent(profile.ProfileURL); err == nil && alreadySent {
+			s.store.RemoveRetry(profile.ID, retryActionConnect)
+			continue
+		}
+
+		if s.browser.VisitedRecently(ctx, profile.ProfileURL) {
+			continue
+		}
+
+		if !s.canSendConnection(ctx) {
+			break
+		}
+
+		if err := s.browser.Actions().Begin(); err != nil {
+			log.Info("Shutting down, stopping retry queue")
+			break
+		}
+
+		s.transitionState(ctx, profile.ProfileURL, storage.StateDiscovered, storage.StateQueued)
+
+		err = s.breaker.Execute(func() error {
+			return s.sendConnectionRequest(ctx, profile)
+		})
+		s.browser.Actions().End()
+		if err != nil {
+			s.releaseProfile(ctx, profile.ProfileURL)
+		}
+		if errors.Is(err, circuit.ErrCircuitOpen) {
+			log.Warn("Circuit breaker open, pausing retry queue")
+			break
+		}
+		if errors.Is(err, ErrWeeklyLimitReached) {
+			s.handleWeeklyLimit(ctx)
+			return sent, err
+		}
+		if errors.Is(err, ErrEmailRequired) {
+			if err := s.store.RemoveRetry(profile.ID, retryActionConnect); err != nil {
+				log.Warnf("Failed to remove retry entry for %s: %v", profile.ProfileURL, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Errorf("Retry failed for %s: %v", profile.ProfileURL, err)
+			s.store.LogActivityAsync("connection_request", profile.ProfileURL, "retry_failed", err.Error())
+			s.scheduleRetry(ctx, profile, err)
+			continue
+		}
+
+		if err := s.store.RemoveRetry(profile.ID, retryActionConnect); err != nil {
+			log.Warnf("Failed to remove retry entry for %s: %v", profile.ProfileURL, err)
+		}
+
+		sent++
+		if s.schedule != nil {
+			s.schedule.UseSlot(stealth.ActionConnection)
+		}
+		s.stealth.RandomDelay("action")
+	}
+
+	log.Infof("Sent %d connection requests from retry queue", sent)
 	return sent, nil
 }
 
+// scheduleRetry queues a failed connection request for a later attempt
+func (s *Service) scheduleRetry(ctx context.Context, profile *storage.Profile, cause error) {
+	log := logger.FromContext(ctx)
+
+	err := s.store.EnqueueRetry(profile.ID, retryActionConnect, cause)
+	switch {
+	case errors.Is(err, storage.ErrRetriesExhausted):
+		log.Warnf("Giving up on %s after %d retries", profile.ProfileURL, storage.MaxRetryAttempts)
+	case err != nil:
+		log.Errorf("Failed to queue retry for %s: %v", profile.ProfileURL, err)
+	}
+}
+
 // sendConnectionRequest sends a connection request to a single profile
-func (s *Service) sendConnectionRequest(profile *storage.Profile) error {
-	s.log.Infof("Sending connection request to: %s", profile.ProfileURL)
+func (s *Service) sendConnectionRequest(ctx context.Context, profile *storage.Profile) error {
+	log := logger.FromContext(ctx)
+
+	log.Infof("Sending connection request to: %s", profile.ProfileURL)
+
+	// Read the search result snippet before clicking through
+	s.stealth.ProfilePreviewDelay(profile.JobTitle, profile.Headline)
+
+	// Sometimes connect straight from the search results, falling back to
+	// the profile page when the result or its Connect button isn't there
+	if !s.stealth.VisitProfileBeforeConnect() {
+		sent, err := s.connectFromSearchResults(ctx, profile)
+		if sent || err != nil {
+			return err
+		}
+	}
 
 	// Navigate to profile
 	if err := s.browser.Navigate(profile.ProfileURL); err != nil {
@@ -98,37 +474,62 @@ func (s *Service) sendConnectionRequest(profile *storage.Profile) error {
 	}
 
 	page := s.browser.GetPage()
-	stealth := s.browser.GetStealth()
+	stealth := s.stealth
 
 	// Simulate reading the profile
 	stealth.SimulateReading(page)
+	s.queuePeopleAlsoViewed(ctx, page, profile)
 	stealth.RandomDelay("think")
 
 	// Find the Connect button
 	connectButton, err := s.findConnectButton(page)
 	if err != nil {
+		if s.cfg.Connection.FollowIfConnectUnavailable {
+			log.Infof("Connect unavailable, following %s instead", profile.ProfileURL)
+			return s.followProfile(ctx, page, profile)
+		}
 		return fmt.Errorf("connect button not found: %w", err)
 	}
 
 	// Click Connect button
-	if err := stealth.HumanClick(connectButton); err != nil {
+	if err := stealth.HumanClick(ctx, connectButton); err != nil {
 		return fmt.Errorf("failed to click connect: %w", err)
 	}
 
 	stealth.RandomDelay("action")
 
+	return s.completeConnectionRequest(ctx, page, profile)
+}
+
+// completeConnectionRequest fills in the invitation modal opened by clicking
+// Connect, sends it and records the request
+func (s *Service) completeConnectionRequest(ctx context.Context, page *rod.Page, profile *storage.Profile) error {
+	log := logger.FromContext(ctx)
+	stealth := s.stealth
+
+	if err := s.checkWeeklyLimit(ctx, page); err != nil {
+		return err
+	}
+
+	if err := s.handleEmailRequired(ctx, page, profile); err != nil {
+		return err
+	}
+
 	// Check if we need to add a note
+	templateID, ruleID := 0, 0
+	note := ""
+	var err error
 	if s.cfg.Connection.SendNote {
-		if err := s.addConnectionNote(page, stealth, profile); err != nil {
-			s.log.Warnf("Failed to add note, sending without note: %v", err)
+		if note, templateID, ruleID, err = s.addConnectionNote(ctx, page, stealth, profile); err != nil {
+			log.Warnf("Failed to add note, sending without note: %v", err)
 			// Try to send without note
-			if err := s.clickSendButton(page, stealth, false); err != nil {
+			if err := s.clickSendButton(ctx, page, stealth, false); err != nil {
 				return fmt.Errorf("failed to send connection: %w", err)
 			}
 		}
 	} else {
 		// Send without note
-		if err := s.clickSendButton(page, stealth, false); err != nil {
+		if err := s.clickSendButton(ctx, page, stealth, false); err != nil {
 			return fmt.Errorf("failed to send connection: %w", err)
 		}
 	}
@@ -138,97 +539,204 @@ func (s *Service) sendConnectionRequest(profile *storage.Profile) error {
 		ProfileID:  profile.ID,
 		ProfileURL: profile.ProfileURL,
 		SentAt:     time.Now(),
+		Note:       note,
 		Status:     "pending",
+		TemplateID: templateID,
+		NoteRuleID: ruleID,
 	}
 
 	if err := s.store.SaveConnectionRequest(connectionReq); err != nil {
 		return fmt.Errorf("failed to save connection request: %w", err)
 	}
 
-	s.store.LogActivity("connection_request", profile.ProfileURL, "success", "")
+	s.transitionState(ctx, profile.ProfileURL, storage.StateQueued, storage.StateRequested)
 
-	return nil
+	s.store.LogActivityAsync("connection_request", profile.ProfileURL, "success", "")
+
+	// LinkedIn may also show the weekly limit once the invitation is sent,
+	// which is checked after saving since this invitation went out
+	return s.checkWeeklyLimit(ctx, page)
 }
 
-// findConnectButton finds the Connect button on a profile page
-func (s *Service) findConnectButton(page *rod.Page) (*rod.Element, error) {
-	// LinkedIn has different button structures, try multiple selectors
-	selectors := []string{
-		"button[aria-label*='Connect']",
-		"button.pvs-profile-actions__action:has-text('Connect')",
-		"button:has-text('Connect')",
-		".pvs-profile-actions button:has-text('Connect')",
+// followProfile clicks the Follow button on a profile whose connection
+// requests are disabled and records it with status "followed"
+func (s *Service) followProfile(ctx context.Context, page *rod.Page, profile *storage.Profile) error {
+	stealth := s.stealth
+
+	followButton, err := browser.FindFirst(page, "FollowButton", s.cfg.Selectors.FollowButton, elementWaitTimeout)
+	if err != nil {
+		return fmt.Errorf("neither connect nor follow button found: %w", err)
 	}
 
-	for _, selector := range selectors {
-		element, err := page.Element(selector)
-		if err == nil {
-			return element, nil
-		}
+	if err := stealth.HumanClick(ctx, followButton); err != nil {
+		return fmt.Errorf("failed to click follow: %w", err)
+	}
+
+	stealth.RandomDelay("action")
+
+	followReq := &storage.ConnectionRequest{
+		ProfileID:  profile.ID,
+		ProfileURL: profile.ProfileURL,
+		SentAt:     time.Now(),
+		Status:     "followed",
+	}
+
+	if err := s.store.SaveConnectionRequest(followReq); err != nil {
+		return fmt.Errorf("failed to save follow: %w", err)
 	}
 
-	return nil, fmt.Errorf("connect button not found with any selector")
+	s.transitionState(ctx, profile.ProfileURL, storage.StateQueued, storage.StateFollowed)
+
+	s.store.LogActivityAsync("follow", profile.ProfileURL, "success", "")
+
+	return nil
+}
+
+// findConnectButton finds the Connect button on a profile page
+func (s *Service) findConnectButton(page *rod.Page) (*rod.Element, error) {
+	// LinkedIn has different button structures, try multiple selectors
+	return browser.FindFirst(page, "ConnectButton", s.cfg.Selectors.ConnectButton, elementWaitTimeout)
 }
 
-// addConnectionNote adds a personalized note to the connection request
-func (s *Service) addConnectionNote(page *rod.Page, st *stealth.Stealth, profile *storage.Profile) error {
+// addConnectionNote adds a personalized note to the connection request and
+// returns the note sent along with the IDs of the template and preference
+// rule used
+func (s *Service) addConnectionNote(ctx context.Context, page *rod.Page, st *stealth.Stealth, profile *storage.Profile) (string, int, int, error) {
 	// Look for "Add a note" button
-	addNoteButton, err := page.Element("button[aria-label*='Add a note']")
+	addNoteButton, err := browser.FindFirst(page, "AddNoteButton", s.cfg.Selectors.AddNoteButton, elementWaitTimeout)
 	if err != nil {
-		// Try alternative selector
-		addNoteButton, err = page.Element("button:has-text('Add a note')")
-		if err != nil {
-			return fmt.Errorf("add note button not found: %w", err)
-		}
+		return "", 0, 0, fmt.Errorf("add note button not found: %w", err)
 	}
 
 	// Click "Add a note"
-	if err := st.HumanClick(addNoteButton); err != nil {
-		return fmt.Errorf("failed to click add note: %w", err)
+	if err := st.HumanClick(ctx, addNoteButton); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to click add note: %w", err)
 	}
 
 	st.RandomDelay("action")
 
+	// Free accounts may be asked to upgrade before they can personalize
+	noteAllowed, err := s.HandlePremiumNotePrompt(ctx, page)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to handle premium prompt: %w", err)
+	}
+	if !noteAllowed {
+		return "", 0, 0, fmt.Errorf("personalized note requires premium")
+	}
+
 	// Find note textarea
-	noteTextarea, err := st.WaitForElement(page, "textarea[name='message']", 5*time.Second)
+	noteTextarea, err := st.WaitForElement(page, s.cfg.Selectors.NoteTextarea, 5*time.Second)
 	if err != nil {
-		return fmt.Errorf("note textarea not found: %w", err)
+		return "", 0, 0, fmt.Errorf("note textarea not found (selector NoteTextarea): %w", err)
 	}
 
 	// Generate personalized note
-	note := s.generateNote(profile)
+	note, templateID, ruleID, err := s.generateNote(profile)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid connection note: %w", err)
+	}
 
 	// Type note with human-like behavior
-	if err := st.HumanType(noteTextarea, note); err != nil {
-		return fmt.Errorf("failed to type note: %w", err)
+	if err := st.HumanType(ctx, noteTextarea, note); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to type note: %w", err)
 	}
 
+	s.logNoteCharCount(ctx, page, len([]rune(note)))
+
 	st.RandomDelay("think")
 
 	// Click Send button
-	return s.clickSendButton(page, st, true)
+	if err := s.clickSendButton(ctx, page, st, true); err != nil {
+		return "", 0, 0, err
+	}
+
+	return note, templateID, ruleID, nil
+}
+
+// HandlePremiumNotePrompt detects the "Upgrade to Premium to personalize your
+// invitation" prompt that can appear after clicking "Add a note" and dismisses
+// it via "Skip for free". It returns false when the prompt was shown, meaning
+// no note can be added, and true when the note modal is available.
+func (s *Service) HandlePremiumNotePrompt(ctx context.Context, page *rod.Page) (bool, error) {
+	has, _, err := page.Has(s.cfg.Selectors.PremiumNotePrompt)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for premium prompt (selector PremiumNotePrompt): %w", err)
+	}
+	if !has {
+		return true, nil
+	}
+
+	st := s.stealth
+
+	skipButton, err := browser.FindFirst(page, "PremiumSkipButton", s.cfg.Selectors.PremiumSkipButton, elementWaitTimeout)
+	if err != nil {
+		return false, fmt.Errorf("premium prompt shown but skip button not found: %w", err)
+	}
+
+	if err := st.HumanClick(ctx, skipButton); err != nil {
+		return false, fmt.Errorf("failed to dismiss premium prompt: %w", err)
+	}
+
+	st.RandomDelay("action")
+
+	return false, nil
+}
+
+// logNoteCharCount logs how many characters remain in the note modal. The
+// modal's own counter is preferred; otherwise the configured limit is used.
+func (s *Service) logNoteCharCount(ctx context.Context, page *rod.Page, typed int) {
+	log := logger.FromContext(ctx)
+
+	limit := s.cfg.Connection.NoteMaxLength
+	if limit <= 0 {
+		limit = 300
+	}
+
+	if has, counter, err := page.Has(s.cfg.Selectors.NoteCharCounter); err == nil && has {
+		text, _ := counter.Text()
+		if used, total, ok := parseCharCounter(text); ok {
+			typed, limit = used, total
+		}
+	}
+
+	log.Infof("Note uses %d/%d characters (%d remaining)", typed, limit, limit-typed)
+}
+
+// parseCharCounter parses a "42/300" style character counter
+func parseCharCounter(text string) (int, int, bool) {
+	parts := strings.Split(strings.TrimSpace(text), "/")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	used, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return used, total, true
 }
 
 // clickSendButton clicks the Send button
-func (s *Service) clickSendButton(page *rod.Page, st *stealth.Stealth, withNote bool) error {
+func (s *Service) clickSendButton(ctx context.Context, page *rod.Page, st *stealth.Stealth, withNote bool) error {
 	var sendButton *rod.Element
 	var err error
 
 	if withNote {
-		sendButton, err = page.Element("button[aria-label*='Send now']")
+		sendButton, err = browser.FindFirst(page, "SendNowButton", []string{s.cfg.Selectors.SendNowButton}, elementWaitTimeout)
 	} else {
-		sendButton, err = page.Element("button[aria-label*='Send without a note']")
-		if err != nil {
-			// Try generic send button
-			sendButton, err = page.Element("button:has-text('Send')")
-		}
+		sendButton, err = browser.FindFirst(page, "SendWithoutNoteButton", s.cfg.Selectors.SendWithoutNoteButton, elementWaitTimeout)
 	}
 
 	if err != nil {
 		return fmt.Errorf("send button not found: %w", err)
 	}
 
-	if err := st.HumanClick(sendButton); err != nil {
+	if err := st.HumanClick(ctx, sendButton); err != nil {
 		return fmt.Errorf("failed to click send: %w", err)
 	}
 
@@ -238,30 +746,57 @@ func (s *Service) clickSendButton(page *rod.Page, st *stealth.Stealth, withNote
 	return nil
 }
 
-// generateNote generates a personalized connection note
-func (s *Service) generateNote(profile *storage.Profile) string {
+// generateNote generates a personalized connection note and returns it with
+// the 1-based IDs of the template used and of the preference rule that
+// boosted it (0 if none)
+func (s *Service) generateNote(profile *storage.Profile) (string, int, int, error) {
 	if len(s.cfg.Connection.NoteTemplates) == 0 {
-		return "Hi, I'd love to connect!"
+		return "Hi, I'd love to connect!", 0, 0, nil
 	}
 
-	// Select random template
-	template := s.cfg.Connection.NoteTemplates[rand.Intn(len(s.cfg.Connection.NoteTemplates))]
+	vars, err := s.store.GetVariables(profile.ID)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to load profile variables: %w", err)
+	}
+
+	// Select a random template, skipping {{SharedSchool}} templates unless
+	// the profile is a known alumnus and templates using unset variables
+	var candidates []int
+	for i, tmpl := range s.cfg.Connection.NoteTemplates {
+		if profile.School == "" && strings.Contains(tmpl, "{{SharedSchool}}") {
+			continue
+		}
+		if len(template.MissingVariables(tmpl, vars)) > 0 {
+			continue
+		}
+		candidates = append(candidates, i)
+	}
+	if len(candidates) == 0 {
+		return "Hi, I'd love to connect!", 0, 0, nil
+	}
+
+	// Weight candidates by the headline preference rules
+	weights, ruleIDs := TemplateWeights(candidates, profile.Headline, s.cfg.Connection.TemplatePreferenceRules)
+	choice := weightedChoice(weights)
+	index := candidates[choice]
+	tmpl := s.cfg.Connection.NoteTemplates[index]
 
 	// Extract first name
 	firstName := extractFirstName(profile.Name)
 
 	// Replace placeholders
-	note := strings.ReplaceAll(template, "{{FirstName}}", firstName)
+	note := strings.ReplaceAll(tmpl, "{{FirstName}}", firstName)
 	note = strings.ReplaceAll(note, "{{Company}}", profile.Company)
 	note = strings.ReplaceAll(note, "{{Field}}", profile.Keywords)
 	note = strings.ReplaceAll(note, "{{Topic}}", profile.JobTitle)
-
-	// Ensure note doesn't exceed max length
-	if len(note) > s.cfg.Connection.NoteMaxLength {
-		note = note[:s.cfg.Connection.NoteMaxLength-3] + "..."
-	}
-
-	return note
+	note = strings.ReplaceAll(note, "{{Headline}}", profile.Headline)
+	note = strings.ReplaceAll(note, "{{Summary}}", profile.Summary)
+	note = strings.ReplaceAll(note, "{{SharedSchool}}", profile.School)
+	note = s.templates.RenderVariables(note, vars)
+
+	// Ensure note is non-empty, fully resolved and within the length limit
+	note, err = s.templates.ValidateMessageLength(note, template.TypeConnectionNote)
+	return note, index + 1, ruleIDs[choice], err
 }
 
 // extractFirstName extracts the first name from a full name
@@ -274,18 +809,32 @@ func extractFirstName(fullName string) string {
 }
 
 // canSendConnection checks if we can send more connections based on rate limits
-func (s *Service) canSendConnection() bool {
-	// Check daily limit
+func (s *Service) canSendConnection(ctx context.Context) bool {
+	log := logger.FromContext(ctx)
+
+	// Check daily limit, lowered while in safe mode
+	perDay, _ := s.cfg.DailyLimits(s.store.SafeModeActive())
 	dailyStats := s.store.GetTodayStats()
-	if dailyStats.ConnectionsSent >= s.cfg.RateLimits.Connections.PerDay {
-		s.log.Warn("Daily connection limit reached")
+	if dailyStats.ConnectionsSent >= perDay {
+		log.Warn("Daily connection limit reached")
 		return false
 	}
 
 	// Check hourly limit
 	hourlyStats := s.store.GetHourlyStats()
 	if hourlyStats.ConnectionsSent >= s.cfg.RateLimits.Connections.PerHour {
-		s.log.Warn("Hourly connection limit reached")
+		log.Warn("Hourly connection limit reached")
+		return false
+	}
+
+	// Don't send ahead of the next slot; the run ends and the main loop
+	// tries again later instead of holding the page while waiting
+	if s.schedule != nil && !s.schedule.SlotDue(stealth.ActionConnection) {
+		if next := s.schedule.NextScheduledSlot(stealth.ActionConnection); next.IsZero() {
+			log.Info("No connection slots left this hour")
+		} else {
+			log.Infof("Next connection slot at %s", next.Format("15:04:05"))
+		}
 		return false
 	}
 
@@ -293,8 +842,10 @@ func (s *Service) canSendConnection() bool {
 }
 
 // WithdrawPendingRequests withdraws pending connection requests (optional feature)
-func (s *Service) WithdrawPendingRequests() error {
-	s.log.Info("Withdrawing old pending requests...")
+func (s *Service) WithdrawPendingRequests(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+
+	log.Info("Withdrawing old pending requests...")
 
 	// Navigate to "My Network" -> "Manage invitations"
 	if err := s.browser.Navigate("https://www.linkedin.com/mynetwork/invitation-manager/sent/"); err != nil {
@@ -302,14 +853,16 @@ func (s *Service) WithdrawPendingRequests() error {
 	}
 
 	page := s.browser.GetPage()
-	stealth := s.browser.GetStealth()
+	stealth := s.stealth
 
-	time.Sleep(3 * time.Second)
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
 
 	// Find withdraw buttons
-	withdrawButtons, err := page.Elements("button[aria-label*='Withdraw']")
+	withdrawButtons, err := page.Elements(s.cfg.Selectors.WithdrawButton)
 	if err != nil {
-		return fmt.Errorf("no withdraw buttons found: %w", err)
+		return fmt.Errorf("no withdraw buttons found (selector WithdrawButton): %w", err)
 	}
 
 	withdrawn := 0
@@ -318,24 +871,26 @@ func (s *Service) WithdrawPendingRequests() error {
 			break
 		}
 
-		if err := stealth.HumanClick(button); err != nil {
-			s.log.Errorf("Failed to click withdraw: %v", err)
+		if err := stealth.HumanClick(ctx, button); err != nil {
+			log.Errorf("Failed to click withdraw: %v", err)
 			continue
 		}
 
 		stealth.RandomDelay("action")
 
 		// Confirm withdrawal
-		confirmButton, err := page.Element("button[data-control-name='withdraw_single']")
+		confirmButton, err := page.Element(s.cfg.Selectors.WithdrawConfirmButton)
 		if err == nil {
-			stealth.HumanClick(confirmButton)
+			stealth.HumanClick(ctx, confirmButton)
 			withdrawn++
+		} else {
+			log.Warnf("Selector WithdrawConfirmButton not found: %v", err)
 		}
 
 		stealth.RandomDelay("action")
 	}
 
-	s.log.Infof("Withdrew %d pending requests", withdrawn)
+	log.Infof("Withdrew %d pending requests", withdrawn)
 	return nil
 }
 
@@ -0,0 +1,295 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/template"
+)
+
+// newStoreTestService returns a service with only storage and config, for
+// the parts that don't touch the browser
+func newStoreTestService(t *testing.T) (*Service, *storage.Storage) {
+	t.Helper()
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return &Service{store: store, cfg: &config.Config{}}, store
+}
+
+// queueProfile saves a profile and moves it to queued like sendBatch does
+func queueProfile(t *testing.T, store *storage.Storage, username string) *storage.Profile {
+	t.Helper()
+
+	profile := &storage.Profile{ProfileURL: "https://www.linkedin.com/in/" + username, Name: username}
+	id, err := store.SaveProfile(profile)
+	if err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	profile.ID = id
+
+	if err := store.TransitionState(profile.ProfileURL, storage.StateDiscovered, storage.StateQueued); err != nil {
+		t.Fatalf("TransitionState: %v", err)
+	}
+	return profile
+}
+
+func TestReleaseProfileAfterFailedSend(t *testing.T) {
+	s, store := newStoreTestService(t)
+	ctx := context.Background()
+
+	failed := queueProfile(t, store, "failed")
+	s.releaseProfile(ctx, failed.ProfileURL)
+
+	// Sent before the failure was noticed, e.g. the weekly limit banner
+	sent := queueProfile(t, store, "sent")
+	if err := store.SaveConnectionRequest(&storage.ConnectionRequest{ProfileID: sent.ID, ProfileURL: sent.ProfileURL, Status: "pending"}); err != nil {
+		t.Fatalf("SaveConnectionRequest: %v", err)
+	}
+	s.releaseProfile(ctx, sent.ProfileURL)
+
+	for url, want := range map[string]storage.ConnectionState{
+		failed.ProfileURL: storage.StateDiscovered,
+		sent.ProfileURL:   storage.StateQueued,
+	} {
+		profile, err := store.GetProfileByURL(url)
+		if err != nil {
+			t.Fatalf("GetProfileByURL: %v", err)
+		}
+		if profile.ConnectionState != want {
+			t.Errorf("%s state = %s, want %s", url, profile.ConnectionState, want)
+		}
+	}
+}
+
+func TestGenerateNoteWithCustomVariables(t *testing.T) {
+	s, store := newStoreTestService(t)
+	s.templates = template.New(s.cfg)
+	s.cfg.Connection.NoteTemplates = []string{"Hi {{FirstName}}, congrats on {{.Vars.trigger_event}}!"}
+
+	profile := queueProfile(t, store, "jane")
+	profile.Name = "Jane Doe"
+
+	// Templates using unset variables are skipped
+	note, templateID, _, err := s.generateNote(profile)
+	if err != nil || templateID != 0 || note != "Hi, I'd love to connect!" {
+		t.Errorf("generateNote without the variable = %q, #%d, %v, want the default note", note, templateID, err)
+	}
+
+	if err := store.SetVariable(profile.ID, "trigger_event", "the new role"); err != nil {
+		t.Fatalf("SetVariable: %v", err)
+	}
+	note, templateID, _, err = s.generateNote(profile)
+	if err != nil || templateID != 1 || note != "Hi Jane, congrats on the new role!" {
+		t.Errorf("generateNote = %q, #%d, %v, want the rendered template", note, templateID, err)
+	}
+}
+
+func TestIsInactive(t *testing.T) {
+	s, _ := newStoreTestService(t)
+	s.cfg.Search.Targets = []config.SearchTarget{
+		{Keywords: "cto", Location: "Berlin", MinRecentActivityDays: 30},
+		{Keywords: "founder", Location: "Berlin"},
+	}
+
+	daysAgo := func(days int) *time.Time {
+		estimate := time.Now().AddDate(0, 0, -days)
+		return &estimate
+	}
+
+	tests := []struct {
+		name       string
+		keywords   string
+		lastActive *time.Time
+		want       bool
+	}{
+		{"active within the window", "cto", daysAgo(21), false},
+		{"inactive past the window", "cto", daysAgo(45), true},
+		{"missing badge", "cto", nil, false},
+		{"target without a minimum", "founder", daysAgo(400), false},
+		{"unknown target", "designer", daysAgo(400), false},
+	}
+
+	for _, tt := range tests {
+		profile := &storage.Profile{Keywords: tt.keywords, Location: "Berlin", LastActiveEstimate: tt.lastActive}
+		if got := s.isInactive(profile); got != tt.want {
+			t.Errorf("%s: isInactive = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateNoteWithHeadlineAndSummary(t *testing.T) {
+	s, store := newStoreTestService(t)
+	s.templates = template.New(s.cfg)
+	s.cfg.Connection.NoteTemplates = []string{"Hi {{FirstName}}, saw you're {{Headline}} ({{Summary}})"}
+
+	profile := queueProfile(t, store, "jane")
+	profile.Name = "Jane Doe"
+	profile.Headline = "CTO at Acme"
+	profile.Summary = "building payments infrastructure"
+
+	note, _, _, err := s.generateNote(profile)
+	if want := "Hi Jane, saw you're CTO at Acme (building payments infrastructure)"; err != nil || note != want {
+		t.Errorf("generateNote = %q, %v, want %q", note, err, want)
+	}
+}
+
+// testProfiles returns n profiles for batching tests
+func testProfiles(n int) []*storage.Profile {
+	profiles := make([]*storage.Profile, n)
+	for i := range profiles {
+		profiles[i] = &storage.Profile{ProfileURL: fmt.Sprintf("https://www.linkedin.com/in/user-%d", i)}
+	}
+	return profiles
+}
+
+func TestRunBatchesPausesBetweenBatches(t *testing.T) {
+	tests := []struct {
+		profiles   int
+		batchSize  int
+		wantSizes  []int
+		wantPauses int
+	}{
+		{12, 5, []int{5, 5, 2}, 2},
+		{10, 5, []int{5, 5}, 1},
+		{5, 5, []int{5}, 0},
+		{11, 0, []int{5, 5, 1}, 2}, // default batch size
+		{3, 1, []int{1, 1, 1}, 2},
+		{0, 5, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d profiles in batches of %d", tt.profiles, tt.batchSize), func(t *testing.T) {
+			var sizes []int
+			pauses := 0
+			send := func(batch []*storage.Profile, sentBefore int) (int, bool, error) {
+				sizes = append(sizes, len(batch))
+				return len(batch), false, nil
+			}
+
+			sent, err := runBatches(context.Background(), splitBatches(testProfiles(tt.profiles), tt.batchSize), send, func() { pauses++ })
+			if err != nil {
+				t.Fatalf("runBatches: %v", err)
+			}
+			if sent != tt.profiles {
+				t.Errorf("sent = %d, want %d", sent, tt.profiles)
+			}
+			if !reflect.DeepEqual(sizes, tt.wantSizes) {
+				t.Errorf("batch sizes = %v, want %v", sizes, tt.wantSizes)
+			}
+			if pauses != tt.wantPauses {
+				t.Errorf("pauses = %d, want %d", pauses, tt.wantPauses)
+			}
+		})
+	}
+}
+
+func TestRunBatchesStops(t *testing.T) {
+	batches := splitBatches(testProfiles(15), 5)
+
+	// No pause after a batch that sent nothing
+	pauses := 0
+	sentBefore := []int{}
+	send := func(batch []*storage.Profile, before int) (int, bool, error) {
+		sentBefore = append(sentBefore, before)
+		if len(sentBefore) == 1 {
+			return 0, false, nil
+		}
+		return len(batch), false, nil
+	}
+	if sent, err := runBatches(context.Background(), batches, send, func() { pauses++ }); err != nil || sent != 10 || pauses != 1 {
+		t.Errorf("with an empty first batch: sent %d, %d pauses, %v, want 10 sent and 1 pause", sent, pauses, err)
+	}
+	if !reflect.DeepEqual(sentBefore, []int{0, 0, 5}) {
+		t.Errorf("sentBefore = %v, want the running total [0 0 5]", sentBefore)
+	}
+
+	// A batch asking to stop ends the run without pausing
+	calls, pauses := 0, 0
+	stop := func(batch []*storage.Profile, before int) (int, bool, error) {
+		calls++
+		return 2, true, nil
+	}
+	if sent, err := runBatches(context.Background(), batches, stop, func() { pauses++ }); err != nil || sent != 2 || calls != 1 || pauses != 0 {
+		t.Errorf("after a stop: sent %d in %d batches with %d pauses, %v, want 2 sent in 1 batch", sent, calls, pauses, err)
+	}
+
+	// Cancellation is checked at the next batch boundary
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	cancelling := func(batch []*storage.Profile, before int) (int, bool, error) {
+		calls++
+		cancel()
+		return len(batch), false, nil
+	}
+	if sent, err := runBatches(ctx, batches, cancelling, func() {}); !errors.Is(err, context.Canceled) || sent != 5 || calls != 1 {
+		t.Errorf("after cancel: sent %d in %d batches, %v, want context.Canceled after 1 batch", sent, calls, err)
+	}
+
+	// Errors from a batch are returned with the running total
+	failing := func(batch []*storage.Profile, before int) (int, bool, error) {
+		return 1, true, ErrWeeklyLimitReached
+	}
+	if sent, err := runBatches(context.Background(), batches, failing, func() {}); !errors.Is(err, ErrWeeklyLimitReached) || sent != 1 {
+		t.Errorf("after an error: sent %d, %v, want 1 and ErrWeeklyLimitReached", sent, err)
+	}
+}
+
+func TestSortByDegree(t *testing.T) {
+	var profiles []*storage.Profile
+	for i, degree := range []int{3, 0, 2, 3, 1, 2} {
+		profiles = append(profiles, &storage.Profile{ProfileURL: fmt.Sprintf("https://www.linkedin.com/in/user-%d", i), ConnectionDegree: degree})
+	}
+
+	var got []string
+	for _, profile := range sortByDegree(profiles) {
+		got = append(got, fmt.Sprintf("%d:%s", profile.ConnectionDegree, strings.TrimPrefix(profile.ProfileURL, "https://www.linkedin.com/in/")))
+	}
+
+	// Ascending degree, stable within a degree, unknown degrees last
+	want := []string{"1:user-4", "2:user-2", "2:user-5", "3:user-0", "3:user-3", "0:user-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortByDegree = %v, want %v", got, want)
+	}
+	if profiles[0].ConnectionDegree != 3 {
+		t.Error("sortByDegree reordered its input")
+	}
+}
+
+func TestCanSendConnectionInSafeMode(t *testing.T) {
+	s, store := newStoreTestService(t)
+	s.cfg.RateLimits.Connections.PerDay = 20
+	s.cfg.RateLimits.Connections.PerHour = 100
+	s.cfg.SafeMode.MaxConnectionsPerDay = 5
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		profile := queueProfile(t, store, fmt.Sprintf("user-%d", i))
+		if err := store.SaveConnectionRequest(&storage.ConnectionRequest{ProfileID: profile.ID, ProfileURL: profile.ProfileURL, Status: "pending"}); err != nil {
+			t.Fatalf("SaveConnectionRequest: %v", err)
+		}
+	}
+
+	if !s.canSendConnection(ctx) {
+		t.Error("canSendConnection = false with 5 of 20 sent, want true")
+	}
+
+	if err := store.SetSafeMode(true, "test"); err != nil {
+		t.Fatalf("SetSafeMode: %v", err)
+	}
+	if s.canSendConnection(ctx) {
+		t.Error("canSendConnection = true in safe mode with 5 of 5 sent, want false")
+	}
+}
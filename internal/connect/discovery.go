@@ -0,0 +1,60 @@
+package connect
+
+import (
+	"context"
+	"strings"
+
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+)
+
+// maxPeopleAlsoViewed caps how many sidebar suggestions are queued per profile
+const maxPeopleAlsoViewed = 3
+
+// queuePeopleAlsoViewed reads the "People Also Viewed" sidebar of the open
+// profile and queues new profiles for the next run
+func (s *Service) queuePeopleAlsoViewed(ctx context.Context, page *rod.Page, profile *storage.Profile) {
+	log := logger.FromContext(ctx)
+
+	if !s.cfg.Connection.CrawlPeopleAlsoViewed {
+		return
+	}
+
+	links, err := page.Elements(s.cfg.Selectors.PeopleAlsoViewedLink)
+	if err != nil {
+		log.Debugf("No People Also Viewed section on %s: %v", profile.ProfileURL, err)
+		return
+	}
+
+	queued := 0
+	for _, link := range links {
+		if queued >= maxPeopleAlsoViewed {
+			break
+		}
+
+		href, err := link.Attribute("href")
+		if err != nil || href == nil {
+			continue
+		}
+
+		url := strings.Split(*href, "?")[0]
+		if !strings.Contains(url, "/in/") || profileURLKey(url) == profileURLKey(profile.ProfileURL) {
+			continue
+		}
+
+		added, err := s.store.QueueDiscovery(url, storage.DiscoverySourcePeopleAlsoViewed, profile.ProfileURL)
+		if err != nil {
+			log.Warnf("Failed to queue %s: %v", url, err)
+			continue
+		}
+		if added {
+			queued++
+		}
+	}
+
+	if queued > 0 {
+		log.Infof("Queued %d People Also Viewed profiles from %s", queued, profile.ProfileURL)
+	}
+}
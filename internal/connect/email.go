@@ -0,0 +1,165 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+)
+
+// ErrEmailRequired is returned when a profile requires their email address to
+// connect and no email could be supplied
+var ErrEmailRequired = errors.New("email address required to connect")
+
+// handleEmailRequired checks for the "enter their email to connect" modal
+// after clicking Connect and fills it in according to EmailRequiredAction.
+// It returns nil when no email is needed or it was entered, and
+// ErrEmailRequired when the profile was skipped.
+func (s *Service) handleEmailRequired(ctx context.Context, page *rod.Page, profile *storage.Profile) error {
+	log := logger.FromContext(ctx)
+
+	has, _, err := page.Has(s.cfg.Selectors.EmailRequiredInput)
+	if err != nil {
+		return fmt.Errorf("failed to check for email modal (selector EmailRequiredInput): %w", err)
+	}
+	if !has {
+		return nil
+	}
+
+	email := ""
+	switch s.cfg.Connection.EmailRequiredAction {
+	case config.EmailRequiredUseConfiguredEmail:
+		email = s.cfg.LinkedIn.Email
+	case config.EmailRequiredUseProfileEmail:
+		// The contact info overlay can't be opened over the modal, so close
+		// it, look up the email and click Connect again
+		if err := s.dismissEmailModal(ctx, page); err != nil {
+			return err
+		}
+		email = s.extractProfileEmail(ctx, page)
+		if email != "" {
+			if err := s.reopenConnectModal(ctx, page); err != nil {
+				return err
+			}
+		}
+	}
+
+	if email == "" {
+		log.Infof("%s requires an email address to connect, skipping", profile.ProfileURL)
+		return s.skipEmailRequired(ctx, page, profile)
+	}
+
+	input, err := page.Element(s.cfg.Selectors.EmailRequiredInput)
+	if err != nil {
+		return fmt.Errorf("email input not found (selector EmailRequiredInput): %w", err)
+	}
+
+	if err := s.stealth.HumanClick(ctx, input); err != nil {
+		return fmt.Errorf("failed to focus email input: %w", err)
+	}
+	if err := s.stealth.HumanType(ctx, input, email); err != nil {
+		return fmt.Errorf("failed to type email: %w", err)
+	}
+
+	s.stealth.RandomDelay("action")
+	log.Infof("Entered email address to connect with %s", profile.ProfileURL)
+
+	return nil
+}
+
+// skipEmailRequired dismisses the email modal and records the profile so it
+// isn't attempted again
+func (s *Service) skipEmailRequired(ctx context.Context, page *rod.Page, profile *storage.Profile) error {
+	// The modal is already closed when the profile email lookup failed
+	if has, _, _ := page.Has(s.cfg.Selectors.EmailRequiredInput); has {
+		if err := s.dismissEmailModal(ctx, page); err != nil {
+			return err
+		}
+	}
+
+	skipped := &storage.ConnectionRequest{
+		ProfileID:  profile.ID,
+		ProfileURL: profile.ProfileURL,
+		SentAt:     time.Now(),
+		Status:     storage.StatusEmailRequiredSkipped,
+	}
+	if err := s.store.SaveConnectionRequest(skipped); err != nil {
+		return fmt.Errorf("failed to record email required skip: %w", err)
+	}
+
+	s.store.LogActivityAsync("connection_request", profile.ProfileURL, "skipped", "email required")
+	return ErrEmailRequired
+}
+
+// dismissEmailModal closes the "Connect via email" modal
+func (s *Service) dismissEmailModal(ctx context.Context, page *rod.Page) error {
+	button, err := browser.FindFirst(page, "EmailRequiredDismiss", s.cfg.Selectors.EmailRequiredDismiss, elementWaitTimeout)
+	if err != nil {
+		return fmt.Errorf("email modal shown but dismiss button not found: %w", err)
+	}
+
+	if err := s.stealth.HumanClick(ctx, button); err != nil {
+		return fmt.Errorf("failed to dismiss email modal: %w", err)
+	}
+
+	s.stealth.RandomDelay("action")
+	return nil
+}
+
+// extractProfileEmail opens the profile's contact info overlay and returns
+// the email shown there, or "" when it isn't visible to us
+func (s *Service) extractProfileEmail(ctx context.Context, page *rod.Page) string {
+	log := logger.FromContext(ctx)
+
+	has, link, err := page.Has(s.cfg.Selectors.ContactInfoLink)
+	if err != nil || !has {
+		log.Debug("No contact info link on profile")
+		return ""
+	}
+
+	if err := s.stealth.HumanClick(ctx, link); err != nil {
+		log.Debugf("Failed to open contact info: %v", err)
+		return ""
+	}
+
+	s.stealth.RandomDelay("action")
+
+	email := ""
+	if element, err := page.Timeout(5 * time.Second).Element(s.cfg.Selectors.ContactInfoEmail); err == nil {
+		if href, err := element.Attribute("href"); err == nil && href != nil {
+			email = strings.TrimSpace(strings.TrimPrefix(*href, "mailto:"))
+		}
+	}
+
+	if has, button, err := page.Has(s.cfg.Selectors.ContactInfoDismiss); err == nil && has {
+		if err := s.stealth.HumanClick(ctx, button); err != nil {
+			log.Debugf("Failed to close contact info: %v", err)
+		}
+		s.stealth.RandomDelay("action")
+	}
+
+	return email
+}
+
+// reopenConnectModal clicks Connect again after the email modal was closed
+func (s *Service) reopenConnectModal(ctx context.Context, page *rod.Page) error {
+	connectButton, err := s.findConnectButton(page)
+	if err != nil {
+		return fmt.Errorf("connect button not found: %w", err)
+	}
+
+	if err := s.stealth.HumanClick(ctx, connectButton); err != nil {
+		return fmt.Errorf("failed to click connect: %w", err)
+	}
+
+	s.stealth.RandomDelay("action")
+	return nil
+}
@@ -0,0 +1,44 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+// InvitationExpiryDays is how long LinkedIn keeps an unanswered invitation
+// before expiring it
+const InvitationExpiryDays = 180
+
+// MarkExpiredConnections marks requests still pending after
+// InvitationExpiryDays as expired, since LinkedIn has withdrawn them on its
+// side, and returns how many were marked
+func (s *Service) MarkExpiredConnections(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	old, err := s.store.GetPendingConnectionsOlderThan(InvitationExpiryDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get old connection requests: %w", err)
+	}
+
+	expired := 0
+	for _, conn := range old {
+		err := s.store.UpdateConnectionStatus(conn.ProfileURL, string(storage.StateExpired))
+		if errors.Is(err, storage.ErrStateConflict) {
+			log.Warnf("Connection state not updated for %s: %v", conn.ProfileURL, err)
+		} else if err != nil {
+			return expired, fmt.Errorf("failed to mark request to %s expired: %w", conn.ProfileURL, err)
+		}
+		s.store.LogActivityAsync("connection_expired", conn.ProfileURL, "success", "")
+		expired++
+	}
+
+	if expired > 0 {
+		log.Infof("Marked %d connection requests expired after %d days pending", expired, InvitationExpiryDays)
+	}
+
+	return expired, nil
+}
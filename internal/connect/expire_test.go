@@ -0,0 +1,87 @@
+package connect
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+func TestMarkExpiredConnections(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	store, err := storage.New(dbPath, 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	s := &Service{store: store, cfg: &config.Config{}}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	fixtures := []struct {
+		username string
+		age      time.Duration
+		status   string
+		want     string
+	}{
+		{"old-pending", 200 * 24 * time.Hour, "pending", "expired"},
+		{"just-expired", 181 * 24 * time.Hour, "pending", "expired"},
+		{"recent-pending", 30 * 24 * time.Hour, "pending", "pending"},
+		{"old-accepted", 200 * 24 * time.Hour, "accepted", "accepted"},
+	}
+
+	for _, f := range fixtures {
+		profile := queueProfile(t, store, f.username)
+		if err := store.SaveConnectionRequest(&storage.ConnectionRequest{ProfileID: profile.ID, ProfileURL: profile.ProfileURL, Status: "pending"}); err != nil {
+			t.Fatalf("SaveConnectionRequest: %v", err)
+		}
+		if err := store.TransitionState(profile.ProfileURL, storage.StateQueued, storage.StateRequested); err != nil {
+			t.Fatalf("TransitionState: %v", err)
+		}
+		if f.status != "pending" {
+			if err := store.UpdateConnectionStatus(profile.ProfileURL, f.status); err != nil {
+				t.Fatalf("UpdateConnectionStatus: %v", err)
+			}
+		}
+		if _, err := db.Exec(`UPDATE connection_requests SET sent_at = ? WHERE profile_url = ?`,
+			now.Add(-f.age).Format("2006-01-02 15:04:05"), profile.ProfileURL); err != nil {
+			t.Fatalf("set sent_at: %v", err)
+		}
+	}
+
+	expired, err := s.MarkExpiredConnections(context.Background())
+	if err != nil {
+		t.Fatalf("MarkExpiredConnections: %v", err)
+	}
+	if expired != 2 {
+		t.Errorf("expired = %d, want 2", expired)
+	}
+
+	for _, f := range fixtures {
+		var status string
+		if err := db.QueryRow(`SELECT status FROM connection_requests WHERE profile_url = ?`,
+			"https://www.linkedin.com/in/"+f.username).Scan(&status); err != nil {
+			t.Fatalf("read status: %v", err)
+		}
+		if status != f.want {
+			t.Errorf("%s status = %q, want %q", f.username, status, f.want)
+		}
+	}
+
+	got, err := store.GetExpiredConnections(now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetExpiredConnections: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("GetExpiredConnections returned %d requests, want 2", len(got))
+	}
+}
@@ -0,0 +1,60 @@
+package connect
+
+import (
+	"context"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/stealth"
+	"linkedin-automation/internal/storage"
+)
+
+// followOnlyProfileHTML is a profile whose owner disabled connection
+// requests, so only Follow and Message are offered
+const followOnlyProfileHTML = `<html><body>
+<div class="pvs-profile-actions">
+  <button aria-label="Follow Jane Doe" onclick="this.setAttribute('aria-pressed', 'true'); this.textContent = 'Following'">Follow</button>
+  <button aria-label="Message Jane Doe">Message</button>
+</div>
+</body></html>`
+
+func TestFollowWhenConnectUnavailable(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(followOnlyProfileHTML); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	s, store := newStoreTestService(t)
+	s.cfg.Selectors = config.DefaultSelectors()
+	s.cfg.Connection.FollowIfConnectUnavailable = true
+	s.stealth = stealth.New(s.cfg, "test")
+	profile := queueProfile(t, store, "jane")
+
+	if _, err := s.findConnectButton(page); err == nil {
+		t.Fatal("findConnectButton found a Connect button on a follow-only profile")
+	}
+
+	if err := s.followProfile(context.Background(), page, profile); err != nil {
+		t.Fatalf("followProfile: %v", err)
+	}
+
+	if pressed := page.MustElement("button[aria-label*='Follow']").MustAttribute("aria-pressed"); pressed == nil || *pressed != "true" {
+		t.Error("Follow button was not clicked")
+	}
+
+	followed, err := store.GetFollowedProfiles()
+	if err != nil {
+		t.Fatalf("GetFollowedProfiles: %v", err)
+	}
+	if len(followed) != 1 || followed[0].ProfileURL != profile.ProfileURL || followed[0].Status != "followed" {
+		t.Fatalf("followed profiles = %+v, want jane with status followed", followed)
+	}
+
+	got, err := store.GetProfileByURL(profile.ProfileURL)
+	if err != nil {
+		t.Fatalf("GetProfileByURL: %v", err)
+	}
+	if got.ConnectionState != storage.StateFollowed {
+		t.Errorf("connection state = %s, want %s", got.ConnectionState, storage.StateFollowed)
+	}
+}
@@ -0,0 +1,115 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/scheduler"
+
+	"github.com/go-rod/rod"
+)
+
+// ErrWeeklyLimitReached is returned when LinkedIn refuses further
+// invitations for the rest of the week
+var ErrWeeklyLimitReached = errors.New("weekly invitation limit reached")
+
+// weeklyLimitPattern matches the banner "You've reached the weekly invitation limit"
+const weeklyLimitPattern = `/reached the weekly invitation limit/i`
+
+// DetectWeeklyInvitationLimit reports whether the page shows LinkedIn's
+// weekly invitation limit modal or banner
+func (s *Service) DetectWeeklyInvitationLimit(page *rod.Page) (bool, error) {
+	has, _, err := page.Has(s.cfg.Selectors.WeeklyLimitModal)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for limit modal (selector WeeklyLimitModal): %w", err)
+	}
+	if has {
+		return true, nil
+	}
+
+	has, _, err = page.HasR(s.cfg.Selectors.WeeklyLimitBanner, weeklyLimitPattern)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for limit banner (selector WeeklyLimitBanner): %w", err)
+	}
+	return has, nil
+}
+
+// checkWeeklyLimit returns ErrWeeklyLimitReached when the page shows the
+// weekly limit
+func (s *Service) checkWeeklyLimit(ctx context.Context, page *rod.Page) error {
+	limited, err := s.DetectWeeklyInvitationLimit(page)
+	if err != nil {
+		logger.FromContext(ctx).Debugf("Weekly limit check failed: %v", err)
+		return nil
+	}
+	if limited {
+		return ErrWeeklyLimitReached
+	}
+	return nil
+}
+
+// handleWeeklyLimit records and reports that the weekly limit was reached
+func (s *Service) handleWeeklyLimit(ctx context.Context) {
+	log := logger.FromContext(ctx)
+
+	resumeAt := NextWeekStart(time.Now())
+	log.Warnf("Weekly invitation limit reached, pausing connection requests until %s", resumeAt.Format("Mon 2006-01-02 15:04"))
+
+	if err := s.store.LogActivity("rate_limit", "", "weekly_limit_reached", ""); err != nil {
+		log.Errorf("Failed to log weekly limit: %v", err)
+	}
+
+	if s.notifier != nil {
+		text := fmt.Sprintf("LinkedIn weekly invitation limit reached. Connection requests are paused until %s.", resumeAt.Format("Mon Jan 2 15:04"))
+		if err := s.notifier.Notify(ctx, text); err != nil {
+			log.Warnf("Failed to send weekly limit notification: %v", err)
+		}
+	}
+}
+
+// WeeklyLimitPausedUntil returns when connection requests may resume if the
+// weekly limit was hit this week
+func (s *Service) WeeklyLimitPausedUntil() (time.Time, bool) {
+	last, err := s.store.GetLastActivityTime("rate_limit", "weekly_limit_reached")
+	if err != nil || last == nil {
+		return time.Time{}, false
+	}
+
+	resumeAt := NextWeekStart(last.Local())
+	return resumeAt, time.Now().Before(resumeAt)
+}
+
+// IsLowAcceptanceHour reports whether adaptive scheduling is on and requests
+// sent at this hour have historically been accepted far less than average
+func (s *Service) IsLowAcceptanceHour(ctx context.Context) bool {
+	log := logger.FromContext(ctx)
+
+	if !s.cfg.Scheduling.AdaptiveConnectHours {
+		return false
+	}
+
+	rates, err := s.store.GetAcceptanceResponseTimeByHourOfDay()
+	if err != nil {
+		log.Warnf("Failed to load hourly acceptance rates: %v", err)
+		return false
+	}
+
+	hour := time.Now().Hour()
+	low, rate, average := scheduler.IsLowAcceptanceHour(rates, hour, s.cfg.Scheduling.AdaptiveMinSamples)
+	if low {
+		log.Infof("Requests sent at %02d:00 are accepted %.1f%% of the time (average %.1f%%)", hour, rate, average)
+	}
+	return low
+}
+
+// NextWeekStart returns midnight of the Monday after t
+func NextWeekStart(t time.Time) time.Time {
+	daysUntilMonday := (8 - int(t.Weekday())) % 7
+	if daysUntilMonday == 0 {
+		daysUntilMonday = 7
+	}
+	return time.Date(t.Year(), t.Month(), t.Day()+daysUntilMonday, 0, 0, 0, 0, t.Location())
+}
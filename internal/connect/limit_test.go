@@ -0,0 +1,95 @@
+package connect
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// newFixturePage opens a blank page in a headless browser, skipping the test
+// when no browser is installed. CHROME_PATH overrides the browser like in
+// browser.New.
+func newFixturePage(t *testing.T) *rod.Page {
+	t.Helper()
+
+	bin := os.Getenv("CHROME_PATH")
+	if bin == "" {
+		path, found := launcher.LookPath()
+		if !found {
+			t.Skip("no browser installed")
+		}
+		bin = path
+	}
+
+	u, err := launcher.New().Bin(bin).Headless(true).Launch()
+	if err != nil {
+		t.Fatalf("launch browser: %v", err)
+	}
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("connect to browser: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("open page: %v", err)
+	}
+	return page
+}
+
+func TestDetectWeeklyInvitationLimit(t *testing.T) {
+	cfg := &config.Config{Selectors: config.DefaultSelectors()}
+	s := &Service{cfg: cfg}
+	page := newFixturePage(t)
+
+	tests := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{"banner", `<div role="alert">You've reached the weekly invitation limit</div>`, true},
+		{"modal", `<div data-test-modal-id="limit-reached-modal">Come back next week</div>`, true},
+		{"other dialog", `<div role="dialog">Add a note to your invitation?</div>`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := page.SetDocumentContent(tt.html); err != nil {
+				t.Fatalf("set content: %v", err)
+			}
+
+			got, err := s.DetectWeeklyInvitationLimit(page)
+			if err != nil {
+				t.Fatalf("DetectWeeklyInvitationLimit: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectWeeklyInvitationLimit = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextWeekStart(t *testing.T) {
+	tests := []struct {
+		from string
+		want string
+	}{
+		{"2024-06-05 15:30", "2024-06-10 00:00"}, // Wednesday
+		{"2024-06-09 23:59", "2024-06-10 00:00"}, // Sunday
+		{"2024-06-10 00:00", "2024-06-17 00:00"}, // Monday
+	}
+
+	for _, tt := range tests {
+		from, _ := time.ParseInLocation("2006-01-02 15:04", tt.from, time.Local)
+		if got := NextWeekStart(from).Format("2006-01-02 15:04"); got != tt.want {
+			t.Errorf("NextWeekStart(%s) = %s, want %s", tt.from, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package connect
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/scoring"
+	"linkedin-automation/internal/search"
+	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/urlutil"
+)
+
+// Columns of a Sales Navigator lead list export
+const (
+	navigatorColumnName    = "Full Name"
+	navigatorColumnTitle   = "Title"
+	navigatorColumnCompany = "Company"
+	navigatorColumnURL     = "LinkedIn URL"
+	navigatorColumnDegree  = "Connection Degree"
+	navigatorColumnAccount = "Account Name"
+)
+
+// ErrNavigatorURLColumnMissing is returned for CSVs without a LinkedIn URL column
+var ErrNavigatorURLColumnMissing = errors.New("lead list has no \"" + navigatorColumnURL + "\" column")
+
+// ImportNavigatorLeads reads a Sales Navigator lead list export and queues
+// each new lead for connecting. Leads already stored, under their URL or an
+// alias of it, are skipped. It returns how many leads were queued.
+func (s *Service) ImportNavigatorLeads(ctx context.Context, r io.Reader) (int, error) {
+	log := logger.FromContext(ctx)
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read lead list header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Exports from Excel start with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns[strings.ToLower(navigatorColumnURL)]; !ok {
+		return 0, ErrNavigatorURLColumnMissing
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := columns[strings.ToLower(column)]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	seen := make(map[string]bool)
+	queued, line := 0, 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return queued, fmt.Errorf("failed to read lead list line %d: %w", line, err)
+		}
+
+		rawURL := field(record, navigatorColumnURL)
+		if rawURL == "" {
+			log.Warnf("Skipping lead list line %d: no profile URL", line)
+			continue
+		}
+		profileURL, err := urlutil.NormalizeProfileURL(rawURL)
+		if err == nil && !urlutil.IsPersonProfileURL(profileURL) {
+			err = fmt.Errorf("%w: not a member profile: %s", urlutil.ErrInvalidLinkedInURL, rawURL)
+		}
+		if err != nil {
+			log.Warnf("Skipping lead list line %d: %v", line, err)
+			continue
+		}
+		if seen[profileURL] {
+			continue
+		}
+		seen[profileURL] = true
+
+		existing, err := s.store.GetProfileByURL(profileURL)
+		if err != nil {
+			return queued, fmt.Errorf("failed to look up %s: %w", profileURL, err)
+		}
+		if existing != nil {
+			log.Debugf("Lead %s already stored, skipping", profileURL)
+			continue
+		}
+
+		company := field(record, navigatorColumnCompany)
+		if company == "" {
+			company = field(record, navigatorColumnAccount)
+		}
+
+		profile := &storage.Profile{
+			ProfileURL:       profileURL,
+			Name:             field(record, navigatorColumnName),
+			JobTitle:         field(record, navigatorColumnTitle),
+			Company:          company,
+			ConnectionDegree: search.ParseConnectionDegree(field(record, navigatorColumnDegree)),
+			DiscoveredAt:     time.Now(),
+			DiscoverySource:  storage.DiscoverySourceNavigatorImport,
+		}
+		profile.BaseScore = scoring.MatchICP(profile, s.cfg.Search.ICP)
+		profile.Score = scoring.ScoreProfile(profile, s.cfg.Search.ICP, s.cfg.Scoring, time.Now())
+
+		if _, err := s.store.SaveProfile(profile); err != nil {
+			return queued, fmt.Errorf("failed to save lead %s: %w", profileURL, err)
+		}
+		if err := s.store.TransitionState(profileURL, storage.StateDiscovered, storage.StateQueued); err != nil {
+			return queued, fmt.Errorf("failed to queue lead %s: %w", profileURL, err)
+		}
+
+		queued++
+	}
+
+	s.store.LogActivityAsync("navigator_import", "", "success", fmt.Sprintf("queued %d leads", queued))
+	log.Infof("Queued %d new leads from the Sales Navigator lead list", queued)
+
+	return queued, nil
+}
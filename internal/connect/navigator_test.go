@@ -0,0 +1,103 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"linkedin-automation/internal/storage"
+)
+
+// navigatorExport is a Sales Navigator lead list with 7 rows: 5 new leads,
+// one repeated under a different URL form and one already stored. Excel
+// saves it with a byte order mark.
+const navigatorExport = "\ufeffFull Name,Title,Company,LinkedIn URL,Connection Degree,Account Name\n" +
+	`"Doe, Jane",VP of Sales,Acme,https://www.linkedin.com/in/jane-doe,2nd,Acme` + "\n" +
+	`John Smith,"CTO ""Platform""",,https://de.linkedin.com/in/John-Smith/?trk=lead,3rd,Globex` + "\n" +
+	`Ana Lopez,Head of Growth,Initech,linkedin.com/in/ana-lopez,2nd,Initech` + "\n" +
+	`Jane Doe,VP Sales,Acme,https://www.linkedin.com/in/Jane-Doe/,2nd,Acme` + "\n" +
+	`Raj Patel,Founder,Hooli,https://www.linkedin.com/in/existing,1st,Hooli` + "\n" +
+	`"Lee, Min",Engineer,"Umbrella, Inc.",https://www.linkedin.com/in/min-lee,3rd,Umbrella` + "\n" +
+	`Sam Green,Director,Vandelay,https://www.linkedin.com/in/sam-green,2nd,Vandelay` + "\n"
+
+func TestImportNavigatorLeads(t *testing.T) {
+	s, store := newStoreTestService(t)
+	if _, err := store.SaveProfile(&storage.Profile{ProfileURL: "https://www.linkedin.com/in/existing", Name: "Raj Patel"}); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	queued, err := s.ImportNavigatorLeads(context.Background(), strings.NewReader(navigatorExport))
+	if err != nil {
+		t.Fatalf("ImportNavigatorLeads: %v", err)
+	}
+	if queued != 5 {
+		t.Errorf("queued %d leads, want 5", queued)
+	}
+
+	profiles, err := store.GetQueuedProfiles(storage.DiscoverySourceNavigatorImport)
+	if err != nil {
+		t.Fatalf("GetQueuedProfiles: %v", err)
+	}
+	got := make(map[string]*storage.Profile, len(profiles))
+	for _, profile := range profiles {
+		got[profile.ProfileURL] = profile
+	}
+	if len(got) != 5 {
+		t.Fatalf("queued navigator profiles = %d, want 5", len(got))
+	}
+
+	jane := got["https://www.linkedin.com/in/jane-doe"]
+	if jane == nil || jane.Name != "Doe, Jane" || jane.JobTitle != "VP of Sales" || jane.ConnectionDegree != 2 {
+		t.Errorf("jane = %+v, want the first row's quoted name, title and degree", jane)
+	}
+	john := got["https://www.linkedin.com/in/john-smith"]
+	if john == nil || john.JobTitle != `CTO "Platform"` || john.Company != "Globex" {
+		t.Errorf("john = %+v, want escaped quotes kept and company from Account Name", john)
+	}
+	if min := got["https://www.linkedin.com/in/min-lee"]; min == nil || min.Company != "Umbrella, Inc." {
+		t.Errorf("min = %+v, want the quoted company with its comma", min)
+	}
+
+	existing, err := store.GetProfileByURL("https://www.linkedin.com/in/existing")
+	if err != nil || existing == nil {
+		t.Fatalf("GetProfileByURL: %v, %v", existing, err)
+	}
+	if existing.DiscoverySource == storage.DiscoverySourceNavigatorImport {
+		t.Error("already stored profile was re-imported")
+	}
+
+	// Importing the same export again queues nothing
+	again, err := s.ImportNavigatorLeads(context.Background(), strings.NewReader(navigatorExport))
+	if err != nil || again != 0 {
+		t.Errorf("second import = %d, %v, want 0 new leads", again, err)
+	}
+}
+
+func TestImportNavigatorLeadsHeader(t *testing.T) {
+	s, _ := newStoreTestService(t)
+
+	tests := []struct {
+		name    string
+		csv     string
+		queued  int
+		wantErr error
+	}{
+		{"missing URL column", "Full Name,Title\nJane,VP\n", 0, ErrNavigatorURLColumnMissing},
+		{"reordered and lowercase columns", "linkedin url, full name\nhttps://www.linkedin.com/in/reordered,Jane\n", 1, nil},
+		{"row without a URL or with a company page", "Full Name,LinkedIn URL\nJane,\nAcme,https://www.linkedin.com/company/acme\n", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queued, err := s.ImportNavigatorLeads(context.Background(), strings.NewReader(tt.csv))
+			if !errors.Is(err, tt.wantErr) || queued != tt.queued {
+				t.Errorf("ImportNavigatorLeads = %d, %v, want %d, %v", queued, err, tt.queued, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := s.ImportNavigatorLeads(context.Background(), strings.NewReader("")); err == nil {
+		t.Error("empty file accepted, want a header error")
+	}
+}
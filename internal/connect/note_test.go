@@ -0,0 +1,126 @@
+package connect
+
+import (
+	"context"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/stealth"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+const (
+	// standardNoteModalHTML is the note modal shown right after "Add a note"
+	standardNoteModalHTML = `<div class="artdeco-modal" role="dialog">
+  <textarea name="message" maxlength="300"></textarea>
+  <span class="t-14 t-black--light">42/300</span>
+  <button aria-label="Send now">Send</button>
+</div>`
+
+	// premiumNotePromptHTML is the upsell free accounts see instead
+	premiumNotePromptHTML = `<div class="artdeco-modal modal-upsell" role="dialog">
+  <h2>Upgrade to Premium to personalize your invitation</h2>
+  <button class="artdeco-button--primary">Try Premium for free</button>
+  <button class="artdeco-button--secondary" aria-label="Skip for free"
+    onclick="document.querySelector('.modal-upsell').remove(); document.body.dataset.skipped = 'true'">Skip for free</button>
+</div>`
+)
+
+func newNoteTestService() *Service {
+	cfg := &config.Config{Selectors: config.DefaultSelectors()}
+	return &Service{cfg: cfg, stealth: stealth.New(cfg, "test")}
+}
+
+func TestHandlePremiumNotePrompt(t *testing.T) {
+	page := newFixturePage(t)
+	s := newNoteTestService()
+
+	tests := []struct {
+		name        string
+		html        string
+		wantAllowed bool
+	}{
+		{"standard flow", standardNoteModalHTML, true},
+		{"premium prompt", premiumNotePromptHTML, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := page.SetDocumentContent(tt.html); err != nil {
+				t.Fatalf("set content: %v", err)
+			}
+
+			allowed, err := s.HandlePremiumNotePrompt(context.Background(), page)
+			if err != nil {
+				t.Fatalf("HandlePremiumNotePrompt: %v", err)
+			}
+			if allowed != tt.wantAllowed {
+				t.Errorf("note allowed = %v, want %v", allowed, tt.wantAllowed)
+			}
+
+			// The prompt is dismissed through "Skip for free"
+			skipped := page.MustEval(`() => document.body.dataset.skipped === 'true'`).Bool()
+			if skipped == tt.wantAllowed {
+				t.Errorf("skip clicked = %v, want %v", skipped, !tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestLogNoteCharCount(t *testing.T) {
+	page := newFixturePage(t)
+	s := newNoteTestService()
+	s.cfg.Connection.NoteMaxLength = 200
+
+	log := logger.Get()
+	hooks := log.ReplaceHooks(make(logrus.LevelHooks))
+	t.Cleanup(func() { log.ReplaceHooks(hooks) })
+	hook := test.NewLocal(log)
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"modal counter", standardNoteModalHTML, "Note uses 42/300 characters (258 remaining)"},
+		{"no counter", `<div class="artdeco-modal"><textarea name="message"></textarea></div>`, "Note uses 50/200 characters (150 remaining)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := page.SetDocumentContent(tt.html); err != nil {
+				t.Fatalf("set content: %v", err)
+			}
+
+			hook.Reset()
+			s.logNoteCharCount(context.Background(), page, 50)
+			if entry := hook.LastEntry(); entry == nil || entry.Message != tt.want {
+				t.Errorf("logged %v, want %q", entry, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCharCounter(t *testing.T) {
+	tests := []struct {
+		text      string
+		used      int
+		total     int
+		wantValid bool
+	}{
+		{"42/300", 42, 300, true},
+		{" 0 / 200 ", 0, 200, true},
+		{"300", 0, 0, false},
+		{"abc/300", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		used, total, ok := parseCharCounter(tt.text)
+		if used != tt.used || total != tt.total || ok != tt.wantValid {
+			t.Errorf("parseCharCounter(%q) = %d, %d, %v, want %d, %d, %v", tt.text, used, total, ok, tt.used, tt.total, tt.wantValid)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package connect
+
+import (
+	"math/rand"
+	"strings"
+
+	"linkedin-automation/internal/config"
+)
+
+// TemplateWeights returns a selection weight for each candidate template
+// index. Every candidate starts at 1; each rule whose IfHeadlineContains
+// appears in the headline (case-insensitive) multiplies the weight of its
+// preferred template by BoostWeight. ruleIDs holds, per candidate, the
+// 1-based ID of the first rule that boosted it, or 0.
+func TemplateWeights(candidates []int, headline string, rules []config.TemplatePreferenceRule) ([]float64, []int) {
+	weights := make([]float64, len(candidates))
+	ruleIDs := make([]int, len(candidates))
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	headline = strings.ToLower(headline)
+	for r, rule := range rules {
+		keyword := strings.ToLower(strings.TrimSpace(rule.IfHeadlineContains))
+		if keyword == "" || rule.BoostWeight <= 0 || !strings.Contains(headline, keyword) {
+			continue
+		}
+
+		for i, index := range candidates {
+			if index != rule.PreferTemplateIndex {
+				continue
+			}
+			weights[i] *= rule.BoostWeight
+			if ruleIDs[i] == 0 {
+				ruleIDs[i] = r + 1
+			}
+		}
+	}
+
+	return weights, ruleIDs
+}
+
+// weightedChoice picks an index with probability proportional to its weight
+func weightedChoice(weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return rand.Intn(len(weights))
+	}
+
+	target := rand.Float64() * total
+	for i, w := range weights {
+		target -= w
+		if target < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
@@ -0,0 +1,72 @@
+package connect
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"linkedin-automation/internal/config"
+)
+
+func TestTemplateWeights(t *testing.T) {
+	rules := []config.TemplatePreferenceRule{
+		{IfHeadlineContains: "CTO", PreferTemplateIndex: 2, BoostWeight: 3},
+		{IfHeadlineContains: "founder", PreferTemplateIndex: 2, BoostWeight: 2},
+		{IfHeadlineContains: "engineer", PreferTemplateIndex: 0, BoostWeight: 0},
+		{IfHeadlineContains: " ", PreferTemplateIndex: 1, BoostWeight: 5},
+	}
+
+	tests := []struct {
+		name        string
+		headline    string
+		wantWeights []float64
+		wantRuleIDs []int
+	}{
+		{"no match", "Sales Manager", []float64{1, 1, 1}, []int{0, 0, 0}},
+		{"case-insensitive match", "cto at Acme", []float64{1, 1, 3}, []int{0, 0, 1}},
+		{"boosts multiply", "CTO and Co-Founder", []float64{1, 1, 6}, []int{0, 0, 1}},
+		{"zero boost ignored", "Software Engineer", []float64{1, 1, 1}, []int{0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, ruleIDs := TemplateWeights([]int{0, 1, 2}, tt.headline, rules)
+			if !reflect.DeepEqual(weights, tt.wantWeights) {
+				t.Errorf("weights = %v, want %v", weights, tt.wantWeights)
+			}
+			if !reflect.DeepEqual(ruleIDs, tt.wantRuleIDs) {
+				t.Errorf("ruleIDs = %v, want %v", ruleIDs, tt.wantRuleIDs)
+			}
+		})
+	}
+}
+
+func TestWeightedChoiceFollowsWeights(t *testing.T) {
+	const samples = 20000
+
+	tests := []struct {
+		name    string
+		weights []float64
+		want    []float64 // expected share of picks per index
+	}{
+		{"boosted template", []float64{1, 3}, []float64{0.25, 0.75}},
+		{"zero weight never picked", []float64{0, 1, 1}, []float64{0, 0.5, 0.5}},
+		{"all zero falls back to uniform", []float64{0, 0, 0, 0}, []float64{0.25, 0.25, 0.25, 0.25}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make([]int, len(tt.weights))
+			for i := 0; i < samples; i++ {
+				counts[weightedChoice(tt.weights)]++
+			}
+
+			for i, want := range tt.want {
+				got := float64(counts[i]) / samples
+				if math.Abs(got-want) > 0.02 || (want == 0 && counts[i] != 0) {
+					t.Errorf("index %d picked %.3f of the time, want %.2f (counts %v)", i, got, want, counts)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/search"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+)
+
+// ErrNoSearchResultConnect is returned when a search result card has no
+// Connect button, e.g. because it shows Follow or Message instead
+var ErrNoSearchResultConnect = errors.New("no connect button in search result")
+
+// peopleSearchURL is the people search used to find a profile's result card
+const peopleSearchURL = "https://www.linkedin.com/search/results/people/"
+
+// SendConnectionFromSearchResult clicks the Connect button in a search result
+// card without leaving the results page. The invitation modal it opens is
+// left for the caller to complete.
+func (s *Service) SendConnectionFromSearchResult(ctx context.Context, searchResultElement *rod.Element) error {
+	connectButton, err := browser.FindFirst(searchResultElement, "SearchResultConnectButton", s.cfg.Selectors.SearchResultConnectButton, 0)
+	if err != nil {
+		return ErrNoSearchResultConnect
+	}
+
+	if err := searchResultElement.ScrollIntoView(); err != nil {
+		return fmt.Errorf("failed to scroll to search result: %w", err)
+	}
+	s.stealth.RandomDelay("scroll")
+
+	if err := s.stealth.HumanClick(ctx, connectButton); err != nil {
+		return fmt.Errorf("failed to click connect: %w", err)
+	}
+
+	s.stealth.RandomDelay("action")
+	return nil
+}
+
+// connectFromSearchResults searches for a profile by name and sends the
+// request from its result card. It reports false with no error when the
+// card or its Connect button wasn't found, so the profile page can be used.
+func (s *Service) connectFromSearchResults(ctx context.Context, profile *storage.Profile) (bool, error) {
+	log := logger.FromContext(ctx)
+
+	if profile.Name == "" {
+		return false, nil
+	}
+
+	// The lookup is a people search like any other, so it uses the monthly
+	// search quota
+	if err := search.CheckSearchQuota(ctx, s.store, s.cfg); err != nil {
+		log.Debugf("Visiting %s instead of searching: %v", profile.ProfileURL, err)
+		return false, nil
+	}
+
+	searchURL := peopleSearchURL + "?" + url.Values{"keywords": {profile.Name}}.Encode()
+	if err := s.browser.Navigate(searchURL); err != nil {
+		return false, fmt.Errorf("failed to navigate to search results: %w", err)
+	}
+	search.RecordSearchView(ctx, s.store, s.cfg)
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	card := s.findSearchResult(page, profile.ProfileURL)
+	if card == nil {
+		log.Debugf("No search result for %s, visiting the profile", profile.ProfileURL)
+		return false, nil
+	}
+
+	err := s.SendConnectionFromSearchResult(ctx, card)
+	if errors.Is(err, ErrNoSearchResultConnect) {
+		log.Debugf("No Connect button on the search result for %s, visiting the profile", profile.ProfileURL)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	log.Infof("Connecting with %s from search results", profile.ProfileURL)
+	return true, s.completeConnectionRequest(ctx, page, profile)
+}
+
+// findSearchResult returns the result card linking to profileURL, or nil
+func (s *Service) findSearchResult(page *rod.Page, profileURL string) *rod.Element {
+	cards, err := page.Elements(s.cfg.Selectors.SearchResultCard)
+	if err != nil {
+		return nil
+	}
+
+	want := profileURLKey(profileURL)
+	for _, card := range cards {
+		has, link, err := card.Has(s.cfg.Selectors.ProfileLink)
+		if err != nil || !has {
+			continue
+		}
+		href, err := link.Attribute("href")
+		if err == nil && href != nil && profileURLKey(*href) == want {
+			return card
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package connect
+
+import (
+	"context"
+	"testing"
+
+	"linkedin-automation/internal/storage"
+)
+
+func TestConnectFromSearchResultsRespectsSearchQuota(t *testing.T) {
+	s, store := newStoreTestService(t)
+	s.cfg.Search.TrackSearchQuota = true
+	s.cfg.Search.MonthlySearchLimit = 10
+
+	if err := store.RecordSearchViews(10, 10); err != nil {
+		t.Fatalf("RecordSearchViews: %v", err)
+	}
+
+	// The service has no browser, so searching would panic
+	profile := &storage.Profile{ProfileURL: "https://www.linkedin.com/in/jane", Name: "Jane Doe"}
+	sent, err := s.connectFromSearchResults(context.Background(), profile)
+	if err != nil || sent {
+		t.Errorf("connectFromSearchResults = %v, %v; want false, nil with the quota used up", sent, err)
+	}
+}
@@ -0,0 +1,77 @@
+package connect
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/logger"
+)
+
+// PoissonInterval draws the wait before the next connection request from an
+// exponential distribution with a mean of 1/lambdaPerHour hours, so requests
+// arrive as a Poisson process instead of in evenly spaced bursts
+func PoissonInterval(lambdaPerHour float64) time.Duration {
+	if lambdaPerHour <= 0 {
+		return 0
+	}
+
+	meanSeconds := 3600 / lambdaPerHour
+	return time.Duration(rand.ExpFloat64() * meanSeconds * float64(time.Second))
+}
+
+// waitBeforeNextRequest sleeps between connection requests: a Poisson
+// interval when Connection.PoissonRateLimit is set, otherwise the usual
+// action delay
+func (s *Service) waitBeforeNextRequest(ctx context.Context) {
+	lambda := s.cfg.Connection.PoissonRateLimit.LambdaPerHour
+	if lambda <= 0 {
+		s.stealth.RandomDelay("action")
+		return
+	}
+
+	log := logger.FromContext(ctx)
+	wait := PoissonInterval(lambda)
+	log.Debugf("Waiting %s before the next connection request", wait.Round(time.Second))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(wait):
+	}
+}
+
+// SimulateTimingDistribution returns the times connection requests would be
+// sent over window starting at start, drawing Poisson intervals and dropping
+// requests that would exceed the hourly or daily connection limits
+func SimulateTimingDistribution(cfg *config.Config, start time.Time, window time.Duration) []time.Time {
+	lambda := cfg.Connection.PoissonRateLimit.LambdaPerHour
+	if lambda <= 0 {
+		return nil
+	}
+
+	perHour := cfg.RateLimits.Connections.PerHour
+	perDay := cfg.RateLimits.Connections.PerDay
+
+	var sent []time.Time
+	for t := start.Add(PoissonInterval(lambda)); t.Before(start.Add(window)); t = t.Add(PoissonInterval(lambda)) {
+		if perDay > 0 && countSince(sent, t.Add(-24*time.Hour)) >= perDay {
+			continue
+		}
+		if perHour > 0 && countSince(sent, t.Add(-time.Hour)) >= perHour {
+			continue
+		}
+		sent = append(sent, t)
+	}
+
+	return sent
+}
+
+// countSince counts the sorted times at or after since
+func countSince(times []time.Time, since time.Time) int {
+	count := 0
+	for i := len(times) - 1; i >= 0 && !times[i].Before(since); i-- {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,88 @@
+package connect
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+)
+
+func TestPoissonIntervalMean(t *testing.T) {
+	const (
+		samples = 10000
+		lambda  = 6.0 // requests per hour
+	)
+
+	var total time.Duration
+	for i := 0; i < samples; i++ {
+		total += PoissonInterval(lambda)
+	}
+
+	rate := samples / total.Hours()
+	if math.Abs(rate-lambda) > lambda*0.05 {
+		t.Errorf("empirical rate = %.2f/h, want within 5%% of %.0f/h", rate, lambda)
+	}
+
+	if got := PoissonInterval(0); got != 0 {
+		t.Errorf("PoissonInterval(0) = %v, want 0", got)
+	}
+}
+
+func TestSimulateTimingDistributionRate(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Connection.PoissonRateLimit.LambdaPerHour = 1
+
+	// About 100 requests over 100 hours without limits
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := 100 * time.Hour
+
+	// The count of a Poisson process over this window only stays within
+	// 10% about two times in three, so average a few runs
+	const runs = 10
+	total := 0
+	for i := 0; i < runs; i++ {
+		sent := SimulateTimingDistribution(cfg, start, window)
+		for j, at := range sent {
+			if at.Before(start) || !at.Before(start.Add(window)) {
+				t.Fatalf("request at %s outside the window", at)
+			}
+			if j > 0 && at.Before(sent[j-1]) {
+				t.Fatalf("requests out of order at %d", j)
+			}
+		}
+		total += len(sent)
+	}
+
+	rate := float64(total) / runs / window.Hours()
+	if math.Abs(rate-1) > 0.1 {
+		t.Errorf("empirical rate = %.2f/h, want within 10%% of 1/h", rate)
+	}
+}
+
+func TestSimulateTimingDistributionRespectsLimits(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Connection.PoissonRateLimit.LambdaPerHour = 20
+	cfg.RateLimits.Connections.PerHour = 5
+	cfg.RateLimits.Connections.PerDay = 30
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sent := SimulateTimingDistribution(cfg, start, 48*time.Hour)
+
+	for i, at := range sent {
+		if n := countSince(sent[:i+1], at.Add(-time.Hour)); n > 5 {
+			t.Fatalf("%d requests in the hour before %s, want at most 5", n, at)
+		}
+		if n := countSince(sent[:i+1], at.Add(-24*time.Hour)); n > 30 {
+			t.Fatalf("%d requests in the day before %s, want at most 30", n, at)
+		}
+	}
+	if len(sent) == 0 {
+		t.Error("no requests simulated")
+	}
+
+	cfg.Connection.PoissonRateLimit.LambdaPerHour = 0
+	if got := SimulateTimingDistribution(cfg, start, time.Hour); got != nil {
+		t.Errorf("SimulateTimingDistribution without a rate = %v, want nil", got)
+	}
+}
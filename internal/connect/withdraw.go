@@ -0,0 +1,180 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+// withdrawalRateAlertThreshold is the share of a week's requests that may
+// be withdrawn before the operator is alerted, as a high rate suggests the
+// targeting or notes aren't working
+const withdrawalRateAlertThreshold = 0.10
+
+// withdrawalCohortDays is the length of the cohort the withdrawal rate is
+// calculated over
+const withdrawalCohortDays = 7
+
+// WithdrawStaleConnections withdraws up to maxWithdrawals connection requests
+// that have been pending for more than olderThanDays days, from each
+// profile's page, then checks the withdrawal rate of the week of requests that
+// just became stale
+func (s *Service) WithdrawStaleConnections(ctx context.Context, olderThanDays int, maxWithdrawals int) (int, error) {
+	log := logger.FromContext(ctx)
+
+	stale, err := s.store.GetPendingConnectionsOlderThan(olderThanDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stale pending connections: %w", err)
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	log.Infof("Withdrawing up to %d of %d requests pending over %d days", maxWithdrawals, len(stale), olderThanDays)
+
+	withdrawn := 0
+	for _, conn := range stale {
+		if withdrawn >= maxWithdrawals {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return withdrawn, ctx.Err()
+		default:
+		}
+
+		if s.cfg.DryRun {
+			log.Infof("[dry-run] Would withdraw request to %s pending since %s", conn.ProfileURL, conn.SentAt.Format("2006-01-02"))
+			withdrawn++
+			continue
+		}
+
+		if err := s.browser.Actions().Begin(); err != nil {
+			log.Info("Shutting down, stopping withdrawals")
+			break
+		}
+		err := s.withdrawConnection(ctx, conn)
+		s.browser.Actions().End()
+		if err != nil {
+			log.Errorf("Failed to withdraw request to %s: %v", conn.ProfileURL, err)
+			s.store.LogActivityAsync("withdraw", conn.ProfileURL, "failed", err.Error())
+			continue
+		}
+
+		withdrawn++
+		s.stealth.RandomDelay("think")
+	}
+
+	if s.cfg.DryRun {
+		log.Infof("[dry-run] Would withdraw %d stale connection requests", withdrawn)
+		return withdrawn, nil
+	}
+
+	log.Infof("Withdrew %d stale connection requests", withdrawn)
+
+	if withdrawn > 0 {
+		s.checkWithdrawalRate(ctx, olderThanDays)
+	}
+
+	return withdrawn, nil
+}
+
+// withdrawConnection opens the profile, clicks "Pending" and confirms the
+// withdrawal
+func (s *Service) withdrawConnection(ctx context.Context, conn storage.ConnectionRequest) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.browser.Navigate(conn.ProfileURL); err != nil {
+		return fmt.Errorf("failed to navigate to profile: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	pending, err := s.stealth.WaitForElement(page, s.cfg.Selectors.PendingButton, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("pending button not found (selector PendingButton): %w", err)
+	}
+
+	if err := s.stealth.HumanClick(ctx, pending); err != nil {
+		return fmt.Errorf("failed to click pending: %w", err)
+	}
+
+	s.stealth.RandomDelay("action")
+
+	confirm, err := s.stealth.WaitForElement(page, s.cfg.Selectors.PendingWithdrawButton, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("withdraw confirmation not found (selector PendingWithdrawButton): %w", err)
+	}
+
+	if err := s.stealth.HumanClick(ctx, confirm); err != nil {
+		return fmt.Errorf("failed to confirm withdrawal: %w", err)
+	}
+
+	s.stealth.RandomDelay("action")
+
+	err = s.store.UpdateConnectionStatus(conn.ProfileURL, string(storage.StateWithdrawn))
+	if errors.Is(err, storage.ErrStateConflict) {
+		log.Warnf("Connection state not updated for %s: %v", conn.ProfileURL, err)
+	} else if err != nil {
+		return fmt.Errorf("failed to record withdrawal: %w", err)
+	}
+
+	age := time.Since(conn.SentAt).Round(time.Hour)
+	s.store.LogActivityAsync("withdraw", conn.ProfileURL, "success", fmt.Sprintf("pending for %s", age))
+	log.Infof("Withdrew request to %s after %s", conn.ProfileURL, age)
+
+	return nil
+}
+
+// checkWithdrawalRate alerts the operator when more than
+// withdrawalRateAlertThreshold of the requests sent in the week before the
+// withdrawal cutoff were withdrawn. The alert is sent once when the rate
+// crosses the threshold, not on every run while it stays above it.
+func (s *Service) checkWithdrawalRate(ctx context.Context, olderThanDays int) {
+	log := logger.FromContext(ctx)
+
+	to := time.Now().AddDate(0, 0, -olderThanDays)
+	from := to.AddDate(0, 0, -withdrawalCohortDays)
+	withdrawn, sent, err := s.store.GetWithdrawalCounts(from, to)
+	if err != nil {
+		log.Warnf("Failed to calculate withdrawal rate: %v", err)
+		return
+	}
+
+	rate := WithdrawalRate(withdrawn, sent)
+	log.Infof("Withdrawal rate of requests sent %s to %s: %.0f%% (%d withdrawn, %d sent)",
+		from.Format("2006-01-02"), to.Format("2006-01-02"), rate*100, withdrawn, sent)
+
+	if rate <= withdrawalRateAlertThreshold {
+		s.withdrawalAlerted = false
+		return
+	}
+	if s.withdrawalAlerted || s.notifier == nil {
+		return
+	}
+
+	text := fmt.Sprintf("High connection withdrawal rate: %d of %d requests sent %s to %s (%.0f%%) were withdrawn. Check targeting and note templates.",
+		withdrawn, sent, from.Format("2006-01-02"), to.Format("2006-01-02"), rate*100)
+	if err := s.notifier.Notify(ctx, text); err != nil {
+		log.Warnf("Failed to send withdrawal rate notification: %v", err)
+		return
+	}
+	s.withdrawalAlerted = true
+}
+
+// WithdrawalRate returns withdrawn/sent, or 0 when nothing was sent
+func WithdrawalRate(withdrawn, sent int) float64 {
+	if sent <= 0 {
+		return 0
+	}
+	return float64(withdrawn) / float64(sent)
+}
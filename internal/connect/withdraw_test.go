@@ -0,0 +1,26 @@
+package connect
+
+import "testing"
+
+func TestWithdrawalRate(t *testing.T) {
+	tests := []struct {
+		withdrawn, sent int
+		want            float64
+	}{
+		{0, 0, 0},
+		{3, 0, 0},
+		{0, 20, 0},
+		{2, 20, 0.1},
+		{5, 20, 0.25},
+	}
+
+	for _, tt := range tests {
+		if got := WithdrawalRate(tt.withdrawn, tt.sent); got != tt.want {
+			t.Errorf("WithdrawalRate(%d, %d) = %v, want %v", tt.withdrawn, tt.sent, got, tt.want)
+		}
+	}
+
+	if WithdrawalRate(2, 20) > withdrawalRateAlertThreshold {
+		t.Error("a 10% rate should not alert")
+	}
+}
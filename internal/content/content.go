@@ -0,0 +1,149 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/engage"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/stealth"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+)
+
+// countPattern matches counts such as "1,204" or "12 comments"
+var countPattern = regexp.MustCompile(`\d[\d,]*`)
+
+// Post is a post from a profile's recent activity feed
+type Post struct {
+	URL          string
+	Content      string
+	PostedAt     *time.Time
+	LikeCount    int
+	CommentCount int
+}
+
+type Service struct {
+	browser *browser.Context
+	store   *storage.Storage
+	cfg     *config.Config
+	stealth *stealth.Stealth
+}
+
+func New(browser *browser.Context, store *storage.Storage, cfg *config.Config) *Service {
+	return &Service{
+		browser: browser,
+		store:   store,
+		cfg:     cfg,
+		stealth: browser.NewStealth("content"),
+	}
+}
+
+// GetRecentPosts opens the profile's recent activity feed, extracts up to
+// maxPosts posts and stores them in profile_posts
+func (s *Service) GetRecentPosts(ctx context.Context, profile *storage.Profile, maxPosts int) ([]Post, error) {
+	log := logger.FromContext(ctx)
+
+	activityURL := strings.TrimSuffix(profile.ProfileURL, "/") + "/recent-activity/all/"
+	if err := s.browser.Navigate(activityURL); err != nil {
+		return nil, fmt.Errorf("failed to navigate to activity: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	s.stealth.SimulateReading(page)
+
+	posts, err := s.extractPosts(page, maxPosts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make([]storage.ProfilePost, 0, len(posts))
+	for _, post := range posts {
+		stored = append(stored, storage.ProfilePost{
+			ProfileID:    profile.ID,
+			URL:          post.URL,
+			Content:      post.Content,
+			PostedAt:     post.PostedAt,
+			LikeCount:    post.LikeCount,
+			CommentCount: post.CommentCount,
+		})
+	}
+	if err := s.store.SaveProfilePosts(profile.ID, stored); err != nil {
+		return posts, fmt.Errorf("failed to save posts: %w", err)
+	}
+
+	log.Infof("Scraped %d recent posts from %s", len(posts), profile.ProfileURL)
+	return posts, nil
+}
+
+// extractPosts reads the posts listed on an activity feed page
+func (s *Service) extractPosts(page *rod.Page, maxPosts int, now time.Time) ([]Post, error) {
+	elements, err := page.Elements(s.cfg.Selectors.ActivityPost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find posts (selector ActivityPost): %w", err)
+	}
+
+	var posts []Post
+	for _, element := range elements {
+		if maxPosts > 0 && len(posts) >= maxPosts {
+			break
+		}
+
+		urn, err := element.Attribute("data-urn")
+		if err != nil || urn == nil || *urn == "" {
+			continue
+		}
+
+		text := elementText(element, s.cfg.Selectors.PostText)
+		if text == "" {
+			// Reposts without commentary have nothing to learn from
+			continue
+		}
+
+		post := Post{
+			URL:          "https://www.linkedin.com/feed/update/" + *urn + "/",
+			Content:      text,
+			LikeCount:    ParseCount(elementText(element, s.cfg.Selectors.PostLikeCount)),
+			CommentCount: ParseCount(elementText(element, s.cfg.Selectors.PostCommentCount)),
+		}
+		if age, ok := engage.ParsePostAge(elementText(element, s.cfg.Selectors.PostTimestamp)); ok {
+			postedAt := now.Add(-age)
+			post.PostedAt = &postedAt
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+// elementText returns the trimmed text of the first descendant matching
+// selector, or "" if there is none
+func elementText(parent *rod.Element, selector string) string {
+	has, element, err := parent.Has(selector)
+	if err != nil || !has {
+		return ""
+	}
+
+	text, _ := element.Text()
+	return strings.TrimSpace(text)
+}
+
+// ParseCount extracts the first number from text such as "1,204" or
+// "12 comments", returning 0 when there is none
+func ParseCount(text string) int {
+	match := countPattern.FindString(text)
+	n, _ := strconv.Atoi(strings.ReplaceAll(match, ",", ""))
+	return n
+}
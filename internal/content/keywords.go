@@ -0,0 +1,111 @@
+package content
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// urlPattern matches links, which would otherwise add "https", "www" etc.
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// minKeywordLength drops short words that rarely carry a topic
+const minKeywordLength = 4
+
+// stopWords are common words that say nothing about a post's topic
+var stopWords = map[string]bool{
+	"about": true, "above": true, "after": true, "again": true, "against": true,
+	"also": true, "because": true, "been": true, "before": true, "being": true,
+	"below": true, "between": true, "both": true, "could": true, "does": true,
+	"doing": true, "down": true, "during": true, "each": true, "even": true,
+	"every": true, "from": true, "further": true, "great": true, "have": true,
+	"having": true, "here": true, "into": true, "just": true, "know": true,
+	"like": true, "love": true, "make": true, "many": true, "more": true,
+	"most": true, "much": true, "need": true, "only": true, "other": true,
+	"over": true, "really": true, "same": true, "should": true, "some": true,
+	"such": true, "than": true, "thank": true, "thanks": true, "that": true,
+	"their": true, "them": true, "then": true, "there": true, "these": true,
+	"they": true, "thing": true, "things": true, "think": true, "this": true,
+	"those": true, "through": true, "time": true, "today": true, "under": true,
+	"until": true, "very": true, "want": true, "week": true, "well": true,
+	"were": true, "what": true, "when": true, "where": true, "which": true,
+	"while": true, "will": true, "with": true, "would": true, "year": true,
+	"your": true, "yours": true, "excited": true, "happy": true, "proud": true,
+	"share": true, "sharing": true, "post": true, "read": true, "link": true,
+	"comments": true, "hashtag": true,
+}
+
+// GetTopKeywords returns the topN most frequent topic words across a
+// profile's stored posts
+func (s *Service) GetTopKeywords(profileID int64, topN int) ([]string, error) {
+	posts, err := s.store.GetProfilePosts(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, 0, len(posts))
+	for _, post := range posts {
+		texts = append(texts, post.Content)
+	}
+	return TopKeywords(texts, topN), nil
+}
+
+// TopKeywords tokenizes texts, drops stop words, short words and numbers,
+// and returns the topN most frequent words. Ties keep first-seen order.
+func TopKeywords(texts []string, topN int) []string {
+	counts := make(map[string]int)
+	var order []string
+
+	for _, text := range texts {
+		for _, word := range tokenize(text) {
+			if len([]rune(word)) < minKeywordLength || stopWords[word] || isNumber(word) {
+				continue
+			}
+			if counts[word] == 0 {
+				order = append(order, word)
+			}
+			counts[word]++
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return counts[order[i]] > counts[order[j]]
+	})
+
+	if topN > 0 && len(order) > topN {
+		order = order[:topN]
+	}
+	return order
+}
+
+// RecentTopics formats keywords for the {{RecentTopics}} placeholder
+func RecentTopics(keywords []string) string {
+	switch len(keywords) {
+	case 0:
+		return ""
+	case 1:
+		return keywords[0]
+	default:
+		return strings.Join(keywords[:len(keywords)-1], ", ") + " and " + keywords[len(keywords)-1]
+	}
+}
+
+// tokenize lowercases text and splits it into words, keeping hashtags'
+// words without the #
+func tokenize(text string) []string {
+	text = urlPattern.ReplaceAllString(strings.ToLower(text), " ")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+'
+	})
+}
+
+// isNumber reports whether word is all digits
+func isNumber(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
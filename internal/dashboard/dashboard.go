@@ -0,0 +1,112 @@
+package dashboard
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"time"
+
+	"linkedin-automation/internal/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Server serves the embedded dashboard page and proxies /api/ to the REST API
+// so the page can be served without CORS
+type Server struct {
+	addr string
+	api  http.Handler
+	log  *logrus.Logger
+	mux  *http.ServeMux
+}
+
+// DefaultAddr is the dashboard address used when none is configured; it
+// only listens on the loopback interface
+const DefaultAddr = "127.0.0.1:3000"
+
+// New creates a dashboard server on addr backed by the REST API handler.
+// A nil api serves the page without mounting /api/.
+func New(addr string, api http.Handler) (*Server, error) {
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dashboard files: %w", err)
+	}
+
+	s := &Server{
+		addr: addr,
+		api:  api,
+		log:  logger.Get(),
+		mux:  http.NewServeMux(),
+	}
+
+	if api != nil {
+		s.mux.Handle("/api/", http.StripPrefix("/api", sameOrigin(api)))
+	}
+	s.mux.Handle("/", http.FileServer(http.FS(static)))
+
+	return s, nil
+}
+
+// Handler returns the HTTP handler serving the dashboard
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start serves the dashboard until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	addr := s.addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	s.log.Infof("Dashboard listening on http://%s", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("dashboard server failed: %w", err)
+	}
+
+	return nil
+}
+
+// sameOrigin rejects requests other than GET and HEAD unless their Origin (or
+// Referer when Origin is absent) is the dashboard itself, so another site
+// open in the browser cannot pause the bot or toggle safe mode
+func sameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		source := r.Header.Get("Origin")
+		if source == "" {
+			source = r.Header.Get("Referer")
+		}
+		u, err := url.Parse(source)
+		if source == "" || err != nil || u.Host != r.Host {
+			http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
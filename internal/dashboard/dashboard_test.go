@@ -0,0 +1,100 @@
+package dashboard
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestServesEmbeddedFiles(t *testing.T) {
+	s, err := New("", nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		file     string
+		mimeType string
+	}{
+		{"/", "static/index.html", "text/html"},
+		{"/app.js", "static/app.js", "text/javascript"},
+		{"/style.css", "static/style.css", "text/css"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			want, err := fs.ReadFile(staticFiles, tt.file)
+			if err != nil {
+				t.Fatalf("read %s: %v", tt.file, err)
+			}
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.mimeType) {
+				t.Errorf("Content-Type = %q, want %s", ct, tt.mimeType)
+			}
+			if cl := rec.Header().Get("Content-Length"); cl != strconv.Itoa(len(want)) {
+				t.Errorf("Content-Length = %q, want %d", cl, len(want))
+			}
+		})
+	}
+}
+
+func TestAPIOnlyMountedWhenEnabled(t *testing.T) {
+	s, err := New("", nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 without an API handler", rec.Code)
+	}
+}
+
+func TestRejectsCrossOriginWrites(t *testing.T) {
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s, err := New("", api)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		origin string
+		want   int
+	}{
+		{"get without origin", http.MethodGet, "", http.StatusNoContent},
+		{"post same origin", http.MethodPost, "http://127.0.0.1:3000", http.StatusNoContent},
+		{"post other origin", http.MethodPost, "http://evil.example", http.StatusForbidden},
+		{"post without origin", http.MethodPost, "", http.StatusForbidden},
+		{"delete other origin", http.MethodDelete, "http://evil.example", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://127.0.0.1:3000/api/control/pause", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+"use strict";
+
+const REFRESH_MS = 30000;
+const API = "/api";
+
+let chart = null;
+let paused = false;
+
+async function getJSON(path, options) {
+  const resp = await fetch(API + path, options);
+  if (!resp.ok) {
+    throw new Error(path + " returned " + resp.status);
+  }
+  return resp.json();
+}
+
+function setText(id, value) {
+  document.getElementById(id).textContent = value;
+}
+
+function renderToday(points) {
+  const today = points[points.length - 1] || {};
+  setText("today-connections", today.connections_sent || 0);
+  setText("today-accepted", today.connections_accepted || 0);
+  setText("today-messages", today.messages_sent || 0);
+  setText("today-replies", today.replies || 0);
+  setText("today-discovered", today.profiles_discovered || 0);
+}
+
+function renderChart(points) {
+  const labels = points.map((p) => p.date);
+  const series = [
+    { label: "Connections sent", key: "connections_sent", color: "#0a66c2" },
+    { label: "Accepted", key: "connections_accepted", color: "#057642" },
+    { label: "Messages sent", key: "messages_sent", color: "#915907" },
+    { label: "Replies", key: "replies", color: "#b24020" },
+  ];
+  const datasets = series.map((s) => ({
+    label: s.label,
+    data: points.map((p) => p[s.key]),
+    borderColor: s.color,
+    backgroundColor: s.color,
+    tension: 0.25,
+  }));
+
+  if (typeof Chart === "undefined") {
+    return;
+  }
+
+  if (chart) {
+    chart.data.labels = labels;
+    chart.data.datasets = datasets;
+    chart.update();
+    return;
+  }
+
+  chart = new Chart(document.getElementById("chart"), {
+    type: "line",
+    data: { labels, datasets },
+    options: {
+      scales: { y: { beginAtZero: true, ticks: { precision: 0 } } },
+    },
+  });
+}
+
+function renderActivity(entries) {
+  const body = document.getElementById("activity");
+  body.replaceChildren();
+
+  for (const entry of entries) {
+    const row = document.createElement("tr");
+    const cells = [
+      new Date(entry.created_at).toLocaleString(),
+      entry.action_type,
+      entry.outcome,
+      entry.target_url,
+    ];
+    cells.forEach((text, i) => {
+      const cell = document.createElement("td");
+      cell.textContent = text || "";
+      if (i === 2 && entry.outcome === "failed") {
+        cell.className = "failed";
+        cell.title = entry.error_message || "";
+      }
+      if (i === 3) {
+        cell.className = "target";
+        cell.title = text || "";
+      }
+      row.appendChild(cell);
+    });
+    body.appendChild(row);
+  }
+}
+
+function renderControl(state) {
+  paused = state.paused;
+  const status = document.getElementById("status");
+  status.textContent = paused ? "paused" : "running";
+  status.classList.toggle("paused", paused);
+
+  const button = document.getElementById("toggle");
+  button.textContent = paused ? "Resume" : "Pause";
+  button.disabled = false;
+}
+
+async function refresh() {
+  try {
+    const [points, activity, control] = await Promise.all([
+      getJSON("/stats/daily"),
+      getJSON("/activity?limit=25"),
+      getJSON("/control"),
+    ]);
+    renderToday(points);
+    renderChart(points);
+    renderActivity(activity);
+    renderControl(control);
+    setText("updated", "updated " + new Date().toLocaleTimeString());
+  } catch (err) {
+    setText("updated", "update failed: " + err.message);
+  }
+}
+
+async function toggle() {
+  const button = document.getElementById("toggle");
+  button.disabled = true;
+  try {
+    const path = paused ? "/control/resume" : "/control/pause";
+    renderControl(await getJSON(path, { method: "POST" }));
+  } catch (err) {
+    setText("updated", "toggle failed: " + err.message);
+    button.disabled = false;
+  }
+}
+
+document.getElementById("toggle").addEventListener("click", toggle);
+refresh();
+setInterval(refresh, REFRESH_MS);
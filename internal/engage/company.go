@@ -0,0 +1,148 @@
+package engage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+)
+
+// ErrAlreadyFollowing is returned when the company page shows "Following"
+var ErrAlreadyFollowing = errors.New("already following company")
+
+// FollowCompanies follows the configured company pages and the company pages
+// of stored profiles that haven't been followed yet
+func (s *Service) FollowCompanies(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	if !s.cfg.Engagement.CompanyFollowEnabled {
+		return 0, nil
+	}
+
+	profileCompanies, err := s.store.GetProfileCompanyURLs()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get profile companies: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	followed := 0
+	for _, companyURL := range append(s.cfg.Engagement.CompanyURLs, profileCompanies...) {
+		select {
+		case <-ctx.Done():
+			return followed, ctx.Err()
+		default:
+		}
+
+		companyURL = normalizeCompanyURL(companyURL)
+		if !IsCompanyPageURL(companyURL) || seen[companyURL] {
+			continue
+		}
+		seen[companyURL] = true
+
+		if done, err := s.store.IsCompanyFollowed(companyURL); err != nil || done {
+			continue
+		}
+
+		if s.cfg.DryRun {
+			log.Infof("[dry-run] Would follow %s", companyURL)
+			followed++
+			continue
+		}
+
+		if err := s.browser.Actions().Begin(); err != nil {
+			log.Info("Shutting down, stopping company follows")
+			break
+		}
+		err := s.FollowCompany(ctx, companyURL)
+		s.browser.Actions().End()
+		switch {
+		case errors.Is(err, ErrAlreadyFollowing):
+			log.Debugf("Already following %s", companyURL)
+			continue
+		case err != nil:
+			log.Errorf("Failed to follow %s: %v", companyURL, err)
+			s.store.LogActivityAsync("company_follow", companyURL, "failed", err.Error())
+			continue
+		}
+
+		followed++
+		s.stealth.RandomDelay("think")
+	}
+
+	log.Infof("Followed %d companies", followed)
+	return followed, nil
+}
+
+// FollowCompany opens a company page and clicks its Follow button
+func (s *Service) FollowCompany(ctx context.Context, companyURL string) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.browser.Navigate(companyURL); err != nil {
+		return fmt.Errorf("failed to navigate to company: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	companyName := ""
+	if has, nameElement, err := page.Has(s.cfg.Selectors.CompanyName); err == nil && has {
+		text, _ := nameElement.Text()
+		companyName = strings.TrimSpace(text)
+	}
+
+	button, err := browser.FindFirst(page, "CompanyFollowButton", s.cfg.Selectors.CompanyFollowButton, 0)
+	if err != nil {
+		return err
+	}
+
+	// The same button reads "Following" once followed
+	text, _ := button.Text()
+	if strings.HasPrefix(strings.TrimSpace(text), "Following") {
+		if err := s.store.SaveCompanyFollow(companyURL, companyName); err != nil {
+			log.Warnf("Failed to record existing follow for %s: %v", companyURL, err)
+		}
+		return ErrAlreadyFollowing
+	}
+
+	s.stealth.SimulateReading(page)
+
+	if err := s.stealth.HumanClick(ctx, button); err != nil {
+		return fmt.Errorf("failed to click follow: %w", err)
+	}
+
+	s.stealth.RandomDelay("action")
+
+	if err := s.store.SaveCompanyFollow(companyURL, companyName); err != nil {
+		return fmt.Errorf("failed to save company follow: %w", err)
+	}
+
+	s.store.LogActivityAsync("company_follow", companyURL, "success", companyName)
+	log.Infof("Followed company %s", companyName)
+
+	return nil
+}
+
+// IsCompanyPageURL reports whether a URL points at a LinkedIn company page
+func IsCompanyPageURL(url string) bool {
+	return strings.Contains(url, "linkedin.com/company/")
+}
+
+// normalizeCompanyURL strips query parameters and sub-pages such as /about/
+// so the same company is only followed once
+func normalizeCompanyURL(url string) string {
+	url = strings.TrimSpace(strings.Split(url, "?")[0])
+
+	idx := strings.Index(url, "/company/")
+	if idx < 0 {
+		return url
+	}
+
+	rest := strings.TrimPrefix(url[idx:], "/company/")
+	slug := strings.SplitN(rest, "/", 2)[0]
+	return url[:idx] + "/company/" + slug + "/"
+}
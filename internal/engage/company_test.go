@@ -0,0 +1,57 @@
+package engage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+func TestFollowCompaniesDryRun(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{DryRun: true}
+	cfg.Engagement.CompanyFollowEnabled = true
+	cfg.Engagement.CompanyURLs = []string{
+		"https://www.linkedin.com/company/acme/about/",
+		"https://www.linkedin.com/company/acme/?trk=feed",
+		"https://www.linkedin.com/in/not-a-company",
+	}
+
+	// The service has no browser, so opening a company page would panic
+	s := &Service{store: store, cfg: cfg}
+	followed, err := s.FollowCompanies(context.Background())
+	if err != nil {
+		t.Fatalf("FollowCompanies: %v", err)
+	}
+	if followed != 1 {
+		t.Errorf("followed = %d, want 1", followed)
+	}
+
+	if done, err := store.IsCompanyFollowed("https://www.linkedin.com/company/acme/"); err != nil || done {
+		t.Errorf("IsCompanyFollowed = %v, %v; a dry run must not record follows", done, err)
+	}
+}
+
+func TestNormalizeCompanyURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.linkedin.com/company/acme/about/?trk=x", "https://www.linkedin.com/company/acme/"},
+		{" https://www.linkedin.com/company/acme ", "https://www.linkedin.com/company/acme/"},
+		{"https://www.linkedin.com/in/jane", "https://www.linkedin.com/in/jane"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeCompanyURL(tt.url); got != tt.want {
+			t.Errorf("normalizeCompanyURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
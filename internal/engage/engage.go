@@ -0,0 +1,307 @@
+package engage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/stealth"
+	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/template"
+
+	"github.com/go-rod/rod"
+)
+
+// actionComment is the post_engagements action for comments
+const actionComment = "comment"
+
+var (
+	// ErrNoEligiblePost is returned when no recent post falls within the configured age window
+	ErrNoEligiblePost = errors.New("no eligible recent post found")
+
+	// ErrAlreadyCommented is returned when the post already has a comment from us
+	ErrAlreadyCommented = errors.New("already commented on post")
+)
+
+type Service struct {
+	browser   *browser.Context
+	store     *storage.Storage
+	cfg       *config.Config
+	stealth   *stealth.Stealth
+	templates *template.Engine
+}
+
+func New(browser *browser.Context, store *storage.Storage, cfg *config.Config) *Service {
+	return &Service{
+		browser:   browser,
+		store:     store,
+		cfg:       cfg,
+		stealth:   browser.NewStealth("engage"),
+		templates: template.New(cfg),
+	}
+}
+
+// CommentOnProfiles comments on a recent post from each profile, up to
+// MaxCommentsPerRun, to warm them up before a connection request
+func (s *Service) CommentOnProfiles(ctx context.Context, profiles []*storage.Profile) (int, error) {
+	log := logger.FromContext(ctx)
+
+	if !s.cfg.Engagement.Enabled || len(s.cfg.Engagement.CommentTemplates) == 0 {
+		return 0, nil
+	}
+
+	commented := 0
+	for _, profile := range profiles {
+		select {
+		case <-ctx.Done():
+			return commented, ctx.Err()
+		default:
+		}
+
+		if limit := s.cfg.Engagement.MaxCommentsPerRun; limit > 0 && commented >= limit {
+			break
+		}
+
+		// Don't comment on profiles we've already reached out to
+		if sent, err := s.store.IsConnectionSent(profile.ProfileURL); err != nil || sent {
+			continue
+		}
+
+		comment, err := s.generateComment(profile)
+		if err != nil {
+			log.Warnf("Invalid comment for %s: %v", profile.ProfileURL, err)
+			continue
+		}
+
+		if err := s.browser.Actions().Begin(); err != nil {
+			log.Info("Shutting down, stopping comments")
+			break
+		}
+		err = s.CommentOnRecentPost(ctx, profile, comment)
+		s.browser.Actions().End()
+		switch {
+		case errors.Is(err, ErrNoEligiblePost), errors.Is(err, ErrAlreadyCommented):
+			log.Debugf("Skipping comment for %s: %v", profile.ProfileURL, err)
+			continue
+		case err != nil:
+			log.Errorf("Failed to comment for %s: %v", profile.ProfileURL, err)
+			s.store.LogActivityAsync("comment", profile.ProfileURL, "failed", err.Error())
+			continue
+		}
+
+		commented++
+		s.stealth.RandomDelay("think")
+	}
+
+	log.Infof("Commented on %d posts", commented)
+	return commented, nil
+}
+
+// CommentOnRecentPost opens the profile's recent activity, picks the most
+// recent post within the configured age window and comments on it
+func (s *Service) CommentOnRecentPost(ctx context.Context, profile *storage.Profile, comment string) error {
+	log := logger.FromContext(ctx)
+
+	activityURL := strings.TrimSuffix(profile.ProfileURL, "/") + "/recent-activity/all/"
+	if err := s.browser.Navigate(activityURL); err != nil {
+		return fmt.Errorf("failed to navigate to activity: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	st := s.stealth
+
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	post, postURL, err := s.findEligiblePost(page)
+	if err != nil {
+		return err
+	}
+
+	engaged, err := s.store.HasEngagedPost(postURL, actionComment)
+	if err != nil {
+		return fmt.Errorf("failed to check post engagement: %w", err)
+	}
+	if engaged || s.hasOwnComment(page, post) {
+		return ErrAlreadyCommented
+	}
+
+	if s.cfg.DryRun {
+		log.Infof("[dry-run] Would comment on %s", postURL)
+		return nil
+	}
+
+	commentButton, err := browser.FindFirst(post, "PostCommentButton", s.cfg.Selectors.PostCommentButton, 0)
+	if err != nil {
+		return fmt.Errorf("comment button not found: %w", err)
+	}
+
+	if err := st.HumanClick(ctx, commentButton); err != nil {
+		return fmt.Errorf("failed to click comment: %w", err)
+	}
+
+	st.RandomDelay("action")
+
+	commentBox, err := post.Timeout(5 * time.Second).Element(s.cfg.Selectors.CommentBox)
+	if err != nil {
+		return fmt.Errorf("comment box not found (selector CommentBox): %w", err)
+	}
+	commentBox = commentBox.CancelTimeout()
+
+	if err := st.HumanClick(ctx, commentBox); err != nil {
+		return fmt.Errorf("failed to focus comment box: %w", err)
+	}
+
+	if err := st.HumanType(ctx, commentBox, comment); err != nil {
+		return fmt.Errorf("failed to type comment: %w", err)
+	}
+
+	st.RandomDelay("think")
+
+	submitButton, err := post.Element(s.cfg.Selectors.CommentSubmitButton)
+	if err != nil {
+		return fmt.Errorf("comment submit button not found (selector CommentSubmitButton): %w", err)
+	}
+
+	if err := st.HumanClick(ctx, submitButton); err != nil {
+		return fmt.Errorf("failed to submit comment: %w", err)
+	}
+
+	// Wait for the comment to post
+	time.Sleep(2 * time.Second)
+
+	engagement := &storage.PostEngagement{
+		ProfileID:  profile.ID,
+		ProfileURL: profile.ProfileURL,
+		PostURL:    postURL,
+		Action:     actionComment,
+		Content:    comment,
+	}
+	if err := s.store.SavePostEngagement(engagement); err != nil {
+		return fmt.Errorf("failed to save comment: %w", err)
+	}
+
+	s.store.LogActivityAsync("comment", postURL, "success", "")
+	log.Infof("Commented on post %s", postURL)
+
+	return nil
+}
+
+// findEligiblePost returns the most recent post between MinPostAgeDays and
+// MaxPostAgeDays old, along with its URL
+func (s *Service) findEligiblePost(page *rod.Page) (*rod.Element, string, error) {
+	posts, err := page.Elements(s.cfg.Selectors.ActivityPost)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find posts (selector ActivityPost): %w", err)
+	}
+
+	minAge := time.Duration(s.cfg.Engagement.MinPostAgeDays) * 24 * time.Hour
+	maxAge := time.Duration(s.cfg.Engagement.MaxPostAgeDays) * 24 * time.Hour
+
+	// Activity is listed newest first, so the first match is the most recent
+	for _, post := range posts {
+		timestamp, err := post.Element(s.cfg.Selectors.PostTimestamp)
+		if err != nil {
+			continue
+		}
+
+		text, _ := timestamp.Text()
+		age, ok := ParsePostAge(text)
+		if !ok || age < minAge || (maxAge > 0 && age > maxAge) {
+			continue
+		}
+
+		urn, err := post.Attribute("data-urn")
+		if err != nil || urn == nil || *urn == "" {
+			continue
+		}
+
+		return post, "https://www.linkedin.com/feed/update/" + *urn + "/", nil
+	}
+
+	return nil, "", ErrNoEligiblePost
+}
+
+// hasOwnComment looks for our own profile picture in the post's comment thread
+func (s *Service) hasOwnComment(page *rod.Page, post *rod.Element) bool {
+	photo, err := page.Element(s.cfg.Selectors.OwnProfilePhoto)
+	if err != nil {
+		return false
+	}
+	ownSrc, err := photo.Attribute("src")
+	if err != nil || ownSrc == nil || *ownSrc == "" {
+		return false
+	}
+
+	avatars, err := post.Elements(s.cfg.Selectors.CommentAvatar)
+	if err != nil {
+		return false
+	}
+
+	for _, avatar := range avatars {
+		if src, err := avatar.Attribute("src"); err == nil && src != nil && *src == *ownSrc {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generateComment renders a random comment template for a profile
+func (s *Service) generateComment(profile *storage.Profile) (string, error) {
+	tmpl := s.cfg.Engagement.CommentTemplates[rand.Intn(len(s.cfg.Engagement.CommentTemplates))]
+
+	firstName := "there"
+	if parts := strings.Fields(profile.Name); len(parts) > 0 {
+		firstName = parts[0]
+	}
+
+	comment := strings.ReplaceAll(tmpl, "{{FirstName}}", firstName)
+	comment = strings.ReplaceAll(comment, "{{Company}}", profile.Company)
+	comment = strings.ReplaceAll(comment, "{{Headline}}", profile.Headline)
+
+	return s.templates.ValidateMessageLength(comment, template.TypeComment)
+}
+
+// postAgePattern matches LinkedIn's relative post timestamps such as "3d", "2w" or "1mo"
+var postAgePattern = regexp.MustCompile(`(\d+)\s*(mo|yr|min|m|h|d|w|y)\b`)
+
+// ParsePostAge converts a relative post timestamp into an age
+func ParsePostAge(text string) (time.Duration, bool) {
+	match := postAgePattern.FindStringSubmatch(strings.ToLower(text))
+	if match == nil {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+
+	day := 24 * time.Hour
+	switch match[2] {
+	case "m", "min":
+		return time.Duration(n) * time.Minute, true
+	case "h":
+		return time.Duration(n) * time.Hour, true
+	case "d":
+		return time.Duration(n) * day, true
+	case "w":
+		return time.Duration(n) * 7 * day, true
+	case "mo":
+		return time.Duration(n) * 30 * day, true
+	case "yr", "y":
+		return time.Duration(n) * 365 * day, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,159 @@
+package engage
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/template"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// newFixturePage opens a blank page in a headless browser, skipping the test
+// when none is installed
+func newFixturePage(t *testing.T) *rod.Page {
+	t.Helper()
+
+	bin := os.Getenv("CHROME_PATH")
+	if bin == "" {
+		path, found := launcher.LookPath()
+		if !found {
+			t.Skip("no browser installed")
+		}
+		bin = path
+	}
+
+	u, err := launcher.New().Bin(bin).Headless(true).Launch()
+	if err != nil {
+		t.Fatalf("launch browser: %v", err)
+	}
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("connect to browser: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("open page: %v", err)
+	}
+	return page
+}
+
+// activityFixture is a recent activity page with posts newest first. The
+// 3 day old post already has a comment from the signed in member.
+const activityFixture = `
+<img class="global-nav__me-photo" src="https://media.licdn.com/me.jpg">
+<div class="feed-shared-update-v2" data-urn="urn:li:activity:1">
+	<span class="update-components-actor__sub-description">2h • Edited</span>
+</div>
+<div class="feed-shared-update-v2" data-urn="urn:li:activity:2">
+	<span class="update-components-actor__sub-description">3d • </span>
+	<div class="comments-comment-item"><img src="https://media.licdn.com/other.jpg"></div>
+	<div class="comments-comment-item"><img src="%s"></div>
+</div>
+<div class="feed-shared-update-v2" data-urn="urn:li:activity:3">
+	<span class="update-components-actor__sub-description">3w • </span>
+</div>
+`
+
+func TestFindEligiblePostOnActivityPage(t *testing.T) {
+	cfg := &config.Config{Selectors: config.DefaultSelectors()}
+	cfg.Engagement.MinPostAgeDays = 1
+	cfg.Engagement.MaxPostAgeDays = 14
+	s := &Service{cfg: cfg}
+	page := newFixturePage(t)
+
+	tests := []struct {
+		name          string
+		ownAvatar     string
+		wantCommented bool
+	}{
+		{"already commented", "https://media.licdn.com/me.jpg", true},
+		{"not commented", "https://media.licdn.com/someone-else.jpg", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := page.SetDocumentContent(strings.Replace(activityFixture, "%s", tt.ownAvatar, 1)); err != nil {
+				t.Fatalf("set content: %v", err)
+			}
+
+			post, postURL, err := s.findEligiblePost(page)
+			if err != nil {
+				t.Fatalf("findEligiblePost: %v", err)
+			}
+			// The 2h post is too new and the 3w post too old
+			if postURL != "https://www.linkedin.com/feed/update/urn:li:activity:2/" {
+				t.Errorf("post URL = %s, want the 3 day old post", postURL)
+			}
+			if got := s.hasOwnComment(page, post); got != tt.wantCommented {
+				t.Errorf("hasOwnComment = %v, want %v", got, tt.wantCommented)
+			}
+		})
+	}
+
+	cfg.Engagement.MaxPostAgeDays = 2
+	if _, _, err := s.findEligiblePost(page); !errors.Is(err, ErrNoEligiblePost) {
+		t.Errorf("findEligiblePost with no post in the window = %v, want ErrNoEligiblePost", err)
+	}
+}
+
+func TestParsePostAge(t *testing.T) {
+	day := 24 * time.Hour
+
+	tests := []struct {
+		text string
+		want time.Duration
+		ok   bool
+	}{
+		{"45m", 45 * time.Minute, true},
+		{"5min", 5 * time.Minute, true},
+		{"2h • Edited", 2 * time.Hour, true},
+		{"3d • ", 3 * day, true},
+		{"2w", 14 * day, true},
+		{"1mo", 30 * day, true},
+		{"1yr", 365 * day, true},
+		{"2Y", 730 * day, true},
+		{"Promoted", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParsePostAge(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParsePostAge(%q) = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGenerateComment(t *testing.T) {
+	cfg := &config.Config{}
+	s := &Service{cfg: cfg, templates: template.New(cfg)}
+	profile := &storage.Profile{Name: "Jane Doe", Company: "Acme", Headline: "VP Sales at Acme"}
+
+	cfg.Engagement.CommentTemplates = []string{"Great point, {{FirstName}}! Curious how {{Company}} handles this."}
+	got, err := s.generateComment(profile)
+	if err != nil || got != "Great point, Jane! Curious how Acme handles this." {
+		t.Errorf("generateComment = %q, %v", got, err)
+	}
+
+	cfg.Engagement.CommentTemplates = []string{"Congrats {{FirstName}} on {{.Vars.trigger_event}}"}
+	if _, err := s.generateComment(profile); !errors.Is(err, template.ErrUnresolvedPlaceholder) {
+		t.Errorf("unresolved placeholder = %v, want ErrUnresolvedPlaceholder", err)
+	}
+
+	// Comments are never truncated, even with truncation turned on for messages
+	cfg.Messaging.TruncateOnOverflow = true
+	cfg.Engagement.CommentTemplates = []string{strings.Repeat("Nice. ", template.MaxCommentLength/5)}
+	if _, err := s.generateComment(profile); !errors.Is(err, template.ErrContentTooLong) {
+		t.Errorf("overlong comment = %v, want ErrContentTooLong", err)
+	}
+}
@@ -0,0 +1,169 @@
+package engage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+)
+
+// defaultMaxSkillEndorsements is used when MaxSkillEndorsements is unset
+const defaultMaxSkillEndorsements = 3
+
+// EndorseProfiles endorses skills of each profile we haven't contacted yet
+func (s *Service) EndorseProfiles(ctx context.Context, profiles []*storage.Profile) (int, error) {
+	log := logger.FromContext(ctx)
+
+	if !s.cfg.Engagement.SkillEndorsementEnabled {
+		return 0, nil
+	}
+
+	maxSkills := s.cfg.Engagement.MaxSkillEndorsements
+	if maxSkills <= 0 {
+		maxSkills = defaultMaxSkillEndorsements
+	}
+
+	total := 0
+	for _, profile := range profiles {
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		default:
+		}
+
+		if sent, err := s.store.IsConnectionSent(profile.ProfileURL); err != nil || sent {
+			continue
+		}
+
+		if err := s.browser.Actions().Begin(); err != nil {
+			log.Info("Shutting down, stopping endorsements")
+			break
+		}
+		endorsed, err := s.EndorseSkills(ctx, profile, maxSkills)
+		s.browser.Actions().End()
+		if err != nil {
+			log.Errorf("Failed to endorse skills for %s: %v", profile.ProfileURL, err)
+			s.store.LogActivityAsync("endorse", profile.ProfileURL, "failed", err.Error())
+			continue
+		}
+
+		total += endorsed
+		if endorsed > 0 {
+			s.stealth.RandomDelay("think")
+		}
+	}
+
+	log.Infof("Endorsed %d skills", total)
+	return total, nil
+}
+
+// EndorseSkills opens a profile's skills page and endorses up to maxSkills
+// skills that aren't endorsed yet, returning how many were endorsed
+func (s *Service) EndorseSkills(ctx context.Context, profile *storage.Profile, maxSkills int) (int, error) {
+	log := logger.FromContext(ctx)
+
+	skillsURL := strings.TrimSuffix(profile.ProfileURL, "/") + "/details/skills/"
+	if err := s.browser.Navigate(skillsURL); err != nil {
+		return 0, fmt.Errorf("failed to navigate to skills: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	return s.endorseOnPage(ctx, page, profile, maxSkills)
+}
+
+// endorseOnPage endorses up to maxSkills not yet endorsed skills listed on
+// an open skills page
+func (s *Service) endorseOnPage(ctx context.Context, page *rod.Page, profile *storage.Profile, maxSkills int) (int, error) {
+	log := logger.FromContext(ctx)
+
+	items, err := page.Elements(s.cfg.Selectors.SkillItem)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find skills (selector SkillItem): %w", err)
+	}
+
+	alreadyEndorsed, err := s.store.GetEndorsedSkills(profile.ProfileURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load endorsed skills: %w", err)
+	}
+
+	endorsed := 0
+	for _, item := range items {
+		if endorsed >= maxSkills {
+			break
+		}
+
+		skill := firstText(item, s.cfg.Selectors.SkillName)
+		if skill == "" || alreadyEndorsed[skill] {
+			continue
+		}
+
+		button, err := browser.FindFirst(item, "SkillEndorseButton", s.cfg.Selectors.SkillEndorseButton, 0)
+		if err != nil {
+			continue
+		}
+
+		// Endorsed skills keep the button but show "Endorsed" / aria-pressed
+		if isEndorsed(button) {
+			if err := s.store.SaveSkillEndorsement(profile.ID, profile.ProfileURL, skill); err != nil {
+				log.Warnf("Failed to record existing endorsement of %s: %v", skill, err)
+			}
+			continue
+		}
+
+		if s.cfg.DryRun {
+			log.Infof("[dry-run] Would endorse %s for %s", skill, profile.ProfileURL)
+			endorsed++
+			continue
+		}
+
+		if err := button.ScrollIntoView(); err != nil {
+			log.Debugf("Failed to scroll to %s: %v", skill, err)
+		}
+
+		if err := s.stealth.HumanClick(ctx, button); err != nil {
+			return endorsed, fmt.Errorf("failed to endorse %s: %w", skill, err)
+		}
+
+		if err := s.store.SaveSkillEndorsement(profile.ID, profile.ProfileURL, skill); err != nil {
+			return endorsed, fmt.Errorf("failed to save endorsement: %w", err)
+		}
+
+		endorsed++
+		s.store.LogActivityAsync("endorse", profile.ProfileURL, "success", skill)
+		log.Infof("Endorsed %s for %s", skill, profile.Name)
+
+		s.stealth.RandomDelay("action")
+	}
+
+	return endorsed, nil
+}
+
+// isEndorsed reports whether an endorsement button is in its endorsed state
+func isEndorsed(button *rod.Element) bool {
+	if pressed, err := button.Attribute("aria-pressed"); err == nil && pressed != nil && *pressed == "true" {
+		return true
+	}
+
+	text, _ := button.Text()
+	return strings.HasPrefix(strings.TrimSpace(text), "Endorsed")
+}
+
+// firstText returns the trimmed text of the first child matching selector
+func firstText(parent *rod.Element, selector string) string {
+	has, element, err := parent.Has(selector)
+	if err != nil || !has {
+		return ""
+	}
+
+	text, _ := element.Text()
+	return strings.TrimSpace(text)
+}
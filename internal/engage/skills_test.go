@@ -0,0 +1,120 @@
+package engage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/stealth"
+	"linkedin-automation/internal/storage"
+)
+
+// skillsFixture is a skills page listing the skill items passed to Sprintf
+const skillsFixture = `<html><body>
+<ul class="pvs-list">
+%s
+</ul>
+</body></html>`
+
+// skillItem is a skill whose endorse button is pressed when clicked, or
+// already pressed when the signed in member endorsed it
+func skillItem(name string, endorsed bool) string {
+	button := `<button class="artdeco-button--secondary" aria-label="Endorse ` + name + `" aria-pressed="false"
+		onclick="this.setAttribute('aria-pressed', 'true'); this.textContent = 'Endorsed'">Endorse</button>`
+	if endorsed {
+		button = `<button class="artdeco-button--secondary" aria-label="Endorse ` + name + `" aria-pressed="true">Endorsed</button>`
+	}
+	return `<li class="pvs-list__paged-list-item">
+	<div class="mr1 t-bold"><span aria-hidden="true">` + name + `</span></div>
+	` + button + `
+</li>`
+}
+
+func TestEndorseSkillsOnFixturePage(t *testing.T) {
+	page := newFixturePage(t)
+	items := skillItem("Go", true) + skillItem("Kubernetes", false) + skillItem("SQL", false) +
+		skillItem("Python", false) + skillItem("Rust", false)
+	if err := page.SetDocumentContent(fmt.Sprintf(skillsFixture, items)); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	profile := &storage.Profile{ProfileURL: "https://www.linkedin.com/in/jane", Name: "Jane"}
+	if profile.ID, err = store.SaveProfile(profile); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	// Endorsed on an earlier run
+	if err := store.SaveSkillEndorsement(profile.ID, profile.ProfileURL, "SQL"); err != nil {
+		t.Fatalf("SaveSkillEndorsement: %v", err)
+	}
+
+	cfg := &config.Config{Selectors: config.DefaultSelectors()}
+	s := &Service{cfg: cfg, store: store, stealth: stealth.New(cfg, "test")}
+
+	endorsed, err := s.endorseOnPage(context.Background(), page, profile, 2)
+	if err != nil {
+		t.Fatalf("endorseOnPage: %v", err)
+	}
+	if endorsed != 2 {
+		t.Errorf("endorsed %d skills, want 2", endorsed)
+	}
+
+	pressed := map[string]string{}
+	for _, button := range page.MustElements("button") {
+		pressed[*button.MustAttribute("aria-label")] = *button.MustAttribute("aria-pressed")
+	}
+	want := map[string]string{
+		"Endorse Go":         "true",
+		"Endorse Kubernetes": "true",
+		"Endorse SQL":        "false",
+		"Endorse Python":     "true",
+		"Endorse Rust":       "false",
+	}
+	if !reflect.DeepEqual(pressed, want) {
+		t.Errorf("endorsement buttons = %v, want %v", pressed, want)
+	}
+
+	// Go was already endorsed on LinkedIn, so it's recorded without a click
+	skills, err := store.GetEndorsedSkills(profile.ProfileURL)
+	if err != nil {
+		t.Fatalf("GetEndorsedSkills: %v", err)
+	}
+	if want := map[string]bool{"Go": true, "Kubernetes": true, "SQL": true, "Python": true}; !reflect.DeepEqual(skills, want) {
+		t.Errorf("endorsed skills = %v, want %v", skills, want)
+	}
+}
+
+func TestEndorseSkillsDryRun(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(fmt.Sprintf(skillsFixture, skillItem("Go", false)+skillItem("Rust", false))); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{Selectors: config.DefaultSelectors(), DryRun: true}
+	s := &Service{cfg: cfg, store: store, stealth: stealth.New(cfg, "test")}
+	profile := &storage.Profile{ProfileURL: "https://www.linkedin.com/in/jane"}
+
+	if endorsed, err := s.endorseOnPage(context.Background(), page, profile, 5); err != nil || endorsed != 2 {
+		t.Errorf("endorseOnPage = %d, %v, want 2 would-be endorsements", endorsed, err)
+	}
+	if skills, _ := store.GetEndorsedSkills(profile.ProfileURL); len(skills) != 0 {
+		t.Errorf("dry run recorded endorsements %v", skills)
+	}
+	if n := len(page.MustElements("button[aria-pressed='true']")); n != 0 {
+		t.Errorf("dry run clicked %d endorsement buttons", n)
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -14,17 +15,7 @@ func Init() *logrus.Logger {
 	log = logrus.New()
 
 	// Set log level
-	level := os.Getenv("LOG_LEVEL")
-	switch level {
-	case "debug":
-		log.SetLevel(logrus.DebugLevel)
-	case "warn":
-		log.SetLevel(logrus.WarnLevel)
-	case "error":
-		log.SetLevel(logrus.ErrorLevel)
-	default:
-		log.SetLevel(logrus.InfoLevel)
-	}
+	log.SetLevel(parseLevel(os.Getenv("LOG_LEVEL")))
 
 	// Set formatter
 	log.SetFormatter(&logrus.TextFormatter{
@@ -49,6 +40,25 @@ func Init() *logrus.Logger {
 	return log
 }
 
+// SetLevel changes the level of the global logger
+func SetLevel(level string) {
+	Get().SetLevel(parseLevel(level))
+}
+
+// parseLevel maps a configured level name to a logrus level, defaulting to info
+func parseLevel(level string) logrus.Level {
+	switch level {
+	case "debug":
+		return logrus.DebugLevel
+	case "warn":
+		return logrus.WarnLevel
+	case "error":
+		return logrus.ErrorLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
+
 // Get returns the global logger instance
 func Get() *logrus.Logger {
 	if log == nil {
@@ -56,3 +66,45 @@ func Get() *logrus.Logger {
 	}
 	return log
 }
+
+type contextKey string
+
+const (
+	runIDKey contextKey = "run_id"
+	phaseKey contextKey = "phase"
+)
+
+// WithRunID returns a copy of ctx carrying the given workflow run ID
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey, runID)
+}
+
+// WithPhase returns a copy of ctx carrying the current workflow phase
+func WithPhase(ctx context.Context, phase string) context.Context {
+	return context.WithValue(ctx, phaseKey, phase)
+}
+
+// RunIDFromContext returns the workflow run ID stored in ctx, or ""
+func RunIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	runID, _ := ctx.Value(runIDKey).(string)
+	return runID
+}
+
+// FromContext returns a log entry tagged with the run ID and phase stored in ctx
+func FromContext(ctx context.Context) *logrus.Entry {
+	fields := logrus.Fields{}
+
+	if ctx != nil {
+		if runID, ok := ctx.Value(runIDKey).(string); ok && runID != "" {
+			fields["run_id"] = runID
+		}
+		if phase, ok := ctx.Value(phaseKey).(string); ok && phase != "" {
+			fields["phase"] = phase
+		}
+	}
+
+	return Get().WithFields(fields)
+}
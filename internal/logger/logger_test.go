@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestMain(m *testing.M) {
+	// Keep the global logger's file out of the source tree
+	dir, err := os.MkdirTemp("", "logger-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("LOG_FILE", filepath.Join(dir, "automation.log"))
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// captureEntries records entries logged to the global logger for the rest
+// of the test
+func captureEntries(t *testing.T) *test.Hook {
+	t.Helper()
+
+	logger := Get()
+	level := logger.GetLevel()
+	hooks := logger.ReplaceHooks(make(logrus.LevelHooks))
+	hook := test.NewLocal(logger)
+	logger.SetLevel(logrus.DebugLevel)
+
+	t.Cleanup(func() {
+		logger.ReplaceHooks(hooks)
+		logger.SetLevel(level)
+	})
+	return hook
+}
+
+func TestRunIDOnEveryEntryOfARun(t *testing.T) {
+	hook := captureEntries(t)
+
+	runCtx := WithRunID(context.Background(), "run-1")
+	FromContext(runCtx).Info("Starting workflow")
+	FromContext(WithPhase(runCtx, "search")).Debug("Searching")
+	FromContext(WithPhase(WithPhase(runCtx, "search"), "connect")).Warn("Rate limited")
+	FromContext(runCtx).WithField("profile", "jane").Error("Send failed")
+
+	entries := hook.AllEntries()
+	if len(entries) != 4 {
+		t.Fatalf("captured %d entries, want 4", len(entries))
+	}
+	for _, entry := range entries {
+		if got := entry.Data["run_id"]; got != "run-1" {
+			t.Errorf("entry %q run_id = %v, want run-1", entry.Message, got)
+		}
+	}
+	if got := entries[2].Data["phase"]; got != "connect" {
+		t.Errorf("phase = %v, want the innermost phase connect", got)
+	}
+
+	hook.Reset()
+	nextRun := WithRunID(runCtx, "run-2")
+	FromContext(nextRun).Info("Next run")
+	FromContext(context.Background()).Info("Outside a run")
+
+	entries = hook.AllEntries()
+	if got := entries[0].Data["run_id"]; got != "run-2" {
+		t.Errorf("run_id after a new run = %v, want run-2", got)
+	}
+	if _, ok := entries[1].Data["run_id"]; ok {
+		t.Errorf("entry outside a run has run_id %v", entries[1].Data["run_id"])
+	}
+}
+
+func TestRunIDFromContext(t *testing.T) {
+	if got := RunIDFromContext(WithPhase(WithRunID(context.Background(), "run-1"), "message")); got != "run-1" {
+		t.Errorf("RunIDFromContext = %q, want run-1", got)
+	}
+	if got := RunIDFromContext(context.Background()); got != "" {
+		t.Errorf("RunIDFromContext without a run = %q, want empty", got)
+	}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// redactedText replaces sensitive values in log output
+const redactedText = "[REDACTED]"
+
+var (
+	emailPattern       = regexp.MustCompile(`\S+@\S+\.\S+`)
+	profileSlugPattern = regexp.MustCompile(`(linkedin\.com/in/)[^/\s?#"]+`)
+
+	// credentialPattern matches password and session cookie values written
+	// as password=..., "password": "..." or li_at=...
+	credentialPattern = regexp.MustCompile(`(?i)((?:password|li_at|jsessionid)["']?\s*[:=]\s*["']?)[^\s"';,&]+`)
+)
+
+// credentialFields are field names whose values are always masked
+var credentialFields = map[string]bool{
+	"password":   true,
+	"li_at":      true,
+	"jsessionid": true,
+	"cookie":     true,
+	"cookies":    true,
+}
+
+// RedactionHook masks email addresses, LinkedIn profile slugs, passwords,
+// session cookies, known secrets and any extra patterns in log messages and
+// fields before they are written
+type RedactionHook struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactionHook compiles the extra patterns into a hook. secrets, such as
+// the configured LinkedIn password, are masked wherever they appear.
+func NewRedactionHook(extraPatterns []string, secrets ...string) (*RedactionHook, error) {
+	hook := &RedactionHook{}
+
+	for _, secret := range secrets {
+		if secret != "" {
+			hook.patterns = append(hook.patterns, regexp.MustCompile(regexp.QuoteMeta(secret)))
+		}
+	}
+
+	for _, pattern := range extraPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		hook.patterns = append(hook.patterns, re)
+	}
+
+	return hook, nil
+}
+
+// EnableRedaction adds a RedactionHook to the global logger. Call it after
+// any other hooks are registered so they see unredacted entries first.
+func EnableRedaction(extraPatterns []string, secrets ...string) error {
+	hook, err := NewRedactionHook(extraPatterns, secrets...)
+	if err != nil {
+		return err
+	}
+
+	Get().AddHook(hook)
+	return nil
+}
+
+// Levels applies the hook to every level
+func (h *RedactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts the entry's message and string-like fields in place
+func (h *RedactionHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.Redact(entry.Message)
+
+	for key, value := range entry.Data {
+		if credentialFields[strings.ToLower(key)] {
+			entry.Data[key] = redactedText
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = h.Redact(v)
+		case error:
+			entry.Data[key] = h.Redact(v.Error())
+		case fmt.Stringer:
+			entry.Data[key] = h.Redact(v.String())
+		}
+	}
+
+	return nil
+}
+
+// Redact returns text with all sensitive matches replaced
+func (h *RedactionHook) Redact(text string) string {
+	text = credentialPattern.ReplaceAllString(text, "${1}"+redactedText)
+	text = emailPattern.ReplaceAllString(text, redactedText)
+	text = profileSlugPattern.ReplaceAllString(text, "${1}"+redactedText)
+
+	for _, re := range h.patterns {
+		text = re.ReplaceAllString(text, redactedText)
+	}
+
+	return text
+}
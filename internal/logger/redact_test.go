@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// captureRedacted records entries after they pass through a RedactionHook,
+// the same order EnableRedaction relies on for the file output
+func captureRedacted(t *testing.T, extraPatterns []string, secrets ...string) *test.Hook {
+	t.Helper()
+
+	redaction, err := NewRedactionHook(extraPatterns, secrets...)
+	if err != nil {
+		t.Fatalf("NewRedactionHook: %v", err)
+	}
+
+	logger := Get()
+	level := logger.GetLevel()
+	hooks := logger.ReplaceHooks(make(logrus.LevelHooks))
+	logger.AddHook(redaction)
+	hook := test.NewLocal(logger)
+	logger.SetLevel(logrus.DebugLevel)
+
+	t.Cleanup(func() {
+		logger.ReplaceHooks(hooks)
+		logger.SetLevel(level)
+	})
+	return hook
+}
+
+func TestRedactionMasksCredentials(t *testing.T) {
+	hook := captureRedacted(t, []string{`ACME-\d+`}, "hunter2!")
+
+	Get().WithFields(logrus.Fields{
+		"email":    "jane.doe@example.com",
+		"password": "s3cret",
+		"li_at":    "AQEDAR-cookie-value",
+		"detail":   "login as jane.doe@example.com with password=s3cret",
+		"error":    errors.New(`set cookie li_at="AQEDAR-cookie-value"; Path=/`),
+		"ticket":   "ACME-42",
+		"status":   "ok",
+	}).Info(`Logging in: {"email": "jane.doe@example.com", "password": "hunter2!"} li_at=AQEDAR-cookie-value JSESSIONID: ajax:123`)
+
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("no entry captured")
+	}
+
+	secrets := []string{"jane.doe@example.com", "s3cret", "hunter2!", "AQEDAR-cookie-value", "ajax:123", "ACME-42"}
+	for _, secret := range secrets {
+		if strings.Contains(entry.Message, secret) {
+			t.Errorf("message leaks %q: %s", secret, entry.Message)
+		}
+		for key, value := range entry.Data {
+			if strings.Contains(value.(string), secret) {
+				t.Errorf("field %s leaks %q: %s", key, secret, value)
+			}
+		}
+	}
+
+	if got := entry.Data["password"]; got != redactedText {
+		t.Errorf("password field = %v, want %s", got, redactedText)
+	}
+	if got := entry.Data["detail"]; got != "login as [REDACTED] with password=[REDACTED]" {
+		t.Errorf("detail field = %v", got)
+	}
+	if got := entry.Data["status"]; got != "ok" {
+		t.Errorf("status field = %v, want it untouched", got)
+	}
+}
+
+func TestRedactKeepsKeyNames(t *testing.T) {
+	hook, err := NewRedactionHook(nil)
+	if err != nil {
+		t.Fatalf("NewRedactionHook: %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"password=hunter2", "password=[REDACTED]"},
+		{`"Password": "hunter2"`, `"Password": "[REDACTED]"`},
+		{"li_at=AQEDAR&JSESSIONID=ajax", "li_at=[REDACTED]&JSESSIONID=[REDACTED]"},
+		{"https://www.linkedin.com/in/jane-doe/", "https://www.linkedin.com/in/[REDACTED]/"},
+		{"no secrets here", "no secrets here"},
+	}
+
+	for _, tt := range tests {
+		if got := hook.Redact(tt.text); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNewRedactionHookRejectsBadPattern(t *testing.T) {
+	if _, err := NewRedactionHook([]string{"("}); err == nil {
+		t.Error("NewRedactionHook accepted an invalid pattern")
+	}
+}
@@ -0,0 +1,274 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/circuit"
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/stealth"
+	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/template"
+)
+
+// ErrAutoReplyDeferred is returned when an auto reply can't be sent right now
+// because of rate limits, dry-run, an open circuit or shutdown. The rule
+// stays unfired so the reply is picked up again on a later check.
+var ErrAutoReplyDeferred = errors.New("auto reply deferred")
+
+// errNoReply is returned when a conversation has no incoming message yet
+var errNoReply = errors.New("no incoming message found (selector IncomingMessage)")
+
+// replyCheckWindow is how far back CheckReplies looks for unanswered messages
+const replyCheckWindow = 14 * 24 * time.Hour
+
+// replyCheckLimit caps the threads CheckReplies opens per run
+const replyCheckLimit = 10
+
+// Reply is a message received from a connection
+type Reply struct {
+	ProfileURL string
+	Content    string
+	ReceivedAt time.Time
+}
+
+// MatchAutoResponderKeyword returns the first of keywords contained in the
+// reply content (case-insensitive), or "" if none is
+func MatchAutoResponderKeyword(content string, keywords []string) string {
+	content = strings.ToLower(content)
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword != "" && strings.Contains(content, strings.ToLower(keyword)) {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// EvaluateAutoResponder checks a reply against every auto-responder rule and
+// runs the action of each rule that matches and hasn't fired for the profile
+// yet. The returned error wraps ErrAutoReplyDeferred if a reply couldn't be
+// sent yet.
+func (s *Service) EvaluateAutoResponder(ctx context.Context, reply Reply) error {
+	log := logger.FromContext(ctx)
+
+	var errs []error
+	for i, rule := range s.cfg.Messaging.AutoResponderRules {
+		keyword := MatchAutoResponderKeyword(reply.Content, rule.KeywordsMatch)
+		if keyword == "" {
+			continue
+		}
+
+		fired, err := s.store.HasAutoResponderFired(reply.ProfileURL, i)
+		if err != nil {
+			return fmt.Errorf("failed to check auto responder log: %w", err)
+		}
+		if fired {
+			log.Debugf("Auto responder rule %d already fired for %s", i+1, reply.ProfileURL)
+			continue
+		}
+
+		log.Infof("Auto responder rule %d matched %q in reply from %s, action %s", i+1, keyword, reply.ProfileURL, rule.Action)
+
+		if err := s.runAutoResponderAction(ctx, rule, reply, keyword); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d: %w", i+1, err))
+			if !errors.Is(err, ErrAutoReplyDeferred) {
+				s.store.LogActivityAsync("auto_responder", reply.ProfileURL, "failed", err.Error())
+			}
+			continue
+		}
+
+		if err := s.store.LogAutoResponderMatch(reply.ProfileURL, i, rule.Action, keyword); err != nil {
+			return fmt.Errorf("failed to log auto responder match: %w", err)
+		}
+		s.store.LogActivityAsync("auto_responder", reply.ProfileURL, "success", fmt.Sprintf("%s (%s)", rule.Action, keyword))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("auto responder failed: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// runAutoResponderAction executes a matched rule
+func (s *Service) runAutoResponderAction(ctx context.Context, rule config.AutoResponderRule, reply Reply, keyword string) error {
+	switch rule.Action {
+	case config.AutoResponderReply:
+		profile, err := s.store.GetProfileByURL(reply.ProfileURL)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+		if profile == nil {
+			profile = &storage.Profile{ProfileURL: reply.ProfileURL}
+		}
+
+		values := s.templateValues(profile, rule.ReplyTemplate)
+		if missing := missingTopics(rule.ReplyTemplate, values); len(missing) > 0 {
+			return fmt.Errorf("reply template uses %s but no topics are known for %s", strings.Join(missing, ", "), reply.ProfileURL)
+		}
+
+		message, err := s.templates.ValidateMessageLength(fillPlaceholders(rule.ReplyTemplate, values), template.TypeMessage)
+		if err != nil {
+			return fmt.Errorf("invalid reply template: %w", err)
+		}
+		return s.sendAutoReply(ctx, reply.ProfileURL, message)
+
+	case config.AutoResponderNotify:
+		if s.notifier == nil {
+			return fmt.Errorf("no Slack webhook configured for notifications")
+		}
+		text := fmt.Sprintf("Reply from %s mentions %q:\n> %s", reply.ProfileURL, keyword, reply.Content)
+		return s.notifier.Notify(ctx, text)
+
+	case config.AutoResponderTag:
+		if rule.TagName == "" {
+			return fmt.Errorf("tag rule has no tag_name")
+		}
+		return s.store.TagProfile(reply.ProfileURL, rule.TagName)
+
+	default:
+		return fmt.Errorf("unknown action %q", rule.Action)
+	}
+}
+
+// sendAutoReply sends a reply under the same rate limits, dry-run and
+// circuit breaker as regular messages
+func (s *Service) sendAutoReply(ctx context.Context, profileURL, message string) error {
+	log := logger.FromContext(ctx)
+
+	if !s.canSendMessage(ctx) {
+		return fmt.Errorf("%w: message rate limit reached", ErrAutoReplyDeferred)
+	}
+
+	if s.cfg.DryRun {
+		log.Infof("[dry-run] Would send auto reply to %s", profileURL)
+		return fmt.Errorf("%w: dry run", ErrAutoReplyDeferred)
+	}
+
+	if err := s.browser.Actions().Begin(); err != nil {
+		return fmt.Errorf("%w: %v", ErrAutoReplyDeferred, err)
+	}
+	err := s.breaker.Execute(func() error {
+		return s.SendMessageToProfile(ctx, profileURL, message)
+	})
+	s.browser.Actions().End()
+	if errors.Is(err, circuit.ErrCircuitOpen) {
+		return fmt.Errorf("%w: %v", ErrAutoReplyDeferred, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.schedule != nil {
+		s.schedule.UseSlot(stealth.ActionMessage)
+	}
+	return nil
+}
+
+// CheckReplies opens the conversations of recently messaged profiles that
+// haven't replied yet and runs the auto-responder rules against any reply.
+// It returns the number of replies found.
+func (s *Service) CheckReplies(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	if len(s.cfg.Messaging.AutoResponderRules) == 0 {
+		return 0, nil
+	}
+
+	messages, err := s.store.GetMessagesAwaitingReply(time.Now().Add(-replyCheckWindow), replyCheckLimit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get messages awaiting reply: %w", err)
+	}
+
+	replies := 0
+	for i := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+
+		msg := &messages[i]
+		if err := s.respondToReply(ctx, msg); err != nil {
+			if errors.Is(err, errNoReply) {
+				continue
+			}
+			log.Warnf("Failed to check reply from %s: %v", msg.ProfileURL, err)
+			continue
+		}
+		replies++
+
+		s.stealth.RandomDelay("action")
+	}
+
+	log.Infof("Found %d replies in %d conversations", replies, len(messages))
+	return replies, nil
+}
+
+// respondToReply reads the latest reply in the conversation with a profile,
+// runs the auto-responder rules against it and marks the message replied.
+// A deferred auto reply leaves the message unmarked so it's checked again.
+func (s *Service) respondToReply(ctx context.Context, msg *storage.Message) error {
+	log := logger.FromContext(ctx)
+
+	if len(s.cfg.Messaging.AutoResponderRules) > 0 {
+		content, err := s.readLatestReply(ctx, msg)
+		if err != nil {
+			return err
+		}
+
+		reply := Reply{ProfileURL: msg.ProfileURL, Content: content, ReceivedAt: time.Now()}
+		if err := s.EvaluateAutoResponder(ctx, reply); err != nil {
+			if errors.Is(err, ErrAutoReplyDeferred) {
+				log.Infof("Auto reply to %s deferred: %v", msg.ProfileURL, err)
+				return nil
+			}
+			log.Warnf("Auto responder for %s: %v", msg.ProfileURL, err)
+		}
+	}
+
+	if err := s.store.MarkMessageReplied(msg.ProfileURL); err != nil {
+		return fmt.Errorf("failed to mark replied: %w", err)
+	}
+	return nil
+}
+
+// readLatestReply opens the conversation and returns the text of the most
+// recent message from the other person
+func (s *Service) readLatestReply(ctx context.Context, msg *storage.Message) (string, error) {
+	log := logger.FromContext(ctx)
+
+	threadURL := msg.ThreadURL
+	if threadURL == "" {
+		threadURL = s.getMessagingURL(msg.ProfileURL)
+	}
+
+	if err := s.browser.Navigate(threadURL); err != nil {
+		return "", fmt.Errorf("failed to navigate to thread: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	messages, err := page.Elements(s.cfg.Selectors.IncomingMessage)
+	if err != nil {
+		return "", fmt.Errorf("failed to find messages (selector IncomingMessage): %w", err)
+	}
+	if len(messages) == 0 {
+		return "", errNoReply
+	}
+
+	s.stealth.SimulateReading(page)
+
+	text, err := messages[len(messages)-1].Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to read message text: %w", err)
+	}
+
+	return strings.TrimSpace(text), nil
+}
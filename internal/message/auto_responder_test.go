@@ -0,0 +1,69 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/template"
+)
+
+func TestMatchAutoResponderKeyword(t *testing.T) {
+	keywords := []string{" ", "Pricing", "demo"}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"What's your PRICING like?", "Pricing"},
+		{"Happy to see a demo", "demo"},
+		{"Thanks for reaching out", ""},
+	}
+
+	for _, tt := range tests {
+		if got := MatchAutoResponderKeyword(tt.content, keywords); got != tt.want {
+			t.Errorf("MatchAutoResponderKeyword(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateAutoResponderDefersRepliesInDryRun(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{DryRun: true}
+	cfg.RateLimits.Messages.PerDay = 10
+	cfg.RateLimits.Messages.PerHour = 5
+	cfg.Messaging.AutoResponderRules = []config.AutoResponderRule{
+		{KeywordsMatch: []string{"pricing"}, Action: config.AutoResponderReply, ReplyTemplate: "Hi {{FirstName}}, sending it over"},
+		{KeywordsMatch: []string{"pricing"}, Action: config.AutoResponderTag, TagName: "pricing"},
+	}
+	s := &Service{store: store, cfg: cfg, templates: template.New(cfg)}
+
+	const profileURL = "https://www.linkedin.com/in/jane"
+	err = s.EvaluateAutoResponder(context.Background(), Reply{ProfileURL: profileURL, Content: "Send me pricing"})
+	if !errors.Is(err, ErrAutoReplyDeferred) {
+		t.Fatalf("err = %v, want ErrAutoReplyDeferred", err)
+	}
+
+	if fired, err := store.HasAutoResponderFired(profileURL, 0); err != nil || fired {
+		t.Errorf("reply rule fired = %v (err %v), want it left for a later check", fired, err)
+	}
+	if fired, err := store.HasAutoResponderFired(profileURL, 1); err != nil || !fired {
+		t.Errorf("tag rule fired = %v (err %v), want true", fired, err)
+	}
+
+	tags, err := store.GetProfileTags(profileURL)
+	if err != nil {
+		t.Fatalf("GetProfileTags: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "pricing" {
+		t.Errorf("tags = %v, want [pricing]", tags)
+	}
+}
@@ -0,0 +1,30 @@
+package message
+
+import (
+	"math/rand"
+	"time"
+)
+
+// MessageDelay draws how long to wait after a connection is accepted before
+// messaging. The delay is normally distributed around meanHours with a
+// standard deviation of jitterHours, clamped to [0.5, 2] times the mean so
+// messages don't always go out exactly on the hour.
+func MessageDelay(meanHours, jitterHours float64) time.Duration {
+	if meanHours <= 0 {
+		return 0
+	}
+
+	hours := meanHours
+	if jitterHours > 0 {
+		hours += rand.NormFloat64() * jitterHours
+	}
+
+	if min := meanHours * 0.5; hours < min {
+		hours = min
+	}
+	if max := meanHours * 2.0; hours > max {
+		hours = max
+	}
+
+	return time.Duration(hours * float64(time.Hour))
+}
@@ -0,0 +1,52 @@
+package message
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMessageDelayJitterDistribution(t *testing.T) {
+	const (
+		mean   = 24.0
+		jitter = 6.0
+		draws  = 20000
+	)
+
+	var sum, sumSq float64
+	distinct := map[time.Duration]bool{}
+	for i := 0; i < draws; i++ {
+		d := MessageDelay(mean, jitter)
+		hours := d.Hours()
+
+		if hours < mean*0.5 || hours > mean*2.0 {
+			t.Fatalf("delay %.2fh outside [%.1f, %.1f]", hours, mean*0.5, mean*2.0)
+		}
+
+		sum += hours
+		sumSq += hours * hours
+		distinct[d] = true
+	}
+
+	avg := sum / draws
+	stddev := math.Sqrt(sumSq/draws - avg*avg)
+
+	if math.Abs(avg-mean) > 0.5 {
+		t.Errorf("mean delay = %.2fh, want about %.0fh", avg, mean)
+	}
+	if math.Abs(stddev-jitter) > 0.75 {
+		t.Errorf("delay standard deviation = %.2fh, want about %.0fh", stddev, jitter)
+	}
+	if len(distinct) < draws/2 {
+		t.Errorf("only %d distinct delays in %d draws", len(distinct), draws)
+	}
+}
+
+func TestMessageDelayWithoutJitter(t *testing.T) {
+	if got := MessageDelay(24, 0); got != 24*time.Hour {
+		t.Errorf("MessageDelay(24, 0) = %s, want 24h", got)
+	}
+	if got := MessageDelay(0, 5); got != 0 {
+		t.Errorf("MessageDelay(0, 5) = %s, want 0", got)
+	}
+}
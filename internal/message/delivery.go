@@ -0,0 +1,154 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+// maxDeliveryChecksPerRun caps how many threads are reopened in one pass
+const maxDeliveryChecksPerRun = 10
+
+// MessageDeliveryStatus describes what LinkedIn shows under our latest message
+type MessageDeliveryStatus struct {
+	Delivered bool
+	Seen      bool
+	SeenAt    *time.Time
+}
+
+// CheckMessageDelivery opens the conversation with a profile and reads the
+// receipt shown under our most recent message
+func (s *Service) CheckMessageDelivery(ctx context.Context, profileURL string) (MessageDeliveryStatus, error) {
+	log := logger.FromContext(ctx)
+
+	var status MessageDeliveryStatus
+
+	msg, err := s.store.GetLatestSentMessage(profileURL)
+	if err != nil {
+		return status, fmt.Errorf("failed to load sent message: %w", err)
+	}
+	if msg == nil {
+		return status, fmt.Errorf("no sent message for %s", profileURL)
+	}
+
+	threadURL := msg.ThreadURL
+	if threadURL == "" {
+		threadURL = s.getMessagingURL(profileURL)
+	}
+
+	if err := s.browser.Navigate(threadURL); err != nil {
+		return status, fmt.Errorf("failed to navigate to thread: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	has, _, err := page.Has(s.cfg.Selectors.MessageThreadEvent)
+	if err != nil {
+		return status, fmt.Errorf("failed to check message thread: %w", err)
+	}
+	status.Delivered = has
+
+	receipts, err := page.Elements(s.cfg.Selectors.ReadReceiptStatus)
+	if err != nil {
+		return status, fmt.Errorf("failed to find read receipts (selector ReadReceiptStatus): %w", err)
+	}
+
+	// Receipts are only rendered under our own message groups, so the last
+	// one belongs to our most recent message
+	if len(receipts) > 0 {
+		text, _ := receipts[len(receipts)-1].Text()
+		status.Seen, status.SeenAt = ParseReadReceipt(text, time.Now())
+		status.Delivered = true
+	}
+
+	deliveryStatus := storage.DeliveryStatusDelivered
+	if status.Seen {
+		deliveryStatus = storage.DeliveryStatusSeen
+	}
+	if status.Delivered {
+		if err := s.store.UpdateMessageDelivery(msg.ID, deliveryStatus, status.SeenAt); err != nil {
+			return status, fmt.Errorf("failed to save delivery status: %w", err)
+		}
+	}
+
+	log.Debugf("Delivery for %s: delivered=%t seen=%t", profileURL, status.Delivered, status.Seen)
+	return status, nil
+}
+
+// CheckPendingDeliveries checks read receipts for messages sent more than
+// DeliveryCheckDelayMinutes ago that haven't been seen yet
+func (s *Service) CheckPendingDeliveries(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	delay := time.Duration(s.cfg.Messaging.DeliveryCheckDelayMinutes) * time.Minute
+	if delay <= 0 {
+		delay = 30 * time.Minute
+	}
+
+	messages, err := s.store.GetMessagesAwaitingDelivery(delay, maxDeliveryChecksPerRun)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get messages awaiting delivery: %w", err)
+	}
+
+	seen := 0
+	for _, msg := range messages {
+		select {
+		case <-ctx.Done():
+			return seen, ctx.Err()
+		default:
+		}
+
+		status, err := s.CheckMessageDelivery(ctx, msg.ProfileURL)
+		if err := s.store.MarkDeliveryChecked(msg.ID); err != nil {
+			log.Warnf("Failed to record delivery check for %s: %v", msg.ProfileURL, err)
+		}
+		if err != nil {
+			log.Warnf("Failed to check delivery for %s: %v", msg.ProfileURL, err)
+			continue
+		}
+
+		if status.Seen {
+			seen++
+		}
+
+		s.stealth.RandomDelay("action")
+	}
+
+	log.Infof("Checked delivery of %d messages, %d seen", len(messages), seen)
+	return seen, nil
+}
+
+// ParseReadReceipt interprets receipt text such as "Seen", "Seen 3:45 PM" or
+// "Seen Jan 5". When no time is shown, now is used as the seen time.
+func ParseReadReceipt(text string, now time.Time) (bool, *time.Time) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(strings.ToLower(text), "seen") {
+		return false, nil
+	}
+
+	rest := strings.ToUpper(strings.TrimSpace(text[len("seen"):]))
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, "AT "))
+
+	seenAt := now
+	if t, err := time.ParseInLocation("3:04 PM", rest, now.Location()); err == nil {
+		seenAt = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if seenAt.After(now) {
+			seenAt = seenAt.AddDate(0, 0, -1)
+		}
+	} else if t, err := time.ParseInLocation("Jan 2", rest, now.Location()); err == nil {
+		seenAt = time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+		if seenAt.After(now) {
+			seenAt = seenAt.AddDate(-1, 0, 0)
+		}
+	}
+
+	return true, &seenAt
+}
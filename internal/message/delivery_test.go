@@ -0,0 +1,40 @@
+package message
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReadReceipt(t *testing.T) {
+	now := time.Date(2024, 3, 10, 14, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		text     string
+		wantSeen bool
+		want     time.Time
+	}{
+		{"Delivered", false, time.Time{}},
+		{"Seen", true, now},
+		{" Seen at 9:15 AM ", true, time.Date(2024, 3, 10, 9, 15, 0, 0, time.Local)},
+		{"Seen 11:30 PM", true, time.Date(2024, 3, 9, 23, 30, 0, 0, time.Local)},
+		{"Seen Mar 2", true, time.Date(2024, 3, 2, 0, 0, 0, 0, time.Local)},
+		{"Seen Dec 28", true, time.Date(2023, 12, 28, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		seen, seenAt := ParseReadReceipt(tt.text, now)
+		if seen != tt.wantSeen {
+			t.Errorf("ParseReadReceipt(%q) seen = %v, want %v", tt.text, seen, tt.wantSeen)
+			continue
+		}
+		if !seen {
+			if seenAt != nil {
+				t.Errorf("ParseReadReceipt(%q) seenAt = %v, want nil", tt.text, seenAt)
+			}
+			continue
+		}
+		if seenAt == nil || !seenAt.Equal(tt.want) {
+			t.Errorf("ParseReadReceipt(%q) seenAt = %v, want %v", tt.text, seenAt, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+const (
+	// engagementCheckDelay is how long after sending a message we look for
+	// signs that it was read
+	engagementCheckDelay = 24 * time.Hour
+
+	// maxEngagementChecksPerRun caps how many profiles are visited in one pass
+	maxEngagementChecksPerRun = 10
+)
+
+// MessageEngagementTracker estimates whether messages were read by revisiting
+// the recipient's profile a day after sending, since LinkedIn doesn't show
+// read receipts for every conversation
+type MessageEngagementTracker struct {
+	svc   *Service
+	delay time.Duration
+	now   func() time.Time
+}
+
+func newEngagementTracker(svc *Service) *MessageEngagementTracker {
+	return &MessageEngagementTracker{
+		svc:   svc,
+		delay: engagementCheckDelay,
+		now:   time.Now,
+	}
+}
+
+// DueAt returns when a message sent at sentAt should be checked
+func (t *MessageEngagementTracker) DueAt(sentAt time.Time) time.Time {
+	return sentAt.Add(t.delay)
+}
+
+// IsDue reports whether a message sent at sentAt is ready to be checked
+func (t *MessageEngagementTracker) IsDue(sentAt time.Time) bool {
+	return !t.now().Before(t.DueAt(sentAt))
+}
+
+// CheckDue records an engagement estimate for every message sent at least a
+// day ago that hasn't been checked yet
+func (t *MessageEngagementTracker) CheckDue(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	messages, err := t.svc.store.GetMessagesAwaitingEngagementCheck(t.now().Add(-t.delay), maxEngagementChecksPerRun)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get messages awaiting engagement check: %w", err)
+	}
+
+	checked := 0
+	for i := range messages {
+		msg := &messages[i]
+
+		select {
+		case <-ctx.Done():
+			return checked, ctx.Err()
+		default:
+		}
+
+		if !t.IsDue(msg.SentAt) {
+			continue
+		}
+
+		status := t.estimate(ctx, msg)
+		if err := t.svc.store.SaveMessageEngagement(msg.ID, msg.ProfileURL, status); err != nil {
+			log.Warnf("Failed to save engagement for %s: %v", msg.ProfileURL, err)
+			continue
+		}
+
+		checked++
+		log.Debugf("Message engagement for %s: %s", msg.ProfileURL, status)
+	}
+
+	log.Infof("Checked engagement for %d messages", checked)
+	return checked, nil
+}
+
+// estimate works out the engagement status of a message, visiting the
+// recipient's profile unless stored data already answers it
+func (t *MessageEngagementTracker) estimate(ctx context.Context, msg *storage.Message) string {
+	log := logger.FromContext(ctx)
+	s := t.svc
+
+	if msg.RepliedAt != nil {
+		return storage.EngagementReplied
+	}
+
+	if err := s.browser.Navigate(msg.ProfileURL); err != nil {
+		log.Warnf("Failed to open %s for engagement check: %v", msg.ProfileURL, err)
+		return storage.EngagementUnknown
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	s.stealth.SimulateReading(page)
+
+	if has, _, err := page.Has(s.cfg.Selectors.ProfileRepliedIndicator); err == nil && has {
+		if err := s.respondToReply(ctx, msg); err != nil {
+			log.Warnf("Failed to handle reply from %s: %v", msg.ProfileURL, err)
+			if err := s.store.MarkMessageReplied(msg.ProfileURL); err != nil {
+				log.Warnf("Failed to mark %s as replied: %v", msg.ProfileURL, err)
+			}
+		}
+		return storage.EngagementReplied
+	}
+
+	if msg.DeliveryStatus == storage.DeliveryStatusSeen {
+		return storage.EngagementReadLikely
+	}
+
+	// Without the Message button the page didn't render as expected
+	if has, _, err := page.Has(s.cfg.Selectors.ProfileMessageButton); err != nil || !has {
+		return storage.EngagementUnknown
+	}
+
+	return storage.EngagementUnread
+}
+
+// TrackEngagement estimates engagement for messages sent at least a day ago
+func (s *Service) TrackEngagement(ctx context.Context) (int, error) {
+	return s.engagement.CheckDue(ctx)
+}
@@ -0,0 +1,81 @@
+package message
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+func TestEngagementCheckDueAfterADay(t *testing.T) {
+	sentAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	tracker := newEngagementTracker(&Service{})
+
+	if got, want := tracker.DueAt(sentAt), sentAt.Add(24*time.Hour); !got.Equal(want) {
+		t.Errorf("DueAt = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{sentAt, false},
+		{sentAt.Add(23*time.Hour + 59*time.Minute), false},
+		{sentAt.Add(24 * time.Hour), true},
+		{sentAt.Add(72 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		tracker.now = func() time.Time { return tt.now }
+		if got := tracker.IsDue(sentAt); got != tt.want {
+			t.Errorf("IsDue %s after sending = %v, want %v", tt.now.Sub(sentAt), got, tt.want)
+		}
+	}
+}
+
+func TestCheckDueWaitsADayAfterSending(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	profileURL := "https://www.linkedin.com/in/jane"
+	if err := store.SaveMessage(&storage.Message{ProfileURL: profileURL, Content: "Hi Jane", Status: "sent"}); err != nil {
+		t.Fatalf("SaveMessage: %v", err)
+	}
+	// A stored reply answers the check without opening the profile, so the
+	// service needs no browser
+	if err := store.MarkMessageReplied(profileURL); err != nil {
+		t.Fatalf("MarkMessageReplied: %v", err)
+	}
+
+	s := &Service{store: store, cfg: &config.Config{}}
+	tracker := newEngagementTracker(s)
+	sentAt := time.Now()
+
+	tracker.now = func() time.Time { return sentAt.Add(23 * time.Hour) }
+	if checked, err := tracker.CheckDue(context.Background()); err != nil || checked != 0 {
+		t.Fatalf("CheckDue before a day passed = %d, %v, want 0 checked", checked, err)
+	}
+
+	tracker.now = func() time.Time { return sentAt.Add(25 * time.Hour) }
+	if checked, err := tracker.CheckDue(context.Background()); err != nil || checked != 1 {
+		t.Fatalf("CheckDue after a day = %d, %v, want 1 checked", checked, err)
+	}
+
+	// Each message is only checked once
+	if checked, err := tracker.CheckDue(context.Background()); err != nil || checked != 0 {
+		t.Errorf("second CheckDue = %d, %v, want 0 checked", checked, err)
+	}
+
+	stats, err := store.GetEngagementStats(time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetEngagementStats: %v", err)
+	}
+	if stats.Checked != 1 || stats.Replied != 1 || stats.ReadRate != 100 {
+		t.Errorf("engagement stats = %+v, want one replied check and a 100%% read rate", stats)
+	}
+}
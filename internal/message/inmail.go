@@ -0,0 +1,93 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+
+	"github.com/go-rod/rod"
+)
+
+// premiumSettingsURL is the Premium subscription page showing InMail credits
+const premiumSettingsURL = "https://www.linkedin.com/premium/manage/"
+
+// inMailCreditsPattern matches the credit count, e.g. "12" or "1,024 credits"
+var inMailCreditsPattern = regexp.MustCompile(`\d[\d,]*`)
+
+// GetInMailCreditBalance opens the Premium settings page, reads the remaining
+// InMail credits and records them
+func (s *Service) GetInMailCreditBalance(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.browser.Navigate(premiumSettingsURL); err != nil {
+		return 0, fmt.Errorf("failed to navigate to premium settings: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	balance, err := s.readInMailCredits(page, 10*time.Second)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.store.SaveInMailBalance(balance); err != nil {
+		return balance, fmt.Errorf("failed to save InMail balance: %w", err)
+	}
+
+	log.Infof("%d InMail credits remaining", balance)
+	return balance, nil
+}
+
+// readInMailCredits reads the credit count shown on a Premium settings page,
+// waiting up to timeout for it to render
+func (s *Service) readInMailCredits(page *rod.Page, timeout time.Duration) (int, error) {
+	element, err := page.Timeout(timeout).Element(s.cfg.Selectors.InMailCredits)
+	if err != nil {
+		return 0, fmt.Errorf("InMail credits not found (selector InMailCredits): %w", err)
+	}
+
+	text, err := element.Text()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read InMail credits: %w", err)
+	}
+
+	return ParseInMailCredits(text)
+}
+
+// ParseInMailCredits extracts the credit count from text such as "12" or
+// "12 InMail credits available"
+func ParseInMailCredits(text string) (int, error) {
+	match := inMailCreditsPattern.FindString(text)
+	if match == "" {
+		return 0, fmt.Errorf("no InMail credit count in %q", strings.TrimSpace(text))
+	}
+
+	return strconv.Atoi(strings.ReplaceAll(match, ",", ""))
+}
+
+// checkInMailBalance returns the remaining InMail credits, or -1 when they
+// couldn't be read and InMail sending shouldn't be limited
+func (s *Service) checkInMailBalance(ctx context.Context) int {
+	log := logger.FromContext(ctx)
+
+	balance, err := s.GetInMailCreditBalance(ctx)
+	if err != nil {
+		log.Warnf("Failed to check InMail credit balance: %v", err)
+		return -1
+	}
+
+	if balance < s.cfg.Messaging.MinInMailBalance {
+		log.Warnf("Only %d InMail credits left (min_inmail_balance %d), skipping InMail",
+			balance, s.cfg.Messaging.MinInMailBalance)
+	}
+	return balance
+}
@@ -0,0 +1,61 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+)
+
+// premiumSettingsFixture is a trimmed Premium settings page
+const premiumSettingsFixture = `<html><body>
+<section class="premium-manage">
+	<h2>Premium Career</h2>
+	<div class="inmail-credits">
+		<span class="inmail-credits__count">1,024 InMail credits available</span>
+		<span class="inmail-credits__renewal">Renews on Nov 1</span>
+	</div>
+</section>
+</body></html>`
+
+func TestReadInMailCreditsFromFixture(t *testing.T) {
+	page := newFixturePage(t)
+	s := &Service{cfg: &config.Config{Selectors: config.DefaultSelectors()}}
+
+	if err := page.SetDocumentContent(premiumSettingsFixture); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+	if balance, err := s.readInMailCredits(page, time.Second); err != nil || balance != 1024 {
+		t.Errorf("readInMailCredits = %d, %v, want 1024", balance, err)
+	}
+
+	// Without Premium there's no credit count on the page
+	if err := page.SetDocumentContent(`<html><body><h2>Try Premium for free</h2></body></html>`); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+	if _, err := s.readInMailCredits(page, time.Second); err == nil {
+		t.Error("readInMailCredits on a page without credits succeeded, want an error")
+	}
+}
+
+func TestParseInMailCredits(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    int
+		wantErr bool
+	}{
+		{"12", 12, false},
+		{"  0 ", 0, false},
+		{"12 InMail credits available", 12, false},
+		{"1,024 credits", 1024, false},
+		{"No credits", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseInMailCredits(tt.text)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseInMailCredits(%q) = %d, %v, want %d (error %v)", tt.text, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -2,44 +2,99 @@ package message
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
 	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/circuit"
 	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/content"
 	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/notify"
+	"linkedin-automation/internal/stealth"
 	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/template"
 
 	"github.com/go-rod/rod"
-	"github.com/sirupsen/logrus"
 )
 
+// ErrExistingThread is returned when a conversation with the recipient already exists
+var ErrExistingThread = errors.New("existing message thread detected")
+
 type Service struct {
-	browser *browser.Context
-	store   *storage.Storage
-	cfg     *config.Config
-	log     *logrus.Logger
+	browser   *browser.Context
+	store     *storage.Storage
+	cfg       *config.Config
+	stealth   *stealth.Stealth
+	templates *template.Engine
+	breaker   *circuit.Breaker
+	content   *content.Service
+
+	engagement *MessageEngagementTracker
+
+	// notifier alerts the operator for auto-responder notify rules; nil
+	// without a Slack webhook
+	notifier *notify.SlackNotifier
+
+	// schedule spreads messages over each hour; nil without
+	// stealth.activity_smoothing
+	schedule *stealth.ActivitySchedule
 }
 
 func New(browser *browser.Context, store *storage.Storage, cfg *config.Config) *Service {
-	return &Service{
-		browser: browser,
-		store:   store,
-		cfg:     cfg,
-		log:     logger.Get(),
+	s := &Service{
+		browser:   browser,
+		store:     store,
+		cfg:       cfg,
+		stealth:   browser.NewStealth("message"),
+		templates: template.New(cfg),
+		breaker:   newBreaker(cfg),
+		content:   content.New(browser, store, cfg),
+	}
+	s.engagement = newEngagementTracker(s)
+
+	if webhook := cfg.Notify.CAPTCHAWebhookURL; notify.IsSlackWebhook(webhook) {
+		s.notifier = notify.NewSlackNotifier(webhook, cfg.Notify.Slack)
+	}
+
+	if s.stealth.ActivitySmoothing() && !cfg.DryRun {
+		s.schedule = stealth.NewActivitySchedule(map[string]int{
+			stealth.ActionMessage: cfg.RateLimits.Messages.PerHour,
+		})
 	}
+
+	return s
+}
+
+// newBreaker creates the message circuit breaker. Existing threads are
+// skipped on purpose, so they don't count as failures.
+func newBreaker(cfg *config.Config) *circuit.Breaker {
+	settings := circuit.SettingsFromConfig(cfg.RateLimits.CircuitBreaker)
+	settings.IsSuccessful = func(err error) bool {
+		return err == nil || errors.Is(err, ErrExistingThread)
+	}
+	return circuit.New("message", settings)
+}
+
+// BreakerStats reports the state of the messaging circuit breaker
+func (s *Service) BreakerStats() circuit.BreakerSnapshot {
+	return s.breaker.Stats()
 }
 
 // SendMessages sends messages to accepted connections
 func (s *Service) SendMessages(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
 	if !s.cfg.Messaging.Enabled {
-		s.log.Info("Messaging is disabled in config")
+		log.Info("Messaging is disabled in config")
 		return 0, nil
 	}
 
-	s.log.Info("Starting to send messages to accepted connections...")
+	log.Info("Starting to send messages to accepted connections...")
 
 	// Get accepted connections that haven't been messaged
 	connections, err := s.store.GetAcceptedConnections()
@@ -47,113 +102,180 @@ func (s *Service) SendMessages(ctx context.Context) (int, error) {
 		return 0, fmt.Errorf("failed to get accepted connections: %w", err)
 	}
 
+	// Followed profiles can't accept a connection, so reach them via InMail
+	// while enough credits remain
+	inMailCredits := -1
+	if s.cfg.Messaging.InMailFollowed {
+		inMailCredits = s.checkInMailBalance(ctx)
+
+		followed, err := s.store.GetFollowedProfiles()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get followed profiles: %w", err)
+		}
+		connections = append(connections, followed...)
+	}
+
 	if len(connections) == 0 {
-		s.log.Info("No accepted connections to message")
+		log.Info("No accepted connections to message")
 		return 0, nil
 	}
 
-	s.log.Infof("Found %d accepted connections to message", len(connections))
+	log.Infof("Found %d accepted connections to message", len(connections))
 
 	sent := 0
 
 	for _, conn := range connections {
 		select {
 		case <-ctx.Done():
-			s.log.Info("Context cancelled, stopping messaging")
+			log.Info("Context cancelled, stopping messaging")
 			return sent, ctx.Err()
 		default:
 		}
 
-		// Check rate limits
-		if !s.canSendMessage() {
-			s.log.Warn("Rate limit reached for messages")
+		// Respect the delay drawn at acceptance; connections accepted before
+		// delays were stored get theirs drawn now
+		if conn.MessageAfterAt == nil && conn.AcceptedAt != nil {
+			messageAfter := conn.AcceptedAt.Add(MessageDelay(float64(s.cfg.Messaging.DelayAfterConnectionHours), s.cfg.Messaging.DelayJitterHours))
+			if err := s.store.SetMessageAfter(conn.ID, messageAfter); err != nil {
+				log.Warnf("Failed to save message delay for %s: %v", conn.ProfileURL, err)
+			}
+			conn.MessageAfterAt = &messageAfter
+		}
+		if conn.MessageAfterAt != nil && time.Now().Before(*conn.MessageAfterAt) {
+			log.Debugf("Connection accepted too recently, skipping until %s: %s", conn.MessageAfterAt.Format(time.RFC3339), conn.ProfileURL)
+			continue
+		}
+
+		inMail := conn.Status == string(storage.StateFollowed)
+		if inMail && inMailCredits >= 0 && inMailCredits < s.cfg.Messaging.MinInMailBalance {
+			log.Debugf("InMail balance below minimum, skipping %s", conn.ProfileURL)
+			continue
+		}
+
+		if s.browser.VisitedRecently(ctx, conn.ProfileURL) {
+			continue
+		}
+
+		// Check rate limits last so skipped connections don't use up a slot
+		if !s.canSendMessage(ctx) {
+			log.Warn("Rate limit reached for messages")
 			break
 		}
 
-		// Check if connection was accepted recently (respect delay)
-		if conn.AcceptedAt != nil {
-			hoursSinceAccepted := time.Since(*conn.AcceptedAt).Hours()
-			if hoursSinceAccepted < float64(s.cfg.Messaging.DelayAfterConnectionHours) {
-				s.log.Debugf("Connection accepted too recently, skipping: %s", conn.ProfileURL)
-				continue
-			}
+		if s.cfg.DryRun {
+			log.Infof("[dry-run] Would send message to %s", conn.ProfileURL)
+			continue
+		}
+
+		if err := s.browser.Actions().Begin(); err != nil {
+			log.Info("Shutting down, stopping messaging")
+			break
 		}
 
 		// Send message
-		if err := s.sendMessage(&conn); err != nil {
-			s.log.Errorf("Failed to send message to %s: %v", conn.ProfileURL, err)
-			s.store.LogActivity("message", conn.ProfileURL, "failed", err.Error())
+		err := s.breaker.Execute(func() error {
+			return s.sendMessage(ctx, &conn)
+		})
+		s.browser.Actions().End()
+		if errors.Is(err, circuit.ErrCircuitOpen) {
+			log.Warn("Circuit breaker open, pausing messages")
+			break
+		}
+		if err != nil {
+			if errors.Is(err, ErrExistingThread) {
+				continue
+			}
+
+			log.Errorf("Failed to send message to %s: %v", conn.ProfileURL, err)
+			s.store.LogActivityAsync("message", conn.ProfileURL, "failed", err.Error())
 			continue
 		}
 
 		sent++
-		s.log.Infof("Message sent (%d/%d)", sent, len(connections))
+		if s.schedule != nil {
+			s.schedule.UseSlot(stealth.ActionMessage)
+		}
+		if inMail && inMailCredits > 0 {
+			inMailCredits--
+		}
+		log.Infof("Message sent (%d/%d)", sent, len(connections))
 
 		// Random delay between messages
-		s.browser.GetStealth().RandomDelay("action")
+		s.stealth.RandomDelay("action")
 
 		// Longer delay every few messages
 		if sent%3 == 0 {
-			s.browser.GetStealth().RandomDelay("think")
+			s.stealth.RandomDelay("think")
 		}
 	}
 
-	s.log.Infof("Sent %d messages", sent)
+	log.Infof("Sent %d messages", sent)
 	return sent, nil
 }
 
 // sendMessage sends a message to a specific connection
-func (s *Service) sendMessage(conn *storage.ConnectionRequest) error {
-	s.log.Infof("Sending message to: %s", conn.ProfileURL)
+func (s *Service) sendMessage(ctx context.Context, conn *storage.ConnectionRequest) error {
+	log := logger.FromContext(ctx)
 
-	// Navigate to messaging page with the profile
-	messagingURL := s.getMessagingURL(conn.ProfileURL)
+	log.Infof("Sending message to: %s", conn.ProfileURL)
 
-	if err := s.browser.Navigate(messagingURL); err != nil {
-		return fmt.Errorf("failed to navigate to messaging: %w", err)
+	// Read their recent posts before leaving for the messaging page
+	s.refreshRecentPosts(ctx, conn.ProfileURL)
+
+	// Navigate to the messaging page and make sure we haven't messaged them before
+	exists, threadURL, err := s.CheckExistingThread(ctx, conn.ProfileURL)
+	if err != nil {
+		return err
 	}
 
-	page := s.browser.GetPage()
-	stealth := s.browser.GetStealth()
+	if exists {
+		log.Warnf("Existing thread detected for %s, skipping: %s", conn.ProfileURL, threadURL)
 
-	// Wait for messaging interface to load
-	time.Sleep(3 * time.Second)
+		msg := &storage.Message{
+			ProfileID:  conn.ProfileID,
+			ProfileURL: conn.ProfileURL,
+			SentAt:     time.Now(),
+			Status:     "existing_thread",
+			ThreadURL:  threadURL,
+		}
+		if err := s.store.SaveMessage(msg); err != nil {
+			return fmt.Errorf("failed to record existing thread: %w", err)
+		}
+
+		return ErrExistingThread
+	}
+
+	page := s.browser.GetPage()
+	stealth := s.stealth
 
 	// Find message input box
-	messageBox, err := stealth.WaitForElement(page, ".msg-form__contenteditable", 10*time.Second)
+	messageBox, err := s.findMessageBox(page)
 	if err != nil {
-		// Try alternative selector
-		messageBox, err = stealth.WaitForElement(page, "div[role='textbox']", 5*time.Second)
-		if err != nil {
-			return fmt.Errorf("message box not found: %w", err)
-		}
+		return fmt.Errorf("message box not found: %w", err)
 	}
 
 	// Generate message content
-	messageContent := s.generateMessage(conn)
+	messageContent, templateID, err := s.generateMessage(conn)
+	if err != nil {
+		return fmt.Errorf("invalid message content: %w", err)
+	}
 
 	// Click on message box
-	if err := stealth.HumanClick(messageBox); err != nil {
+	if err := stealth.HumanClick(ctx, messageBox); err != nil {
 		return fmt.Errorf("failed to click message box: %w", err)
 	}
 
 	stealth.RandomDelay("action")
 
 	// Type message with human-like behavior
-	if err := stealth.HumanType(messageBox, messageContent); err != nil {
+	if err := stealth.HumanType(ctx, messageBox, messageContent); err != nil {
 		return fmt.Errorf("failed to type message: %w", err)
 	}
 
 	stealth.RandomDelay("think")
 
-	// Find and click send button
-	sendButton, err := s.findSendButton(page)
-	if err != nil {
-		return fmt.Errorf("send button not found: %w", err)
-	}
-
-	if err := stealth.HumanClick(sendButton); err != nil {
-		return fmt.Errorf("failed to click send: %w", err)
+	if err := s.send(ctx, page, messageBox); err != nil {
+		return err
 	}
 
 	// Wait for message to be sent
@@ -166,17 +288,97 @@ func (s *Service) sendMessage(conn *storage.ConnectionRequest) error {
 		Content:    messageContent,
 		SentAt:     time.Now(),
 		Status:     "sent",
+		ThreadURL:  page.MustInfo().URL,
+		TemplateID: templateID,
 	}
 
 	if err := s.store.SaveMessage(msg); err != nil {
 		return fmt.Errorf("failed to save message: %w", err)
 	}
 
-	s.store.LogActivity("message", conn.ProfileURL, "success", "")
+	// conn.Status is "accepted", or "followed" for InMail to followed profiles
+	if err := s.store.TransitionState(conn.ProfileURL, storage.ConnectionState(conn.Status), storage.StateMessaged); err != nil {
+		log.Debugf("Connection state not updated for %s: %v", conn.ProfileURL, err)
+	}
+
+	s.store.LogActivityAsync("message", conn.ProfileURL, "success", "")
 
 	return nil
 }
 
+// send sends the typed message with the keyboard shortcut when enabled,
+// clicking the send button if the shortcut left the text in the box
+func (s *Service) send(ctx context.Context, page *rod.Page, messageBox *rod.Element) error {
+	if s.stealth.KeyboardShortcuts() {
+		if err := s.stealth.KeyboardSend(page); err != nil {
+			return fmt.Errorf("failed to send with keyboard shortcut: %w", err)
+		}
+
+		time.Sleep(time.Second)
+		if text, err := messageBox.Text(); err != nil || strings.TrimSpace(text) == "" {
+			return nil
+		}
+		s.stealth.RandomDelay("action")
+	}
+
+	// Find and click send button
+	sendButton, err := s.findSendButton(page)
+	if err != nil {
+		return fmt.Errorf("send button not found: %w", err)
+	}
+
+	if err := s.stealth.HumanClick(ctx, sendButton); err != nil {
+		return fmt.Errorf("failed to click send: %w", err)
+	}
+
+	return nil
+}
+
+// CheckExistingThread navigates to the messaging page for a profile and reports
+// whether a conversation already exists, along with the thread URL
+func (s *Service) CheckExistingThread(ctx context.Context, profileURL string) (bool, string, error) {
+	log := logger.FromContext(ctx)
+
+	messagingURL := s.getMessagingURL(profileURL)
+	if err := s.browser.Navigate(messagingURL); err != nil {
+		return false, "", fmt.Errorf("failed to navigate to messaging: %w", err)
+	}
+
+	page := s.browser.GetPage()
+
+	// Wait for messaging interface to load
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	exists, threadURL, err := s.threadOnPage(page)
+	if err == nil && !exists {
+		log.Debugf("No existing thread for %s", profileURL)
+	}
+
+	return exists, threadURL, err
+}
+
+// threadOnPage reports whether the loaded messaging page shows an existing
+// conversation, along with its URL
+func (s *Service) threadOnPage(page *rod.Page) (bool, string, error) {
+	has, _, err := page.Has(s.cfg.Selectors.MessageThreadEvent)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check message thread: %w", err)
+	}
+
+	if !has {
+		return false, "", nil
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		return true, "", fmt.Errorf("failed to get thread URL: %w", err)
+	}
+
+	return true, info.URL, nil
+}
+
 // getMessagingURL constructs the messaging URL for a profile
 func (s *Service) getMessagingURL(profileURL string) string {
 	// Extract profile ID from URL
@@ -193,14 +395,7 @@ func (s *Service) getMessagingURL(profileURL string) string {
 
 // findSendButton finds the send button in the messaging interface
 func (s *Service) findSendButton(page *rod.Page) (*rod.Element, error) {
-	selectors := []string{
-		"button.msg-form__send-button",
-		"button[type='submit']",
-		"button:has-text('Send')",
-		".msg-form__send-button",
-	}
-
-	for _, selector := range selectors {
+	for _, selector := range s.cfg.Selectors.SendButton {
 		element, err := page.Element(selector)
 		if err == nil {
 			// Check if button is enabled
@@ -211,34 +406,119 @@ func (s *Service) findSendButton(page *rod.Page) (*rod.Element, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("send button not found or disabled")
+	return nil, fmt.Errorf("send button not found or disabled (selector SendButton)")
 }
 
-// generateMessage generates a personalized message
-func (s *Service) generateMessage(conn *storage.ConnectionRequest) string {
-	if len(s.cfg.Messaging.Templates) == 0 {
-		return "Thanks for connecting! Looking forward to staying in touch."
+// findMessageBox waits for the message input, racing the configured selectors
+func (s *Service) findMessageBox(page *rod.Page) (*rod.Element, error) {
+	element, err := s.stealth.WaitForElement(page, s.cfg.Selectors.MessageBox, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("message box not found (selector MessageBox): %w", err)
 	}
 
-	// Select random template
-	template := s.cfg.Messaging.Templates[rand.Intn(len(s.cfg.Messaging.Templates))]
+	return element, nil
+}
+
+// generateMessage generates a personalized message and returns it with the
+// 1-based ID of the template used
+func (s *Service) generateMessage(conn *storage.ConnectionRequest) (string, int, error) {
+	const fallback = "Thanks for connecting! Looking forward to staying in touch."
+
+	if len(s.cfg.Messaging.Templates) == 0 {
+		return fallback, 0, nil
+	}
 
 	// Get profile information
 	profile, err := s.store.GetProfileByURL(conn.ProfileURL)
 	if err != nil || profile == nil {
-		return template
+		// Select random template
+		index := rand.Intn(len(s.cfg.Messaging.Templates))
+		message, err := s.templates.ValidateMessageLength(s.cfg.Messaging.Templates[index], template.TypeMessage)
+		return message, index + 1, err
 	}
 
-	// Extract first name
-	firstName := extractFirstName(profile.Name)
+	vars, err := s.store.GetVariables(profile.ID)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to load profile variables: %w", err)
+	}
 
-	// Replace placeholders
-	message := strings.ReplaceAll(template, "{{FirstName}}", firstName)
-	message = strings.ReplaceAll(message, "{{Company}}", profile.Company)
-	message = strings.ReplaceAll(message, "{{Topic}}", profile.Keywords)
-	message = strings.ReplaceAll(message, "{{Field}}", profile.JobTitle)
+	values := s.templateValues(profile, s.cfg.Messaging.Templates...)
 
-	return message
+	// Select a random template among those whose variables are all set,
+	// skipping {{RecentTopics}} and {{DerivedTopics}} templates when no
+	// topics are known
+	var candidates []int
+	for i, tmpl := range s.cfg.Messaging.Templates {
+		if len(missingTopics(tmpl, values)) > 0 {
+			continue
+		}
+		if len(template.MissingVariables(tmpl, vars)) == 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback, 0, nil
+	}
+
+	index := candidates[rand.Intn(len(candidates))]
+	tmpl := s.cfg.Messaging.Templates[index]
+
+	message := s.templates.RenderVariables(fillPlaceholders(tmpl, values), vars)
+
+	message, err = s.templates.ValidateMessageLength(message, template.TypeMessage)
+	return message, index + 1, err
+}
+
+// templateValues returns the placeholder values shared by every message
+// template, first messages and auto-responder replies alike. Topics are only
+// looked up when one of templates uses them.
+func (s *Service) templateValues(profile *storage.Profile, templates ...string) map[string]string {
+	values := map[string]string{
+		"{{FirstName}}": extractFirstName(profile.Name),
+		"{{Company}}":   profile.Company,
+		"{{Topic}}":     profile.Keywords,
+		"{{Field}}":     profile.JobTitle,
+		"{{Headline}}":  profile.Headline,
+		"{{Summary}}":   profile.Summary,
+	}
+
+	used := strings.Join(templates, "\n")
+	if strings.Contains(used, recentTopicsPlaceholder) {
+		values[recentTopicsPlaceholder] = s.recentTopics(profile)
+	}
+	if strings.Contains(used, derivedTopicsPlaceholder) {
+		values[derivedTopicsPlaceholder] = s.derivedTopics(profile)
+	}
+	return values
+}
+
+// fillPlaceholders replaces the placeholders in a message template with
+// their values from templateValues
+func fillPlaceholders(tmpl string, values map[string]string) string {
+	placeholders := make([]string, 0, len(values))
+	for placeholder := range values {
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+
+	// A single pass, so values containing placeholders are left alone
+	pairs := make([]string, 0, 2*len(placeholders))
+	for _, placeholder := range placeholders {
+		pairs = append(pairs, placeholder, values[placeholder])
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+// missingTopics returns the topic placeholders tmpl uses that have no value,
+// so the template can be skipped rather than sent with a gap
+func missingTopics(tmpl string, values map[string]string) []string {
+	var missing []string
+	for _, placeholder := range []string{recentTopicsPlaceholder, derivedTopicsPlaceholder} {
+		if strings.Contains(tmpl, placeholder) && values[placeholder] == "" {
+			missing = append(missing, placeholder)
+		}
+	}
+	return missing
 }
 
 // extractFirstName extracts the first name from a full name
@@ -251,18 +531,32 @@ func extractFirstName(fullName string) string {
 }
 
 // canSendMessage checks if we can send more messages based on rate limits
-func (s *Service) canSendMessage() bool {
-	// Check daily limit
+func (s *Service) canSendMessage(ctx context.Context) bool {
+	log := logger.FromContext(ctx)
+
+	// Check daily limit, lowered while in safe mode
+	_, perDay := s.cfg.DailyLimits(s.store.SafeModeActive())
 	dailyStats := s.store.GetTodayStats()
-	if dailyStats.MessagesSent >= s.cfg.RateLimits.Messages.PerDay {
-		s.log.Warn("Daily message limit reached")
+	if dailyStats.MessagesSent >= perDay {
+		log.Warn("Daily message limit reached")
 		return false
 	}
 
 	// Check hourly limit
 	hourlyStats := s.store.GetHourlyStats()
 	if hourlyStats.MessagesSent >= s.cfg.RateLimits.Messages.PerHour {
-		s.log.Warn("Hourly message limit reached")
+		log.Warn("Hourly message limit reached")
+		return false
+	}
+
+	// Don't send ahead of the next slot; the run ends and the main loop
+	// tries again later instead of holding the page while waiting
+	if s.schedule != nil && !s.schedule.SlotDue(stealth.ActionMessage) {
+		if next := s.schedule.NextScheduledSlot(stealth.ActionMessage); next.IsZero() {
+			log.Info("No message slots left this hour")
+		} else {
+			log.Infof("Next message slot at %s", next.Format("15:04:05"))
+		}
 		return false
 	}
 
@@ -270,8 +564,10 @@ func (s *Service) canSendMessage() bool {
 }
 
 // SendMessageToProfile sends a message to a specific profile URL
-func (s *Service) SendMessageToProfile(profileURL, message string) error {
-	s.log.Infof("Sending custom message to: %s", profileURL)
+func (s *Service) SendMessageToProfile(ctx context.Context, profileURL, message string) error {
+	log := logger.FromContext(ctx)
+
+	log.Infof("Sending custom message to: %s", profileURL)
 
 	// Get or create profile
 	profile, err := s.store.GetProfileByURL(profileURL)
@@ -299,27 +595,26 @@ func (s *Service) SendMessageToProfile(profileURL, message string) error {
 	}
 
 	page := s.browser.GetPage()
-	stealth := s.browser.GetStealth()
+	stealth := s.stealth
 
-	time.Sleep(3 * time.Second)
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
 
 	// Find message box
-	messageBox, err := stealth.WaitForElement(page, ".msg-form__contenteditable", 10*time.Second)
+	messageBox, err := s.findMessageBox(page)
 	if err != nil {
-		messageBox, err = stealth.WaitForElement(page, "div[role='textbox']", 5*time.Second)
-		if err != nil {
-			return fmt.Errorf("message box not found: %w", err)
-		}
+		return fmt.Errorf("message box not found: %w", err)
 	}
 
 	// Click and type message
-	if err := stealth.HumanClick(messageBox); err != nil {
+	if err := stealth.HumanClick(ctx, messageBox); err != nil {
 		return fmt.Errorf("failed to click message box: %w", err)
 	}
 
 	stealth.RandomDelay("action")
 
-	if err := stealth.HumanType(messageBox, message); err != nil {
+	if err := stealth.HumanType(ctx, messageBox, message); err != nil {
 		return fmt.Errorf("failed to type message: %w", err)
 	}
 
@@ -331,7 +626,7 @@ func (s *Service) SendMessageToProfile(profileURL, message string) error {
 		return fmt.Errorf("send button not found: %w", err)
 	}
 
-	if err := stealth.HumanClick(sendButton); err != nil {
+	if err := stealth.HumanClick(ctx, sendButton); err != nil {
 		return fmt.Errorf("failed to click send: %w", err)
 	}
 
@@ -350,7 +645,7 @@ func (s *Service) SendMessageToProfile(profileURL, message string) error {
 		return fmt.Errorf("failed to save message: %w", err)
 	}
 
-	s.store.LogActivity("message", profileURL, "success", "")
+	s.store.LogActivityAsync("message", profileURL, "success", "")
 
 	return nil
 }
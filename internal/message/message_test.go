@@ -0,0 +1,185 @@
+package message
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// newFixturePage opens a blank page in a headless browser, skipping the test
+// when no browser is installed. CHROME_PATH overrides the browser like in
+// browser.New.
+func newFixturePage(t *testing.T) *rod.Page {
+	t.Helper()
+
+	bin := os.Getenv("CHROME_PATH")
+	if bin == "" {
+		path, found := launcher.LookPath()
+		if !found {
+			t.Skip("no browser installed")
+		}
+		bin = path
+	}
+
+	u, err := launcher.New().Bin(bin).Headless(true).Launch()
+	if err != nil {
+		t.Fatalf("launch browser: %v", err)
+	}
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("connect to browser: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("open page: %v", err)
+	}
+	return page
+}
+
+const (
+	newThreadHTML = `<html><body>
+<div class="msg-form__contenteditable" contenteditable="true"></div>
+<button class="msg-form__send-button">Send</button>
+</body></html>`
+
+	existingThreadHTML = `<html><body>
+<ul class="msg-s-message-list-content">
+  <li class="msg-s-message-list__event">Hi Jane, great to connect!</li>
+  <li class="msg-s-message-list__event">Thanks, likewise</li>
+</ul>
+<div class="msg-form__contenteditable" contenteditable="true"></div>
+</body></html>`
+)
+
+func TestThreadOnPage(t *testing.T) {
+	page := newFixturePage(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/messaging/thread/2-abc/" {
+			w.Write([]byte(existingThreadHTML))
+			return
+		}
+		w.Write([]byte(newThreadHTML))
+	}))
+	t.Cleanup(server.Close)
+
+	s := &Service{cfg: &config.Config{}}
+	s.cfg.Selectors = config.DefaultSelectors()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantExists bool
+	}{
+		{"new thread", "/messaging/thread/new/?recipient=jane", false},
+		{"existing thread", "/messaging/thread/2-abc/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page.MustNavigate(server.URL + tt.path).MustWaitLoad()
+
+			exists, threadURL, err := s.threadOnPage(page)
+			if err != nil {
+				t.Fatalf("threadOnPage: %v", err)
+			}
+			if exists != tt.wantExists {
+				t.Errorf("exists = %v, want %v", exists, tt.wantExists)
+			}
+
+			wantURL := ""
+			if tt.wantExists {
+				wantURL = server.URL + tt.path
+			}
+			if threadURL != wantURL {
+				t.Errorf("thread URL = %q, want %q", threadURL, wantURL)
+			}
+		})
+	}
+}
+
+func TestGetMessagingURL(t *testing.T) {
+	s := &Service{}
+
+	for _, profileURL := range []string{"https://www.linkedin.com/in/jane-doe", "https://www.linkedin.com/in/jane-doe/"} {
+		want := "https://www.linkedin.com/messaging/thread/new/?recipient=jane-doe"
+		if got := s.getMessagingURL(profileURL); got != want {
+			t.Errorf("getMessagingURL(%q) = %q, want %q", profileURL, got, want)
+		}
+	}
+}
+
+func TestIsMessageSentCountsExistingThreads(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	tests := []struct {
+		username string
+		status   string
+		want     bool
+	}{
+		{"sent", "sent", true},
+		{"existing", "existing_thread", true},
+		{"failed", "failed", false},
+	}
+
+	for _, tt := range tests {
+		profileURL := "https://www.linkedin.com/in/" + tt.username
+		msg := &storage.Message{ProfileURL: profileURL, Status: tt.status, ThreadURL: "https://www.linkedin.com/messaging/thread/2-abc/"}
+		if err := store.SaveMessage(msg); err != nil {
+			t.Fatalf("SaveMessage: %v", err)
+		}
+
+		if sent, err := store.IsMessageSent(profileURL); err != nil || sent != tt.want {
+			t.Errorf("IsMessageSent with status %s = %v (err %v), want %v", tt.status, sent, err, tt.want)
+		}
+	}
+}
+
+func TestCanSendMessageInSafeMode(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{}
+	cfg.RateLimits.Messages.PerDay = 10
+	cfg.RateLimits.Messages.PerHour = 100
+	cfg.SafeMode.MaxMessagesPerDay = 3
+	s := &Service{store: store, cfg: cfg}
+	ctx := context.Background()
+
+	for _, username := range []string{"jane", "john", "jim"} {
+		if err := store.SaveMessage(&storage.Message{ProfileURL: "https://www.linkedin.com/in/" + username, Content: "Hi", Status: "sent"}); err != nil {
+			t.Fatalf("SaveMessage: %v", err)
+		}
+	}
+
+	if !s.canSendMessage(ctx) {
+		t.Error("canSendMessage = false with 3 of 10 sent, want true")
+	}
+
+	if err := store.SetSafeMode(true, "test"); err != nil {
+		t.Fatalf("SetSafeMode: %v", err)
+	}
+	if s.canSendMessage(ctx) {
+		t.Error("canSendMessage = true in safe mode with 3 of 3 sent, want false")
+	}
+}
@@ -0,0 +1,83 @@
+package message
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/content"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+const (
+	// recentTopicsPlaceholder is filled with the recipient's top post keywords
+	recentTopicsPlaceholder = "{{RecentTopics}}"
+
+	// recentTopicsCount is how many keywords {{RecentTopics}} lists
+	recentTopicsCount = 3
+
+	// derivedTopicsPlaceholder is filled with the recipient's top profile
+	// keywords from the nightly TF-IDF extraction
+	derivedTopicsPlaceholder = "{{DerivedTopics}}"
+
+	// derivedTopicsCount is how many keywords {{DerivedTopics}} lists
+	derivedTopicsCount = 3
+
+	// recentPostsTTL is how long scraped posts are reused before rescraping
+	recentPostsTTL = 7 * 24 * time.Hour
+)
+
+// refreshRecentPosts scrapes the recipient's recent posts when a message
+// template uses {{RecentTopics}} and they haven't been scraped lately
+func (s *Service) refreshRecentPosts(ctx context.Context, profileURL string) {
+	log := logger.FromContext(ctx)
+
+	if s.cfg.Messaging.RecentPostsLimit <= 0 || !s.templatesUse(recentTopicsPlaceholder) {
+		return
+	}
+
+	profile, err := s.store.GetProfileByURL(profileURL)
+	if err != nil || profile == nil {
+		return
+	}
+
+	if fresh, err := s.store.PostsScrapedSince(profile.ID, time.Now().Add(-recentPostsTTL)); err != nil || fresh {
+		return
+	}
+
+	if _, err := s.content.GetRecentPosts(ctx, profile, s.cfg.Messaging.RecentPostsLimit); err != nil {
+		log.Warnf("Failed to read recent posts for %s: %v", profileURL, err)
+	}
+	s.stealth.RandomDelay("action")
+}
+
+// recentTopics returns the {{RecentTopics}} value for a profile, or "" when
+// none of its posts have been scraped
+func (s *Service) recentTopics(profile *storage.Profile) string {
+	keywords, err := s.content.GetTopKeywords(profile.ID, recentTopicsCount)
+	if err != nil {
+		return ""
+	}
+	return content.RecentTopics(keywords)
+}
+
+// derivedTopics returns the {{DerivedTopics}} value for a profile, or "" when
+// no keywords have been extracted for it yet
+func (s *Service) derivedTopics(profile *storage.Profile) string {
+	keywords, err := s.store.GetProfileKeywords(profile.ID, derivedTopicsCount)
+	if err != nil {
+		return ""
+	}
+	return content.RecentTopics(keywords)
+}
+
+// templatesUse reports whether any message template contains placeholder
+func (s *Service) templatesUse(placeholder string) bool {
+	for _, tmpl := range s.cfg.Messaging.Templates {
+		if strings.Contains(tmpl, placeholder) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+	"linkedin-automation/internal/template"
+)
+
+func TestFillPlaceholders(t *testing.T) {
+	values := map[string]string{
+		"{{FirstName}}":          "Jane",
+		"{{Company}}":            "{{FirstName}} Labs",
+		derivedTopicsPlaceholder: "go, sqlite",
+	}
+
+	got := fillPlaceholders("Hi {{FirstName}} at {{Company}}, into {{DerivedTopics}}? {{Unknown}}", values)
+	want := "Hi Jane at {{FirstName}} Labs, into go, sqlite? {{Unknown}}"
+	if got != want {
+		t.Errorf("fillPlaceholders = %q, want %q", got, want)
+	}
+}
+
+func TestAutoReplyResolvesDerivedTopics(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{}
+	s := &Service{store: store, cfg: cfg, templates: template.New(cfg)}
+
+	profile := &storage.Profile{ProfileURL: "https://www.linkedin.com/in/jane", Name: "Jane Doe"}
+	id, err := store.SaveProfile(profile)
+	if err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	profile.ID = id
+
+	const reply = "Thanks {{FirstName}}! More on {{DerivedTopics}} soon."
+	values := s.templateValues(profile, reply)
+	if missing := missingTopics(reply, values); len(missing) != 1 || missing[0] != derivedTopicsPlaceholder {
+		t.Fatalf("missingTopics without keywords = %v, want [%s]", missing, derivedTopicsPlaceholder)
+	}
+
+	if err := store.ReplaceProfileKeywords(map[int64][]storage.ProfileKeyword{
+		id: {{Keyword: "kubernetes", Score: 2}, {Keyword: "golang", Score: 1}},
+	}); err != nil {
+		t.Fatalf("ReplaceProfileKeywords: %v", err)
+	}
+
+	values = s.templateValues(profile, reply)
+	if missing := missingTopics(reply, values); len(missing) != 0 {
+		t.Fatalf("missingTopics = %v, want none", missing)
+	}
+	got := fillPlaceholders(reply, values)
+	if strings.Contains(got, "{{") || !strings.Contains(got, "kubernetes") {
+		t.Errorf("reply = %q, want the topics filled in", got)
+	}
+}
+
+func TestAutoReplyWithoutTopicsFails(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{DryRun: true}
+	cfg.RateLimits.Messages.PerDay = 10
+	cfg.RateLimits.Messages.PerHour = 5
+	cfg.Messaging.AutoResponderRules = []config.AutoResponderRule{
+		{KeywordsMatch: []string{"pricing"}, Action: config.AutoResponderReply, ReplyTemplate: "More on {{DerivedTopics}} soon"},
+	}
+	s := &Service{store: store, cfg: cfg, templates: template.New(cfg)}
+
+	err = s.EvaluateAutoResponder(context.Background(), Reply{ProfileURL: "https://www.linkedin.com/in/jane", Content: "pricing?"})
+	if err == nil || errors.Is(err, ErrAutoReplyDeferred) || !strings.Contains(err.Error(), derivedTopicsPlaceholder) {
+		t.Errorf("err = %v, want a missing topics failure", err)
+	}
+}
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+// RefreshInterval is how often the database derived metrics are recomputed
+const RefreshInterval = 60 * time.Second
+
+// Counters are the actions recorded since the process started
+type Counters struct {
+	ConnectionsSent     int64 `json:"connections_sent"`
+	ConnectionsAccepted int64 `json:"connections_accepted"`
+	Follows             int64 `json:"follows"`
+	MessagesSent        int64 `json:"messages_sent"`
+	Endorsements        int64 `json:"endorsements"`
+	Comments            int64 `json:"comments"`
+	CompanyFollows      int64 `json:"company_follows"`
+	Failures            int64 `json:"failures"`
+}
+
+// Derived are the metrics computed from the database on each refresh
+type Derived struct {
+	TotalProfiles      int                             `json:"total_profiles"`
+	ProfilesByState    map[storage.ConnectionState]int `json:"profiles_by_state"`
+	PendingConnections int                             `json:"pending_connections"`
+	RefreshedAt        time.Time                       `json:"refreshed_at"`
+}
+
+// Snapshot is the current state of all metrics
+type Snapshot struct {
+	StartedAt time.Time `json:"started_at"`
+	Counters  Counters  `json:"counters"`
+	Derived   Derived   `json:"derived"`
+}
+
+// Collector keeps metrics in memory so reading them never blocks on SQLite.
+// Counters are updated atomically as activities are logged and the derived
+// metrics are refreshed in the background by Start.
+type Collector struct {
+	store     *storage.Storage
+	startedAt time.Time
+
+	connectionsSent     atomic.Int64
+	connectionsAccepted atomic.Int64
+	follows             atomic.Int64
+	messagesSent        atomic.Int64
+	endorsements        atomic.Int64
+	comments            atomic.Int64
+	companyFollows      atomic.Int64
+	failures            atomic.Int64
+
+	derived atomic.Pointer[Derived]
+}
+
+// New creates a collector and registers it as the store's activity observer
+func New(store *storage.Storage) *Collector {
+	c := &Collector{
+		store:     store,
+		startedAt: time.Now(),
+	}
+	c.derived.Store(&Derived{ProfilesByState: map[storage.ConnectionState]int{}})
+
+	store.OnActivity(c.Record)
+	return c
+}
+
+// Record counts a logged activity
+func (c *Collector) Record(actionType, outcome string) {
+	if outcome == "failed" {
+		c.failures.Add(1)
+		return
+	}
+	if outcome != "success" {
+		return
+	}
+
+	switch actionType {
+	case "connection_request":
+		c.connectionsSent.Add(1)
+	case "connection_accepted":
+		c.connectionsAccepted.Add(1)
+	case "follow":
+		c.follows.Add(1)
+	case "message":
+		c.messagesSent.Add(1)
+	case "endorse":
+		c.endorsements.Add(1)
+	case "comment":
+		c.comments.Add(1)
+	case "company_follow":
+		c.companyFollows.Add(1)
+	}
+}
+
+// Start refreshes the derived metrics now and every RefreshInterval until
+// the context is cancelled
+func (c *Collector) Start(ctx context.Context) {
+	log := logger.FromContext(ctx)
+
+	if err := c.Refresh(); err != nil {
+		log.Warnf("Failed to refresh metrics: %v", err)
+	}
+
+	ticker := time.NewTicker(RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(); err != nil {
+				log.Warnf("Failed to refresh metrics: %v", err)
+			}
+		}
+	}
+}
+
+// Refresh recomputes the derived metrics from the database
+func (c *Collector) Refresh() error {
+	byState, err := c.store.CountByState()
+	if err != nil {
+		return err
+	}
+
+	pending, err := c.store.GetPendingConnectionURLs()
+	if err != nil {
+		return err
+	}
+
+	derived := &Derived{
+		ProfilesByState:    byState,
+		PendingConnections: len(pending),
+		RefreshedAt:        time.Now(),
+	}
+	for _, count := range byState {
+		derived.TotalProfiles += count
+	}
+
+	c.derived.Store(derived)
+	return nil
+}
+
+// Snapshot returns the current metrics without touching the database
+func (c *Collector) Snapshot() Snapshot {
+	return Snapshot{
+		StartedAt: c.startedAt,
+		Counters: Counters{
+			ConnectionsSent:     c.connectionsSent.Load(),
+			ConnectionsAccepted: c.connectionsAccepted.Load(),
+			Follows:             c.follows.Load(),
+			MessagesSent:        c.messagesSent.Load(),
+			Endorsements:        c.endorsements.Load(),
+			Comments:            c.comments.Load(),
+			CompanyFollows:      c.companyFollows.Load(),
+			Failures:            c.failures.Load(),
+		},
+		Derived: *c.derived.Load(),
+	}
+}
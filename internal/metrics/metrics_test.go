@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"linkedin-automation/internal/storage"
+)
+
+func newTestCollector(t *testing.T) (*Collector, *storage.Storage) {
+	t.Helper()
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return New(store), store
+}
+
+// TestConcurrentRecording is meant to be run with -race
+func TestConcurrentRecording(t *testing.T) {
+	c, store := newTestCollector(t)
+
+	const (
+		goroutines = 50
+		perRoutine = 100
+	)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perRoutine; i++ {
+				switch i % 4 {
+				case 0:
+					c.Record("connection_request", "success")
+				case 1:
+					c.Record("message", "success")
+				case 2:
+					c.Record("connection_request", "failed")
+				default:
+					// Through the store's activity observer
+					store.LogActivityAsync("follow", "", "success", "")
+				}
+
+				if i%10 == 0 {
+					_ = c.Snapshot()
+				}
+			}
+			if g%10 == 0 {
+				if err := c.Refresh(); err != nil {
+					t.Errorf("Refresh: %v", err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	want := int64(goroutines * perRoutine / 4)
+	got := c.Snapshot().Counters
+	if got.ConnectionsSent != want || got.MessagesSent != want || got.Failures != want || got.Follows != want {
+		t.Errorf("counters = %+v, want %d of each recorded kind", got, want)
+	}
+}
+
+func TestRecordIgnoresOtherOutcomes(t *testing.T) {
+	c, _ := newTestCollector(t)
+
+	c.Record("connection_request", "skipped")
+	c.Record("unknown_action", "success")
+
+	if got := c.Snapshot().Counters; got != (Counters{}) {
+		t.Errorf("counters = %+v, want all zero", got)
+	}
+}
+
+func TestRefreshCountsProfiles(t *testing.T) {
+	c, store := newTestCollector(t)
+
+	for _, username := range []string{"jane", "john"} {
+		if _, err := store.SaveProfile(&storage.Profile{ProfileURL: "https://www.linkedin.com/in/" + username, Name: username}); err != nil {
+			t.Fatalf("SaveProfile: %v", err)
+		}
+	}
+
+	if got := c.Snapshot().Derived.TotalProfiles; got != 0 {
+		t.Errorf("TotalProfiles before refresh = %d, want 0", got)
+	}
+	if err := c.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	derived := c.Snapshot().Derived
+	if derived.TotalProfiles != 2 || derived.RefreshedAt.IsZero() {
+		t.Errorf("derived = %+v, want 2 profiles and a refresh time", derived)
+	}
+}
@@ -0,0 +1,42 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+)
+
+// legalSuffixes are words dropped from the end of company names so "Acme
+// Corp.", "Acme Corporation" and "Acme, Inc." all normalize to "acme"
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "llp": true, "ltd": true,
+	"limited": true, "corp": true, "corporation": true, "co": true, "plc": true,
+	"gmbh": true, "ag": true, "sa": true, "bv": true, "pty": true,
+}
+
+// companyJoinChars are removed before a company name is split into words
+var companyJoinChars = strings.NewReplacer(".", "", "'", "", "\u2019", "")
+
+// NormalizeCompanyName lowercases a company name, drops punctuation and
+// trailing legal suffixes, e.g. "Acme Co., Ltd." -> "acme". Names made up
+// only of a suffix, such as "Corporation", are kept.
+func NormalizeCompanyName(raw string) string {
+	// Dots and apostrophes join rather than split, so "S.A." is "sa" and
+	// "McDonald's" is "mcdonalds"
+	name := companyJoinChars.Replace(strings.ToLower(raw))
+
+	companyWords := strings.FieldsFunc(name, func(r rune) bool {
+		// "&" is part of names such as "AT&T" and "Procter & Gamble"
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+	})
+
+	// "& Co." leaves a dangling "&" once the suffix is gone
+	for len(companyWords) > 1 {
+		last := companyWords[len(companyWords)-1]
+		if !legalSuffixes[last] && last != "&" {
+			break
+		}
+		companyWords = companyWords[:len(companyWords)-1]
+	}
+
+	return strings.Join(companyWords, " ")
+}
@@ -0,0 +1,222 @@
+package normalize
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// minTermLength drops short tokens such as "at", "co" and initials
+const minTermLength = 3
+
+// stopWords are common English words and profile boilerplate that say
+// nothing about what a person works on
+var stopWords = map[string]bool{
+	"about": true, "after": true, "all": true, "also": true, "and": true,
+	"any": true, "are": true, "around": true, "been": true, "being": true,
+	"both": true, "but": true, "can": true, "could": true, "did": true,
+	"does": true, "each": true, "every": true, "for": true, "from": true,
+	"had": true, "has": true, "have": true, "her": true, "here": true,
+	"him": true, "his": true, "how": true, "into": true, "its": true,
+	"just": true, "like": true, "more": true, "most": true, "much": true,
+	"not": true, "now": true, "off": true, "one": true, "only": true,
+	"other": true, "our": true, "out": true, "over": true, "own": true,
+	"she": true, "should": true, "some": true, "such": true, "than": true,
+	"that": true, "the": true, "their": true, "them": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "those": true,
+	"through": true, "too": true, "under": true, "very": true, "was": true,
+	"way": true, "were": true, "what": true, "when": true, "where": true,
+	"which": true, "while": true, "who": true, "why": true, "will": true,
+	"with": true, "would": true, "you": true, "your": true,
+	// Profile boilerplate
+	"currently": true, "experience": true, "helping": true, "help": true,
+	"inc": true, "llc": true, "ltd": true, "passionate": true, "professional": true,
+	"team": true, "work": true, "working": true, "world": true, "years": true,
+}
+
+// stemSuffixes are the suffix replacements Stem applies, longest first
+var stemSuffixes = []struct {
+	suffix      string
+	replacement string
+}{
+	{"ization", "ize"},
+	{"ational", "ate"},
+	{"fulness", "ful"},
+	{"ments", ""},
+	{"ment", ""},
+	{"ness", ""},
+	{"ings", ""},
+	{"ing", ""},
+	{"ies", "y"},
+	{"ed", ""},
+	{"ly", ""},
+	{"s", ""},
+}
+
+// Keyword is an extracted term with its TF-IDF score. Term is the stem that
+// was scored and Word its most frequent spelling in the text.
+type Keyword struct {
+	Term  string
+	Word  string
+	Score float64
+}
+
+// keywordCorpus holds the document frequencies ExtractKeywords scores against
+type keywordCorpus struct {
+	docs    int
+	docFreq map[string]int
+}
+
+var (
+	corpusMu sync.RWMutex
+	corpus   = &keywordCorpus{docFreq: map[string]int{}}
+)
+
+// SetKeywordCorpus replaces the documents used for inverse document
+// frequency, normally the combined text of every stored profile
+func SetKeywordCorpus(texts []string) {
+	built := &keywordCorpus{docs: len(texts), docFreq: make(map[string]int)}
+	for _, text := range texts {
+		seen := make(map[string]bool)
+		for _, term := range Terms(text) {
+			if !seen[term] {
+				seen[term] = true
+				built.docFreq[term]++
+			}
+		}
+	}
+
+	corpusMu.Lock()
+	corpus = built
+	corpusMu.Unlock()
+}
+
+// ExtractKeywords returns the topN terms of text by TF-IDF score against the
+// corpus set with SetKeywordCorpus
+func ExtractKeywords(text string, topN int) []string {
+	scored := ScoreKeywords(text, topN)
+	words := make([]string, len(scored))
+	for i, keyword := range scored {
+		words[i] = keyword.Word
+	}
+	return words
+}
+
+// ScoreKeywords is ExtractKeywords with the scores. Terms are stemmed, so
+// "engineering" and "engineers" both count as "engineer".
+func ScoreKeywords(text string, topN int) []Keyword {
+	words := keywordTokens(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	spellings := make(map[string]map[string]int)
+	var order []string
+	for _, word := range words {
+		term := Stem(word)
+		if counts[term] == 0 {
+			order = append(order, term)
+			spellings[term] = make(map[string]int)
+		}
+		counts[term]++
+		spellings[term][word]++
+	}
+
+	corpusMu.RLock()
+	current := corpus
+	corpusMu.RUnlock()
+
+	keywords := make([]Keyword, 0, len(order))
+	for _, term := range order {
+		tf := float64(counts[term]) / float64(len(words))
+		keywords = append(keywords, Keyword{
+			Term:  term,
+			Word:  commonSpelling(spellings[term]),
+			Score: tf * current.idf(term),
+		})
+	}
+
+	// Ties keep first-seen order
+	sort.SliceStable(keywords, func(i, j int) bool {
+		return keywords[i].Score > keywords[j].Score
+	})
+
+	if topN > 0 && len(keywords) > topN {
+		keywords = keywords[:topN]
+	}
+	return keywords
+}
+
+// commonSpelling returns the most frequent word, the alphabetically first
+// on ties
+func commonSpelling(counts map[string]int) string {
+	best := ""
+	for word, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && word < best) {
+			best = word
+		}
+	}
+	return best
+}
+
+// idf is the smoothed inverse document frequency of a term, so terms missing
+// from the corpus still score and terms in every document score lowest
+func (c *keywordCorpus) idf(term string) float64 {
+	return math.Log(float64(c.docs+1)/float64(c.docFreq[term]+1)) + 1
+}
+
+// Terms tokenizes text into lowercase stemmed words, dropping stop words,
+// short words and numbers
+func Terms(text string) []string {
+	words := keywordTokens(text)
+	for i, word := range words {
+		words[i] = Stem(word)
+	}
+	return words
+}
+
+// keywordTokens splits text into lowercase words, dropping stop words, short
+// words and numbers
+func keywordTokens(text string) []string {
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+'
+	})
+
+	var words []string
+	for _, token := range tokens {
+		if len([]rune(token)) < minTermLength || stopWords[token] || !hasLetter(token) {
+			continue
+		}
+		words = append(words, token)
+	}
+	return words
+}
+
+// Stem replaces a common English suffix, keeping at least three letters of
+// the word, e.g. "marketing" -> "market" and "companies" -> "company"
+func Stem(word string) string {
+	// Words such as "business" and "class" aren't plurals
+	if strings.HasSuffix(word, "ss") {
+		return word
+	}
+
+	for _, rule := range stemSuffixes {
+		if strings.HasSuffix(word, rule.suffix) && len(word)-len(rule.suffix) >= minTermLength {
+			return strings.TrimSuffix(word, rule.suffix) + rule.replacement
+		}
+	}
+	return word
+}
+
+// hasLetter reports whether word contains a letter
+func hasLetter(word string) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package normalize
+
+import "testing"
+
+func TestNormalizeJobTitle(t *testing.T) {
+	tests := []struct {
+		raw       string
+		title     string
+		seniority Seniority
+	}{
+		{"Software Engineer", "software engineer", SeniorityIC},
+		{"Sr. Software Engineer", "senior software engineer", SeniorityIC},
+		{"SWE II", "software engineer ii", SeniorityIC},
+		{"SDE", "software engineer", SeniorityIC},
+		{"Software Development Engineer", "software engineer", SeniorityIC},
+		{"Jr Dev", "junior developer", SeniorityIC},
+		{"Data Scientist", "data scientist", SeniorityIC},
+		{"Account Exec", "account executive", SeniorityIC},
+		{"Senior Product Designer @ Figma", "senior product designer", SeniorityIC},
+		{"Engineering Mgr", "engineering manager", SeniorityManager},
+		{"Product Manager at Google", "product manager", SeniorityManager},
+		{"Tech Lead", "tech lead", SeniorityManager},
+		{"Team Lead, Customer Support", "team lead customer support", SeniorityManager},
+		{"Shift Supervisor", "shift supervisor", SeniorityManager},
+		{"Director of Engineering", "director engineering", SeniorityDirector},
+		{"Sr Dir, Mktg", "senior director marketing", SeniorityDirector},
+		{"Head of Growth", "head growth", SeniorityDirector},
+		{"Managing Director", "md", SeniorityDirector},
+		{"VP of Sales", "vp sales", SeniorityVP},
+		{"Vice President, Sales", "vp sales", SeniorityVP},
+		{"VP Sales", "vp sales", SeniorityVP},
+		{"Senior Vice President of Ops", "svp operations", SeniorityVP},
+		{"Sr VP Biz Dev", "svp business development", SeniorityVP},
+		{"Executive Vice President", "evp", SeniorityVP},
+		{"VP Engineering Manager", "vp engineering manager", SeniorityVP},
+		{"Chief Executive Officer", "ceo", SeniorityCLevel},
+		{"Chief Technology Officer & Co-Founder", "cto cofounder", SeniorityCLevel},
+		{"CFO", "cfo", SeniorityCLevel},
+		{"Founder and CEO", "founder ceo", SeniorityCLevel},
+		{"President", "president", SeniorityCLevel},
+		{"Owner, Smith Plumbing", "owner smith plumbing", SeniorityCLevel},
+		{"", "", SeniorityIC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			title, seniority := NormalizeJobTitle(tt.raw)
+			if title != tt.title || seniority != tt.seniority {
+				t.Errorf("NormalizeJobTitle(%q) = %q, %s, want %q, %s", tt.raw, title, seniority, tt.title, tt.seniority)
+			}
+		})
+	}
+}
+
+func TestSetSynonyms(t *testing.T) {
+	t.Cleanup(func() { SetSynonyms(nil) })
+
+	SetSynonyms(map[string]string{"Growth Hacker": "marketing manager", "SWE": "engineer"})
+
+	if title, seniority := NormalizeJobTitle("Growth Hacker"); title != "marketing manager" || seniority != SeniorityManager {
+		t.Errorf("custom synonym = %q, %s, want marketing manager, Manager", title, seniority)
+	}
+	if title, _ := NormalizeJobTitle("SWE"); title != "engineer" {
+		t.Errorf("overridden default = %q, want engineer", title)
+	}
+	if title, _ := NormalizeJobTitle("VP of Sales"); title != "vp sales" {
+		t.Errorf("defaults after SetSynonyms = %q, want vp sales", title)
+	}
+}
@@ -0,0 +1,207 @@
+package normalize
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Seniority is the level of a job title
+type Seniority string
+
+// Seniority levels, from least to most senior. The values match those
+// accepted in search.icp.seniorities.
+const (
+	SeniorityIC       Seniority = "IC"
+	SeniorityManager  Seniority = "Manager"
+	SeniorityDirector Seniority = "Director"
+	SeniorityVP       Seniority = "VP"
+	SeniorityCLevel   Seniority = "C-Suite"
+)
+
+// defaultSynonyms maps common title variants to a canonical form. Keys and
+// values are lowercase words separated by single spaces.
+var defaultSynonyms = map[string]string{
+	"vice president":                "vp",
+	"senior vice president":         "svp",
+	"executive vice president":      "evp",
+	"assistant vice president":      "avp",
+	"sr vp":                         "svp",
+	"chief executive officer":       "ceo",
+	"chief technology officer":      "cto",
+	"chief technical officer":       "cto",
+	"chief financial officer":       "cfo",
+	"chief operating officer":       "coo",
+	"chief marketing officer":       "cmo",
+	"chief information officer":     "cio",
+	"chief product officer":         "cpo",
+	"chief revenue officer":         "cro",
+	"co founder":                    "cofounder",
+	"managing director":             "md",
+	"sr":                            "senior",
+	"snr":                           "senior",
+	"jr":                            "junior",
+	"mgr":                           "manager",
+	"dir":                           "director",
+	"eng":                           "engineering",
+	"engg":                          "engineering",
+	"swe":                           "software engineer",
+	"sde":                           "software engineer",
+	"software development engineer": "software engineer",
+	"dev":                           "developer",
+	"mktg":                          "marketing",
+	"ops":                           "operations",
+	"biz dev":                       "business development",
+	"bd":                            "business development",
+	"acct":                          "account",
+	"exec":                          "executive",
+}
+
+// fillerWords are dropped so "VP of Sales" and "VP, Sales" normalize alike
+var fillerWords = map[string]bool{"of": true, "the": true, "and": true, "for": true}
+
+// seniorityKeywords maps canonical title words to seniority levels, checked
+// from most to least senior so "VP Engineering Manager" counts as VP
+var seniorityKeywords = []struct {
+	level    Seniority
+	keywords []string
+}{
+	{SeniorityCLevel, []string{"chief", "ceo", "cto", "cfo", "coo", "cmo", "cio", "cpo", "cro", "founder", "cofounder", "president", "owner"}},
+	{SeniorityVP, []string{"vp", "svp", "evp", "avp"}},
+	{SeniorityDirector, []string{"director", "md", "head"}},
+	{SeniorityManager, []string{"manager", "lead", "supervisor"}},
+}
+
+var (
+	synonymsMu sync.RWMutex
+	synonyms   = orderSynonyms(defaultSynonyms)
+)
+
+// synonym is a single variant -> canonical replacement
+type synonym struct {
+	variant   string
+	canonical string
+}
+
+// SetSynonyms adds title synonyms on top of the defaults, replacing any
+// default for the same variant
+func SetSynonyms(extra map[string]string) {
+	merged := make(map[string]string, len(defaultSynonyms)+len(extra))
+	for variant, canonical := range defaultSynonyms {
+		merged[variant] = canonical
+	}
+	for variant, canonical := range extra {
+		variant = strings.Join(words(variant), " ")
+		if variant != "" {
+			merged[variant] = strings.Join(words(canonical), " ")
+		}
+	}
+
+	synonymsMu.Lock()
+	synonyms = orderSynonyms(merged)
+	synonymsMu.Unlock()
+}
+
+// orderSynonyms sorts synonyms by word count, longest first, so "senior
+// vice president" wins over "vice president"
+func orderSynonyms(m map[string]string) []synonym {
+	ordered := make([]synonym, 0, len(m))
+	for variant, canonical := range m {
+		ordered = append(ordered, synonym{variant, canonical})
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		wi, wj := len(strings.Fields(ordered[i].variant)), len(strings.Fields(ordered[j].variant))
+		if wi != wj {
+			return wi > wj
+		}
+		return ordered[i].variant < ordered[j].variant
+	})
+	return ordered
+}
+
+// NormalizeJobTitle canonicalizes common variants of a job title, so "VP of
+// Sales", "Vice President, Sales" and "VP Sales" all become "vp sales", and
+// extracts its seniority level. Anything after " at " or "@" is treated as
+// the company and dropped.
+func NormalizeJobTitle(raw string) (string, Seniority) {
+	title := strings.ToLower(raw)
+	for _, sep := range []string{" at ", "@"} {
+		if idx := strings.Index(title, sep); idx > 0 {
+			title = title[:idx]
+		}
+	}
+
+	synonymsMu.RLock()
+	replaced := replaceSynonyms(words(title), synonyms)
+	synonymsMu.RUnlock()
+
+	var kept []string
+	for _, word := range replaced {
+		if !fillerWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	normalized := strings.Join(kept, " ")
+
+	return normalized, seniorityOf(kept)
+}
+
+// replaceSynonyms walks the title words once, replacing the longest variant
+// that starts at each position with its canonical words
+func replaceSynonyms(titleWords []string, ordered []synonym) []string {
+	var out []string
+	for i := 0; i < len(titleWords); {
+		matched := false
+		for _, s := range ordered {
+			variant := strings.Fields(s.variant)
+			if i+len(variant) > len(titleWords) || !equalWords(titleWords[i:i+len(variant)], variant) {
+				continue
+			}
+
+			out = append(out, strings.Fields(s.canonical)...)
+			i += len(variant)
+			matched = true
+			break
+		}
+
+		if !matched {
+			out = append(out, titleWords[i])
+			i++
+		}
+	}
+	return out
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// seniorityOf returns the most senior level matched by the title words
+func seniorityOf(titleWords []string) Seniority {
+	present := make(map[string]bool, len(titleWords))
+	for _, word := range titleWords {
+		present[word] = true
+	}
+
+	for _, level := range seniorityKeywords {
+		for _, keyword := range level.keywords {
+			if present[keyword] {
+				return level.level
+			}
+		}
+	}
+
+	return SeniorityIC
+}
+
+// words lowercases text and splits it into letter/digit runs
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+}
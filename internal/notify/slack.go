@@ -0,0 +1,245 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/config"
+)
+
+const (
+	defaultSlackAPIURL = "https://slack.com/api"
+	slackPollInterval  = 10 * time.Second
+)
+
+var (
+	// ErrAcknowledgmentTimeout is returned when nobody replies before the timeout
+	ErrAcknowledgmentTimeout = errors.New("timed out waiting for acknowledgment")
+
+	// ErrRepliesUnsupported is returned when no bot token is configured, since
+	// incoming webhooks can post messages but cannot read replies
+	ErrRepliesUnsupported = errors.New("reading replies requires a Slack bot token and channel")
+)
+
+// SlackNotifier posts messages to Slack and reads operator replies
+type SlackNotifier struct {
+	webhookURL   string
+	token        string
+	channel      string
+	apiURL       string
+	client       *http.Client
+	pollInterval time.Duration
+}
+
+// NewSlackNotifier creates a notifier that posts to the given webhook URL and,
+// when a bot token is configured, reads thread replies through the Web API
+func NewSlackNotifier(webhookURL string, cfg config.SlackConfig) *SlackNotifier {
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultSlackAPIURL
+	}
+
+	return &SlackNotifier{
+		webhookURL:   webhookURL,
+		token:        cfg.BotToken,
+		channel:      cfg.Channel,
+		apiURL:       strings.TrimRight(apiURL, "/"),
+		client:       &http.Client{Timeout: 15 * time.Second},
+		pollInterval: slackPollInterval,
+	}
+}
+
+// IsSlackWebhook reports whether a webhook URL points at Slack
+func IsSlackWebhook(webhookURL string) bool {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return false
+	}
+	return u.Host == "hooks.slack.com"
+}
+
+// Notify posts a plain text message
+func (n *SlackNotifier) Notify(ctx context.Context, text string) error {
+	if n.token != "" && n.channel != "" {
+		_, err := n.postMessage(ctx, map[string]interface{}{"text": text})
+		return err
+	}
+
+	if n.webhookURL == "" {
+		return fmt.Errorf("no Slack webhook URL or bot token configured")
+	}
+
+	return n.postJSON(ctx, n.webhookURL, map[string]interface{}{"text": text}, nil)
+}
+
+// WaitForAcknowledgment posts a prompt asking the operator for input and polls
+// the message thread until someone replies or the timeout expires. It returns
+// the text of the first reply.
+func (n *SlackNotifier) WaitForAcknowledgment(ctx context.Context, prompt string, timeoutMinutes int) (string, error) {
+	if n.token == "" || n.channel == "" {
+		// Still alert the operator even though we cannot read the answer
+		if n.webhookURL != "" {
+			_ = n.Notify(ctx, prompt)
+		}
+		return "", ErrRepliesUnsupported
+	}
+
+	threadTS, err := n.postMessage(ctx, map[string]interface{}{
+		"text": prompt,
+		"blocks": []map[string]interface{}{
+			{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": prompt},
+			},
+			{
+				"type":     "input",
+				"block_id": "solution",
+				"label":    map[string]string{"type": "plain_text", "text": "Solution"},
+				"element": map[string]interface{}{
+					"type":      "plain_text_input",
+					"action_id": "solution_text",
+				},
+			},
+			{
+				"type": "context",
+				"elements": []map[string]string{
+					{"type": "mrkdwn", "text": "Reply in this thread with the solution (or `done` once solved in the browser)."},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if timeoutMinutes <= 0 {
+		timeoutMinutes = 10
+	}
+	deadline := time.NewTimer(time.Duration(timeoutMinutes) * time.Minute)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(n.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-deadline.C:
+			return "", ErrAcknowledgmentTimeout
+		case <-ticker.C:
+			reply, err := n.firstReply(ctx, threadTS)
+			if err != nil {
+				return "", err
+			}
+			if reply != "" {
+				return reply, nil
+			}
+		}
+	}
+}
+
+// postMessage sends a chat.postMessage request and returns the message timestamp
+func (n *SlackNotifier) postMessage(ctx context.Context, payload map[string]interface{}) (string, error) {
+	payload["channel"] = n.channel
+
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := n.postJSON(ctx, n.apiURL+"/chat.postMessage", payload, &resp); err != nil {
+		return "", err
+	}
+	if !resp.OK {
+		return "", fmt.Errorf("slack chat.postMessage failed: %s", resp.Error)
+	}
+
+	return resp.TS, nil
+}
+
+// firstReply returns the first human reply in a thread, or "" if there is none yet
+func (n *SlackNotifier) firstReply(ctx context.Context, threadTS string) (string, error) {
+	query := url.Values{"channel": {n.channel}, "ts": {threadTS}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.apiURL+"/conversations.replies?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build Slack request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+n.token)
+
+	httpResp, err := n.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to poll Slack replies: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	var resp struct {
+		OK       bool   `json:"ok"`
+		Error    string `json:"error"`
+		Messages []struct {
+			TS    string `json:"ts"`
+			Text  string `json:"text"`
+			BotID string `json:"bot_id"`
+		} `json:"messages"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return "", fmt.Errorf("failed to decode Slack replies: %w", err)
+	}
+	if !resp.OK {
+		return "", fmt.Errorf("slack conversations.replies failed: %s", resp.Error)
+	}
+
+	for _, msg := range resp.Messages {
+		if msg.TS == threadTS || msg.BotID != "" {
+			continue
+		}
+		if text := strings.TrimSpace(msg.Text); text != "" {
+			return text, nil
+		}
+	}
+
+	return "", nil
+}
+
+// postJSON posts a JSON payload and optionally decodes the JSON response
+func (n *SlackNotifier) postJSON(ctx context.Context, endpoint string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if n.token != "" && endpoint != n.webhookURL {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode Slack response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+)
+
+// mockSlack serves chat.postMessage and conversations.replies, answering the
+// thread after a number of empty polls
+type mockSlack struct {
+	mu      sync.Mutex
+	posted  []map[string]interface{}
+	polls   int
+	replyOn int
+	reply   string
+}
+
+func (m *mockSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "not_authed"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/chat.postMessage":
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		m.posted = append(m.posted, payload)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "ts": "1700000000.000100"})
+	case "/conversations.replies":
+		m.polls++
+		messages := []map[string]string{{"ts": r.URL.Query().Get("ts"), "text": "prompt"}}
+		if m.polls >= m.replyOn {
+			messages = append(messages,
+				map[string]string{"ts": "1700000001.000100", "text": "bot echo", "bot_id": "B1"},
+				map[string]string{"ts": "1700000002.000100", "text": "  " + m.reply + "  "},
+			)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "messages": messages})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestSlack(t *testing.T, handler http.Handler, token string) (*SlackNotifier, *httptest.Server) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	n := NewSlackNotifier(server.URL+"/webhook", config.SlackConfig{BotToken: token, Channel: "C123", APIURL: server.URL + "/"})
+	n.pollInterval = 10 * time.Millisecond
+	return n, server
+}
+
+func TestWaitForAcknowledgmentReturnsFirstHumanReply(t *testing.T) {
+	mock := &mockSlack{replyOn: 3, reply: "482913"}
+	n, _ := newTestSlack(t, mock, "xoxb-test")
+
+	reply, err := n.WaitForAcknowledgment(context.Background(), "Solve the CAPTCHA", 1)
+	if err != nil {
+		t.Fatalf("WaitForAcknowledgment: %v", err)
+	}
+	if reply != "482913" {
+		t.Errorf("reply = %q, want the trimmed human reply", reply)
+	}
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if mock.polls != 3 {
+		t.Errorf("polled %d times, want 3", mock.polls)
+	}
+	if len(mock.posted) != 1 {
+		t.Fatalf("posted %d messages, want 1", len(mock.posted))
+	}
+	payload := mock.posted[0]
+	if payload["channel"] != "C123" || payload["text"] != "Solve the CAPTCHA" {
+		t.Errorf("payload channel/text = %v/%v", payload["channel"], payload["text"])
+	}
+	if !strings.Contains(mustJSON(t, payload["blocks"]), `"type":"plain_text_input"`) {
+		t.Errorf("payload blocks have no text input: %v", payload["blocks"])
+	}
+}
+
+func TestWaitForAcknowledgmentStopsOnCancel(t *testing.T) {
+	n, _ := newTestSlack(t, &mockSlack{replyOn: 1 << 30}, "xoxb-test")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := n.WaitForAcknowledgment(ctx, "Enter the 2FA code", 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForAcknowledgmentAPIError(t *testing.T) {
+	n, _ := newTestSlack(t, &mockSlack{}, "xoxb-wrong")
+
+	_, err := n.WaitForAcknowledgment(context.Background(), "prompt", 1)
+	if err == nil || !strings.Contains(err.Error(), "not_authed") {
+		t.Errorf("err = %v, want the Slack API error", err)
+	}
+}
+
+func TestWaitForAcknowledgmentWithoutBotToken(t *testing.T) {
+	var webhookText string
+	n, _ := newTestSlack(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		webhookText = payload["text"]
+	}), "")
+
+	if _, err := n.WaitForAcknowledgment(context.Background(), "Solve the CAPTCHA", 1); !errors.Is(err, ErrRepliesUnsupported) {
+		t.Errorf("err = %v, want ErrRepliesUnsupported", err)
+	}
+	if webhookText != "Solve the CAPTCHA" {
+		t.Errorf("webhook text = %q, want the prompt posted anyway", webhookText)
+	}
+}
+
+func TestNotifyNon2xx(t *testing.T) {
+	n, _ := newTestSlack(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}), "")
+
+	if err := n.Notify(context.Background(), "hello"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Notify = %v, want a status 403 error", err)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(data)
+}
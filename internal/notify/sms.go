@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNoSMSCode is returned when no SMS verification code was provided
+var ErrNoSMSCode = errors.New("no SMS verification code provided")
+
+type smsCodeRequest struct {
+	Email string `json:"email"`
+}
+
+type smsCodeResponse struct {
+	Code string `json:"code"`
+}
+
+// RequestSMSCode posts the account email to an SMS webhook and waits up to
+// timeout for it to respond with the verification code LinkedIn texted
+func RequestSMSCode(ctx context.Context, webhookURL, email string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(smsCodeRequest{Email: email})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode SMS request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build SMS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", ErrAcknowledgmentTimeout
+		}
+		return "", fmt.Errorf("failed to post to SMS webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("SMS webhook returned status %d", resp.StatusCode)
+	}
+
+	var out smsCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode SMS webhook response: %w", err)
+	}
+
+	code := strings.TrimSpace(out.Code)
+	if code == "" {
+		return "", ErrNoSMSCode
+	}
+
+	return code, nil
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestSMSCode(t *testing.T) {
+	var gotEmail, gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req smsCodeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		gotEmail, gotContentType = req.Email, r.Header.Get("Content-Type")
+
+		// Like a webhook waiting for the text message to arrive
+		time.Sleep(500 * time.Millisecond)
+		json.NewEncoder(w).Encode(smsCodeResponse{Code: " 123456\n"})
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	code, err := RequestSMSCode(context.Background(), server.URL, "jane@example.com", 3*time.Minute)
+	if err != nil {
+		t.Fatalf("RequestSMSCode: %v", err)
+	}
+	if code != "123456" {
+		t.Errorf("code = %q, want 123456", code)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("returned after %v, before the webhook answered", elapsed)
+	}
+	if gotEmail != "jane@example.com" || !strings.HasPrefix(gotContentType, "application/json") {
+		t.Errorf("webhook got email %q, content type %q", gotEmail, gotContentType)
+	}
+}
+
+func TestRequestSMSCodeFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		timeout time.Duration
+		wantErr error  // checked with errors.Is when set
+		wantMsg string // substring of the error otherwise
+	}{
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(300 * time.Millisecond)
+			},
+			timeout: 100 * time.Millisecond,
+			wantErr: ErrAcknowledgmentTimeout,
+		},
+		{
+			name: "empty code",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"code": "  "}`))
+			},
+			wantErr: ErrNoSMSCode,
+		},
+		{
+			name: "non-2xx status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantMsg: "status 502",
+		},
+		{
+			name: "invalid JSON",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("123456"))
+			},
+			wantMsg: "decode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = time.Minute
+			}
+
+			code, err := RequestSMSCode(context.Background(), server.URL, "jane@example.com", timeout)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequestSMSCode = %q, %v, want %v", code, err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && (err == nil || !strings.Contains(err.Error(), tt.wantMsg)) {
+				t.Errorf("RequestSMSCode = %q, %v, want an error containing %q", code, err, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"fmt"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"linkedin-automation/internal/config"
+)
+
+// SMTPNotifier sends email notifications through an SMTP relay
+type SMTPNotifier struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTPNotifier creates an email notifier
+func NewSMTPNotifier(cfg config.SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+// Enabled reports whether enough settings are present to send email
+func (n *SMTPNotifier) Enabled() bool {
+	return n.cfg.Host != "" && n.cfg.From != "" && len(n.cfg.To) > 0
+}
+
+// SendHTML emails an HTML body to all configured recipients
+func (n *SMTPNotifier) SendHTML(subject, body string) error {
+	if !n.Enabled() {
+		return fmt.Errorf("SMTP notifier is not configured")
+	}
+
+	port := n.cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := n.cfg.Host + ":" + strconv.Itoa(port)
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n\r\n")
+	msg.WriteString(body)
+
+	if err := smtp.SendMail(addr, auth, n.cfg.From, n.cfg.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/scheduler"
+	"linkedin-automation/internal/storage"
+)
+
+// chartWidth is the length of the longest bar in the activity chart
+const chartWidth = 40
+
+// GenerateStatusReport builds a plain text overview of outreach progress,
+// today's rate limit usage, the last 7 days of activity and the next run
+func GenerateStatusReport(store *storage.Storage, cfg *config.Config, sched *scheduler.Service) string {
+	var b strings.Builder
+	now := time.Now()
+
+	fmt.Fprintf(&b, "LinkedIn automation status (%s)\n\n", now.Format("2006-01-02 15:04"))
+
+	b.WriteString("Pipeline\n")
+	if counts, err := store.CountByState(); err != nil {
+		fmt.Fprintf(&b, "  unavailable: %v\n", err)
+	} else {
+		writeRow(&b, "Pending", counts[storage.StateRequested]+counts[storage.StatePending])
+		writeRow(&b, "Accepted", counts[storage.StateAccepted])
+		writeRow(&b, "Messaged", counts[storage.StateMessaged])
+		writeRow(&b, "Replied", counts[storage.StateReplied])
+	}
+
+	today := store.GetTodayStats()
+	connectionsPerDay, messagesPerDay := cfg.DailyLimits(store.SafeModeActive())
+	if store.SafeModeActive() {
+		b.WriteString("\nToday's limits (safe mode)\n")
+	} else {
+		b.WriteString("\nToday's limits\n")
+	}
+	fmt.Fprintf(&b, "  %-12s %d / %d\n", "Connections", today.ConnectionsSent, connectionsPerDay)
+	fmt.Fprintf(&b, "  %-12s %d / %d\n", "Messages", today.MessagesSent, messagesPerDay)
+
+	b.WriteString("\nLast 7 days (connections + messages)\n")
+	if points, err := store.GetDailyStats(now.AddDate(0, 0, -6), now); err != nil {
+		fmt.Fprintf(&b, "  unavailable: %v\n", err)
+	} else {
+		b.WriteString(BarChart(dailyActivity(points), chartWidth))
+	}
+
+	b.WriteString("\nTop job titles connected\n")
+	if titles, err := store.GetTopConnectedJobTitles(5); err != nil {
+		fmt.Fprintf(&b, "  unavailable: %v\n", err)
+	} else if len(titles) == 0 {
+		b.WriteString("  none yet\n")
+	} else {
+		for i, t := range titles {
+			fmt.Fprintf(&b, "  %d. %s (%d)\n", i+1, t.JobTitle, t.Count)
+		}
+	}
+
+	b.WriteString("\nTime to accept (hours)\n")
+	if stats, err := store.GetAcceptanceResponseTimeStats(); err != nil {
+		fmt.Fprintf(&b, "  unavailable: %v\n", err)
+	} else if stats.Count == 0 {
+		b.WriteString("  none yet\n")
+	} else {
+		fmt.Fprintf(&b, "  mean %.1f, median %.1f, p90 %.1f, p99 %.1f (%d accepted)\n",
+			stats.Mean, stats.Median, stats.P90, stats.P99, stats.Count)
+	}
+
+	next := sched.GetNextRunTime()
+	if !next.After(now) {
+		fmt.Fprintf(&b, "\nNext run: now (within active hours)\n")
+	} else {
+		fmt.Fprintf(&b, "\nNext run: %s\n", next.Format("Mon 2006-01-02 15:04"))
+	}
+
+	return b.String()
+}
+
+// Bar is one labelled value in a bar chart
+type Bar struct {
+	Label string
+	Value int
+}
+
+// BarChart renders bars scaled so the largest value spans width characters.
+// Non-zero values always get at least one character.
+func BarChart(bars []Bar, width int) string {
+	max := 0
+	labelWidth := 0
+	for _, bar := range bars {
+		if bar.Value > max {
+			max = bar.Value
+		}
+		if len(bar.Label) > labelWidth {
+			labelWidth = len(bar.Label)
+		}
+	}
+
+	var b strings.Builder
+	for _, bar := range bars {
+		fmt.Fprintf(&b, "  %-*s | %s %d\n", labelWidth, bar.Label, strings.Repeat("#", barLength(bar.Value, max, width)), bar.Value)
+	}
+
+	return b.String()
+}
+
+// barLength scales value against max to at most width characters
+func barLength(value, max, width int) int {
+	if value <= 0 || max <= 0 {
+		return 0
+	}
+
+	length := value * width / max
+	if length == 0 {
+		length = 1
+	}
+	return length
+}
+
+// dailyActivity converts daily stats into chart bars
+func dailyActivity(points []storage.DailyStatPoint) []Bar {
+	bars := make([]Bar, 0, len(points))
+	for _, p := range points {
+		label := p.Date
+		if t, err := time.Parse("2006-01-02", p.Date); err == nil {
+			label = t.Format("Mon 01-02")
+		}
+		bars = append(bars, Bar{Label: label, Value: p.ConnectionsSent + p.MessagesSent})
+	}
+	return bars
+}
+
+// writeRow writes an indented label/count line
+func writeRow(b *strings.Builder, label string, count int) {
+	fmt.Fprintf(b, "  %-12s %d\n", label, count)
+}
@@ -0,0 +1,162 @@
+package report
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/scheduler"
+	"linkedin-automation/internal/storage"
+)
+
+func TestBarChartScaling(t *testing.T) {
+	chart := BarChart([]Bar{
+		{Label: "Mon", Value: 20},
+		{Label: "Tue", Value: 10},
+		{Label: "Wed", Value: 1},
+		{Label: "Thursday", Value: 0},
+	}, 40)
+
+	want := "" +
+		"  Mon      | " + strings.Repeat("#", 40) + " 20\n" +
+		"  Tue      | " + strings.Repeat("#", 20) + " 10\n" +
+		"  Wed      | ## 1\n" +
+		"  Thursday |  0\n"
+	if chart != want {
+		t.Errorf("BarChart =\n%s\nwant\n%s", chart, want)
+	}
+}
+
+func TestBarLength(t *testing.T) {
+	tests := []struct {
+		value, max, width int
+		want              int
+	}{
+		{100, 100, 40, 40},
+		{50, 100, 40, 20},
+		{1, 1000, 40, 1}, // non-zero values stay visible
+		{0, 100, 40, 0},
+		{0, 0, 40, 0},
+		{-3, 10, 40, 0},
+	}
+
+	for _, tt := range tests {
+		if got := barLength(tt.value, tt.max, tt.width); got != tt.want {
+			t.Errorf("barLength(%d, %d, %d) = %d, want %d", tt.value, tt.max, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestBarChartZeroActivity(t *testing.T) {
+	chart := BarChart(dailyActivity([]storage.DailyStatPoint{
+		{Date: "2024-03-04"},
+		{Date: "2024-03-05"},
+	}), chartWidth)
+
+	if strings.Contains(chart, "#") {
+		t.Errorf("chart without activity has bars:\n%s", chart)
+	}
+	if !strings.Contains(chart, "Mon 03-04 |  0") || !strings.Contains(chart, "Tue 03-05 |  0") {
+		t.Errorf("chart is missing zero rows:\n%s", chart)
+	}
+	if got := BarChart(nil, chartWidth); got != "" {
+		t.Errorf("BarChart(nil) = %q, want empty", got)
+	}
+}
+
+func TestDailyActivity(t *testing.T) {
+	bars := dailyActivity([]storage.DailyStatPoint{
+		{Date: "2024-03-01", ConnectionsSent: 3, MessagesSent: 2, Replies: 5},
+		{Date: "not a date", ConnectionsSent: 1},
+	})
+
+	if len(bars) != 2 || bars[0] != (Bar{Label: "Fri 03-01", Value: 5}) || bars[1] != (Bar{Label: "not a date", Value: 1}) {
+		t.Errorf("bars = %+v, want connections + messages labelled by weekday", bars)
+	}
+}
+
+// newReportConfig limits outreach to 20 connections and 10 messages a day
+func newReportConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.RateLimits.Connections.PerDay = 20
+	cfg.RateLimits.Messages.PerDay = 10
+	cfg.Scheduling.ActiveHours.Start = 9
+	cfg.Scheduling.ActiveHours.End = 17
+	return cfg
+}
+
+func newTestStore(t *testing.T) *storage.Storage {
+	t.Helper()
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestGenerateStatusReportWithoutActivity(t *testing.T) {
+	cfg := newReportConfig()
+	report := GenerateStatusReport(newTestStore(t), cfg, scheduler.New(cfg))
+
+	for _, want := range []string{
+		"  Pending      0\n",
+		"  Accepted     0\n",
+		"  Connections  0 / 20\n",
+		"  Messages     0 / 10\n",
+		"Top job titles connected\n  none yet\n",
+		"Time to accept (hours)\n  none yet\n",
+		"\nNext run: ",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "unavailable") || strings.Contains(report, "#") {
+		t.Errorf("empty store report has errors or bars:\n%s", report)
+	}
+	if got := strings.Count(report, " |  0\n"); got != 7 {
+		t.Errorf("chart has %d zero days, want 7:\n%s", got, report)
+	}
+}
+
+func TestGenerateStatusReport(t *testing.T) {
+	store := newTestStore(t)
+	for _, p := range []struct{ username, title string }{{"jane", "CTO"}, {"john", "CTO"}, {"joan", "Engineer"}} {
+		profile := &storage.Profile{ProfileURL: "https://www.linkedin.com/in/" + p.username, Name: p.username, JobTitle: p.title}
+		if _, err := store.SaveProfile(profile); err != nil {
+			t.Fatalf("SaveProfile: %v", err)
+		}
+		if err := store.SaveConnectionRequest(&storage.ConnectionRequest{ProfileURL: profile.ProfileURL, Status: "pending"}); err != nil {
+			t.Fatalf("SaveConnectionRequest: %v", err)
+		}
+		for _, step := range [][2]storage.ConnectionState{{storage.StateDiscovered, storage.StateQueued}, {storage.StateQueued, storage.StateRequested}} {
+			if err := store.TransitionState(profile.ProfileURL, step[0], step[1]); err != nil {
+				t.Fatalf("TransitionState: %v", err)
+			}
+		}
+	}
+	for _, username := range []string{"jane", "john"} {
+		if err := store.TransitionState("https://www.linkedin.com/in/"+username, storage.StateRequested, storage.StateAccepted); err != nil {
+			t.Fatalf("TransitionState: %v", err)
+		}
+	}
+
+	cfg := newReportConfig()
+	report := GenerateStatusReport(store, cfg, scheduler.New(cfg))
+
+	for _, want := range []string{
+		"  Pending      1\n",
+		"  Accepted     2\n",
+		"  Connections  3 / 20\n",
+		"Top job titles connected\n  1. CTO (2)\n\n",
+		strings.Repeat("#", chartWidth) + " 3\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"linkedin-automation/internal/config"
 	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
 
 	"github.com/sirupsen/logrus"
 )
@@ -106,6 +107,21 @@ func (s *Service) GetNextRunTime() time.Time {
 		s.cfg.Scheduling.ActiveHours.Start, 0, 0, 0, tomorrow.Location())
 }
 
+// IsWeeklyReportDue reports whether the weekly report should be generated.
+// Reports are produced once every Sunday, regardless of active hours.
+func (s *Service) IsWeeklyReportDue(lastReport time.Time) bool {
+	if !s.cfg.Scheduling.WeeklyReport {
+		return false
+	}
+
+	now := time.Now()
+	if now.Weekday() != time.Sunday {
+		return false
+	}
+
+	return lastReport.Format("2006-01-02") != now.Format("2006-01-02")
+}
+
 // WaitUntilActiveHours blocks until the next active time
 func (s *Service) WaitUntilActiveHours() {
 	if s.ShouldRun() {
@@ -174,3 +190,29 @@ func (s *Service) GetBreakDuration() time.Duration {
 	// End of day: until next active hour
 	return s.GetTimeUntilNextActiveHour()
 }
+
+// lowAcceptanceRatio is the share of the average acceptance rate below which
+// an hour counts as a poor time to send connection requests
+const lowAcceptanceRatio = 0.5
+
+// IsLowAcceptanceHour reports whether requests sent during hour have been
+// accepted at under half the average rate across all hours. Hours with fewer
+// than minSamples requests are never considered low.
+func IsLowAcceptanceHour(rates []storage.HourlyResponseRate, hour, minSamples int) (bool, float64, float64) {
+	sent, accepted := 0, 0
+	var current *storage.HourlyResponseRate
+	for i, rate := range rates {
+		sent += rate.Sent
+		accepted += rate.Accepted
+		if rate.Hour == hour {
+			current = &rates[i]
+		}
+	}
+
+	if current == nil || current.Sent < minSamples || sent == 0 {
+		return false, 0, 0
+	}
+
+	average := float64(accepted) / float64(sent) * 100
+	return current.AcceptanceRate < average*lowAcceptanceRatio, current.AcceptanceRate, average
+}
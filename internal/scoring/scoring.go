@@ -0,0 +1,108 @@
+package scoring
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/normalize"
+	"linkedin-automation/internal/storage"
+)
+
+// Dimension weights for MatchICP; they sum to 1.0
+const (
+	industryWeight    = 0.3
+	seniorityWeight   = 0.3
+	locationWeight    = 0.2
+	companySizeWeight = 0.2
+)
+
+// Seniority levels accepted in ICPConfig.Seniorities
+const (
+	SeniorityIC       = string(normalize.SeniorityIC)
+	SeniorityManager  = string(normalize.SeniorityManager)
+	SeniorityDirector = string(normalize.SeniorityDirector)
+	SeniorityVP       = string(normalize.SeniorityVP)
+	SeniorityCSuite   = string(normalize.SeniorityCLevel)
+)
+
+// MatchICP scores how well a profile fits the ideal customer profile, from
+// 0.0 to 1.0. Each matched dimension adds its weight; dimensions the ICP
+// leaves empty count as matched.
+func MatchICP(profile *storage.Profile, icp config.ICPConfig) float64 {
+	score := 0.0
+
+	if len(icp.Industries) == 0 || containsAny(industryText(profile), icp.Industries) {
+		score += industryWeight
+	}
+
+	if len(icp.Seniorities) == 0 || containsFold(icp.Seniorities, Seniority(profile.JobTitle)) {
+		score += seniorityWeight
+	}
+
+	if len(icp.Locations) == 0 || containsAny(profile.Location, icp.Locations) {
+		score += locationWeight
+	}
+
+	// Company size is only known for profiles enriched with
+	// search.infer_company_size
+	if len(icp.CompanySizes) == 0 || containsFold(icp.CompanySizes, string(profile.CompanySizeBucket)) {
+		score += companySizeWeight
+	}
+
+	return score
+}
+
+// ScoreProfile returns the profile's ICP match decayed for the time since it
+// was discovered
+func ScoreProfile(profile *storage.Profile, icp config.ICPConfig, weights config.ScoringWeights, now time.Time) float64 {
+	return ApplyDecay(MatchICP(profile, icp), profile.DiscoveredAt, weights.Decay, now)
+}
+
+// ApplyDecay scales a base score by 2^(-days since discovery / HalfLifeDays)
+func ApplyDecay(base float64, discoveredAt time.Time, decay config.ScoreDecay, now time.Time) float64 {
+	if decay.HalfLifeDays <= 0 || discoveredAt.IsZero() {
+		return base
+	}
+
+	days := now.Sub(discoveredAt).Hours() / 24
+	if days <= 0 {
+		return base
+	}
+
+	return base * math.Pow(2, -days/decay.HalfLifeDays)
+}
+
+// Seniority infers the seniority level from a job title, defaulting to IC
+func Seniority(jobTitle string) string {
+	_, level := normalize.NormalizeJobTitle(jobTitle)
+	return string(level)
+}
+
+// industryText is the profile text searched for industry keywords
+func industryText(profile *storage.Profile) string {
+	return strings.Join([]string{profile.Company, profile.JobTitle, profile.Headline, profile.Summary, profile.Keywords}, " ")
+}
+
+// containsAny reports whether text contains any of the terms, ignoring case
+func containsAny(text string, terms []string) bool {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term != "" && strings.Contains(text, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+// fixtureProfiles are the profiles scored against fixtureICP
+var fixtureProfiles = map[string]*storage.Profile{
+	"perfect fit": {
+		JobTitle:          "VP of Engineering",
+		Company:           "Acme Software",
+		Headline:          "Building developer tools",
+		Location:          "San Francisco Bay Area",
+		CompanySizeBucket: storage.CompanySizeMidMarket,
+	},
+	"industry and seniority only": {
+		JobTitle:          "Chief Executive Officer",
+		Company:           "Fintech Labs",
+		Location:          "Berlin, Germany",
+		CompanySizeBucket: storage.CompanySizeEnterprise,
+	},
+	"industry from summary": {
+		JobTitle:          "Software Engineer",
+		Company:           "Globex",
+		Summary:           "Ten years in SaaS infrastructure",
+		Location:          "new york, NY",
+		CompanySizeBucket: storage.CompanySizeSMB,
+	},
+	"no match": {
+		JobTitle: "Barista",
+		Company:  "Corner Coffee",
+		Location: "Lisbon, Portugal",
+	},
+}
+
+var fixtureICP = config.ICPConfig{
+	Industries:   []string{"software", "SaaS", "fintech"},
+	CompanySizes: []string{"mid-market", "SMB"},
+	Seniorities:  []string{"VP", "C-Suite"},
+	Locations:    []string{"San Francisco", "New York"},
+}
+
+func TestMatchICP(t *testing.T) {
+	tests := []struct {
+		profile string
+		icp     config.ICPConfig
+		want    float64
+	}{
+		{"perfect fit", fixtureICP, 1.0},
+		{"industry and seniority only", fixtureICP, 0.6},
+		{"industry from summary", fixtureICP, 0.7},
+		{"no match", fixtureICP, 0},
+		{"no match", config.ICPConfig{}, 1.0},
+		{"no match", config.ICPConfig{Industries: []string{"software"}}, 0.7},
+		{"industry and seniority only", config.ICPConfig{Seniorities: []string{" c-suite "}}, 1.0},
+		{"perfect fit", config.ICPConfig{Locations: []string{" ", "Berlin"}}, 0.8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			got := MatchICP(fixtureProfiles[tt.profile], tt.icp)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("MatchICP(%+v) = %.2f, want %.2f", tt.icp, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchICPUnknownCompanySize(t *testing.T) {
+	profile := *fixtureProfiles["perfect fit"]
+	profile.CompanySizeBucket = ""
+
+	if got := MatchICP(&profile, fixtureICP); math.Abs(got-0.8) > 1e-9 {
+		t.Errorf("MatchICP without a company size = %.2f, want 0.80", got)
+	}
+}
+
+func TestApplyDecay(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	halfLife := config.ScoreDecay{HalfLifeDays: 10}
+
+	tests := []struct {
+		name         string
+		discoveredAt time.Time
+		decay        config.ScoreDecay
+		want         float64
+	}{
+		{"one half-life", now.AddDate(0, 0, -10), halfLife, 0.4},
+		{"two half-lives", now.AddDate(0, 0, -20), halfLife, 0.2},
+		{"discovered now", now, halfLife, 0.8},
+		{"discovered in the future", now.Add(time.Hour), halfLife, 0.8},
+		{"unknown discovery time", time.Time{}, halfLife, 0.8},
+		{"decay disabled", now.AddDate(0, 0, -10), config.ScoreDecay{}, 0.8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyDecay(0.8, tt.discoveredAt, tt.decay, now); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ApplyDecay = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreProfile(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	profile := *fixtureProfiles["industry and seniority only"]
+	profile.DiscoveredAt = now.AddDate(0, 0, -30)
+
+	weights := config.ScoringWeights{Decay: config.ScoreDecay{HalfLifeDays: 30}}
+	if got := ScoreProfile(&profile, fixtureICP, weights, now); math.Abs(got-0.3) > 1e-9 {
+		t.Errorf("ScoreProfile = %.4f, want the 0.60 ICP match halved", got)
+	}
+}
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+
+	"linkedin-automation/internal/logger"
+
+	"github.com/go-rod/rod"
+)
+
+// maxSummaryLength caps the stored About text, in characters
+const maxSummaryLength = 2000
+
+// ErrLowKeywordDensity is returned by EnrichProfile when the About section
+// mentions too few of Search.RelevantKeywords
+var ErrLowKeywordDensity = errors.New("summary keyword density below minimum")
+
+// extractAbout expands the About section if it's truncated and returns its
+// full text, or "" when the profile has no About section
+func (s *Service) extractAbout(ctx context.Context, page *rod.Page) string {
+	log := logger.FromContext(ctx)
+
+	var section *rod.Element
+	for _, selector := range s.cfg.Selectors.ProfileAboutSection {
+		if has, element, err := page.Has(selector); err == nil && has {
+			section = element
+			break
+		}
+	}
+	if section == nil {
+		return ""
+	}
+
+	for _, selector := range s.cfg.Selectors.ProfileAboutSeeMore {
+		has, button, err := section.Has(selector)
+		if err != nil || !has {
+			continue
+		}
+		if visible, _ := button.Visible(); !visible {
+			continue
+		}
+
+		if err := s.stealth.HumanClick(ctx, button); err != nil {
+			log.Debugf("Failed to expand About section: %v", err)
+		}
+		break
+	}
+
+	text := firstTextIn(section, s.cfg.Selectors.ProfileAboutText)
+	return truncateRunes(text, maxSummaryLength)
+}
+
+// SummaryKeywords returns the relevant keywords mentioned in a summary and
+// their density: the share of summary words that belong to a keyword match
+func SummaryKeywords(summary string, keywords []string) ([]string, float64) {
+	words := tokenize(summary)
+	if len(words) == 0 {
+		return nil, 0
+	}
+
+	var matched []string
+	matchedWords := 0
+	for _, keyword := range keywords {
+		phrase := tokenize(keyword)
+		if len(phrase) == 0 {
+			continue
+		}
+
+		count := countPhrase(words, phrase)
+		if count > 0 {
+			matched = append(matched, keyword)
+			matchedWords += count * len(phrase)
+		}
+	}
+
+	return matched, float64(matchedWords) / float64(len(words))
+}
+
+// tokenize lowercases text and splits it into words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+}
+
+// countPhrase counts occurrences of phrase as consecutive words
+func countPhrase(words, phrase []string) int {
+	count := 0
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, word := range phrase {
+			if words[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			count++
+		}
+	}
+	return count
+}
+
+// truncateRunes shortens text to at most max characters
+func truncateRunes(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:max]))
+}
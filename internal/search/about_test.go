@@ -0,0 +1,76 @@
+package search
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/stealth"
+)
+
+const fullAbout = "I lead platform engineering at Acme. We build Kubernetes tooling in Go and run machine learning pipelines."
+
+// aboutProfileHTML is a profile page whose About section is truncated until
+// "see more" is clicked
+var aboutProfileHTML = `<html><body>
+<section>
+  <div id="about"></div>
+  <h2>About</h2>
+  <div class="inline-show-more-text">
+    <span aria-hidden="true">I lead platform engineering at Acme…</span>
+    <button class="inline-show-more-text__button" onclick="this.previousElementSibling.textContent = '` + fullAbout + `'; this.remove()">…see more</button>
+  </div>
+</section>
+<section><div id="experience"></div><h2>Experience</h2></section>
+</body></html>`
+
+func TestExtractAboutExpandsSeeMore(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(aboutProfileHTML); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	cfg := &config.Config{Selectors: config.DefaultSelectors()}
+	s := &Service{cfg: cfg, stealth: stealth.New(cfg, "test")}
+
+	if got := s.extractAbout(context.Background(), page); got != fullAbout {
+		t.Errorf("extractAbout = %q, want the expanded text %q", got, fullAbout)
+	}
+
+	if err := page.SetDocumentContent(`<html><body><section><div id="experience"></div></section></body></html>`); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+	if got := s.extractAbout(context.Background(), page); got != "" {
+		t.Errorf("extractAbout without an About section = %q, want empty", got)
+	}
+}
+
+func TestSummaryKeywords(t *testing.T) {
+	keywords := []string{"Kubernetes", "machine learning", "Rust", " "}
+
+	matched, density := SummaryKeywords(fullAbout, keywords)
+	if want := []string{"Kubernetes", "machine learning"}; !reflect.DeepEqual(matched, want) {
+		t.Errorf("matched = %v, want %v", matched, want)
+	}
+	// 3 of the 17 words belong to a keyword
+	if want := 3.0 / 17; math.Abs(density-want) > 1e-9 {
+		t.Errorf("density = %.4f, want %.4f", density, want)
+	}
+
+	if matched, density := SummaryKeywords("", keywords); matched != nil || density != 0 {
+		t.Errorf("empty summary = %v, %.2f, want no matches", matched, density)
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	long := strings.Repeat("é", maxSummaryLength+10)
+	if got := truncateRunes(long, maxSummaryLength); len([]rune(got)) != maxSummaryLength {
+		t.Errorf("truncated to %d characters, want %d", len([]rune(got)), maxSummaryLength)
+	}
+	if got := truncateRunes("short", maxSummaryLength); got != "short" {
+		t.Errorf("truncateRunes(short) = %q", got)
+	}
+}
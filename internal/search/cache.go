@@ -0,0 +1,105 @@
+package search
+
+import (
+	"sync/atomic"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// searchCacheSize is how many search targets the result cache holds
+const searchCacheSize = 64
+
+// CacheStats reports search result cache usage
+type CacheStats struct {
+	Size   int    `json:"size"`
+	Hits   uint64 `json:"cache_hits_total"`
+	Misses uint64 `json:"cache_misses_total"`
+}
+
+// SearchResultCache keeps the profiles found for each search target so a
+// target run again before the TTL expires isn't searched twice
+type SearchResultCache struct {
+	entries *expirable.LRU[string, []*storage.Profile]
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+// NewSearchResultCache creates a cache whose entries expire after ttl
+func NewSearchResultCache(size int, ttl time.Duration) *SearchResultCache {
+	return &SearchResultCache{
+		entries: expirable.NewLRU[string, []*storage.Profile](size, nil, ttl),
+	}
+}
+
+// Get returns copies of the profiles cached for a target hash
+func (c *SearchResultCache) Get(targetHash string) ([]*storage.Profile, bool) {
+	profiles, ok := c.entries.Get(targetHash)
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return copyProfiles(profiles), true
+}
+
+// Put caches copies of the profiles found for a target hash
+func (c *SearchResultCache) Put(targetHash string, profiles []*storage.Profile) {
+	c.entries.Add(targetHash, copyProfiles(profiles))
+}
+
+// Stats returns the cache size and hit/miss counts
+func (c *SearchResultCache) Stats() CacheStats {
+	return CacheStats{
+		Size:   c.entries.Len(),
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
+
+// copyProfiles copies profiles so later changes by the workflow don't leak
+// into the cache
+func copyProfiles(profiles []*storage.Profile) []*storage.Profile {
+	copied := make([]*storage.Profile, len(profiles))
+	for i, profile := range profiles {
+		p := *profile
+		copied[i] = &p
+	}
+	return copied
+}
+
+// CacheStats reports search result cache usage; zero when the cache is off
+func (s *Service) CacheStats() CacheStats {
+	if s.cache == nil {
+		return CacheStats{}
+	}
+	return s.cache.Stats()
+}
+
+// cachedResults returns the cached profiles for a target, if any
+func (s *Service) cachedResults(target config.SearchTarget) ([]*storage.Profile, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+	return s.cache.Get(TargetHash(target))
+}
+
+// cacheCursor caches a target's results, but only once every page of it was
+// loaded in this run; a search cut short by an error, the quota or a resume
+// would otherwise hide the missing results until the TTL expires
+func (s *Service) cacheCursor(cursor *searchCursor) {
+	if cursor.complete && !cursor.resumed {
+		s.cacheResults(cursor.target, cursor.profiles)
+	}
+}
+
+// cacheResults stores the profiles found for a target
+func (s *Service) cacheResults(target config.SearchTarget, profiles []*storage.Profile) {
+	if s.cache != nil {
+		s.cache.Put(TargetHash(target), profiles)
+	}
+}
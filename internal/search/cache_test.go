@@ -0,0 +1,41 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+func TestCacheCursorOnlyCachesCompleteSearches(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor searchCursor
+		want   bool
+	}{
+		{"all pages loaded", searchCursor{done: true, complete: true}, true},
+		{"stopped early", searchCursor{done: true}, false},
+		{"resumed from a saved page", searchCursor{done: true, complete: true, resumed: true}, false},
+		{"still paging", searchCursor{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{cache: NewSearchResultCache(searchCacheSize, time.Hour)}
+			cursor := tt.cursor
+			cursor.target = config.SearchTarget{JobTitle: "Engineer", Location: "Berlin"}
+			cursor.profiles = []*storage.Profile{{ProfileURL: "https://www.linkedin.com/in/jane"}}
+
+			s.cacheCursor(&cursor)
+
+			profiles, ok := s.cachedResults(cursor.target)
+			if ok != tt.want {
+				t.Fatalf("cached = %v, want %v", ok, tt.want)
+			}
+			if ok && len(profiles) != 1 {
+				t.Errorf("cached %d profiles, want 1", len(profiles))
+			}
+		})
+	}
+}
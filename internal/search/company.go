@@ -0,0 +1,97 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+// companySizeTTL is how long an inferred company size is reused
+const companySizeTTL = 7 * 24 * time.Hour
+
+// employeeCountPattern matches employee counts such as "1,001" in "1,001-5,000 employees"
+var employeeCountPattern = regexp.MustCompile(`\d[\d,]*`)
+
+// InferCompanySize opens a company page and buckets its employee count
+// ("11-50 employees", "10,001+ employees"). Results are cached for 7 days.
+func (s *Service) InferCompanySize(ctx context.Context, companyURL string) (storage.CompanySize, error) {
+	log := logger.FromContext(ctx)
+
+	if size, ok, err := s.store.GetCompanySize(companyURL, companySizeTTL); err != nil {
+		log.Warnf("Failed to read cached company size for %s: %v", companyURL, err)
+	} else if ok {
+		return size, nil
+	}
+
+	if err := s.browser.Navigate(companyURL); err != nil {
+		return storage.CompanySizeUnknown, fmt.Errorf("failed to navigate to company: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	items, err := page.Elements(s.cfg.Selectors.CompanySizeInfo)
+	if err != nil {
+		return storage.CompanySizeUnknown, fmt.Errorf("failed to find company info (selector CompanySizeInfo): %w", err)
+	}
+
+	for _, item := range items {
+		text, err := item.Text()
+		if err != nil || !strings.Contains(strings.ToLower(text), "employees") {
+			continue
+		}
+
+		size := ParseCompanySize(text)
+		if size == storage.CompanySizeUnknown {
+			continue
+		}
+
+		employeeRange := strings.TrimSpace(text)
+		if err := s.store.SaveCompanySize(companyURL, size, employeeRange); err != nil {
+			log.Warnf("Failed to cache company size for %s: %v", companyURL, err)
+		}
+
+		log.Debugf("Company %s has %s (%s)", companyURL, employeeRange, size)
+		return size, nil
+	}
+
+	return storage.CompanySizeUnknown, fmt.Errorf("no employee count on %s", companyURL)
+}
+
+// ParseCompanySize maps LinkedIn's employee range text to a size bucket using
+// the upper end of the range: up to 200 is SMB, up to 5,000 is Mid-Market and
+// anything larger (or "5,001+") is Enterprise
+func ParseCompanySize(text string) storage.CompanySize {
+	counts := employeeCountPattern.FindAllString(text, -1)
+	if len(counts) == 0 {
+		return storage.CompanySizeUnknown
+	}
+
+	upper, err := strconv.Atoi(strings.ReplaceAll(counts[len(counts)-1], ",", ""))
+	if err != nil {
+		return storage.CompanySizeUnknown
+	}
+
+	// "10,001+ employees" has no upper end
+	if len(counts) == 1 && strings.Contains(text, "+") {
+		upper++
+	}
+
+	switch {
+	case upper <= 200:
+		return storage.CompanySizeSMB
+	case upper <= 5000:
+		return storage.CompanySizeMidMarket
+	default:
+		return storage.CompanySizeEnterprise
+	}
+}
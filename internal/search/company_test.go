@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+func TestParseCompanySize(t *testing.T) {
+	tests := []struct {
+		text string
+		want storage.CompanySize
+	}{
+		// Every range LinkedIn shows on company pages
+		{"0-1 employees", storage.CompanySizeSMB},
+		{"2-10 employees", storage.CompanySizeSMB},
+		{"11-50 employees", storage.CompanySizeSMB},
+		{"51-200 employees", storage.CompanySizeSMB},
+		{"201-500 employees", storage.CompanySizeMidMarket},
+		{"501-1,000 employees", storage.CompanySizeMidMarket},
+		{"1,001-5,000 employees", storage.CompanySizeMidMarket},
+		{"5,001-10,000 employees", storage.CompanySizeEnterprise},
+		{"10,001+ employees", storage.CompanySizeEnterprise},
+
+		// Open-ended ranges count as larger than their lower end
+		{"5,000+ employees", storage.CompanySizeEnterprise},
+		{"200+ employees", storage.CompanySizeMidMarket},
+		{"  1001-5000 employees\n", storage.CompanySizeMidMarket},
+		{"Software Development", storage.CompanySizeUnknown},
+		{"", storage.CompanySizeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := ParseCompanySize(tt.text); got != tt.want {
+				t.Errorf("ParseCompanySize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferCompanySizeUsesCache(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	companyURL := "https://www.linkedin.com/company/acme/"
+	if err := store.SaveCompanySize(companyURL, storage.CompanySizeMidMarket, "1,001-5,000 employees"); err != nil {
+		t.Fatalf("SaveCompanySize: %v", err)
+	}
+
+	// Without a browser, a cache miss would panic on navigation
+	s := &Service{cfg: &config.Config{}, store: store}
+	size, err := s.InferCompanySize(context.Background(), companyURL)
+	if err != nil || size != storage.CompanySizeMidMarket {
+		t.Errorf("InferCompanySize = %q, %v, want the cached Mid-Market", size, err)
+	}
+}
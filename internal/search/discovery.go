@@ -0,0 +1,55 @@
+package search
+
+import (
+	"context"
+
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+// maxDiscoveriesPerRun caps how many queued profiles are visited in one run
+const maxDiscoveriesPerRun = 10
+
+// ProcessDiscoveryQueue visits profiles queued from sources other than
+// search, such as "People Also Viewed", and returns them for connecting
+func (s *Service) ProcessDiscoveryQueue(ctx context.Context) ([]*storage.Profile, error) {
+	log := logger.FromContext(ctx)
+
+	entries, err := s.store.GetPendingDiscoveries(maxDiscoveriesPerRun)
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []*storage.Profile
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return profiles, ctx.Err()
+		default:
+		}
+
+		profile, err := s.searchByURL(ctx, entry.ProfileURL, entry.Source)
+		if err != nil {
+			log.Warnf("Failed to visit queued profile %s: %v", entry.ProfileURL, err)
+			gaveUp, err := s.store.RecordDiscoveryFailure(entry.ID)
+			if err != nil {
+				log.Warnf("Failed to record failure for %s: %v", entry.ProfileURL, err)
+			} else if gaveUp {
+				log.Warnf("Giving up on queued profile %s after %d attempts", entry.ProfileURL, storage.MaxDiscoveryAttempts)
+			}
+			continue
+		}
+
+		if err := s.store.MarkDiscoveryProcessed(entry.ID); err != nil {
+			log.Warnf("Failed to mark %s processed: %v", entry.ProfileURL, err)
+		}
+		profiles = append(profiles, profile)
+
+		s.stealth.RandomDelay("action")
+	}
+
+	if len(entries) > 0 {
+		log.Infof("Visited %d of %d queued profiles", len(profiles), len(entries))
+	}
+	return profiles, nil
+}
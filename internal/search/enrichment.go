@@ -0,0 +1,212 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"linkedin-automation/internal/browser"
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+)
+
+// enrichmentPollInterval is how long the workers wait when the queue is empty
+// or enrichment isn't allowed right now
+const enrichmentPollInterval = time.Minute
+
+// ErrEnrichmentDeferred is returned by an EnrichFunc that didn't visit the
+// profile because enrichment isn't allowed right now; the entry stays queued
+var ErrEnrichmentDeferred = errors.New("enrichment deferred")
+
+// EnrichFunc enriches one queued profile
+type EnrichFunc func(ctx context.Context, profile *storage.Profile) error
+
+// queueEnrichment adds a saved search result to the enrichment queue, ahead
+// of the rest when it scores above Enrichment.PriorityScoreThreshold
+func (s *Service) queueEnrichment(ctx context.Context, profile *storage.Profile) {
+	if s.cfg.Enrichment.WorkerCount <= 0 {
+		return
+	}
+
+	priority := storage.EnrichmentPriorityNormal
+	if profile.Score > s.cfg.Enrichment.PriorityScoreThreshold {
+		priority = storage.EnrichmentPriorityHigh
+	}
+
+	if _, err := s.store.QueueEnrichment(profile.ID, priority); err != nil {
+		logger.FromContext(ctx).Warnf("Failed to queue %s for enrichment: %v", profile.ProfileURL, err)
+	}
+}
+
+// StartEnrichmentWorkers drains the enrichment queue with
+// Enrichment.WorkerCount workers until the context is cancelled
+func (s *Service) StartEnrichmentWorkers(ctx context.Context) {
+	RunEnrichmentQueue(ctx, s.store, s.cfg.Enrichment.WorkerCount, s.enrichQueued)
+}
+
+// SetEnrichmentGate makes background enrichment check gate before each
+// profile visit, e.g. for active hours and the dashboard's pause button
+func (s *Service) SetEnrichmentGate(gate func() bool) {
+	s.enrichmentGate = gate
+}
+
+// enrichQueued enriches a queued profile once the browser page is free and
+// enrichment is allowed
+func (s *Service) enrichQueued(ctx context.Context, profile *storage.Profile) error {
+	s.browser.LockPage()
+	defer s.browser.UnlockPage()
+
+	if !s.enrichmentAllowed(ctx) {
+		return ErrEnrichmentDeferred
+	}
+
+	if err := s.browser.Actions().Begin(); err != nil {
+		return err
+	}
+	defer s.browser.Actions().End()
+
+	err := s.EnrichProfile(ctx, profile)
+	if errors.Is(err, ErrLowKeywordDensity) {
+		// The profile was still enriched, it just won't be prioritized
+		return nil
+	}
+	return err
+}
+
+// enrichmentAllowed reports whether the gate allows a visit and this hour's
+// Enrichment.MaxVisitsPerHour budget isn't used up
+func (s *Service) enrichmentAllowed(ctx context.Context) bool {
+	if s.enrichmentGate != nil && !s.enrichmentGate() {
+		return false
+	}
+
+	limit := s.cfg.Enrichment.MaxVisitsPerHour
+	if limit <= 0 {
+		return true
+	}
+
+	visits, err := s.store.CountProfileVisitsSince(storage.VisitPurposeEnrichment, time.Now().Add(-time.Hour))
+	if err != nil {
+		logger.FromContext(ctx).Warnf("Failed to count enrichment visits: %v", err)
+		return false
+	}
+	return visits < limit
+}
+
+// RunEnrichmentQueue processes pending enrichment queue entries with the given
+// number of worker goroutines until the context is cancelled. Entries are
+// taken a batch of workers at a time, highest priority first, so profiles
+// queued with high priority meanwhile are picked up by the next batch. Only
+// process runs on the workers; the queue itself is read and updated here.
+// Failed entries are retried up to storage.MaxEnrichmentAttempts times.
+func RunEnrichmentQueue(ctx context.Context, store *storage.Storage, workers int, process EnrichFunc) {
+	log := logger.FromContext(ctx)
+
+	if workers <= 0 {
+		return
+	}
+
+	for {
+		batch, err := loadEnrichmentBatch(store, workers)
+		if err != nil {
+			log.Warnf("Failed to read enrichment queue: %v", err)
+		}
+
+		if len(batch) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(enrichmentPollInterval):
+			}
+			continue
+		}
+
+		errs := make([]error, len(batch))
+		var wg sync.WaitGroup
+		for i, item := range batch {
+			if item.profile == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(i int, profile *storage.Profile) {
+				defer wg.Done()
+				errs[i] = process(ctx, profile)
+			}(i, item.profile)
+		}
+		wg.Wait()
+
+		deferred := false
+		for i, item := range batch {
+			// Entries interrupted by shutdown stay queued for the next start
+			if ctx.Err() != nil || errors.Is(errs[i], browser.ErrDraining) {
+				continue
+			}
+			if errors.Is(errs[i], ErrEnrichmentDeferred) {
+				deferred = true
+				continue
+			}
+			if errs[i] != nil && item.profile != nil {
+				log.Warnf("Failed to enrich %s: %v", item.profile.ProfileURL, errs[i])
+				store.LogActivityAsync("enrich", item.profile.ProfileURL, "failed", errs[i].Error())
+				gaveUp, err := store.RecordEnrichmentFailure(item.entry.ID)
+				if err != nil {
+					log.Warnf("Failed to record enrichment %d failure: %v", item.entry.ID, err)
+				} else if gaveUp {
+					log.Warnf("Giving up on enriching %s after %d attempts", item.profile.ProfileURL, storage.MaxEnrichmentAttempts)
+				}
+				continue
+			}
+			if err := store.MarkEnrichmentProcessed(item.entry.ID); err != nil {
+				log.Warnf("Failed to mark enrichment %d processed: %v", item.entry.ID, err)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Wait for the gate or the hourly budget instead of polling the page
+		if deferred {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(enrichmentPollInterval):
+			}
+		}
+	}
+}
+
+// enrichmentItem is a queue entry with its profile, nil if the profile was deleted
+type enrichmentItem struct {
+	entry   storage.EnrichmentEntry
+	profile *storage.Profile
+}
+
+// loadEnrichmentBatch returns up to limit pending entries with their profiles
+func loadEnrichmentBatch(store *storage.Storage, limit int) ([]enrichmentItem, error) {
+	entries, err := store.GetPendingEnrichments(limit)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ProfileID
+	}
+	profiles, err := store.GetProfilesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*storage.Profile, len(profiles))
+	for _, profile := range profiles {
+		byID[profile.ID] = profile
+	}
+
+	batch := make([]enrichmentItem, len(entries))
+	for i, entry := range entries {
+		batch[i] = enrichmentItem{entry: entry, profile: byID[entry.ProfileID]}
+	}
+	return batch, nil
+}
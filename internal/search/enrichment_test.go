@@ -0,0 +1,179 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/storage"
+)
+
+// newEnrichmentStore returns a database with one queued profile per
+// username, queued with the given priority in order
+func newEnrichmentStore(t *testing.T, queue []struct {
+	username string
+	priority int
+}) *storage.Storage {
+	t.Helper()
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	for _, q := range queue {
+		id, err := store.SaveProfile(&storage.Profile{ProfileURL: "https://www.linkedin.com/in/" + q.username, Name: q.username})
+		if err != nil {
+			t.Fatalf("SaveProfile: %v", err)
+		}
+		if _, err := store.QueueEnrichment(id, q.priority); err != nil {
+			t.Fatalf("QueueEnrichment: %v", err)
+		}
+	}
+
+	return store
+}
+
+// runQueueUntil runs the enrichment queue until done returns true or the
+// test times out
+func runQueueUntil(t *testing.T, store *storage.Storage, workers int, process EnrichFunc, done func() bool) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		RunEnrichmentQueue(ctx, store, workers, process)
+		close(finished)
+	}()
+
+	deadline := time.After(10 * time.Second)
+	for !done() {
+		select {
+		case <-deadline:
+			cancel()
+			<-finished
+			t.Fatal("timed out waiting for the enrichment queue")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	<-finished
+}
+
+func TestRunEnrichmentQueuePriorityOrder(t *testing.T) {
+	store := newEnrichmentStore(t, []struct {
+		username string
+		priority int
+	}{
+		{"normal-1", storage.EnrichmentPriorityNormal},
+		{"high-1", storage.EnrichmentPriorityHigh},
+		{"normal-2", storage.EnrichmentPriorityNormal},
+		{"high-2", storage.EnrichmentPriorityHigh},
+	})
+
+	var mu sync.Mutex
+	var order []string
+	process := func(ctx context.Context, profile *storage.Profile) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, profile.Name)
+		return nil
+	}
+
+	runQueueUntil(t, store, 1, process, func() bool { return store.GetEnrichmentQueueDepth() == 0 })
+
+	want := []string{"high-1", "high-2", "normal-1", "normal-2"}
+	if len(order) != len(want) {
+		t.Fatalf("processed %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("processed %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRunEnrichmentQueueWorkerConcurrency(t *testing.T) {
+	store := newEnrichmentStore(t, []struct {
+		username string
+		priority int
+	}{
+		{"a", 0}, {"b", 0}, {"c", 0}, {"d", 0}, {"e", 0}, {"f", 0},
+	})
+
+	var running, maxRunning atomic.Int32
+	process := func(ctx context.Context, profile *storage.Profile) error {
+		n := running.Add(1)
+		for {
+			max := maxRunning.Load()
+			if n <= max || maxRunning.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+
+	runQueueUntil(t, store, 3, process, func() bool { return store.GetEnrichmentQueueDepth() == 0 })
+
+	if got := maxRunning.Load(); got != 3 {
+		t.Errorf("max concurrent workers = %d, want 3", got)
+	}
+}
+
+func TestRunEnrichmentQueueRetriesFailures(t *testing.T) {
+	store := newEnrichmentStore(t, []struct {
+		username string
+		priority int
+	}{
+		{"flaky", 0}, {"broken", 0},
+	})
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	process := func(ctx context.Context, profile *storage.Profile) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[profile.Name]++
+		if profile.Name == "broken" || calls[profile.Name] == 1 {
+			return errors.New("page did not load")
+		}
+		return nil
+	}
+
+	runQueueUntil(t, store, 1, process, func() bool { return store.GetEnrichmentQueueDepth() == 0 })
+
+	if calls["flaky"] != 2 {
+		t.Errorf("flaky profile tried %d times, want 2", calls["flaky"])
+	}
+	if calls["broken"] != storage.MaxEnrichmentAttempts {
+		t.Errorf("broken profile tried %d times, want %d", calls["broken"], storage.MaxEnrichmentAttempts)
+	}
+}
+
+func TestRunEnrichmentQueueKeepsDeferredEntries(t *testing.T) {
+	store := newEnrichmentStore(t, []struct {
+		username string
+		priority int
+	}{
+		{"waiting", 0},
+	})
+
+	var calls atomic.Int32
+	process := func(ctx context.Context, profile *storage.Profile) error {
+		calls.Add(1)
+		return ErrEnrichmentDeferred
+	}
+
+	runQueueUntil(t, store, 1, process, func() bool { return calls.Load() > 0 })
+
+	if depth := store.GetEnrichmentQueueDepth(); depth != 1 {
+		t.Errorf("queue depth = %d, want the deferred entry to stay queued", depth)
+	}
+}
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+)
+
+const (
+	// photoRefreshInterval is how long a downloaded photo is kept before
+	// it's downloaded again to check for a new picture
+	photoRefreshInterval = 30 * 24 * time.Hour
+
+	// maxPhotoBytes bounds the size of a downloaded profile photo
+	maxPhotoBytes = 5 << 20
+)
+
+// DownloadProfilePhoto saves the profile picture on the current profile page
+// to DataDir/photos/{profileID}.jpg. Photos downloaded within
+// photoRefreshInterval are skipped; older ones are downloaded again and only
+// replaced when their hash changed.
+func (s *Service) DownloadProfilePhoto(ctx context.Context, profile *storage.Profile) error {
+	log := logger.FromContext(ctx)
+
+	existing, err := s.store.GetProfilePhoto(profile.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get stored photo: %w", err)
+	}
+	if existing.Path != "" && existing.DownloadedAt != nil && time.Since(*existing.DownloadedAt) < photoRefreshInterval {
+		if _, err := os.Stat(existing.Path); err == nil {
+			return nil
+		}
+	}
+
+	photoURL := s.extractPhotoURL(s.browser.GetPage())
+	if photoURL == "" {
+		log.Debugf("No profile photo on %s", profile.ProfileURL)
+		return nil
+	}
+
+	return s.savePhoto(ctx, profile, existing, photoURL)
+}
+
+// savePhoto downloads the photo at photoURL and saves it unless it matches
+// the existing photo
+func (s *Service) savePhoto(ctx context.Context, profile *storage.Profile, existing storage.ProfilePhoto, photoURL string) error {
+	log := logger.FromContext(ctx)
+
+	data, err := DownloadPhoto(ctx, s.httpClient, photoURL)
+	if err != nil {
+		return err
+	}
+
+	hash := photoHash(data)
+	if existing.Path != "" && hash == existing.Hash {
+		if _, err := os.Stat(existing.Path); err == nil {
+			return s.store.TouchProfilePhoto(profile.ID)
+		}
+	}
+
+	dir := s.cfg.Storage.PhotoDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create photo directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%d.jpg", profile.ID))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save photo: %w", err)
+	}
+
+	if err := s.store.SaveProfilePhoto(profile.ID, path, hash); err != nil {
+		return fmt.Errorf("failed to record photo: %w", err)
+	}
+
+	if existing.Hash != "" && existing.Hash != hash {
+		log.Infof("Profile photo of %s changed", profile.ProfileURL)
+	} else {
+		log.Debugf("Saved profile photo of %s to %s", profile.ProfileURL, path)
+	}
+
+	return nil
+}
+
+// extractPhotoURL returns the profile picture URL on a profile page, or ""
+// for profiles without a photo
+func (s *Service) extractPhotoURL(page *rod.Page) string {
+	for _, selector := range s.cfg.Selectors.ProfilePhoto {
+		has, img, err := page.Has(selector)
+		if err != nil || !has {
+			continue
+		}
+
+		src, err := img.Attribute("src")
+		if err != nil || src == nil {
+			continue
+		}
+
+		// Profiles without a photo show an inline placeholder image
+		if strings.HasPrefix(*src, "http") {
+			return *src
+		}
+	}
+
+	return ""
+}
+
+// DownloadPhoto fetches an image, rejecting non-image responses and images
+// over maxPhotoBytes
+func DownloadPhoto(ctx context.Context, client *http.Client, photoURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, photoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download photo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("photo download returned status %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("photo download returned %q instead of an image", contentType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPhotoBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo: %w", err)
+	}
+	if len(data) > maxPhotoBytes {
+		return nil, fmt.Errorf("photo is larger than %d bytes", maxPhotoBytes)
+	}
+
+	return data, nil
+}
+
+// photoHash returns the hex SHA-256 of a photo, used to detect changes
+func photoHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
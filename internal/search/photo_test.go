@@ -0,0 +1,192 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+// photoServer serves the current photo bytes at /photo.jpg. Other paths
+// return errors, non-images or oversized images.
+func photoServer(t *testing.T, photo *atomic.Value) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/photo.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(photo.Load().([]byte))
+		case "/huge.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(bytes.Repeat([]byte{0xff}, maxPhotoBytes+1))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newPhotoTestService(t *testing.T) (*Service, *storage.Storage, *storage.Profile) {
+	t.Helper()
+
+	dir := t.TempDir()
+	store, err := storage.New(filepath.Join(dir, "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	profile := &storage.Profile{ProfileURL: "https://www.linkedin.com/in/jane", Name: "Jane"}
+	if profile.ID, err = store.SaveProfile(profile); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Storage.DataDir = dir
+	cfg.Storage.PhotoStorageEnabled = true
+
+	return &Service{cfg: cfg, store: store, httpClient: &http.Client{Timeout: 5 * time.Second}}, store, profile
+}
+
+func TestDownloadPhoto(t *testing.T) {
+	var photo atomic.Value
+	photo.Store([]byte("jpeg bytes"))
+	srv := photoServer(t, &photo)
+
+	data, err := DownloadPhoto(context.Background(), srv.Client(), srv.URL+"/photo.jpg")
+	if err != nil || string(data) != "jpeg bytes" {
+		t.Errorf("DownloadPhoto = %q, %v, want the image bytes", data, err)
+	}
+
+	for path, want := range map[string]string{
+		"/missing.jpg": "status 404",
+		"/page.html":   "instead of an image",
+		"/huge.jpg":    "larger than",
+	} {
+		if _, err := DownloadPhoto(context.Background(), srv.Client(), srv.URL+path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("DownloadPhoto(%s) = %v, want an error containing %q", path, err, want)
+		}
+	}
+}
+
+func TestSavePhotoDetectsChanges(t *testing.T) {
+	var photo atomic.Value
+	photo.Store([]byte("first photo"))
+	srv := photoServer(t, &photo)
+
+	s, store, profile := newPhotoTestService(t)
+	ctx := context.Background()
+	wantPath := filepath.Join(s.cfg.Storage.DataDir, "photos", fmt.Sprintf("%d.jpg", profile.ID))
+
+	save := func() storage.ProfilePhoto {
+		t.Helper()
+		existing, err := store.GetProfilePhoto(profile.ID)
+		if err != nil {
+			t.Fatalf("GetProfilePhoto: %v", err)
+		}
+		if err := s.savePhoto(ctx, profile, existing, srv.URL+"/photo.jpg"); err != nil {
+			t.Fatalf("savePhoto: %v", err)
+		}
+		saved, err := store.GetProfilePhoto(profile.ID)
+		if err != nil {
+			t.Fatalf("GetProfilePhoto: %v", err)
+		}
+		return saved
+	}
+
+	first := save()
+	if first.Path != wantPath || first.Hash != photoHash([]byte("first photo")) || first.DownloadedAt == nil {
+		t.Fatalf("stored photo = %+v, want %s with the photo's hash", first, wantPath)
+	}
+	if data, err := os.ReadFile(wantPath); err != nil || string(data) != "first photo" {
+		t.Fatalf("photo file = %q, %v", data, err)
+	}
+
+	// Same photo: the file is left alone
+	if err := os.Chtimes(wantPath, time.Time{}, time.Unix(0, 0)); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if unchanged := save(); unchanged.Hash != first.Hash {
+		t.Errorf("hash of an unchanged photo = %s, want %s", unchanged.Hash, first.Hash)
+	}
+	if info, err := os.Stat(wantPath); err != nil || !info.ModTime().Equal(time.Unix(0, 0)) {
+		t.Errorf("unchanged photo was rewritten")
+	}
+
+	// New photo: the file and hash are replaced
+	photo.Store([]byte("second photo"))
+	changed := save()
+	if changed.Hash != photoHash([]byte("second photo")) {
+		t.Errorf("hash after the photo changed = %s, want the new photo's", changed.Hash)
+	}
+	if data, _ := os.ReadFile(wantPath); string(data) != "second photo" {
+		t.Errorf("photo file after change = %q, want the new photo", data)
+	}
+}
+
+func TestDownloadProfilePhotoSkipsRecentPhoto(t *testing.T) {
+	s, store, profile := newPhotoTestService(t)
+
+	path := filepath.Join(t.TempDir(), "1.jpg")
+	if err := os.WriteFile(path, []byte("photo"), 0644); err != nil {
+		t.Fatalf("write photo: %v", err)
+	}
+	if err := store.SaveProfilePhoto(profile.ID, path, photoHash([]byte("photo"))); err != nil {
+		t.Fatalf("SaveProfilePhoto: %v", err)
+	}
+
+	// The service has no browser, so anything past the skip would panic
+	if err := s.DownloadProfilePhoto(context.Background(), profile); err != nil {
+		t.Errorf("DownloadProfilePhoto = %v, want a skip", err)
+	}
+}
+
+func TestExtractPhotoURL(t *testing.T) {
+	page := newFixturePage(t)
+	s := &Service{cfg: &config.Config{Selectors: config.DefaultSelectors()}}
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			"photo",
+			`<div class="pv-top-card-profile-picture"><img src="https://media.licdn.com/dms/image/jane.jpg"></div>`,
+			"https://media.licdn.com/dms/image/jane.jpg",
+		},
+		{
+			"placeholder",
+			`<div class="pv-top-card-profile-picture"><img src="data:image/gif;base64,R0lGODlhAQABAAAAACw="></div>`,
+			"",
+		},
+		{"no picture", `<p>Jane Doe</p>`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := page.SetDocumentContent(tt.html); err != nil {
+				t.Fatalf("set content: %v", err)
+			}
+			if got := s.extractPhotoURL(page); got != tt.want {
+				t.Errorf("extractPhotoURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
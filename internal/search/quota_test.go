@@ -0,0 +1,42 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+)
+
+func TestSearchQuotaOutsideTheSearchService(t *testing.T) {
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{}
+	cfg.Search.MonthlySearchLimit = 20
+	ctx := context.Background()
+
+	// Nothing is recorded while tracking is off
+	RecordSearchView(ctx, store, cfg)
+	if status, _ := store.GetSearchQuotaStatus(); status.Used != 0 {
+		t.Fatalf("Used = %d with tracking off, want 0", status.Used)
+	}
+
+	cfg.Search.TrackSearchQuota = true
+	for i := 0; i < 19; i++ {
+		RecordSearchView(ctx, store, cfg)
+	}
+	if err := CheckSearchQuota(ctx, store, cfg); err != nil {
+		t.Fatalf("CheckSearchQuota at 95%%: %v", err)
+	}
+
+	RecordSearchView(ctx, store, cfg)
+	if err := CheckSearchQuota(ctx, store, cfg); !errors.Is(err, ErrSearchQuotaExhausted) {
+		t.Errorf("CheckSearchQuota at 100%% = %v, want ErrSearchQuotaExhausted", err)
+	}
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/urlutil"
+)
+
+// ResolveCanonicalURL follows the redirects of a profile URL with a HEAD
+// request sent with the given headers and returns the URL it ends up at,
+// without query parameters. Ending up anywhere but a member profile, such as
+// the authwall, is an error.
+func ResolveCanonicalURL(ctx context.Context, client *http.Client, profileURL string, header http.Header) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, profileURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", profileURL, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("resolving %s returned status %d", profileURL, resp.StatusCode)
+	}
+
+	resolved := strings.Split(resp.Request.URL.String(), "?")[0]
+	if !urlutil.IsPersonProfileURL(resolved) {
+		return "", fmt.Errorf("%s redirected to %s, not a profile", profileURL, resolved)
+	}
+
+	return resolved, nil
+}
+
+// resolveProfileURL returns the canonical URL for a profile URL found on a
+// search card, using the profile_url_redirects cache before resolving it.
+// The discovered URL is returned unchanged, and nothing is cached, when it
+// can't be resolved.
+func (s *Service) resolveProfileURL(ctx context.Context, discoveredURL string) string {
+	log := logger.FromContext(ctx)
+
+	if cached, err := s.store.GetProfileRedirect(discoveredURL); err != nil {
+		log.Warnf("Failed to read profile redirect cache: %v", err)
+	} else if cached != "" && urlutil.IsPersonProfileURL(cached) {
+		return cached
+	}
+
+	// Without the session's cookies and User-Agent LinkedIn answers with
+	// the authwall
+	header, err := s.browser.RequestHeader()
+	if err != nil {
+		log.Debugf("Resolving profile URL without session cookies: %v", err)
+	}
+
+	canonicalURL, err := ResolveCanonicalURL(ctx, s.httpClient, discoveredURL, header)
+	if err != nil {
+		log.Debugf("Keeping discovered profile URL: %v", err)
+		return discoveredURL
+	}
+
+	if err := s.store.SaveProfileRedirect(discoveredURL, canonicalURL); err != nil {
+		log.Warnf("Failed to cache profile redirect: %v", err)
+	}
+
+	if canonicalURL != discoveredURL {
+		log.Infof("Profile URL %s redirects to %s", discoveredURL, canonicalURL)
+	}
+	return canonicalURL
+}
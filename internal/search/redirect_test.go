@@ -0,0 +1,70 @@
+package search
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// linkedInTestClient returns a client that sends every www.linkedin.com
+// request to server
+func linkedInTestClient(server *httptest.Server) *http.Client {
+	addr := server.Listener.Addr().String()
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+}
+
+func TestResolveCanonicalURL(t *testing.T) {
+	var gotUserAgent, gotCookie string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		gotCookie = r.Header.Get("Cookie")
+
+		switch r.URL.Path {
+		case "/in/old-name":
+			http.Redirect(w, r, "https://www.linkedin.com/in/new-name/?trk=redirect", http.StatusMovedPermanently)
+		case "/in/new-name/":
+			w.WriteHeader(http.StatusOK)
+		case "/in/logged-out":
+			http.Redirect(w, r, "https://www.linkedin.com/authwall?sessionRedirect=x", http.StatusFound)
+		case "/authwall":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := linkedInTestClient(server)
+
+	header := http.Header{}
+	header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64)")
+	header.Set("Cookie", "li_at=session")
+
+	got, err := ResolveCanonicalURL(context.Background(), client, "https://www.linkedin.com/in/old-name", header)
+	if err != nil {
+		t.Fatalf("ResolveCanonicalURL: %v", err)
+	}
+	if want := "https://www.linkedin.com/in/new-name/"; got != want {
+		t.Errorf("canonical URL = %s, want %s", got, want)
+	}
+	if gotUserAgent != header.Get("User-Agent") || gotCookie != header.Get("Cookie") {
+		t.Errorf("request sent User-Agent %q and Cookie %q, want the session's", gotUserAgent, gotCookie)
+	}
+
+	if got, err := ResolveCanonicalURL(context.Background(), client, "https://www.linkedin.com/in/logged-out", header); err == nil {
+		t.Errorf("redirect to the authwall resolved to %s, want an error", got)
+	}
+
+	if _, err := ResolveCanonicalURL(context.Background(), client, "https://www.linkedin.com/in/missing", header); err == nil {
+		t.Error("404 resolved without an error")
+	}
+}
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"linkedin-automation/internal/logger"
+)
+
+// schoolURNPattern matches school entity URNs in typeahead responses
+var schoolURNPattern = regexp.MustCompile(`urn:li:(?:fsd_school|fs_miniSchool|school|company):(\d+)`)
+
+// typeaheadScript queries LinkedIn's typeahead API from the logged-in page,
+// passing the session's CSRF token like the web app does
+const typeaheadScript = `async (type, keywords) => {
+	const token = (document.cookie.match(/JSESSIONID="?([^";]+)"?/) || [])[1] || '';
+	const res = await fetch('/voyager/api/typeahead/hitsV2?keywords=' + encodeURIComponent(keywords) +
+		'&origin=OTHER&q=type&type=' + type, {
+		credentials: 'include',
+		headers: { 'csrf-token': token, 'accept': 'application/vnd.linkedin.normalized+json+2.1' },
+	});
+	if (!res.ok) {
+		throw new Error('typeahead returned ' + res.status);
+	}
+	return await res.text();
+}`
+
+// ResolveSchoolURN returns LinkedIn's numeric ID for a school name, using the
+// school_urns cache before falling back to the typeahead API
+func (s *Service) ResolveSchoolURN(ctx context.Context, schoolName string) (string, error) {
+	log := logger.FromContext(ctx)
+
+	schoolName = strings.TrimSpace(schoolName)
+	if cached, err := s.store.GetSchoolURN(schoolName); err != nil {
+		log.Warnf("Failed to read school URN cache: %v", err)
+	} else if cached != "" {
+		return cached, nil
+	}
+
+	body, err := s.typeahead("SCHOOL", schoolName)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve school %q: %w", schoolName, err)
+	}
+
+	urn := ParseSchoolURN(body)
+	if urn == "" {
+		return "", fmt.Errorf("no school found for %q", schoolName)
+	}
+
+	if err := s.store.SaveSchoolURN(schoolName, urn); err != nil {
+		log.Warnf("Failed to cache school URN: %v", err)
+	}
+
+	log.Debugf("Resolved school %q to %s", schoolName, urn)
+	return urn, nil
+}
+
+// resolveSchoolURNs resolves every school of a search target, skipping (and
+// logging) names that can't be resolved. It returns the URNs along with the
+// names they belong to.
+func (s *Service) resolveSchoolURNs(ctx context.Context, schools []string) ([]string, []string) {
+	var urns, resolved []string
+	for _, school := range schools {
+		urn, err := s.ResolveSchoolURN(ctx, school)
+		if err != nil {
+			logger.FromContext(ctx).Warnf("Ignoring school filter: %v", err)
+			continue
+		}
+		urns = append(urns, urn)
+		resolved = append(resolved, school)
+	}
+	return urns, resolved
+}
+
+// typeahead runs a typeahead query from a linkedin.com page
+func (s *Service) typeahead(entityType, keywords string) (string, error) {
+	page := s.browser.GetPage()
+
+	info, err := page.Info()
+	if err != nil {
+		return "", fmt.Errorf("failed to get page info: %w", err)
+	}
+
+	// fetch needs a linkedin.com origin to send the session cookies
+	if !strings.Contains(info.URL, "linkedin.com") {
+		if err := s.browser.Navigate("https://www.linkedin.com/feed/"); err != nil {
+			return "", fmt.Errorf("failed to navigate to feed: %w", err)
+		}
+	}
+
+	res, err := page.Eval(typeaheadScript, entityType, keywords)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Value.Str(), nil
+}
+
+// ParseSchoolURN extracts the first school ID from a typeahead response body
+func ParseSchoolURN(body string) string {
+	if match := schoolURNPattern.FindStringSubmatch(body); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// schoolFilterValue formats school IDs for the schoolFilter search parameter
+func schoolFilterValue(urns []string) string {
+	data, _ := json.Marshal(urns)
+	return string(data)
+}
+
+// matchSchool returns the first target school named in a result card's text.
+// When the search was filtered by a single school the filter already
+// guarantees the match; filteredSchools lists the schools whose filter was
+// applied, so a school whose URN didn't resolve is never assumed.
+func matchSchool(cardText string, schools, filteredSchools []string) string {
+	lower := strings.ToLower(cardText)
+	for _, school := range schools {
+		if school != "" && strings.Contains(lower, strings.ToLower(school)) {
+			return school
+		}
+	}
+
+	if len(schools) == 1 && len(filteredSchools) == 1 {
+		return filteredSchools[0]
+	}
+	return ""
+}
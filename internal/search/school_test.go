@@ -0,0 +1,42 @@
+package search
+
+import "testing"
+
+func TestParseSchoolURN(t *testing.T) {
+	body := `{"included":[{"entityUrn":"urn:li:fsd_school:12345"}]}`
+	if got := ParseSchoolURN(body); got != "12345" {
+		t.Errorf("ParseSchoolURN = %q, want 12345", got)
+	}
+	if got := ParseSchoolURN(`{"included":[]}`); got != "" {
+		t.Errorf("ParseSchoolURN without a school = %q, want empty", got)
+	}
+}
+
+func TestSchoolFilterValue(t *testing.T) {
+	if got := schoolFilterValue([]string{"1", "2"}); got != `["1","2"]` {
+		t.Errorf("schoolFilterValue = %s", got)
+	}
+}
+
+func TestMatchSchool(t *testing.T) {
+	tests := []struct {
+		name     string
+		cardText string
+		schools  []string
+		filtered []string
+		want     string
+	}{
+		{"named on card", "Engineer · Stanford University", []string{"MIT", "Stanford University"}, nil, "Stanford University"},
+		{"single filtered school", "Engineer at Acme", []string{"MIT"}, []string{"MIT"}, "MIT"},
+		{"single unresolved school", "Engineer at Acme", []string{"MIT"}, nil, ""},
+		{"several schools not on card", "Engineer at Acme", []string{"MIT", "Stanford"}, []string{"MIT", "Stanford"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchSchool(tt.cardText, tt.schools, tt.filtered); got != tt.want {
+				t.Errorf("matchSchool = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,130 +2,483 @@ package search
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"linkedin-automation/internal/browser"
 	"linkedin-automation/internal/config"
 	"linkedin-automation/internal/logger"
+	"linkedin-automation/internal/scoring"
 	"linkedin-automation/internal/stealth"
 	"linkedin-automation/internal/storage"
 
 	"github.com/go-rod/rod"
-	"github.com/sirupsen/logrus"
 )
 
+// Monthly search quota thresholds, in percent
+const (
+	quotaWarnPercent  = 80.0
+	quotaBlockPercent = 95.0
+)
+
+// searchResumeWindow is how long an interrupted search can be resumed
+const searchResumeWindow = 24 * time.Hour
+
+// ErrSearchQuotaExhausted is returned when the monthly search quota is nearly used up
+var ErrSearchQuotaExhausted = errors.New("monthly search quota nearly exhausted")
+
+// redirectTimeout bounds the HEAD request used to resolve profile redirects
+const redirectTimeout = 10 * time.Second
+
 type Service struct {
-	browser *browser.Context
-	store   *storage.Storage
-	cfg     *config.Config
-	log     *logrus.Logger
+	browser    *browser.Context
+	store      *storage.Storage
+	cfg        *config.Config
+	stealth    *stealth.Stealth
+	httpClient *http.Client
+
+	// cache holds recent results per target; nil when caching is off
+	cache *SearchResultCache
+
+	// enrichmentGate reports whether background enrichment may visit a
+	// profile now; nil allows it, see SetEnrichmentGate
+	enrichmentGate func() bool
 }
 
 func New(browser *browser.Context, store *storage.Storage, cfg *config.Config) *Service {
-	return &Service{
-		browser: browser,
-		store:   store,
-		cfg:     cfg,
-		log:     logger.Get(),
+	s := &Service{
+		browser:    browser,
+		store:      store,
+		cfg:        cfg,
+		stealth:    browser.NewStealth("search"),
+		httpClient: &http.Client{Timeout: redirectTimeout},
 	}
+
+	if cfg.Search.CacheExpiryHours > 0 && !cfg.Search.DisableCache {
+		ttl := time.Duration(cfg.Search.CacheExpiryHours * float64(time.Hour))
+		s.cache = NewSearchResultCache(searchCacheSize, ttl)
+	}
+
+	return s
 }
 
 // SearchProfiles searches for profiles based on configured targets
 func (s *Service) SearchProfiles(ctx context.Context) ([]*storage.Profile, error) {
-	s.log.Info("Starting profile search...")
+	log := logger.FromContext(ctx)
 
-	var allProfiles []*storage.Profile
-	seenURLs := make(map[string]bool)
+	log.Info("Starting profile search...")
 
+	var targets []config.SearchTarget
 	for _, target := range s.cfg.Search.Targets {
-		s.log.Infof("Searching for: %s in %s", target.JobTitle, target.Location)
-
-		profiles, err := s.searchTarget(ctx, target)
-		if err != nil {
-			s.log.Errorf("Search failed for target %s: %v", target.JobTitle, err)
+		if s.cfg.Campaign != "" && target.Campaign != s.cfg.Campaign {
 			continue
 		}
+		targets = append(targets, target)
+	}
 
-		// Deduplicate profiles
+	var allProfiles []*storage.Profile
+	seenURLs := make(map[string]bool)
+
+	// Deduplicate profiles
+	addProfiles := func(profiles []*storage.Profile) {
 		for _, profile := range profiles {
 			if !seenURLs[profile.ProfileURL] {
 				seenURLs[profile.ProfileURL] = true
 				allProfiles = append(allProfiles, profile)
 			}
 		}
+	}
 
-		// Delay between searches
-		s.browser.GetStealth().RandomDelay("think")
+	var err error
+	if s.cfg.Search.SearchMode == config.SearchModeBreadthFirst {
+		err = s.searchBreadthFirst(ctx, targets, addProfiles)
+	} else {
+		err = s.searchDepthFirst(ctx, targets, addProfiles)
+	}
+	if err != nil {
+		return allProfiles, err
 	}
 
-	s.log.Infof("Found %d unique profiles", len(allProfiles))
-	s.store.LogActivity("search", "", "success", fmt.Sprintf("Found %d profiles", len(allProfiles)))
+	log.Infof("Found %d unique profiles", len(allProfiles))
+	s.store.LogActivityAsync("search", "", "success", fmt.Sprintf("Found %d profiles", len(allProfiles)))
 
 	return allProfiles, nil
 }
 
-// searchTarget performs a search for a specific target
-func (s *Service) searchTarget(ctx context.Context, target config.SearchTarget) ([]*storage.Profile, error) {
-	// Build search URL
-	searchURL := s.buildSearchURL(target)
+// searchDepthFirst pages through each target in turn before moving on
+func (s *Service) searchDepthFirst(ctx context.Context, targets []config.SearchTarget, add func([]*storage.Profile)) error {
+	log := logger.FromContext(ctx)
+
+	for _, target := range targets {
+		if profiles, ok := s.cachedResults(target); ok {
+			log.Infof("Using %d cached results for: %s in %s", len(profiles), target.JobTitle, target.Location)
+			add(profiles)
+			continue
+		}
+
+		log.Infof("Searching for: %s in %s", target.JobTitle, target.Location)
 
-	s.log.Debugf("Search URL: %s", searchURL)
+		cursor, err := s.searchTarget(ctx, target)
+		if errors.Is(err, ErrSearchQuotaExhausted) {
+			return err
+		}
+		if err != nil {
+			log.Errorf("Search failed for target %s: %v", target.JobTitle, err)
+			continue
+		}
 
-	// Navigate to search page
-	if err := s.browser.Navigate(searchURL); err != nil {
-		return nil, fmt.Errorf("failed to navigate to search: %w", err)
+		s.cacheCursor(cursor)
+		add(cursor.profiles)
+
+		// Delay between searches
+		s.stealth.RandomDelay("think")
 	}
 
-	page := s.browser.GetPage()
-	stealth := s.browser.GetStealth()
+	return nil
+}
 
-	// Wait for search results to load
-	time.Sleep(3 * time.Second)
+// searchBreadthFirst visits page 1 of every target, then page 2 of every
+// target and so on, so a run cut short still covers all targets
+func (s *Service) searchBreadthFirst(ctx context.Context, targets []config.SearchTarget, add func([]*storage.Profile)) error {
+	log := logger.FromContext(ctx)
 
-	var profiles []*storage.Profile
+	var cursors []*searchCursor
+	for _, target := range targets {
+		if profiles, ok := s.cachedResults(target); ok {
+			log.Infof("Using %d cached results for: %s in %s", len(profiles), target.JobTitle, target.Location)
+			add(profiles)
+			continue
+		}
+
+		cursor, err := s.openCursor(ctx, target)
+		if errors.Is(err, ErrSearchQuotaExhausted) {
+			return err
+		}
+		if err != nil {
+			log.Errorf("Search failed for target %s: %v", target.JobTitle, err)
+			continue
+		}
+		cursors = append(cursors, cursor)
+	}
 
-	// Iterate through pagination
-	for i := 0; i < s.cfg.Search.PaginationLimit; i++ {
-		s.log.Infof("Processing search results page %d", i+1)
+	return interleavePages(ctx, cursors, func(cursor *searchCursor, round int, switched bool) error {
+		if switched {
+			log.Infof("Searching for: %s in %s (round %d)", cursor.target.JobTitle, cursor.target.Location, round+1)
+		}
 
-		// Scroll to load all results
-		stealth.RandomScroll(page)
-		stealth.RandomDelay("scroll")
+		before := len(cursor.profiles)
+		err := s.searchNextPage(ctx, cursor, switched)
+		add(cursor.profiles[before:])
 
-		// Extract profile URLs from current page
-		pageProfiles, err := s.extractProfilesFromPage(page, target)
+		if errors.Is(err, ErrSearchQuotaExhausted) {
+			return err
+		}
 		if err != nil {
-			s.log.Errorf("Failed to extract profiles from page %d: %v", i+1, err)
-			break
+			log.Errorf("Search failed for target %s: %v", cursor.target.JobTitle, err)
+		} else {
+			s.cacheCursor(cursor)
 		}
 
-		profiles = append(profiles, pageProfiles...)
+		// Delay between searches
+		s.stealth.RandomDelay("think")
+		return nil
+	})
+}
+
+// interleavePages calls next for one page of each unfinished cursor in turn,
+// round after round, until every cursor is done. switched is false when the
+// same cursor runs twice in a row, so its next page is already loaded. The
+// first error from next stops the search.
+func interleavePages(ctx context.Context, cursors []*searchCursor, next func(cursor *searchCursor, round int, switched bool) error) error {
+	var last *searchCursor
+	for round := 0; ; round++ {
+		active := 0
+		for _, cursor := range cursors {
+			if cursor.done {
+				continue
+			}
+			active++
 
-		s.log.Infof("Extracted %d profiles from page %d", len(pageProfiles), i+1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
 
-		// Check if we've reached the limit
-		if len(profiles) >= s.cfg.Search.MaxResultsPerSearch {
-			profiles = profiles[:s.cfg.Search.MaxResultsPerSearch]
-			break
+			err := next(cursor, round, cursor != last)
+			last = cursor
+			if err != nil {
+				return err
+			}
 		}
 
-		// Try to go to next page
-		if !s.goToNextPage(page, stealth) {
-			s.log.Info("No more pages available")
-			break
+		if active == 0 {
+			return nil
 		}
+	}
+}
+
+// searchCursor tracks a target's progress through its result pages
+type searchCursor struct {
+	target   config.SearchTarget
+	schools  []string // target schools applied as a search filter
+	hash     string
+	url      string // URL of the next page to process
+	page     int    // zero-based index of the next page
+	profiles []*storage.Profile
+	done     bool
+	complete bool // every page was loaded, so profiles holds all results
+	resumed  bool // started from a saved page, so earlier pages are missing
+}
+
+// searchTarget performs a search for a specific target
+func (s *Service) searchTarget(ctx context.Context, target config.SearchTarget) (*searchCursor, error) {
+	log := logger.FromContext(ctx)
 
-		stealth.RandomDelay("action")
+	cursor, err := s.openCursor(ctx, target)
+	if err != nil {
+		return nil, err
 	}
 
-	return profiles, nil
+	for navigate := true; !cursor.done; navigate = false {
+		if err := s.searchNextPage(ctx, cursor, navigate); err != nil {
+			if errors.Is(err, ErrSearchQuotaExhausted) {
+				log.Warn(err.Error())
+				break
+			}
+			return cursor, err
+		}
+	}
+
+	return cursor, nil
+}
+
+// openCursor starts a search for a target, resuming an interrupted search
+// if there is one
+func (s *Service) openCursor(ctx context.Context, target config.SearchTarget) (*searchCursor, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.checkSearchQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	schoolURNs, filteredSchools := s.resolveSchoolURNs(ctx, target.Schools)
+	cursor := &searchCursor{
+		target:  target,
+		schools: filteredSchools,
+		hash:    TargetHash(target),
+		url:     s.buildSearchURL(target, schoolURNs),
+	}
+
+	state, err := s.store.GetSearchState(cursor.hash)
+	if err != nil {
+		log.Warnf("Failed to load search state: %v", err)
+	}
+	if state != nil && state.CompletedAt == nil && state.LastURL != "" &&
+		time.Since(state.StartedAt) < searchResumeWindow {
+		log.Infof("Resuming interrupted search from page %d", state.LastPage+1)
+		cursor.url = state.LastURL
+		cursor.page = state.LastPage
+		cursor.resumed = true
+	} else if err := s.store.StartSearchState(cursor.hash, cursor.url); err != nil {
+		log.Warnf("Failed to save search state: %v", err)
+	}
+
+	if cursor.page >= s.cfg.Search.PaginationLimit {
+		s.completeCursor(ctx, cursor)
+	}
+
+	return cursor, nil
+}
+
+// searchNextPage extracts the cursor's next results page and advances it.
+// When navigate is false the page is assumed to already be loaded.
+func (s *Service) searchNextPage(ctx context.Context, cursor *searchCursor, navigate bool) error {
+	log := logger.FromContext(ctx)
+
+	page := s.browser.GetPage()
+	stealth := s.stealth
+
+	if navigate {
+		log.Debugf("Search URL: %s", cursor.url)
+
+		// Navigate to search page
+		if err := s.browser.Navigate(cursor.url); err != nil {
+			cursor.done = true
+			return fmt.Errorf("failed to navigate to search: %w", err)
+		}
+		page = s.browser.GetPage()
+
+		// Wait for search results to load
+		if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+			log.Debugf("Continuing before network idle: %v", err)
+		}
+	}
+
+	i := cursor.page
+	log.Infof("Processing search results page %d", i+1)
+
+	// Scroll to load all results
+	stealth.RandomScroll(page)
+	stealth.RandomDelay("scroll")
+
+	// Extract profile URLs from current page
+	pageProfiles, err := s.extractProfilesFromPage(ctx, page, cursor.target, cursor.schools)
+	if err != nil {
+		log.Errorf("Failed to extract profiles from page %d: %v", i+1, err)
+		cursor.done = true
+		return nil
+	}
+
+	cursor.profiles = append(cursor.profiles, pageProfiles...)
+
+	log.Infof("Extracted %d profiles from page %d", len(pageProfiles), i+1)
+
+	if s.cfg.Search.TrackSearchQuota {
+		s.recordSearchPage(ctx, page, i+1)
+		if err := s.checkSearchQuota(ctx); err != nil {
+			cursor.done = true
+			return err
+		}
+	}
+
+	// Check if we've reached the limit
+	if len(cursor.profiles) >= s.cfg.Search.MaxResultsPerSearch {
+		cursor.profiles = cursor.profiles[:s.cfg.Search.MaxResultsPerSearch]
+		s.completeCursor(ctx, cursor)
+		return nil
+	}
+
+	// Try to go to next page
+	if !s.goToNextPage(ctx, page, stealth) {
+		log.Info("No more pages available")
+		s.completeCursor(ctx, cursor)
+		return nil
+	}
+
+	cursor.page = i + 1
+	cursor.url = page.MustInfo().URL
+	if err := s.store.UpdateSearchProgress(cursor.hash, cursor.page, cursor.url); err != nil {
+		log.Warnf("Failed to save search progress: %v", err)
+	}
+
+	stealth.RandomDelay("action")
+
+	if cursor.page >= s.cfg.Search.PaginationLimit {
+		s.completeCursor(ctx, cursor)
+	}
+
+	return nil
+}
+
+// completeCursor marks the cursor's search as finished
+func (s *Service) completeCursor(ctx context.Context, cursor *searchCursor) {
+	cursor.done = true
+	cursor.complete = true
+	if err := s.store.CompleteSearchState(cursor.hash); err != nil {
+		logger.FromContext(ctx).Warnf("Failed to mark search complete: %v", err)
+	}
+}
+
+// checkSearchQuota warns when monthly search usage is high and returns
+// ErrSearchQuotaExhausted once it is nearly used up
+func (s *Service) checkSearchQuota(ctx context.Context) error {
+	return CheckSearchQuota(ctx, s.store, s.cfg)
+}
+
+// CheckSearchQuota is checkSearchQuota for searches made outside the search
+// service, such as looking a profile up by name
+func CheckSearchQuota(ctx context.Context, store *storage.Storage, cfg *config.Config) error {
+	if !cfg.Search.TrackSearchQuota {
+		return nil
+	}
+
+	log := logger.FromContext(ctx)
+
+	status, err := store.GetSearchQuotaStatus()
+	if err != nil {
+		log.Warnf("Failed to read search quota: %v", err)
+		return nil
+	}
+
+	switch {
+	case status.Percent > quotaBlockPercent:
+		return fmt.Errorf("%w: %d/%d views used (%.0f%%)", ErrSearchQuotaExhausted, status.Used, status.Limit, status.Percent)
+	case status.Percent > quotaWarnPercent:
+		log.Warnf("Monthly search quota at %.0f%% (%d/%d views)", status.Percent, status.Used, status.Limit)
+	}
+
+	return nil
+}
+
+// recordSearchPage records a viewed results page against the monthly quota
+func (s *Service) recordSearchPage(ctx context.Context, page *rod.Page, pageNum int) {
+	log := logger.FromContext(ctx)
+
+	if header, err := page.Element(s.cfg.Selectors.ResultsCount); err == nil {
+		text, _ := header.Text()
+		log.Debugf("Search page %d of ~%d total results", pageNum, parseResultCount(text))
+	} else {
+		log.Debugf("Selector ResultsCount not found: %v", err)
+	}
+
+	RecordSearchView(ctx, s.store, s.cfg)
+}
+
+// RecordSearchView records one viewed results page against the monthly quota
+// when quota tracking is enabled
+func RecordSearchView(ctx context.Context, store *storage.Storage, cfg *config.Config) {
+	if !cfg.Search.TrackSearchQuota {
+		return
+	}
+	if err := store.RecordSearchViews(1, cfg.Search.MonthlySearchLimit); err != nil {
+		logger.FromContext(ctx).Warnf("Failed to record search quota usage: %v", err)
+	}
 }
 
-// buildSearchURL constructs the LinkedIn search URL
-func (s *Service) buildSearchURL(target config.SearchTarget) string {
+// parseResultCount extracts the number from a header like "About 1,200 results"
+func parseResultCount(text string) int {
+	var digits strings.Builder
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ',' || r == '.':
+			// thousands separators
+		case digits.Len() > 0:
+			n, _ := strconv.Atoi(digits.String())
+			return n
+		}
+	}
+
+	n, _ := strconv.Atoi(digits.String())
+	return n
+}
+
+// TargetHash identifies a search target by the SHA256 of its parameters
+func TargetHash(target config.SearchTarget) string {
+	fields := []string{target.JobTitle, target.Location, target.Keywords, target.Campaign}
+
+	// Only hash schools when set so existing targets keep their saved state
+	if len(target.Schools) > 0 {
+		fields = append(fields, strings.Join(target.Schools, ","))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// buildSearchURL constructs the LinkedIn search URL, filtering by the
+// resolved school IDs when any are given
+func (s *Service) buildSearchURL(target config.SearchTarget, schoolURNs []string) string {
 	baseURL := "https://www.linkedin.com/search/results/people/"
 
 	params := url.Values{}
@@ -148,30 +501,36 @@ func (s *Service) buildSearchURL(target config.SearchTarget) string {
 		params.Add("geoUrn", target.Location)
 	}
 
-	// Add filters for 2nd and 3rd degree connections
-	params.Add("network", "[\"S\",\"O\"]")
+	if len(schoolURNs) > 0 {
+		params.Add("schoolFilter", schoolFilterValue(schoolURNs))
+	}
+
+	// Filter by connection degree: S = 2nd, O = 3rd+
+	params.Add("network", networkFilter(target.ConnectionDegree))
 
 	return baseURL + "?" + params.Encode()
 }
 
 // extractProfilesFromPage extracts profile information from the current page
-func (s *Service) extractProfilesFromPage(page *rod.Page, target config.SearchTarget) ([]*storage.Profile, error) {
+func (s *Service) extractProfilesFromPage(ctx context.Context, page *rod.Page, target config.SearchTarget, filteredSchools []string) ([]*storage.Profile, error) {
+	log := logger.FromContext(ctx)
+
 	// Wait for search results container
 	time.Sleep(2 * time.Second)
 
 	// Find all profile cards
 	// LinkedIn search results are in list items with specific classes
-	elements, err := page.Elements(".reusable-search__result-container")
+	elements, err := page.Elements(s.cfg.Selectors.SearchResultCard)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find search results: %w", err)
+		return nil, fmt.Errorf("failed to find search results (selector SearchResultCard): %w", err)
 	}
 
 	var profiles []*storage.Profile
 
 	for _, element := range elements {
-		profile, err := s.extractProfileFromElement(element, target)
+		profile, err := s.extractProfileFromElement(ctx, element, target, filteredSchools)
 		if err != nil {
-			s.log.Debugf("Failed to extract profile: %v", err)
+			log.Debugf("Failed to extract profile: %v", err)
 			continue
 		}
 
@@ -179,23 +538,49 @@ func (s *Service) extractProfilesFromPage(page *rod.Page, target config.SearchTa
 			// Save to database
 			profileID, err := s.store.SaveProfile(profile)
 			if err != nil {
-				s.log.Errorf("Failed to save profile: %v", err)
+				log.Errorf("Failed to save profile: %v", err)
 				continue
 			}
 			profile.ID = profileID
+			s.queueEnrichment(ctx, profile)
+
+			if profile.LastActiveEstimate != nil {
+				if err := s.store.UpdateLastActiveEstimate(profile.ProfileURL, *profile.LastActiveEstimate); err != nil {
+					log.Warnf("Failed to update activity estimate: %v", err)
+				}
+			}
 			profiles = append(profiles, profile)
 		}
 	}
 
+	s.linkSearchTargetProfiles(ctx, profiles, target)
+
 	return profiles, nil
 }
 
+// linkSearchTargetProfiles records same_search_target relationships between
+// profiles discovered together on a results page
+func (s *Service) linkSearchTargetProfiles(ctx context.Context, profiles []*storage.Profile, target config.SearchTarget) {
+	log := logger.FromContext(ctx)
+
+	metadata := strings.TrimSpace(target.JobTitle + " " + target.Keywords)
+	for i := 0; i < len(profiles); i++ {
+		for j := i + 1; j < len(profiles); j++ {
+			if err := s.store.AddRelationship(profiles[i].ID, profiles[j].ID, "same_search_target", metadata); err != nil {
+				log.Debugf("Failed to link profiles %d and %d: %v", profiles[i].ID, profiles[j].ID, err)
+			}
+		}
+	}
+}
+
 // extractProfileFromElement extracts profile data from a search result element
-func (s *Service) extractProfileFromElement(element *rod.Element, target config.SearchTarget) (*storage.Profile, error) {
+func (s *Service) extractProfileFromElement(ctx context.Context, element *rod.Element, target config.SearchTarget, filteredSchools []string) (*storage.Profile, error) {
+	log := logger.FromContext(ctx)
+
 	// Extract profile URL
-	linkElement, err := element.Element("a.app-aware-link")
+	linkElement, err := element.Element(s.cfg.Selectors.ProfileLink)
 	if err != nil {
-		return nil, fmt.Errorf("profile link not found: %w", err)
+		return nil, fmt.Errorf("profile link not found (selector ProfileLink): %w", err)
 	}
 
 	profileURL, err := linkElement.Attribute("href")
@@ -205,51 +590,245 @@ func (s *Service) extractProfileFromElement(element *rod.Element, target config.
 
 	// Clean URL (remove query parameters)
 	cleanURL := strings.Split(*profileURL, "?")[0]
+	discoveredURL := cleanURL
+	if s.cfg.Search.ResolveRedirects {
+		cleanURL = s.resolveProfileURL(ctx, cleanURL)
+	}
 
 	// Extract name
-	nameElement, err := element.Element(".entity-result__title-text a span[aria-hidden='true']")
+	nameElement, err := element.Element(s.cfg.Selectors.ProfileName)
 	var name string
 	if err == nil {
 		nameText, _ := nameElement.Text()
 		name = strings.TrimSpace(nameText)
+	} else {
+		log.Debugf("Selector ProfileName not found: %v", err)
 	}
 
 	// Extract job title
-	jobTitleElement, err := element.Element(".entity-result__primary-subtitle")
+	jobTitleElement, err := element.Element(s.cfg.Selectors.ProfileTitle)
 	var jobTitle string
 	if err == nil {
 		jobTitleText, _ := jobTitleElement.Text()
 		jobTitle = strings.TrimSpace(jobTitleText)
+	} else {
+		log.Debugf("Selector ProfileTitle not found: %v", err)
 	}
 
 	// Extract company/location
-	secondaryElement, err := element.Element(".entity-result__secondary-subtitle")
+	secondaryElement, err := element.Element(s.cfg.Selectors.ProfileSubtitle)
 	var company string
 	if err == nil {
 		companyText, _ := secondaryElement.Text()
 		company = strings.TrimSpace(companyText)
+	} else {
+		log.Debugf("Selector ProfileSubtitle not found: %v", err)
+	}
+
+	if excluded := s.matchExcludedCompany(ctx, company); excluded != "" {
+		log.Infof("Excluded by company blacklist: %s", company)
+		return nil, nil
 	}
 
 	profile := &storage.Profile{
-		ProfileURL:   cleanURL,
-		Name:         name,
-		JobTitle:     jobTitle,
-		Company:      company,
-		Location:     target.Location,
-		Keywords:     target.Keywords,
-		DiscoveredAt: time.Now(),
+		ProfileURL:    cleanURL,
+		DiscoveredURL: discoveredURL,
+		Name:          name,
+		JobTitle:      jobTitle,
+		Company:       company,
+		Location:      target.Location,
+		Keywords:      target.Keywords,
+		Headline:      s.ExtractHeadline(element),
+		Summary:       s.ExtractSummary(element),
+		DiscoveredAt:  time.Now(),
+	}
+
+	if len(target.Schools) > 0 {
+		cardText, _ := element.Text()
+		profile.School = matchSchool(cardText, target.Schools, filteredSchools)
 	}
 
-	s.log.Debugf("Extracted profile: %s - %s at %s", name, jobTitle, company)
+	if badgeElement, err := element.Element(s.cfg.Selectors.ConnectionDegree); err == nil {
+		badgeText, _ := badgeElement.Text()
+		profile.ConnectionDegree = ParseConnectionDegree(badgeText)
+	}
+
+	// Extract activity recency hint
+	if badgeElement, err := element.Element(s.cfg.Selectors.ActivityBadge); err == nil {
+		badgeText, _ := badgeElement.Text()
+		if estimate, ok := ParseActivityRecency(badgeText, time.Now()); ok {
+			profile.LastActiveEstimate = &estimate
+		}
+	}
+
+	profile.BaseScore = scoring.MatchICP(profile, s.cfg.Search.ICP)
+	profile.Score = scoring.ScoreProfile(profile, s.cfg.Search.ICP, s.cfg.Scoring, time.Now())
+	if s.cfg.Search.MinICPScore > 0 && profile.BaseScore < s.cfg.Search.MinICPScore {
+		log.Infof("Skipping %s: ICP score %.2f below %.2f", name, profile.BaseScore, s.cfg.Search.MinICPScore)
+		return nil, nil
+	}
+
+	log.Debugf("Extracted profile: %s - %s at %s", name, jobTitle, company)
 
 	return profile, nil
 }
 
+// ExtractHeadline returns the headline shown on a search result card, or ""
+// if none of the ProfileHeadline selectors match
+func (s *Service) ExtractHeadline(element *rod.Element) string {
+	return firstTextIn(element, s.cfg.Selectors.ProfileHeadline)
+}
+
+// ExtractSummary returns the summary snippet shown on a search result card,
+// or "" when LinkedIn didn't render one
+func (s *Service) ExtractSummary(element *rod.Element) string {
+	return firstTextIn(element, s.cfg.Selectors.ProfileSummary)
+}
+
+// firstTextIn returns the trimmed text of the first selector that matches a
+// non-empty element inside parent
+func firstTextIn(parent *rod.Element, selectors []string) string {
+	for _, selector := range selectors {
+		has, element, err := parent.Has(selector)
+		if err != nil || !has {
+			continue
+		}
+
+		text, err := element.Text()
+		if err != nil {
+			continue
+		}
+
+		if text = strings.TrimSpace(text); text != "" {
+			return text
+		}
+	}
+
+	return ""
+}
+
+// networkFilter maps a configured connection degree to the network search
+// parameter, defaulting to both 2nd and 3rd+ degree
+func networkFilter(degree int) string {
+	switch degree {
+	case 2:
+		return `["S"]`
+	case 3:
+		return `["O"]`
+	default:
+		return `["S","O"]`
+	}
+}
+
+// degreePattern matches degree badges such as "• 2nd" or "3rd+ degree connection"
+var degreePattern = regexp.MustCompile(`\b([123])(?:st|nd|rd)\+?`)
+
+// ParseConnectionDegree extracts the connection degree (1, 2 or 3) from a
+// search result badge, returning 0 when the badge can't be read
+func ParseConnectionDegree(text string) int {
+	match := degreePattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0
+	}
+
+	degree, _ := strconv.Atoi(match[1])
+	return degree
+}
+
+// matchExcludedCompany returns the blacklist entry matching a company, or ""
+// if the company is not excluded
+func (s *Service) matchExcludedCompany(ctx context.Context, company string) string {
+	if company == "" {
+		return ""
+	}
+
+	excluded := append([]string{}, s.cfg.Search.ExcludedCompanies...)
+	for _, domain := range s.cfg.Search.ExcludedDomains {
+		excluded = append(excluded, domainStem(domain))
+	}
+
+	blacklisted, err := s.store.GetBlacklistedCompanies()
+	if err != nil {
+		logger.FromContext(ctx).Warnf("Failed to load company blacklist: %v", err)
+	}
+	excluded = append(excluded, blacklisted...)
+
+	return MatchExcludedCompany(company, excluded)
+}
+
+// MatchExcludedCompany returns the first exclusion that is a case-insensitive
+// substring of the company name, or "" if none match
+func MatchExcludedCompany(company string, excluded []string) string {
+	lower := strings.ToLower(company)
+	for _, entry := range excluded {
+		entry = strings.TrimSpace(entry)
+		if entry != "" && strings.Contains(lower, strings.ToLower(entry)) {
+			return entry
+		}
+	}
+	return ""
+}
+
+// domainStem reduces a company domain like "www.acme.co.uk" to its name "acme"
+func domainStem(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+	domain = strings.TrimPrefix(domain, "www.")
+	return strings.SplitN(domain, ".", 2)[0]
+}
+
+// activityPattern matches badges like "Active 3 weeks ago" and
+// "Responded to messages within 2 hours"
+var activityPattern = regexp.MustCompile(`(?i)(?:active|within)\s+(\d+|an?)\s+(minute|hour|day|week|month|year)s?`)
+
+// ParseActivityRecency converts an activity badge into an estimate of when the
+// profile was last active
+func ParseActivityRecency(text string, now time.Time) (time.Time, bool) {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	if lower == "" {
+		return time.Time{}, false
+	}
+
+	if strings.Contains(lower, "active now") || strings.Contains(lower, "active today") {
+		return now, true
+	}
+
+	match := activityPattern.FindStringSubmatch(lower)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	n := 1
+	if v, err := strconv.Atoi(match[1]); err == nil {
+		n = v
+	}
+
+	switch match[2] {
+	case "minute":
+		return now.Add(-time.Duration(n) * time.Minute), true
+	case "hour":
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case "day":
+		return now.AddDate(0, 0, -n), true
+	case "week":
+		return now.AddDate(0, 0, -7*n), true
+	case "month":
+		return now.AddDate(0, -n, 0), true
+	case "year":
+		return now.AddDate(-n, 0, 0), true
+	}
+
+	return time.Time{}, false
+}
+
 // goToNextPage attempts to navigate to the next page of search results
-func (s *Service) goToNextPage(page *rod.Page, st *stealth.Stealth) bool {
+func (s *Service) goToNextPage(ctx context.Context, page *rod.Page, st *stealth.Stealth) bool {
+	log := logger.FromContext(ctx)
+
 	// Look for "Next" button
-	nextButton, err := page.Element("button[aria-label='Next']")
+	nextButton, err := page.Element(s.cfg.Selectors.NextPageButton)
 	if err != nil {
+		log.Debugf("Selector NextPageButton not found: %v", err)
 		return false
 	}
 
@@ -260,20 +839,29 @@ func (s *Service) goToNextPage(page *rod.Page, st *stealth.Stealth) bool {
 	}
 
 	// Click next button with human-like behavior
-	if err := st.HumanClick(nextButton); err != nil {
-		s.log.Errorf("Failed to click next button: %v", err)
+	if err := st.HumanClick(ctx, nextButton); err != nil {
+		log.Errorf("Failed to click next button: %v", err)
 		return false
 	}
 
 	// Wait for new results to load
-	time.Sleep(3 * time.Second)
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
 
 	return true
 }
 
 // SearchByURL searches for a specific profile by URL
-func (s *Service) SearchByURL(profileURL string) (*storage.Profile, error) {
-	s.log.Infof("Searching for profile: %s", profileURL)
+func (s *Service) SearchByURL(ctx context.Context, profileURL string) (*storage.Profile, error) {
+	return s.searchByURL(ctx, profileURL, storage.DiscoverySourceSearch)
+}
+
+// searchByURL visits a profile and saves it with the given discovery source
+func (s *Service) searchByURL(ctx context.Context, profileURL, source string) (*storage.Profile, error) {
+	log := logger.FromContext(ctx)
+
+	log.Infof("Searching for profile: %s", profileURL)
 
 	// Check if profile already exists in database
 	existingProfile, err := s.store.GetProfileByURL(profileURL)
@@ -288,22 +876,25 @@ func (s *Service) SearchByURL(profileURL string) (*storage.Profile, error) {
 
 	// Extract profile information
 	page := s.browser.GetPage()
-	time.Sleep(2 * time.Second)
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
 
 	profile := &storage.Profile{
-		ProfileURL:   profileURL,
-		DiscoveredAt: time.Now(),
+		ProfileURL:      profileURL,
+		DiscoveredAt:    time.Now(),
+		DiscoverySource: source,
 	}
 
 	// Extract name
-	nameElement, err := page.Element("h1.text-heading-xlarge")
+	nameElement, err := page.Element(s.cfg.Selectors.ProfilePageName)
 	if err == nil {
 		nameText, _ := nameElement.Text()
 		profile.Name = strings.TrimSpace(nameText)
 	}
 
 	// Extract job title
-	titleElement, err := page.Element(".text-body-medium.break-words")
+	titleElement, err := page.Element(s.cfg.Selectors.ProfilePageTitle)
 	if err == nil {
 		titleText, _ := titleElement.Text()
 		profile.JobTitle = strings.TrimSpace(titleText)
@@ -319,3 +910,86 @@ func (s *Service) SearchByURL(profileURL string) (*storage.Profile, error) {
 	return profile, nil
 }
 
+
+// EnrichProfile visits a profile page and captures details that are not
+// shown on search result cards
+func (s *Service) EnrichProfile(ctx context.Context, profile *storage.Profile) error {
+	log := logger.FromContext(ctx)
+
+	log.Infof("Enriching profile: %s", profile.ProfileURL)
+
+	if err := s.browser.NavigateFor(profile.ProfileURL, storage.VisitPurposeEnrichment); err != nil {
+		return fmt.Errorf("failed to navigate to profile: %w", err)
+	}
+
+	page := s.browser.GetPage()
+	if err := s.browser.WaitForNetworkIdle(page, browser.NetworkIdleTimeout, browser.NetworkIdleMaxWait); err != nil {
+		log.Debugf("Continuing before network idle: %v", err)
+	}
+
+	profile.OpenToWork = detectOpenToWork(page, s.cfg.Selectors.OpenToWorkBadge)
+	if profile.OpenToWork {
+		log.Debugf("Open to work badge found on %s", profile.ProfileURL)
+	}
+
+	if companyURL := extractCompanyURL(page, s.cfg.Selectors.ProfileCompanyLink); companyURL != "" {
+		profile.CompanyURL = companyURL
+	}
+
+	if about := s.extractAbout(ctx, page); about != "" {
+		profile.Summary = about
+	}
+	profile.SummaryKeywords, profile.KeywordDensity = SummaryKeywords(profile.Summary, s.cfg.Search.RelevantKeywords)
+
+	// Before company size inference, which leaves the profile page
+	if s.cfg.Storage.PhotoStorageEnabled && profile.ID != 0 {
+		if err := s.DownloadProfilePhoto(ctx, profile); err != nil {
+			log.Warnf("Failed to download profile photo for %s: %v", profile.ProfileURL, err)
+		}
+	}
+
+	if s.cfg.Search.InferCompanySize && profile.CompanyURL != "" {
+		size, err := s.InferCompanySize(ctx, profile.CompanyURL)
+		if err != nil {
+			log.Warnf("Failed to infer company size for %s: %v", profile.ProfileURL, err)
+		} else {
+			profile.CompanySizeBucket = size
+		}
+	}
+
+	if err := s.store.UpdateProfile(profile); err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	s.store.LogActivityAsync("enrich", profile.ProfileURL, "success", "")
+
+	if min := s.cfg.Search.MinKeywordDensity; min > 0 && profile.KeywordDensity < min {
+		log.Infof("Keyword density %.3f for %s is below %.3f", profile.KeywordDensity, profile.ProfileURL, min)
+		return ErrLowKeywordDensity
+	}
+
+	return nil
+}
+
+// extractCompanyURL returns the LinkedIn company page linked from the current
+// position on a profile page, or "" if there is none
+func extractCompanyURL(page *rod.Page, selector string) string {
+	has, link, err := page.Has(selector)
+	if err != nil || !has {
+		return ""
+	}
+
+	href, err := link.Attribute("href")
+	if err != nil || href == nil || !strings.Contains(*href, "linkedin.com/company/") {
+		return ""
+	}
+
+	return strings.Split(*href, "?")[0]
+}
+
+// detectOpenToWork checks the profile page for the "#OPEN TO WORK" photo frame
+// or the open to work banner card
+func detectOpenToWork(page *rod.Page, selector string) bool {
+	has, _, err := page.Has(selector)
+	return err == nil && has
+}
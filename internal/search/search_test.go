@@ -0,0 +1,587 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+	"linkedin-automation/internal/storage"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// newFixturePage opens a blank page in a headless browser, skipping the test
+// when none is installed
+func newFixturePage(t *testing.T) *rod.Page {
+	t.Helper()
+
+	bin := os.Getenv("CHROME_PATH")
+	if bin == "" {
+		path, found := launcher.LookPath()
+		if !found {
+			t.Skip("no browser installed")
+		}
+		bin = path
+	}
+
+	u, err := launcher.New().Bin(bin).Headless(true).Launch()
+	if err != nil {
+		t.Fatalf("launch browser: %v", err)
+	}
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("connect to browser: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("open page: %v", err)
+	}
+	return page
+}
+
+// fakePages returns a next function for interleavePages that records each
+// visited page as "<title><page>" and finishes a cursor after pages[title]
+// pages or at paginationLimit, like searchNextPage does
+func fakePages(pages map[string]int, paginationLimit int, visited *[]string) func(*searchCursor, int, bool) error {
+	return func(cursor *searchCursor, round int, switched bool) error {
+		title := cursor.target.JobTitle
+		*visited = append(*visited, fmt.Sprintf("%s%d", title, cursor.page+1))
+
+		cursor.page++
+		if cursor.page >= pages[title] || cursor.page >= paginationLimit {
+			cursor.done = true
+		}
+		return nil
+	}
+}
+
+func newCursors(titles ...string) []*searchCursor {
+	cursors := make([]*searchCursor, len(titles))
+	for i, title := range titles {
+		cursors[i] = &searchCursor{target: config.SearchTarget{JobTitle: title}}
+	}
+	return cursors
+}
+
+func TestInterleavePagesOrder(t *testing.T) {
+	tests := []struct {
+		name            string
+		pages           map[string]int
+		paginationLimit int
+		want            []string
+	}{
+		{
+			name:            "three targets with three pages",
+			pages:           map[string]int{"A": 3, "B": 3, "C": 3},
+			paginationLimit: 10,
+			want:            []string{"A1", "B1", "C1", "A2", "B2", "C2", "A3", "B3", "C3"},
+		},
+		{
+			name:            "pagination limit per target",
+			pages:           map[string]int{"A": 3, "B": 3, "C": 3},
+			paginationLimit: 2,
+			want:            []string{"A1", "B1", "C1", "A2", "B2", "C2"},
+		},
+		{
+			name:            "targets running out of pages drop out",
+			pages:           map[string]int{"A": 1, "B": 3, "C": 2},
+			paginationLimit: 10,
+			want:            []string{"A1", "B1", "C1", "B2", "C2", "B3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var visited []string
+			err := interleavePages(context.Background(), newCursors("A", "B", "C"), fakePages(tt.pages, tt.paginationLimit, &visited))
+			if err != nil {
+				t.Fatalf("interleavePages: %v", err)
+			}
+			if !reflect.DeepEqual(visited, tt.want) {
+				t.Errorf("visited %v, want %v", visited, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterleavePagesSwitchedTargets(t *testing.T) {
+	// Once only B is left its next page is already loaded
+	cursors := newCursors("A", "B")
+	pages := map[string]int{"A": 1, "B": 3}
+
+	var switches []bool
+	record := fakePages(pages, 10, new([]string))
+	err := interleavePages(context.Background(), cursors, func(cursor *searchCursor, round int, switched bool) error {
+		switches = append(switches, switched)
+		return record(cursor, round, switched)
+	})
+	if err != nil {
+		t.Fatalf("interleavePages: %v", err)
+	}
+	if want := []bool{true, true, false, false}; !reflect.DeepEqual(switches, want) {
+		t.Errorf("switched = %v, want %v", switches, want)
+	}
+}
+
+func TestInterleavePagesStops(t *testing.T) {
+	var visited []string
+	record := fakePages(map[string]int{"A": 3, "B": 3, "C": 3}, 10, &visited)
+
+	err := interleavePages(context.Background(), newCursors("A", "B", "C"), func(cursor *searchCursor, round int, switched bool) error {
+		if err := record(cursor, round, switched); err != nil {
+			return err
+		}
+		if len(visited) == 4 {
+			return ErrSearchQuotaExhausted
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrSearchQuotaExhausted) || len(visited) != 4 {
+		t.Errorf("after a quota error: %v with %v visited, want ErrSearchQuotaExhausted after 4 pages", err, visited)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	visited = nil
+	err = interleavePages(ctx, newCursors("A", "B", "C"), func(cursor *searchCursor, round int, switched bool) error {
+		if len(visited) == 2 {
+			cancel()
+		}
+		return record(cursor, round, switched)
+	})
+	if !errors.Is(err, context.Canceled) || len(visited) != 3 {
+		t.Errorf("after cancel: %v with %v visited, want context.Canceled after 3 pages", err, visited)
+	}
+}
+
+func TestDetectOpenToWork(t *testing.T) {
+	selector := config.DefaultSelectors().OpenToWorkBadge
+	page := newFixturePage(t)
+
+	tests := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{"badge container", `<div class="pv-top-card-profile-picture"><div class="open-to-work-badge-container"><img src="jane.jpg"></div></div>`, true},
+		{"photo frame aria label", `<div class="pv-top-card-profile-picture"><img aria-label="Jane Doe is Open to work" src="jane.jpg"></div>`, true},
+		{"aria label in another case", `<button class="pv-top-card__photo" aria-label="open to WORK"><img src="jane.jpg"></button>`, true},
+		{"banner card", `<section class="pv-open-to-work-banner"><h2>Open to work</h2><p>Software Engineer roles</p></section>`, true},
+		{"plain photo", `<div class="pv-top-card-profile-picture"><img aria-label="Jane Doe" src="jane.jpg"></div>`, false},
+		{"hiring badge", `<div class="pv-top-card-profile-picture"><span aria-label="Hiring"></span></div><p>Open to work with great teams</p>`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := page.SetDocumentContent(tt.html); err != nil {
+				t.Fatalf("set content: %v", err)
+			}
+			if got := detectOpenToWork(page, selector); got != tt.want {
+				t.Errorf("detectOpenToWork = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseActivityRecency(t *testing.T) {
+	now := time.Date(2024, 3, 10, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text   string
+		want   time.Time
+		wantOK bool
+	}{
+		{"Active 3 weeks ago", now.AddDate(0, 0, -21), true},
+		{"Active 1 month ago", now.AddDate(0, -1, 0), true},
+		{"  active 2 years ago ", now.AddDate(-2, 0, 0), true},
+		{"Active an hour ago", now.Add(-time.Hour), true},
+		{"Responded to messages within 2 hours", now.Add(-2 * time.Hour), true},
+		{"Active now", now, true},
+		{"", time.Time{}, false},
+		{"Open to work", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseActivityRecency(tt.text, now)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("ParseActivityRecency(%q) = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMatchExcludedCompany(t *testing.T) {
+	excluded := []string{" ", "Initech", "globex"}
+
+	tests := []struct {
+		company string
+		want    string
+	}{
+		{"Initech", "Initech"},
+		{"Senior Engineer at INITECH Labs", "Initech"},
+		{"Globex Corporation", "globex"},
+		{"Acme", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := MatchExcludedCompany(tt.company, excluded); got != tt.want {
+			t.Errorf("MatchExcludedCompany(%q) = %q, want %q", tt.company, got, tt.want)
+		}
+	}
+}
+
+func TestExtractProfileSkipsBlacklistedCompanies(t *testing.T) {
+	page := newFixturePage(t)
+
+	card := func(username, company string) string {
+		return `<li class="entity-result">
+  <span class="entity-result__title-text">
+    <a class="app-aware-link" href="https://www.linkedin.com/in/` + username + `?miniProfileUrn=abc"><span aria-hidden="true">` + username + `</span></a>
+  </span>
+  <div class="entity-result__primary-subtitle">Engineer</div>
+  <div class="entity-result__secondary-subtitle">` + company + `</div>
+</li>`
+	}
+	html := "<ul>" + card("jane", "Acme") + card("john", "Initech") + card("jim", "Globex Corporation") + card("joan", "Umbrella Corp") + "</ul>"
+	if err := page.SetDocumentContent(html); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.AddBlacklistedCompany("umbrella"); err != nil {
+		t.Fatalf("AddBlacklistedCompany: %v", err)
+	}
+
+	cfg := &config.Config{Selectors: config.DefaultSelectors()}
+	cfg.Search.ExcludedCompanies = []string{"initech"}
+	cfg.Search.ExcludedDomains = []string{"https://www.globex.com"}
+	s := &Service{cfg: cfg, store: store}
+
+	var kept []string
+	for _, element := range page.MustElements(".entity-result") {
+		// Cards lack the optional badges, so don't wait long for them
+		profile, err := s.extractProfileFromElement(context.Background(), element.Timeout(time.Second), config.SearchTarget{}, nil)
+		if err != nil {
+			t.Fatalf("extractProfileFromElement: %v", err)
+		}
+		if profile != nil {
+			kept = append(kept, profile.Company)
+		}
+	}
+
+	if !reflect.DeepEqual(kept, []string{"Acme"}) {
+		t.Errorf("kept companies %v, want only Acme", kept)
+	}
+}
+
+func TestExtractProfileFiltersBelowMinICPScore(t *testing.T) {
+	page := newFixturePage(t)
+
+	card := func(username, title string) string {
+		return `<li class="entity-result">
+  <span class="entity-result__title-text">
+    <a class="app-aware-link" href="https://www.linkedin.com/in/` + username + `"><span aria-hidden="true">` + username + `</span></a>
+  </span>
+  <div class="entity-result__primary-subtitle">` + title + `</div>
+  <div class="entity-result__secondary-subtitle">Acme Software</div>
+</li>`
+	}
+	html := "<ul>" + card("jane", "VP of Sales") + card("john", "Sales Associate") + "</ul>"
+	if err := page.SetDocumentContent(html); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	cfg := &config.Config{Selectors: config.DefaultSelectors()}
+	cfg.Search.ICP = config.ICPConfig{Industries: []string{"software"}, Seniorities: []string{"VP"}, Locations: []string{"London"}}
+	cfg.Search.MinICPScore = 0.6
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	s := &Service{cfg: cfg, store: store}
+
+	var kept []string
+	for _, element := range page.MustElements(".entity-result") {
+		profile, err := s.extractProfileFromElement(context.Background(), element.Timeout(time.Second), config.SearchTarget{}, nil)
+		if err != nil {
+			t.Fatalf("extractProfileFromElement: %v", err)
+		}
+		if profile != nil {
+			kept = append(kept, profile.Name)
+			if profile.BaseScore < cfg.Search.MinICPScore {
+				t.Errorf("kept %s with ICP score %.2f", profile.Name, profile.BaseScore)
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(kept, []string{"jane"}) {
+		t.Errorf("kept %v, want only the VP above the threshold", kept)
+	}
+}
+
+// newStateTestService returns a service over a fresh database along with
+// the database path, for tests of saved search state
+func newStateTestService(t *testing.T) (*Service, *storage.Storage, string) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	store, err := storage.New(dbPath, 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := &config.Config{Selectors: config.DefaultSelectors()}
+	cfg.Search.PaginationLimit = 10
+	return &Service{cfg: cfg, store: store}, store, dbPath
+}
+
+func TestOpenCursorResumesInterruptedSearch(t *testing.T) {
+	target := config.SearchTarget{JobTitle: "CTO", Location: "Berlin"}
+	const savedURL = "https://www.linkedin.com/search/results/people/?keywords=CTO&page=4"
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, store *storage.Storage, dbPath, hash string)
+		wantResumed bool
+	}{
+		{"no saved state", func(*testing.T, *storage.Storage, string, string) {}, false},
+		{"interrupted search", func(t *testing.T, store *storage.Storage, _, hash string) {
+			saveSearchProgress(t, store, hash, 3, savedURL)
+		}, true},
+		{"completed search", func(t *testing.T, store *storage.Storage, _, hash string) {
+			saveSearchProgress(t, store, hash, 3, savedURL)
+			if err := store.CompleteSearchState(hash); err != nil {
+				t.Fatalf("CompleteSearchState: %v", err)
+			}
+		}, false},
+		{"interrupted over a day ago", func(t *testing.T, store *storage.Storage, dbPath, hash string) {
+			saveSearchProgress(t, store, hash, 3, savedURL)
+			db, err := sql.Open("sqlite", dbPath)
+			if err != nil {
+				t.Fatalf("open database: %v", err)
+			}
+			defer db.Close()
+			startedAt := time.Now().Add(-25 * time.Hour).UTC().Format("2006-01-02 15:04:05")
+			if _, err := db.Exec(`UPDATE search_state SET started_at = ?`, startedAt); err != nil {
+				t.Fatalf("set started_at: %v", err)
+			}
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, dbPath := newStateTestService(t)
+			hash := TargetHash(target)
+			tt.setup(t, store, dbPath, hash)
+
+			cursor, err := s.openCursor(context.Background(), target)
+			if err != nil {
+				t.Fatalf("openCursor: %v", err)
+			}
+
+			if tt.wantResumed {
+				if !cursor.resumed || cursor.page != 3 || cursor.url != savedURL {
+					t.Errorf("cursor = page %d at %s (resumed %v), want page 3 at the saved URL", cursor.page, cursor.url, cursor.resumed)
+				}
+				return
+			}
+
+			if cursor.resumed || cursor.page != 0 || cursor.url != s.buildSearchURL(target, nil) {
+				t.Errorf("cursor = page %d at %s (resumed %v), want a fresh search", cursor.page, cursor.url, cursor.resumed)
+			}
+			state, err := store.GetSearchState(hash)
+			if err != nil || state == nil || state.LastPage != 0 || state.CompletedAt != nil {
+				t.Errorf("saved state = %+v, %v, want a restarted search", state, err)
+			}
+		})
+	}
+}
+
+func TestOpenCursorCompletesSearchPastPaginationLimit(t *testing.T) {
+	s, store, _ := newStateTestService(t)
+	target := config.SearchTarget{JobTitle: "CTO"}
+	hash := TargetHash(target)
+
+	saveSearchProgress(t, store, hash, s.cfg.Search.PaginationLimit, "https://www.linkedin.com/search/results/people/?keywords=CTO&page=11")
+
+	cursor, err := s.openCursor(context.Background(), target)
+	if err != nil {
+		t.Fatalf("openCursor: %v", err)
+	}
+	if !cursor.done || !cursor.complete {
+		t.Errorf("cursor done = %v, complete = %v, want the search finished", cursor.done, cursor.complete)
+	}
+	if state, err := store.GetSearchState(hash); err != nil || state.CompletedAt == nil {
+		t.Errorf("saved state = %+v, %v, want completed_at set", state, err)
+	}
+}
+
+// saveSearchProgress records an in-progress search for hash
+func saveSearchProgress(t *testing.T, store *storage.Storage, hash string, lastPage int, nextURL string) {
+	t.Helper()
+
+	if err := store.StartSearchState(hash, "https://www.linkedin.com/search/results/people/"); err != nil {
+		t.Fatalf("StartSearchState: %v", err)
+	}
+	if err := store.UpdateSearchProgress(hash, lastPage, nextURL); err != nil {
+		t.Fatalf("UpdateSearchProgress: %v", err)
+	}
+}
+
+func TestExtractHeadlineAndSummary(t *testing.T) {
+	page := newFixturePage(t)
+	s := &Service{cfg: &config.Config{Selectors: config.DefaultSelectors()}}
+
+	tests := []struct {
+		name        string
+		html        string
+		wantHead    string
+		wantSummary string
+	}{
+		{
+			"visible summary",
+			`<li class="entity-result">
+  <div class="entity-result__primary-subtitle">CTO at Acme</div>
+  <p class="entity-result__summary--2-lines">Past: Staff Engineer at Globex</p>
+</li>`,
+			"CTO at Acme", "Past: Staff Engineer at Globex",
+		},
+		{
+			"snippet as headline",
+			`<li class="entity-result">
+  <div class="entity-result__primary-subtitle">Engineering</div>
+  <p class="entity-result__summary">  Building payments infrastructure </p>
+</li>`,
+			"Building payments infrastructure", "Building payments infrastructure",
+		},
+		{
+			"no summary",
+			`<li class="entity-result"><div class="entity-result__primary-subtitle">CTO at Acme</div></li>`,
+			"CTO at Acme", "",
+		},
+		{
+			"empty summary",
+			`<li class="entity-result">
+  <div class="entity-result__primary-subtitle">CTO at Acme</div>
+  <p class="entity-result__content-summary">   </p>
+</li>`,
+			"CTO at Acme", "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := page.SetDocumentContent(tt.html); err != nil {
+				t.Fatalf("set content: %v", err)
+			}
+			card := page.MustElement(".entity-result")
+
+			if got := s.ExtractHeadline(card); got != tt.wantHead {
+				t.Errorf("ExtractHeadline = %q, want %q", got, tt.wantHead)
+			}
+			if got := s.ExtractSummary(card); got != tt.wantSummary {
+				t.Errorf("ExtractSummary = %q, want %q", got, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestParseConnectionDegree(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"• 1st", 1},
+		{"• 2nd", 2},
+		{"3rd+", 3},
+		{"3rd+ degree connection", 3},
+		{"Out of network", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseConnectionDegree(tt.text); got != tt.want {
+			t.Errorf("ParseConnectionDegree(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtractConnectionDegreeFromSearchResults(t *testing.T) {
+	page := newFixturePage(t)
+
+	card := func(username, badge string) string {
+		html := `<li class="entity-result">
+  <span class="entity-result__title-text">
+    <a class="app-aware-link" href="https://www.linkedin.com/in/` + username + `"><span aria-hidden="true">` + username + `</span></a>
+  </span>`
+		if badge != "" {
+			html += `<div class="entity-result__badge"><span class="entity-result__badge-text"><span aria-hidden="true">` + badge + `</span><span class="visually-hidden">` + badge + ` degree connection</span></span></div>`
+		}
+		return html + "</li>"
+	}
+	html := "<ul>" + card("first", "• 1st") + card("second", "• 2nd") + card("third", "• 3rd+") + card("nobadge", "") + "</ul>"
+	if err := page.SetDocumentContent(html); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	s := &Service{cfg: &config.Config{Selectors: config.DefaultSelectors()}, store: store}
+
+	want := map[string]int{"first": 1, "second": 2, "third": 3, "nobadge": 0}
+	for _, element := range page.MustElements(".entity-result") {
+		// Cards lack the other optional badges, so don't wait long for them
+		profile, err := s.extractProfileFromElement(context.Background(), element.Timeout(time.Second), config.SearchTarget{}, nil)
+		if err != nil {
+			t.Fatalf("extractProfileFromElement: %v", err)
+		}
+		if got := profile.ConnectionDegree; got != want[profile.Name] {
+			t.Errorf("%s: ConnectionDegree = %d, want %d", profile.Name, got, want[profile.Name])
+		}
+	}
+}
+
+func TestBuildSearchURLNetworkFilter(t *testing.T) {
+	s := &Service{cfg: &config.Config{}}
+
+	tests := []struct {
+		degree int
+		want   string
+	}{
+		{2, `["S"]`},
+		{3, `["O"]`},
+		{0, `["S","O"]`},
+	}
+
+	for _, tt := range tests {
+		searchURL := s.buildSearchURL(config.SearchTarget{JobTitle: "CTO", ConnectionDegree: tt.degree}, nil)
+		parsed, err := url.Parse(searchURL)
+		if err != nil {
+			t.Fatalf("parse %s: %v", searchURL, err)
+		}
+		if got := parsed.Query().Get("network"); got != tt.want {
+			t.Errorf("degree %d: network = %s, want %s", tt.degree, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package stealth
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+)
+
+// tabNavigationRate is how often a form field is reached with Tab instead
+// of a click when keyboard shortcuts are enabled
+const tabNavigationRate = 0.3
+
+// maxTabPresses is how many times Tab is pressed looking for a field before
+// falling back to clicking it
+const maxTabPresses = 3
+
+// KeyboardShortcuts reports whether messages are sent and fields reached
+// with the keyboard, see config.StealthConfig.UseKeyboardShortcuts
+func (s *Stealth) KeyboardShortcuts() bool {
+	return s.sc.UseKeyboardShortcuts
+}
+
+// KeyboardSend sends the focused message with Ctrl+Enter, or Meta+Enter when
+// the browser reports a Mac platform
+func (s *Stealth) KeyboardSend(page *rod.Page) error {
+	modifier := input.ControlLeft
+	if isMacPlatform(s.detectPlatform(page)) {
+		modifier = input.MetaLeft
+	}
+
+	time.Sleep(time.Duration(100+rand.Intn(200)) * time.Millisecond)
+
+	if err := page.Keyboard.Press(modifier); err != nil {
+		return fmt.Errorf("failed to press modifier: %w", err)
+	}
+	time.Sleep(time.Duration(50+rand.Intn(100)) * time.Millisecond)
+
+	err := page.Keyboard.Type(input.Enter)
+	if releaseErr := page.Keyboard.Release(modifier); err == nil && releaseErr != nil {
+		err = releaseErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to press Enter: %w", err)
+	}
+
+	s.log.Debug("Message sent with keyboard shortcut")
+	s.actionCount++
+	return nil
+}
+
+// FocusField moves focus to a form field, occasionally with Tab from the
+// current field when keyboard shortcuts are enabled and otherwise with a
+// human click
+func (s *Stealth) FocusField(ctx context.Context, element *rod.Element) error {
+	if s.sc.UseKeyboardShortcuts && rand.Float64() < tabNavigationRate {
+		if focused, err := s.tabTo(element); err == nil && focused {
+			return nil
+		}
+	}
+
+	return s.HumanClick(ctx, element)
+}
+
+// tabTo presses Tab until element, or a field inside it, has focus and
+// reports whether it got there within maxTabPresses
+func (s *Stealth) tabTo(element *rod.Element) (bool, error) {
+	page := element.Page()
+
+	for i := 0; i < maxTabPresses; i++ {
+		if err := page.Keyboard.Type(input.Tab); err != nil {
+			return false, err
+		}
+		time.Sleep(time.Duration(150+rand.Intn(250)) * time.Millisecond)
+
+		focused, err := element.Eval(`function() {
+			return this === document.activeElement || this.contains(document.activeElement)
+		}`)
+		if err != nil {
+			return false, err
+		}
+		if focused.Value.Bool() {
+			s.log.Debug("Reached field with Tab")
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// detectPlatform returns navigator.platform, "" when it can't be read
+func (s *Stealth) detectPlatform(page *rod.Page) string {
+	result, err := page.Eval(`() => navigator.platform`)
+	if err != nil {
+		s.log.Debugf("Failed to read navigator.platform: %v", err)
+		return ""
+	}
+	return result.Value.Str()
+}
+
+// isMacPlatform reports whether a navigator.platform value is macOS
+func isMacPlatform(platform string) bool {
+	return strings.HasPrefix(platform, "Mac")
+}
@@ -0,0 +1,114 @@
+package stealth
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod"
+)
+
+// keyboardFixture records the Enter key presses reaching a message box and
+// the modifier keys released
+const keyboardFixture = `<html><body>
+<div class="msg-form__contenteditable" contenteditable="true">Hi Jane</div>
+<script>
+	window.enterPresses = [];
+	window.releasedModifiers = [];
+	document.addEventListener("keydown", e => {
+		if (e.key === "Enter") window.enterPresses.push({ctrl: e.ctrlKey, meta: e.metaKey});
+	});
+	document.addEventListener("keyup", e => {
+		if (e.key === "Control" || e.key === "Meta") window.releasedModifiers.push(e.key);
+	});
+</script>
+</body></html>`
+
+// setPlatform overrides navigator.platform for the current document
+func setPlatform(t *testing.T, page *rod.Page, platform string) {
+	t.Helper()
+
+	if _, err := page.Eval(`platform => Object.defineProperty(navigator, "platform", {get: () => platform})`, platform); err != nil {
+		t.Fatalf("set platform: %v", err)
+	}
+}
+
+func TestKeyboardSend(t *testing.T) {
+	page := newFixturePage(t)
+
+	tests := []struct {
+		platform string
+		ctrl     bool
+		meta     bool
+	}{
+		{"Linux x86_64", true, false},
+		{"Win32", true, false},
+		{"MacIntel", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			if err := page.SetDocumentContent(keyboardFixture); err != nil {
+				t.Fatalf("set content: %v", err)
+			}
+			setPlatform(t, page, tt.platform)
+			page.MustElement(".msg-form__contenteditable").MustFocus()
+
+			s := newTestStealth()
+			s.sc.UseKeyboardShortcuts = true
+			if err := s.KeyboardSend(page); err != nil {
+				t.Fatalf("KeyboardSend: %v", err)
+			}
+
+			presses := page.MustEval(`() => window.enterPresses`).Arr()
+			if len(presses) != 1 {
+				t.Fatalf("Enter pressed %d times, want once", len(presses))
+			}
+			ctrl, meta := presses[0].Get("ctrl").Bool(), presses[0].Get("meta").Bool()
+			if ctrl != tt.ctrl || meta != tt.meta {
+				t.Errorf("Enter modifiers ctrl=%v meta=%v, want ctrl=%v meta=%v", ctrl, meta, tt.ctrl, tt.meta)
+			}
+
+			// The modifier is released afterwards
+			if released := page.MustEval(`() => window.releasedModifiers`).Arr(); len(released) != 1 {
+				t.Errorf("released %d modifier keys, want 1", len(released))
+			}
+		})
+	}
+}
+
+func TestTabToField(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(`<html><body>
+		<input id="subject"><textarea id="body"></textarea><button>Send</button>
+	</body></html>`); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+	page.MustElement("#subject").MustFocus()
+
+	s := newTestStealth()
+	if focused, err := s.tabTo(page.MustElement("#body")); err != nil || !focused {
+		t.Fatalf("tabTo(next field) = %v, %v, want focused", focused, err)
+	}
+
+	// A field out of reach falls back to clicking
+	page.MustElement("#subject").MustFocus()
+	page.MustEval(`() => document.getElementById("body").tabIndex = -1`)
+	if focused, err := s.tabTo(page.MustElement("#body")); err != nil || focused {
+		t.Errorf("tabTo(untabbable field) = %v, %v, want not focused", focused, err)
+	}
+}
+
+func TestIsMacPlatform(t *testing.T) {
+	tests := map[string]bool{
+		"MacIntel":     true,
+		"Macintosh":    true,
+		"Win32":        false,
+		"Linux x86_64": false,
+		"iPhone":       false,
+		"":             false,
+	}
+	for platform, want := range tests {
+		if got := isMacPlatform(platform); got != want {
+			t.Errorf("isMacPlatform(%q) = %v, want %v", platform, got, want)
+		}
+	}
+}
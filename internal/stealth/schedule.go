@@ -0,0 +1,128 @@
+package stealth
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Action types with their own slots in an ActivitySchedule
+const (
+	ActionConnection = "connection"
+	ActionMessage    = "message"
+)
+
+// ActivitySchedule spreads each action type's hourly limit over the hour, so
+// actions don't all go out at the top of the hour. Slots are computed when
+// an hour is first used.
+type ActivitySchedule struct {
+	mu      sync.Mutex
+	perHour map[string]int
+	hours   map[string]time.Time
+	slots   map[string][]time.Time
+
+	// now is replaceable for tests
+	now func() time.Time
+}
+
+// NewActivitySchedule creates a schedule with the given actions per hour for
+// each action type
+func NewActivitySchedule(perHour map[string]int) *ActivitySchedule {
+	return &ActivitySchedule{
+		perHour: perHour,
+		hours:   make(map[string]time.Time),
+		slots:   make(map[string][]time.Time),
+		now:     time.Now,
+	}
+}
+
+// NextScheduledSlot returns the next unused slot of the current hour for an
+// action type without using it. Slots missed while busy are skipped, apart
+// from the latest, so a late start doesn't turn into a burst. It returns the
+// zero time once the hour's slots are used up.
+func (a *ActivitySchedule) NextScheduledSlot(actionType string) time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	slots := a.pendingSlots(actionType)
+	if len(slots) == 0 {
+		return time.Time{}
+	}
+	return slots[0]
+}
+
+// SlotDue reports whether the next slot of an action type has arrived. It
+// never waits: when the slot is still ahead the caller should end its run
+// and try again later, so active hours, pausing and session checks are
+// re-evaluated in between.
+func (a *ActivitySchedule) SlotDue(actionType string) bool {
+	slot := a.NextScheduledSlot(actionType)
+	return !slot.IsZero() && !slot.After(a.now())
+}
+
+// UseSlot marks the next slot of an action type used. Call it once the
+// action actually went out, so failed attempts don't use up the hour.
+func (a *ActivitySchedule) UseSlot(actionType string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if slots := a.pendingSlots(actionType); len(slots) > 0 {
+		a.slots[actionType] = slots[1:]
+	}
+}
+
+// pendingSlots returns the unused slots of the current hour, computing them
+// when the hour is first used and dropping missed ones. a.mu must be held.
+func (a *ActivitySchedule) pendingSlots(actionType string) []time.Time {
+	now := a.now()
+	hour := now.Truncate(time.Hour)
+	if !a.hours[actionType].Equal(hour) {
+		a.hours[actionType] = hour
+		a.slots[actionType] = scheduleSlots(now, hour.Add(time.Hour), a.hourlyShare(actionType, now, hour))
+	}
+
+	slots := a.slots[actionType]
+	for len(slots) > 1 && !slots[1].After(now) {
+		slots = slots[1:]
+	}
+	a.slots[actionType] = slots
+
+	return slots
+}
+
+// hourlyShare is the number of actions for the rest of the hour when the
+// schedule starts part way through it
+func (a *ActivitySchedule) hourlyShare(actionType string, now, hour time.Time) int {
+	perHour := a.perHour[actionType]
+	remaining := hour.Add(time.Hour).Sub(now)
+	return int(math.Ceil(float64(perHour) * float64(remaining) / float64(time.Hour)))
+}
+
+// scheduleSlots returns n action times between start and end. Each slot
+// gets an equal share of the window and is placed at an exponentially
+// distributed offset into its share, the gaps of a Poisson process, so slots
+// are roughly even without being regular.
+func scheduleSlots(start, end time.Time, n int) []time.Time {
+	if n <= 0 || !end.After(start) {
+		return nil
+	}
+
+	share := end.Sub(start) / time.Duration(n)
+	slots := make([]time.Time, n)
+	for i := range slots {
+		offset := time.Duration(rand.ExpFloat64() * float64(share) / 4)
+		if offset >= share {
+			offset = share - 1
+		}
+		slots[i] = start.Add(time.Duration(i)*share + offset)
+	}
+
+	return slots
+}
+
+// ActivitySmoothing reports whether actions should wait for their slot in
+// an ActivitySchedule, see config.StealthConfig.ActivitySmoothing
+func (s *Stealth) ActivitySmoothing() bool {
+	return s.sc.ActivitySmoothing
+}
@@ -0,0 +1,57 @@
+package stealth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleSlotsEvenlySpaced(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	slots := scheduleSlots(start, end, 10)
+	if len(slots) != 10 {
+		t.Fatalf("got %d slots, want 10", len(slots))
+	}
+
+	share := 6 * time.Minute
+	for i, slot := range slots {
+		lo := start.Add(time.Duration(i) * share)
+		if slot.Before(lo) || !slot.Before(lo.Add(share)) {
+			t.Errorf("slot %d at %s is outside its share %s-%s", i, slot.Format("15:04:05"), lo.Format("15:04:05"), lo.Add(share).Format("15:04:05"))
+		}
+		if i > 0 && !slot.After(slots[i-1]) {
+			t.Errorf("slot %d is not after slot %d", i, i-1)
+		}
+	}
+}
+
+func TestSlotDueNeverWaits(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	schedule := NewActivitySchedule(map[string]int{ActionConnection: 4})
+	schedule.now = func() time.Time { return now }
+
+	first := schedule.NextScheduledSlot(ActionConnection)
+	if first.IsZero() {
+		t.Fatal("expected a slot this hour")
+	}
+
+	// Before the slot nothing is due, and asking doesn't use it up
+	now = first.Add(-time.Second)
+	if schedule.SlotDue(ActionConnection) {
+		t.Error("slot reported due before its time")
+	}
+	if next := schedule.NextScheduledSlot(ActionConnection); !next.Equal(first) {
+		t.Errorf("next slot moved to %s without being used", next)
+	}
+
+	// Once due, the slot stays due until it is used
+	now = first
+	if !schedule.SlotDue(ActionConnection) || !schedule.SlotDue(ActionConnection) {
+		t.Fatal("slot not due at its time")
+	}
+	schedule.UseSlot(ActionConnection)
+	if next := schedule.NextScheduledSlot(ActionConnection); !next.After(first) {
+		t.Errorf("next slot %s is not after the used one %s", next, first)
+	}
+}
@@ -0,0 +1,186 @@
+package stealth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// DefaultStealthTestURL is the public bot detection page used by the self-test
+const DefaultStealthTestURL = "https://bot.sannysoft.com/"
+
+// criticalChecks are detection tests that the browser stealth patches are
+// expected to pass; failing any of them fails the self-test
+var criticalChecks = []string{
+	"webdriver",
+	"chrome",
+	"permissions",
+	"plugins length",
+	"languages",
+	"user agent",
+}
+
+// SelfTestResult is a single row of the bot detection report
+type SelfTestResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"` // passed, failed, warn
+	Value    string `json:"value"`
+	Critical bool   `json:"critical"`
+}
+
+// SelfTestReport summarizes a run against a bot detection page
+type SelfTestReport struct {
+	URL        string           `json:"url"`
+	RanAt      time.Time        `json:"ran_at"`
+	Screenshot string           `json:"screenshot,omitempty"`
+	Results    []SelfTestResult `json:"results"`
+}
+
+// RunSelfTest loads a bot detection page and extracts its pass/fail table
+func (s *Stealth) RunSelfTest(page *rod.Page, url string) (*SelfTestReport, error) {
+	if url == "" {
+		url = DefaultStealthTestURL
+	}
+
+	s.log.Infof("Running stealth self-test against %s", url)
+
+	if err := page.Navigate(url); err != nil {
+		return nil, fmt.Errorf("failed to navigate to test page: %w", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return nil, fmt.Errorf("test page load failed: %w", err)
+	}
+
+	// Give the detection scripts time to finish
+	time.Sleep(5 * time.Second)
+
+	html, err := page.HTML()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test page: %w", err)
+	}
+
+	return &SelfTestReport{
+		URL:     url,
+		RanAt:   time.Now(),
+		Results: ParseSelfTestResults(html),
+	}, nil
+}
+
+// ParseSelfTestResults extracts result rows from a bot detection page. Rows are
+// table rows whose value cell carries a passed, failed or warn class.
+func ParseSelfTestResults(html string) []SelfTestResult {
+	var results []SelfTestResult
+
+	for _, row := range strings.Split(html, "<tr")[1:] {
+		row = row[:indexOr(row, "</tr>", len(row))]
+
+		cells := strings.Split(row, "<td")[1:]
+		if len(cells) < 2 {
+			continue
+		}
+
+		status := cellStatus(cells[len(cells)-1])
+		if status == "" {
+			continue
+		}
+
+		name := stripTags(cells[0])
+		results = append(results, SelfTestResult{
+			Name:     name,
+			Status:   status,
+			Value:    stripTags(cells[len(cells)-1]),
+			Critical: isCriticalCheck(name),
+		})
+	}
+
+	return results
+}
+
+// HasCriticalFailure reports whether any critical check failed
+func (r *SelfTestReport) HasCriticalFailure() bool {
+	for _, result := range r.Results {
+		if result.Critical && result.Status == "failed" {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the report as a human-readable table
+func (r *SelfTestReport) String() string {
+	var b strings.Builder
+
+	passed, failed := 0, 0
+	fmt.Fprintf(&b, "Stealth self-test: %s (%s)\n", r.URL, r.RanAt.Format("2006-01-02 15:04:05"))
+	for _, result := range r.Results {
+		marker := " "
+		if result.Critical {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "%s %-8s %-35s %s\n", marker, strings.ToUpper(result.Status), result.Name, result.Value)
+
+		switch result.Status {
+		case "passed":
+			passed++
+		case "failed":
+			failed++
+		}
+	}
+	fmt.Fprintf(&b, "\n%d passed, %d failed (* = critical)\n", passed, failed)
+
+	return b.String()
+}
+
+// cellStatus returns the passed/failed/warn class of a table cell
+func cellStatus(cell string) string {
+	open := cell[:indexOr(cell, ">", len(cell))]
+	for _, status := range []string{"passed", "failed", "warn"} {
+		if strings.Contains(open, status) {
+			return status
+		}
+	}
+	return ""
+}
+
+// isCriticalCheck matches a result name against criticalChecks
+func isCriticalCheck(name string) bool {
+	lower := strings.ToLower(name)
+	for _, check := range criticalChecks {
+		if strings.Contains(lower, check) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripTags removes HTML tags from a table cell fragment and trims whitespace
+func stripTags(fragment string) string {
+	// Drop the remainder of the opening <td ...> tag
+	fragment = fragment[indexOr(fragment, ">", -1)+1:]
+	fragment = fragment[:indexOr(fragment, "</td>", len(fragment))]
+
+	var b strings.Builder
+	inTag := false
+	for _, r := range fragment {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// indexOr returns the index of substr in s, or fallback if not found
+func indexOr(s, substr string, fallback int) int {
+	if i := strings.Index(s, substr); i >= 0 {
+		return i
+	}
+	return fallback
+}
@@ -0,0 +1,84 @@
+package stealth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// selfTestFixture mimics the result tables of bot.sannysoft.com
+const selfTestFixture = `<html><body>
+<h1>Intoli.com tests + additions</h1>
+<table>
+  <tr><th>Test Name</th><th>Result</th></tr>
+  <tr>
+    <td>User Agent <span class="tiny">(Old)</span></td>
+    <td class="result passed" id="user-agent-result">Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0.0.0</td>
+  </tr>
+  <tr>
+    <td>WebDriver (New)</td>
+    <td class="failed" id="webdriver-result">present (failed)</td>
+  </tr>
+  <tr>
+    <td>Chrome (New)</td>
+    <td class="passed" id="chrome-result">present (passed)</td>
+  </tr>
+  <tr>
+    <td>Plugins Length (Old)</td>
+    <td class="passed" id="plugins-length-result">5</td>
+  </tr>
+  <tr><td>Broken Image Dimensions</td><td class="warn">16x16</td></tr>
+  <tr><td>Notes</td><td>not a result row</td></tr>
+</table>
+<table>
+  <tr><td>WebGL Vendor</td><td>Google Inc.</td><td class="failed">Google SwiftShader</td></tr>
+</table>
+</body></html>`
+
+func TestParseSelfTestResults(t *testing.T) {
+	want := []SelfTestResult{
+		{Name: "User Agent (Old)", Status: "passed", Value: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0.0.0", Critical: true},
+		{Name: "WebDriver (New)", Status: "failed", Value: "present (failed)", Critical: true},
+		{Name: "Chrome (New)", Status: "passed", Value: "present (passed)", Critical: true},
+		{Name: "Plugins Length (Old)", Status: "passed", Value: "5", Critical: true},
+		{Name: "Broken Image Dimensions", Status: "warn", Value: "16x16"},
+		{Name: "WebGL Vendor", Status: "failed", Value: "Google SwiftShader"},
+	}
+
+	got := ParseSelfTestResults(selfTestFixture)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSelfTestResults =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := ParseSelfTestResults("<html><body>no tables</body></html>"); len(got) != 0 {
+		t.Errorf("page without tables parsed %+v, want no results", got)
+	}
+}
+
+func TestSelfTestReportCriticalFailure(t *testing.T) {
+	report := &SelfTestReport{
+		URL:     DefaultStealthTestURL,
+		RanAt:   time.Date(2024, 3, 10, 14, 0, 0, 0, time.UTC),
+		Results: ParseSelfTestResults(selfTestFixture),
+	}
+	if !report.HasCriticalFailure() {
+		t.Error("HasCriticalFailure = false with WebDriver failed, want true")
+	}
+
+	out := report.String()
+	for _, line := range []string{"* FAILED   WebDriver (New)", "  FAILED   WebGL Vendor", "3 passed, 2 failed"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("report missing %q:\n%s", line, out)
+		}
+	}
+
+	// A non-critical failure alone doesn't fail the self-test
+	report.Results = []SelfTestResult{
+		{Name: "WebDriver (New)", Status: "passed", Critical: true},
+		{Name: "WebGL Vendor", Status: "failed"},
+	}
+	if report.HasCriticalFailure() {
+		t.Error("HasCriticalFailure = true with only a non-critical failure, want false")
+	}
+}
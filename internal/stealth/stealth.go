@@ -1,9 +1,13 @@
 package stealth
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"strings"
 	"time"
 
 	"linkedin-automation/internal/config"
@@ -16,16 +20,76 @@ import (
 )
 
 type Stealth struct {
-	cfg         *config.Config
-	log         *logrus.Logger
-	actionCount int
+	cfg              *config.Config
+	sc               config.StealthConfig
+	log              *logrus.Logger
+	actionCount      int
+	focusActionCount int
+	page             *rod.Page
+	viewport         Viewport
+	userAgent        string
+
+	// safeMode reports whether safe mode is active; nil means never
+	safeMode func() bool
 }
 
-func New(cfg *config.Config) *Stealth {
+// Viewport is the page size chosen for the session
+type Viewport struct {
+	Width             int
+	Height            int
+	DeviceScaleFactor float64
+}
+
+// New creates a stealth engine using the settings for the given module, see
+// config.GetStealthConfig
+func New(cfg *config.Config, module string) *Stealth {
 	return &Stealth{
 		cfg: cfg,
+		sc:  cfg.GetStealthConfig(module),
 		log: logger.Get(),
+		viewport: Viewport{
+			Width:             cfg.Browser.Viewport.Width,
+			Height:            cfg.Browser.Viewport.Height,
+			DeviceScaleFactor: 1,
+		},
+	}
+}
+
+// SetViewport records the session's viewport so mouse movements stay within it
+func (s *Stealth) SetViewport(viewport Viewport) {
+	s.viewport = viewport
+}
+
+// SetUserAgent records the session's user agent so spoofed values such as
+// the WebGL renderer match its OS
+func (s *Stealth) SetUserAgent(userAgent string) {
+	s.userAgent = userAgent
+}
+
+// SetSafeMode makes the engine follow the SafeMode settings whenever
+// active reports true
+func (s *Stealth) SetSafeMode(active func() bool) {
+	s.safeMode = active
+}
+
+// inSafeMode reports whether safe mode is currently active
+func (s *Stealth) inSafeMode() bool {
+	return s.safeMode != nil && s.safeMode()
+}
+
+// hasTechnique reports whether a technique is enabled, counting every
+// technique as enabled in safe mode with ForceFullStealth
+func (s *Stealth) hasTechnique(name string) bool {
+	if s.cfg.SafeMode.ForceFullStealth && s.inSafeMode() {
+		return true
 	}
+	return s.sc.HasTechnique(name)
+}
+
+// AttachPage sets the main automation page, used to open background tabs
+// for idle browsing
+func (s *Stealth) AttachPage(page *rod.Page) {
+	s.page = page
 }
 
 // ApplyBrowserStealth applies stealth techniques to the browser
@@ -83,47 +147,203 @@ func (s *Stealth) ApplyBrowserStealth(page *rod.Page) error {
 		return fmt.Errorf("failed to override languages: %w", err)
 	}
 
+	if err := s.applyTechniques(page); err != nil {
+		return err
+	}
+
 	s.log.Info("Browser stealth techniques applied")
 	return nil
 }
 
+// PlatformInfo holds the navigator values matching a user agent's OS
+type PlatformInfo struct {
+	Platform       string // navigator.platform
+	OSCPU          string // navigator.oscpu
+	UADataPlatform string // navigator.userAgentData.platform
+}
+
+// PlatformForUserAgent derives navigator platform values from the OS in a
+// user agent string, defaulting to Windows
+func PlatformForUserAgent(userAgent string) PlatformInfo {
+	switch {
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		return PlatformInfo{Platform: "MacIntel", OSCPU: "Intel Mac OS X 10_15_7", UADataPlatform: "macOS"}
+	case strings.Contains(userAgent, "Linux"), strings.Contains(userAgent, "X11"):
+		return PlatformInfo{Platform: "Linux x86_64", OSCPU: "Linux x86_64", UADataPlatform: "Linux"}
+	default:
+		return PlatformInfo{Platform: "Win32", OSCPU: "Windows NT 10.0; Win64; x64", UADataPlatform: "Windows"}
+	}
+}
+
+// SpoofPlatform makes navigator.platform, oscpu, appVersion,
+// userAgentData.platform and vendor consistent with the spoofed user agent.
+// The overrides are registered for new documents and applied to the current one.
+// Technique 13: Platform consistency
+func (s *Stealth) SpoofPlatform(userAgent string) error {
+	if s.page == nil {
+		return fmt.Errorf("no page attached")
+	}
+
+	info := PlatformForUserAgent(userAgent)
+	script := `(platform, oscpu, appVersion, uaDataPlatform) => {
+		const define = (obj, prop, value) => {
+			try {
+				Object.defineProperty(obj, prop, { get: () => value, configurable: true });
+			} catch (e) {}
+		};
+		define(Navigator.prototype, 'platform', platform);
+		define(Navigator.prototype, 'oscpu', oscpu);
+		define(Navigator.prototype, 'appVersion', appVersion);
+		define(Navigator.prototype, 'vendor', 'Google Inc.');
+		if (navigator.userAgentData) {
+			define(Object.getPrototypeOf(navigator.userAgentData), 'platform', uaDataPlatform);
+		}
+	}`
+	appVersion := strings.TrimPrefix(userAgent, "Mozilla/")
+
+	args, err := json.Marshal([]string{info.Platform, info.OSCPU, appVersion, info.UADataPlatform})
+	if err != nil {
+		return fmt.Errorf("failed to encode platform values: %w", err)
+	}
+
+	if _, err := s.page.EvalOnNewDocument(fmt.Sprintf("(%s).apply(null, %s)", script, args)); err != nil {
+		return fmt.Errorf("failed to register platform spoofing: %w", err)
+	}
+
+	if _, err := s.page.Eval(script, info.Platform, info.OSCPU, appVersion, info.UADataPlatform); err != nil {
+		return fmt.Errorf("failed to spoof platform: %w", err)
+	}
+
+	s.log.Debugf("Platform spoofed as %s", info.Platform)
+	return nil
+}
+
 // RandomDelay introduces a random delay based on configuration
 func (s *Stealth) RandomDelay(delayType string) {
-	var min, max int
+	var delayCfg config.DelayConfig
 
 	switch delayType {
 	case "action":
-		min = s.cfg.Stealth.ActionDelay.Min
-		max = s.cfg.Stealth.ActionDelay.Max
+		delayCfg = s.sc.ActionDelay
 	case "scroll":
-		min = s.cfg.Stealth.ScrollDelay.Min
-		max = s.cfg.Stealth.ScrollDelay.Max
+		delayCfg = s.sc.ScrollDelay
 	case "typing":
-		min = s.cfg.Stealth.TypingDelay.Min
-		max = s.cfg.Stealth.TypingDelay.Max
+		delayCfg = s.sc.TypingDelay
 	case "think":
-		min = s.cfg.Stealth.ThinkTime.Min
-		max = s.cfg.Stealth.ThinkTime.Max
+		delayCfg = s.sc.ThinkTime
 	default:
-		min = 1000
-		max = 3000
+		delayCfg = config.DelayConfig{Min: 1000, Max: 3000}
 	}
 
-	delay := min + rand.Intn(max-min+1)
+	// Safe mode shifts action delays up so they start at MinActionDelayMs
+	if safeMin := s.cfg.SafeMode.MinActionDelayMs; delayType == "action" && s.inSafeMode() && delayCfg.Min < safeMin {
+		delayCfg.Max += safeMin - delayCfg.Min
+		delayCfg.Min = safeMin
+	}
+
+	s.DelayWith(delayType, delayCfg)
+}
+
+// DelayWith sleeps for a random duration within delayCfg, honoring UsePoisson.
+// The name only labels the debug log.
+func (s *Stealth) DelayWith(delayType string, delayCfg config.DelayConfig) {
+	delay := s.delayMs(delayCfg)
+
 	s.log.Debugf("Random %s delay: %dms", delayType, delay)
 	time.Sleep(time.Duration(delay) * time.Millisecond)
 }
 
+// delayMs draws a delay in milliseconds within delayCfg, from a Poisson
+// process clamped to [Min, Max] when UsePoisson is set and uniformly otherwise
+func (s *Stealth) delayMs(delayCfg config.DelayConfig) int {
+	min, max := delayCfg.Min, delayCfg.Max
+
+	var delay int
+	if s.sc.UsePoisson {
+		// Fall back to the midpoint of the range when no lambda is configured
+		lambda := delayCfg.PoissonLambdaMs
+		if lambda <= 0 {
+			lambda = float64(min+max) / 2
+		}
+
+		delay = int(s.PoissonDelay(lambda) / time.Millisecond)
+		if delay < min {
+			delay = min
+		}
+		if delay > max {
+			delay = max
+		}
+	} else {
+		delay = min + rand.Intn(max-min+1)
+	}
+
+	return delay
+}
+
+// DefaultReadingWPM is the reading speed used when stealth.reading_wpm is unset
+const DefaultReadingWPM = 200
+
+// CalculateReadingDelay returns how long reading text takes at the
+// configured words per minute
+func (s *Stealth) CalculateReadingDelay(text string) time.Duration {
+	wpm := s.sc.ReadingWPM
+	if wpm <= 0 {
+		wpm = DefaultReadingWPM
+	}
+
+	words := len(strings.Fields(text))
+	return time.Duration(words) * time.Minute / time.Duration(wpm)
+}
+
+// ProfilePreviewDelay pauses before opening a profile picked from search
+// results, as if reading its snippet. The ProfilePreviewDelay range (the
+// think time range when unset) is extended by the time to read texts.
+func (s *Stealth) ProfilePreviewDelay(texts ...string) {
+	delayCfg := s.sc.ProfilePreviewDelay
+	if delayCfg.Max <= 0 {
+		delayCfg = s.sc.ThinkTime
+	}
+
+	reading := int(s.CalculateReadingDelay(strings.Join(texts, " ")) / time.Millisecond)
+	delayCfg.Min += reading
+	delayCfg.Max += reading
+	if delayCfg.PoissonLambdaMs > 0 {
+		delayCfg.PoissonLambdaMs += float64(reading)
+	}
+
+	s.DelayWith("profile preview", delayCfg)
+}
+
+// VisitProfileBeforeConnect reports whether to open a profile before
+// connecting, true with probability ProfileVisitBeforeConnectProbability
+func (s *Stealth) VisitProfileBeforeConnect() bool {
+	return rand.Float64() < s.sc.ProfileVisitBeforeConnectProbability
+}
+
+// PoissonDelay draws an exponentially distributed inter-action delay with the
+// given mean (in milliseconds) using inverse transform sampling: -ln(U)/lambda
+func (s *Stealth) PoissonDelay(lambdaMs float64) time.Duration {
+	if lambdaMs <= 0 {
+		return 0
+	}
+
+	// rand.Float64 returns [0, 1); use 1-U to avoid ln(0)
+	u := 1 - rand.Float64()
+	delayMs := -math.Log(u) * lambdaMs
+
+	return time.Duration(delayMs * float64(time.Millisecond))
+}
+
 // HumanMouseMove moves the mouse in a human-like way using Bezier curves
 // Technique 6: Bezier curve mouse movement
 func (s *Stealth) HumanMouseMove(page *rod.Page, targetX, targetY float64) error {
-	if !s.cfg.Stealth.EnableMouseMovement {
+	if !s.hasTechnique(config.TechniqueHumanMouse) {
 		return nil
 	}
 
 	// Get current mouse position (start from random position if first move)
-	startX := rand.Float64() * float64(s.cfg.Browser.Viewport.Width)
-	startY := rand.Float64() * float64(s.cfg.Browser.Viewport.Height)
+	startX := rand.Float64() * float64(s.viewport.Width)
+	startY := rand.Float64() * float64(s.viewport.Height)
 
 	// Generate control points for Bezier curve
 	cp1X := startX + (targetX-startX)*0.25 + (rand.Float64()-0.5)*100
@@ -131,8 +351,15 @@ func (s *Stealth) HumanMouseMove(page *rod.Page, targetX, targetY float64) error
 	cp2X := startX + (targetX-startX)*0.75 + (rand.Float64()-0.5)*100
 	cp2Y := startY + (targetY-startY)*0.75 + (rand.Float64()-0.5)*100
 
-	// Number of steps for smooth movement
-	steps := 20 + rand.Intn(10)
+	// Occasionally overshoot slightly past the target and correct afterwards
+	endX, endY := targetX, targetY
+	overshoot := rand.Float64() < overshootProbability
+	if overshoot {
+		endX, endY = overshootPoint(startX, startY, targetX, targetY)
+	}
+
+	// Longer movements take more steps, so speed grows with distance
+	steps := calculateSteps(startX, startY, endX, endY)
 
 	for i := 0; i <= steps; i++ {
 		t := float64(i) / float64(steps)
@@ -141,23 +368,66 @@ func (s *Stealth) HumanMouseMove(page *rod.Page, targetX, targetY float64) error
 		x := math.Pow(1-t, 3)*startX +
 			3*math.Pow(1-t, 2)*t*cp1X +
 			3*(1-t)*math.Pow(t, 2)*cp2X +
-			math.Pow(t, 3)*targetX
+			math.Pow(t, 3)*endX
 
 		y := math.Pow(1-t, 3)*startY +
 			3*math.Pow(1-t, 2)*t*cp1Y +
 			3*(1-t)*math.Pow(t, 2)*cp2Y +
-			math.Pow(t, 3)*targetY
+			math.Pow(t, 3)*endY
 
 		page.Mouse.MoveLinear(proto.Point{X: x, Y: y}, 1)
 		time.Sleep(time.Duration(10+rand.Intn(20)) * time.Millisecond)
 	}
 
+	// Correct an overshoot with a short straight move back onto the target
+	if overshoot {
+		time.Sleep(50 * time.Millisecond)
+		page.Mouse.MoveLinear(proto.Point{X: targetX, Y: targetY}, 3+rand.Intn(3))
+	}
+
 	s.log.Debugf("Human mouse move to (%.0f, %.0f)", targetX, targetY)
 	return nil
 }
 
+const (
+	// overshootProbability is the chance a mouse movement passes the target
+	overshootProbability = 0.2
+	// pixelsPerStep controls how quickly step counts grow with distance
+	pixelsPerStep = 40
+	minMouseSteps = 10
+	maxMouseSteps = 50
+)
+
+// calculateSteps returns the number of Bezier steps for a movement,
+// proportional to its pixel distance and clamped to [10, 50]
+func calculateSteps(startX, startY, targetX, targetY float64) int {
+	distance := math.Hypot(targetX-startX, targetY-startY)
+
+	steps := int(distance / pixelsPerStep)
+	if steps < minMouseSteps {
+		steps = minMouseSteps
+	}
+	if steps > maxMouseSteps {
+		steps = maxMouseSteps
+	}
+	return steps
+}
+
+// overshootPoint returns a point 5-15px past the target along the direction
+// of travel
+func overshootPoint(startX, startY, targetX, targetY float64) (float64, float64) {
+	dx, dy := targetX-startX, targetY-startY
+	distance := math.Hypot(dx, dy)
+	if distance == 0 {
+		return targetX, targetY
+	}
+
+	extra := 5 + rand.Float64()*10
+	return targetX + dx/distance*extra, targetY + dy/distance*extra
+}
+
 // HumanClick performs a human-like click with movement and delay
-func (s *Stealth) HumanClick(element *rod.Element) error {
+func (s *Stealth) HumanClick(ctx context.Context, element *rod.Element) error {
 	// Move mouse to element with Bezier curve
 	box, err := element.Shape()
 	if err != nil {
@@ -177,6 +447,12 @@ func (s *Stealth) HumanClick(element *rod.Element) error {
 	centerY += (rand.Float64() - 0.5) * 10
 
 	page := element.Page()
+
+	// Occasionally click next to the target first, as if by accident
+	if s.shouldMissClick() {
+		s.missClick(page, element, box.Quads[0])
+	}
+
 	s.HumanMouseMove(page, centerX, centerY)
 
 	// Small delay before click
@@ -191,43 +467,124 @@ func (s *Stealth) HumanClick(element *rod.Element) error {
 	s.actionCount++
 
 	// Check if we should take an idle break
-	s.MaybeIdleBreak()
+	s.MaybeIdleBreak(ctx, page)
 
 	return nil
 }
 
+// shouldMissClick reports whether the next click should be preceded by an
+// accidental miss, at the configured AccidentalMissRate
+func (s *Stealth) shouldMissClick() bool {
+	return s.sc.AccidentalMissRate > 0 && rand.Float64() < s.sc.AccidentalMissRate
+}
+
+// missClick clicks on empty space 15-30px outside the target, then pauses
+// briefly as a person would before correcting. quad is the target's border
+// quad (x1,y1 ... x4,y4, clockwise from top-left).
+func (s *Stealth) missClick(page *rod.Page, target *rod.Element, quad []float64) {
+	x, y, ok := s.findMissPoint(page, target, quad)
+	if !ok {
+		s.log.Debug("No safe spot for an accidental miss, skipping")
+		return
+	}
+
+	s.HumanMouseMove(page, x, y)
+	if err := page.Mouse.MoveTo(proto.Point{X: x, Y: y}); err != nil {
+		s.log.Debugf("Accidental miss move failed: %v", err)
+		return
+	}
+
+	if err := page.Mouse.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		s.log.Debugf("Accidental miss click failed: %v", err)
+		return
+	}
+
+	s.log.Debugf("Accidental miss click at (%.0f, %.0f), correcting", x, y)
+	time.Sleep(time.Duration(200+rand.Intn(301)) * time.Millisecond)
+}
+
+// findMissPoint picks a point just outside the target that doesn't land on
+// the target itself or on another interactive element, retrying a few
+// different offsets before giving up
+func (s *Stealth) findMissPoint(page *rod.Page, target *rod.Element, quad []float64) (float64, float64, bool) {
+	for attempt := 0; attempt < 3; attempt++ {
+		x, y := missCandidate(quad, rand.Intn(4), 15+rand.Float64()*15)
+		if x < 0 || y < 0 {
+			continue
+		}
+
+		hit, err := page.ElementFromPoint(int(x), int(y))
+		if err != nil {
+			continue
+		}
+
+		if inside, err := target.ContainsElement(hit); err != nil || inside {
+			continue
+		}
+
+		if isInteractive(hit) {
+			continue
+		}
+
+		return x, y, true
+	}
+
+	return 0, 0, false
+}
+
+// missCandidate returns the point offset pixels outside the middle of one
+// side of the quad: 0 left, 1 right, 2 top, anything else bottom
+func missCandidate(quad []float64, side int, offset float64) (float64, float64) {
+	left, top, right, bottom := quad[0], quad[1], quad[2], quad[5]
+	midX, midY := (left+right)/2, (top+bottom)/2
+
+	switch side {
+	case 0:
+		return left - offset, midY
+	case 1:
+		return right + offset, midY
+	case 2:
+		return midX, top - offset
+	default:
+		return midX, bottom + offset
+	}
+}
+
+// isInteractive reports whether clicking the element (or one of its
+// ancestors) would trigger an action
+func isInteractive(element *rod.Element) bool {
+	res, err := element.Eval(`() => !!this.closest(
+		'a, button, input, textarea, select, label, summary, [role="button"], [role="link"], [role="menuitem"], [onclick], [contenteditable="true"]'
+	)`)
+	if err != nil {
+		// Treat unknown elements as unsafe
+		return true
+	}
+
+	return res.Value.Bool()
+}
+
 // HumanType types text in a human-like way with random delays and occasional mistakes
 // Technique 7: Human typing simulation with mistakes
-func (s *Stealth) HumanType(element *rod.Element, text string) error {
-	if !s.cfg.Stealth.EnableHumanTyping {
+func (s *Stealth) HumanType(ctx context.Context, element *rod.Element, text string) error {
+	if !s.hasTechnique(config.TechniqueHumanTyping) {
 		return element.Input(text)
 	}
 
-	// Click on element first
-	if err := s.HumanClick(element); err != nil {
+	// Click on element first, or Tab to it
+	if err := s.FocusField(ctx, element); err != nil {
 		return err
 	}
 
-	// Type each character with random delay
-	for i, char := range text {
-		// 5% chance of making a typo
-		if rand.Float64() < 0.05 && i < len(text)-1 {
-			// Type wrong character
-			wrongChar := rune('a' + rand.Intn(26))
-			element.Page().Keyboard.Type(input.Key(wrongChar))
-			time.Sleep(time.Duration(s.cfg.Stealth.TypingDelay.Min+rand.Intn(s.cfg.Stealth.TypingDelay.Max-s.cfg.Stealth.TypingDelay.Min)) * time.Millisecond)
+	keyboard := element.Page().Keyboard
+	for _, key := range s.planKeystrokes(text, typoRate) {
+		time.Sleep(key.wait)
 
-			// Backspace
-			element.Page().Keyboard.Press(input.Backspace)
-			time.Sleep(time.Duration(100+rand.Intn(100)) * time.Millisecond)
+		if key.backspace {
+			keyboard.Press(input.Backspace)
+		} else {
+			keyboard.Type(input.Key(key.char))
 		}
-
-		// Type correct character
-		element.Page().Keyboard.Type(input.Key(char))
-
-		// Random delay between keystrokes
-		delay := s.cfg.Stealth.TypingDelay.Min + rand.Intn(s.cfg.Stealth.TypingDelay.Max-s.cfg.Stealth.TypingDelay.Min)
-		time.Sleep(time.Duration(delay) * time.Millisecond)
 	}
 
 	s.log.Debugf("Human typed: %s", text)
@@ -237,7 +594,7 @@ func (s *Stealth) HumanType(element *rod.Element, text string) error {
 // RandomScroll performs random scrolling on the page
 // Technique 8: Random scrolling behavior
 func (s *Stealth) RandomScroll(page *rod.Page) error {
-	if !s.cfg.Stealth.EnableRandomScrolling {
+	if !s.hasTechnique(config.TechniqueRandomScroll) {
 		return nil
 	}
 
@@ -264,13 +621,13 @@ func (s *Stealth) RandomScroll(page *rod.Page) error {
 // MouseHover performs random mouse hovering
 // Technique 9: Mouse hovering and wandering
 func (s *Stealth) MouseHover(page *rod.Page) error {
-	if !s.cfg.Stealth.EnableMouseHovering {
+	if !s.sc.EnableMouseHovering {
 		return nil
 	}
 
 	// Random position on page
-	x := rand.Float64() * float64(s.cfg.Browser.Viewport.Width)
-	y := rand.Float64() * float64(s.cfg.Browser.Viewport.Height)
+	x := rand.Float64() * float64(s.viewport.Width)
+	y := rand.Float64() * float64(s.viewport.Height)
 
 	s.HumanMouseMove(page, x, y)
 
@@ -283,30 +640,183 @@ func (s *Stealth) MouseHover(page *rod.Page) error {
 
 // MaybeIdleBreak takes an idle break if the action count threshold is reached
 // Technique 10: Idle breaks and cool-down periods
-func (s *Stealth) MaybeIdleBreak() {
-	if !s.cfg.Stealth.EnableIdleBreaks {
+func (s *Stealth) MaybeIdleBreak(ctx context.Context, page *rod.Page) {
+	s.maybeSimulateFocusLoss(page)
+
+	if !s.hasTechnique(config.TechniqueIdleBreaks) {
 		return
 	}
 
-	if s.actionCount >= s.cfg.Stealth.IdleBreak.FrequencyActions {
-		duration := s.cfg.Stealth.IdleBreak.MinDurationSeconds +
-			rand.Intn(s.cfg.Stealth.IdleBreak.MaxDurationSeconds-s.cfg.Stealth.IdleBreak.MinDurationSeconds)
+	if s.actionCount >= s.sc.IdleBreak.FrequencyActions {
+		duration := s.sc.IdleBreak.MinDurationSeconds +
+			rand.Intn(s.sc.IdleBreak.MaxDurationSeconds-s.sc.IdleBreak.MinDurationSeconds)
+
+		// Reset first so clicks made while idle browsing don't trigger another break
+		s.actionCount = 0
 
 		s.log.Infof("Taking idle break for %d seconds", duration)
-		time.Sleep(time.Duration(duration) * time.Second)
+		if s.sc.EnableIdleBrowsing {
+			err := s.SimulateIdleBrowsing(ctx, duration)
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			s.log.Warnf("Idle browsing failed, sleeping instead: %v", err)
+		}
 
-		s.actionCount = 0
+		// Shutdown ends the break early
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(duration) * time.Second):
+		}
 	}
 }
 
-// WaitForElement waits for an element with human-like behavior
-func (s *Stealth) WaitForElement(page *rod.Page, selector string, timeout time.Duration) (*rod.Element, error) {
+// idleBrowsingURLs are the LinkedIn pages visited during idle breaks. They are
+// read-only pages where scrolling has no side effects.
+var idleBrowsingURLs = []string{
+	"https://www.linkedin.com/feed/",
+	"https://www.linkedin.com/notifications/",
+	"https://www.linkedin.com/mynetwork/",
+}
+
+// SimulateIdleBrowsing casually browses the feed, notifications and network
+// pages in a background tab for the given duration, so idle breaks look like
+// a user reading LinkedIn rather than a silent gap. The main page is left
+// untouched and brought back to the front afterwards.
+func (s *Stealth) SimulateIdleBrowsing(ctx context.Context, durationSeconds int) error {
+	if s.page == nil {
+		return fmt.Errorf("no page attached for idle browsing")
+	}
+
+	tab, err := s.page.Browser().Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return fmt.Errorf("failed to open idle browsing tab: %w", err)
+	}
+	defer func() {
+		tab.Close()
+		s.page.Activate()
+	}()
+
+	if err := s.ApplyBrowserStealth(tab); err != nil {
+		s.log.Warnf("Failed to apply stealth to idle browsing tab: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Duration(durationSeconds) * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		url := idleBrowsingURLs[rand.Intn(len(idleBrowsingURLs))]
+		s.log.Debugf("Idle browsing: %s", url)
+
+		if err := tab.Navigate(url); err != nil {
+			return fmt.Errorf("idle navigation to %s failed: %w", url, err)
+		}
+		if err := tab.WaitLoad(); err != nil {
+			return fmt.Errorf("idle page load failed: %w", err)
+		}
+
+		s.SimulateReading(tab)
+		s.RandomScroll(tab)
+
+		// Linger on the page, but not past the end of the break
+		linger := time.Duration(5+rand.Intn(15)) * time.Second
+		if remaining := time.Until(deadline); linger > remaining {
+			linger = remaining
+		}
+		if linger > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(linger):
+			}
+		}
+	}
+
+	return nil
+}
+
+// maybeSimulateFocusLoss switches away from the tab every FocusLossIntervalActions actions
+func (s *Stealth) maybeSimulateFocusLoss(page *rod.Page) {
+	if !s.hasTechnique(config.TechniqueFocusBlur) || s.sc.FocusLossIntervalActions <= 0 {
+		return
+	}
+
+	s.focusActionCount++
+	if s.focusActionCount < s.sc.FocusLossIntervalActions {
+		return
+	}
+	s.focusActionCount = 0
+
+	if err := s.SimulateFocusLoss(page); err != nil {
+		s.log.Warnf("Focus loss simulation failed: %v", err)
+	}
+}
+
+// SimulateFocusLoss makes the page believe the user switched to another tab
+// for a few seconds before coming back
+// Technique 12: Tab focus and visibility simulation
+func (s *Stealth) SimulateFocusLoss(page *rod.Page) error {
+	_, err := page.Eval(`() => {
+		Object.defineProperty(document, 'visibilityState', { configurable: true, get: () => 'hidden' });
+		Object.defineProperty(document, 'hidden', { configurable: true, get: () => true });
+		document.hasFocus = () => false;
+		window.dispatchEvent(new Event('blur'));
+		document.dispatchEvent(new Event('blur'));
+		document.dispatchEvent(new Event('visibilitychange'));
+	}`)
+	if err != nil {
+		return fmt.Errorf("failed to simulate focus loss: %w", err)
+	}
+
+	away := time.Duration(2000+rand.Intn(13000)) * time.Millisecond
+	s.log.Debugf("Simulating tab focus loss for %s", away)
+	time.Sleep(away)
+
+	// Removing the own-property overrides restores the native getters
+	_, err = page.Eval(`() => {
+		delete document.visibilityState;
+		delete document.hidden;
+		delete document.hasFocus;
+		window.dispatchEvent(new Event('focus'));
+		document.dispatchEvent(new Event('focus'));
+		document.dispatchEvent(new Event('visibilitychange'));
+	}`)
+	if err != nil {
+		return fmt.Errorf("failed to restore focus: %w", err)
+	}
+
+	return nil
+}
+
+// ErrSelectorNotFound is returned by WaitForElement when none of the
+// selectors matched, even after waiting for the page to stabilize
+var ErrSelectorNotFound = errors.New("selector not found")
+
+// WaitForElement waits for the first of the selectors to match, trying them
+// concurrently. Pages that render after JS hydration often miss the first
+// wait, so it then waits for network requests to settle and tries once more
+// before returning ErrSelectorNotFound.
+func (s *Stealth) WaitForElement(page *rod.Page, selectors []string, timeout time.Duration) (*rod.Element, error) {
 	// Add some think time before searching
 	s.RandomDelay("think")
 
-	element, err := page.Timeout(timeout).Element(selector)
+	element, err := raceSelectors(page, selectors, timeout)
 	if err != nil {
-		return nil, fmt.Errorf("element not found: %w", err)
+		start := time.Now()
+
+		stabilize := time.Duration(s.sc.StabilizationWaitMs) * time.Millisecond
+		page.Timeout(timeout).WaitRequestIdle(stabilize, nil, nil, nil)()
+
+		element, err = raceSelectors(page, selectors, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("%w (tried %q): %v", ErrSelectorNotFound, selectors, err)
+		}
+
+		s.log.Debugf("Element %q appeared after page stabilized for %s", selectors, time.Since(start).Round(time.Millisecond))
 	}
 
 	// Small delay after finding element
@@ -315,6 +825,26 @@ func (s *Stealth) WaitForElement(page *rod.Page, selector string, timeout time.D
 	return element, nil
 }
 
+// raceSelectors returns the element of whichever selector matches first
+func raceSelectors(page *rod.Page, selectors []string, timeout time.Duration) (*rod.Element, error) {
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("no selectors given")
+	}
+
+	race := page.Timeout(timeout).Race()
+	for _, selector := range selectors {
+		race.Element(selector)
+	}
+
+	element, err := race.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	// Race elements carry the timeout context, so drop it before returning
+	return element.CancelTimeout(), nil
+}
+
 // SimulateReading simulates reading content on the page
 // Technique 11: Reading simulation
 func (s *Stealth) SimulateReading(page *rod.Page) {
@@ -328,3 +858,75 @@ func (s *Stealth) SimulateReading(page *rod.Page) {
 
 	s.log.Debug("Reading simulation performed")
 }
+
+// defaultPreambleURLs are innocuous sites visited before LinkedIn when no
+// preamble URLs are configured
+var defaultPreambleURLs = []string{
+	"https://www.wikipedia.org/",
+	"https://news.ycombinator.com/",
+	"https://www.bbc.com/news",
+	"https://github.com/trending",
+	"https://stackoverflow.com/questions",
+}
+
+// SimulateBrowsingHistory visits a few non-LinkedIn sites on a fresh session
+// so the tab doesn't start with an empty history
+func (s *Stealth) SimulateBrowsingHistory(ctx context.Context, page *rod.Page) error {
+	if !s.sc.EnableBrowsingPreamble {
+		return nil
+	}
+
+	// Only run the preamble on fresh sessions
+	result, err := page.Eval(`() => window.history.length`)
+	if err != nil {
+		return fmt.Errorf("failed to read history length: %w", err)
+	}
+	if result.Value.Int() > 1 {
+		s.log.Debug("Browser history already present, skipping preamble")
+		return nil
+	}
+
+	for _, url := range s.pickPreambleURLs() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		s.log.Debugf("Browsing preamble: %s", url)
+
+		if err := page.Navigate(url); err != nil {
+			return fmt.Errorf("preamble navigation to %s failed: %w", url, err)
+		}
+		if err := page.WaitLoad(); err != nil {
+			return fmt.Errorf("preamble page load failed: %w", err)
+		}
+
+		s.SimulateReading(page)
+		s.RandomDelay("think")
+	}
+
+	s.log.Info("Browsing preamble completed")
+	return nil
+}
+
+// pickPreambleURLs selects 2-4 distinct preamble URLs in random order
+func (s *Stealth) pickPreambleURLs() []string {
+	urls := s.sc.PreambleURLs
+	if len(urls) == 0 {
+		urls = defaultPreambleURLs
+	}
+
+	shuffled := make([]string, len(urls))
+	copy(shuffled, urls)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	count := 2 + rand.Intn(3)
+	if count > len(shuffled) {
+		count = len(shuffled)
+	}
+
+	return shuffled[:count]
+}
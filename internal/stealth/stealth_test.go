@@ -0,0 +1,711 @@
+package stealth
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// newFixturePage opens a blank page in a headless browser, skipping the test
+// when no browser is installed. CHROME_PATH overrides the browser like in
+// browser.New.
+func newFixturePage(t *testing.T) *rod.Page {
+	t.Helper()
+
+	bin := os.Getenv("CHROME_PATH")
+	if bin == "" {
+		path, found := launcher.LookPath()
+		if !found {
+			t.Skip("no browser installed")
+		}
+		bin = path
+	}
+
+	u, err := launcher.New().Bin(bin).Headless(true).Launch()
+	if err != nil {
+		t.Fatalf("launch browser: %v", err)
+	}
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("connect to browser: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		t.Fatalf("open page: %v", err)
+	}
+	return page
+}
+
+// newTestStealth returns a stealth engine with only the given techniques
+// enabled
+func newTestStealth(techniques ...string) *Stealth {
+	cfg := &config.Config{}
+	cfg.Stealth.Techniques = techniques
+	return New(cfg, "test")
+}
+
+func TestMaybeIdleBreakStopsOnShutdown(t *testing.T) {
+	for _, browsing := range []bool{false, true} {
+		s := newTestStealth(config.TechniqueIdleBreaks)
+		s.sc.IdleBreak = config.IdleBreakConfig{FrequencyActions: 1, MinDurationSeconds: 600, MaxDurationSeconds: 601}
+		s.sc.EnableIdleBrowsing = browsing
+		s.actionCount = 1
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		s.MaybeIdleBreak(ctx, nil)
+		cancel()
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("idle break with browsing=%v ran %s after shutdown", browsing, elapsed)
+		}
+		if s.actionCount != 0 {
+			t.Errorf("actionCount = %d after the break, want 0", s.actionCount)
+		}
+	}
+}
+
+func TestIdleBrowsingURLsAreSafe(t *testing.T) {
+	safe := map[string]bool{
+		"/feed/":          true,
+		"/notifications/": true,
+		"/mynetwork/":     true,
+	}
+
+	for _, raw := range idleBrowsingURLs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Errorf("parse %q: %v", raw, err)
+			continue
+		}
+		if u.Scheme != "https" || u.Host != "www.linkedin.com" || u.RawQuery != "" || !safe[u.Path] {
+			t.Errorf("idle browsing URL %q is not a read-only LinkedIn page", raw)
+		}
+	}
+}
+
+func TestShouldMissClickRate(t *testing.T) {
+	const samples = 10000
+
+	for _, rate := range []float64{0, 0.2} {
+		s := newTestStealth()
+		s.sc.AccidentalMissRate = rate
+
+		misses := 0
+		for i := 0; i < samples; i++ {
+			if s.shouldMissClick() {
+				misses++
+			}
+		}
+
+		if got := float64(misses) / samples; got < rate-0.02 || got > rate+0.02 {
+			t.Errorf("miss rate = %.3f, want %.2f", got, rate)
+		}
+	}
+}
+
+func TestMissCandidateLandsOutsideTheBox(t *testing.T) {
+	// A 100x40 box at (200, 300), clockwise from top-left
+	quad := []float64{200, 300, 300, 300, 300, 340, 200, 340}
+
+	for side := 0; side < 4; side++ {
+		for _, offset := range []float64{15, 30} {
+			x, y := missCandidate(quad, side, offset)
+
+			dx := max(200-x, x-300, 0)
+			dy := max(300-y, y-340, 0)
+			if dx+dy != offset {
+				t.Errorf("side %d offset %.0f: point (%.0f, %.0f) is %.0fpx outside the box, want %.0f", side, offset, x, y, dx+dy, offset)
+			}
+		}
+	}
+}
+
+func TestHumanClickCorrectsAfterMiss(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(`<body style="margin:0;width:800px;height:600px">
+		<button id="target" style="position:absolute;left:300px;top:250px;width:120px;height:40px"
+			onclick="window.targetClicks = (window.targetClicks || 0) + 1">Connect</button>
+	</body>`); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+	page.MustEval(`() => {
+		window.clicks = [];
+		document.addEventListener('click', e => window.clicks.push(e.target.id || e.target.tagName));
+	}`)
+
+	s := newTestStealth()
+	s.sc.AccidentalMissRate = 1
+	button := page.MustElement("#target")
+
+	if err := s.HumanClick(context.Background(), button); err != nil {
+		t.Fatalf("HumanClick: %v", err)
+	}
+
+	if got := page.MustEval(`() => window.targetClicks || 0`).Int(); got != 1 {
+		t.Errorf("target clicked %d times, want 1", got)
+	}
+	clicks := page.MustEval(`() => window.clicks`).Arr()
+	if len(clicks) != 2 || clicks[0].Str() == "target" || clicks[1].Str() != "target" {
+		t.Errorf("clicks = %v, want a miss next to the target and then the target", clicks)
+	}
+}
+
+func TestPoissonDelayDistribution(t *testing.T) {
+	const (
+		samples  = 10000
+		lambdaMs = 2000.0
+		bins     = 10
+	)
+	s := newTestStealth()
+
+	// Bin edges split the exponential distribution into equally likely bins
+	edges := make([]float64, bins-1)
+	for i := range edges {
+		edges[i] = -lambdaMs * math.Log(1-float64(i+1)/bins)
+	}
+
+	var sum float64
+	counts := make([]int, bins)
+	for i := 0; i < samples; i++ {
+		ms := float64(s.PoissonDelay(lambdaMs)) / float64(time.Millisecond)
+		sum += ms
+
+		bin := 0
+		for bin < len(edges) && ms >= edges[bin] {
+			bin++
+		}
+		counts[bin]++
+	}
+
+	if mean := sum / samples; math.Abs(mean-lambdaMs) > lambdaMs*0.05 {
+		t.Errorf("mean delay = %.0fms, want within 5%% of %.0fms", mean, lambdaMs)
+	}
+
+	expected := float64(samples) / bins
+	var chi2 float64
+	for _, c := range counts {
+		chi2 += (float64(c) - expected) * (float64(c) - expected) / expected
+	}
+	// Critical value for 9 degrees of freedom at p = 0.001
+	if chi2 > 27.88 {
+		t.Errorf("chi-squared = %.1f over bins %v, want an exponential distribution", chi2, counts)
+	}
+
+	if got := s.PoissonDelay(0); got != 0 {
+		t.Errorf("PoissonDelay(0) = %v, want 0", got)
+	}
+}
+
+func TestPoissonDelayIsClamped(t *testing.T) {
+	s := newTestStealth()
+	s.sc.UsePoisson = true
+
+	tests := []struct {
+		name      string
+		cfg       config.DelayConfig
+		mostlyMin bool // nearly every delay is clamped up to Min
+		mostlyMax bool // nearly every delay is clamped down to Max
+	}{
+		{"midpoint lambda", config.DelayConfig{Min: 500, Max: 1500}, false, false},
+		{"lambda below min", config.DelayConfig{Min: 500, Max: 1500, PoissonLambdaMs: 10}, true, false},
+		{"lambda above max", config.DelayConfig{Min: 500, Max: 1500, PoissonLambdaMs: 100000}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atMin, atMax := 0, 0
+			for i := 0; i < 1000; i++ {
+				delay := s.delayMs(tt.cfg)
+				if delay < tt.cfg.Min || delay > tt.cfg.Max {
+					t.Fatalf("delay %dms outside [%d, %d]", delay, tt.cfg.Min, tt.cfg.Max)
+				}
+				if delay == tt.cfg.Min {
+					atMin++
+				}
+				if delay == tt.cfg.Max {
+					atMax++
+				}
+			}
+
+			if tt.mostlyMin && atMin < 950 {
+				t.Errorf("%d/1000 delays clamped to min, want nearly all", atMin)
+			}
+			if tt.mostlyMax && atMax < 950 {
+				t.Errorf("%d/1000 delays clamped to max, want nearly all", atMax)
+			}
+		})
+	}
+}
+
+func TestCalculateSteps(t *testing.T) {
+	tests := []struct {
+		name                   string
+		startX, startY, tx, ty float64
+		want                   int
+	}{
+		{"no movement", 100, 100, 100, 100, 10},
+		{"short move", 0, 0, 200, 0, 10},
+		{"scales with distance", 0, 0, 800, 0, 20},
+		{"diagonal", 0, 0, 600, 800, 25},
+		{"just under the cap", 0, 0, 1999, 0, 49},
+		{"long move capped", 0, 0, 5000, 0, 50},
+		{"direction doesn't matter", 800, 0, 0, 0, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calculateSteps(tt.startX, tt.startY, tt.tx, tt.ty); got != tt.want {
+				t.Errorf("calculateSteps = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOvershootPointPassesTheTarget(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		x, y := overshootPoint(0, 0, 300, 400)
+
+		// Along the line of travel, 5-15px beyond the target
+		extra := math.Hypot(x, y) - 500
+		if extra < 5 || extra > 15 || math.Abs(x*4-y*3) > 1e-6 {
+			t.Fatalf("overshootPoint = (%.1f, %.1f), want 5-15px past (300, 400)", x, y)
+		}
+	}
+
+	if x, y := overshootPoint(10, 10, 10, 10); x != 10 || y != 10 {
+		t.Errorf("overshootPoint without movement = (%.1f, %.1f), want the target", x, y)
+	}
+}
+
+func TestPickPreambleURLs(t *testing.T) {
+	s := newTestStealth()
+	s.sc.PreambleURLs = []string{"https://a.example/", "https://b.example/", "https://c.example/", "https://d.example/", "https://e.example/"}
+
+	configured := make(map[string]bool)
+	for _, u := range s.sc.PreambleURLs {
+		configured[u] = true
+	}
+
+	for i := 0; i < 200; i++ {
+		urls := s.pickPreambleURLs()
+		if len(urls) < 2 || len(urls) > 4 {
+			t.Fatalf("picked %d URLs, want 2-4", len(urls))
+		}
+		seen := make(map[string]bool)
+		for _, u := range urls {
+			if !configured[u] {
+				t.Fatalf("picked %q, not in the configured list", u)
+			}
+			if seen[u] {
+				t.Fatalf("picked %q twice in %v", u, urls)
+			}
+			seen[u] = true
+		}
+	}
+
+	s.sc.PreambleURLs = []string{"https://only.example/"}
+	if urls := s.pickPreambleURLs(); len(urls) != 1 || urls[0] != "https://only.example/" {
+		t.Errorf("single configured URL picked %v", urls)
+	}
+
+	s.sc.PreambleURLs = nil
+	defaults := make(map[string]bool)
+	for _, u := range defaultPreambleURLs {
+		defaults[u] = true
+	}
+	for _, u := range s.pickPreambleURLs() {
+		if !defaults[u] {
+			t.Errorf("picked %q with no configured URLs, want a default", u)
+		}
+	}
+}
+
+// preambleServer serves numbered pages and records the order they are
+// visited in
+func preambleServer(t *testing.T) (urls []string, visited func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/favicon.ico" {
+			mu.Lock()
+			paths = append(paths, r.URL.Path)
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body style="height:3000px">preamble</body></html>`))
+	}))
+	t.Cleanup(server.Close)
+
+	for _, path := range []string{"/1", "/2", "/3", "/4"} {
+		urls = append(urls, server.URL+path)
+	}
+	return urls, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func TestSimulateBrowsingHistoryNavigationSequence(t *testing.T) {
+	if testing.Short() {
+		t.Skip("preamble reads each page for several seconds")
+	}
+	page := newFixturePage(t)
+	urls, visited := preambleServer(t)
+
+	s := newTestStealth()
+	s.sc.EnableBrowsingPreamble = true
+	s.sc.PreambleURLs = urls
+	s.sc.ThinkTime = config.DelayConfig{Min: 1, Max: 1}
+
+	if err := s.SimulateBrowsingHistory(context.Background(), page); err != nil {
+		t.Fatalf("SimulateBrowsingHistory: %v", err)
+	}
+
+	paths := visited()
+	if len(paths) < 2 || len(paths) > 4 {
+		t.Fatalf("visited %v, want 2-4 preamble pages", paths)
+	}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			t.Errorf("visited %s twice in %v", path, paths)
+		}
+		seen[path] = true
+	}
+
+	// Each navigation adds one history entry on top of about:blank, and the
+	// tab ends on the last page visited
+	if n := page.MustEval(`() => window.history.length`).Int(); n != len(paths)+1 {
+		t.Errorf("history length = %d, want %d", n, len(paths)+1)
+	}
+	if got := page.MustInfo().URL; !strings.HasSuffix(got, paths[len(paths)-1]) {
+		t.Errorf("ended on %s, want the last visited page %s", got, paths[len(paths)-1])
+	}
+
+	// The tab now has history, so a second preamble is skipped
+	if err := s.SimulateBrowsingHistory(context.Background(), page); err != nil {
+		t.Fatalf("second SimulateBrowsingHistory: %v", err)
+	}
+	if again := visited(); len(again) != len(paths) {
+		t.Errorf("second preamble visited %v, want it skipped", again[len(paths):])
+	}
+}
+
+func TestSimulateBrowsingHistorySkipped(t *testing.T) {
+	page := newFixturePage(t)
+	urls, visited := preambleServer(t)
+
+	s := newTestStealth()
+	s.sc.PreambleURLs = urls
+	if err := s.SimulateBrowsingHistory(context.Background(), page); err != nil {
+		t.Fatalf("SimulateBrowsingHistory: %v", err)
+	}
+	if paths := visited(); len(paths) != 0 {
+		t.Errorf("disabled preamble visited %v", paths)
+	}
+
+	s.sc.EnableBrowsingPreamble = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.SimulateBrowsingHistory(ctx, page); !errors.Is(err, context.Canceled) {
+		t.Errorf("SimulateBrowsingHistory after shutdown = %v, want context.Canceled", err)
+	}
+	if paths := visited(); len(paths) != 0 {
+		t.Errorf("preamble after shutdown visited %v", paths)
+	}
+}
+
+func TestSimulateFocusLossVisibilitySequence(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(`<html><body>feed</body></html>`); err != nil {
+		t.Fatalf("SetDocumentContent: %v", err)
+	}
+
+	// Record the visibility state seen by each focus and visibility event
+	page.MustEval(`() => {
+		window.__events = [];
+		const record = (e) => window.__events.push(e.type + ':' + document.visibilityState + ':' + document.hidden);
+		window.addEventListener('blur', record);
+		window.addEventListener('focus', record);
+		document.addEventListener('visibilitychange', record);
+	}`)
+
+	s := newTestStealth(config.TechniqueFocusBlur)
+	done := make(chan error, 1)
+	go func() { done <- s.SimulateFocusLoss(page) }()
+
+	// While away the page reports hidden and unfocused
+	deadline := time.Now().Add(time.Second)
+	for page.MustEval(`() => window.__events.length`).Int() < 2 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	away := page.MustEval(`() => [document.visibilityState, document.hidden, document.hasFocus()]`).Arr()
+	if away[0].Str() != "hidden" || !away[1].Bool() || away[2].Bool() {
+		t.Errorf("state while away = %v, want hidden, hidden and unfocused", away)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("SimulateFocusLoss: %v", err)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("SimulateFocusLoss did not return within the 15s maximum away time")
+	}
+
+	want := []string{
+		"blur:hidden:true",
+		"visibilitychange:hidden:true",
+		"focus:visible:false",
+		"visibilitychange:visible:false",
+	}
+	var got []string
+	for _, event := range page.MustEval(`() => window.__events`).Arr() {
+		got = append(got, event.Str())
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	back := page.MustEval(`() => [document.visibilityState, document.hidden, ['visibilityState', 'hidden', 'hasFocus'].filter((name) => document.hasOwnProperty(name))]`).Arr()
+	if back[0].Str() != "visible" || back[1].Bool() || len(back[2].Arr()) != 0 {
+		t.Errorf("state after returning = %v, want visible with the overrides removed", back)
+	}
+}
+
+func TestNewAppliesModuleOverrides(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Stealth.TypingDelay = config.DelayConfig{Min: 50, Max: 150}
+	cfg.Stealth.ThinkTime = config.DelayConfig{Min: 2000, Max: 5000}
+	cfg.PerModuleOverrides = map[string]config.StealthConfig{
+		"message": {TypingDelay: config.DelayConfig{Min: 80, Max: 250}},
+	}
+
+	if sc := New(cfg, "message").sc; sc.TypingDelay.Max != 250 || sc.ThinkTime != cfg.Stealth.ThinkTime {
+		t.Errorf("message stealth config = %+v, want the typing override over the base", sc)
+	}
+	if sc := New(cfg, "search").sc; sc.TypingDelay != cfg.Stealth.TypingDelay {
+		t.Errorf("search typing delay = %+v, want the base %+v", sc.TypingDelay, cfg.Stealth.TypingDelay)
+	}
+}
+
+const windowsUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+func TestPlatformForUserAgent(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{windowsUserAgent, "Win32"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "MacIntel"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "Linux x86_64"},
+		{"", "Win32"},
+	}
+
+	for _, tt := range tests {
+		if got := PlatformForUserAgent(tt.userAgent).Platform; got != tt.want {
+			t.Errorf("PlatformForUserAgent(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestSpoofPlatform(t *testing.T) {
+	if err := newTestStealth().SpoofPlatform(windowsUserAgent); err == nil {
+		t.Error("SpoofPlatform without a page succeeded, want an error")
+	}
+
+	page := newFixturePage(t)
+	if err := (proto.NetworkSetUserAgentOverride{UserAgent: windowsUserAgent}).Call(page); err != nil {
+		t.Fatalf("set user agent: %v", err)
+	}
+
+	s := newTestStealth()
+	s.AttachPage(page)
+	if err := s.SpoofPlatform(windowsUserAgent); err != nil {
+		t.Fatalf("SpoofPlatform: %v", err)
+	}
+
+	check := func(when string) {
+		t.Helper()
+
+		got := page.MustEval(`() => [navigator.platform, navigator.oscpu, navigator.appVersion, navigator.vendor,
+			navigator.userAgentData ? navigator.userAgentData.platform : 'Windows']`).Arr()
+		want := []string{"Win32", "Windows NT 10.0; Win64; x64", strings.TrimPrefix(windowsUserAgent, "Mozilla/"), "Google Inc.", "Windows"}
+		for i, value := range got {
+			if value.Str() != want[i] {
+				t.Errorf("%s: navigator value %d = %q, want %q", when, i, value.Str(), want[i])
+			}
+		}
+	}
+
+	check("current document")
+
+	// The overrides are registered for documents loaded later too
+	if err := page.Navigate("data:text/html,<p>next page</p>"); err != nil {
+		t.Fatalf("navigate: %v", err)
+	}
+	page.MustWaitLoad()
+	check("new document")
+}
+
+func TestWaitForElementRacesFallbackSelectors(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(`<input name="session_key" id="legacy-email">`); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	s := newTestStealth()
+	element, err := s.WaitForElement(page, []string{"#username", "input[name='session_key']"}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForElement: %v", err)
+	}
+	if id := element.MustAttribute("id"); id == nil || *id != "legacy-email" {
+		t.Errorf("WaitForElement matched %v, want the fallback input", id)
+	}
+}
+
+func TestWaitForElementRetriesAfterHydration(t *testing.T) {
+	page := newFixturePage(t)
+	// The button renders a while after the first wait gives up, like a
+	// component mounted after JS hydration
+	if err := page.SetDocumentContent(`<div id="root"></div><script>
+setTimeout(() => { document.getElementById('root').innerHTML = '<button class="hydrated">Send</button>' }, 400)
+</script>`); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	s := newTestStealth()
+	s.sc.StabilizationWaitMs = 800
+
+	element, err := s.WaitForElement(page, []string{"button.hydrated"}, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForElement after stabilization: %v", err)
+	}
+	if text := element.MustText(); text != "Send" {
+		t.Errorf("element text = %q, want Send", text)
+	}
+}
+
+func TestWaitForElementNotFound(t *testing.T) {
+	page := newFixturePage(t)
+	if err := page.SetDocumentContent(`<p>No buttons here</p>`); err != nil {
+		t.Fatalf("set content: %v", err)
+	}
+
+	s := newTestStealth()
+	s.sc.StabilizationWaitMs = 100
+
+	_, err := s.WaitForElement(page, []string{"button.primary", "button.fallback"}, 100*time.Millisecond)
+	if !errors.Is(err, ErrSelectorNotFound) {
+		t.Fatalf("WaitForElement = %v, want ErrSelectorNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "button.fallback") {
+		t.Errorf("error %q doesn't list the selectors tried", err)
+	}
+}
+
+func TestRaceSelectorsWithoutSelectors(t *testing.T) {
+	// Fails before touching the page
+	if _, err := raceSelectors(nil, nil, time.Second); err == nil {
+		t.Error("raceSelectors without selectors succeeded, want an error")
+	}
+}
+
+func TestRandomDelayInSafeMode(t *testing.T) {
+	s := newTestStealth()
+	s.cfg.SafeMode.MinActionDelayMs = 150
+	s.sc.ActionDelay = config.DelayConfig{Min: 0, Max: 10}
+
+	safe := false
+	s.SetSafeMode(func() bool { return safe })
+
+	timeDelay := func(delayType string) time.Duration {
+		start := time.Now()
+		s.RandomDelay(delayType)
+		return time.Since(start)
+	}
+
+	if elapsed := timeDelay("action"); elapsed >= 150*time.Millisecond {
+		t.Errorf("action delay outside safe mode took %s, want under 150ms", elapsed)
+	}
+
+	safe = true
+	if elapsed := timeDelay("action"); elapsed < 150*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("action delay in safe mode took %s, want at least the 150ms safe mode minimum", elapsed)
+	}
+	// Only action delays are raised
+	if elapsed := timeDelay("scroll"); elapsed >= 150*time.Millisecond {
+		t.Errorf("scroll delay in safe mode took %s, want it unchanged", elapsed)
+	}
+}
+
+func TestCalculateReadingDelay(t *testing.T) {
+	s := newTestStealth()
+
+	short := "Senior Software Engineer"
+	long := "Senior Software Engineer at Acme building distributed systems for payments and fraud detection teams worldwide"
+
+	if got, want := s.CalculateReadingDelay(short), 3*time.Minute/DefaultReadingWPM; got != want {
+		t.Errorf("reading delay for 3 words = %s, want %s at the default %d WPM", got, want, DefaultReadingWPM)
+	}
+	if got := s.CalculateReadingDelay(""); got != 0 {
+		t.Errorf("reading delay for no text = %s, want 0", got)
+	}
+
+	// Delays grow in proportion to the word count
+	shortDelay, longDelay := s.CalculateReadingDelay(short), s.CalculateReadingDelay(long)
+	if ratio := float64(longDelay) / float64(shortDelay); math.Abs(ratio-5) > 0.001 {
+		t.Errorf("15 words took %.2fx as long as 3, want 5x", ratio)
+	}
+
+	s.sc.ReadingWPM = 100
+	if got, want := s.CalculateReadingDelay(short), 3*time.Minute/100; got != want {
+		t.Errorf("reading delay at 100 WPM = %s, want %s", got, want)
+	}
+}
+
+func TestProfilePreviewDelayIncludesReadingTime(t *testing.T) {
+	s := newTestStealth()
+	// 6000 WPM reads a word every 10ms
+	s.sc.ReadingWPM = 6000
+	s.sc.ThinkTime = config.DelayConfig{Min: 0, Max: 5}
+
+	timePreview := func(texts ...string) time.Duration {
+		start := time.Now()
+		s.ProfilePreviewDelay(texts...)
+		return time.Since(start)
+	}
+
+	if elapsed := timePreview("Engineer"); elapsed >= 100*time.Millisecond {
+		t.Errorf("preview of 1 word took %s, want under 100ms", elapsed)
+	}
+	if elapsed := timePreview("Staff Engineer", "Building distributed systems for payments at Acme Corp"); elapsed < 100*time.Millisecond {
+		t.Errorf("preview of 10 words took %s, want at least 100ms of reading", elapsed)
+	}
+
+	// A configured preview range replaces the think time range
+	s.sc.ProfilePreviewDelay = config.DelayConfig{Min: 150, Max: 160}
+	if elapsed := timePreview(); elapsed < 150*time.Millisecond {
+		t.Errorf("preview with no text took %s, want at least the configured 150ms", elapsed)
+	}
+}
@@ -0,0 +1,316 @@
+package stealth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"linkedin-automation/internal/config"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Technique is a named stealth technique that can be toggled in
+// stealth.techniques
+type Technique struct {
+	Name        string
+	Description string
+
+	// apply registers the page patch in ApplyBrowserStealth, for the current
+	// document and every new one; nil for behavioral techniques, which are
+	// checked each time the action runs
+	apply func(s *Stealth, page *rod.Page) error
+}
+
+// techniques maps every name in config.StealthTechniques to its implementation
+var techniques = map[string]Technique{
+	config.TechniqueCanvas: {
+		Description: "Add per-session noise to canvas reads (toDataURL, getImageData)",
+		apply:       (*Stealth).spoofCanvas,
+	},
+	config.TechniqueWebGL: {
+		Description: "Report a GPU vendor and renderer matching the user agent's OS to WebGL",
+		apply:       (*Stealth).spoofWebGL,
+	},
+	config.TechniqueHardware: {
+		Description: "Report common hardwareConcurrency and deviceMemory values",
+		apply:       (*Stealth).spoofHardware,
+	},
+	config.TechniqueBattery: {
+		Description: "Report a charging, full battery from navigator.getBattery",
+		apply:       (*Stealth).spoofBattery,
+	},
+	config.TechniqueNetworkInfo: {
+		Description: "Report a typical 4g connection from navigator.connection",
+		apply:       (*Stealth).spoofNetworkInfo,
+	},
+	config.TechniqueFonts: {
+		Description: "Only confirm common system fonts to document.fonts.check",
+		apply:       (*Stealth).spoofFonts,
+	},
+	config.TechniqueWebRTC: {
+		Description: "Force relayed WebRTC candidates so local IPs aren't exposed",
+		apply:       (*Stealth).spoofWebRTC,
+	},
+	config.TechniqueAudio: {
+		Description: "Add per-session noise to AudioBuffer and analyser reads",
+		apply:       (*Stealth).spoofAudio,
+	},
+	config.TechniqueGeolocation: {
+		Description: "Deny geolocation as if the user declined the prompt",
+		apply:       (*Stealth).spoofGeolocation,
+	},
+	config.TechniqueTimezone: {
+		Description: "Emulate the scheduling.timezone time zone",
+		apply:       (*Stealth).spoofTimezone,
+	},
+	config.TechniqueHumanMouse: {
+		Description: "Move the mouse along Bezier curves before clicks (HumanMouseMove)",
+	},
+	config.TechniqueHumanTyping: {
+		Description: "Type with variable delays and occasional typos (HumanType)",
+	},
+	config.TechniqueRandomScroll: {
+		Description: "Scroll pages randomly while reading (RandomScroll)",
+	},
+	config.TechniqueIdleBreaks: {
+		Description: "Take idle breaks every idle_break.frequency_actions actions (MaybeIdleBreak)",
+	},
+	config.TechniqueFocusBlur: {
+		Description: "Switch away from the tab every focus_loss_interval_actions actions (SimulateFocusLoss)",
+	},
+}
+
+// Techniques returns every available technique in config.StealthTechniques order
+func Techniques() []Technique {
+	list := make([]Technique, 0, len(config.StealthTechniques))
+	for _, name := range config.StealthTechniques {
+		technique := techniques[name]
+		technique.Name = name
+		list = append(list, technique)
+	}
+	return list
+}
+
+// applyTechniques runs the page patches of the enabled techniques, or of
+// every technique in safe mode with ForceFullStealth
+func (s *Stealth) applyTechniques(page *rod.Page) error {
+	enabled := s.sc.Techniques
+	if s.cfg.SafeMode.ForceFullStealth && s.inSafeMode() {
+		enabled = config.StealthTechniques
+	}
+
+	for _, name := range enabled {
+		technique, ok := techniques[name]
+		if !ok {
+			return fmt.Errorf("unknown stealth technique %q", name)
+		}
+		if technique.apply == nil {
+			continue
+		}
+
+		if err := technique.apply(s, page); err != nil {
+			return fmt.Errorf("failed to apply %s technique: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// injectScript registers script, a JS function called with args, to run in
+// every new document and runs it in the current one, so patches survive
+// navigation
+func injectScript(page *rod.Page, script string, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to encode script arguments: %w", err)
+	}
+
+	if _, err := page.EvalOnNewDocument(fmt.Sprintf("(%s).apply(null, %s)", script, encoded)); err != nil {
+		return fmt.Errorf("failed to register script: %w", err)
+	}
+
+	_, err = page.Eval(script, args...)
+	return err
+}
+
+// spoofCanvas shifts a few pixel values so canvas fingerprints vary per session
+func (s *Stealth) spoofCanvas(page *rod.Page) error {
+	return injectScript(page, `(shift) => {
+		const getImageData = CanvasRenderingContext2D.prototype.getImageData;
+		CanvasRenderingContext2D.prototype.getImageData = function (...args) {
+			const image = getImageData.apply(this, args);
+			for (let i = 0; i < image.data.length; i += 97) {
+				image.data[i] = Math.max(0, Math.min(255, image.data[i] + shift));
+			}
+			return image;
+		};
+		const toDataURL = HTMLCanvasElement.prototype.toDataURL;
+		HTMLCanvasElement.prototype.toDataURL = function (...args) {
+			const ctx = this.getContext('2d');
+			if (ctx && this.width && this.height) {
+				ctx.putImageData(ctx.getImageData(0, 0, this.width, this.height), 0, 0);
+			}
+			return toDataURL.apply(this, args);
+		};
+	}`, rand.Intn(10)-5)
+}
+
+// WebGLInfo is the UNMASKED_VENDOR/RENDERER pair reported by spoofWebGL
+type WebGLInfo struct {
+	Vendor   string
+	Renderer string
+}
+
+// WebGLForUserAgent returns a common GPU for the OS in a user agent string,
+// so the renderer doesn't contradict the platform; Windows and Linux Chrome
+// report GPUs through ANGLE
+func WebGLForUserAgent(userAgent string) WebGLInfo {
+	switch PlatformForUserAgent(userAgent).UADataPlatform {
+	case "macOS":
+		return WebGLInfo{Vendor: "Intel Inc.", Renderer: "Intel Iris OpenGL Engine"}
+	case "Linux":
+		return WebGLInfo{Vendor: "Google Inc. (Intel)", Renderer: "ANGLE (Intel, Mesa Intel(R) UHD Graphics 620 (KBL GT2), OpenGL 4.6)"}
+	default:
+		return WebGLInfo{Vendor: "Google Inc. (Intel)", Renderer: "ANGLE (Intel, Intel(R) UHD Graphics 620 Direct3D11 vs_5_0 ps_5_0, D3D11)"}
+	}
+}
+
+// spoofWebGL hides the real GPU behind the UNMASKED_VENDOR/RENDERER parameters
+func (s *Stealth) spoofWebGL(page *rod.Page) error {
+	gpu := WebGLForUserAgent(s.userAgent)
+	return injectScript(page, `(vendor, renderer) => {
+		const patch = (proto) => {
+			if (!proto) return;
+			const getParameter = proto.getParameter;
+			proto.getParameter = function (parameter) {
+				if (parameter === 37445) return vendor;
+				if (parameter === 37446) return renderer;
+				return getParameter.call(this, parameter);
+			};
+		};
+		patch(window.WebGLRenderingContext && WebGLRenderingContext.prototype);
+		patch(window.WebGL2RenderingContext && WebGL2RenderingContext.prototype);
+	}`, gpu.Vendor, gpu.Renderer)
+}
+
+// spoofHardware reports a mid-range CPU core count and memory size
+func (s *Stealth) spoofHardware(page *rod.Page) error {
+	return injectScript(page, `() => {
+		Object.defineProperty(Navigator.prototype, 'hardwareConcurrency', { get: () => 8, configurable: true });
+		Object.defineProperty(Navigator.prototype, 'deviceMemory', { get: () => 8, configurable: true });
+	}`)
+}
+
+// spoofBattery returns a desktop-like battery from navigator.getBattery
+func (s *Stealth) spoofBattery(page *rod.Page) error {
+	return injectScript(page, `() => {
+		const battery = {
+			charging: true,
+			chargingTime: 0,
+			dischargingTime: Infinity,
+			level: 1,
+			addEventListener: () => {},
+			removeEventListener: () => {},
+		};
+		navigator.getBattery = () => Promise.resolve(battery);
+	}`)
+}
+
+// spoofNetworkInfo replaces navigator.connection with a typical broadband link
+func (s *Stealth) spoofNetworkInfo(page *rod.Page) error {
+	return injectScript(page, `() => {
+		const connection = {
+			effectiveType: '4g',
+			type: 'wifi',
+			rtt: 50,
+			downlink: 10,
+			saveData: false,
+			addEventListener: () => {},
+			removeEventListener: () => {},
+		};
+		Object.defineProperty(Navigator.prototype, 'connection', { get: () => connection, configurable: true });
+	}`)
+}
+
+// spoofFonts limits font probing to a common set of system fonts
+func (s *Stealth) spoofFonts(page *rod.Page) error {
+	return injectScript(page, `() => {
+		if (!document.fonts) return;
+		const common = ['arial', 'helvetica', 'times new roman', 'courier new', 'verdana',
+			'georgia', 'tahoma', 'trebuchet ms', 'segoe ui', 'sans-serif', 'serif', 'monospace'];
+		const check = document.fonts.check.bind(document.fonts);
+		document.fonts.check = (font, text) => {
+			const family = font.split(/\s+/).slice(1).join(' ').replace(/["']/g, '').toLowerCase();
+			return common.some((name) => family.includes(name)) && check(font, text);
+		};
+	}`)
+}
+
+// spoofWebRTC keeps WebRTC from gathering host candidates with local IPs
+func (s *Stealth) spoofWebRTC(page *rod.Page) error {
+	return injectScript(page, `() => {
+		const Original = window.RTCPeerConnection;
+		if (!Original) return;
+		window.RTCPeerConnection = function (config, ...rest) {
+			return new Original(Object.assign({}, config, { iceTransportPolicy: 'relay' }), ...rest);
+		};
+		window.RTCPeerConnection.prototype = Original.prototype;
+	}`)
+}
+
+// spoofAudio adds inaudible noise so audio fingerprints vary per session
+func (s *Stealth) spoofAudio(page *rod.Page) error {
+	return injectScript(page, `(noise) => {
+		if (window.AudioBuffer) {
+			const getChannelData = AudioBuffer.prototype.getChannelData;
+			AudioBuffer.prototype.getChannelData = function (...args) {
+				const data = getChannelData.apply(this, args);
+				for (let i = 0; i < data.length; i += 100) {
+					data[i] += noise;
+				}
+				return data;
+			};
+		}
+		if (window.AnalyserNode) {
+			const getFloatFrequencyData = AnalyserNode.prototype.getFloatFrequencyData;
+			AnalyserNode.prototype.getFloatFrequencyData = function (array) {
+				getFloatFrequencyData.call(this, array);
+				for (let i = 0; i < array.length; i += 100) {
+					array[i] += noise;
+				}
+			};
+		}
+	}`, rand.Float64()*1e-7)
+}
+
+// spoofGeolocation fails every geolocation request with PERMISSION_DENIED
+func (s *Stealth) spoofGeolocation(page *rod.Page) error {
+	return injectScript(page, `() => {
+		if (!navigator.geolocation) return;
+		const denied = (error) => error && error({ code: 1, message: 'User denied Geolocation', PERMISSION_DENIED: 1 });
+		navigator.geolocation.getCurrentPosition = (success, error) => denied(error);
+		navigator.geolocation.watchPosition = (success, error) => { denied(error); return 0; };
+	}`)
+}
+
+// spoofTimezone emulates scheduling.timezone so Date and Intl match the
+// configured active hours
+func (s *Stealth) spoofTimezone(page *rod.Page) error {
+	timezone := s.cfg.Scheduling.Timezone
+	if timezone == "" {
+		return nil
+	}
+
+	// The override outlives reloads, so re-applying it fails harmlessly
+	err := proto.EmulationSetTimezoneOverride{TimezoneID: timezone}.Call(page)
+	if err != nil && strings.Contains(err.Error(), "already in effect") {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package stealth
+
+import (
+	"strings"
+	"testing"
+
+	"linkedin-automation/internal/config"
+)
+
+func TestEveryTechniqueIsImplemented(t *testing.T) {
+	behavioral := map[string]bool{}
+	for _, name := range config.DefaultStealthTechniques {
+		behavioral[name] = true
+	}
+
+	for _, name := range config.StealthTechniques {
+		technique, ok := techniques[name]
+		if !ok {
+			t.Errorf("technique %q has no implementation", name)
+			continue
+		}
+		if technique.Description == "" {
+			t.Errorf("technique %q has no description", name)
+		}
+		if !behavioral[name] && technique.apply == nil {
+			t.Errorf("page technique %q has no apply method", name)
+		}
+	}
+
+	if len(techniques) != len(config.StealthTechniques) {
+		t.Errorf("%d implementations for %d technique names", len(techniques), len(config.StealthTechniques))
+	}
+	if got := len(Techniques()); got != len(config.StealthTechniques) {
+		t.Errorf("Techniques() returned %d, want %d", got, len(config.StealthTechniques))
+	}
+}
+
+func TestWebGLForUserAgentMatchesOS(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36", "Direct3D11"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "Intel Iris OpenGL Engine"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "Mesa"},
+	}
+
+	for _, tt := range tests {
+		if got := WebGLForUserAgent(tt.userAgent).Renderer; !strings.Contains(got, tt.want) {
+			t.Errorf("renderer for %q = %q, want it to contain %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package stealth
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Character classes used to find transitions while typing
+const (
+	classLower = iota
+	classUpper
+	classDigit
+	classSpace
+	classPunct
+)
+
+// typoRate is how often HumanType hits a wrong key before a character and
+// corrects it with Backspace
+const typoRate = 0.05
+
+// shiftedSymbols are the US-layout symbols that need the Shift key
+const shiftedSymbols = `~!@#$%^&*()_+{}|:"<>?`
+
+// charClass returns the character class of r
+func charClass(r rune) int {
+	switch {
+	case unicode.IsSpace(r):
+		return classSpace
+	case unicode.IsDigit(r):
+		return classDigit
+	case unicode.IsUpper(r):
+		return classUpper
+	case unicode.IsLetter(r):
+		return classLower
+	default:
+		return classPunct
+	}
+}
+
+// needsShift reports whether typing r requires holding Shift
+func needsShift(r rune) bool {
+	return unicode.IsUpper(r) || strings.ContainsRune(shiftedSymbols, r)
+}
+
+// randomMs returns a random duration between min and max milliseconds
+func randomMs(min, max int) time.Duration {
+	return time.Duration(min+rand.Intn(max-min+1)) * time.Millisecond
+}
+
+// contentPauses returns the extra pause before each rune of text: 50-150ms
+// when the character class changes (letter to digit, lower to upper case,
+// word to punctuation), 30-80ms before a shifted character and 40-100ms at
+// word boundaries. Starting a word with a capital letter is not a transition.
+func contentPauses(text []rune) []time.Duration {
+	pauses := make([]time.Duration, len(text))
+
+	for i, r := range text {
+		class := charClass(r)
+
+		if class == classSpace {
+			pauses[i] += randomMs(40, 100)
+		} else if i > 0 {
+			prev := charClass(text[i-1])
+			if prev != classSpace && prev != class && !(prev == classUpper && class == classLower) {
+				pauses[i] += randomMs(50, 150)
+			}
+		}
+
+		if needsShift(r) {
+			pauses[i] += randomMs(30, 80)
+		}
+	}
+
+	return pauses
+}
+
+// keystrokeScale shrinks the base keystroke delay so that, with the extra
+// content pauses added, typing text still averages the configured speed
+func keystrokeScale(pauses []time.Duration, meanDelay time.Duration) float64 {
+	if len(pauses) == 0 || meanDelay <= 0 {
+		return 1
+	}
+
+	var extra time.Duration
+	for _, pause := range pauses {
+		extra += pause
+	}
+
+	budget := time.Duration(len(pauses)) * meanDelay
+	scale := float64(budget-extra) / float64(budget)
+
+	// Never type faster than a third of the configured delay, even for text
+	// that is mostly transitions
+	if scale < 1.0/3 {
+		scale = 1.0 / 3
+	}
+	return scale
+}
+
+// keystroke is one key press planned by planKeystrokes
+type keystroke struct {
+	char      rune
+	backspace bool
+
+	// wait is the pause before pressing the key
+	wait time.Duration
+}
+
+// planKeystrokes returns the key presses HumanType makes to type text,
+// including typos made at typoRate and their corrections. With
+// ContentTypeAwareTyping it pauses at character class changes, Shift and
+// word boundaries, typing the rest faster so the overall speed stays the
+// same.
+func (s *Stealth) planKeystrokes(text string, typoRate float64) []keystroke {
+	runes := []rune(text)
+
+	var pauses []time.Duration
+	scale := 1.0
+	if s.sc.ContentTypeAwareTyping {
+		pauses = contentPauses(runes)
+		meanDelay := time.Duration(s.sc.TypingDelay.Min+s.sc.TypingDelay.Max) * time.Millisecond / 2
+		scale = keystrokeScale(pauses, meanDelay)
+	}
+
+	keyDelay := func() time.Duration {
+		return time.Duration(s.sc.TypingDelay.Min+rand.Intn(s.sc.TypingDelay.Max-s.sc.TypingDelay.Min)) * time.Millisecond
+	}
+
+	keys := make([]keystroke, 0, len(runes))
+	var wait time.Duration
+	for i, char := range runes {
+		if pauses != nil {
+			wait += pauses[i]
+		}
+
+		// Hit a wrong key, notice and delete it. Never on the last
+		// character, where the typo would be left for the send.
+		if rand.Float64() < typoRate && i < len(runes)-1 {
+			keys = append(keys,
+				keystroke{char: rune('a' + rand.Intn(26)), wait: wait},
+				keystroke{backspace: true, wait: keyDelay()},
+			)
+			wait = time.Duration(100+rand.Intn(100)) * time.Millisecond
+		}
+
+		keys = append(keys, keystroke{char: char, wait: wait})
+		wait = time.Duration(float64(keyDelay()) * scale)
+	}
+
+	return keys
+}
@@ -0,0 +1,142 @@
+package stealth
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"linkedin-automation/internal/config"
+)
+
+// newTypingStealth returns a Stealth typing 100-200ms per key
+func newTypingStealth(contentAware bool) *Stealth {
+	s := newTestStealth(config.TechniqueHumanTyping)
+	s.sc.TypingDelay = config.DelayConfig{Min: 100, Max: 200}
+	s.sc.ContentTypeAwareTyping = contentAware
+	return s
+}
+
+// meanContentPause averages the extra pause per character of text over runs
+func meanContentPause(text string, runs int) time.Duration {
+	runes := []rune(text)
+
+	var total time.Duration
+	for i := 0; i < runs; i++ {
+		for _, pause := range contentPauses(runes) {
+			total += pause
+		}
+	}
+	return total / time.Duration(runs*len(runes))
+}
+
+func TestContentPausesByContentType(t *testing.T) {
+	const runs = 500
+
+	prose := meanContentPause("thanks for connecting, i enjoyed your post about hiring", runs)
+	numbers := meanContentPause("ticket 4521 moved to v2 on 10am friday", runs)
+	url := meanContentPause("https://www.Example.com/Path?id=42&ref=A1", runs)
+
+	if !(prose < numbers && numbers < url) {
+		t.Errorf("mean pause per character: prose %v, numbers %v, URL %v; want prose < numbers < URL", prose, numbers, url)
+	}
+}
+
+func TestContentPauses(t *testing.T) {
+	tests := []struct {
+		text     string
+		index    int
+		min, max time.Duration
+	}{
+		{"ab", 1, 0, 0},
+		{"a b", 1, 40 * time.Millisecond, 100 * time.Millisecond},
+		{"a b", 2, 0, 0},
+		{"Hello", 0, 30 * time.Millisecond, 80 * time.Millisecond},
+		{"Hello", 1, 0, 0},
+		{"v2", 1, 50 * time.Millisecond, 150 * time.Millisecond},
+		{"aB", 1, 80 * time.Millisecond, 230 * time.Millisecond},
+		{"hi!", 2, 80 * time.Millisecond, 230 * time.Millisecond},
+		{"a.b", 1, 50 * time.Millisecond, 150 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			pause := contentPauses([]rune(tt.text))[tt.index]
+			if pause < tt.min || pause > tt.max {
+				t.Errorf("pause before %q[%d] = %v, want %v-%v", tt.text, tt.index, pause, tt.min, tt.max)
+				break
+			}
+		}
+	}
+}
+
+func TestPlannedTypingKeepsAverageSpeed(t *testing.T) {
+	const runs = 300
+
+	for _, text := range []string{
+		"thanks for connecting, i enjoyed your post about hiring",
+		"ticket 4521 moved to v2 on 10am friday",
+	} {
+		var plain, aware time.Duration
+		for i := 0; i < runs; i++ {
+			plain += totalWait(newTypingStealth(false).planKeystrokes(text, 0))
+			aware += totalWait(newTypingStealth(true).planKeystrokes(text, 0))
+		}
+
+		ratio := float64(aware) / float64(plain)
+		if math.Abs(ratio-1) > 0.05 {
+			t.Errorf("%q: content-aware typing took %.3f times as long as plain typing, want within 5%%", text, ratio)
+		}
+	}
+}
+
+func TestPlanKeystrokesCorrectsTypos(t *testing.T) {
+	s := newTypingStealth(true)
+	text := "Hi Jane, 2 quick Qs?"
+
+	clean := s.planKeystrokes(text, 0)
+	if got := replay(clean); got != text || len(clean) != len([]rune(text)) {
+		t.Errorf("without typos typed %q in %d keys, want %q in %d", got, len(clean), text, len([]rune(text)))
+	}
+
+	// Every character but the last gets a wrong key and a Backspace first
+	typos := s.planKeystrokes(text, 1)
+	if got := replay(typos); got != text {
+		t.Errorf("with typos the field ends up %q, want %q", got, text)
+	}
+	if want := 3*(len([]rune(text))-1) + 1; len(typos) != want {
+		t.Errorf("planned %d keys, want %d", len(typos), want)
+	}
+	for i := 0; i+2 < len(typos); i += 3 {
+		wrong, backspace := typos[i], typos[i+1]
+		if wrong.backspace || wrong.char < 'a' || wrong.char > 'z' || !backspace.backspace {
+			t.Fatalf("keys %d-%d = %+v, %+v, want a wrong letter then Backspace", i, i+1, wrong, backspace)
+		}
+	}
+	if last := typos[len(typos)-1]; last.backspace || last.char != '?' {
+		t.Errorf("last key = %+v, want the final character without a typo", last)
+	}
+}
+
+// replay applies planned keystrokes to an empty field
+func replay(keys []keystroke) string {
+	var field []rune
+	for _, key := range keys {
+		if key.backspace {
+			if len(field) > 0 {
+				field = field[:len(field)-1]
+			}
+			continue
+		}
+		field = append(field, key.char)
+	}
+	return string(field)
+}
+
+// totalWait adds up the pauses of planned keystrokes
+func totalWait(keys []keystroke) time.Duration {
+	var total time.Duration
+	for _, key := range keys {
+		total += key.wait
+	}
+	return total
+}
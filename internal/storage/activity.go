@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ActivityEntry is a row of the activity log
+type ActivityEntry struct {
+	ID           int64     `json:"id"`
+	ActionType   string    `json:"action_type"`
+	TargetURL    string    `json:"target_url"`
+	Outcome      string    `json:"outcome"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GetRecentActivity returns the most recent activity log entries, newest first
+func (s *Storage) GetRecentActivity(limit int) ([]ActivityEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, action_type, target_url, outcome, error_message, created_at
+		FROM activity_log
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ActivityEntry
+	for rows.Next() {
+		var entry ActivityEntry
+		var targetURL, outcome, errorMessage sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.ActionType, &targetURL, &outcome, &errorMessage, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entry.TargetURL = targetURL.String
+		entry.Outcome = outcome.String
+		entry.ErrorMessage = errorMessage.String
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetLastActivityTime returns when an action last had the given outcome, or
+// nil if it never did
+func (s *Storage) GetLastActivityTime(actionType, outcome string) (*time.Time, error) {
+	var createdAt time.Time
+	err := s.db.QueryRow(`
+		SELECT created_at FROM activity_log
+		WHERE action_type = ? AND outcome = ?
+		ORDER BY id DESC LIMIT 1
+	`, actionType, outcome).Scan(&createdAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &createdAt, nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+// HasAutoResponderFired reports whether an auto-responder rule already
+// matched a reply from the profile
+func (s *Storage) HasAutoResponderFired(profileURL string, ruleIndex int) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM auto_responder_log WHERE profile_url = ? AND rule_index = ?
+	`, profileURL, ruleIndex).Scan(&count)
+
+	return count > 0, err
+}
+
+// LogAutoResponderMatch records that a rule matched a reply and its action ran
+func (s *Storage) LogAutoResponderMatch(profileURL string, ruleIndex int, action, keyword string) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO auto_responder_log (profile_url, rule_index, action, keyword)
+		VALUES (?, ?, ?, ?)
+	`, profileURL, ruleIndex, action, keyword)
+
+	return err
+}
+
+// TagProfile attaches a tag to a profile
+func (s *Storage) TagProfile(profileURL, tag string) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO profile_tags (profile_url, tag) VALUES (?, ?)
+	`, profileURL, tag)
+
+	return err
+}
+
+// GetProfileTags returns the tags attached to a profile
+func (s *Storage) GetProfileTags(profileURL string) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT tag FROM profile_tags WHERE profile_url = ? ORDER BY tag
+	`, profileURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
@@ -0,0 +1,46 @@
+package storage
+
+import "strings"
+
+// AddBlacklistedCompany adds a company to the runtime exclusion list
+func (s *Storage) AddBlacklistedCompany(name string) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO company_blacklist (name) VALUES (?)
+	`, strings.TrimSpace(name))
+
+	return err
+}
+
+// RemoveBlacklistedCompany removes a company from the exclusion list and
+// reports whether it was present
+func (s *Storage) RemoveBlacklistedCompany(name string) (bool, error) {
+	result, err := s.db.Exec(`
+		DELETE FROM company_blacklist WHERE name = ?
+	`, strings.TrimSpace(name))
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetBlacklistedCompanies returns all companies on the exclusion list
+func (s *Storage) GetBlacklistedCompanies() ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM company_blacklist ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// BotState is the persisted operating mode of the bot
+type BotState struct {
+	SafeModeActive bool       `json:"safe_mode_active"`
+	SafeModeReason string     `json:"safe_mode_reason,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+// GetBotState returns the persisted bot state, zero when never set
+func (s *Storage) GetBotState() (BotState, error) {
+	var state BotState
+	err := s.db.QueryRow(`
+		SELECT safe_mode_active, safe_mode_reason, updated_at FROM bot_state WHERE id = 1
+	`).Scan(&state.SafeModeActive, &state.SafeModeReason, &state.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return BotState{}, nil
+	}
+
+	return state, err
+}
+
+// SetSafeMode turns safe mode on or off, recording why
+func (s *Storage) SetSafeMode(active bool, reason string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO bot_state (id, safe_mode_active, safe_mode_reason, updated_at)
+		VALUES (1, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			safe_mode_active = excluded.safe_mode_active,
+			safe_mode_reason = excluded.safe_mode_reason,
+			updated_at = excluded.updated_at
+	`, active, reason, time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return err
+	}
+
+	s.safeMode.Store(active)
+	return nil
+}
+
+// SafeModeActive reports whether safe mode is on. It reads the in-memory
+// copy so it can be checked before every delay.
+func (s *Storage) SafeModeActive() bool {
+	return s.safeMode.Load()
+}
+
+// loadBotState reads the persisted safe mode flag into memory
+func (s *Storage) loadBotState() error {
+	state, err := s.GetBotState()
+	if err != nil {
+		return err
+	}
+
+	s.safeMode.Store(state.SafeModeActive)
+	return nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSafeModePersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	s, err := New(path, 100)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if s.SafeModeActive() {
+		t.Error("safe mode active on a new database")
+	}
+	if err := s.SetSafeMode(true, "security checkpoint"); err != nil {
+		t.Fatalf("SetSafeMode: %v", err)
+	}
+	if !s.SafeModeActive() {
+		t.Error("SafeModeActive = false right after enabling it")
+	}
+	s.Close()
+
+	s, err = New(path, 100)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	if !s.SafeModeActive() {
+		t.Error("safe mode not restored after reopening the database")
+	}
+	state, err := s.GetBotState()
+	if err != nil {
+		t.Fatalf("GetBotState: %v", err)
+	}
+	if !state.SafeModeActive || state.SafeModeReason != "security checkpoint" || state.UpdatedAt == nil {
+		t.Errorf("bot state = %+v, want safe mode on with its reason", state)
+	}
+
+	if err := s.SetSafeMode(false, "api"); err != nil {
+		t.Fatalf("SetSafeMode: %v", err)
+	}
+	if s.SafeModeActive() {
+		t.Error("SafeModeActive = true after disabling it")
+	}
+}
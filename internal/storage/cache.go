@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// DefaultProfileCacheSize is used when no profile cache size is configured
+const DefaultProfileCacheSize = 500
+
+// CacheStats reports how well the profile cache is doing
+type CacheStats struct {
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// profileCacheCounters tracks cache activity; lru.Cache keeps no stats itself
+type profileCacheCounters struct {
+	capacity  int
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// newProfileCache creates the LRU cache that sits in front of GetProfileByURL
+func (s *Storage) newProfileCache(size int) error {
+	if size <= 0 {
+		size = DefaultProfileCacheSize
+	}
+
+	cache, err := lru.New[string, *Profile](size)
+	if err != nil {
+		return fmt.Errorf("failed to create profile cache: %w", err)
+	}
+
+	s.ProfileCache = cache
+	s.cacheCounters.capacity = size
+	return nil
+}
+
+// cachedProfile returns a copy of a cached profile so callers can't modify
+// the cached entry
+func (s *Storage) cachedProfile(url string) (*Profile, bool) {
+	profile, ok := s.ProfileCache.Get(url)
+	if !ok {
+		s.cacheCounters.misses.Add(1)
+		return nil, false
+	}
+
+	s.cacheCounters.hits.Add(1)
+	return copyProfile(profile), true
+}
+
+// cacheProfile stores a copy of a profile loaded from the database
+func (s *Storage) cacheProfile(profile *Profile) {
+	if evicted := s.ProfileCache.Add(profile.ProfileURL, copyProfile(profile)); evicted {
+		s.cacheCounters.evictions.Add(1)
+	}
+}
+
+// copyProfile returns a deep copy of a profile, so the cached entry shares
+// no slices or pointers with callers
+func copyProfile(profile *Profile) *Profile {
+	copied := *profile
+	if profile.SummaryKeywords != nil {
+		copied.SummaryKeywords = append([]string(nil), profile.SummaryKeywords...)
+	}
+	if profile.LastActiveEstimate != nil {
+		estimate := *profile.LastActiveEstimate
+		copied.LastActiveEstimate = &estimate
+	}
+	return &copied
+}
+
+// invalidateProfile drops a profile from the cache after it was written
+func (s *Storage) invalidateProfile(url string) {
+	s.ProfileCache.Remove(url)
+}
+
+// purgeProfileCache drops every cached profile after a bulk update
+func (s *Storage) purgeProfileCache() {
+	s.ProfileCache.Purge()
+}
+
+// GetCacheStats returns hit, miss and eviction counts for the profile cache
+func (s *Storage) GetCacheStats() CacheStats {
+	return CacheStats{
+		Size:      s.ProfileCache.Len(),
+		Capacity:  s.cacheCounters.capacity,
+		Hits:      s.cacheCounters.hits.Load(),
+		Misses:    s.cacheCounters.misses.Load(),
+		Evictions: s.cacheCounters.evictions.Load(),
+	}
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// lookUp fetches a profile by username and fails the test if it is missing
+func lookUp(t *testing.T, s *Storage, username string) *Profile {
+	t.Helper()
+
+	profile, err := s.GetProfileByURL("https://www.linkedin.com/in/" + username)
+	if err != nil || profile == nil {
+		t.Fatalf("GetProfileByURL(%s) = %v, %v", username, profile, err)
+	}
+	return profile
+}
+
+func TestProfileCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	s, err := New(filepath.Join(t.TempDir(), "test.db"), 2)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	for _, username := range []string{"a", "b", "c"} {
+		saveTestProfile(t, s, username)
+	}
+
+	steps := []struct {
+		username string
+		want     CacheStats
+	}{
+		{"a", CacheStats{Size: 1, Capacity: 2, Misses: 1}},
+		{"a", CacheStats{Size: 1, Capacity: 2, Hits: 1, Misses: 1}},
+		{"b", CacheStats{Size: 2, Capacity: 2, Hits: 1, Misses: 2}},
+		// a is now more recent than b, so caching c evicts b
+		{"a", CacheStats{Size: 2, Capacity: 2, Hits: 2, Misses: 2}},
+		{"c", CacheStats{Size: 2, Capacity: 2, Hits: 2, Misses: 3, Evictions: 1}},
+		{"a", CacheStats{Size: 2, Capacity: 2, Hits: 3, Misses: 3, Evictions: 1}},
+		{"b", CacheStats{Size: 2, Capacity: 2, Hits: 3, Misses: 4, Evictions: 2}},
+	}
+
+	for i, step := range steps {
+		lookUp(t, s, step.username)
+		if got := s.GetCacheStats(); got != step.want {
+			t.Fatalf("after lookup %d (%s) stats = %+v, want %+v", i+1, step.username, got, step.want)
+		}
+	}
+}
+
+func TestProfileCacheInvalidation(t *testing.T) {
+	s := newTestStorage(t)
+	saveTestProfile(t, s, "jane")
+
+	cached := lookUp(t, s, "jane")
+	lookUp(t, s, "jane")
+	if stats := s.GetCacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("stats = %+v, want 1 hit and 1 miss", stats)
+	}
+
+	// Changing a returned profile must not change the cached entry
+	cached.Name = "changed by caller"
+	if got := lookUp(t, s, "jane").Name; got != "jane" {
+		t.Errorf("cached name = %q, want it unaffected by callers", got)
+	}
+
+	updated := lookUp(t, s, "jane")
+	updated.Name = "Jane Doe"
+	if err := s.UpdateProfile(updated); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	misses := s.GetCacheStats().Misses
+	if got := lookUp(t, s, "jane").Name; got != "Jane Doe" {
+		t.Errorf("name after UpdateProfile = %q, want Jane Doe", got)
+	}
+	if got := s.GetCacheStats().Misses; got != misses+1 {
+		t.Errorf("misses after UpdateProfile = %d, want %d; the entry was not invalidated", got, misses+1)
+	}
+
+	// SaveProfile keeps an existing row but still drops the cached entry
+	if _, err := s.SaveProfile(&Profile{ProfileURL: updated.ProfileURL, Name: "Jane Saved"}); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	misses = s.GetCacheStats().Misses
+	lookUp(t, s, "jane")
+	if got := s.GetCacheStats().Misses; got != misses+1 {
+		t.Errorf("misses after SaveProfile = %d, want %d; the entry was not invalidated", got, misses+1)
+	}
+}
+
+func TestProfileCacheDefaultSize(t *testing.T) {
+	s, err := New(filepath.Join(t.TempDir(), "test.db"), 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	if got := s.GetCacheStats().Capacity; got != DefaultProfileCacheSize {
+		t.Errorf("capacity = %d, want %d", got, DefaultProfileCacheSize)
+	}
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Workflow phases recorded in checkpoints
+const (
+	PhaseSearch  = "search"
+	PhaseConnect = "connect"
+	PhaseMessage = "message"
+)
+
+// checkpointRetention is how long completed checkpoints are kept
+const checkpointRetention = 7 * 24 * time.Hour
+
+// WorkflowCheckpoint records the last completed phase of a workflow run and
+// the profiles still to be processed by the following phases
+type WorkflowCheckpoint struct {
+	RunID       string
+	Phase       string
+	ProfileIDs  []int64
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// SaveCheckpoint records that a run finished the given phase
+func (s *Storage) SaveCheckpoint(runID, phase string, profileIDs []int64) error {
+	data, err := json.Marshal(profileIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile IDs: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO workflow_checkpoints (run_id, phase, profiles_json, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(run_id) DO UPDATE SET
+			phase = excluded.phase,
+			profiles_json = excluded.profiles_json,
+			updated_at = excluded.updated_at
+	`, runID, phase, string(data), time.Now().UTC().Format("2006-01-02 15:04:05"))
+
+	return err
+}
+
+// GetIncompleteCheckpoint returns the most recent unfinished run updated
+// within maxAge, or nil if there is none. A maxAge of 0 ignores age.
+func (s *Storage) GetIncompleteCheckpoint(maxAge time.Duration) (*WorkflowCheckpoint, error) {
+	cutoff := ""
+	if maxAge > 0 {
+		cutoff = time.Now().UTC().Add(-maxAge).Format("2006-01-02 15:04:05")
+	}
+
+	var cp WorkflowCheckpoint
+	var profilesJSON, updatedAt string
+	err := s.db.QueryRow(`
+		SELECT run_id, phase, profiles_json, updated_at
+		FROM workflow_checkpoints
+		WHERE completed_at IS NULL AND updated_at >= ?
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`, cutoff).Scan(&cp.RunID, &cp.Phase, &profilesJSON, &updatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(profilesJSON), &cp.ProfileIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint profiles: %w", err)
+	}
+	cp.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+
+	return &cp, nil
+}
+
+// CompleteCheckpoint marks a run as finished and prunes old completed runs
+func (s *Storage) CompleteCheckpoint(runID string) error {
+	now := time.Now().UTC()
+
+	if _, err := s.db.Exec(`
+		UPDATE workflow_checkpoints SET completed_at = ? WHERE run_id = ?
+	`, now.Format("2006-01-02 15:04:05"), runID); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`
+		DELETE FROM workflow_checkpoints
+		WHERE completed_at IS NOT NULL AND completed_at < ?
+	`, now.Add(-checkpointRetention).Format("2006-01-02 15:04:05"))
+
+	return err
+}
+
+// GetProfilesByIDs loads the given profiles, skipping IDs that no longer exist
+func (s *Storage) GetProfilesByIDs(ids []int64) ([]*Profile, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := s.db.Query(`SELECT `+profileColumns+` FROM profiles WHERE id IN (`+placeholders+`) ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
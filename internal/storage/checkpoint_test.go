@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// ageCheckpoint moves a checkpoint's updated_at back by age
+func ageCheckpoint(t *testing.T, s *Storage, runID string, age time.Duration) {
+	t.Helper()
+
+	if _, err := s.db.Exec(`UPDATE workflow_checkpoints SET updated_at = ? WHERE run_id = ?`,
+		time.Now().UTC().Add(-age).Format("2006-01-02 15:04:05"), runID); err != nil {
+		t.Fatalf("age checkpoint: %v", err)
+	}
+}
+
+func TestCheckpointCreationAndDetection(t *testing.T) {
+	s := newTestStorage(t)
+
+	if cp, err := s.GetIncompleteCheckpoint(24 * time.Hour); err != nil || cp != nil {
+		t.Fatalf("GetIncompleteCheckpoint on an empty database = %+v, %v, want nil", cp, err)
+	}
+
+	if err := s.SaveCheckpoint("run-1", PhaseSearch, []int64{3, 1, 2}); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+	// Finishing the next phase updates the same run
+	if err := s.SaveCheckpoint("run-1", PhaseConnect, []int64{1, 2}); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+
+	cp, err := s.GetIncompleteCheckpoint(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("GetIncompleteCheckpoint: %v", err)
+	}
+	if cp == nil || cp.RunID != "run-1" || cp.Phase != PhaseConnect || !reflect.DeepEqual(cp.ProfileIDs, []int64{1, 2}) {
+		t.Fatalf("checkpoint = %+v, want run-1 after connect with profiles [1 2]", cp)
+	}
+	if time.Since(cp.UpdatedAt) > time.Minute {
+		t.Errorf("UpdatedAt = %v, want just now", cp.UpdatedAt)
+	}
+
+	// The most recently updated unfinished run wins
+	if err := s.SaveCheckpoint("run-2", PhaseSearch, []int64{5}); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+	ageCheckpoint(t, s, "run-1", time.Hour)
+	if cp, err := s.GetIncompleteCheckpoint(24 * time.Hour); err != nil || cp.RunID != "run-2" {
+		t.Errorf("GetIncompleteCheckpoint = %+v, %v, want the newer run-2", cp, err)
+	}
+}
+
+func TestCheckpointMaxAge(t *testing.T) {
+	s := newTestStorage(t)
+
+	if err := s.SaveCheckpoint("run-1", PhaseSearch, []int64{1}); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+	ageCheckpoint(t, s, "run-1", 25*time.Hour)
+
+	if cp, err := s.GetIncompleteCheckpoint(24 * time.Hour); err != nil || cp != nil {
+		t.Errorf("GetIncompleteCheckpoint(24h) = %+v, %v, want a 25h old run ignored", cp, err)
+	}
+	if cp, err := s.GetIncompleteCheckpoint(0); err != nil || cp == nil || cp.RunID != "run-1" {
+		t.Errorf("GetIncompleteCheckpoint(0) = %+v, %v, want run-1 regardless of age", cp, err)
+	}
+}
+
+func TestCompleteCheckpointCleanup(t *testing.T) {
+	s := newTestStorage(t)
+
+	for _, runID := range []string{"old", "recent", "current"} {
+		if err := s.SaveCheckpoint(runID, PhaseMessage, nil); err != nil {
+			t.Fatalf("SaveCheckpoint: %v", err)
+		}
+	}
+	if _, err := s.db.Exec(`UPDATE workflow_checkpoints SET completed_at = ? WHERE run_id = 'old'`,
+		time.Now().UTC().AddDate(0, 0, -8).Format("2006-01-02 15:04:05")); err != nil {
+		t.Fatalf("complete old run: %v", err)
+	}
+	if _, err := s.db.Exec(`UPDATE workflow_checkpoints SET completed_at = ? WHERE run_id = 'recent'`,
+		time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02 15:04:05")); err != nil {
+		t.Fatalf("complete recent run: %v", err)
+	}
+
+	if err := s.CompleteCheckpoint("current"); err != nil {
+		t.Fatalf("CompleteCheckpoint: %v", err)
+	}
+
+	if cp, err := s.GetIncompleteCheckpoint(0); err != nil || cp != nil {
+		t.Errorf("GetIncompleteCheckpoint after completing every run = %+v, %v, want nil", cp, err)
+	}
+
+	rows, err := s.db.Query(`SELECT run_id FROM workflow_checkpoints ORDER BY run_id`)
+	if err != nil {
+		t.Fatalf("list checkpoints: %v", err)
+	}
+	defer rows.Close()
+	var kept []string
+	for rows.Next() {
+		var runID string
+		if err := rows.Scan(&runID); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		kept = append(kept, runID)
+	}
+	if !reflect.DeepEqual(kept, []string{"current", "recent"}) {
+		t.Errorf("kept checkpoints %v, want runs completed within a week", kept)
+	}
+}
+
+func TestGetProfilesByIDs(t *testing.T) {
+	s := newTestStorage(t)
+
+	jane := saveTestProfile(t, s, "jane")
+	john := saveTestProfile(t, s, "john")
+
+	profiles, err := s.GetProfilesByIDs([]int64{john.ID, 999, jane.ID})
+	if err != nil {
+		t.Fatalf("GetProfilesByIDs: %v", err)
+	}
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"jane", "john"}) {
+		t.Errorf("profiles = %v, want jane and john without the missing ID", names)
+	}
+
+	if profiles, err := s.GetProfilesByIDs(nil); err != nil || profiles != nil {
+		t.Errorf("GetProfilesByIDs(nil) = %v, %v, want nil", profiles, err)
+	}
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"linkedin-automation/internal/normalize"
+)
+
+// CompanyAlias maps a company name as written on profiles to its canonical
+// name. Aliases are recorded automatically when profiles are saved; manual
+// ones were set through the API and override normalization.
+type CompanyAlias struct {
+	RawName       string    `json:"raw_name"`
+	CanonicalName string    `json:"canonical_name"`
+	Manual        bool      `json:"manual"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// canonicalCompany returns the canonical name of a raw company name, from
+// company_aliases when known and otherwise by normalizing it and recording
+// the alias
+func (s *Storage) canonicalCompany(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+
+	var canonical string
+	err := s.db.QueryRow(`SELECT canonical_name FROM company_aliases WHERE raw_name = ?`, raw).Scan(&canonical)
+	if err == nil {
+		return canonical, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	canonical = normalize.NormalizeCompanyName(raw)
+	_, err = s.db.Exec(`
+		INSERT OR IGNORE INTO company_aliases (raw_name, canonical_name, updated_at)
+		VALUES (?, ?, ?)
+	`, raw, canonical, time.Now().UTC().Format("2006-01-02 15:04:05"))
+
+	return canonical, err
+}
+
+// GetCompanyAliases returns all company aliases ordered by canonical name
+func (s *Storage) GetCompanyAliases() ([]CompanyAlias, error) {
+	rows, err := s.db.Query(`
+		SELECT raw_name, canonical_name, manual, COALESCE(updated_at, '')
+		FROM company_aliases
+		ORDER BY canonical_name, raw_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aliases []CompanyAlias
+	for rows.Next() {
+		var alias CompanyAlias
+		var updatedAt string
+		if err := rows.Scan(&alias.RawName, &alias.CanonicalName, &alias.Manual, &updatedAt); err != nil {
+			return nil, err
+		}
+		alias.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// SetCompanyAlias maps a raw company name to a canonical name and updates
+// the profiles at that company. The canonical name is normalized too, so
+// "Alphabet Inc." and "alphabet" are the same target.
+func (s *Storage) SetCompanyAlias(raw, canonical string) (*CompanyAlias, error) {
+	alias := &CompanyAlias{
+		RawName:       strings.TrimSpace(raw),
+		CanonicalName: normalize.NormalizeCompanyName(canonical),
+		Manual:        true,
+		UpdatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO company_aliases (raw_name, canonical_name, manual, updated_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT(raw_name) DO UPDATE SET
+			canonical_name = excluded.canonical_name,
+			manual = 1,
+			updated_at = excluded.updated_at
+	`, alias.RawName, alias.CanonicalName, alias.UpdatedAt.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateProfileCompanies(tx, alias.RawName, alias.CanonicalName); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.purgeProfileCache()
+	return alias, nil
+}
+
+// RemoveCompanyAlias reverts a raw company name to automatic normalization
+// and reports whether an alias was present
+func (s *Storage) RemoveCompanyAlias(raw string) (bool, error) {
+	raw = strings.TrimSpace(raw)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM company_aliases WHERE raw_name = ?`, raw)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+
+	canonical := normalize.NormalizeCompanyName(raw)
+	_, err = tx.Exec(`
+		INSERT INTO company_aliases (raw_name, canonical_name, updated_at)
+		VALUES (?, ?, ?)
+	`, raw, canonical, time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return false, err
+	}
+
+	if err := updateProfileCompanies(tx, raw, canonical); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	s.purgeProfileCache()
+	return true, nil
+}
+
+// updateProfileCompanies sets the normalized company of profiles whose
+// company is raw. Callers purge the profile cache once the transaction
+// commits.
+func updateProfileCompanies(tx *sql.Tx, raw, canonical string) error {
+	_, err := tx.Exec(`
+		UPDATE profiles SET normalized_company = ? WHERE TRIM(company) = ?
+	`, canonical, raw)
+	return err
+}
+
+// backfillNormalizedCompanies fills normalized_company for profiles saved
+// before the column existed
+func (s *Storage) backfillNormalizedCompanies() error {
+	rows, err := s.db.Query(`SELECT DISTINCT TRIM(company) FROM profiles WHERE COALESCE(company, '') != ''`)
+	if err != nil {
+		return err
+	}
+
+	var companies []string
+	for rows.Next() {
+		var company string
+		if err := rows.Scan(&company); err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, company)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	canonical := make(map[string]string, len(companies))
+	for _, company := range companies {
+		name, err := s.canonicalCompany(company)
+		if err != nil {
+			return err
+		}
+		canonical[company] = name
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for raw, name := range canonical {
+		if err := updateProfileCompanies(tx, raw, name); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.purgeProfileCache()
+	return nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetCompanyAliasRefreshesCachedProfiles(t *testing.T) {
+	s := newTestStorage(t)
+
+	profile := &Profile{
+		ProfileURL: "https://www.linkedin.com/in/jane",
+		Name:       "Jane",
+		Company:    "Google LLC",
+	}
+	if _, err := s.SaveProfile(profile); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	cached, err := s.GetProfileByURL(profile.ProfileURL)
+	if err != nil || cached == nil {
+		t.Fatalf("GetProfileByURL: %v", err)
+	}
+	if cached.NormalizedCompany != "google" {
+		t.Fatalf("NormalizedCompany = %q, want google", cached.NormalizedCompany)
+	}
+
+	if _, err := s.SetCompanyAlias("Google LLC", "Alphabet Inc."); err != nil {
+		t.Fatalf("SetCompanyAlias: %v", err)
+	}
+	got, err := s.GetProfileByURL(profile.ProfileURL)
+	if err != nil {
+		t.Fatalf("GetProfileByURL: %v", err)
+	}
+	if got.NormalizedCompany != "alphabet" {
+		t.Errorf("NormalizedCompany after alias = %q, want alphabet", got.NormalizedCompany)
+	}
+
+	removed, err := s.RemoveCompanyAlias("Google LLC")
+	if err != nil || !removed {
+		t.Fatalf("RemoveCompanyAlias = %v, %v", removed, err)
+	}
+	got, err = s.GetProfileByURL(profile.ProfileURL)
+	if err != nil {
+		t.Fatalf("GetProfileByURL: %v", err)
+	}
+	if got.NormalizedCompany != "google" {
+		t.Errorf("NormalizedCompany after removal = %q, want google", got.NormalizedCompany)
+	}
+}
+
+func TestCachedProfilesAreDeepCopies(t *testing.T) {
+	s := newTestStorage(t)
+
+	want := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	estimate := want
+	profile := &Profile{
+		ProfileURL:         "https://www.linkedin.com/in/jane",
+		SummaryKeywords:    []string{"golang"},
+		LastActiveEstimate: &estimate,
+	}
+	s.cacheProfile(profile)
+
+	profile.SummaryKeywords[0] = "changed"
+	*profile.LastActiveEstimate = want.AddDate(0, 1, 0)
+
+	cached, ok := s.cachedProfile(profile.ProfileURL)
+	if !ok {
+		t.Fatal("profile not cached")
+	}
+	if cached.SummaryKeywords[0] != "golang" {
+		t.Errorf("cached SummaryKeywords = %v, want [golang]", cached.SummaryKeywords)
+	}
+	if !cached.LastActiveEstimate.Equal(want) {
+		t.Errorf("cached LastActiveEstimate = %v, want %v", cached.LastActiveEstimate, want)
+	}
+
+	cached.SummaryKeywords[0] = "changed"
+	again, _ := s.cachedProfile(profile.ProfileURL)
+	if again.SummaryKeywords[0] != "golang" {
+		t.Errorf("cache entry changed through a returned copy: %v", again.SummaryKeywords)
+	}
+}
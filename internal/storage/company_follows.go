@@ -0,0 +1,78 @@
+package storage
+
+import "time"
+
+// CompanyFollow records a LinkedIn company page we followed
+type CompanyFollow struct {
+	ID          int64
+	CompanyURL  string
+	CompanyName string
+	FollowedAt  time.Time
+}
+
+// SaveCompanyFollow records that a company page was followed
+func (s *Storage) SaveCompanyFollow(companyURL, companyName string) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO company_follows (company_url, company_name) VALUES (?, ?)
+	`, companyURL, companyName)
+
+	return err
+}
+
+// IsCompanyFollowed reports whether a company page was already followed
+func (s *Storage) IsCompanyFollowed(companyURL string) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM company_follows WHERE company_url = ?
+	`, companyURL).Scan(&count)
+
+	return count > 0, err
+}
+
+// GetFollowedCompanies returns all followed company pages, most recent first
+func (s *Storage) GetFollowedCompanies() ([]CompanyFollow, error) {
+	rows, err := s.db.Query(`
+		SELECT id, company_url, COALESCE(company_name, ''), followed_at
+		FROM company_follows
+		ORDER BY followed_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var follows []CompanyFollow
+	for rows.Next() {
+		var f CompanyFollow
+		if err := rows.Scan(&f.ID, &f.CompanyURL, &f.CompanyName, &f.FollowedAt); err != nil {
+			return nil, err
+		}
+		follows = append(follows, f)
+	}
+
+	return follows, rows.Err()
+}
+
+// GetProfileCompanyURLs returns the distinct LinkedIn company pages linked
+// from stored profiles
+func (s *Storage) GetProfileCompanyURLs() ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT company_url FROM profiles
+		WHERE company_url LIKE '%linkedin.com/company/%'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+
+	return urls, rows.Err()
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// CompanySize buckets a company's employee count. The values match those
+// accepted in search.icp.company_sizes.
+type CompanySize string
+
+const (
+	CompanySizeUnknown    CompanySize = ""
+	CompanySizeSMB        CompanySize = "SMB"
+	CompanySizeMidMarket  CompanySize = "Mid-Market"
+	CompanySizeEnterprise CompanySize = "Enterprise"
+)
+
+// GetCompanySize returns the cached size of a company if it was looked up
+// within maxAge
+func (s *Storage) GetCompanySize(companyURL string, maxAge time.Duration) (CompanySize, bool, error) {
+	var size CompanySize
+	err := s.db.QueryRow(`
+		SELECT company_size FROM company_metadata
+		WHERE company_url = ? AND updated_at >= ?
+	`, companyURL, time.Now().UTC().Add(-maxAge).Format("2006-01-02 15:04:05")).Scan(&size)
+
+	if err == sql.ErrNoRows {
+		return CompanySizeUnknown, false, nil
+	}
+	if err != nil {
+		return CompanySizeUnknown, false, err
+	}
+
+	return size, true, nil
+}
+
+// SaveCompanySize caches a company's size and the employee range it was
+// derived from
+func (s *Storage) SaveCompanySize(companyURL string, size CompanySize, employeeRange string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO company_metadata (company_url, company_size, employee_range, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (company_url) DO UPDATE SET
+			company_size = excluded.company_size,
+			employee_range = excluded.employee_range,
+			updated_at = excluded.updated_at
+	`, companyURL, size, employeeRange, time.Now().UTC().Format("2006-01-02 15:04:05"))
+
+	return err
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompanySizeCacheExpires(t *testing.T) {
+	s := newTestStorage(t)
+
+	companyURL := "https://www.linkedin.com/company/acme/"
+	if err := s.SaveCompanySize(companyURL, CompanySizeSMB, "11-50 employees"); err != nil {
+		t.Fatalf("SaveCompanySize: %v", err)
+	}
+	if size, ok, err := s.GetCompanySize(companyURL, 7*24*time.Hour); err != nil || !ok || size != CompanySizeSMB {
+		t.Fatalf("GetCompanySize = %q, %v, %v, want cached SMB", size, ok, err)
+	}
+
+	stale := time.Now().UTC().AddDate(0, 0, -8).Format("2006-01-02 15:04:05")
+	if _, err := s.db.Exec(`UPDATE company_metadata SET updated_at = ?`, stale); err != nil {
+		t.Fatalf("age cache entry: %v", err)
+	}
+	if _, ok, err := s.GetCompanySize(companyURL, 7*24*time.Hour); err != nil || ok {
+		t.Errorf("GetCompanySize after 8 days = %v, %v, want a cache miss", ok, err)
+	}
+
+	// Refreshing replaces the stale entry
+	if err := s.SaveCompanySize(companyURL, CompanySizeMidMarket, "201-500 employees"); err != nil {
+		t.Fatalf("SaveCompanySize: %v", err)
+	}
+	if size, ok, err := s.GetCompanySize(companyURL, 7*24*time.Hour); err != nil || !ok || size != CompanySizeMidMarket {
+		t.Errorf("GetCompanySize after refresh = %q, %v, %v, want Mid-Market", size, ok, err)
+	}
+}
+
+func TestListProfilesCompanySize(t *testing.T) {
+	s := newTestStorage(t)
+
+	for username, size := range map[string]CompanySize{"jane": CompanySizeSMB, "john": CompanySizeEnterprise, "jim": CompanySizeUnknown} {
+		profile := saveTestProfile(t, s, username)
+		if _, err := s.db.Exec(`UPDATE profiles SET company_size_bucket = ? WHERE id = ?`, size, profile.ID); err != nil {
+			t.Fatalf("set company_size_bucket: %v", err)
+		}
+	}
+
+	profiles, err := s.ListProfiles(ProfileQueryOptions{CompanySize: CompanySizeEnterprise})
+	if err != nil {
+		t.Fatalf("ListProfiles: %v", err)
+	}
+	var got []string
+	for _, profile := range profiles {
+		got = append(got, profile.Name)
+	}
+	if !reflect.DeepEqual(got, []string{"john"}) {
+		t.Errorf("ListProfiles(CompanySize Enterprise) = %v, want [john]", got)
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetAcceptedConnectionsHonoursMessageAfter(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	ready := saveTestProfile(t, s, "ready")
+	waiting := saveTestProfile(t, s, "waiting")
+	undrawn := saveTestProfile(t, s, "undrawn")
+	for _, p := range []*Profile{ready, waiting, undrawn} {
+		saveTestConnection(t, s, p, now.AddDate(0, 0, -5), "pending")
+		if err := s.UpdateConnectionStatus(p.ProfileURL, "accepted"); err != nil {
+			t.Fatalf("UpdateConnectionStatus: %v", err)
+		}
+	}
+
+	readyAt := now.Add(-time.Hour).UTC().Truncate(time.Second)
+	if err := s.SetMessageAfterForProfile(ready.ProfileURL, readyAt); err != nil {
+		t.Fatalf("SetMessageAfterForProfile: %v", err)
+	}
+	if err := s.SetMessageAfterForProfile(waiting.ProfileURL, now.Add(time.Hour)); err != nil {
+		t.Fatalf("SetMessageAfterForProfile: %v", err)
+	}
+
+	// The delay is drawn once; a second draw doesn't move it
+	if err := s.SetMessageAfterForProfile(ready.ProfileURL, now.Add(48*time.Hour)); err != nil {
+		t.Fatalf("SetMessageAfterForProfile: %v", err)
+	}
+
+	connections, err := s.GetAcceptedConnections()
+	if err != nil {
+		t.Fatalf("GetAcceptedConnections: %v", err)
+	}
+
+	got := map[string]ConnectionRequest{}
+	for _, conn := range connections {
+		got[conn.ProfileURL] = conn
+	}
+
+	if _, ok := got[waiting.ProfileURL]; ok {
+		t.Error("connection still inside its message delay was returned")
+	}
+	if conn, ok := got[undrawn.ProfileURL]; !ok || conn.MessageAfterAt != nil {
+		t.Error("connection without a drawn delay should be returned with a nil MessageAfterAt")
+	}
+	conn, ok := got[ready.ProfileURL]
+	if !ok {
+		t.Fatal("connection past its message delay was not returned")
+	}
+	if conn.MessageAfterAt == nil || !conn.MessageAfterAt.Equal(readyAt) {
+		t.Errorf("MessageAfterAt = %v, want %v", conn.MessageAfterAt, readyAt)
+	}
+}
@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// titleOverlapThreshold is the share of job title keywords two profiles with
+// the same name must have in common to be flagged as possible duplicates
+const titleOverlapThreshold = 0.5
+
+// stateProgress orders connection states so merging keeps the furthest one
+var stateProgress = map[ConnectionState]int{
+	StateDiscovered: 0,
+	StateQueued:     1,
+	StateWithdrawn:  1,
+	StateExpired:    1,
+	StateRequested:  2,
+	StateFollowed:   2,
+	StatePending:    3,
+	StateRejected:   3,
+	StateAccepted:   4,
+	StateMessaged:   5,
+	StateReplied:    6,
+}
+
+// titleStopWords are ignored when comparing job titles
+var titleStopWords = map[string]bool{
+	"at": true, "of": true, "and": true, "the": true, "for": true, "in": true, "&": true, "-": true, "|": true,
+}
+
+// dedupeCandidate is the subset of a profile needed to find duplicates
+type dedupeCandidate struct {
+	id           int64
+	url          string
+	name         string
+	jobTitle     string
+	score        float64
+	state        ConnectionState
+	discoveredAt time.Time
+
+	// urls holds the normalized URLs known to belong to the profile: its own,
+	// its canonical and discovered URLs and where it redirects to
+	urls map[string]bool
+}
+
+// DuplicatePair is two profiles that may be the same person
+type DuplicatePair struct {
+	ProfileURL string
+	OtherURL   string
+}
+
+// DedupeResult reports what DeduplicateProfiles did
+type DedupeResult struct {
+	Merged int
+
+	// Flagged lists profiles that share a name and have overlapping job
+	// titles but nothing linking their URLs, so they were left for review
+	Flagged []DuplicatePair
+}
+
+// DeduplicateProfiles merges profiles that share a name and are linked by
+// URL, i.e. one's canonical URL, discovered URL or redirect points at the
+// other, since LinkedIn sometimes serves the same person under more than one
+// URL. The most recently discovered URL is kept, the other URLs are recorded
+// in profile_aliases and their history is moved over. Same-name profiles
+// with overlapping job titles but no URL link may be different people and
+// are only flagged.
+func (s *Storage) DeduplicateProfiles() (*DedupeResult, error) {
+	rows, err := s.db.Query(`
+		SELECT p.id, p.profile_url, p.name, COALESCE(p.job_title, ''), p.score, p.connection_state, p.discovered_at,
+			COALESCE(p.canonical_url, ''), COALESCE(p.discovered_url, ''), COALESCE(r.canonical_url, '')
+		FROM profiles p
+		LEFT JOIN profile_url_redirects r ON r.discovered_url = p.profile_url
+		WHERE TRIM(COALESCE(p.name, '')) != ''
+			AND LOWER(TRIM(p.name)) IN (
+				SELECT LOWER(TRIM(name)) FROM profiles GROUP BY LOWER(TRIM(name)) HAVING COUNT(*) > 1
+			)
+		ORDER BY LOWER(TRIM(p.name)), p.discovered_at DESC, p.id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate names: %w", err)
+	}
+
+	groups := make(map[string][]*dedupeCandidate)
+	var order []string
+	for rows.Next() {
+		var c dedupeCandidate
+		var canonicalURL, discoveredURL, redirectURL string
+		if err := rows.Scan(&c.id, &c.url, &c.name, &c.jobTitle, &c.score, &c.state, &c.discoveredAt,
+			&canonicalURL, &discoveredURL, &redirectURL); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		c.urls = make(map[string]bool)
+		for _, u := range []string{c.url, canonicalURL, discoveredURL, redirectURL} {
+			if key := dedupeURLKey(u); key != "" {
+				c.urls[key] = true
+			}
+		}
+
+		key := strings.ToLower(strings.TrimSpace(c.name))
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], &c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &DedupeResult{}
+	for _, key := range order {
+		// Candidates are newest first, so each is merged into the newest
+		// earlier candidate it is linked to
+		var kept []*dedupeCandidate
+		for _, candidate := range groups[key] {
+			var target, similar *dedupeCandidate
+			for _, k := range kept {
+				if sharesURL(k, candidate) {
+					target = k
+					break
+				}
+				if similar == nil && TitleOverlap(k.jobTitle, candidate.jobTitle) >= titleOverlapThreshold {
+					similar = k
+				}
+			}
+
+			if target == nil {
+				if similar != nil {
+					result.Flagged = append(result.Flagged, DuplicatePair{ProfileURL: similar.url, OtherURL: candidate.url})
+				}
+				kept = append(kept, candidate)
+				continue
+			}
+
+			if err := s.mergeProfiles(target, candidate); err != nil {
+				return result, fmt.Errorf("failed to merge %s into %s: %w", candidate.url, target.url, err)
+			}
+			for u := range candidate.urls {
+				target.urls[u] = true
+			}
+			result.Merged++
+		}
+	}
+
+	return result, nil
+}
+
+// sharesURL reports whether two profiles have a URL in common
+func sharesURL(a, b *dedupeCandidate) bool {
+	for u := range b.urls {
+		if a.urls[u] {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeURLKey normalizes a profile URL for comparison
+func dedupeURLKey(url string) string {
+	url = strings.TrimSpace(strings.Split(url, "?")[0])
+	return strings.ToLower(strings.TrimSuffix(url, "/"))
+}
+
+// mergeProfiles moves the duplicate's history onto the kept profile, records
+// the duplicate URL as an alias and deletes the duplicate
+func (s *Storage) mergeProfiles(keep, dup *dedupeCandidate) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE connection_requests SET profile_id = ?, profile_url = ? WHERE profile_id = ? OR profile_url = ?`,
+			[]any{keep.id, keep.url, dup.id, dup.url}},
+		{`UPDATE messages SET profile_id = ?, profile_url = ? WHERE profile_id = ? OR profile_url = ?`,
+			[]any{keep.id, keep.url, dup.id, dup.url}},
+		{`UPDATE post_engagements SET profile_id = ?, profile_url = ? WHERE profile_id = ? OR profile_url = ?`,
+			[]any{keep.id, keep.url, dup.id, dup.url}},
+		{`UPDATE OR IGNORE skill_endorsements SET profile_id = ?, profile_url = ? WHERE profile_url = ?`,
+			[]any{keep.id, keep.url, dup.url}},
+		{`DELETE FROM skill_endorsements WHERE profile_url = ?`, []any{dup.url}},
+		{`UPDATE message_engagements SET profile_url = ? WHERE profile_url = ?`, []any{keep.url, dup.url}},
+		{`UPDATE profile_visits SET profile_url = ? WHERE profile_url = ?`, []any{keep.url, dup.url}},
+		{`UPDATE OR IGNORE retry_queue SET profile_id = ? WHERE profile_id = ?`, []any{keep.id, dup.id}},
+		{`DELETE FROM retry_queue WHERE profile_id = ?`, []any{dup.id}},
+		{`UPDATE OR IGNORE profile_variables SET profile_id = ? WHERE profile_id = ?`, []any{keep.id, dup.id}},
+		{`DELETE FROM profile_variables WHERE profile_id = ?`, []any{dup.id}},
+		{`UPDATE profile_notes SET profile_id = ? WHERE profile_id = ?`, []any{keep.id, dup.id}},
+		{`UPDATE OR IGNORE profile_posts SET profile_id = ? WHERE profile_id = ?`, []any{keep.id, dup.id}},
+		{`DELETE FROM profile_posts WHERE profile_id = ?`, []any{dup.id}},
+		{`UPDATE OR IGNORE profile_keywords SET profile_id = ? WHERE profile_id = ?`, []any{keep.id, dup.id}},
+		{`DELETE FROM profile_keywords WHERE profile_id = ?`, []any{dup.id}},
+		{`UPDATE enrichment_queue SET profile_id = ? WHERE profile_id = ?`, []any{keep.id, dup.id}},
+		{`UPDATE score_history SET profile_id = ? WHERE profile_id = ?`, []any{keep.id, dup.id}},
+		{`UPDATE OR IGNORE profile_relationships SET source_profile_id = ? WHERE source_profile_id = ?`, []any{keep.id, dup.id}},
+		{`UPDATE OR IGNORE profile_relationships SET target_profile_id = ? WHERE target_profile_id = ?`, []any{keep.id, dup.id}},
+		{`DELETE FROM profile_relationships WHERE source_profile_id = ? OR target_profile_id = ? OR source_profile_id = target_profile_id`,
+			[]any{dup.id, dup.id}},
+		{`UPDATE OR IGNORE profile_tags SET profile_url = ? WHERE profile_url = ?`, []any{keep.url, dup.url}},
+		{`DELETE FROM profile_tags WHERE profile_url = ?`, []any{dup.url}},
+		{`UPDATE OR IGNORE auto_responder_log SET profile_url = ? WHERE profile_url = ?`, []any{keep.url, dup.url}},
+		{`DELETE FROM auto_responder_log WHERE profile_url = ?`, []any{dup.url}},
+		{`UPDATE OR IGNORE discovery_queue SET profile_url = ? WHERE profile_url = ?`, []any{keep.url, dup.url}},
+		{`DELETE FROM discovery_queue WHERE profile_url = ?`, []any{dup.url}},
+		{`UPDATE profile_aliases SET profile_url = ? WHERE profile_url = ?`, []any{keep.url, dup.url}},
+		{`INSERT OR REPLACE INTO profile_aliases (alias_url, profile_url, merged_at) VALUES (?, ?, ?)`,
+			[]any{dup.url, keep.url, time.Now().UTC().Format("2006-01-02 15:04:05")}},
+		{`DELETE FROM profiles WHERE id = ?`, []any{dup.id}},
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			return err
+		}
+	}
+
+	// Keep the furthest outreach state and the best score of the two
+	if stateProgress[dup.state] > stateProgress[keep.state] {
+		keep.state = dup.state
+	}
+	if dup.score > keep.score {
+		keep.score = dup.score
+	}
+	if _, err := tx.Exec(`UPDATE profiles SET connection_state = ?, score = MAX(score, ?) WHERE id = ?`,
+		keep.state, keep.score, keep.id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.invalidateProfile(keep.url)
+	s.invalidateProfile(dup.url)
+	return nil
+}
+
+// ResolveProfileAlias returns the URL a merged duplicate or redirected URL
+// now points to, or the URL itself if it isn't an alias
+func (s *Storage) ResolveProfileAlias(url string) (string, error) {
+	var canonical string
+	err := s.db.QueryRow(`
+		SELECT profile_url FROM profile_aliases WHERE alias_url = ?
+		UNION ALL
+		SELECT canonical_url FROM profile_url_redirects WHERE discovered_url = ? AND canonical_url != discovered_url
+		LIMIT 1
+	`, url, url).Scan(&canonical)
+	if err == sql.ErrNoRows {
+		return url, nil
+	}
+	if err != nil {
+		return url, err
+	}
+
+	return canonical, nil
+}
+
+// TitleOverlap returns the share of keywords two job titles have in common,
+// relative to the shorter title. Identical empty titles count as a match.
+func TitleOverlap(a, b string) float64 {
+	wordsA := titleKeywords(a)
+	wordsB := titleKeywords(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	common := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			common++
+		}
+	}
+
+	shorter := len(wordsA)
+	if len(wordsB) < shorter {
+		shorter = len(wordsB)
+	}
+	return float64(common) / float64(shorter)
+}
+
+// titleKeywords splits a job title into lowercase keywords
+func titleKeywords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '/' || r == '(' || r == ')'
+	}) {
+		if !titleStopWords[word] {
+			words[word] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeProfilesMovesPerProfileRows(t *testing.T) {
+	s := newTestStorage(t)
+
+	old := &Profile{ProfileURL: "https://www.linkedin.com/in/jane-old", Name: "Jane Doe", JobTitle: "Senior Engineer"}
+	dup := &Profile{ProfileURL: "https://www.linkedin.com/in/jane-new", Name: "Jane Doe", JobTitle: "Senior Engineer"}
+	for _, p := range []*Profile{old, dup} {
+		if _, err := s.SaveProfile(p); err != nil {
+			t.Fatalf("SaveProfile: %v", err)
+		}
+	}
+	// The newest profile is kept, so make the first one older
+	if _, err := s.db.Exec(`UPDATE profiles SET discovered_at = '2020-01-01 00:00:00' WHERE profile_url = ?`, old.ProfileURL); err != nil {
+		t.Fatalf("set discovered_at: %v", err)
+	}
+	// Only profiles linked by URL are merged
+	if err := s.SaveProfileRedirect(old.ProfileURL, dup.ProfileURL); err != nil {
+		t.Fatalf("SaveProfileRedirect: %v", err)
+	}
+
+	for _, tag := range []string{"hot", "pricing"} {
+		if err := s.TagProfile(old.ProfileURL, tag); err != nil {
+			t.Fatalf("TagProfile: %v", err)
+		}
+	}
+	if err := s.TagProfile(dup.ProfileURL, "hot"); err != nil {
+		t.Fatalf("TagProfile: %v", err)
+	}
+	if err := s.LogAutoResponderMatch(old.ProfileURL, 0, "tag", "pricing"); err != nil {
+		t.Fatalf("LogAutoResponderMatch: %v", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO discovery_queue (profile_url, source) VALUES (?, ?)`,
+		old.ProfileURL, DiscoverySourcePeopleAlsoViewed); err != nil {
+		t.Fatalf("queue discovery: %v", err)
+	}
+
+	result, err := s.DeduplicateProfiles()
+	if err != nil {
+		t.Fatalf("DeduplicateProfiles: %v", err)
+	}
+	if result.Merged != 1 || len(result.Flagged) != 0 {
+		t.Fatalf("merged = %d, flagged = %v; want 1 merge", result.Merged, result.Flagged)
+	}
+
+	tags, err := s.GetProfileTags(dup.ProfileURL)
+	if err != nil {
+		t.Fatalf("GetProfileTags: %v", err)
+	}
+	if want := []string{"hot", "pricing"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+
+	fired, err := s.HasAutoResponderFired(dup.ProfileURL, 0)
+	if err != nil {
+		t.Fatalf("HasAutoResponderFired: %v", err)
+	}
+	if !fired {
+		t.Error("auto responder log wasn't moved to the kept profile")
+	}
+
+	var queued int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM discovery_queue WHERE profile_url = ?`, dup.ProfileURL).Scan(&queued); err != nil {
+		t.Fatalf("count queue: %v", err)
+	}
+	if queued != 1 {
+		t.Errorf("discovery queue entry wasn't moved to the kept profile")
+	}
+
+	var leftover int
+	if err := s.db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM profile_tags WHERE profile_url = ?)
+			+ (SELECT COUNT(*) FROM auto_responder_log WHERE profile_url = ?)
+			+ (SELECT COUNT(*) FROM discovery_queue WHERE profile_url = ?)
+	`, old.ProfileURL, old.ProfileURL, old.ProfileURL).Scan(&leftover); err != nil {
+		t.Fatalf("count leftovers: %v", err)
+	}
+	if leftover != 0 {
+		t.Errorf("%d rows left on the merged duplicate", leftover)
+	}
+}
+
+func TestDeduplicateProfilesOnlyFlagsUnlinkedNamesakes(t *testing.T) {
+	s := newTestStorage(t)
+
+	first := &Profile{ProfileURL: "https://www.linkedin.com/in/jane-doe-1", Name: "Jane Doe", JobTitle: "Senior Engineer"}
+	second := &Profile{ProfileURL: "https://www.linkedin.com/in/jane-doe-2", Name: "Jane Doe", JobTitle: "Senior Software Engineer"}
+	other := &Profile{ProfileURL: "https://www.linkedin.com/in/jane-doe-3", Name: "Jane Doe", JobTitle: "Head of Marketing"}
+	for _, p := range []*Profile{first, second, other} {
+		if _, err := s.SaveProfile(p); err != nil {
+			t.Fatalf("SaveProfile: %v", err)
+		}
+	}
+
+	result, err := s.DeduplicateProfiles()
+	if err != nil {
+		t.Fatalf("DeduplicateProfiles: %v", err)
+	}
+	if result.Merged != 0 {
+		t.Errorf("merged = %d, want 0 without a URL link", result.Merged)
+	}
+	if len(result.Flagged) != 1 {
+		t.Fatalf("flagged = %v, want the two engineers", result.Flagged)
+	}
+	// The newer profile comes first
+	want := DuplicatePair{ProfileURL: second.ProfileURL, OtherURL: first.ProfileURL}
+	if result.Flagged[0] != want {
+		t.Errorf("flagged pair = %+v, want %+v", result.Flagged[0], want)
+	}
+
+	for _, p := range []*Profile{first, second, other} {
+		if got, err := s.GetProfileByURL(p.ProfileURL); err != nil || got == nil || got.ProfileURL != p.ProfileURL {
+			t.Errorf("profile %s was removed", p.ProfileURL)
+		}
+	}
+}
+
+func TestDeduplicateProfilesMergesSharedCanonicalURL(t *testing.T) {
+	s := newTestStorage(t)
+
+	vanity := &Profile{ProfileURL: "https://www.linkedin.com/in/jane-vanity", Name: "Jane Doe", JobTitle: "Engineer"}
+	if _, err := s.SaveProfile(vanity); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	if _, err := s.db.Exec(`UPDATE profiles SET discovered_at = '2020-01-01 00:00:00' WHERE profile_url = ?`, vanity.ProfileURL); err != nil {
+		t.Fatalf("set discovered_at: %v", err)
+	}
+
+	// Found again later under its canonical URL, with a different title
+	canonical := &Profile{ProfileURL: "https://www.linkedin.com/in/jane-canonical", Name: "Jane Doe", JobTitle: "Product Manager"}
+	if _, err := s.SaveProfile(canonical); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	if _, err := s.db.Exec(`UPDATE profiles SET discovered_url = ? WHERE profile_url = ?`, vanity.ProfileURL+"/", canonical.ProfileURL); err != nil {
+		t.Fatalf("set discovered_url: %v", err)
+	}
+
+	result, err := s.DeduplicateProfiles()
+	if err != nil {
+		t.Fatalf("DeduplicateProfiles: %v", err)
+	}
+	if result.Merged != 1 {
+		t.Fatalf("merged = %d, want 1", result.Merged)
+	}
+
+	resolved, err := s.ResolveProfileAlias(vanity.ProfileURL)
+	if err != nil {
+		t.Fatalf("ResolveProfileAlias: %v", err)
+	}
+	if resolved != canonical.ProfileURL {
+		t.Errorf("%s resolves to %s, want %s", vanity.ProfileURL, resolved, canonical.ProfileURL)
+	}
+}
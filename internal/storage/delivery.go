@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Message delivery statuses recorded by UpdateMessageDelivery
+const (
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusSeen      = "seen"
+)
+
+// GetMessagesAwaitingDelivery returns sent messages older than minAge that
+// haven't been seen yet. Messages never checked come first, oldest first,
+// then the ones checked longest ago, so unread old messages don't keep newer
+// ones from being checked.
+func (s *Storage) GetMessagesAwaitingDelivery(minAge time.Duration, limit int) ([]Message, error) {
+	cutoff := time.Now().UTC().Add(-minAge).Format("2006-01-02 15:04:05")
+
+	rows, err := s.db.Query(`
+		SELECT id, profile_id, profile_url, sent_at, COALESCE(thread_url, ''), COALESCE(delivery_status, '')
+		FROM messages
+		WHERE status = 'sent' AND sent_at <= ?
+			AND (delivery_status IS NULL OR delivery_status != ?)
+		ORDER BY delivery_checked_at IS NOT NULL, delivery_checked_at, sent_at
+		LIMIT ?
+	`, cutoff, DeliveryStatusSeen, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var profileID sql.NullInt64
+		if err := rows.Scan(&msg.ID, &profileID, &msg.ProfileURL, &msg.SentAt, &msg.ThreadURL, &msg.DeliveryStatus); err != nil {
+			return nil, err
+		}
+		msg.ProfileID = profileID.Int64
+		msg.Status = "sent"
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// MarkDeliveryChecked records that a message's delivery was just checked,
+// moving it to the back of GetMessagesAwaitingDelivery
+func (s *Storage) MarkDeliveryChecked(messageID int64) error {
+	_, err := s.db.Exec(`
+		UPDATE messages SET delivery_checked_at = ? WHERE id = ?
+	`, time.Now().UTC().Format("2006-01-02 15:04:05"), messageID)
+
+	return err
+}
+
+// GetMessagesAwaitingReply returns the latest message sent since the given
+// time to each profile that hasn't replied yet, most recent first
+func (s *Storage) GetMessagesAwaitingReply(since time.Time, limit int) ([]Message, error) {
+	rows, err := s.db.Query(`
+		SELECT m.id, m.profile_id, m.profile_url, m.sent_at, COALESCE(m.thread_url, '')
+		FROM messages m
+		JOIN profiles p ON p.profile_url = m.profile_url
+		WHERE m.status = 'sent' AND m.replied_at IS NULL AND m.sent_at >= ?
+			AND p.connection_state = ?
+			AND m.id = (
+				SELECT latest.id FROM messages latest
+				WHERE latest.profile_url = m.profile_url AND latest.status = 'sent'
+				ORDER BY latest.sent_at DESC, latest.id DESC LIMIT 1
+			)
+		ORDER BY m.sent_at DESC
+		LIMIT ?
+	`, since.UTC().Format("2006-01-02 15:04:05"), StateMessaged, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var profileID sql.NullInt64
+		if err := rows.Scan(&msg.ID, &profileID, &msg.ProfileURL, &msg.SentAt, &msg.ThreadURL); err != nil {
+			return nil, err
+		}
+		msg.ProfileID = profileID.Int64
+		msg.Status = "sent"
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// GetLatestSentMessage returns the most recent message sent to a profile, or
+// nil if none was sent
+func (s *Storage) GetLatestSentMessage(profileURL string) (*Message, error) {
+	var msg Message
+	var profileID sql.NullInt64
+	var seenAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT id, profile_id, profile_url, content, sent_at, status,
+			COALESCE(thread_url, ''), template_id, COALESCE(delivery_status, ''), seen_at
+		FROM messages
+		WHERE profile_url = ? AND status = 'sent'
+		ORDER BY sent_at DESC, id DESC
+		LIMIT 1
+	`, profileURL).Scan(&msg.ID, &profileID, &msg.ProfileURL, &msg.Content, &msg.SentAt, &msg.Status,
+		&msg.ThreadURL, &msg.TemplateID, &msg.DeliveryStatus, &seenAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	msg.ProfileID = profileID.Int64
+	if seenAt.Valid {
+		msg.SeenAt = &seenAt.Time
+	}
+	return &msg, nil
+}
+
+// UpdateMessageDelivery records the delivery status of a sent message
+func (s *Storage) UpdateMessageDelivery(messageID int64, status string, seenAt *time.Time) error {
+	_, err := s.db.Exec(`
+		UPDATE messages SET delivery_status = ?, seen_at = ? WHERE id = ?
+	`, status, seenAt, messageID)
+
+	return err
+}
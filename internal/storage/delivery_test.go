@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetMessagesAwaitingReply(t *testing.T) {
+	s := newTestStorage(t)
+
+	waiting := saveTestProfile(t, s, "waiting")
+	replied := saveTestProfile(t, s, "replied")
+	stale := saveTestProfile(t, s, "stale")
+
+	for _, p := range []*Profile{waiting, replied, stale} {
+		if _, err := s.db.Exec(`UPDATE profiles SET connection_state = ? WHERE id = ?`, StateMessaged, p.ID); err != nil {
+			t.Fatalf("set state: %v", err)
+		}
+		if err := s.SaveMessage(&Message{ProfileID: p.ID, ProfileURL: p.ProfileURL, Content: "hi", Status: "sent"}); err != nil {
+			t.Fatalf("SaveMessage: %v", err)
+		}
+	}
+	if _, err := s.db.Exec(`UPDATE messages SET sent_at = ? WHERE profile_url = ?`,
+		time.Now().AddDate(0, 0, -30).UTC().Format("2006-01-02 15:04:05"), stale.ProfileURL); err != nil {
+		t.Fatalf("set sent_at: %v", err)
+	}
+	if err := s.MarkMessageReplied(replied.ProfileURL); err != nil {
+		t.Fatalf("MarkMessageReplied: %v", err)
+	}
+
+	messages, err := s.GetMessagesAwaitingReply(time.Now().AddDate(0, 0, -14), 10)
+	if err != nil {
+		t.Fatalf("GetMessagesAwaitingReply: %v", err)
+	}
+	if len(messages) != 1 || messages[0].ProfileURL != waiting.ProfileURL {
+		t.Fatalf("messages = %+v, want only %s", messages, waiting.ProfileURL)
+	}
+}
+
+func TestGetMessagesAwaitingDeliveryRotates(t *testing.T) {
+	s := newTestStorage(t)
+
+	var urls []string
+	for i, name := range []string{"oldest", "older", "newest"} {
+		p := saveTestProfile(t, s, name)
+		if err := s.SaveMessage(&Message{ProfileID: p.ID, ProfileURL: p.ProfileURL, Content: "hi", Status: "sent"}); err != nil {
+			t.Fatalf("SaveMessage: %v", err)
+		}
+		sentAt := time.Now().Add(-time.Duration(3-i) * time.Hour)
+		if _, err := s.db.Exec(`UPDATE messages SET sent_at = ? WHERE profile_url = ?`,
+			sentAt.UTC().Format("2006-01-02 15:04:05"), p.ProfileURL); err != nil {
+			t.Fatalf("set sent_at: %v", err)
+		}
+		urls = append(urls, p.ProfileURL)
+	}
+
+	first, err := s.GetMessagesAwaitingDelivery(30*time.Minute, 2)
+	if err != nil {
+		t.Fatalf("GetMessagesAwaitingDelivery: %v", err)
+	}
+	if len(first) != 2 || first[0].ProfileURL != urls[0] || first[1].ProfileURL != urls[1] {
+		t.Fatalf("first pass = %+v, want the two oldest", first)
+	}
+
+	// Neither was seen, so they stay pending but move behind the unchecked one
+	for _, msg := range first {
+		if err := s.MarkDeliveryChecked(msg.ID); err != nil {
+			t.Fatalf("MarkDeliveryChecked: %v", err)
+		}
+	}
+
+	second, err := s.GetMessagesAwaitingDelivery(30*time.Minute, 2)
+	if err != nil {
+		t.Fatalf("GetMessagesAwaitingDelivery: %v", err)
+	}
+	if len(second) != 2 || second[0].ProfileURL != urls[2] {
+		t.Fatalf("second pass = %+v, want %s first", second, urls[2])
+	}
+
+	if err := s.UpdateMessageDelivery(second[0].ID, DeliveryStatusSeen, nil); err != nil {
+		t.Fatalf("UpdateMessageDelivery: %v", err)
+	}
+	all, err := s.GetMessagesAwaitingDelivery(30*time.Minute, 10)
+	if err != nil {
+		t.Fatalf("GetMessagesAwaitingDelivery: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("%d messages pending, want 2 once one was seen", len(all))
+	}
+}
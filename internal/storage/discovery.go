@@ -0,0 +1,122 @@
+package storage
+
+import "time"
+
+// Discovery sources recorded in profiles.discovery_source
+const (
+	DiscoverySourceSearch           = "search"
+	DiscoverySourcePeopleAlsoViewed = "people_also_viewed"
+	DiscoverySourceNavigatorImport  = "navigator_import"
+)
+
+// MaxDiscoveryAttempts is how many times a queued profile is visited before
+// it is given up on
+const MaxDiscoveryAttempts = 3
+
+// DiscoveryEntry is a profile found outside search, waiting to be visited
+type DiscoveryEntry struct {
+	ID               int64
+	ProfileURL       string
+	Source           string
+	SourceProfileURL string
+	AddedAt          time.Time
+	Attempts         int
+}
+
+// QueueDiscovery adds a profile URL to the discovery queue unless it is
+// already queued or stored. It reports whether the URL was added.
+func (s *Storage) QueueDiscovery(profileURL, source, sourceProfileURL string) (bool, error) {
+	result, err := s.db.Exec(`
+		INSERT OR IGNORE INTO discovery_queue (profile_url, source, source_profile_url)
+		SELECT ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM profiles WHERE profile_url = ?)
+	`, profileURL, source, sourceProfileURL, profileURL)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetPendingDiscoveries returns queued profiles that haven't been visited
+// yet, fewest failed attempts first and then oldest first
+func (s *Storage) GetPendingDiscoveries(limit int) ([]DiscoveryEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, profile_url, source, source_profile_url, added_at, attempts
+		FROM discovery_queue
+		WHERE processed_at IS NULL
+		ORDER BY attempts, id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []DiscoveryEntry
+	for rows.Next() {
+		var entry DiscoveryEntry
+		if err := rows.Scan(&entry.ID, &entry.ProfileURL, &entry.Source, &entry.SourceProfileURL, &entry.AddedAt, &entry.Attempts); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// MarkDiscoveryProcessed removes an entry from the pending discovery queue
+func (s *Storage) MarkDiscoveryProcessed(id int64) error {
+	_, err := s.db.Exec(`
+		UPDATE discovery_queue SET processed_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, id)
+
+	return err
+}
+
+// RecordDiscoveryFailure counts a failed visit to a queued profile, leaving
+// it queued for a retry until MaxDiscoveryAttempts is reached. It reports
+// whether the entry was given up on.
+func (s *Storage) RecordDiscoveryFailure(id int64) (bool, error) {
+	_, err := s.db.Exec(`
+		UPDATE discovery_queue
+		SET attempts = attempts + 1,
+			processed_at = CASE WHEN attempts + 1 >= ? THEN CURRENT_TIMESTAMP ELSE processed_at END
+		WHERE id = ?
+	`, MaxDiscoveryAttempts, id)
+	if err != nil {
+		return false, err
+	}
+
+	var attempts int
+	err = s.db.QueryRow(`SELECT attempts FROM discovery_queue WHERE id = ?`, id).Scan(&attempts)
+	return attempts >= MaxDiscoveryAttempts, err
+}
+
+// GetQueuedProfiles returns profiles from a discovery source that are queued
+// for connecting and haven't been sent a request yet, oldest first
+func (s *Storage) GetQueuedProfiles(source string) ([]*Profile, error) {
+	rows, err := s.db.Query(`
+		SELECT `+profileColumns+`
+		FROM profiles
+		WHERE connection_state = ? AND discovery_source = ?
+			AND profile_url NOT IN (SELECT profile_url FROM connection_requests)
+		ORDER BY id
+	`, StateQueued, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
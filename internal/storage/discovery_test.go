@@ -0,0 +1,83 @@
+package storage
+
+import "testing"
+
+func TestQueueDiscoverySkipsStoredProfiles(t *testing.T) {
+	s := newTestStorage(t)
+
+	stored := saveTestProfile(t, s, "stored")
+
+	added, err := s.QueueDiscovery(stored.ProfileURL, DiscoverySourcePeopleAlsoViewed, "")
+	if err != nil {
+		t.Fatalf("QueueDiscovery: %v", err)
+	}
+	if added {
+		t.Error("queued a profile that is already stored")
+	}
+
+	const url = "https://www.linkedin.com/in/new"
+	for i, want := range []bool{true, false} {
+		added, err := s.QueueDiscovery(url, DiscoverySourcePeopleAlsoViewed, stored.ProfileURL)
+		if err != nil {
+			t.Fatalf("QueueDiscovery: %v", err)
+		}
+		if added != want {
+			t.Errorf("QueueDiscovery #%d added = %v, want %v", i+1, added, want)
+		}
+	}
+}
+
+func TestFailedDiscoveriesDontBlockTheQueue(t *testing.T) {
+	s := newTestStorage(t)
+
+	urls := []string{
+		"https://www.linkedin.com/in/broken",
+		"https://www.linkedin.com/in/second",
+		"https://www.linkedin.com/in/third",
+	}
+	for _, url := range urls {
+		if _, err := s.QueueDiscovery(url, DiscoverySourcePeopleAlsoViewed, ""); err != nil {
+			t.Fatalf("QueueDiscovery: %v", err)
+		}
+	}
+
+	head := func() DiscoveryEntry {
+		t.Helper()
+		entries, err := s.GetPendingDiscoveries(1)
+		if err != nil {
+			t.Fatalf("GetPendingDiscoveries: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("got %d entries, want 1", len(entries))
+		}
+		return entries[0]
+	}
+
+	broken := head()
+	if broken.ProfileURL != urls[0] {
+		t.Fatalf("head = %s, want %s", broken.ProfileURL, urls[0])
+	}
+
+	for i := 1; i <= MaxDiscoveryAttempts; i++ {
+		gaveUp, err := s.RecordDiscoveryFailure(broken.ID)
+		if err != nil {
+			t.Fatalf("RecordDiscoveryFailure: %v", err)
+		}
+		if gaveUp != (i == MaxDiscoveryAttempts) {
+			t.Errorf("attempt %d gave up = %v", i, gaveUp)
+		}
+
+		// A failed entry moves behind the untried ones
+		if next := head(); next.ProfileURL == urls[0] {
+			t.Fatalf("failed entry still at the head of the queue after %d attempts", i)
+		}
+	}
+
+	entries, err := s.GetPendingDiscoveries(10)
+	if err != nil {
+		t.Fatalf("GetPendingDiscoveries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("%d entries pending, want 2 after giving up on %s", len(entries), urls[0])
+	}
+}
@@ -0,0 +1,32 @@
+package storage
+
+// SaveSkillEndorsement records that we endorsed one of a profile's skills
+func (s *Storage) SaveSkillEndorsement(profileID int64, profileURL, skill string) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO skill_endorsements (profile_id, profile_url, skill) VALUES (?, ?, ?)
+	`, profileID, profileURL, skill)
+
+	return err
+}
+
+// GetEndorsedSkills returns the skills we've endorsed for a profile
+func (s *Storage) GetEndorsedSkills(profileURL string) (map[string]bool, error) {
+	rows, err := s.db.Query(`
+		SELECT skill FROM skill_endorsements WHERE profile_url = ?
+	`, profileURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := make(map[string]bool)
+	for rows.Next() {
+		var skill string
+		if err := rows.Scan(&skill); err != nil {
+			return nil, err
+		}
+		skills[skill] = true
+	}
+
+	return skills, rows.Err()
+}
@@ -0,0 +1,34 @@
+package storage
+
+import "time"
+
+// PostEngagement records an interaction with a LinkedIn post
+type PostEngagement struct {
+	ID         int64
+	ProfileID  int64
+	ProfileURL string
+	PostURL    string
+	Action     string // comment
+	Content    string
+	CreatedAt  time.Time
+}
+
+// SavePostEngagement records an interaction with a post
+func (s *Storage) SavePostEngagement(e *PostEngagement) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO post_engagements (profile_id, profile_url, post_url, action, content)
+		VALUES (?, ?, ?, ?, ?)
+	`, e.ProfileID, e.ProfileURL, e.PostURL, e.Action, e.Content)
+
+	return err
+}
+
+// HasEngagedPost reports whether the given action was already taken on a post
+func (s *Storage) HasEngagedPost(postURL, action string) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM post_engagements WHERE post_url = ? AND action = ?
+	`, postURL, action).Scan(&count)
+
+	return count > 0, err
+}
@@ -0,0 +1,113 @@
+package storage
+
+import "time"
+
+// EnrichmentRefreshInterval is how long an enriched profile isn't queued again
+const EnrichmentRefreshInterval = 30 * 24 * time.Hour
+
+// MaxEnrichmentAttempts is how many times a queue entry is tried before it
+// is given up on
+const MaxEnrichmentAttempts = 3
+
+// Enrichment queue priorities, highest processed first
+const (
+	EnrichmentPriorityNormal = 0
+	EnrichmentPriorityHigh   = 1
+)
+
+// EnrichmentEntry is a profile waiting to have its profile page visited
+type EnrichmentEntry struct {
+	ID        int64
+	ProfileID int64
+	Priority  int
+	QueuedAt  time.Time
+
+	// Attempts counts failed enrichments of this entry
+	Attempts int
+}
+
+// QueueEnrichment adds a profile to the enrichment queue unless it is already
+// queued or was enriched within EnrichmentRefreshInterval. It reports whether
+// the profile was added.
+func (s *Storage) QueueEnrichment(profileID int64, priority int) (bool, error) {
+	cutoff := time.Now().UTC().Add(-EnrichmentRefreshInterval).Format("2006-01-02 15:04:05")
+
+	result, err := s.db.Exec(`
+		INSERT INTO enrichment_queue (profile_id, priority)
+		SELECT ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM enrichment_queue
+			WHERE profile_id = ? AND (processed_at IS NULL OR processed_at >= ?)
+		)
+	`, profileID, priority, profileID, cutoff)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetPendingEnrichments returns unprocessed queue entries, highest priority
+// first, then entries that failed least often and oldest first, so a failing
+// entry doesn't hold up the rest of the queue
+func (s *Storage) GetPendingEnrichments(limit int) ([]EnrichmentEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, profile_id, priority, queued_at, attempts
+		FROM enrichment_queue
+		WHERE processed_at IS NULL
+		ORDER BY priority DESC, attempts, id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []EnrichmentEntry
+	for rows.Next() {
+		var entry EnrichmentEntry
+		if err := rows.Scan(&entry.ID, &entry.ProfileID, &entry.Priority, &entry.QueuedAt, &entry.Attempts); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// MarkEnrichmentProcessed removes an entry from the pending enrichment queue
+func (s *Storage) MarkEnrichmentProcessed(id int64) error {
+	_, err := s.db.Exec(`
+		UPDATE enrichment_queue SET processed_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, id)
+
+	return err
+}
+
+// RecordEnrichmentFailure counts a failed attempt at an entry, leaving it
+// queued for a retry until MaxEnrichmentAttempts is reached. It reports
+// whether the entry was given up on.
+func (s *Storage) RecordEnrichmentFailure(id int64) (bool, error) {
+	_, err := s.db.Exec(`
+		UPDATE enrichment_queue
+		SET attempts = attempts + 1,
+			processed_at = CASE WHEN attempts + 1 >= ? THEN CURRENT_TIMESTAMP ELSE processed_at END
+		WHERE id = ?
+	`, MaxEnrichmentAttempts, id)
+	if err != nil {
+		return false, err
+	}
+
+	var attempts int
+	err = s.db.QueryRow(`SELECT attempts FROM enrichment_queue WHERE id = ?`, id).Scan(&attempts)
+	return attempts >= MaxEnrichmentAttempts, err
+}
+
+// GetEnrichmentQueueDepth returns how many profiles are waiting to be
+// enriched, 0 if the queue can't be read
+func (s *Storage) GetEnrichmentQueueDepth() int {
+	var depth int
+	s.db.QueryRow(`SELECT COUNT(*) FROM enrichment_queue WHERE processed_at IS NULL`).Scan(&depth)
+	return depth
+}
@@ -0,0 +1,29 @@
+package storage
+
+import "time"
+
+// GetExpiredConnections returns connection requests marked expired between
+// from and to, most recent first
+func (s *Storage) GetExpiredConnections(from, to time.Time) ([]ConnectionRequest, error) {
+	rows, err := s.db.Query(`
+		SELECT id, profile_id, profile_url, sent_at, COALESCE(note, ''), status, accepted_at, expired_at
+		FROM connection_requests
+		WHERE status = 'expired' AND expired_at >= ? AND expired_at <= ?
+		ORDER BY expired_at DESC
+	`, from.UTC().Format("2006-01-02 15:04:05"), to.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var connections []ConnectionRequest
+	for rows.Next() {
+		var conn ConnectionRequest
+		if err := rows.Scan(&conn.ID, &conn.ProfileID, &conn.ProfileURL, &conn.SentAt, &conn.Note, &conn.Status, &conn.AcceptedAt, &conn.ExpiredAt); err != nil {
+			return nil, err
+		}
+		connections = append(connections, conn)
+	}
+
+	return connections, rows.Err()
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// hubSpotHeaders are the HubSpot contact import column names
+var hubSpotHeaders = []string{
+	"First Name",
+	"Last Name",
+	"Job Title",
+	"Company Name",
+	"LinkedIn Profile URL",
+	"LinkedIn Connection Status",
+	"Notes",
+	"Create Date",
+	"Lifecycle Stage",
+}
+
+// profileHeaders are the column names of the plain profile CSV export
+var profileHeaders = []string{
+	"profile_url",
+	"name",
+	"job_title",
+	"company",
+	"location",
+	"keywords",
+	"open_to_work",
+	"connection_status",
+	"connection_note",
+	"discovered_at",
+}
+
+// outreachStatus summarizes the connection and messaging state of a profile
+type outreachStatus struct {
+	status   string
+	note     string
+	messaged bool
+}
+
+// ExportHubSpotCSV writes profiles matching opts as a HubSpot-importable CSV
+func (s *Storage) ExportHubSpotCSV(w io.Writer, opts ProfileQueryOptions) error {
+	profiles, err := s.ListProfiles(opts)
+	if err != nil {
+		return fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	statuses, err := s.getOutreachStatuses()
+	if err != nil {
+		return fmt.Errorf("failed to load connection statuses: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(hubSpotHeaders); err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		firstName, lastName := splitName(profile.Name)
+		outreach := statuses[profile.ProfileURL]
+
+		connectionStatus := outreach.status
+		if outreach.messaged {
+			connectionStatus = "messaged"
+		}
+
+		record := []string{
+			firstName,
+			lastName,
+			profile.JobTitle,
+			profile.Company,
+			profile.ProfileURL,
+			connectionStatus,
+			outreach.note,
+			profile.DiscoveredAt.Format("2006-01-02"),
+			hubSpotLifecycleStage(connectionStatus),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportProfilesCSV writes profiles matching opts as CSV, including the
+// connection status and the note sent with the connection request
+func (s *Storage) ExportProfilesCSV(w io.Writer, opts ProfileQueryOptions) error {
+	profiles, err := s.ListProfiles(opts)
+	if err != nil {
+		return fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	statuses, err := s.getOutreachStatuses()
+	if err != nil {
+		return fmt.Errorf("failed to load connection statuses: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(profileHeaders); err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		outreach := statuses[profile.ProfileURL]
+
+		record := []string{
+			profile.ProfileURL,
+			profile.Name,
+			profile.JobTitle,
+			profile.Company,
+			profile.Location,
+			profile.Keywords,
+			strconv.FormatBool(profile.OpenToWork),
+			outreach.status,
+			outreach.note,
+			profile.DiscoveredAt.Format("2006-01-02 15:04:05"),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// getOutreachStatuses returns the latest connection status and note for every
+// contacted profile, keyed by profile URL
+func (s *Storage) getOutreachStatuses() (map[string]outreachStatus, error) {
+	statuses := make(map[string]outreachStatus)
+
+	rows, err := s.db.Query(`
+		SELECT profile_url, COALESCE(status, ''), COALESCE(note, '')
+		FROM connection_requests
+		ORDER BY sent_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var url string
+		var status outreachStatus
+		if err := rows.Scan(&url, &status.status, &status.note); err != nil {
+			return nil, err
+		}
+		statuses[url] = status
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	msgRows, err := s.db.Query(`SELECT DISTINCT profile_url FROM messages WHERE status = 'sent'`)
+	if err != nil {
+		return nil, err
+	}
+	defer msgRows.Close()
+
+	for msgRows.Next() {
+		var url string
+		if err := msgRows.Scan(&url); err != nil {
+			return nil, err
+		}
+		status := statuses[url]
+		status.messaged = true
+		statuses[url] = status
+	}
+
+	return statuses, msgRows.Err()
+}
+
+// hubSpotLifecycleStage maps a connection status to a HubSpot lifecycle stage
+func hubSpotLifecycleStage(status string) string {
+	switch status {
+	case "pending":
+		return "Lead"
+	case "accepted":
+		return "MQL"
+	case "messaged":
+		return "SQL"
+	default:
+		return ""
+	}
+}
+
+// splitName splits a full name into first name and the remaining last name
+func splitName(fullName string) (string, string) {
+	parts := strings.Fields(fullName)
+	if len(parts) == 0 {
+		return "", ""
+	}
+	return parts[0], strings.Join(parts[1:], " ")
+}
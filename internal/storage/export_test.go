@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportHubSpotCSV(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	pending := saveTestProfile(t, s, "pending")
+	saveTestConnection(t, s, pending, now, "pending")
+	accepted := saveTestProfile(t, s, "accepted")
+	saveTestConnection(t, s, accepted, now, "accepted")
+	messaged := saveTestProfile(t, s, "messaged")
+	saveTestConnection(t, s, messaged, now, "accepted")
+	if err := s.SaveMessage(&Message{ProfileID: messaged.ID, ProfileURL: messaged.ProfileURL, Content: "Hi", Status: "sent"}); err != nil {
+		t.Fatalf("SaveMessage: %v", err)
+	}
+
+	named := &Profile{
+		ProfileURL: "https://www.linkedin.com/in/jane-doe",
+		Name:       "Jane van der Berg",
+		JobTitle:   "VP, Sales",
+		Company:    `Acme "Rockets"`,
+	}
+	if _, err := s.SaveProfile(named); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.ExportHubSpotCSV(&buf, ProfileQueryOptions{}); err != nil {
+		t.Fatalf("ExportHubSpotCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("exported CSV does not parse: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("exported %d rows, want a header and 4 profiles", len(records))
+	}
+	if !reflect.DeepEqual(records[0], hubSpotHeaders) {
+		t.Errorf("header = %v, want %v", records[0], hubSpotHeaders)
+	}
+
+	rows := make(map[string][]string)
+	for _, record := range records[1:] {
+		rows[record[4]] = record
+	}
+
+	for url, want := range map[string][2]string{
+		pending.ProfileURL:  {"pending", "Lead"},
+		accepted.ProfileURL: {"accepted", "MQL"},
+		messaged.ProfileURL: {"messaged", "SQL"},
+		named.ProfileURL:    {"", ""},
+	} {
+		row := rows[url]
+		if row == nil {
+			t.Errorf("%s missing from export", url)
+			continue
+		}
+		if row[5] != want[0] || row[8] != want[1] {
+			t.Errorf("%s status/stage = %q/%q, want %q/%q", url, row[5], row[8], want[0], want[1])
+		}
+	}
+
+	row := rows[named.ProfileURL]
+	if row[0] != "Jane" || row[1] != "van der Berg" {
+		t.Errorf("name split = %q/%q, want Jane/van der Berg", row[0], row[1])
+	}
+	if row[2] != "VP, Sales" || row[3] != `Acme "Rockets"` {
+		t.Errorf("title/company = %q/%q, want commas and quotes round-tripped", row[2], row[3])
+	}
+	if _, err := time.Parse("2006-01-02", row[7]); err != nil {
+		t.Errorf("create date %q is not YYYY-MM-DD", row[7])
+	}
+}
+
+func TestExportProfilesCSVIncludesConnectionNote(t *testing.T) {
+	s := newTestStorage(t)
+
+	jane := saveTestProfile(t, s, "jane")
+	if err := s.SaveConnectionRequest(&ConnectionRequest{ProfileID: jane.ID, ProfileURL: jane.ProfileURL, Note: "Hi Jane, let's connect", Status: "pending"}); err != nil {
+		t.Fatalf("SaveConnectionRequest: %v", err)
+	}
+	saveTestProfile(t, s, "john")
+
+	var buf bytes.Buffer
+	if err := s.ExportProfilesCSV(&buf, ProfileQueryOptions{}); err != nil {
+		t.Fatalf("ExportProfilesCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want a header and 2 profiles", len(records))
+	}
+
+	column := -1
+	for i, header := range records[0] {
+		if header == "connection_note" {
+			column = i
+		}
+	}
+	if column < 0 {
+		t.Fatalf("headers %v lack connection_note", records[0])
+	}
+
+	notes := make(map[string]string)
+	for _, record := range records[1:] {
+		notes[record[0]] = record[column]
+	}
+	want := map[string]string{
+		jane.ProfileURL:                    "Hi Jane, let's connect",
+		"https://www.linkedin.com/in/john": "",
+	}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("connection notes = %v, want %v", notes, want)
+	}
+}
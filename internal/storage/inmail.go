@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// InMailBalance is a reading of the remaining Premium InMail credits
+type InMailBalance struct {
+	Balance   int       `json:"balance"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// SaveInMailBalance records the remaining InMail credits
+func (s *Storage) SaveInMailBalance(balance int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO inmail_balance (balance, checked_at) VALUES (?, ?)
+	`, balance, time.Now().UTC().Format("2006-01-02 15:04:05"))
+
+	return err
+}
+
+// GetLatestInMailBalance returns the most recent InMail credit reading, or
+// nil when the balance was never checked
+func (s *Storage) GetLatestInMailBalance() (*InMailBalance, error) {
+	var balance InMailBalance
+	err := s.db.QueryRow(`
+		SELECT balance, checked_at FROM inmail_balance ORDER BY checked_at DESC, id DESC LIMIT 1
+	`).Scan(&balance.Balance, &balance.CheckedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
@@ -0,0 +1,26 @@
+package storage
+
+import "testing"
+
+func TestGetLatestInMailBalance(t *testing.T) {
+	s := newTestStorage(t)
+
+	if balance, err := s.GetLatestInMailBalance(); err != nil || balance != nil {
+		t.Fatalf("GetLatestInMailBalance before any check = %+v, %v, want nil", balance, err)
+	}
+
+	// Readings within the same second are ordered by insertion
+	for _, credits := range []int{30, 12} {
+		if err := s.SaveInMailBalance(credits); err != nil {
+			t.Fatalf("SaveInMailBalance(%d): %v", credits, err)
+		}
+	}
+
+	balance, err := s.GetLatestInMailBalance()
+	if err != nil {
+		t.Fatalf("GetLatestInMailBalance: %v", err)
+	}
+	if balance == nil || balance.Balance != 12 || balance.CheckedAt.IsZero() {
+		t.Errorf("latest balance = %+v, want the last reading of 12", balance)
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import "strings"
+
+// ProfileText is the combined name, title, company, headline and summary of
+// a profile, the document keywords are extracted from
+type ProfileText struct {
+	ProfileID int64
+	Name      string
+	Text      string
+}
+
+// ProfileKeyword is a keyword extracted from a profile with its TF-IDF score
+type ProfileKeyword struct {
+	Keyword string
+	Score   float64
+}
+
+// GetProfileTexts returns the keyword extraction document of every profile
+func (s *Storage) GetProfileTexts() ([]ProfileText, error) {
+	rows, err := s.db.Query(`
+		SELECT id, COALESCE(name, ''), COALESCE(job_title, ''), COALESCE(company, ''),
+			COALESCE(headline, ''), COALESCE(summary, '')
+		FROM profiles
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var texts []ProfileText
+	for rows.Next() {
+		var id int64
+		var name, title, company, headline, summary string
+		if err := rows.Scan(&id, &name, &title, &company, &headline, &summary); err != nil {
+			return nil, err
+		}
+		texts = append(texts, ProfileText{
+			ProfileID: id,
+			Name:      name,
+			Text:      strings.Join([]string{name, title, company, headline, summary}, "\n"),
+		})
+	}
+
+	return texts, rows.Err()
+}
+
+// ReplaceProfileKeywords replaces the stored keywords of each profile in
+// keywords in a single transaction
+func (s *Storage) ReplaceProfileKeywords(keywords map[int64][]ProfileKeyword) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for profileID, profileKeywords := range keywords {
+		if _, err := tx.Exec(`DELETE FROM profile_keywords WHERE profile_id = ?`, profileID); err != nil {
+			return err
+		}
+		for _, keyword := range profileKeywords {
+			_, err := tx.Exec(`
+				INSERT OR REPLACE INTO profile_keywords (profile_id, keyword, score)
+				VALUES (?, ?, ?)
+			`, profileID, keyword.Keyword, keyword.Score)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetProfileKeywords returns a profile's top keywords, highest score first
+func (s *Storage) GetProfileKeywords(profileID int64, limit int) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT keyword FROM profile_keywords
+		WHERE profile_id = ?
+		ORDER BY score DESC, keyword
+		LIMIT ?
+	`, profileID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keywords []string
+	for rows.Next() {
+		var keyword string
+		if err := rows.Scan(&keyword); err != nil {
+			return nil, err
+		}
+		keywords = append(keywords, keyword)
+	}
+
+	return keywords, rows.Err()
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Message engagement statuses estimated by the message engagement tracker
+const (
+	EngagementReplied    = "replied"
+	EngagementReadLikely = "read_likely"
+	EngagementUnread     = "unread"
+	EngagementUnknown    = "unknown"
+)
+
+// EngagementStats summarizes estimated engagement with messages checked
+// between From and To
+type EngagementStats struct {
+	From       time.Time
+	To         time.Time
+	Checked    int
+	Replied    int
+	ReadLikely int
+	Unread     int
+	Unknown    int
+
+	// ReadRate is the percentage of conclusive checks that were replied or
+	// likely read
+	ReadRate float64
+}
+
+// GetMessagesAwaitingEngagementCheck returns sent messages sent before
+// sentBefore that have no engagement record yet, oldest first
+func (s *Storage) GetMessagesAwaitingEngagementCheck(sentBefore time.Time, limit int) ([]Message, error) {
+	rows, err := s.db.Query(`
+		SELECT m.id, m.profile_id, m.profile_url, m.sent_at, COALESCE(m.delivery_status, ''), m.replied_at
+		FROM messages m
+		WHERE m.status = 'sent' AND m.sent_at <= ?
+			AND NOT EXISTS (SELECT 1 FROM message_engagements e WHERE e.message_id = m.id)
+		ORDER BY m.sent_at
+		LIMIT ?
+	`, sentBefore.UTC().Format("2006-01-02 15:04:05"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var profileID sql.NullInt64
+		var repliedAt sql.NullTime
+		if err := rows.Scan(&msg.ID, &profileID, &msg.ProfileURL, &msg.SentAt, &msg.DeliveryStatus, &repliedAt); err != nil {
+			return nil, err
+		}
+		msg.ProfileID = profileID.Int64
+		msg.Status = "sent"
+		if repliedAt.Valid {
+			msg.RepliedAt = &repliedAt.Time
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// SaveMessageEngagement records the estimated engagement with a sent message
+func (s *Storage) SaveMessageEngagement(messageID int64, profileURL, status string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO message_engagements (message_id, profile_url, status) VALUES (?, ?, ?)
+	`, messageID, profileURL, status)
+
+	return err
+}
+
+// GetEngagementStats counts engagement checks made between from and to
+// (inclusive, by date)
+func (s *Storage) GetEngagementStats(from, to time.Time) (*EngagementStats, error) {
+	stats := &EngagementStats{From: from, To: to}
+
+	rows, err := s.db.Query(`
+		SELECT status, COUNT(*) FROM message_engagements
+		WHERE DATE(checked_at) BETWEEN ? AND ?
+		GROUP BY status
+	`, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count message engagements: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+
+		stats.Checked += count
+		switch status {
+		case EngagementReplied:
+			stats.Replied = count
+		case EngagementReadLikely:
+			stats.ReadLikely = count
+		case EngagementUnread:
+			stats.Unread = count
+		default:
+			stats.Unknown += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats.ReadRate = percentage(stats.Replied+stats.ReadLikely, stats.Checked-stats.Unknown)
+	return stats, nil
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNoteNotFound is returned when updating or deleting a missing note
+var ErrNoteNotFound = errors.New("note not found")
+
+// notesRankWeight scales note matches in SearchProfilesFTS so they rank
+// below matches on the profile itself
+const notesRankWeight = 0.5
+
+// Note is a free-text note attached to a profile
+type Note struct {
+	ID        int64     `json:"id"`
+	ProfileID int64     `json:"profile_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// searchIndexSchema creates the full-text indexes over profiles and notes.
+// Triggers keep them in sync with their content tables.
+const searchIndexSchema = `
+	CREATE VIRTUAL TABLE profiles_fts USING fts5(
+		name, job_title, company, headline, summary,
+		content='profiles', content_rowid='id'
+	);
+
+	CREATE TRIGGER profiles_fts_insert AFTER INSERT ON profiles BEGIN
+		INSERT INTO profiles_fts (rowid, name, job_title, company, headline, summary)
+		VALUES (new.id, new.name, new.job_title, new.company, new.headline, new.summary);
+	END;
+
+	CREATE TRIGGER profiles_fts_delete AFTER DELETE ON profiles BEGIN
+		INSERT INTO profiles_fts (profiles_fts, rowid, name, job_title, company, headline, summary)
+		VALUES ('delete', old.id, old.name, old.job_title, old.company, old.headline, old.summary);
+	END;
+
+	CREATE TRIGGER profiles_fts_update AFTER UPDATE ON profiles BEGIN
+		INSERT INTO profiles_fts (profiles_fts, rowid, name, job_title, company, headline, summary)
+		VALUES ('delete', old.id, old.name, old.job_title, old.company, old.headline, old.summary);
+		INSERT INTO profiles_fts (rowid, name, job_title, company, headline, summary)
+		VALUES (new.id, new.name, new.job_title, new.company, new.headline, new.summary);
+	END;
+
+	CREATE VIRTUAL TABLE profile_notes_fts USING fts5(
+		content,
+		content='profile_notes', content_rowid='id'
+	);
+
+	CREATE TRIGGER profile_notes_fts_insert AFTER INSERT ON profile_notes BEGIN
+		INSERT INTO profile_notes_fts (rowid, content) VALUES (new.id, new.content);
+	END;
+
+	CREATE TRIGGER profile_notes_fts_delete AFTER DELETE ON profile_notes BEGIN
+		INSERT INTO profile_notes_fts (profile_notes_fts, rowid, content) VALUES ('delete', old.id, old.content);
+	END;
+
+	CREATE TRIGGER profile_notes_fts_update AFTER UPDATE ON profile_notes BEGIN
+		INSERT INTO profile_notes_fts (profile_notes_fts, rowid, content) VALUES ('delete', old.id, old.content);
+		INSERT INTO profile_notes_fts (rowid, content) VALUES (new.id, new.content);
+	END;
+
+	INSERT INTO profiles_fts (profiles_fts) VALUES ('rebuild');
+	INSERT INTO profile_notes_fts (profile_notes_fts) VALUES ('rebuild');
+`
+
+// initSearchIndex creates the full-text indexes once, indexing existing rows.
+// It runs after migrateSchema since it indexes migrated columns.
+func (s *Storage) initSearchIndex() error {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'profiles_fts'
+	`).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	_, err = s.db.Exec(searchIndexSchema)
+	return err
+}
+
+// AddNote attaches a note to a profile
+func (s *Storage) AddNote(profileID int64, content string) error {
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	_, err := s.db.Exec(`
+		INSERT INTO profile_notes (profile_id, content, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+	`, profileID, content, now, now)
+
+	return err
+}
+
+// GetNotes returns a profile's notes, oldest first
+func (s *Storage) GetNotes(profileID int64) ([]Note, error) {
+	rows, err := s.db.Query(`
+		SELECT id, profile_id, content, created_at, updated_at
+		FROM profile_notes WHERE profile_id = ?
+		ORDER BY id
+	`, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []Note{}
+	for rows.Next() {
+		var note Note
+		var createdAt, updatedAt string
+		if err := rows.Scan(&note.ID, &note.ProfileID, &note.Content, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		note.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+		note.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
+
+// UpdateNote replaces the content of one of a profile's notes
+func (s *Storage) UpdateNote(profileID, noteID int64, content string) error {
+	result, err := s.db.Exec(`
+		UPDATE profile_notes SET content = ?, updated_at = ? WHERE id = ? AND profile_id = ?
+	`, content, time.Now().UTC().Format("2006-01-02 15:04:05"), noteID, profileID)
+	if err != nil {
+		return err
+	}
+
+	return noteAffected(result.RowsAffected())
+}
+
+// DeleteNote removes one of a profile's notes
+func (s *Storage) DeleteNote(profileID, noteID int64) error {
+	result, err := s.db.Exec(`DELETE FROM profile_notes WHERE id = ? AND profile_id = ?`, noteID, profileID)
+	if err != nil {
+		return err
+	}
+
+	return noteAffected(result.RowsAffected())
+}
+
+// noteAffected turns an update of zero rows into ErrNoteNotFound
+func noteAffected(n int64, err error) error {
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoteNotFound
+	}
+	return nil
+}
+
+// SearchProfilesFTS returns profiles whose name, job title, company,
+// headline, summary or notes match every word of the query, best match
+// first. Note matches are weighted by notesRankWeight.
+func (s *Storage) SearchProfilesFTS(query string, limit int) ([]Profile, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return []Profile{}, nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// bm25 is negative with better matches lower, so scaling it towards 0
+	// ranks note matches lower
+	rows, err := s.db.Query(`
+		SELECT `+profileColumns+` FROM profiles
+		JOIN (
+			SELECT pid, MIN(match_rank) AS match_rank FROM (
+				SELECT rowid AS pid, bm25(profiles_fts) AS match_rank
+				FROM profiles_fts WHERE profiles_fts MATCH ?
+				UNION ALL
+				SELECT n.profile_id, bm25(profile_notes_fts) * ?
+				FROM profile_notes_fts JOIN profile_notes n ON n.id = profile_notes_fts.rowid
+				WHERE profile_notes_fts MATCH ?
+			) GROUP BY pid
+		) m ON m.pid = profiles.id
+		ORDER BY m.match_rank
+		LIMIT ?
+	`, match, notesRankWeight, match, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []Profile{}
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// ftsQuery quotes each word of a user query so FTS5 syntax characters are
+// matched literally
+func ftsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"`)
+	}
+	return strings.Join(terms, " ")
+}
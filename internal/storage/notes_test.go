@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNoteChangesAreScopedToTheProfile(t *testing.T) {
+	s := newTestStorage(t)
+
+	owner := saveTestProfile(t, s, "owner")
+	other := saveTestProfile(t, s, "other")
+
+	if err := s.AddNote(owner.ID, "met at the conference"); err != nil {
+		t.Fatalf("AddNote: %v", err)
+	}
+	notes, err := s.GetNotes(owner.ID)
+	if err != nil || len(notes) != 1 {
+		t.Fatalf("GetNotes = %v, %v, want one note", notes, err)
+	}
+	noteID := notes[0].ID
+
+	if err := s.UpdateNote(other.ID, noteID, "changed"); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("UpdateNote through another profile = %v, want ErrNoteNotFound", err)
+	}
+	if err := s.DeleteNote(other.ID, noteID); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("DeleteNote through another profile = %v, want ErrNoteNotFound", err)
+	}
+
+	if err := s.UpdateNote(owner.ID, noteID, "follow up in May"); err != nil {
+		t.Fatalf("UpdateNote: %v", err)
+	}
+	notes, err = s.GetNotes(owner.ID)
+	if err != nil || len(notes) != 1 || notes[0].Content != "follow up in May" {
+		t.Fatalf("GetNotes = %v, %v, want the updated note", notes, err)
+	}
+
+	if err := s.DeleteNote(owner.ID, noteID); err != nil {
+		t.Fatalf("DeleteNote: %v", err)
+	}
+	if notes, err := s.GetNotes(owner.ID); err != nil || len(notes) != 0 {
+		t.Errorf("GetNotes = %v, %v, want no notes", notes, err)
+	}
+}
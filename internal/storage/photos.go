@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ProfilePhoto is the downloaded profile picture of a profile
+type ProfilePhoto struct {
+	Path         string
+	Hash         string
+	DownloadedAt *time.Time
+}
+
+// GetProfilePhoto returns the stored photo of a profile; Path is empty when
+// none was downloaded
+func (s *Storage) GetProfilePhoto(profileID int64) (ProfilePhoto, error) {
+	var photo ProfilePhoto
+	var path, hash sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT photo_path, photo_hash, photo_downloaded_at FROM profiles WHERE id = ?
+	`, profileID).Scan(&path, &hash, &photo.DownloadedAt)
+	if err != nil {
+		return photo, err
+	}
+
+	photo.Path = path.String
+	photo.Hash = hash.String
+	return photo, nil
+}
+
+// SaveProfilePhoto records where a profile's photo was saved and its hash
+func (s *Storage) SaveProfilePhoto(profileID int64, path, hash string) error {
+	_, err := s.db.Exec(`
+		UPDATE profiles
+		SET photo_path = ?, photo_hash = ?, photo_downloaded_at = ?
+		WHERE id = ?
+	`, path, hash, time.Now().UTC().Format("2006-01-02 15:04:05"), profileID)
+
+	return err
+}
+
+// TouchProfilePhoto marks an unchanged photo as checked now
+func (s *Storage) TouchProfilePhoto(profileID int64) error {
+	_, err := s.db.Exec(`
+		UPDATE profiles SET photo_downloaded_at = ? WHERE id = ?
+	`, time.Now().UTC().Format("2006-01-02 15:04:05"), profileID)
+
+	return err
+}
@@ -0,0 +1,79 @@
+package storage
+
+import "time"
+
+// ProfilePost is a post scraped from a profile's activity feed
+type ProfilePost struct {
+	ID           int64
+	ProfileID    int64
+	URL          string
+	Content      string
+	PostedAt     *time.Time
+	LikeCount    int
+	CommentCount int
+	ScrapedAt    time.Time
+}
+
+// SaveProfilePosts stores scraped posts, refreshing the text and counts of
+// posts already seen
+func (s *Storage) SaveProfilePosts(profileID int64, posts []ProfilePost) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, post := range posts {
+		_, err := tx.Exec(`
+			INSERT INTO profile_posts (profile_id, post_url, content, posted_at, like_count, comment_count)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(profile_id, post_url) DO UPDATE SET
+				content = excluded.content,
+				posted_at = excluded.posted_at,
+				like_count = excluded.like_count,
+				comment_count = excluded.comment_count,
+				scraped_at = CURRENT_TIMESTAMP
+		`, profileID, post.URL, post.Content, post.PostedAt, post.LikeCount, post.CommentCount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetProfilePosts returns a profile's stored posts, newest first
+func (s *Storage) GetProfilePosts(profileID int64) ([]ProfilePost, error) {
+	rows, err := s.db.Query(`
+		SELECT id, profile_id, post_url, content, posted_at, like_count, comment_count, scraped_at
+		FROM profile_posts
+		WHERE profile_id = ?
+		ORDER BY posted_at DESC, id
+	`, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []ProfilePost
+	for rows.Next() {
+		var post ProfilePost
+		if err := rows.Scan(&post.ID, &post.ProfileID, &post.URL, &post.Content, &post.PostedAt,
+			&post.LikeCount, &post.CommentCount, &post.ScrapedAt); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// PostsScrapedSince reports whether a profile's posts were scraped after since
+func (s *Storage) PostsScrapedSince(profileID int64, since time.Time) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM profile_posts WHERE profile_id = ? AND scraped_at >= ?
+	`, profileID, since.UTC().Format("2006-01-02 15:04:05")).Scan(&count)
+
+	return count > 0, err
+}
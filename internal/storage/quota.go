@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// QuotaStatus describes monthly search result view usage
+type QuotaStatus struct {
+	Month   string
+	Used    int
+	Limit   int
+	Percent float64
+}
+
+// currentQuotaMonth returns the quota_tracking key for the current month
+func currentQuotaMonth() string {
+	return time.Now().Format("2006-01")
+}
+
+// RecordSearchViews adds search result page views to the current month's usage
+func (s *Storage) RecordSearchViews(views, limit int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO quota_tracking (month, views_used, views_limit)
+		VALUES (?, ?, ?)
+		ON CONFLICT (month) DO UPDATE SET
+			views_used = views_used + excluded.views_used,
+			views_limit = excluded.views_limit,
+			updated_at = CURRENT_TIMESTAMP
+	`, currentQuotaMonth(), views, limit)
+
+	return err
+}
+
+// GetSearchQuotaStatus returns search view usage for the current month
+func (s *Storage) GetSearchQuotaStatus() (*QuotaStatus, error) {
+	status := &QuotaStatus{Month: currentQuotaMonth()}
+
+	err := s.db.QueryRow(`
+		SELECT views_used, views_limit FROM quota_tracking WHERE month = ?
+	`, status.Month).Scan(&status.Used, &status.Limit)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	status.Percent = QuotaPercent(status.Used, status.Limit)
+	return status, nil
+}
+
+// QuotaPercent returns used as a percentage of limit, or 0 when there is no limit
+func QuotaPercent(used, limit int) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(used) / float64(limit) * 100
+}
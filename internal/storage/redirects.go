@@ -0,0 +1,28 @@
+package storage
+
+import "database/sql"
+
+// GetProfileRedirect returns the canonical URL previously resolved for a
+// discovered profile URL, or "" if it hasn't been resolved yet
+func (s *Storage) GetProfileRedirect(discoveredURL string) (string, error) {
+	var canonical string
+	err := s.db.QueryRow(`
+		SELECT canonical_url FROM profile_url_redirects WHERE discovered_url = ?
+	`, discoveredURL).Scan(&canonical)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return canonical, err
+}
+
+// SaveProfileRedirect records that a discovered profile URL redirects to
+// canonicalURL
+func (s *Storage) SaveProfileRedirect(discoveredURL, canonicalURL string) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO profile_url_redirects (discovered_url, canonical_url, resolved_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+	`, discoveredURL, canonicalURL)
+
+	return err
+}
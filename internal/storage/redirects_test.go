@@ -0,0 +1,34 @@
+package storage
+
+import "testing"
+
+func TestProfileRedirectStoresCanonicalURL(t *testing.T) {
+	s := newTestStorage(t)
+
+	discovered := "https://www.linkedin.com/in/old-name"
+	canonical := "https://www.linkedin.com/in/new-name"
+
+	if got, err := s.GetProfileRedirect(discovered); err != nil || got != "" {
+		t.Fatalf("GetProfileRedirect before resolving = %q, %v; want empty", got, err)
+	}
+
+	if err := s.SaveProfileRedirect(discovered, canonical); err != nil {
+		t.Fatalf("SaveProfileRedirect: %v", err)
+	}
+	if _, err := s.SaveProfile(&Profile{ProfileURL: canonical, DiscoveredURL: discovered, Name: "Jane"}); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	if got, err := s.GetProfileRedirect(discovered); err != nil || got != canonical {
+		t.Errorf("GetProfileRedirect = %q, %v; want %s", got, err, canonical)
+	}
+
+	var storedDiscovered, storedCanonical string
+	if err := s.db.QueryRow(`SELECT discovered_url, canonical_url FROM profiles WHERE profile_url = ?`, canonical).
+		Scan(&storedDiscovered, &storedCanonical); err != nil {
+		t.Fatalf("read profile: %v", err)
+	}
+	if storedDiscovered != discovered || storedCanonical != canonical {
+		t.Errorf("stored discovered/canonical = %s, %s; want %s, %s", storedDiscovered, storedCanonical, discovered, canonical)
+	}
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+// profileNames returns the names of profiles in order
+func profileNames(profiles []Profile) []string {
+	names := make([]string, len(profiles))
+	for i, profile := range profiles {
+		names[i] = profile.Name
+	}
+	return names
+}
+
+func TestRelationshipGraphWithCycles(t *testing.T) {
+	s := newTestStorage(t)
+
+	ids := make(map[string]int64)
+	for _, name := range []string{"a", "b", "c", "d", "e", "lonely"} {
+		ids[name] = saveTestProfile(t, s, name).ID
+	}
+
+	// a -> b -> c -> a is a cycle, c -> d hangs off it and d <-> e point at
+	// each other
+	edges := []struct {
+		source, target, relType string
+	}{
+		{"a", "b", "same_search_target"},
+		{"b", "c", "same_search_target"},
+		{"c", "a", "same_search_target"},
+		{"c", "d", "same_company"},
+		{"d", "e", "referred_by"},
+		{"e", "d", "referred_by"},
+		{"a", "b", "same_search_target"}, // duplicate, ignored
+		{"a", "a", "same_company"},       // self loop, ignored
+	}
+	for _, edge := range edges {
+		if err := s.AddRelationship(ids[edge.source], ids[edge.target], edge.relType, ""); err != nil {
+			t.Fatalf("AddRelationship(%s, %s): %v", edge.source, edge.target, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		start   string
+		relType string
+		want    []string
+	}{
+		{"every type from a", "a", "", []string{"b", "c", "d", "e"}},
+		{"every type from e", "e", "", []string{"d", "c", "a", "b"}},
+		{"search cycle only", "b", "same_search_target", []string{"a", "c"}},
+		{"two-node cycle", "d", "referred_by", []string{"e"}},
+		{"no relationships", "lonely", "", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, err := s.GetConnectedProfiles(ids[tt.start], tt.relType)
+			if err != nil {
+				t.Fatalf("GetConnectedProfiles: %v", err)
+			}
+			if got := profileNames(profiles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("visited %v, want each of %v once", got, tt.want)
+			}
+		})
+	}
+
+	related, err := s.GetRelatedProfiles(ids["c"], "")
+	if err != nil {
+		t.Fatalf("GetRelatedProfiles: %v", err)
+	}
+	if got := profileNames(related); !reflect.DeepEqual(got, []string{"a", "b", "d"}) {
+		t.Errorf("profiles related to c = %v, want a, b and d in both directions", got)
+	}
+
+	nodes, graphEdges, err := s.GetRelationshipGraph()
+	if err != nil {
+		t.Fatalf("GetRelationshipGraph: %v", err)
+	}
+	if len(nodes) != 5 || len(graphEdges) != 6 {
+		t.Errorf("graph has %d nodes and %d edges, want 5 and 6", len(nodes), len(graphEdges))
+	}
+}
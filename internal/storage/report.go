@@ -0,0 +1,304 @@
+package storage
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TemplateStat summarizes how one note or message template performed
+type TemplateStat struct {
+	TemplateID int
+	Sent       int
+	Successes  int
+	Rate       float64
+}
+
+// NoteRuleStats summarizes the requests sent with a template chosen by one
+// template preference rule
+type NoteRuleStats struct {
+	RuleID   int
+	Sent     int
+	Accepted int
+	Rate     float64
+}
+
+// WeeklyReport summarizes outreach results over a date range
+type WeeklyReport struct {
+	From time.Time
+	To   time.Time
+
+	ConnectionsSent     int
+	ConnectionsAccepted int
+	ConnectionsRejected int
+	AcceptanceRate      float64
+
+	// ProfilesFollowed counts profiles followed because Connect was unavailable;
+	// they are not included in ConnectionsSent
+	ProfilesFollowed int
+
+	MessagesSent    int
+	MessagesReplied int
+	ReplyRate       float64
+
+	// TopNoteTemplate and TopMessageTemplate are nil when no template was used
+	TopNoteTemplate    *TemplateStat
+	TopMessageTemplate *TemplateStat
+
+	AvgAcceptLatency time.Duration
+	AvgReplyLatency  time.Duration
+
+	// AvgConversionScore is the average score of profiles messaged in the
+	// period, from score_history
+	AvgConversionScore float64
+
+	StatusDistribution map[string]int
+}
+
+// GenerateWeeklyReport computes outreach statistics for activity sent between
+// from and to (inclusive, by date)
+func (s *Storage) GenerateWeeklyReport(from, to time.Time) (*WeeklyReport, error) {
+	report := &WeeklyReport{
+		From:               from,
+		To:                 to,
+		StatusDistribution: make(map[string]int),
+	}
+	fromDate := from.Format("2006-01-02")
+	toDate := to.Format("2006-01-02")
+
+	rows, err := s.db.Query(`
+		SELECT status, COUNT(*) FROM connection_requests
+		WHERE DATE(sent_at) BETWEEN ? AND ?
+		GROUP BY status
+	`, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count connection requests: %w", err)
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.StatusDistribution[status] = count
+		if status == string(StateFollowed) {
+			report.ProfilesFollowed = count
+			continue
+		}
+		if status == StatusEmailRequiredSkipped {
+			continue
+		}
+		report.ConnectionsSent += count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.ConnectionsAccepted = report.StatusDistribution["accepted"]
+	report.ConnectionsRejected = report.StatusDistribution["rejected"]
+	report.AcceptanceRate = percentage(report.ConnectionsAccepted, report.ConnectionsSent)
+
+	err = s.db.QueryRow(`
+		SELECT COUNT(*), COUNT(replied_at) FROM messages
+		WHERE DATE(sent_at) BETWEEN ? AND ? AND status = 'sent'
+	`, fromDate, toDate).Scan(&report.MessagesSent, &report.MessagesReplied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count messages: %w", err)
+	}
+	report.ReplyRate = percentage(report.MessagesReplied, report.MessagesSent)
+
+	report.AvgAcceptLatency, err = s.averageLatency(`
+		SELECT AVG((julianday(accepted_at) - julianday(sent_at)) * 86400)
+		FROM connection_requests
+		WHERE DATE(sent_at) BETWEEN ? AND ? AND accepted_at IS NOT NULL
+	`, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute accept latency: %w", err)
+	}
+
+	report.AvgReplyLatency, err = s.averageLatency(`
+		SELECT AVG((julianday(replied_at) - julianday(sent_at)) * 86400)
+		FROM messages
+		WHERE DATE(sent_at) BETWEEN ? AND ? AND replied_at IS NOT NULL
+	`, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute reply latency: %w", err)
+	}
+
+	report.TopNoteTemplate, err = s.topTemplate(`
+		SELECT template_id, COUNT(*), SUM(CASE WHEN status = 'accepted' THEN 1 ELSE 0 END)
+		FROM connection_requests
+		WHERE DATE(sent_at) BETWEEN ? AND ? AND template_id > 0
+		GROUP BY template_id
+	`, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank note templates: %w", err)
+	}
+
+	report.TopMessageTemplate, err = s.topTemplate(`
+		SELECT template_id, COUNT(*), COUNT(replied_at)
+		FROM messages
+		WHERE DATE(sent_at) BETWEEN ? AND ? AND status = 'sent' AND template_id > 0
+		GROUP BY template_id
+	`, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank message templates: %w", err)
+	}
+
+	report.AvgConversionScore, err = s.GetAverageConversionScore(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute conversion score: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetNoteRuleStats returns the acceptance rate of connection requests per
+// template preference rule, ordered by rule ID
+func (s *Storage) GetNoteRuleStats() ([]NoteRuleStats, error) {
+	rows, err := s.db.Query(`
+		SELECT note_rule_id, COUNT(*), SUM(CASE WHEN status = 'accepted' THEN 1 ELSE 0 END)
+		FROM connection_requests
+		WHERE note_rule_id > 0
+		GROUP BY note_rule_id
+		ORDER BY note_rule_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []NoteRuleStats
+	for rows.Next() {
+		var stat NoteRuleStats
+		if err := rows.Scan(&stat.RuleID, &stat.Sent, &stat.Accepted); err != nil {
+			return nil, err
+		}
+		stat.Rate = percentage(stat.Accepted, stat.Sent)
+		stats = append(stats, stat)
+	}
+
+	return stats, rows.Err()
+}
+
+// averageLatency runs a query returning an average number of seconds
+func (s *Storage) averageLatency(query string, args ...any) (time.Duration, error) {
+	var seconds *float64
+	if err := s.db.QueryRow(query, args...).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	if seconds == nil {
+		return 0, nil
+	}
+	return time.Duration(*seconds * float64(time.Second)), nil
+}
+
+// topTemplate runs a query returning (template_id, sent, successes) rows and
+// picks the template with the best success rate
+func (s *Storage) topTemplate(query string, args ...any) (*TemplateStat, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var best *TemplateStat
+	for rows.Next() {
+		var stat TemplateStat
+		if err := rows.Scan(&stat.TemplateID, &stat.Sent, &stat.Successes); err != nil {
+			return nil, err
+		}
+		stat.Rate = percentage(stat.Successes, stat.Sent)
+
+		if best == nil || stat.Rate > best.Rate || (stat.Rate == best.Rate && stat.Sent > best.Sent) {
+			best = &stat
+		}
+	}
+
+	return best, rows.Err()
+}
+
+// percentage returns part/total as a percentage, or 0 when total is 0
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// reportLines returns the label/value pairs shared by the report formatters
+func reportLines(r *WeeklyReport) [][2]string {
+	lines := [][2]string{
+		{"Connections sent", fmt.Sprintf("%d", r.ConnectionsSent)},
+		{"Connections accepted", fmt.Sprintf("%d", r.ConnectionsAccepted)},
+		{"Connections rejected", fmt.Sprintf("%d", r.ConnectionsRejected)},
+		{"Acceptance rate", fmt.Sprintf("%.1f%%", r.AcceptanceRate)},
+		{"Profiles followed", fmt.Sprintf("%d", r.ProfilesFollowed)},
+		{"Messages sent", fmt.Sprintf("%d", r.MessagesSent)},
+		{"Messages replied", fmt.Sprintf("%d", r.MessagesReplied)},
+		{"Reply rate", fmt.Sprintf("%.1f%%", r.ReplyRate)},
+		{"Top note template", formatTemplateStat(r.TopNoteTemplate)},
+		{"Top message template", formatTemplateStat(r.TopMessageTemplate)},
+		{"Avg. time to accept", formatLatency(r.AvgAcceptLatency)},
+		{"Avg. time to reply", formatLatency(r.AvgReplyLatency)},
+		{"Avg. conversion score", fmt.Sprintf("%.2f", r.AvgConversionScore)},
+	}
+
+	statuses := make([]string, 0, len(r.StatusDistribution))
+	for status := range r.StatusDistribution {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		lines = append(lines, [2]string{"Status: " + status, fmt.Sprintf("%d", r.StatusDistribution[status])})
+	}
+
+	return lines
+}
+
+// FormatWeeklyReportText renders a weekly report as plain text
+func FormatWeeklyReportText(r *WeeklyReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Weekly report %s - %s\n\n", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+	for _, line := range reportLines(r) {
+		fmt.Fprintf(&b, "%-24s %s\n", line[0]+":", line[1])
+	}
+
+	return b.String()
+}
+
+// FormatWeeklyReportHTML renders a weekly report as an HTML document
+func FormatWeeklyReportHTML(r *WeeklyReport) string {
+	var b strings.Builder
+
+	title := fmt.Sprintf("Weekly report %s - %s", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+	fmt.Fprintf(&b, "<html><head><title>%s</title></head><body>\n", title)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<table>\n", title)
+	for _, line := range reportLines(r) {
+		fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>\n", html.EscapeString(line[0]), html.EscapeString(line[1]))
+	}
+	b.WriteString("</table>\n</body></html>\n")
+
+	return b.String()
+}
+
+// formatTemplateStat describes a template's performance
+func formatTemplateStat(stat *TemplateStat) string {
+	if stat == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("#%d (%d/%d, %.1f%%)", stat.TemplateID, stat.Successes, stat.Sent, stat.Rate)
+}
+
+// formatLatency renders a latency rounded to the minute
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return "n/a"
+	}
+	return d.Round(time.Minute).String()
+}
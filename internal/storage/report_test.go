@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateWeeklyReportRates(t *testing.T) {
+	s := newTestStorage(t)
+
+	// Yesterday at noon UTC keeps every row well inside the range
+	base := time.Now().UTC().Truncate(24 * time.Hour).Add(-12 * time.Hour)
+	from, to := base.AddDate(0, 0, -6), base.AddDate(0, 0, 1)
+
+	requests := []struct {
+		status     string
+		templateID int
+		acceptedIn time.Duration
+		sentAt     time.Time
+	}{
+		{"accepted", 1, 2 * time.Hour, base},
+		{"accepted", 1, 4 * time.Hour, base},
+		{"rejected", 1, 0, base},
+		{"accepted", 2, 6 * time.Hour, base},
+		{"pending", 2, 0, base},
+		{"pending", 2, 0, base},
+		{string(StateFollowed), 0, 0, base},
+		{StatusEmailRequiredSkipped, 0, 0, base},
+		{"accepted", 2, time.Hour, base.AddDate(0, 0, -30)},
+	}
+	for i, req := range requests {
+		profile := saveTestProfile(t, s, fmt.Sprintf("request-%d", i))
+		saveTestConnection(t, s, profile, req.sentAt, req.status)
+
+		var acceptedAt interface{}
+		if req.acceptedIn > 0 {
+			acceptedAt = req.sentAt.Add(req.acceptedIn).Format("2006-01-02 15:04:05")
+		}
+		if _, err := s.db.Exec(`UPDATE connection_requests SET template_id = ?, accepted_at = ? WHERE profile_url = ?`,
+			req.templateID, acceptedAt, profile.ProfileURL); err != nil {
+			t.Fatalf("update request: %v", err)
+		}
+	}
+
+	messages := []struct {
+		status     string
+		templateID int
+		replied    bool
+	}{
+		{"sent", 1, true},
+		{"sent", 1, false},
+		{"sent", 2, true},
+		{"sent", 2, true},
+		{"failed", 1, false},
+	}
+	for i, msg := range messages {
+		profile := saveTestProfile(t, s, fmt.Sprintf("message-%d", i))
+		if err := s.SaveMessage(&Message{ProfileID: profile.ID, ProfileURL: profile.ProfileURL, Status: msg.status, TemplateID: msg.templateID}); err != nil {
+			t.Fatalf("SaveMessage: %v", err)
+		}
+
+		var repliedAt interface{}
+		if msg.replied {
+			repliedAt = base.Add(time.Hour).Format("2006-01-02 15:04:05")
+		}
+		if _, err := s.db.Exec(`UPDATE messages SET sent_at = ?, replied_at = ? WHERE profile_url = ?`,
+			base.Format("2006-01-02 15:04:05"), repliedAt, profile.ProfileURL); err != nil {
+			t.Fatalf("update message: %v", err)
+		}
+	}
+
+	r, err := s.GenerateWeeklyReport(from, to)
+	if err != nil {
+		t.Fatalf("GenerateWeeklyReport: %v", err)
+	}
+
+	// Followed and email-required requests are not sent requests
+	if r.ConnectionsSent != 6 || r.ConnectionsAccepted != 3 || r.ConnectionsRejected != 1 || r.ProfilesFollowed != 1 {
+		t.Errorf("sent/accepted/rejected/followed = %d/%d/%d/%d, want 6/3/1/1",
+			r.ConnectionsSent, r.ConnectionsAccepted, r.ConnectionsRejected, r.ProfilesFollowed)
+	}
+	assertRate(t, "acceptance rate", r.AcceptanceRate, 50)
+
+	if r.MessagesSent != 4 || r.MessagesReplied != 3 {
+		t.Errorf("messages sent/replied = %d/%d, want 4/3", r.MessagesSent, r.MessagesReplied)
+	}
+	assertRate(t, "reply rate", r.ReplyRate, 75)
+
+	if r.TopNoteTemplate == nil || r.TopNoteTemplate.TemplateID != 1 {
+		t.Fatalf("top note template = %+v, want #1", r.TopNoteTemplate)
+	}
+	assertRate(t, "top note template rate", r.TopNoteTemplate.Rate, 200.0/3)
+	if r.TopMessageTemplate == nil || r.TopMessageTemplate.TemplateID != 2 {
+		t.Fatalf("top message template = %+v, want #2", r.TopMessageTemplate)
+	}
+	assertRate(t, "top message template rate", r.TopMessageTemplate.Rate, 100)
+
+	if r.AvgAcceptLatency.Round(time.Second) != 4*time.Hour {
+		t.Errorf("average accept latency = %v, want 4h", r.AvgAcceptLatency)
+	}
+	if r.AvgReplyLatency.Round(time.Second) != time.Hour {
+		t.Errorf("average reply latency = %v, want 1h", r.AvgReplyLatency)
+	}
+
+	text := FormatWeeklyReportText(r)
+	for _, want := range []string{"Acceptance rate:         50.0%", "Reply rate:              75.0%", "#1 (2/3, 66.7%)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text report missing %q:\n%s", want, text)
+		}
+	}
+	if html := FormatWeeklyReportHTML(r); !strings.Contains(html, "<td>75.0%</td>") {
+		t.Errorf("HTML report missing the reply rate:\n%s", html)
+	}
+}
+
+func TestGenerateWeeklyReportEmpty(t *testing.T) {
+	s := newTestStorage(t)
+
+	r, err := s.GenerateWeeklyReport(time.Now().AddDate(0, 0, -7), time.Now())
+	if err != nil {
+		t.Fatalf("GenerateWeeklyReport: %v", err)
+	}
+	if r.AcceptanceRate != 0 || r.ReplyRate != 0 || r.TopNoteTemplate != nil || r.AvgAcceptLatency != 0 {
+		t.Errorf("empty report = %+v, want zero rates and no templates", r)
+	}
+	if text := FormatWeeklyReportText(r); !strings.Contains(text, "Top note template:       n/a") {
+		t.Errorf("empty text report:\n%s", text)
+	}
+}
+
+func assertRate(t *testing.T, name string, got, want float64) {
+	t.Helper()
+
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %.4f, want %.4f", name, got, want)
+	}
+}
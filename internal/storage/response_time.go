@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"math"
+	"sort"
+)
+
+// responseMaturityWindow excludes requests sent within the last week from
+// hourly acceptance rates, since many of them may still be accepted
+const responseMaturityWindow = "-7 days"
+
+// ResponseTimeStats summarizes how many hours accepted connection requests
+// took to be accepted
+type ResponseTimeStats struct {
+	Count  int
+	Mean   float64
+	Median float64
+	P90    float64
+	P99    float64
+}
+
+// HourlyResponseRate is the acceptance rate of the requests sent during one
+// hour of the day (local time)
+type HourlyResponseRate struct {
+	Hour           int
+	Sent           int
+	Accepted       int
+	AcceptanceRate float64
+}
+
+// GetAcceptanceResponseTimeStats returns the distribution of response times
+// across accepted connection requests. All fields are zero without any.
+func (s *Storage) GetAcceptanceResponseTimeStats() (*ResponseTimeStats, error) {
+	rows, err := s.db.Query(`
+		SELECT response_time_hours FROM connection_requests
+		WHERE response_time_hours IS NOT NULL AND response_time_hours >= 0
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hours []float64
+	for rows.Next() {
+		var h float64
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := &ResponseTimeStats{Count: len(hours)}
+	if len(hours) == 0 {
+		return stats, nil
+	}
+
+	sort.Float64s(hours)
+
+	sum := 0.0
+	for _, h := range hours {
+		sum += h
+	}
+	stats.Mean = sum / float64(len(hours))
+	stats.Median = percentile(hours, 50)
+	stats.P90 = percentile(hours, 90)
+	stats.P99 = percentile(hours, 99)
+
+	return stats, nil
+}
+
+// GetAcceptanceResponseTimeByHourOfDay returns the acceptance rate of
+// connection requests grouped by the local hour they were sent, for every
+// hour with at least one request older than a week
+func (s *Storage) GetAcceptanceResponseTimeByHourOfDay() ([]HourlyResponseRate, error) {
+	rows, err := s.db.Query(`
+		SELECT CAST(strftime('%H', sent_at, 'localtime') AS INTEGER) AS hour,
+			COUNT(*),
+			SUM(CASE WHEN accepted_at IS NOT NULL THEN 1 ELSE 0 END)
+		FROM connection_requests
+		WHERE `+invitationSentFilter+` AND sent_at <= datetime('now', ?)
+		GROUP BY hour
+		ORDER BY hour
+	`, responseMaturityWindow)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []HourlyResponseRate
+	for rows.Next() {
+		var rate HourlyResponseRate
+		if err := rows.Scan(&rate.Hour, &rate.Sent, &rate.Accepted); err != nil {
+			return nil, err
+		}
+		rate.AcceptanceRate = percentage(rate.Accepted, rate.Sent)
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// percentile interpolates the p-th percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	frac := rank - float64(lower)
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAcceptanceResponseTimeStats(t *testing.T) {
+	s := newTestStorage(t)
+
+	if stats, err := s.GetAcceptanceResponseTimeStats(); err != nil || *stats != (ResponseTimeStats{}) {
+		t.Fatalf("stats without acceptances = %+v, %v, want all zero", stats, err)
+	}
+
+	// Accepted after 1 to 10 hours, plus requests still pending
+	now := time.Now()
+	for h := 1; h <= 10; h++ {
+		profile := saveTestProfile(t, s, fmt.Sprintf("accepted-%d", h))
+		saveTestConnection(t, s, profile, now.Add(-time.Duration(h)*time.Hour), "pending")
+		if err := s.UpdateConnectionStatus(profile.ProfileURL, "accepted"); err != nil {
+			t.Fatalf("UpdateConnectionStatus: %v", err)
+		}
+	}
+	saveTestConnection(t, s, saveTestProfile(t, s, "pending"), now.Add(-48*time.Hour), "pending")
+
+	stats, err := s.GetAcceptanceResponseTimeStats()
+	if err != nil {
+		t.Fatalf("GetAcceptanceResponseTimeStats: %v", err)
+	}
+	if stats.Count != 10 {
+		t.Errorf("Count = %d, want 10", stats.Count)
+	}
+	for name, got := range map[string][2]float64{
+		"Mean":   {stats.Mean, 5.5},
+		"Median": {stats.Median, 5.5},
+		"P90":    {stats.P90, 9.1},
+		"P99":    {stats.P99, 9.91},
+	} {
+		// sent_at is stored to the second
+		if math.Abs(got[0]-got[1]) > 0.01 {
+			t.Errorf("%s = %.3f hours, want %.2f", name, got[0], got[1])
+		}
+	}
+}
+
+func TestAcceptanceResponseTimeByHourOfDay(t *testing.T) {
+	s := newTestStorage(t)
+
+	old := time.Now().AddDate(0, 0, -10)
+	at := func(hour int) time.Time {
+		return time.Date(old.Year(), old.Month(), old.Day(), hour, 15, 0, 0, time.Local)
+	}
+
+	// 9am: 3 of 4 accepted, 2pm: 1 of 2 accepted
+	requests := []struct {
+		hour     int
+		accepted bool
+	}{
+		{9, true}, {9, true}, {9, true}, {9, false},
+		{14, true}, {14, false},
+	}
+	for i, r := range requests {
+		profile := saveTestProfile(t, s, fmt.Sprintf("user-%d", i))
+		saveTestConnection(t, s, profile, at(r.hour), "pending")
+		if r.accepted {
+			if _, err := s.db.Exec(`UPDATE connection_requests SET status = 'accepted', accepted_at = ? WHERE profile_url = ?`,
+				at(r.hour).Add(time.Hour).UTC().Format("2006-01-02 15:04:05"), profile.ProfileURL); err != nil {
+				t.Fatalf("set accepted_at: %v", err)
+			}
+		}
+	}
+
+	// Too recent to count, and a follow that sent no invitation
+	saveTestConnection(t, s, saveTestProfile(t, s, "recent"), time.Now().Add(-time.Hour), "pending")
+	saveTestConnection(t, s, saveTestProfile(t, s, "followed"), at(9), string(StateFollowed))
+
+	rates, err := s.GetAcceptanceResponseTimeByHourOfDay()
+	if err != nil {
+		t.Fatalf("GetAcceptanceResponseTimeByHourOfDay: %v", err)
+	}
+	want := []HourlyResponseRate{
+		{Hour: 9, Sent: 4, Accepted: 3, AcceptanceRate: 75},
+		{Hour: 14, Sent: 2, Accepted: 1, AcceptanceRate: 50},
+	}
+	if !reflect.DeepEqual(rates, want) {
+		t.Errorf("rates = %+v, want %+v", rates, want)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{2, 4, 6, 8}
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 2},
+		{50, 5},
+		{100, 8},
+		{90, 7.4},
+	}
+	for _, tt := range tests {
+		if got := percentile(values, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile([]float64{3}, 99); got != 3 {
+		t.Errorf("percentile of one value = %v, want 3", got)
+	}
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// MaxRetryAttempts is the number of retries allowed per profile and action
+const MaxRetryAttempts = 3
+
+// ErrRetriesExhausted is returned by EnqueueRetry once all attempts are used up
+var ErrRetriesExhausted = errors.New("maximum retry attempts reached")
+
+// retryBackoffs holds the delay before each retry attempt
+var retryBackoffs = []time.Duration{
+	1 * time.Hour,
+	4 * time.Hour,
+	24 * time.Hour,
+}
+
+// RetryBackoff returns the delay before the given retry attempt (1-based)
+func RetryBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > len(retryBackoffs) {
+		attempt = len(retryBackoffs)
+	}
+	return retryBackoffs[attempt-1]
+}
+
+// EnqueueRetry schedules another attempt of an action for a profile using
+// exponential backoff. Once MaxRetryAttempts is reached the entry is kept for
+// reference but no longer scheduled, and ErrRetriesExhausted is returned.
+func (s *Storage) EnqueueRetry(profileID int64, action string, cause error) error {
+	lastError := ""
+	if cause != nil {
+		lastError = cause.Error()
+	}
+
+	var attempts int
+	err := s.db.QueryRow(`
+		SELECT attempt_count FROM retry_queue WHERE profile_id = ? AND action_type = ?
+	`, profileID, action).Scan(&attempts)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if attempts >= MaxRetryAttempts {
+		_, err := s.db.Exec(`
+			UPDATE retry_queue SET next_retry_at = NULL, last_error = ?
+			WHERE profile_id = ? AND action_type = ?
+		`, lastError, profileID, action)
+		if err != nil {
+			return err
+		}
+		return ErrRetriesExhausted
+	}
+
+	attempts++
+	nextRetry := time.Now().Add(RetryBackoff(attempts)).UTC().Format("2006-01-02 15:04:05")
+
+	_, err = s.db.Exec(`
+		INSERT INTO retry_queue (profile_id, action_type, attempt_count, next_retry_at, last_error)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (profile_id, action_type) DO UPDATE SET
+			attempt_count = excluded.attempt_count,
+			next_retry_at = excluded.next_retry_at,
+			last_error = excluded.last_error
+	`, profileID, action, attempts, nextRetry, lastError)
+
+	return err
+}
+
+// GetDueRetries returns queued retries for an action whose retry time has passed
+func (s *Storage) GetDueRetries(action string) ([]ConnectionRequest, error) {
+	rows, err := s.db.Query(`
+		SELECT rq.profile_id, p.profile_url
+		FROM retry_queue rq
+		JOIN profiles p ON p.id = rq.profile_id
+		WHERE rq.action_type = ? AND rq.next_retry_at IS NOT NULL AND rq.next_retry_at <= ?
+		ORDER BY rq.next_retry_at
+	`, action, time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var retries []ConnectionRequest
+	for rows.Next() {
+		var req ConnectionRequest
+		if err := rows.Scan(&req.ProfileID, &req.ProfileURL); err != nil {
+			return nil, err
+		}
+		retries = append(retries, req)
+	}
+
+	return retries, rows.Err()
+}
+
+// RemoveRetry deletes a retry entry after the action succeeds
+func (s *Storage) RemoveRetry(profileID int64, action string) error {
+	_, err := s.db.Exec(`
+		DELETE FROM retry_queue WHERE profile_id = ? AND action_type = ?
+	`, profileID, action)
+
+	return err
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Hour},
+		{1, time.Hour},
+		{2, 4 * time.Hour},
+		{3, 24 * time.Hour},
+		{4, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := RetryBackoff(tt.attempt); got != tt.want {
+			t.Errorf("RetryBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestEnqueueRetryStopsAfterMaxAttempts(t *testing.T) {
+	s := newTestStorage(t)
+	profile := saveTestProfile(t, s, "jane")
+	cause := errors.New("timeout")
+
+	for i := 1; i <= MaxRetryAttempts; i++ {
+		if err := s.EnqueueRetry(profile.ID, "connect", cause); err != nil {
+			t.Fatalf("EnqueueRetry attempt %d: %v", i, err)
+		}
+	}
+
+	var attempts int
+	if err := s.db.QueryRow(`SELECT attempt_count FROM retry_queue WHERE profile_id = ?`, profile.ID).Scan(&attempts); err != nil {
+		t.Fatalf("read attempt_count: %v", err)
+	}
+	if attempts != MaxRetryAttempts {
+		t.Errorf("attempt_count = %d, want %d", attempts, MaxRetryAttempts)
+	}
+
+	if err := s.EnqueueRetry(profile.ID, "connect", cause); !errors.Is(err, ErrRetriesExhausted) {
+		t.Fatalf("EnqueueRetry after %d attempts = %v, want ErrRetriesExhausted", MaxRetryAttempts, err)
+	}
+
+	// An exhausted entry is never due again
+	if _, err := s.db.Exec(`UPDATE retry_queue SET next_retry_at = ? WHERE profile_id = ? AND next_retry_at IS NOT NULL`,
+		time.Now().Add(-time.Hour).UTC().Format("2006-01-02 15:04:05"), profile.ID); err != nil {
+		t.Fatalf("backdate retry: %v", err)
+	}
+	due, err := s.GetDueRetries("connect")
+	if err != nil {
+		t.Fatalf("GetDueRetries: %v", err)
+	}
+	if len(due) != 0 {
+		t.Errorf("GetDueRetries returned %d exhausted retries, want 0", len(due))
+	}
+}
+
+func TestGetDueRetries(t *testing.T) {
+	s := newTestStorage(t)
+	due := saveTestProfile(t, s, "due")
+	later := saveTestProfile(t, s, "later")
+	other := saveTestProfile(t, s, "other-action")
+
+	for _, p := range []*Profile{due, later} {
+		if err := s.EnqueueRetry(p.ID, "connect", nil); err != nil {
+			t.Fatalf("EnqueueRetry: %v", err)
+		}
+	}
+	if err := s.EnqueueRetry(other.ID, "message", nil); err != nil {
+		t.Fatalf("EnqueueRetry: %v", err)
+	}
+
+	// Only the first profile's backoff has elapsed
+	if _, err := s.db.Exec(`UPDATE retry_queue SET next_retry_at = ? WHERE profile_id IN (?, ?)`,
+		time.Now().Add(-time.Minute).UTC().Format("2006-01-02 15:04:05"), due.ID, other.ID); err != nil {
+		t.Fatalf("backdate retry: %v", err)
+	}
+
+	got, err := s.GetDueRetries("connect")
+	if err != nil {
+		t.Fatalf("GetDueRetries: %v", err)
+	}
+	if len(got) != 1 || got[0].ProfileURL != due.ProfileURL {
+		t.Fatalf("GetDueRetries = %+v, want only %s", got, due.ProfileURL)
+	}
+
+	// A new attempt is scheduled an hour out in UTC
+	var nextRetry string
+	if err := s.db.QueryRow(`SELECT CAST(next_retry_at AS TEXT) FROM retry_queue WHERE profile_id = ?`, later.ID).Scan(&nextRetry); err != nil {
+		t.Fatalf("read next_retry_at: %v", err)
+	}
+	at, err := time.Parse("2006-01-02 15:04:05", nextRetry)
+	if err != nil {
+		t.Fatalf("next_retry_at %q is not a UTC timestamp: %v", nextRetry, err)
+	}
+	if d := time.Until(at); d < 59*time.Minute || d > 61*time.Minute {
+		t.Errorf("next_retry_at is %v away, want about 1h", d)
+	}
+
+	if err := s.RemoveRetry(due.ID, "connect"); err != nil {
+		t.Fatalf("RemoveRetry: %v", err)
+	}
+	if got, _ := s.GetDueRetries("connect"); len(got) != 0 {
+		t.Errorf("GetDueRetries after RemoveRetry = %+v, want none", got)
+	}
+}
@@ -0,0 +1,27 @@
+package storage
+
+import "database/sql"
+
+// GetSchoolURN returns the cached LinkedIn ID for a school name, or "" if it
+// hasn't been resolved yet
+func (s *Storage) GetSchoolURN(schoolName string) (string, error) {
+	var urn string
+	err := s.db.QueryRow(`
+		SELECT urn FROM school_urns WHERE school_name = ? COLLATE NOCASE
+	`, schoolName).Scan(&urn)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return urn, err
+}
+
+// SaveSchoolURN caches the LinkedIn ID for a school name
+func (s *Storage) SaveSchoolURN(schoolName, urn string) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO school_urns (school_name, urn, resolved_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+	`, schoolName, urn)
+
+	return err
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScoreHistoryEntry is a profile score archived when the profile was messaged
+type ScoreHistoryEntry struct {
+	ProfileID  int64     `json:"profile_id"`
+	Score      float64   `json:"score"`
+	BaseScore  float64   `json:"base_score"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// ArchiveProfileScore copies a profile's score to score_history and resets
+// it to 0, so converted profiles no longer compete for prioritization but
+// their quality is kept for reporting
+func (s *Storage) ArchiveProfileScore(profileID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var profileURL string
+	if err := tx.QueryRow(`SELECT profile_url FROM profiles WHERE id = ?`, profileID).Scan(&profileURL); err != nil {
+		return fmt.Errorf("failed to load profile %d: %w", profileID, err)
+	}
+
+	if err := archiveScore(tx, `id = ?`, profileID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.invalidateProfile(profileURL)
+
+	return nil
+}
+
+// archiveScore archives and resets the score of the profile matching where,
+// inside the caller's transaction
+func archiveScore(exec execQueryer, where string, arg any) error {
+	_, err := exec.Exec(`
+		INSERT INTO score_history (profile_id, score, base_score, archived_at)
+		SELECT id, score, base_score, ? FROM profiles WHERE `+where,
+		time.Now().UTC().Format("2006-01-02 15:04:05"), arg)
+	if err != nil {
+		return fmt.Errorf("failed to archive score: %w", err)
+	}
+
+	if _, err := exec.Exec(`UPDATE profiles SET score = 0 WHERE `+where, arg); err != nil {
+		return fmt.Errorf("failed to reset score: %w", err)
+	}
+
+	return nil
+}
+
+// GetScoreHistory returns the archived scores of a profile, oldest first
+func (s *Storage) GetScoreHistory(profileID int64) ([]ScoreHistoryEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT profile_id, score, base_score, archived_at FROM score_history
+		WHERE profile_id = ?
+		ORDER BY archived_at, id
+	`, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ScoreHistoryEntry
+	for rows.Next() {
+		var entry ScoreHistoryEntry
+		if err := rows.Scan(&entry.ProfileID, &entry.Score, &entry.BaseScore, &entry.ArchivedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetAverageConversionScore returns the average score of profiles archived
+// between from and to (inclusive, by date in their time zone), 0 when there
+// were none. archived_at is UTC, so the day bounds are converted to UTC.
+func (s *Storage) GetAverageConversionScore(from, to time.Time) (float64, error) {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, to.Location())
+
+	var avg *float64
+	err := s.db.QueryRow(`
+		SELECT AVG(score) FROM score_history
+		WHERE archived_at >= ? AND archived_at < ?
+	`, start.UTC().Format("2006-01-02 15:04:05"), end.UTC().Format("2006-01-02 15:04:05")).Scan(&avg)
+	if err != nil || avg == nil {
+		return 0, err
+	}
+
+	return *avg, nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMessagedTransitionArchivesScore(t *testing.T) {
+	s := newTestStorage(t)
+
+	p := &Profile{ProfileURL: "https://www.linkedin.com/in/jane", Name: "Jane", Score: 42}
+	id, err := s.SaveProfile(p)
+	if err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	for _, step := range [][2]ConnectionState{
+		{StateDiscovered, StateQueued},
+		{StateQueued, StateRequested},
+		{StateRequested, StateAccepted},
+	} {
+		if err := s.TransitionState(p.ProfileURL, step[0], step[1]); err != nil {
+			t.Fatalf("%s -> %s: %v", step[0], step[1], err)
+		}
+	}
+
+	// A conflicting transition must not archive anything
+	if err := s.TransitionState(p.ProfileURL, StateFollowed, StateMessaged); !errors.Is(err, ErrStateConflict) {
+		t.Fatalf("followed -> messaged from accepted = %v, want ErrStateConflict", err)
+	}
+	if history, _ := s.GetScoreHistory(id); len(history) != 0 {
+		t.Fatalf("score archived by a failed transition: %+v", history)
+	}
+
+	if err := s.TransitionState(p.ProfileURL, StateAccepted, StateMessaged); err != nil {
+		t.Fatalf("accepted -> messaged: %v", err)
+	}
+
+	history, err := s.GetScoreHistory(id)
+	if err != nil {
+		t.Fatalf("GetScoreHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Score != 42 {
+		t.Errorf("score history = %+v, want one entry with score 42", history)
+	}
+
+	got, err := s.GetProfileByURL(p.ProfileURL)
+	if err != nil {
+		t.Fatalf("GetProfileByURL: %v", err)
+	}
+	if got.Score != 0 || got.ConnectionState != StateMessaged {
+		t.Errorf("profile score = %v, state = %s; want 0, messaged", got.Score, got.ConnectionState)
+	}
+}
+
+func TestGetAverageConversionScoreUsesCallerDays(t *testing.T) {
+	s := newTestStorage(t)
+	p := saveTestProfile(t, s, "jane")
+
+	// 23:30 UTC on May 1st is already May 2nd two hours east of UTC
+	for _, entry := range []struct {
+		score      float64
+		archivedAt string
+	}{
+		{10, "2024-05-01 21:00:00"},
+		{30, "2024-05-01 23:30:00"},
+	} {
+		if _, err := s.db.Exec(`INSERT INTO score_history (profile_id, score, base_score, archived_at) VALUES (?, ?, 0, ?)`,
+			p.ID, entry.score, entry.archivedAt); err != nil {
+			t.Fatalf("insert score_history: %v", err)
+		}
+	}
+
+	east := time.FixedZone("UTC+2", 2*60*60)
+	tests := []struct {
+		day  time.Time
+		want float64
+	}{
+		{time.Date(2024, 5, 1, 12, 0, 0, 0, east), 10},
+		{time.Date(2024, 5, 2, 12, 0, 0, 0, east), 30},
+		{time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), 20},
+	}
+
+	for _, tt := range tests {
+		got, err := s.GetAverageConversionScore(tt.day, tt.day)
+		if err != nil {
+			t.Fatalf("GetAverageConversionScore: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("GetAverageConversionScore(%s) = %v, want %v", tt.day, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// DecayFunc returns a profile's current score from its base score and
+// discovery time
+type DecayFunc func(base float64, discoveredAt time.Time) float64
+
+// DecayScores recalculates the score of every profile that hasn't been
+// contacted yet from its base score and returns how many changed
+func (s *Storage) DecayScores(decay DecayFunc) (int, error) {
+	rows, err := s.db.Query(`
+		SELECT id, base_score, score, discovered_at FROM profiles
+		WHERE connection_state IN (?, ?)
+	`, StateDiscovered, StateQueued)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load profile scores: %w", err)
+	}
+
+	type scoreUpdate struct {
+		id    int64
+		score float64
+	}
+
+	var updates []scoreUpdate
+	for rows.Next() {
+		var id int64
+		var base, current float64
+		var discoveredAt time.Time
+		if err := rows.Scan(&id, &base, &current, &discoveredAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		if score := decay(base, discoveredAt); score != current {
+			updates = append(updates, scoreUpdate{id, score})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, u := range updates {
+		if _, err := tx.Exec(`UPDATE profiles SET score = ? WHERE id = ?`, u.score, u.id); err != nil {
+			return 0, fmt.Errorf("failed to update score: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	s.ProfileCache.Purge()
+
+	return len(updates), nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDecayScores(t *testing.T) {
+	s := newTestStorage(t)
+
+	const halfLifeDays = 30
+	now := time.Now().UTC()
+	halve := func(base float64, discoveredAt time.Time) float64 {
+		days := now.Sub(discoveredAt).Hours() / 24
+		return base * math.Pow(2, -days/halfLifeDays)
+	}
+
+	discovered := map[string]time.Time{
+		"fresh":     now,
+		"stale":     now.AddDate(0, 0, -halfLifeDays),
+		"contacted": now.AddDate(0, 0, -halfLifeDays),
+	}
+	for username, at := range discovered {
+		profile := &Profile{ProfileURL: "https://www.linkedin.com/in/" + username, Name: username, BaseScore: 0.8, Score: 0.8}
+		if _, err := s.SaveProfile(profile); err != nil {
+			t.Fatalf("SaveProfile: %v", err)
+		}
+		if _, err := s.db.Exec(`UPDATE profiles SET discovered_at = ? WHERE profile_url = ?`,
+			at.Format("2006-01-02 15:04:05"), profile.ProfileURL); err != nil {
+			t.Fatalf("set discovered_at: %v", err)
+		}
+	}
+	if _, err := s.db.Exec(`UPDATE profiles SET connection_state = ? WHERE name = 'contacted'`, StateRequested); err != nil {
+		t.Fatalf("set connection_state: %v", err)
+	}
+
+	updated, err := s.DecayScores(halve)
+	if err != nil {
+		t.Fatalf("DecayScores: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("updated %d profiles, want the 2 uncontacted ones", updated)
+	}
+
+	want := map[string]float64{"fresh": 0.8, "stale": 0.4, "contacted": 0.8}
+	for username, score := range want {
+		got, err := s.GetProfileByURL("https://www.linkedin.com/in/" + username)
+		if err != nil {
+			t.Fatalf("GetProfileByURL: %v", err)
+		}
+		if math.Abs(got.Score-score) > 0.001 {
+			t.Errorf("%s score = %.4f, want about %.1f", username, got.Score, score)
+		}
+		if got.BaseScore != 0.8 {
+			t.Errorf("%s base score = %v, want it unchanged", username, got.BaseScore)
+		}
+	}
+
+	// Scores already decayed to the same value aren't rewritten
+	if updated, err := s.DecayScores(func(base float64, _ time.Time) float64 { return base }); err != nil || updated != 2 {
+		t.Errorf("DecayScores back to base = %d, %v, want 2 updated", updated, err)
+	}
+	if updated, err := s.DecayScores(func(base float64, _ time.Time) float64 { return base }); err != nil || updated != 0 {
+		t.Errorf("DecayScores with unchanged scores = %d, %v, want 0 updated", updated, err)
+	}
+}
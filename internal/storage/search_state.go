@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SearchState tracks pagination progress for a search target so interrupted
+// searches can resume where they stopped
+type SearchState struct {
+	TargetHash  string
+	LastPage    int
+	LastURL     string
+	StartedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// GetSearchState returns the saved state for a search target, or nil if none
+func (s *Storage) GetSearchState(targetHash string) (*SearchState, error) {
+	var state SearchState
+	var completedAt sql.NullTime
+
+	err := s.db.QueryRow(`
+		SELECT search_target_hash, last_page, last_url, started_at, completed_at
+		FROM search_state WHERE search_target_hash = ?
+	`, targetHash).Scan(&state.TargetHash, &state.LastPage, &state.LastURL, &state.StartedAt, &completedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		state.CompletedAt = &completedAt.Time
+	}
+	return &state, nil
+}
+
+// StartSearchState records the start of a fresh search for a target
+func (s *Storage) StartSearchState(targetHash, startURL string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO search_state (search_target_hash, last_page, last_url, started_at, completed_at)
+		VALUES (?, 0, ?, CURRENT_TIMESTAMP, NULL)
+		ON CONFLICT (search_target_hash) DO UPDATE SET
+			last_page = 0,
+			last_url = excluded.last_url,
+			started_at = CURRENT_TIMESTAMP,
+			completed_at = NULL
+	`, targetHash, startURL)
+
+	return err
+}
+
+// UpdateSearchProgress records the number of pages processed and the URL of
+// the next page to process
+func (s *Storage) UpdateSearchProgress(targetHash string, lastPage int, nextURL string) error {
+	_, err := s.db.Exec(`
+		UPDATE search_state SET last_page = ?, last_url = ? WHERE search_target_hash = ?
+	`, lastPage, nextURL, targetHash)
+
+	return err
+}
+
+// CompleteSearchState marks a target's search as finished
+func (s *Storage) CompleteSearchState(targetHash string) error {
+	_, err := s.db.Exec(`
+		UPDATE search_state SET completed_at = CURRENT_TIMESTAMP WHERE search_target_hash = ?
+	`, targetHash)
+
+	return err
+}
+
+// ResetSearchState discards saved progress so the next search starts at page 1
+func (s *Storage) ResetSearchState(targetHash string) error {
+	_, err := s.db.Exec(`DELETE FROM search_state WHERE search_target_hash = ?`, targetHash)
+	return err
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSearchStateLifecycle(t *testing.T) {
+	s := newTestStorage(t)
+	const hash = "target-hash"
+
+	if state, err := s.GetSearchState(hash); err != nil || state != nil {
+		t.Fatalf("GetSearchState before start = %+v, %v, want nil", state, err)
+	}
+
+	if err := s.StartSearchState(hash, "https://www.linkedin.com/search/results/people/?keywords=cto"); err != nil {
+		t.Fatalf("StartSearchState: %v", err)
+	}
+	if err := s.UpdateSearchProgress(hash, 3, "https://www.linkedin.com/search/results/people/?keywords=cto&page=4"); err != nil {
+		t.Fatalf("UpdateSearchProgress: %v", err)
+	}
+
+	state, err := s.GetSearchState(hash)
+	if err != nil {
+		t.Fatalf("GetSearchState: %v", err)
+	}
+	if state.LastPage != 3 || state.LastURL != "https://www.linkedin.com/search/results/people/?keywords=cto&page=4" || state.CompletedAt != nil {
+		t.Errorf("state after progress = %+v, want page 3 of an incomplete search", state)
+	}
+	if time.Since(state.StartedAt) > time.Minute {
+		t.Errorf("StartedAt = %v, want just now", state.StartedAt)
+	}
+
+	if err := s.CompleteSearchState(hash); err != nil {
+		t.Fatalf("CompleteSearchState: %v", err)
+	}
+	if state, err := s.GetSearchState(hash); err != nil || state.CompletedAt == nil {
+		t.Errorf("state after completion = %+v, %v, want completed_at set", state, err)
+	}
+
+	// Starting again resets progress and completion
+	if err := s.StartSearchState(hash, "https://www.linkedin.com/search/results/people/?keywords=cto"); err != nil {
+		t.Fatalf("StartSearchState again: %v", err)
+	}
+	if state, err := s.GetSearchState(hash); err != nil || state.LastPage != 0 || state.CompletedAt != nil {
+		t.Errorf("state after restart = %+v, %v, want page 0 of an incomplete search", state, err)
+	}
+
+	if err := s.ResetSearchState(hash); err != nil {
+		t.Fatalf("ResetSearchState: %v", err)
+	}
+	if state, err := s.GetSearchState(hash); err != nil || state != nil {
+		t.Errorf("GetSearchState after reset = %+v, %v, want nil", state, err)
+	}
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SaveSessionCreatedAt records when the current LinkedIn session was created
+func (s *Storage) SaveSessionCreatedAt(t time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO session_state (id, created_at) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET created_at = excluded.created_at
+	`, t.UTC().Format("2006-01-02 15:04:05"))
+
+	return err
+}
+
+// GetSessionCreatedAt returns when the current session was created, or the
+// zero time if no session has been recorded
+func (s *Storage) GetSessionCreatedAt() (time.Time, error) {
+	var createdAt string
+	err := s.db.QueryRow(`SELECT created_at FROM session_state WHERE id = 1`).Scan(&createdAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse("2006-01-02 15:04:05", createdAt)
+}
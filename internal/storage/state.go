@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConnectionState is a profile's position in the outreach lifecycle
+type ConnectionState string
+
+const (
+	StateDiscovered ConnectionState = "discovered"
+	StateQueued     ConnectionState = "queued"
+	StateRequested  ConnectionState = "requested"
+	StatePending    ConnectionState = "pending"
+	StateAccepted   ConnectionState = "accepted"
+	StateWithdrawn  ConnectionState = "withdrawn"
+	StateExpired    ConnectionState = "expired"
+	StateRejected   ConnectionState = "rejected"
+	StateFollowed   ConnectionState = "followed"
+	StateMessaged   ConnectionState = "messaged"
+	StateReplied    ConnectionState = "replied"
+)
+
+var (
+	// ErrInvalidTransition is returned for transitions not in allowedTransitions
+	ErrInvalidTransition = errors.New("invalid connection state transition")
+
+	// ErrStateConflict is returned when the profile is no longer in the expected
+	// state, e.g. because another worker already moved it
+	ErrStateConflict = errors.New("connection state changed concurrently")
+)
+
+// allowedTransitions lists the states each state may move to
+var allowedTransitions = map[ConnectionState][]ConnectionState{
+	StateDiscovered: {StateQueued},
+	StateQueued:     {StateRequested, StateFollowed, StateDiscovered},
+	StateRequested:  {StatePending, StateAccepted, StateRejected, StateWithdrawn, StateExpired},
+	StatePending:    {StateAccepted, StateRejected, StateWithdrawn, StateExpired},
+	StateAccepted:   {StateMessaged},
+	StateFollowed:   {StateMessaged},
+	StateWithdrawn:  {StateQueued},
+	StateExpired:    {StateQueued},
+	StateMessaged:   {StateReplied},
+}
+
+// CanTransition reports whether a profile may move from one state to another
+func CanTransition(from, to ConnectionState) bool {
+	for _, allowed := range allowedTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// statesLeadingTo returns every state that may transition to the given state
+func statesLeadingTo(to ConnectionState) []ConnectionState {
+	var states []ConnectionState
+	for from := range allowedTransitions {
+		if CanTransition(from, to) {
+			states = append(states, from)
+		}
+	}
+	return states
+}
+
+// TransitionState moves a profile from one state to another. The update only
+// applies if the profile is still in the from state, so concurrent callers
+// can't both win.
+func (s *Storage) TransitionState(profileURL string, from, to ConnectionState) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE profiles SET connection_state = ?
+		WHERE profile_url = ? AND connection_state = ?
+	`, to, profileURL, from)
+	if err != nil {
+		return fmt.Errorf("failed to update connection state: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s is not %s", ErrStateConflict, profileURL, from)
+	}
+
+	// Messaged profiles are converted, so their score moves to score_history
+	// along with the state change
+	if to == StateMessaged {
+		if err := archiveScore(tx, `profile_url = ?`, profileURL); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.invalidateProfile(profileURL)
+
+	return nil
+}
+
+// execQueryer is satisfied by both *sql.DB and *sql.Tx
+type execQueryer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// transitionToAny moves a profile to the given state from whichever valid
+// state it is in. Used where the caller only knows the outcome, such as a
+// connection being accepted. A profile already in that state is left alone;
+// one in a state that can't move there gets ErrStateConflict.
+func (s *Storage) transitionToAny(exec execQueryer, profileURL string, to ConnectionState) error {
+	from := statesLeadingTo(to)
+	if len(from) == 0 {
+		return fmt.Errorf("%w: nothing leads to %s", ErrInvalidTransition, to)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(from)), ", ")
+	args := []any{to, profileURL}
+	for _, state := range from {
+		args = append(args, state)
+	}
+
+	result, err := exec.Exec(`
+		UPDATE profiles SET connection_state = ?
+		WHERE profile_url = ? AND connection_state IN (`+placeholders+`)
+	`, args...)
+	s.invalidateProfile(profileURL)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+
+	var current ConnectionState
+	err = exec.QueryRow(`SELECT connection_state FROM profiles WHERE profile_url = ?`, profileURL).Scan(&current)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if current != to {
+		return fmt.Errorf("%w: %s is %s, can't move to %s", ErrStateConflict, profileURL, current, to)
+	}
+	return nil
+}
+
+// GetConnectionsByState returns all profiles currently in the given state
+func (s *Storage) GetConnectionsByState(state ConnectionState) ([]Profile, error) {
+	rows, err := s.db.Query(`SELECT `+profileColumns+` FROM profiles WHERE connection_state = ? ORDER BY id`, state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// CountByState returns the number of profiles in each connection state
+func (s *Storage) CountByState() (map[ConnectionState]int, error) {
+	rows, err := s.db.Query(`SELECT connection_state, COUNT(*) FROM profiles GROUP BY connection_state`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[ConnectionState]int)
+	for rows.Next() {
+		var state ConnectionState
+		var count int
+		if err := rows.Scan(&state, &count); err != nil {
+			return nil, err
+		}
+		counts[state] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// profileState returns the stored connection state of a profile
+func profileState(t *testing.T, s *Storage, profileURL string) ConnectionState {
+	t.Helper()
+
+	var state ConnectionState
+	if err := s.db.QueryRow(`SELECT connection_state FROM profiles WHERE profile_url = ?`, profileURL).Scan(&state); err != nil {
+		t.Fatalf("read connection_state: %v", err)
+	}
+	return state
+}
+
+func TestTransitionState(t *testing.T) {
+	s := newTestStorage(t)
+	p := saveTestProfile(t, s, "jane")
+
+	if err := s.TransitionState(p.ProfileURL, StateDiscovered, StateAccepted); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("discovered -> accepted = %v, want ErrInvalidTransition", err)
+	}
+	if err := s.TransitionState(p.ProfileURL, StateQueued, StateRequested); !errors.Is(err, ErrStateConflict) {
+		t.Errorf("queued -> requested from discovered = %v, want ErrStateConflict", err)
+	}
+
+	for _, step := range [][2]ConnectionState{
+		{StateDiscovered, StateQueued},
+		{StateQueued, StateDiscovered},
+		{StateDiscovered, StateQueued},
+		{StateQueued, StateRequested},
+	} {
+		if err := s.TransitionState(p.ProfileURL, step[0], step[1]); err != nil {
+			t.Fatalf("%s -> %s: %v", step[0], step[1], err)
+		}
+	}
+	if got := profileState(t, s, p.ProfileURL); got != StateRequested {
+		t.Errorf("state = %s, want %s", got, StateRequested)
+	}
+}
+
+func TestUpdateConnectionStatusChecksTheFromState(t *testing.T) {
+	s := newTestStorage(t)
+
+	requested := saveTestProfile(t, s, "requested")
+	saveTestConnection(t, s, requested, time.Now(), "pending")
+
+	if err := s.UpdateConnectionStatus(requested.ProfileURL, "accepted"); err != nil {
+		t.Fatalf("UpdateConnectionStatus: %v", err)
+	}
+	if got := profileState(t, s, requested.ProfileURL); got != StateAccepted {
+		t.Errorf("state = %s, want %s", got, StateAccepted)
+	}
+
+	// Seeing the same acceptance again is not a conflict
+	if err := s.UpdateConnectionStatus(requested.ProfileURL, "accepted"); err != nil {
+		t.Errorf("repeated UpdateConnectionStatus = %v, want nil", err)
+	}
+
+	// A profile that was never queued can't jump to withdrawn, but the
+	// request status is still saved
+	stray := saveTestProfile(t, s, "stray")
+	if err := s.SaveConnectionRequest(&ConnectionRequest{ProfileID: stray.ID, ProfileURL: stray.ProfileURL, Status: "pending"}); err != nil {
+		t.Fatalf("SaveConnectionRequest: %v", err)
+	}
+	if err := s.UpdateConnectionStatus(stray.ProfileURL, "withdrawn"); !errors.Is(err, ErrStateConflict) {
+		t.Errorf("UpdateConnectionStatus = %v, want ErrStateConflict", err)
+	}
+	if got := profileState(t, s, stray.ProfileURL); got != StateDiscovered {
+		t.Errorf("state = %s, want it left at %s", got, StateDiscovered)
+	}
+
+	var status string
+	if err := s.db.QueryRow(`SELECT status FROM connection_requests WHERE profile_url = ?`, stray.ProfileURL).Scan(&status); err != nil {
+		t.Fatalf("read status: %v", err)
+	}
+	if status != "withdrawn" {
+		t.Errorf("status = %s, want withdrawn", status)
+	}
+}
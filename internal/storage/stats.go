@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// DailyStatPoint holds activity counts for a single day
+type DailyStatPoint struct {
+	Date                string `json:"date"`
+	ConnectionsSent     int    `json:"connections_sent"`
+	MessagesSent        int    `json:"messages_sent"`
+	ConnectionsAccepted int    `json:"connections_accepted"`
+	Replies             int    `json:"replies"`
+	ProfilesDiscovered  int    `json:"profiles_discovered"`
+}
+
+// WeeklyStatPoint holds activity counts for a Monday-to-Sunday week
+type WeeklyStatPoint struct {
+	WeekStart           string `json:"week_start"`
+	ConnectionsSent     int    `json:"connections_sent"`
+	MessagesSent        int    `json:"messages_sent"`
+	ConnectionsAccepted int    `json:"connections_accepted"`
+	Replies             int    `json:"replies"`
+	ProfilesDiscovered  int    `json:"profiles_discovered"`
+}
+
+// GetDailyStats returns one point per day between from and to (inclusive),
+// with zeros for days without activity
+func (s *Storage) GetDailyStats(from, to time.Time) ([]DailyStatPoint, error) {
+	fromDate := from.Format("2006-01-02")
+	toDate := to.Format("2006-01-02")
+	if fromDate > toDate {
+		return nil, fmt.Errorf("invalid range: %s is after %s", fromDate, toDate)
+	}
+
+	rows, err := s.db.Query(`
+		WITH RECURSIVE days(day) AS (
+			SELECT DATE(?)
+			UNION ALL
+			SELECT DATE(day, '+1 day') FROM days WHERE day < DATE(?)
+		)
+		SELECT days.day,
+			COALESCE(cs.n, 0), COALESCE(ms.n, 0), COALESCE(ca.n, 0),
+			COALESCE(mr.n, 0), COALESCE(pd.n, 0)
+		FROM days
+		LEFT JOIN (
+			SELECT DATE(sent_at) AS day, COUNT(*) AS n FROM connection_requests
+			WHERE `+invitationSentFilter+` GROUP BY DATE(sent_at)
+		) cs ON cs.day = days.day
+		LEFT JOIN (
+			SELECT DATE(sent_at) AS day, COUNT(*) AS n FROM messages
+			WHERE status = 'sent' GROUP BY DATE(sent_at)
+		) ms ON ms.day = days.day
+		LEFT JOIN (
+			SELECT DATE(accepted_at) AS day, COUNT(*) AS n FROM connection_requests
+			WHERE accepted_at IS NOT NULL GROUP BY DATE(accepted_at)
+		) ca ON ca.day = days.day
+		LEFT JOIN (
+			SELECT DATE(replied_at) AS day, COUNT(*) AS n FROM messages
+			WHERE replied_at IS NOT NULL GROUP BY DATE(replied_at)
+		) mr ON mr.day = days.day
+		LEFT JOIN (
+			SELECT DATE(discovered_at) AS day, COUNT(*) AS n FROM profiles
+			GROUP BY DATE(discovered_at)
+		) pd ON pd.day = days.day
+		ORDER BY days.day
+	`, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily stats: %w", err)
+	}
+	defer rows.Close()
+
+	var points []DailyStatPoint
+	for rows.Next() {
+		var p DailyStatPoint
+		if err := rows.Scan(&p.Date, &p.ConnectionsSent, &p.MessagesSent, &p.ConnectionsAccepted, &p.Replies, &p.ProfilesDiscovered); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
+
+// GetWeeklyStats returns one point per week for the current week and the
+// weeksBack-1 weeks before it, oldest first
+func (s *Storage) GetWeeklyStats(weeksBack int) ([]WeeklyStatPoint, error) {
+	if weeksBack <= 0 {
+		return nil, fmt.Errorf("weeks must be positive, got %d", weeksBack)
+	}
+
+	today := time.Now().UTC()
+	daysSinceMonday := (int(today.Weekday()) + 6) % 7
+	from := today.AddDate(0, 0, -daysSinceMonday-7*(weeksBack-1))
+
+	days, err := s.GetDailyStats(from, today)
+	if err != nil {
+		return nil, err
+	}
+
+	weeks := make([]WeeklyStatPoint, 0, weeksBack)
+	for i, day := range days {
+		if i%7 == 0 {
+			weeks = append(weeks, WeeklyStatPoint{WeekStart: day.Date})
+		}
+		week := &weeks[len(weeks)-1]
+		week.ConnectionsSent += day.ConnectionsSent
+		week.MessagesSent += day.MessagesSent
+		week.ConnectionsAccepted += day.ConnectionsAccepted
+		week.Replies += day.Replies
+		week.ProfilesDiscovered += day.ProfilesDiscovered
+	}
+
+	return weeks, nil
+}
+
+// TitleCount is the number of profiles sharing a job title
+type TitleCount struct {
+	JobTitle string `json:"job_title"`
+	Count    int    `json:"count"`
+}
+
+// GetTopConnectedJobTitles returns the most common job titles among profiles
+// that accepted a connection request
+func (s *Storage) GetTopConnectedJobTitles(limit int) ([]TitleCount, error) {
+	rows, err := s.db.Query(`
+		SELECT job_title, COUNT(*) AS n FROM profiles
+		WHERE connection_state IN (?, ?, ?) AND job_title != ''
+		GROUP BY job_title
+		ORDER BY n DESC, job_title
+		LIMIT ?
+	`, StateAccepted, StateMessaged, StateReplied, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var titles []TitleCount
+	for rows.Next() {
+		var t TitleCount
+		if err := rows.Scan(&t.JobTitle, &t.Count); err != nil {
+			return nil, err
+		}
+		titles = append(titles, t)
+	}
+
+	return titles, rows.Err()
+}
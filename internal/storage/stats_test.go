@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetDailyStatsZeroFillsMissingDays(t *testing.T) {
+	s := newTestStorage(t)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 13)
+	at := func(day, hour int) string {
+		return from.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour).Format("2006-01-02 15:04:05")
+	}
+
+	// Activity on days 0, 3 and 13 only; the rest of the 14 days are empty
+	for i, day := range []int{0, 0, 3, 13} {
+		profile := saveTestProfile(t, s, fmt.Sprintf("user-%d", i))
+		if _, err := s.db.Exec(`UPDATE profiles SET discovered_at = ? WHERE id = ?`, at(day, 9), profile.ID); err != nil {
+			t.Fatalf("set discovered_at: %v", err)
+		}
+		saveTestConnection(t, s, profile, from.AddDate(0, 0, day).Add(10*time.Hour), "pending")
+	}
+	if _, err := s.db.Exec(`UPDATE connection_requests SET accepted_at = ? WHERE profile_url LIKE '%user-0'`, at(3, 12)); err != nil {
+		t.Fatalf("set accepted_at: %v", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO messages (profile_url, content, sent_at, replied_at) VALUES (?, 'hi', ?, ?)`,
+		"https://www.linkedin.com/in/user-0", at(3, 13), at(13, 8)); err != nil {
+		t.Fatalf("insert message: %v", err)
+	}
+	// Outside the range
+	if _, err := s.db.Exec(`INSERT INTO messages (profile_url, content, sent_at) VALUES ('https://www.linkedin.com/in/user-1', 'hi', ?)`, at(14, 0)); err != nil {
+		t.Fatalf("insert message: %v", err)
+	}
+
+	points, err := s.GetDailyStats(from, to)
+	if err != nil {
+		t.Fatalf("GetDailyStats: %v", err)
+	}
+	if len(points) != 14 {
+		t.Fatalf("got %d points, want 14", len(points))
+	}
+
+	want := map[int]DailyStatPoint{
+		0:  {ConnectionsSent: 2, ProfilesDiscovered: 2},
+		3:  {ConnectionsSent: 1, ProfilesDiscovered: 1, ConnectionsAccepted: 1, MessagesSent: 1},
+		13: {ConnectionsSent: 1, ProfilesDiscovered: 1, Replies: 1},
+	}
+	for i, point := range points {
+		expected := want[i]
+		expected.Date = from.AddDate(0, 0, i).Format("2006-01-02")
+		if !reflect.DeepEqual(point, expected) {
+			t.Errorf("day %d = %+v, want %+v", i, point, expected)
+		}
+	}
+}
+
+func TestGetDailyStatsInvalidRange(t *testing.T) {
+	s := newTestStorage(t)
+
+	now := time.Now()
+	if _, err := s.GetDailyStats(now, now.AddDate(0, 0, -1)); err == nil {
+		t.Error("GetDailyStats with from after to succeeded, want an error")
+	}
+}
+
+func TestGetWeeklyStats(t *testing.T) {
+	s := newTestStorage(t)
+
+	today := time.Now().UTC()
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	profile := saveTestProfile(t, s, "jane")
+	saveTestConnection(t, s, profile, monday.AddDate(0, 0, -7), "pending")
+
+	weeks, err := s.GetWeeklyStats(2)
+	if err != nil {
+		t.Fatalf("GetWeeklyStats: %v", err)
+	}
+	if len(weeks) != 2 {
+		t.Fatalf("got %d weeks, want 2", len(weeks))
+	}
+	if got, want := weeks[0].WeekStart, monday.AddDate(0, 0, -7).Format("2006-01-02"); got != want {
+		t.Errorf("first week starts %s, want %s", got, want)
+	}
+	if weeks[0].ConnectionsSent != 1 || weeks[1].ConnectionsSent != 0 {
+		t.Errorf("connections per week = %d, %d, want 1, 0", weeks[0].ConnectionsSent, weeks[1].ConnectionsSent)
+	}
+
+	if _, err := s.GetWeeklyStats(0); err == nil {
+		t.Error("GetWeeklyStats(0) succeeded, want an error")
+	}
+}
@@ -2,37 +2,168 @@ package storage
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"linkedin-automation/internal/normalize"
+	"linkedin-automation/internal/urlutil"
+
+	lru "github.com/hashicorp/golang-lru/v2"
 	_ "modernc.org/sqlite"
 )
 
 type Storage struct {
 	db *sql.DB
+
+	// Asynchronous activity log writer
+	activityCh     chan activityEntry
+	activityFlush  chan chan error
+	activityDone   chan struct{}
+	activityMu     sync.RWMutex
+	activityClosed bool
+
+	// activityObserver is notified of every logged activity, see OnActivity
+	activityObserver atomic.Pointer[ActivityObserver]
+
+	// safeMode mirrors bot_state.safe_mode_active, see SafeModeActive
+	safeMode atomic.Bool
+
+	// ProfileCache holds recently looked-up profiles keyed by profile URL
+	ProfileCache  *lru.Cache[string, *Profile]
+	cacheCounters profileCacheCounters
 }
 
+// ActivityObserver is called with each activity as it's logged
+type ActivityObserver func(actionType, outcome string)
+
+// activityEntry is a queued activity_log row
+type activityEntry struct {
+	actionType   string
+	targetURL    string
+	outcome      string
+	errorMessage string
+}
+
+const (
+	activityQueueSize     = 1000
+	activityBatchSize     = 50
+	activityFlushInterval = 2 * time.Second
+)
+
 type Profile struct {
 	ID           int64
 	ProfileURL   string
 	Name         string
 	JobTitle     string
 	Company      string
+	CompanyURL   string
+	School       string
 	Location     string
 	Keywords     string
+	OpenToWork   bool
+	Headline     string
+	Summary      string
 	DiscoveredAt time.Time
+
+	// BaseScore is the ICP match score from 0.0 to 1.0; Score is BaseScore
+	// after decay for time since discovery
+	BaseScore float64
+	Score     float64
+
+	// KeywordDensity is the share of About section words matching
+	// Search.RelevantKeywords; SummaryKeywords lists the matched keywords
+	// and is not persisted
+	KeywordDensity  float64
+	SummaryKeywords []string
+
+	// ConnectionDegree is 1, 2 or 3 (3rd+) from the search result badge, 0 if unknown
+	ConnectionDegree int
+
+	// ConnectionState tracks the outreach lifecycle, see TransitionState
+	ConnectionState ConnectionState
+
+	// LastActiveEstimate is derived from activity badges on search results;
+	// nil when LinkedIn showed no recency hint
+	LastActiveEstimate *time.Time
+
+	// NormalizedJobTitle and Seniority are derived from JobTitle on save,
+	// see normalize.NormalizeJobTitle
+	NormalizedJobTitle string
+	Seniority          normalize.Seniority
+
+	// NormalizedCompany is Company without punctuation and legal suffixes,
+	// or the canonical name set for it in company_aliases
+	NormalizedCompany string
+
+	// CompanySizeBucket is inferred from the company page during enrichment
+	CompanySizeBucket CompanySize
+
+	// DiscoveredURL is the URL found on the search card before redirects
+	// were resolved; ProfileURL holds the canonical URL
+	DiscoveredURL string
+
+	// DiscoverySource is where the profile was found, e.g.
+	// DiscoverySourcePeopleAlsoViewed; empty means DiscoverySourceSearch
+	DiscoverySource string
+}
+
+// ProfileQueryOptions filters and paginates profile listings
+type ProfileQueryOptions struct {
+	OpenToWorkOnly bool
+
+	// HeadlineContains matches profiles whose headline contains the text
+	HeadlineContains string
+
+	// Seniority matches profiles at the given level, e.g. "VP"
+	Seniority normalize.Seniority
+
+	// CompanyContains matches profiles whose normalized company contains
+	// the normalized text, so "Acme Corp" matches "Acme, Inc."
+	CompanyContains string
+
+	// CompanySize matches profiles whose company is in the given bucket
+	CompanySize CompanySize
+
+	Limit  int
+	Offset int
 }
 
+// StatusEmailRequiredSkipped is the status of connection_requests rows for
+// profiles skipped because they require an email address to connect
+const StatusEmailRequiredSkipped = "email_required_skipped"
+
+// invitationSentFilter excludes connection_requests rows that sent no
+// invitation, i.e. follows and email required skips
+const invitationSentFilter = `status NOT IN ('` + string(StateFollowed) + `', '` + StatusEmailRequiredSkipped + `')`
+
 type ConnectionRequest struct {
 	ID         int64
 	ProfileID  int64
 	ProfileURL string
 	SentAt     time.Time
 	Note       string
-	Status     string // pending, accepted, rejected
+	Status     string // pending, accepted, rejected, followed, withdrawn, expired
 	AcceptedAt *time.Time
+
+	// ExpiredAt is when a request LinkedIn let lapse was marked expired
+	ExpiredAt *time.Time
+
+	// TemplateID is the 1-based position of the note template in the config, 0 if none
+	TemplateID int
+
+	// NoteRuleID is the 1-based position of the template preference rule
+	// that boosted the chosen template, 0 if none
+	NoteRuleID int
+
+	// MessageAfterAt is the earliest time an accepted connection may be
+	// messaged, drawn once when the acceptance is first seen
+	MessageAfterAt *time.Time
 }
 
 type Message struct {
@@ -41,7 +172,27 @@ type Message struct {
 	ProfileURL string
 	Content    string
 	SentAt     time.Time
-	Status     string // sent, failed
+	Status     string // sent, failed, existing_thread
+	ThreadURL  string
+
+	// TemplateID is the 1-based position of the message template in the config, 0 if none
+	TemplateID int
+
+	// DeliveryStatus is "delivered" or "seen" once checked, empty before that
+	DeliveryStatus string
+	SeenAt         *time.Time
+
+	// RepliedAt is set once a reply was detected
+	RepliedAt *time.Time
+}
+
+// Relationship links two profiles, e.g. same_company, referred_by or same_search_target
+type Relationship struct {
+	ID               int64
+	SourceProfileID  int64
+	TargetProfileID  int64
+	RelationshipType string
+	Metadata         string
 }
 
 type DailyStats struct {
@@ -51,22 +202,38 @@ type DailyStats struct {
 }
 
 // New creates a new storage instance
-func New(dbPath string) (*Storage, error) {
+func New(dbPath string, profileCacheSize int) (*Storage, error) {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	// Wait for locks instead of failing with SQLITE_BUSY, since the metrics,
+	// API, enrichment and activity log goroutines share the database. Pragmas
+	// in the DSN apply to every pooled connection.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	storage := &Storage{db: db}
+	storage := &Storage{
+		db:            db,
+		activityCh:    make(chan activityEntry, activityQueueSize),
+		activityFlush: make(chan chan error),
+		activityDone:  make(chan struct{}),
+	}
+	if err := storage.newProfileCache(profileCacheSize); err != nil {
+		return nil, err
+	}
 	if err := storage.initSchema(); err != nil {
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
+	if err := storage.loadBotState(); err != nil {
+		return nil, fmt.Errorf("failed to load bot state: %w", err)
+	}
+
+	go storage.runActivityWriter()
 
 	return storage, nil
 }
@@ -115,56 +282,526 @@ func (s *Storage) initSchema() error {
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS profile_relationships (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_profile_id INTEGER NOT NULL,
+		target_profile_id INTEGER NOT NULL,
+		relationship_type TEXT NOT NULL,
+		metadata TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (source_profile_id, target_profile_id, relationship_type),
+		FOREIGN KEY (source_profile_id) REFERENCES profiles(id),
+		FOREIGN KEY (target_profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS retry_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER NOT NULL,
+		action_type TEXT NOT NULL,
+		attempt_count INTEGER DEFAULT 0,
+		next_retry_at TIMESTAMP,
+		last_error TEXT,
+		UNIQUE (profile_id, action_type),
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS quota_tracking (
+		month TEXT PRIMARY KEY,
+		views_used INTEGER DEFAULT 0,
+		views_limit INTEGER DEFAULT 0,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS company_blacklist (
+		name TEXT PRIMARY KEY COLLATE NOCASE,
+		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS post_engagements (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER,
+		profile_url TEXT NOT NULL,
+		post_url TEXT NOT NULL,
+		action TEXT NOT NULL,
+		content TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (post_url, action),
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS company_follows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_url TEXT UNIQUE NOT NULL,
+		company_name TEXT,
+		followed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS skill_endorsements (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER,
+		profile_url TEXT NOT NULL,
+		skill TEXT NOT NULL,
+		endorsed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (profile_url, skill),
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS message_engagements (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		message_id INTEGER NOT NULL,
+		profile_url TEXT NOT NULL,
+		status TEXT NOT NULL,
+		checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (message_id) REFERENCES messages(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS company_metadata (
+		company_url TEXT PRIMARY KEY,
+		company_size TEXT NOT NULL,
+		employee_range TEXT,
+		updated_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS session_state (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		created_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_posts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER NOT NULL,
+		post_url TEXT NOT NULL,
+		content TEXT NOT NULL,
+		posted_at TIMESTAMP,
+		like_count INTEGER DEFAULT 0,
+		comment_count INTEGER DEFAULT 0,
+		scraped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (profile_id, post_url),
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_keywords (
+		profile_id INTEGER NOT NULL,
+		keyword TEXT NOT NULL,
+		score REAL NOT NULL,
+		extracted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (profile_id, keyword),
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS bot_state (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		safe_mode_active BOOLEAN NOT NULL DEFAULT 0,
+		safe_mode_reason TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS score_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER NOT NULL,
+		score REAL NOT NULL,
+		base_score REAL NOT NULL,
+		archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS inmail_balance (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		balance INTEGER NOT NULL,
+		checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS company_aliases (
+		raw_name TEXT PRIMARY KEY,
+		canonical_name TEXT NOT NULL,
+		manual BOOLEAN NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS enrichment_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER NOT NULL,
+		priority INTEGER NOT NULL DEFAULT 0,
+		queued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		processed_at TIMESTAMP,
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS discovery_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT UNIQUE NOT NULL,
+		source TEXT NOT NULL,
+		source_profile_url TEXT NOT NULL DEFAULT '',
+		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		processed_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_notes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL,
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS auto_responder_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT NOT NULL,
+		rule_index INTEGER NOT NULL,
+		action TEXT NOT NULL,
+		keyword TEXT NOT NULL,
+		matched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (profile_url, rule_index)
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_tags (
+		profile_url TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		tagged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (profile_url, tag)
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_url_redirects (
+		discovered_url TEXT PRIMARY KEY,
+		canonical_url TEXT NOT NULL,
+		resolved_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_aliases (
+		alias_url TEXT PRIMARY KEY,
+		profile_url TEXT NOT NULL,
+		merged_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS school_urns (
+		school_name TEXT PRIMARY KEY COLLATE NOCASE,
+		urn TEXT NOT NULL,
+		resolved_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_variables (
+		profile_id INTEGER NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (profile_id, key),
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS workflow_checkpoints (
+		run_id TEXT PRIMARY KEY,
+		phase TEXT NOT NULL,
+		profiles_json TEXT NOT NULL DEFAULT '[]',
+		updated_at TEXT NOT NULL,
+		completed_at TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS search_state (
+		search_target_hash TEXT PRIMARY KEY,
+		last_page INTEGER DEFAULT 0,
+		last_url TEXT,
+		started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		completed_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_visits (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT NOT NULL,
+		visited_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_profile_visits_url ON profile_visits(profile_url, visited_at);
 	CREATE INDEX IF NOT EXISTS idx_profiles_url ON profiles(profile_url);
 	CREATE INDEX IF NOT EXISTS idx_connections_status ON connection_requests(status);
 	CREATE INDEX IF NOT EXISTS idx_connections_sent_at ON connection_requests(sent_at);
 	CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages(sent_at);
+	CREATE INDEX IF NOT EXISTS idx_relationships_source ON profile_relationships(source_profile_id);
+	CREATE INDEX IF NOT EXISTS idx_relationships_target ON profile_relationships(target_profile_id);
 	`
 
-	_, err := s.db.Exec(schema)
-	return err
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	if err := s.migrateSchema(); err != nil {
+		return err
+	}
+
+	return s.initSearchIndex()
+}
+
+// columnMigrations lists columns added after the initial schema
+var columnMigrations = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"profiles", "open_to_work", "BOOLEAN DEFAULT 0"},
+	{"messages", "thread_url", "TEXT"},
+	{"profiles", "last_active_estimate", "DATETIME"},
+	{"connection_requests", "template_id", "INTEGER DEFAULT 0"},
+	{"messages", "template_id", "INTEGER DEFAULT 0"},
+	{"messages", "replied_at", "TIMESTAMP"},
+	{"profiles", "headline", "TEXT DEFAULT ''"},
+	{"profiles", "summary", "TEXT DEFAULT ''"},
+	{"messages", "seen_at", "TIMESTAMP"},
+	{"messages", "delivery_status", "TEXT"},
+	{"profiles", "company_url", "TEXT DEFAULT ''"},
+	{"profiles", "school", "TEXT DEFAULT ''"},
+	{"profiles", "connection_state", "TEXT DEFAULT 'discovered'"},
+	{"profiles", "connection_degree", "INTEGER DEFAULT 0"},
+	{"profiles", "score", "REAL DEFAULT 0"},
+	{"profiles", "keyword_density", "REAL DEFAULT 0"},
+	{"profiles", "base_score", "REAL DEFAULT 0"},
+	{"connection_requests", "message_after_at", "TIMESTAMP"},
+	{"profiles", "normalized_job_title", "TEXT DEFAULT ''"},
+	{"profiles", "seniority", "TEXT DEFAULT ''"},
+	{"profiles", "company_size_bucket", "TEXT DEFAULT ''"},
+	{"profiles", "discovered_url", "TEXT DEFAULT ''"},
+	{"profiles", "canonical_url", "TEXT DEFAULT ''"},
+	{"connection_requests", "note_rule_id", "INTEGER DEFAULT 0"},
+	{"profiles", "discovery_source", "TEXT DEFAULT 'search'"},
+	{"connection_requests", "response_time_hours", "REAL"},
+	{"connection_requests", "withdrawn_at", "TIMESTAMP"},
+	{"profiles", "photo_path", "TEXT"},
+	{"profiles", "photo_hash", "TEXT"},
+	{"profiles", "photo_downloaded_at", "TIMESTAMP"},
+	{"connection_requests", "expired_at", "TIMESTAMP"},
+	{"profiles", "normalized_company", "TEXT DEFAULT ''"},
+	{"enrichment_queue", "attempts", "INTEGER NOT NULL DEFAULT 0"},
+	{"profile_visits", "purpose", "TEXT NOT NULL DEFAULT 'outreach'"},
+	{"discovery_queue", "attempts", "INTEGER NOT NULL DEFAULT 0"},
+	{"messages", "delivery_checked_at", "TIMESTAMP"},
+}
+
+// columnBackfills derive values for newly added columns from existing data,
+// keyed by "table.column". They only run when the column is first added.
+var columnBackfills = map[string]string{
+	"profiles.base_score":     `UPDATE profiles SET base_score = score`,
+	"profiles.discovered_url": `UPDATE profiles SET discovered_url = profile_url`,
+	"profiles.canonical_url":  `UPDATE profiles SET canonical_url = profile_url`,
+	"connection_requests.response_time_hours": `
+		UPDATE connection_requests SET response_time_hours = (JULIANDAY(accepted_at) - JULIANDAY(sent_at)) * 24
+		WHERE accepted_at IS NOT NULL`,
+	"profiles.connection_state": `
+		UPDATE profiles SET connection_state = CASE
+			WHEN EXISTS (SELECT 1 FROM messages m WHERE m.profile_url = profiles.profile_url AND m.replied_at IS NOT NULL) THEN 'replied'
+			WHEN EXISTS (SELECT 1 FROM messages m WHERE m.profile_url = profiles.profile_url AND m.status = 'sent') THEN 'messaged'
+			ELSE COALESCE((
+				SELECT CASE cr.status WHEN 'pending' THEN 'requested' ELSE cr.status END
+				FROM connection_requests cr
+				WHERE cr.profile_url = profiles.profile_url
+				ORDER BY cr.sent_at DESC LIMIT 1
+			), 'discovered')
+		END
+	`,
+}
+
+// columnBackfillFuncs are backfills that need Go code rather than SQL, keyed
+// like columnBackfills
+var columnBackfillFuncs = map[string]func(s *Storage) error{
+	"profiles.seniority":          (*Storage).backfillNormalizedJobTitles,
+	"profiles.normalized_company": (*Storage).backfillNormalizedCompanies,
+}
+
+// migrateSchema adds any columns missing from databases created by older versions
+func (s *Storage) migrateSchema() error {
+	for _, m := range columnMigrations {
+		exists, err := s.columnExists(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", m.table, m.column, err)
+		}
+
+		if backfill, ok := columnBackfills[m.table+"."+m.column]; ok {
+			if _, err := s.db.Exec(backfill); err != nil {
+				return fmt.Errorf("failed to backfill %s.%s: %w", m.table, m.column, err)
+			}
+		}
+		if backfill, ok := columnBackfillFuncs[m.table+"."+m.column]; ok {
+			if err := backfill(s); err != nil {
+				return fmt.Errorf("failed to backfill %s.%s: %w", m.table, m.column, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// columnExists checks whether a table already has the given column
+func (s *Storage) columnExists(table, column string) (bool, error) {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
 }
 
 // SaveProfile saves a profile to the database
 func (s *Storage) SaveProfile(profile *Profile) (int64, error) {
+	if err := urlutil.ValidateLinkedInURL(profile.ProfileURL); err != nil {
+		return 0, err
+	}
+
+	profile.NormalizedJobTitle, profile.Seniority = normalize.NormalizeJobTitle(profile.JobTitle)
+
+	// Don't recreate a profile that was merged into another URL
+	url, err := s.ResolveProfileAlias(profile.ProfileURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve profile alias: %w", err)
+	}
+
+	discoveredURL := profile.DiscoveredURL
+	if discoveredURL == "" {
+		discoveredURL = profile.ProfileURL
+	}
+	if profile.DiscoverySource == "" {
+		profile.DiscoverySource = DiscoverySourceSearch
+	}
+
+	profile.NormalizedCompany, err = s.canonicalCompany(profile.Company)
+	if err != nil {
+		return 0, fmt.Errorf("failed to normalize company: %w", err)
+	}
+
 	result, err := s.db.Exec(`
-		INSERT OR IGNORE INTO profiles (profile_url, name, job_title, company, location, keywords)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, profile.ProfileURL, profile.Name, profile.JobTitle, profile.Company, profile.Location, profile.Keywords)
+		INSERT OR IGNORE INTO profiles (profile_url, name, job_title, company, company_url, school, location, keywords, open_to_work, headline, summary, connection_degree, base_score, score, last_active_estimate, normalized_job_title, seniority, discovered_url, canonical_url, discovery_source, normalized_company)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, url, profile.Name, profile.JobTitle, profile.Company, profile.CompanyURL, profile.School, profile.Location, profile.Keywords, profile.OpenToWork,
+		profile.Headline, profile.Summary, profile.ConnectionDegree, profile.BaseScore, profile.Score, profile.LastActiveEstimate,
+		profile.NormalizedJobTitle, profile.Seniority, discoveredURL, url, profile.DiscoverySource, profile.NormalizedCompany)
 
 	if err != nil {
 		return 0, err
 	}
+	s.invalidateProfile(url)
 
-	id, _ := result.LastInsertId()
+	// LastInsertId isn't reset by an ignored insert, so check RowsAffected
+	var id int64
+	if n, _ := result.RowsAffected(); n > 0 {
+		id, _ = result.LastInsertId()
+	}
 	if id == 0 {
 		// Profile already exists, get its ID
-		err = s.db.QueryRow("SELECT id FROM profiles WHERE profile_url = ?", profile.ProfileURL).Scan(&id)
+		err = s.db.QueryRow("SELECT id FROM profiles WHERE profile_url = ?", url).Scan(&id)
 	}
 
 	return id, err
 }
 
+// UpdateProfile updates the stored details of an existing profile
+func (s *Storage) UpdateProfile(profile *Profile) error {
+	profile.NormalizedJobTitle, profile.Seniority = normalize.NormalizeJobTitle(profile.JobTitle)
+
+	normalizedCompany, err := s.canonicalCompany(profile.Company)
+	if err != nil {
+		return fmt.Errorf("failed to normalize company: %w", err)
+	}
+	profile.NormalizedCompany = normalizedCompany
+
+	_, err = s.db.Exec(`
+		UPDATE profiles
+		SET name = ?, job_title = ?, company = ?, company_url = ?, location = ?, keywords = ?, open_to_work = ?,
+			summary = ?, keyword_density = ?, normalized_job_title = ?, seniority = ?, company_size_bucket = ?,
+			normalized_company = ?
+		WHERE profile_url = ?
+	`, profile.Name, profile.JobTitle, profile.Company, profile.CompanyURL, profile.Location, profile.Keywords, profile.OpenToWork,
+		profile.Summary, profile.KeywordDensity, profile.NormalizedJobTitle, profile.Seniority, profile.CompanySizeBucket,
+		profile.NormalizedCompany, profile.ProfileURL)
+	s.invalidateProfile(profile.ProfileURL)
+
+	return err
+}
+
+// UpdateLastActiveEstimate records when a profile was last seen active
+func (s *Storage) UpdateLastActiveEstimate(profileURL string, estimate time.Time) error {
+	_, err := s.db.Exec(`
+		UPDATE profiles SET last_active_estimate = ? WHERE profile_url = ?
+	`, estimate, profileURL)
+	s.invalidateProfile(profileURL)
+
+	return err
+}
+
 // SaveConnectionRequest saves a connection request
 func (s *Storage) SaveConnectionRequest(req *ConnectionRequest) error {
 	_, err := s.db.Exec(`
-		INSERT INTO connection_requests (profile_id, profile_url, note, status)
-		VALUES (?, ?, ?, ?)
-	`, req.ProfileID, req.ProfileURL, req.Note, req.Status)
+		INSERT INTO connection_requests (profile_id, profile_url, note, status, template_id, note_rule_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, req.ProfileID, req.ProfileURL, req.Note, req.Status, req.TemplateID, req.NoteRuleID)
 
 	return err
 }
 
+// GetConnectionNote returns the note sent with the latest connection request
+// to a profile, or "" if no request was sent or it had no note
+func (s *Storage) GetConnectionNote(profileURL string) (string, error) {
+	var note sql.NullString
+	err := s.db.QueryRow(`
+		SELECT note FROM connection_requests
+		WHERE profile_url = ?
+		ORDER BY sent_at DESC, id DESC LIMIT 1
+	`, profileURL).Scan(&note)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return note.String, err
+}
+
 // SaveMessage saves a message
 func (s *Storage) SaveMessage(msg *Message) error {
 	_, err := s.db.Exec(`
-		INSERT INTO messages (profile_id, profile_url, content, status)
-		VALUES (?, ?, ?, ?)
-	`, msg.ProfileID, msg.ProfileURL, msg.Content, msg.Status)
+		INSERT INTO messages (profile_id, profile_url, content, status, thread_url, template_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, msg.ProfileID, msg.ProfileURL, msg.Content, msg.Status, msg.ThreadURL, msg.TemplateID)
 
 	return err
 }
 
+// MarkMessageReplied records that a profile replied to our latest message
+func (s *Storage) MarkMessageReplied(profileURL string) error {
+	_, err := s.db.Exec(`
+		UPDATE messages SET replied_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM messages
+			WHERE profile_url = ? AND status = 'sent'
+			ORDER BY sent_at DESC LIMIT 1
+		) AND replied_at IS NULL
+	`, profileURL)
+	if err != nil {
+		return err
+	}
+
+	return s.transitionToAny(s.db, profileURL, StateReplied)
+}
+
 // IsConnectionSent checks if a connection request was already sent to a profile
 func (s *Storage) IsConnectionSent(profileURL string) (bool, error) {
 	var count int
@@ -175,24 +812,103 @@ func (s *Storage) IsConnectionSent(profileURL string) (bool, error) {
 	return count > 0, err
 }
 
-// IsMessageSent checks if a message was already sent to a profile
+// IsMessageSent checks if a message was already sent to a profile or an
+// existing conversation with them was detected
 func (s *Storage) IsMessageSent(profileURL string) (bool, error) {
 	var count int
 	err := s.db.QueryRow(`
-		SELECT COUNT(*) FROM messages WHERE profile_url = ?
+		SELECT COUNT(*) FROM messages
+		WHERE profile_url = ? AND status IN ('sent', 'existing_thread')
 	`, profileURL).Scan(&count)
 
 	return count > 0, err
 }
 
-// GetAcceptedConnections returns connections that were accepted and haven't been messaged
+// GetAcceptedConnections returns connections that were accepted, haven't been
+// messaged and are past their message delay. Connections whose delay hasn't
+// been drawn yet are included so the caller can draw it.
 func (s *Storage) GetAcceptedConnections() ([]ConnectionRequest, error) {
 	rows, err := s.db.Query(`
-		SELECT cr.id, cr.profile_id, cr.profile_url, cr.sent_at, cr.note, cr.status, cr.accepted_at
+		SELECT cr.id, cr.profile_id, cr.profile_url, cr.sent_at, cr.note, cr.status, cr.accepted_at, cr.message_after_at
 		FROM connection_requests cr
 		LEFT JOIN messages m ON cr.profile_url = m.profile_url
 		WHERE cr.status = 'accepted' AND m.id IS NULL
+			AND (cr.message_after_at IS NULL OR cr.message_after_at <= ?)
 		ORDER BY cr.accepted_at DESC
+	`, time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var connections []ConnectionRequest
+	for rows.Next() {
+		var conn ConnectionRequest
+		var messageAfter sql.NullTime
+		if err := rows.Scan(&conn.ID, &conn.ProfileID, &conn.ProfileURL, &conn.SentAt, &conn.Note, &conn.Status, &conn.AcceptedAt, &messageAfter); err != nil {
+			return nil, err
+		}
+		if messageAfter.Valid {
+			conn.MessageAfterAt = &messageAfter.Time
+		}
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// SetMessageAfter records the earliest time an accepted connection may be messaged
+func (s *Storage) SetMessageAfter(connectionID int64, at time.Time) error {
+	_, err := s.db.Exec(`
+		UPDATE connection_requests SET message_after_at = ? WHERE id = ?
+	`, at.UTC().Format("2006-01-02 15:04:05"), connectionID)
+
+	return err
+}
+
+// SetMessageAfterForProfile records the earliest time a profile's accepted
+// connection may be messaged, unless one was already drawn
+func (s *Storage) SetMessageAfterForProfile(profileURL string, at time.Time) error {
+	_, err := s.db.Exec(`
+		UPDATE connection_requests SET message_after_at = ?
+		WHERE profile_url = ? AND status = 'accepted' AND message_after_at IS NULL
+	`, at.UTC().Format("2006-01-02 15:04:05"), profileURL)
+
+	return err
+}
+
+// GetPendingConnectionURLs returns the profile URLs of connection requests
+// that haven't been accepted yet
+func (s *Storage) GetPendingConnectionURLs() ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT profile_url FROM connection_requests WHERE status = 'pending'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+
+	return urls, rows.Err()
+}
+
+// GetFollowedProfiles returns profiles that were followed instead of invited
+// and haven't been messaged yet
+func (s *Storage) GetFollowedProfiles() ([]ConnectionRequest, error) {
+	rows, err := s.db.Query(`
+		SELECT cr.id, cr.profile_id, cr.profile_url, cr.sent_at, cr.note, cr.status, cr.accepted_at
+		FROM connection_requests cr
+		LEFT JOIN messages m ON cr.profile_url = m.profile_url
+		WHERE cr.status = 'followed' AND m.id IS NULL
+		ORDER BY cr.sent_at DESC
 	`)
 	if err != nil {
 		return nil, err
@@ -218,12 +934,12 @@ func (s *Storage) GetTodayStats() DailyStats {
 
 	s.db.QueryRow(`
 		SELECT COUNT(*) FROM connection_requests 
-		WHERE DATE(sent_at) = ?
+		WHERE DATE(sent_at) = ? AND `+invitationSentFilter+`
 	`, today).Scan(&stats.ConnectionsSent)
 
 	s.db.QueryRow(`
 		SELECT COUNT(*) FROM messages 
-		WHERE DATE(sent_at) = ?
+		WHERE DATE(sent_at) = ? AND status = 'sent'
 	`, today).Scan(&stats.MessagesSent)
 
 	return stats
@@ -236,19 +952,38 @@ func (s *Storage) GetHourlyStats() DailyStats {
 
 	s.db.QueryRow(`
 		SELECT COUNT(*) FROM connection_requests 
-		WHERE sent_at >= ?
+		WHERE sent_at >= ? AND `+invitationSentFilter+`
 	`, hourAgo).Scan(&stats.ConnectionsSent)
 
 	s.db.QueryRow(`
 		SELECT COUNT(*) FROM messages 
-		WHERE sent_at >= ?
+		WHERE sent_at >= ? AND status = 'sent'
 	`, hourAgo).Scan(&stats.MessagesSent)
 
 	return stats
 }
 
+// OnActivity registers an observer that sees every activity before it's
+// written, so counters can be kept without querying activity_log
+func (s *Storage) OnActivity(observer ActivityObserver) {
+	s.activityObserver.Store(&observer)
+}
+
+// notifyActivity passes an activity to the registered observer
+func (s *Storage) notifyActivity(actionType, outcome string) {
+	if observer := s.activityObserver.Load(); observer != nil {
+		(*observer)(actionType, outcome)
+	}
+}
+
 // LogActivity logs an activity to the database
 func (s *Storage) LogActivity(actionType, targetURL, outcome, errorMessage string) error {
+	s.notifyActivity(actionType, outcome)
+	return s.writeActivity(actionType, targetURL, outcome, errorMessage)
+}
+
+// writeActivity inserts a single activity_log row
+func (s *Storage) writeActivity(actionType, targetURL, outcome, errorMessage string) error {
 	_, err := s.db.Exec(`
 		INSERT INTO activity_log (action_type, target_url, outcome, error_message)
 		VALUES (?, ?, ?, ?)
@@ -257,37 +992,419 @@ func (s *Storage) LogActivity(actionType, targetURL, outcome, errorMessage strin
 	return err
 }
 
-// UpdateConnectionStatus updates the status of a connection request
+// LogActivityAsync queues an activity for batched writing so hot-path browser
+// actions don't block on SQLite. Use LogActivity for critical events.
+func (s *Storage) LogActivityAsync(actionType, targetURL, outcome, errorMessage string) {
+	s.notifyActivity(actionType, outcome)
+
+	s.activityMu.RLock()
+	defer s.activityMu.RUnlock()
+
+	if s.activityClosed {
+		s.writeActivity(actionType, targetURL, outcome, errorMessage)
+		return
+	}
+
+	entry := activityEntry{
+		actionType:   actionType,
+		targetURL:    targetURL,
+		outcome:      outcome,
+		errorMessage: errorMessage,
+	}
+
+	select {
+	case s.activityCh <- entry:
+	default:
+		// Queue is full, write synchronously rather than drop the entry
+		s.writeActivity(actionType, targetURL, outcome, errorMessage)
+	}
+}
+
+// FlushActivityLog writes all queued activities to the database
+func (s *Storage) FlushActivityLog() error {
+	s.activityMu.RLock()
+	defer s.activityMu.RUnlock()
+
+	if s.activityClosed {
+		return nil
+	}
+
+	reply := make(chan error)
+	s.activityFlush <- reply
+	return <-reply
+}
+
+// runActivityWriter drains the activity queue in batches until the queue is closed
+func (s *Storage) runActivityWriter() {
+	defer close(s.activityDone)
+
+	ticker := time.NewTicker(activityFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]activityEntry, 0, activityBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.writeActivityBatch(batch)
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		select {
+		case entry, ok := <-s.activityCh:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= activityBatchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+
+		case reply := <-s.activityFlush:
+			var err error
+			for drained := false; !drained; {
+				select {
+				case entry := <-s.activityCh:
+					batch = append(batch, entry)
+					if len(batch) >= activityBatchSize {
+						if flushErr := flush(); flushErr != nil {
+							err = flushErr
+						}
+					}
+				default:
+					drained = true
+				}
+			}
+			if flushErr := flush(); flushErr != nil {
+				err = flushErr
+			}
+			reply <- err
+		}
+	}
+}
+
+// writeActivityBatch inserts a batch of activities in a single transaction
+func (s *Storage) writeActivityBatch(batch []activityEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO activity_log (action_type, target_url, outcome, error_message)
+		VALUES (?, ?, ?, ?)
+	`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, entry := range batch {
+		if _, err := stmt.Exec(entry.actionType, entry.targetURL, entry.outcome, entry.errorMessage); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateConnectionStatus updates the status of a connection request and moves
+// the profile's connection state to match in the same transaction. If the
+// profile is in a state that can't move there, the status is still saved and
+// ErrStateConflict is returned.
 func (s *Storage) UpdateConnectionStatus(profileURL, status string) error {
-	_, err := s.db.Exec(`
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		UPDATE connection_requests 
-		SET status = ?, accepted_at = CASE WHEN ? = 'accepted' THEN CURRENT_TIMESTAMP ELSE accepted_at END
+		SET status = ?,
+			accepted_at = CASE WHEN ? = 'accepted' THEN CURRENT_TIMESTAMP ELSE accepted_at END,
+			response_time_hours = CASE WHEN ? = 'accepted'
+				THEN (JULIANDAY(CURRENT_TIMESTAMP) - JULIANDAY(sent_at)) * 24
+				ELSE response_time_hours END,
+			withdrawn_at = CASE WHEN ? = 'withdrawn' THEN CURRENT_TIMESTAMP ELSE withdrawn_at END,
+			expired_at = CASE WHEN ? = 'expired' THEN CURRENT_TIMESTAMP ELSE expired_at END
 		WHERE profile_url = ?
-	`, status, status, profileURL)
+	`, status, status, status, status, status, profileURL)
+	if err != nil {
+		return err
+	}
 
-	return err
+	var stateErr error
+	if state := ConnectionState(status); len(statesLeadingTo(state)) > 0 {
+		stateErr = s.transitionToAny(tx, profileURL, state)
+		if stateErr != nil && !errors.Is(stateErr, ErrStateConflict) {
+			return fmt.Errorf("failed to update connection state: %w", stateErr)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.invalidateProfile(profileURL)
+
+	return stateErr
 }
 
-// Close closes the database connection
+// Close flushes queued activities and closes the database connection
 func (s *Storage) Close() error {
+	s.activityMu.Lock()
+	if !s.activityClosed {
+		s.activityClosed = true
+		close(s.activityCh)
+		<-s.activityDone
+	}
+	s.activityMu.Unlock()
+
 	if s.db != nil {
 		return s.db.Close()
 	}
 	return nil
 }
 
-// GetProfileByURL retrieves a profile by URL
-func (s *Storage) GetProfileByURL(url string) (*Profile, error) {
+// profileColumns is the column list matching scanProfile
+const profileColumns = `id, profile_url, name, job_title, company, company_url, school, location, keywords, open_to_work, headline, summary, connection_degree, base_score, score, keyword_density, connection_state, discovered_at, last_active_estimate, normalized_job_title, seniority, company_size_bucket, discovered_url, discovery_source, normalized_company`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanProfile scans a row selected with profileColumns into a Profile
+func scanProfile(row rowScanner) (*Profile, error) {
 	var profile Profile
-	err := s.db.QueryRow(`
-		SELECT id, profile_url, name, job_title, company, location, keywords, discovered_at
+	var lastActive sql.NullTime
+	err := row.Scan(&profile.ID, &profile.ProfileURL, &profile.Name, &profile.JobTitle,
+		&profile.Company, &profile.CompanyURL, &profile.School, &profile.Location, &profile.Keywords, &profile.OpenToWork, &profile.Headline, &profile.Summary, &profile.ConnectionDegree, &profile.BaseScore, &profile.Score, &profile.KeywordDensity, &profile.ConnectionState, &profile.DiscoveredAt, &lastActive,
+		&profile.NormalizedJobTitle, &profile.Seniority, &profile.CompanySizeBucket, &profile.DiscoveredURL, &profile.DiscoverySource, &profile.NormalizedCompany)
+	if err != nil {
+		return nil, err
+	}
+	if lastActive.Valid {
+		profile.LastActiveEstimate = &lastActive.Time
+	}
+	return &profile, nil
+}
+
+// GetProfileByURL retrieves a profile by URL, serving repeat lookups from
+// the profile cache
+func (s *Storage) GetProfileByURL(url string) (*Profile, error) {
+	if profile, ok := s.cachedProfile(url); ok {
+		return profile, nil
+	}
+
+	profile, err := scanProfile(s.db.QueryRow(`
+		SELECT `+profileColumns+`
 		FROM profiles WHERE profile_url = ?
-	`, url).Scan(&profile.ID, &profile.ProfileURL, &profile.Name, &profile.JobTitle,
-		&profile.Company, &profile.Location, &profile.Keywords, &profile.DiscoveredAt)
+	`, url))
 
 	if err == sql.ErrNoRows {
-		return nil, nil
+		// The URL may belong to a profile merged into another one
+		canonical, aliasErr := s.ResolveProfileAlias(url)
+		if aliasErr != nil || canonical == url {
+			return nil, aliasErr
+		}
+		return s.GetProfileByURL(canonical)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheProfile(profile)
+	return profile, nil
+}
+
+// ListProfiles returns stored profiles matching the query options
+func (s *Storage) ListProfiles(opts ProfileQueryOptions) ([]Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM profiles`
+	var conditions []string
+	var args []any
+
+	if opts.OpenToWorkOnly {
+		conditions = append(conditions, "open_to_work = 1")
+	}
+
+	if opts.Seniority != "" {
+		conditions = append(conditions, "seniority = ?")
+		args = append(args, opts.Seniority)
+	}
+
+	if opts.CompanySize != "" {
+		conditions = append(conditions, "company_size_bucket = ?")
+		args = append(args, opts.CompanySize)
+	}
+
+	if opts.HeadlineContains != "" {
+		conditions = append(conditions, "headline LIKE ?")
+		args = append(args, "%"+opts.HeadlineContains+"%")
+	}
+
+	if company := normalize.NormalizeCompanyName(opts.CompanyContains); company != "" {
+		conditions = append(conditions, "normalized_company LIKE ?")
+		args = append(args, "%"+company+"%")
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY discovered_at DESC"
+
+	if opts.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, opts.Limit, opts.Offset)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// AddRelationship records a relationship between two profiles, ignoring duplicates
+func (s *Storage) AddRelationship(sourceID, targetID int64, relType, metadata string) error {
+	if sourceID == targetID {
+		return nil
+	}
+
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO profile_relationships (source_profile_id, target_profile_id, relationship_type, metadata)
+		VALUES (?, ?, ?, ?)
+	`, sourceID, targetID, relType, metadata)
+
+	return err
+}
+
+// GetRelatedProfiles returns profiles directly related to the given profile in
+// either direction. An empty relType matches every relationship type.
+func (s *Storage) GetRelatedProfiles(profileID int64, relType string) ([]Profile, error) {
+	rows, err := s.db.Query(`
+		SELECT `+profileColumns+` FROM profiles
+		WHERE id IN (
+			SELECT target_profile_id FROM profile_relationships
+			WHERE source_profile_id = ? AND (? = '' OR relationship_type = ?)
+			UNION
+			SELECT source_profile_id FROM profile_relationships
+			WHERE target_profile_id = ? AND (? = '' OR relationship_type = ?)
+		) AND id != ?
+		ORDER BY id
+	`, profileID, relType, relType, profileID, relType, relType, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// GetConnectedProfiles walks relationships breadth first from a profile and
+// returns every profile reachable from it, nearest first. Each profile is
+// visited once, so cycles in the graph are safe. An empty relType follows
+// every relationship type.
+func (s *Storage) GetConnectedProfiles(profileID int64, relType string) ([]Profile, error) {
+	visited := map[int64]bool{profileID: true}
+	queue := []int64{profileID}
+
+	var profiles []Profile
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		related, err := s.GetRelatedProfiles(id, relType)
+		if err != nil {
+			return nil, err
+		}
+		for _, profile := range related {
+			if visited[profile.ID] {
+				continue
+			}
+			visited[profile.ID] = true
+			profiles = append(profiles, profile)
+			queue = append(queue, profile.ID)
+		}
+	}
+
+	return profiles, nil
+}
+
+// GetRelationshipGraph returns every profile that takes part in a relationship
+// along with all relationship edges
+func (s *Storage) GetRelationshipGraph() (nodes []Profile, edges []Relationship, err error) {
+	edgeRows, err := s.db.Query(`
+		SELECT id, source_profile_id, target_profile_id, relationship_type, COALESCE(metadata, '')
+		FROM profile_relationships
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer edgeRows.Close()
+
+	for edgeRows.Next() {
+		var edge Relationship
+		if err := edgeRows.Scan(&edge.ID, &edge.SourceProfileID, &edge.TargetProfileID, &edge.RelationshipType, &edge.Metadata); err != nil {
+			return nil, nil, err
+		}
+		edges = append(edges, edge)
+	}
+	if err := edgeRows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	nodeRows, err := s.db.Query(`
+		SELECT ` + profileColumns + ` FROM profiles
+		WHERE id IN (
+			SELECT source_profile_id FROM profile_relationships
+			UNION
+			SELECT target_profile_id FROM profile_relationships
+		)
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer nodeRows.Close()
+
+	for nodeRows.Next() {
+		profile, err := scanProfile(nodeRows)
+		if err != nil {
+			return nil, nil, err
+		}
+		nodes = append(nodes, *profile)
 	}
 
-	return &profile, err
+	return nodes, edges, nodeRows.Err()
 }
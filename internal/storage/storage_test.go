@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestStorage opens a fresh database in a temporary directory
+func newTestStorage(t testing.TB) *Storage {
+	t.Helper()
+
+	s, err := New(filepath.Join(t.TempDir(), "test.db"), 100)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+// saveTestProfile saves a profile with the given username and returns it
+// with its ID set
+func saveTestProfile(t *testing.T, s *Storage, username string) *Profile {
+	t.Helper()
+
+	profile := &Profile{
+		ProfileURL: "https://www.linkedin.com/in/" + username,
+		Name:       username,
+	}
+	id, err := s.SaveProfile(profile)
+	if err != nil {
+		t.Fatalf("SaveProfile(%s): %v", username, err)
+	}
+	profile.ID = id
+
+	return profile
+}
+
+// saveTestConnection records a connection request to profile sent at sentAt
+// with the given status and moves the profile to the matching state, like
+// the connection_state backfill does
+func saveTestConnection(t *testing.T, s *Storage, profile *Profile, sentAt time.Time, status string) {
+	t.Helper()
+
+	if err := s.SaveConnectionRequest(&ConnectionRequest{
+		ProfileID:  profile.ID,
+		ProfileURL: profile.ProfileURL,
+		Status:     status,
+	}); err != nil {
+		t.Fatalf("SaveConnectionRequest: %v", err)
+	}
+	if _, err := s.db.Exec(`UPDATE connection_requests SET sent_at = ? WHERE profile_url = ?`,
+		sentAt.UTC().Format("2006-01-02 15:04:05"), profile.ProfileURL); err != nil {
+		t.Fatalf("set sent_at: %v", err)
+	}
+
+	state := ConnectionState(status)
+	if status == "pending" {
+		state = StateRequested
+	}
+	if _, ok := stateProgress[state]; ok {
+		if _, err := s.db.Exec(`UPDATE profiles SET connection_state = ? WHERE id = ?`, state, profile.ID); err != nil {
+			t.Fatalf("set connection_state: %v", err)
+		}
+	}
+}
+
+func TestSkippedAndFollowedRequestsAreNotCountedAsSent(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	saveTestConnection(t, s, saveTestProfile(t, s, "sent"), now, "pending")
+	saveTestConnection(t, s, saveTestProfile(t, s, "followed"), now, string(StateFollowed))
+	saveTestConnection(t, s, saveTestProfile(t, s, "skipped"), now, StatusEmailRequiredSkipped)
+
+	if got := s.GetTodayStats().ConnectionsSent; got != 1 {
+		t.Errorf("GetTodayStats ConnectionsSent = %d, want 1", got)
+	}
+	if got := s.GetHourlyStats().ConnectionsSent; got != 1 {
+		t.Errorf("GetHourlyStats ConnectionsSent = %d, want 1", got)
+	}
+
+	_, sent, err := s.GetWithdrawalCounts(now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetWithdrawalCounts: %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("GetWithdrawalCounts sent = %d, want 1", sent)
+	}
+}
+
+func TestFlushActivityLogPersistsQueuedRows(t *testing.T) {
+	s := newTestStorage(t)
+
+	// More than one batch, all still queued when the flush is requested
+	const queued = activityBatchSize*2 + 7
+	for i := 0; i < queued; i++ {
+		s.LogActivityAsync("profile_view", fmt.Sprintf("https://www.linkedin.com/in/user-%d", i), "success", "")
+	}
+
+	if err := s.FlushActivityLog(); err != nil {
+		t.Fatalf("FlushActivityLog: %v", err)
+	}
+
+	var rows, targets int
+	if err := s.db.QueryRow(`SELECT COUNT(*), COUNT(DISTINCT target_url) FROM activity_log WHERE action_type = 'profile_view'`).Scan(&rows, &targets); err != nil {
+		t.Fatalf("count activity_log: %v", err)
+	}
+	if rows != queued || targets != queued {
+		t.Errorf("activity_log has %d rows for %d targets, want %d of each", rows, targets, queued)
+	}
+}
+
+func BenchmarkLogActivity(b *testing.B) {
+	s := newTestStorage(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := s.LogActivity("profile_view", "https://www.linkedin.com/in/jane", "success", ""); err != nil {
+			b.Fatalf("LogActivity: %v", err)
+		}
+	}
+}
+
+func BenchmarkLogActivityAsync(b *testing.B) {
+	s := newTestStorage(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.LogActivityAsync("profile_view", "https://www.linkedin.com/in/jane", "success", "")
+	}
+	if err := s.FlushActivityLog(); err != nil {
+		b.Fatalf("FlushActivityLog: %v", err)
+	}
+}
+
+func TestUpdateLastActiveEstimate(t *testing.T) {
+	s := newTestStorage(t)
+	profile := saveTestProfile(t, s, "jane")
+
+	if got, err := s.GetProfileByURL(profile.ProfileURL); err != nil || got.LastActiveEstimate != nil {
+		t.Fatalf("LastActiveEstimate before update = %v (err %v), want nil", got.LastActiveEstimate, err)
+	}
+
+	estimate := time.Date(2024, 2, 18, 14, 0, 0, 0, time.UTC)
+	if err := s.UpdateLastActiveEstimate(profile.ProfileURL, estimate); err != nil {
+		t.Fatalf("UpdateLastActiveEstimate: %v", err)
+	}
+
+	got, err := s.GetProfileByURL(profile.ProfileURL)
+	if err != nil {
+		t.Fatalf("GetProfileByURL: %v", err)
+	}
+	if got.LastActiveEstimate == nil || !got.LastActiveEstimate.Equal(estimate) {
+		t.Errorf("LastActiveEstimate = %v, want %v", got.LastActiveEstimate, estimate)
+	}
+}
+
+func TestGetFollowedProfilesSkipsMessaged(t *testing.T) {
+	s := newTestStorage(t)
+
+	saveTestConnection(t, s, saveTestProfile(t, s, "jane"), time.Now(), "followed")
+	john := saveTestProfile(t, s, "john")
+	saveTestConnection(t, s, john, time.Now(), "followed")
+	saveTestConnection(t, s, saveTestProfile(t, s, "jim"), time.Now(), "pending")
+
+	if err := s.SaveMessage(&Message{ProfileID: john.ID, ProfileURL: john.ProfileURL, Status: "sent"}); err != nil {
+		t.Fatalf("SaveMessage: %v", err)
+	}
+
+	followed, err := s.GetFollowedProfiles()
+	if err != nil {
+		t.Fatalf("GetFollowedProfiles: %v", err)
+	}
+	if len(followed) != 1 || followed[0].ProfileURL != "https://www.linkedin.com/in/jane" {
+		t.Errorf("followed profiles = %+v, want only jane awaiting InMail", followed)
+	}
+}
+
+func TestConnectionNotePersists(t *testing.T) {
+	s := newTestStorage(t)
+
+	jane := saveTestProfile(t, s, "jane")
+	john := saveTestProfile(t, s, "john")
+
+	const note = "Hi Jane, loved your talk on Go generics — would be great to connect!"
+	for _, req := range []*ConnectionRequest{
+		{ProfileID: jane.ID, ProfileURL: jane.ProfileURL, Note: "first attempt", Status: "failed"},
+		{ProfileID: jane.ID, ProfileURL: jane.ProfileURL, Note: note, Status: "pending"},
+		{ProfileID: john.ID, ProfileURL: john.ProfileURL, Note: "", Status: "pending"},
+	} {
+		if err := s.SaveConnectionRequest(req); err != nil {
+			t.Fatalf("SaveConnectionRequest: %v", err)
+		}
+	}
+
+	tests := []struct {
+		profileURL string
+		want       string
+	}{
+		{jane.ProfileURL, note},
+		{john.ProfileURL, ""},
+		{"https://www.linkedin.com/in/nobody", ""},
+	}
+
+	for _, tt := range tests {
+		if got, err := s.GetConnectionNote(tt.profileURL); err != nil || got != tt.want {
+			t.Errorf("GetConnectionNote(%s) = %q, %v, want %q", tt.profileURL, got, err, tt.want)
+		}
+	}
+
+	// Requests sent without a note store an empty string rather than NULL
+	var nulls int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM connection_requests WHERE note IS NULL`).Scan(&nulls); err != nil {
+		t.Fatalf("count NULL notes: %v", err)
+	}
+	if nulls != 0 {
+		t.Errorf("%d requests stored a NULL note, want empty strings", nulls)
+	}
+}
+
+func TestListProfilesHeadlineContains(t *testing.T) {
+	s := newTestStorage(t)
+
+	for username, headline := range map[string]string{
+		"jane": "CTO at Acme | Distributed systems",
+		"john": "Head of Sales",
+		"jim":  "",
+	} {
+		if _, err := s.SaveProfile(&Profile{ProfileURL: "https://www.linkedin.com/in/" + username, Name: username, Headline: headline}); err != nil {
+			t.Fatalf("SaveProfile: %v", err)
+		}
+	}
+
+	tests := []struct {
+		contains string
+		want     []string
+	}{
+		{"cto", []string{"jane"}},
+		{"distributed", []string{"jane"}},
+		{"Head of", []string{"john"}},
+		{"designer", nil},
+		{"", []string{"jane", "jim", "john"}},
+	}
+
+	for _, tt := range tests {
+		profiles, err := s.ListProfiles(ProfileQueryOptions{HeadlineContains: tt.contains})
+		if err != nil {
+			t.Fatalf("ListProfiles(%q): %v", tt.contains, err)
+		}
+
+		var got []string
+		for _, profile := range profiles {
+			got = append(got, profile.Name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListProfiles(HeadlineContains %q) = %v, want %v", tt.contains, got, tt.want)
+		}
+	}
+}
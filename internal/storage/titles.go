@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"linkedin-automation/internal/normalize"
+)
+
+// backfillNormalizedJobTitles fills normalized_job_title and seniority for
+// profiles saved before the columns existed
+func (s *Storage) backfillNormalizedJobTitles() error {
+	rows, err := s.db.Query(`SELECT id, COALESCE(job_title, '') FROM profiles`)
+	if err != nil {
+		return err
+	}
+
+	type titleUpdate struct {
+		id         int64
+		normalized string
+		seniority  normalize.Seniority
+	}
+
+	var updates []titleUpdate
+	for rows.Next() {
+		var id int64
+		var title string
+		if err := rows.Scan(&id, &title); err != nil {
+			rows.Close()
+			return err
+		}
+
+		normalized, seniority := normalize.NormalizeJobTitle(title)
+		updates = append(updates, titleUpdate{id, normalized, seniority})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, u := range updates {
+		if _, err := tx.Exec(`UPDATE profiles SET normalized_job_title = ?, seniority = ? WHERE id = ?`,
+			u.normalized, u.seniority, u.id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+)
+
+// ErrInvalidVariableKey is returned for keys that can't be used as template
+// placeholders
+var ErrInvalidVariableKey = errors.New("variable key must contain only letters, digits and underscores")
+
+var variableKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ValidVariableKey reports whether key can be used as a {{.Vars.key}} placeholder
+func ValidVariableKey(key string) bool {
+	return variableKeyPattern.MatchString(key)
+}
+
+// SetVariable stores a custom template variable for a profile, replacing any
+// existing value for the key
+func (s *Storage) SetVariable(profileID int64, key, value string) error {
+	if !ValidVariableKey(key) {
+		return ErrInvalidVariableKey
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO profile_variables (profile_id, key, value)
+		VALUES (?, ?, ?)
+		ON CONFLICT(profile_id, key) DO UPDATE SET value = excluded.value
+	`, profileID, key, value)
+
+	return err
+}
+
+// GetVariable returns a profile's variable, or "" if it isn't set
+func (s *Storage) GetVariable(profileID int64, key string) (string, error) {
+	var value string
+	err := s.db.QueryRow(`
+		SELECT value FROM profile_variables WHERE profile_id = ? AND key = ?
+	`, profileID, key).Scan(&value)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return value, err
+}
+
+// GetVariables returns all variables set for a profile
+func (s *Storage) GetVariables(profileID int64) (map[string]string, error) {
+	rows, err := s.db.Query(`
+		SELECT key, value FROM profile_variables WHERE profile_id = ?
+	`, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vars := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		vars[key] = value
+	}
+
+	return vars, rows.Err()
+}
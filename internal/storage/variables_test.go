@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProfileVariables(t *testing.T) {
+	s := newTestStorage(t)
+	jane := saveTestProfile(t, s, "jane")
+	john := saveTestProfile(t, s, "john")
+
+	if err := s.SetVariable(jane.ID, "trigger_event", "promoted last month"); err != nil {
+		t.Fatalf("SetVariable: %v", err)
+	}
+	if err := s.SetVariable(jane.ID, "pain_point", "hiring"); err != nil {
+		t.Fatalf("SetVariable: %v", err)
+	}
+	// Setting a key again replaces its value
+	if err := s.SetVariable(jane.ID, "pain_point", "scaling engineers"); err != nil {
+		t.Fatalf("SetVariable: %v", err)
+	}
+
+	if got, err := s.GetVariable(jane.ID, "pain_point"); err != nil || got != "scaling engineers" {
+		t.Errorf("GetVariable = %q, %v, want the replaced value", got, err)
+	}
+	if got, err := s.GetVariable(jane.ID, "missing"); err != nil || got != "" {
+		t.Errorf("GetVariable for an unset key = %q, %v, want empty", got, err)
+	}
+
+	want := map[string]string{"trigger_event": "promoted last month", "pain_point": "scaling engineers"}
+	if got, err := s.GetVariables(jane.ID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetVariables = %v, %v, want %v", got, err, want)
+	}
+	if got, err := s.GetVariables(john.ID); err != nil || len(got) != 0 {
+		t.Errorf("GetVariables for another profile = %v, %v, want none", got, err)
+	}
+
+	for _, key := range []string{"", "pain point", "pain-point", "{{x}}"} {
+		if err := s.SetVariable(jane.ID, key, "x"); !errors.Is(err, ErrInvalidVariableKey) {
+			t.Errorf("SetVariable(%q) = %v, want ErrInvalidVariableKey", key, err)
+		}
+	}
+}
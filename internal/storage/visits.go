@@ -0,0 +1,46 @@
+package storage
+
+import "time"
+
+// Why a profile page was opened. Only outreach visits count towards the
+// revisit window, so enriching a profile doesn't hold back its connection
+// request or message.
+const (
+	VisitPurposeOutreach   = "outreach"
+	VisitPurposeEnrichment = "enrichment"
+)
+
+// RecordProfileVisit records that a profile page was opened for the given
+// purpose
+func (s *Storage) RecordProfileVisit(profileURL, purpose string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO profile_visits (profile_url, visited_at, purpose) VALUES (?, ?, ?)
+	`, profileURL, time.Now().UTC().Format("2006-01-02 15:04:05"), purpose)
+
+	return err
+}
+
+// HasVisitedRecently reports whether a profile page was opened for outreach
+// within the last withinHours hours
+func (s *Storage) HasVisitedRecently(profileURL string, withinHours int) (bool, error) {
+	cutoff := time.Now().UTC().Add(-time.Duration(withinHours) * time.Hour).Format("2006-01-02 15:04:05")
+
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM profile_visits
+		WHERE profile_url = ? AND visited_at >= ? AND purpose = ?
+	`, profileURL, cutoff, VisitPurposeOutreach).Scan(&count)
+
+	return count > 0, err
+}
+
+// CountProfileVisitsSince returns how many profile pages were opened for the
+// given purpose since the given time
+func (s *Storage) CountProfileVisitsSince(purpose string, since time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM profile_visits WHERE purpose = ? AND visited_at >= ?
+	`, purpose, since.UTC().Format("2006-01-02 15:04:05")).Scan(&count)
+
+	return count, err
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnrichmentVisitsDontCountAsRevisits(t *testing.T) {
+	s := newTestStorage(t)
+	url := "https://www.linkedin.com/in/alice"
+
+	if err := s.RecordProfileVisit(url, VisitPurposeEnrichment); err != nil {
+		t.Fatalf("RecordProfileVisit: %v", err)
+	}
+
+	visited, err := s.HasVisitedRecently(url, 24)
+	if err != nil {
+		t.Fatalf("HasVisitedRecently: %v", err)
+	}
+	if visited {
+		t.Error("an enrichment visit should not count towards the revisit window")
+	}
+
+	if err := s.RecordProfileVisit(url, VisitPurposeOutreach); err != nil {
+		t.Fatalf("RecordProfileVisit: %v", err)
+	}
+	if visited, _ := s.HasVisitedRecently(url, 24); !visited {
+		t.Error("an outreach visit should count towards the revisit window")
+	}
+
+	count, err := s.CountProfileVisitsSince(VisitPurposeEnrichment, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("CountProfileVisitsSince: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("enrichment visits in the last hour = %d, want 1", count)
+	}
+}
@@ -0,0 +1,46 @@
+package storage
+
+import "time"
+
+// GetPendingConnectionsOlderThan returns connection requests still pending
+// after the given number of days, oldest first
+func (s *Storage) GetPendingConnectionsOlderThan(days int) ([]ConnectionRequest, error) {
+	cutoff := time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+
+	rows, err := s.db.Query(`
+		SELECT id, profile_id, profile_url, sent_at, COALESCE(note, ''), status, accepted_at
+		FROM connection_requests
+		WHERE status = 'pending' AND sent_at <= ?
+		ORDER BY sent_at
+	`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var connections []ConnectionRequest
+	for rows.Next() {
+		var conn ConnectionRequest
+		if err := rows.Scan(&conn.ID, &conn.ProfileID, &conn.ProfileURL, &conn.SentAt, &conn.Note, &conn.Status, &conn.AcceptedAt); err != nil {
+			return nil, err
+		}
+		connections = append(connections, conn)
+	}
+
+	return connections, rows.Err()
+}
+
+// GetWithdrawalCounts returns how many of the connection requests sent
+// between from and to were sent and how many of those were later withdrawn,
+// so the rate is over a single cohort of requests
+func (s *Storage) GetWithdrawalCounts(from, to time.Time) (withdrawn, sent int, err error) {
+	err = s.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN withdrawn_at IS NOT NULL THEN 1 ELSE 0 END), 0),
+			COUNT(*)
+		FROM connection_requests
+		WHERE sent_at >= ? AND sent_at < ? AND `+invitationSentFilter+`
+	`, from.UTC().Format("2006-01-02 15:04:05"), to.UTC().Format("2006-01-02 15:04:05")).Scan(&withdrawn, &sent)
+
+	return withdrawn, sent, err
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetPendingConnectionsOlderThan(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	saveTestConnection(t, s, saveTestProfile(t, s, "old-pending"), now.AddDate(0, 0, -30), "pending")
+	saveTestConnection(t, s, saveTestProfile(t, s, "older-pending"), now.AddDate(0, 0, -40), "pending")
+	saveTestConnection(t, s, saveTestProfile(t, s, "new-pending"), now.AddDate(0, 0, -3), "pending")
+	saveTestConnection(t, s, saveTestProfile(t, s, "old-accepted"), now.AddDate(0, 0, -30), "accepted")
+
+	stale, err := s.GetPendingConnectionsOlderThan(21)
+	if err != nil {
+		t.Fatalf("GetPendingConnectionsOlderThan: %v", err)
+	}
+
+	if len(stale) != 2 {
+		t.Fatalf("got %d stale requests, want 2", len(stale))
+	}
+	if stale[0].ProfileURL != "https://www.linkedin.com/in/older-pending" {
+		t.Errorf("first stale request = %s, want the oldest", stale[0].ProfileURL)
+	}
+}
+
+func TestGetWithdrawalCountsUsesOneCohort(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	// Cohort sent 21-28 days ago: 4 requests, one withdrawn
+	for i, status := range []string{"pending", "accepted", "pending", "pending"} {
+		saveTestConnection(t, s, saveTestProfile(t, s, "cohort-"+string(rune('a'+i))), now.AddDate(0, 0, -25), status)
+	}
+	if err := s.UpdateConnectionStatus("https://www.linkedin.com/in/cohort-a", "withdrawn"); err != nil {
+		t.Fatalf("UpdateConnectionStatus: %v", err)
+	}
+
+	// Sent this week and long ago, outside the cohort
+	saveTestConnection(t, s, saveTestProfile(t, s, "recent"), now.AddDate(0, 0, -2), "pending")
+	saveTestConnection(t, s, saveTestProfile(t, s, "ancient"), now.AddDate(0, 0, -60), "pending")
+	if err := s.UpdateConnectionStatus("https://www.linkedin.com/in/ancient", "withdrawn"); err != nil {
+		t.Fatalf("UpdateConnectionStatus: %v", err)
+	}
+
+	withdrawn, sent, err := s.GetWithdrawalCounts(now.AddDate(0, 0, -28), now.AddDate(0, 0, -21))
+	if err != nil {
+		t.Fatalf("GetWithdrawalCounts: %v", err)
+	}
+	if withdrawn != 1 || sent != 4 {
+		t.Errorf("GetWithdrawalCounts = (%d, %d), want (1, 4)", withdrawn, sent)
+	}
+}
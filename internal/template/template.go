@@ -0,0 +1,136 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"linkedin-automation/internal/config"
+)
+
+// Message types understood by ValidateMessageLength
+const (
+	TypeConnectionNote = "connection_note"
+	TypeMessage        = "message"
+	TypeComment        = "comment"
+)
+
+// LinkedIn character limits
+const (
+	MaxConnectionNoteLength = 300
+	MaxMessageLength        = 8000
+	MaxCommentLength        = 1250
+)
+
+var (
+	ErrEmptyContent          = errors.New("message content is empty")
+	ErrUnresolvedPlaceholder = errors.New("message contains unresolved placeholders")
+	ErrContentTooLong        = errors.New("message content exceeds character limit")
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+// variablePattern matches custom variable placeholders such as {{.Vars.pain_point}}
+var variablePattern = regexp.MustCompile(`\{\{\s*\.Vars\.([A-Za-z0-9_]+)\s*\}\}`)
+
+type Engine struct {
+	cfg *config.Config
+}
+
+func New(cfg *config.Config) *Engine {
+	return &Engine{cfg: cfg}
+}
+
+// RenderVariables replaces {{.Vars.key}} placeholders with the profile's
+// custom variables. Unknown keys are left in place so validation rejects them.
+func (e *Engine) RenderVariables(content string, vars map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(content, func(placeholder string) string {
+		key := variablePattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := vars[key]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// MissingVariables returns the {{.Vars.key}} keys used in content that vars
+// doesn't define
+func MissingVariables(content string, vars map[string]string) []string {
+	var missing []string
+	for _, match := range variablePattern.FindAllStringSubmatch(content, -1) {
+		if _, ok := vars[match[1]]; !ok {
+			missing = append(missing, match[1])
+		}
+	}
+	return missing
+}
+
+// ValidateMessageLength checks rendered content against LinkedIn's limits for
+// the given message type. When truncation is enabled for that type, content
+// over the limit is cut at the last sentence boundary instead of rejected.
+func (e *Engine) ValidateMessageLength(content string, msgType string) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", ErrEmptyContent
+	}
+
+	if placeholders := placeholderPattern.FindAllString(content, -1); len(placeholders) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrUnresolvedPlaceholder, strings.Join(placeholders, ", "))
+	}
+
+	limit, truncate := e.limitFor(msgType)
+	length := utf8.RuneCountInString(content)
+	if length <= limit {
+		return content, nil
+	}
+
+	if !truncate {
+		return "", fmt.Errorf("%w: %d > %d characters", ErrContentTooLong, length, limit)
+	}
+
+	return truncateAtSentence(content, limit), nil
+}
+
+// limitFor returns the character limit and truncation setting for a message type
+func (e *Engine) limitFor(msgType string) (int, bool) {
+	switch msgType {
+	case TypeConnectionNote:
+		limit := MaxConnectionNoteLength
+		if n := e.cfg.Connection.NoteMaxLength; n > 0 && n < limit {
+			limit = n
+		}
+		return limit, e.cfg.Connection.TruncateOnOverflow
+	case TypeComment:
+		return MaxCommentLength, false
+	default:
+		return MaxMessageLength, e.cfg.Messaging.TruncateOnOverflow
+	}
+}
+
+// truncateAtSentence shortens content to at most limit characters, preferring
+// to cut after the last complete sentence, then the last word
+func truncateAtSentence(content string, limit int) string {
+	runes := []rune(content)
+	if len(runes) <= limit {
+		return content
+	}
+
+	cut := runes[:limit]
+
+	for i := len(cut) - 1; i > 0; i-- {
+		if cut[i] == '.' || cut[i] == '!' || cut[i] == '?' {
+			if i == len(runes)-1 || runes[i+1] == ' ' || runes[i+1] == '\n' {
+				return strings.TrimSpace(string(cut[:i+1]))
+			}
+		}
+	}
+
+	for i := len(cut) - 1; i > 0; i-- {
+		if cut[i] == ' ' {
+			return strings.TrimSpace(string(cut[:i]))
+		}
+	}
+
+	return string(cut)
+}
@@ -0,0 +1,148 @@
+package template
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"linkedin-automation/internal/config"
+)
+
+func newTestEngine(configure func(cfg *config.Config)) *Engine {
+	cfg := &config.Config{}
+	if configure != nil {
+		configure(cfg)
+	}
+	return New(cfg)
+}
+
+func TestValidateMessageLengthBoundaries(t *testing.T) {
+	e := newTestEngine(nil)
+
+	tests := []struct {
+		name    string
+		content string
+		msgType string
+		wantErr error
+	}{
+		{"note at the limit", strings.Repeat("a", MaxConnectionNoteLength), TypeConnectionNote, nil},
+		{"note one over the limit", strings.Repeat("a", MaxConnectionNoteLength+1), TypeConnectionNote, ErrContentTooLong},
+		{"multibyte note at the limit", strings.Repeat("é", MaxConnectionNoteLength), TypeConnectionNote, nil},
+		{"multibyte note over the limit", strings.Repeat("日", MaxConnectionNoteLength+1), TypeConnectionNote, ErrContentTooLong},
+		{"emoji note at the limit", strings.Repeat("👋", MaxConnectionNoteLength), TypeConnectionNote, nil},
+		{"message at the limit", strings.Repeat("a", MaxMessageLength), TypeMessage, nil},
+		{"message one over the limit", strings.Repeat("a", MaxMessageLength+1), TypeMessage, ErrContentTooLong},
+		{"unknown type uses the message limit", strings.Repeat("a", MaxMessageLength), "inmail", nil},
+		{"empty", "", TypeMessage, ErrEmptyContent},
+		{"whitespace only", " \n\t ", TypeConnectionNote, ErrEmptyContent},
+		{"unresolved placeholder", "Hi {{FirstName}},", TypeMessage, ErrUnresolvedPlaceholder},
+		{"unresolved variable", "Saw {{.Vars.trigger_event}}", TypeConnectionNote, ErrUnresolvedPlaceholder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.ValidateMessageLength(tt.content, tt.msgType)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateMessageLength error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != tt.content {
+				t.Errorf("content within the limit was changed")
+			}
+		})
+	}
+}
+
+func TestValidateMessageLengthConfiguredNoteLimit(t *testing.T) {
+	e := newTestEngine(func(cfg *config.Config) { cfg.Connection.NoteMaxLength = 200 })
+
+	if _, err := e.ValidateMessageLength(strings.Repeat("a", 200), TypeConnectionNote); err != nil {
+		t.Errorf("note at the configured limit: %v", err)
+	}
+	if _, err := e.ValidateMessageLength(strings.Repeat("a", 201), TypeConnectionNote); !errors.Is(err, ErrContentTooLong) {
+		t.Errorf("note over the configured limit: %v, want ErrContentTooLong", err)
+	}
+
+	// A configured limit above LinkedIn's cannot raise it
+	e = newTestEngine(func(cfg *config.Config) { cfg.Connection.NoteMaxLength = 500 })
+	if _, err := e.ValidateMessageLength(strings.Repeat("a", 301), TypeConnectionNote); !errors.Is(err, ErrContentTooLong) {
+		t.Errorf("note over LinkedIn's limit: %v, want ErrContentTooLong", err)
+	}
+}
+
+func TestValidateMessageLengthTruncates(t *testing.T) {
+	e := newTestEngine(func(cfg *config.Config) {
+		cfg.Connection.TruncateOnOverflow = true
+		cfg.Messaging.TruncateOnOverflow = true
+	})
+
+	first := "Hi Jane, loved your talk on scaling teams. "
+	note := first + strings.Repeat("I would really like to connect and hear more ", 10)
+	got, err := e.ValidateMessageLength(note, TypeConnectionNote)
+	if err != nil {
+		t.Fatalf("ValidateMessageLength: %v", err)
+	}
+	if got != strings.TrimSpace(first) {
+		t.Errorf("truncated note = %q, want the first sentence", got)
+	}
+
+	// Without a sentence boundary it cuts at the last word, counting runes
+	words := strings.Repeat("café ", 100)
+	got, err = e.ValidateMessageLength(words, TypeConnectionNote)
+	if err != nil {
+		t.Fatalf("ValidateMessageLength: %v", err)
+	}
+	if n := utf8.RuneCountInString(got); n > MaxConnectionNoteLength || !strings.HasSuffix(got, "café") {
+		t.Errorf("truncated to %d runes ending %q, want at most %d ending on a whole word", n, got[len(got)-6:], MaxConnectionNoteLength)
+	}
+
+	// A period inside a word is not a sentence boundary
+	got = truncateAtSentence("See example.com for details about it", 20)
+	if got != "See example.com for" {
+		t.Errorf("truncateAtSentence = %q, want a cut at the last word", got)
+	}
+}
+
+func TestRenderVariables(t *testing.T) {
+	e := newTestEngine(nil)
+	vars := map[string]string{
+		"trigger_event": "your promotion last month",
+		"pain_point":    "scaling engineers",
+		"empty":         "",
+	}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"Congrats on {{.Vars.trigger_event}}!", "Congrats on your promotion last month!"},
+		{"{{ .Vars.pain_point }} is hard, and {{.Vars.pain_point}} again", "scaling engineers is hard, and scaling engineers again"},
+		{"Hi{{.Vars.empty}} there", "Hi there"},
+		{"Saw {{.Vars.unknown}}", "Saw {{.Vars.unknown}}"},
+		{"No variables here", "No variables here"},
+	}
+
+	for _, tt := range tests {
+		if got := e.RenderVariables(tt.content, vars); got != tt.want {
+			t.Errorf("RenderVariables(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+
+	// Unknown keys stay in place so validation rejects the message
+	rendered := e.RenderVariables("Saw {{.Vars.unknown}}", vars)
+	if _, err := e.ValidateMessageLength(rendered, TypeMessage); !errors.Is(err, ErrUnresolvedPlaceholder) {
+		t.Errorf("ValidateMessageLength with an unknown variable = %v, want ErrUnresolvedPlaceholder", err)
+	}
+}
+
+func TestMissingVariables(t *testing.T) {
+	vars := map[string]string{"trigger_event": "promotion"}
+
+	got := MissingVariables("{{.Vars.trigger_event}} {{.Vars.pain_point}} {{FirstName}} {{.Vars.team}}", vars)
+	if strings.Join(got, ",") != "pain_point,team" {
+		t.Errorf("MissingVariables = %v, want [pain_point team]", got)
+	}
+	if got := MissingVariables("Hi {{.Vars.trigger_event}}", vars); len(got) != 0 {
+		t.Errorf("MissingVariables with every key set = %v, want none", got)
+	}
+}
@@ -0,0 +1,133 @@
+package urlutil
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// linkedInHost is the only host stored profile and company URLs may use
+const linkedInHost = "www.linkedin.com"
+
+// ErrInvalidLinkedInURL is returned for URLs that aren't a LinkedIn member,
+// company or Sales Navigator page
+var ErrInvalidLinkedInURL = errors.New("invalid LinkedIn URL")
+
+// slugPattern matches a profile or company username segment
+var slugPattern = regexp.MustCompile(`^[a-zA-Z0-9\-]+$`)
+
+// salesIDPattern matches the start of a Sales Navigator lead ID, which is
+// followed by comma separated search context
+var salesIDPattern = regexp.MustCompile(`^[a-zA-Z0-9\-]+`)
+
+// reservedSlugs are /in/ usernames that belong to LinkedIn itself
+var reservedSlugs = map[string]bool{
+	"linkedin": true,
+}
+
+// ValidateLinkedInURL checks that raw is an https://www.linkedin.com URL of a
+// member profile (/in/<username>), company page (/company/<name>) or Sales
+// Navigator lead (/sales/<kind>/<id>). A trailing slash is allowed, deeper
+// paths such as /in/<username>/details/ are not.
+func ValidateLinkedInURL(raw string) error {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidLinkedInURL, err)
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be https: %s", ErrInvalidLinkedInURL, raw)
+	}
+	if !strings.EqualFold(parsed.Host, linkedInHost) {
+		return fmt.Errorf("%w: host must be %s: %s", ErrInvalidLinkedInURL, linkedInHost, raw)
+	}
+
+	segments := strings.Split(strings.TrimSuffix(parsed.Path, "/"), "/")
+	if len(segments) < 3 || segments[0] != "" {
+		return fmt.Errorf("%w: missing username: %s", ErrInvalidLinkedInURL, raw)
+	}
+
+	switch segments[1] {
+	case "in", "company":
+		if len(segments) != 3 {
+			return fmt.Errorf("%w: unexpected path after username: %s", ErrInvalidLinkedInURL, raw)
+		}
+		if !slugPattern.MatchString(segments[2]) {
+			return fmt.Errorf("%w: invalid username %q", ErrInvalidLinkedInURL, segments[2])
+		}
+		if segments[1] == "in" && reservedSlugs[strings.ToLower(segments[2])] {
+			return fmt.Errorf("%w: %s is a LinkedIn page, not a member", ErrInvalidLinkedInURL, raw)
+		}
+	case "sales":
+		if len(segments) != 4 {
+			return fmt.Errorf("%w: expected /sales/<kind>/<id>: %s", ErrInvalidLinkedInURL, raw)
+		}
+		if !salesIDPattern.MatchString(segments[3]) {
+			return fmt.Errorf("%w: invalid Sales Navigator ID %q", ErrInvalidLinkedInURL, segments[3])
+		}
+	default:
+		return fmt.Errorf("%w: path must start with /in/, /company/ or /sales/: %s", ErrInvalidLinkedInURL, raw)
+	}
+
+	return nil
+}
+
+// localeHostPattern matches country subdomains such as de.linkedin.com
+var localeHostPattern = regexp.MustCompile(`^[a-z]{2}\.linkedin\.com$`)
+
+// NormalizeProfileURL canonicalizes a member profile or company page URL to
+// https://www.linkedin.com/in/<username> or /company/<name>. Relative paths
+// and missing schemes are accepted, locale subdomains such as de.linkedin.com
+// become www, the username is percent-decoded and lowercased, and query
+// strings, fragments, trailing slashes and sub-pages such as /details/ are
+// dropped. Anything else returns ErrInvalidLinkedInURL.
+func NormalizeProfileURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(raw, "/"):
+		raw = "https://" + linkedInHost + raw
+	case !strings.Contains(raw, "://"):
+		raw = "https://" + raw
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidLinkedInURL, err)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host != linkedInHost && host != "linkedin.com" && !localeHostPattern.MatchString(host) {
+		return "", fmt.Errorf("%w: host must be %s: %s", ErrInvalidLinkedInURL, linkedInHost, raw)
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) < 2 || (segments[0] != "in" && segments[0] != "company") {
+		return "", fmt.Errorf("%w: not a profile or company page: %s", ErrInvalidLinkedInURL, raw)
+	}
+
+	canonical := "https://" + linkedInHost + "/" + segments[0] + "/" + strings.ToLower(segments[1])
+	if err := ValidateLinkedInURL(canonical); err != nil {
+		return "", err
+	}
+	return canonical, nil
+}
+
+// IsPersonProfileURL reports whether rawURL is a valid member profile URL
+func IsPersonProfileURL(rawURL string) bool {
+	return hasValidPrefix(rawURL, "/in/")
+}
+
+// IsCompanyURL reports whether rawURL is a valid company page URL
+func IsCompanyURL(rawURL string) bool {
+	return hasValidPrefix(rawURL, "/company/")
+}
+
+// hasValidPrefix reports whether rawURL is valid and its path starts with prefix
+func hasValidPrefix(rawURL, prefix string) bool {
+	if ValidateLinkedInURL(rawURL) != nil {
+		return false
+	}
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	return err == nil && strings.HasPrefix(parsed.Path, prefix)
+}
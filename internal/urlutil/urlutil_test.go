@@ -0,0 +1,124 @@
+package urlutil
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateLinkedInURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://www.linkedin.com/in/jane-doe", true},
+		{"https://www.linkedin.com/in/jane-doe/", true},
+		{"https://www.linkedin.com/in/JaneDoe123", true},
+		{"  https://www.linkedin.com/in/jane  ", true},
+		{"https://WWW.LinkedIn.com/in/jane", true},
+		{"https://www.linkedin.com/in/jane?trk=search", true},
+		{"https://www.linkedin.com/company/acme", true},
+		{"https://www.linkedin.com/company/acme-inc/", true},
+		{"https://www.linkedin.com/sales/lead/ACwAAA123,NAME_SEARCH,abc", true},
+		{"https://www.linkedin.com/sales/people/ACwAAA123", true},
+		{"http://www.linkedin.com/in/jane", false},
+		{"www.linkedin.com/in/jane", false},
+		{"https://linkedin.com/in/jane", false},
+		{"https://de.linkedin.com/in/jane", false},
+		{"https://www.linkedin.com.evil.com/in/jane", false},
+		{"https://www.linkedin.com/in/", false},
+		{"https://www.linkedin.com/in", false},
+		{"https://www.linkedin.com/", false},
+		{"https://www.linkedin.com/in/jane/details/skills/", false},
+		{"https://www.linkedin.com/in/jane_doe", false},
+		{"https://www.linkedin.com/in/jos%C3%A9", false},
+		{"https://www.linkedin.com/in/linkedin", false},
+		{"https://www.linkedin.com/in/LinkedIn/", false},
+		{"https://www.linkedin.com/company/linkedin", true},
+		{"https://www.linkedin.com/feed/", false},
+		{"https://www.linkedin.com/jobs/view/123", false},
+		{"https://www.linkedin.com/sales/lead", false},
+		{"https://www.linkedin.com/sales/lead/,abc", false},
+		{"https://www.linkedin.com/company/acme/about/", false},
+		{"://bad", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateLinkedInURL(tt.url)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateLinkedInURL = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidLinkedInURL) {
+				t.Errorf("ValidateLinkedInURL = %v, want ErrInvalidLinkedInURL", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeProfileURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string // empty when the URL is rejected
+	}{
+		{"https://www.linkedin.com/in/jane-doe", "https://www.linkedin.com/in/jane-doe"},
+		{"https://www.linkedin.com/in/jane-doe/", "https://www.linkedin.com/in/jane-doe"},
+		{"https://www.linkedin.com/in/Jane-Doe", "https://www.linkedin.com/in/jane-doe"},
+		{"https://www.linkedin.com/in/jane?miniProfileUrn=urn%3Ali%3Afs", "https://www.linkedin.com/in/jane"},
+		{"https://www.linkedin.com/in/jane/#experience", "https://www.linkedin.com/in/jane"},
+		{"https://www.linkedin.com/in/jane/details/skills/", "https://www.linkedin.com/in/jane"},
+		{"http://www.linkedin.com/in/jane", "https://www.linkedin.com/in/jane"},
+		{"www.linkedin.com/in/jane", "https://www.linkedin.com/in/jane"},
+		{"linkedin.com/in/jane", "https://www.linkedin.com/in/jane"},
+		{"/in/jane/", "https://www.linkedin.com/in/jane"},
+		{"https://de.linkedin.com/in/jane", "https://www.linkedin.com/in/jane"},
+		{"https://uk.linkedin.com/in/jane?locale=en_GB", "https://www.linkedin.com/in/jane"},
+		{"https://www.linkedin.com/in/jane%2Ddoe", "https://www.linkedin.com/in/jane-doe"},
+		{"https://www.linkedin.com/company/Acme/about/", "https://www.linkedin.com/company/acme"},
+		{"https://www.linkedin.com/in/jos%C3%A9", ""},
+		{"https://www.linkedin.com/in/linkedin", ""},
+		{"https://www.linkedin.com/in/", ""},
+		{"https://www.linkedin.com/feed/", ""},
+		{"https://www.linkedin.com/sales/lead/ACwAAA123", ""},
+		{"https://mobile.app.linkedin.com/in/jane", ""},
+		{"https://example.com/in/jane", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := NormalizeProfileURL(tt.url)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidLinkedInURL) {
+					t.Errorf("NormalizeProfileURL = %q, %v, want ErrInvalidLinkedInURL", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizeProfileURL = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileKindHelpers(t *testing.T) {
+	tests := []struct {
+		url     string
+		person  bool
+		company bool
+	}{
+		{"https://www.linkedin.com/in/jane", true, false},
+		{"https://www.linkedin.com/company/acme/", false, true},
+		{"https://www.linkedin.com/sales/lead/ACwAAA123", false, false},
+		{"https://www.linkedin.com/in/linkedin", false, false},
+		{"https://example.com/company/acme", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPersonProfileURL(tt.url); got != tt.person {
+			t.Errorf("IsPersonProfileURL(%q) = %v, want %v", tt.url, got, tt.person)
+		}
+		if got := IsCompanyURL(tt.url); got != tt.company {
+			t.Errorf("IsCompanyURL(%q) = %v, want %v", tt.url, got, tt.company)
+		}
+	}
+}